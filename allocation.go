@@ -0,0 +1,135 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+//allocationState keeps fractions of equity allocated to strategies and realized PnL of the strategies at
+//the start of reallocation period
+type allocationState struct {
+	weights     map[string]float64
+	period      time.Duration
+	minWeight   float64
+	periodStart time.Time
+	startPnL    map[string]float64
+}
+
+//SetAllocation sets fractions of equity allocated to strategies by symbol, e.g. 0.25 for 25%. Sum of
+//fractions can't be more than 1. Strategies without allocation get nothing. Sizing helpers of strategies use
+//allocated equity instead of equity of the whole account.
+func (c *Engine) SetAllocation(weights map[string]float64) error {
+	return c.portfolio.setAllocation(weights)
+}
+
+//SetReallocation enables reallocation of equity every period of market time by realized performance.
+//Allocation of the strategy is multiplied by one plus its realized return on allocated equity during the period,
+//then allocations are scaled back to the same total. Allocation can't fall below minWeight. Zero period disables
+//reallocation.
+func (c *Engine) SetReallocation(period time.Duration, minWeight float64) error {
+	return c.portfolio.setReallocation(period, minWeight)
+}
+
+func (p *portfolioHandler) setAllocation(weights map[string]float64) error {
+	total := 0.0
+	for s, w := range weights {
+		if w < 0 || math.IsNaN(w) {
+			return fmt.Errorf("Allocation of %v can't be negative or NaN. ", s)
+		}
+		total += w
+	}
+	if total > 1+1e-9 {
+		return errors.New("Sum of allocations can't be more than 1. ")
+	}
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	p.alloc.weights = make(map[string]float64, len(weights))
+	for s, w := range weights {
+		p.alloc.weights[s] = w
+	}
+	p.alloc.periodStart = time.Time{}
+	return nil
+}
+
+func (p *portfolioHandler) setReallocation(period time.Duration, minWeight float64) error {
+	if period < 0 {
+		return errors.New("Reallocation period can't be negative. ")
+	}
+	if minWeight < 0 || minWeight > 1 || math.IsNaN(minWeight) {
+		return errors.New("Min allocation should be between 0 and 1. ")
+	}
+	p.mut.Lock()
+	p.alloc.period = period
+	p.alloc.minWeight = minWeight
+	p.alloc.periodStart = time.Time{}
+	p.mut.Unlock()
+	return nil
+}
+
+//Allocation returns fraction of equity allocated to strategy of the symbol. It's 1 if allocation isn't set.
+func (p *portfolioHandler) Allocation(symbol string) float64 {
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	if p.alloc.weights == nil {
+		return 1
+	}
+	return p.alloc.weights[symbol]
+}
+
+//realizedPnL returns closed PnL of all trades of the symbol. Caller should hold the lock.
+func (p *portfolioHandler) realizedPnL(symbol string) float64 {
+	pnl := 0.0
+	for _, t := range p.trades {
+		if t.Type == FlatTrade || t.Ticker.Symbol != symbol {
+			continue
+		}
+		pnl += t.ClosedPnL
+	}
+	return pnl
+}
+
+//reallocate changes allocations by realized performance of strategies if reallocation period is passed
+func (p *portfolioHandler) reallocate(t time.Time) {
+	equity := p.Equity()
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	a := &p.alloc
+	if a.weights == nil || a.period == 0 {
+		return
+	}
+	if !a.periodStart.IsZero() && t.Before(a.periodStart.Add(a.period)) {
+		return
+	}
+
+	if !a.periodStart.IsZero() {
+		total, scored := 0.0, 0.0
+		scores := make(map[string]float64, len(a.weights))
+		for s, w := range a.weights {
+			total += w
+			score := w
+			if capital := equity * w; capital > 0 {
+				score = w * math.Max(0, 1+(p.realizedPnL(s)-a.startPnL[s])/capital)
+			}
+			scores[s] = math.Max(score, a.minWeight)
+			scored += scores[s]
+		}
+		if scored > 0 {
+			for s, score := range scores {
+				a.weights[s] = score * total / scored
+			}
+		}
+	}
+
+	a.periodStart = t
+	a.startPnL = make(map[string]float64, len(a.weights))
+	for s := range a.weights {
+		a.startPnL[s] = p.realizedPnL(s)
+	}
+}
+
+//AllocatedEquity returns part of the account equity allocated to the strategy
+func (b *BasicStrategy) AllocatedEquity() float64 {
+	return b.portfolio.Equity() * b.portfolio.Allocation(b.symbol.Symbol)
+}
@@ -0,0 +1,54 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBasicStrategy_AllocatedEquity(t *testing.T) {
+	st := newTestSizingStrategy(100000)
+	st.symbol.LotSize = 1
+	symbol := st.symbol.Symbol
+	assert.Equal(t, 100000.0, st.AllocatedEquity())
+
+	c := Engine{portfolio: st.portfolio}
+	assert.NotNil(t, c.SetAllocation(map[string]float64{symbol: -0.1}))
+	assert.NotNil(t, c.SetAllocation(map[string]float64{symbol: 0.6, "Other": 0.5}))
+	assert.Nil(t, c.SetAllocation(map[string]float64{symbol: 0.25, "Other": 0.75}))
+	assert.Equal(t, 25000.0, st.AllocatedEquity())
+	assert.Equal(t, int64(83), st.SizeFixedFractional(0.1, 30))
+
+	assert.Nil(t, c.SetAllocation(map[string]float64{"Other": 1}))
+	assert.Equal(t, 0.0, st.AllocatedEquity())
+	assert.Equal(t, int64(0), st.SizeFixedFractional(0.1, 30))
+}
+
+func TestPortfolio_reallocate(t *testing.T) {
+	p := newPortfolio()
+	p.setCapital(10000)
+	c := Engine{portfolio: p}
+	assert.NotNil(t, c.SetReallocation(-time.Hour, 0))
+	assert.NotNil(t, c.SetReallocation(time.Hour, 2))
+	assert.Nil(t, c.SetAllocation(map[string]float64{"A": 0.5, "B": 0.4}))
+	assert.Nil(t, c.SetReallocation(24*time.Hour, 0.1))
+
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	a := &Trade{Ticker: &Instrument{Symbol: "A"}, Type: ClosedTrade, ClosedPnL: 100}
+	b := &Trade{Ticker: &Instrument{Symbol: "B"}, Type: ClosedTrade, ClosedPnL: 100}
+	p.onNewTrade(a)
+	p.onNewTrade(b)
+	p.reallocate(tm)
+
+	a.ClosedPnL += 500
+	b.ClosedPnL -= 4000
+	p.reallocate(tm.Add(time.Hour))
+	assert.Equal(t, 0.5, p.Allocation("A"))
+
+	//A made 10% of allocated equity, B lost all and gets min allocation
+	p.reallocate(tm.Add(24 * time.Hour))
+	assert.InDelta(t, 0.9*0.55/0.65, p.Allocation("A"), 0.0000001)
+	assert.InDelta(t, 0.9*0.1/0.65, p.Allocation("B"), 0.0000001)
+	assert.Equal(t, 0.0, p.Allocation("C"))
+}
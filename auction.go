@@ -0,0 +1,220 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"errors"
+	"math"
+	"time"
+)
+
+//OpeningAuction configures simulation of the opening auction in backtests with ticks. BTM emits indicative
+//AuctionImbalanceEvent every Interval during Lead before the opening tick of every symbol and the final one
+//with the auction print at the time of the opening tick.
+type OpeningAuction struct {
+	Lead     time.Duration
+	Interval time.Duration
+}
+
+//IAuctionUserStrategy can be implemented by user strategy to be notified about opening auction of its symbol
+type IAuctionUserStrategy interface {
+	OnAuction(b *BasicStrategy, e *AuctionImbalanceEvent)
+}
+
+//SetAuction enables opening auction simulation. Nil disables it.
+func (m *BTM) SetAuction(a *OpeningAuction) error {
+	if a != nil && (a.Lead <= 0 || a.Interval <= 0 || a.Interval > a.Lead) {
+		return errors.New("Auction lead and interval should be positive, interval not longer than lead. ")
+	}
+	m.auction = a
+	return nil
+}
+
+//loadAuctions returns indicative and final auction events of all symbols for every day with opening tick
+func (m *BTM) loadAuctions() eventArray {
+	if m.auction == nil || m.mode == MarketDataModeCandles {
+		return nil
+	}
+	loadQuotes := m.mode == MarketDataModeTicksQuotes || m.mode == MarketDataModeQuotes
+	var events eventArray
+	for _, s := range m.Symbols {
+		prevClose := math.NaN()
+		for d := m.FromDate; !d.After(m.ToDate); d = d.AddDate(0, 0, 1) {
+			rng := marketdata.DateRange{
+				From: time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC),
+				To:   time.Date(d.Year(), d.Month(), d.Day(), 23, 59, 59, 59, time.UTC),
+			}
+			raw, err := m.Storage.GetStoredTicks(s.Symbol, rng, loadQuotes, true)
+			if err != nil && raw == nil {
+				m.newError(err)
+				continue
+			}
+			s.normalizeTicks(raw)
+			raw.Sort()
+			events = append(events, m.auction.events(s, raw, prevClose)...)
+			for _, t := range raw {
+				if t.HasTrade() {
+					prevClose = t.LastPrice
+				}
+			}
+		}
+	}
+	return events
+}
+
+//events builds auction events of the day. Indicative price is price of the last pre-open tick. Without
+//pre-open data it is modeled as moving from previous close to the opening print. Paired qty is growing
+//to the size of the print, imbalance is the rest of it on the side price has to move to.
+func (a *OpeningAuction) events(s *Instrument, ticks marketdata.TickArray, prevClose float64) eventArray {
+	var open *marketdata.Tick
+	var preOpen marketdata.TickArray
+	for _, t := range ticks {
+		if t.IsOpening && t.HasTrade() {
+			open = t
+			break
+		}
+		preOpen = append(preOpen, t)
+	}
+	if open == nil {
+		return nil
+	}
+	if math.IsNaN(prevClose) {
+		prevClose = open.LastPrice
+	}
+
+	var events eventArray
+	start := open.Datetime.Add(-a.Lead)
+	for t := start; t.Before(open.Datetime); t = t.Add(a.Interval) {
+		share := float64(t.Sub(start)) / float64(a.Lead)
+		price := prevClose + (open.LastPrice-prevClose)*share
+		for _, p := range preOpen {
+			if p.Datetime.After(t) {
+				break
+			}
+			switch {
+			case p.HasTrade():
+				price = p.LastPrice
+			case p.HasQuote():
+				price = (p.BidPrice + p.AskPrice) / 2
+			}
+		}
+		e := &AuctionImbalanceEvent{
+			BaseEvent:       be(t, s),
+			IndicativePrice: price,
+			PairedQty:       int64(float64(open.LastSize) * share),
+		}
+		e.ImbalanceQty = open.LastSize - e.PairedQty
+		switch {
+		case open.LastPrice > price:
+			e.ImbalanceSide = OrderBuy
+		case open.LastPrice < price:
+			e.ImbalanceSide = OrderSell
+		default:
+			e.ImbalanceQty = 0
+		}
+		events = append(events, e)
+	}
+	return append(events, &AuctionImbalanceEvent{
+		BaseEvent:       be(open.Datetime, s),
+		IndicativePrice: open.LastPrice,
+		PairedQty:       open.LastSize,
+		Final:           true,
+	})
+}
+
+func (b *simBrokerWorker) onAuction(e *AuctionImbalanceEvent) {
+	b.proceedStoredRequests(e.getTime())
+	b.findExecutions(e)
+}
+
+func (c *Engine) eAuction(e *AuctionImbalanceEvent) {
+	if c.broker.IsSimulated() {
+		c.broker.Notify(e)
+	}
+	if st, ok := c.findStrategy(e.getSymbol()); ok {
+		st.notify(e)
+	}
+}
+
+func (b *BasicStrategy) onAuctionHandler(e *AuctionImbalanceEvent) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	if e.getTime().After(b.mostRecentTime) {
+		b.mostRecentTime = e.getTime()
+	}
+	if us, ok := b.userStrategy.(IAuctionUserStrategy); ok {
+		us.OnAuction(b, e)
+	}
+}
+
+//SetAuctionParticipation limits fills of MOO and LOO orders on the auction print to the share of its qty,
+//e.g. 0.1 for 10%. The rest of the order is canceled. Zero means no limit for MOO orders and the whole print
+//for LOO ones.
+func (b *SimBroker) SetAuctionParticipation(share float64) error {
+	if share < 0 || share > 1 || math.IsNaN(share) {
+		return errors.New("Auction participation should be between 0 and 1. ")
+	}
+	b.auctionParticipation = share
+	if b.workersMut == nil {
+		return nil
+	}
+	b.workersMut.RLock()
+	defer b.workersMut.RUnlock()
+	for _, w := range b.workers {
+		w.mpMutext.Lock()
+		w.participation = share
+		w.mpMutext.Unlock()
+	}
+	return nil
+}
+
+//fillOnAuction fills MOO and LOO orders by the final auction print. Orders are filled or canceled by it, so
+//opening tick which comes after the print doesn't fill them again.
+func (b *simBrokerWorker) fillOnAuction(order *simBrokerOrder, e *AuctionImbalanceEvent) []event {
+	if !e.Final || (order.Type != MarketOnOpen && order.Type != LimitOnOpen) {
+		return nil
+	}
+	err := b.validateOrderForExecution(order, order.Type)
+	if err != nil {
+		b.newError(err)
+		return nil
+	}
+	t := b.genTimeRoundTrip(e.getTime())
+	cancel := &OrderCancelEvent{OrdId: order.Id, BaseEvent: be(t, order.Ticker)}
+	if order.Type == LimitOnOpen {
+		switch order.Side {
+		case OrderBuy:
+			if e.IndicativePrice > order.BrokerPrice {
+				return []event{cancel}
+			}
+		case OrderSell:
+			if e.IndicativePrice < order.BrokerPrice {
+				return []event{cancel}
+			}
+		}
+		if e.IndicativePrice == order.BrokerPrice && b.strictLimitOrders {
+			return []event{cancel}
+		}
+	}
+
+	leaves := order.qty() - order.BrokerExecQty
+	execQty := leaves
+	if b.participation > 0 || order.Type == LimitOnOpen {
+		size := e.PairedQty
+		if b.participation > 0 {
+			size = int64(float64(size) * b.participation)
+		}
+		if limit := order.Ticker.sizeToQty(size); execQty > limit {
+			execQty = limit
+		}
+	}
+	if execQty <= 0 {
+		return []event{cancel}
+	}
+	events := []event{&OrderFillEvent{OrdId: order.Id, Price: e.IndicativePrice, Qty: execQty,
+		BaseEvent: be(t, order.Ticker)}}
+	if execQty < leaves {
+		events = append(events, cancel)
+	}
+	return events
+}
@@ -0,0 +1,153 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"io/ioutil"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpeningAuction_events(t *testing.T) {
+	open := time.Date(2018, 3, 5, 9, 30, 0, 0, time.UTC)
+	a := &OpeningAuction{Lead: 10 * time.Minute, Interval: 5 * time.Minute}
+	inst := newTestInstrument()
+	ticks := marketdata.TickArray{
+		{Datetime: open, Symbol: inst.Symbol, LastPrice: 101, LastSize: 1000, IsOpening: true},
+		{Datetime: open.Add(time.Minute), Symbol: inst.Symbol, LastPrice: 101.5, LastSize: 100},
+	}
+
+	//Events are compared without sequence numbers
+	value := func(e event, tm time.Time) AuctionImbalanceEvent {
+		a := *e.(*AuctionImbalanceEvent)
+		assert.Equal(t, tm, a.getTime())
+		assert.Equal(t, inst, a.Ticker)
+		a.BaseEvent = BaseEvent{}
+		return a
+	}
+	events := a.events(inst, ticks, 100)
+	if assert.Len(t, events, 3) {
+		assert.Equal(t, AuctionImbalanceEvent{IndicativePrice: 100, ImbalanceQty: 1000, ImbalanceSide: OrderBuy},
+			value(events[0], open.Add(-10*time.Minute)))
+		assert.Equal(t, AuctionImbalanceEvent{IndicativePrice: 100.5, PairedQty: 500, ImbalanceQty: 500,
+			ImbalanceSide: OrderBuy}, value(events[1], open.Add(-5*time.Minute)))
+		assert.Equal(t, AuctionImbalanceEvent{IndicativePrice: 101, PairedQty: 1000, Final: true},
+			value(events[2], open))
+	}
+
+	//Pre-open trade sets indicative price
+	ticks = append(marketdata.TickArray{{Datetime: open.Add(-7 * time.Minute), Symbol: inst.Symbol, LastPrice: 102,
+		LastSize: 100}}, ticks...)
+	events = a.events(inst, ticks, math.NaN())
+	if assert.Len(t, events, 3) {
+		e := events[1].(*AuctionImbalanceEvent)
+		assert.Equal(t, 102.0, e.IndicativePrice)
+		assert.Equal(t, OrderSell, e.ImbalanceSide)
+		e = events[0].(*AuctionImbalanceEvent)
+		assert.Equal(t, 101.0, e.IndicativePrice)
+		assert.Equal(t, int64(0), e.ImbalanceQty)
+	}
+
+	assert.Nil(t, a.events(inst, ticks[2:], 100))
+}
+
+func TestSimBroker_fillOnAuction(t *testing.T) {
+	b := newTestSimBrokerWorker()
+	final := &AuctionImbalanceEvent{BaseEvent: be(newTestOrderTime(), b.symbol), IndicativePrice: 20,
+		PairedQty: 1000, Final: true}
+	moo := newTestDayBrokerOrder(math.NaN(), OrderBuy, 500, "1")
+	moo.Type = MarketOnOpen
+	moo.State = ConfirmedOrder
+	moo.StateUpdTime = newTestOpgOrderTime()
+
+	assert.Nil(t, b.fillOnAuction(moo, &AuctionImbalanceEvent{BaseEvent: final.BaseEvent, IndicativePrice: 20}))
+	events := b.fillOnAuction(moo, final)
+	if assert.Len(t, events, 1) {
+		fill := events[0].(*OrderFillEvent)
+		assert.Equal(t, 20.0, fill.Price)
+		assert.Equal(t, int64(500), fill.Qty)
+	}
+
+	//Participation limits fill qty, the rest is canceled
+	b.participation = 0.2
+	events = b.fillOnAuction(moo, final)
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, int64(200), events[0].(*OrderFillEvent).Qty)
+		assert.IsType(t, &OrderCancelEvent{}, events[1])
+	}
+	b.participation = 0.0001
+	events = b.fillOnAuction(moo, final)
+	if assert.Len(t, events, 1) {
+		assert.IsType(t, &OrderCancelEvent{}, events[0])
+	}
+
+	b.participation = 0
+	loo := newTestDayBrokerOrder(19.9, OrderBuy, 1500, "2")
+	loo.Type = LimitOnOpen
+	loo.State = ConfirmedOrder
+	events = b.fillOnAuction(loo, final)
+	if assert.Len(t, events, 1) {
+		assert.IsType(t, &OrderCancelEvent{}, events[0])
+	}
+	loo.BrokerPrice = 20.1
+	events = b.fillOnAuction(loo, final)
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, int64(1000), events[0].(*OrderFillEvent).Qty)
+		assert.IsType(t, &OrderCancelEvent{}, events[1])
+	}
+
+	sb := &SimBroker{}
+	assert.NotNil(t, sb.SetAuctionParticipation(1.5))
+	assert.Nil(t, sb.SetAuctionParticipation(0.5))
+}
+
+//auctionTestStrategy sends MOO order on the first indicative auction event and keeps auction events
+type auctionTestStrategy struct {
+	DummyStrategy
+	events []*AuctionImbalanceEvent
+}
+
+func (s *auctionTestStrategy) OnAuction(b *BasicStrategy, e *AuctionImbalanceEvent) {
+	if len(s.events) == 0 {
+		if _, err := b.NewMarketOnOpenOrder(OrderBuy, 5000, "Sim"); err != nil {
+			panic(err)
+		}
+	}
+	s.events = append(s.events, e)
+}
+
+func TestEngine_OpeningAuction(t *testing.T) {
+	dir, err := ioutil.TempDir("", "auction")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	md := newTestBTMforSyntheticTicks(dir, 1)
+	md.ToDate = md.FromDate.AddDate(0, 0, 2)
+	assert.NotNil(t, md.SetAuction(&OpeningAuction{Lead: time.Minute, Interval: time.Hour}))
+	assert.Nil(t, md.SetAuction(&OpeningAuction{Lead: 10 * time.Minute, Interval: 5 * time.Minute}))
+	broker := &SimBroker{}
+	assert.Nil(t, broker.SetAuctionParticipation(0.1))
+	st := &auctionTestStrategy{}
+	e, err := New(WithMarketData(md), WithBroker(broker), WithStrategy(&Instrument{Symbol: "Sym1"}, st, 1))
+	assert.Nil(t, err)
+	fills := recordFills(e)
+	e.SetDeterministic(true)
+	e.Run()
+	assert.Nil(t, e.Status().Err)
+
+	//Two trading days with two indicative events and auction print
+	if !assert.Len(t, st.events, 6) {
+		return
+	}
+	first, final := st.events[0], st.events[2]
+	assert.False(t, first.Final)
+	assert.True(t, final.Final)
+	assert.Equal(t, first.getTime().Add(10*time.Minute), final.getTime())
+	if result := fills.result(); assert.Len(t, result, 1) {
+		assert.Equal(t, final.IndicativePrice, result[0].Price)
+		assert.Equal(t, final.PairedQty/10, result[0].Qty)
+		assert.Equal(t, final.getTime(), result[0].Time)
+	}
+}
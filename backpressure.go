@@ -0,0 +1,378 @@
+package engine
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+)
+
+//BackpressurePolicy is what strategy queue does with market data when it's full
+type BackpressurePolicy string
+
+const (
+	//BackpressureBlock makes engine wait until strategy handles queued market data
+	BackpressureBlock BackpressurePolicy = "Block"
+	//BackpressureDrop drops market data and sends EventDroppedEvent to the engine
+	BackpressureDrop BackpressurePolicy = "Drop"
+	//BackpressureSpill writes market data to file and reads it back when strategy catches up
+	BackpressureSpill BackpressurePolicy = "Spill"
+)
+
+//BackpressureConfig sets queue between engine and every strategy. Without queue event loop of the strategy
+//holds single market data event, so engine waits for the previous handler of the strategy before it passes
+//the next one and one slow strategy stalls all of them.
+//Capacity limits number of ticks, candles, news and fundamentals in memory. Order, account, timer and other
+//events are never dropped or spilled and don't wait, but they are delivered in the same order with market data.
+type BackpressureConfig struct {
+	Capacity int
+	Policy   BackpressurePolicy
+	//SpillDir is folder of spill files. Temp folder is used if it's empty.
+	SpillDir string
+}
+
+func (cfg BackpressureConfig) validate() error {
+	if cfg.Capacity < 1 {
+		return errors.New("Queue capacity should be positive. ")
+	}
+	switch cfg.Policy {
+	case BackpressureBlock, BackpressureDrop, BackpressureSpill:
+		return nil
+	}
+	return fmt.Errorf("Unknown backpressure policy %v. ", cfg.Policy)
+}
+
+//QueueStats is state of the queue of the strategy. Depth is number of queued events, including spilled ones.
+type QueueStats struct {
+	Symbol    string
+	Strategy  string
+	Depth     int
+	MaxDepth  int
+	Spilled   int
+	Dropped   int
+	Delivered int
+}
+
+//queuedStrategy is implemented by strategies with event queues
+type queuedStrategy interface {
+	queueStats() []QueueStats
+}
+
+//SetBackpressure puts bounded queue between engine and every strategy. It should be called before Run.
+//Queues are not used in deterministic mode, where engine waits for every handler anyway.
+func (c *Engine) SetBackpressure(cfg BackpressureConfig) error {
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.backpressure = &cfg
+	for _, st := range c.strategiesMap {
+		st.setBackpressure(c.backpressure)
+	}
+	return nil
+}
+
+//QueueStats returns state of queues of all strategies by symbol
+func (c *Engine) QueueStats() []QueueStats {
+	c.mut.Lock()
+	var stats []QueueStats
+	for _, st := range c.strategiesMap {
+		if qs, ok := st.(queuedStrategy); ok {
+			stats = append(stats, qs.queueStats()...)
+		}
+	}
+	c.mut.Unlock()
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Symbol == stats[j].Symbol {
+			return stats[i].Strategy < stats[j].Strategy
+		}
+		return stats[i].Symbol < stats[j].Symbol
+	})
+	return stats
+}
+
+//isDroppableEvent returns true for market data which strategy can live without
+func isDroppableEvent(e event) bool {
+	switch e.(type) {
+	case *NewTickEvent, *CandleOpenEvent, *CandleCloseEvent, *NewsEvent, *FundamentalDataEvent:
+		return true
+	}
+	return false
+}
+
+//queueItem is queued event. Spilled event is read from spill file when its turn comes.
+type queueItem struct {
+	e       event
+	spilled bool
+}
+
+//strategyQueue passes events to strategy in goroutine, so engine doesn't wait for strategy handlers.
+//Goroutine runs only while queue has events.
+type strategyQueue struct {
+	cfg     BackpressureConfig
+	deliver func(e event)
+	dropped func(e event)
+	restore func(e Event)
+	cancel  func() <-chan struct{}
+	//wg is done when queued event is delivered
+	wg *sync.WaitGroup
+
+	mut     sync.Mutex
+	items   []queueItem
+	inMem   int
+	running bool
+	//freed is closed when droppable event leaves memory
+	freed chan struct{}
+	spill *spillFile
+	stats QueueStats
+}
+
+//push queues event. It returns false if event was dropped.
+func (q *strategyQueue) push(e event) bool {
+	q.mut.Lock()
+	droppable := isDroppableEvent(e)
+	for droppable && q.inMem >= q.cfg.Capacity && q.cfg.Policy == BackpressureBlock {
+		if q.freed == nil {
+			q.freed = make(chan struct{})
+		}
+		freed := q.freed
+		q.mut.Unlock()
+		select {
+		case <-freed:
+		case <-q.cancel():
+			return false
+		}
+		q.mut.Lock()
+	}
+
+	item := queueItem{e: e}
+	if droppable && q.inMem >= q.cfg.Capacity {
+		switch q.cfg.Policy {
+		case BackpressureDrop:
+			q.stats.Dropped++
+			q.mut.Unlock()
+			q.dropped(e)
+			return false
+		case BackpressureSpill:
+			if err := q.spillEvent(e); err != nil {
+				//Event stays in memory rather than lost
+				q.inMem++
+			} else {
+				item = queueItem{spilled: true}
+				q.stats.Spilled++
+			}
+		}
+	} else if droppable {
+		q.inMem++
+	}
+	q.items = append(q.items, item)
+	q.wg.Add(1)
+	if len(q.items) > q.stats.MaxDepth {
+		q.stats.MaxDepth = len(q.items)
+	}
+	start := !q.running
+	q.running = true
+	q.mut.Unlock()
+
+	if start {
+		go q.run()
+	}
+	return true
+}
+
+func (q *strategyQueue) spillEvent(e event) error {
+	ev, ok := e.(Event)
+	if !ok {
+		return errors.New("Event can't be spilled. ")
+	}
+	if q.spill == nil {
+		s, err := newSpillFile(q.cfg.SpillDir)
+		if err != nil {
+			return err
+		}
+		q.spill = s
+	}
+	return q.spill.write(ev)
+}
+
+//run delivers events until queue is empty
+func (q *strategyQueue) run() {
+	for {
+		q.mut.Lock()
+		if len(q.items) == 0 {
+			q.running = false
+			q.mut.Unlock()
+			return
+		}
+		item := q.items[0]
+		q.items = q.items[1:]
+		e := item.e
+		var err error
+		if item.spilled {
+			var ev Event
+			ev, err = q.spill.read()
+			if err == nil {
+				q.restore(ev)
+				e = ev
+			}
+		} else if isDroppableEvent(e) {
+			q.inMem--
+			if q.freed != nil {
+				close(q.freed)
+				q.freed = nil
+			}
+		}
+		q.mut.Unlock()
+
+		if err != nil {
+			q.dropped(nil)
+			q.wg.Done()
+			continue
+		}
+		q.deliver(e)
+
+		q.mut.Lock()
+		q.stats.Delivered++
+		q.mut.Unlock()
+		q.wg.Done()
+	}
+}
+
+func (q *strategyQueue) getStats() QueueStats {
+	q.mut.Lock()
+	defer q.mut.Unlock()
+	s := q.stats
+	s.Depth = len(q.items)
+	return s
+}
+
+//spillFile keeps spilled events in order they were written. Writes go straight to the file, so they are
+//visible to the reader.
+type spillFile struct {
+	path string
+	w    *os.File
+	r    *os.File
+	br   *bufio.Reader
+}
+
+func newSpillFile(dir string) (*spillFile, error) {
+	w, err := ioutil.TempFile(dir, "spill")
+	if err != nil {
+		return nil, err
+	}
+	r, err := os.Open(w.Name())
+	if err != nil {
+		w.Close()
+		return nil, err
+	}
+	return &spillFile{path: w.Name(), w: w, r: r, br: bufio.NewReader(r)}, nil
+}
+
+func (s *spillFile) write(e Event) error {
+	data, err := MarshalEvent(e)
+	if err != nil {
+		return err
+	}
+	_, err = s.w.Write(append(data, '\n'))
+	return err
+}
+
+func (s *spillFile) read() (Event, error) {
+	line, err := s.br.ReadBytes('\n')
+	if err != nil {
+		return nil, err
+	}
+	var ej EventJSON
+	if err := json.Unmarshal(line, &ej); err != nil {
+		return nil, err
+	}
+	if ej.Event == nil {
+		return nil, errors.New("Spill file has empty record. ")
+	}
+	return ej.Event, nil
+}
+
+func (s *spillFile) close() {
+	s.w.Close()
+	s.r.Close()
+	os.Remove(s.path)
+}
+
+//eventLoopConfig is config of event loop of the strategy without backpressure queue. Strategy handles all
+//kinds of events, market data, broker answers, timers and portfolio events, one by one in order of arrival,
+//so fill can't be handled in the middle of the tick handler and the next tick sees all fills which came
+//before it. Loop holds single market data event, order and other events don't wait.
+var eventLoopConfig = BackpressureConfig{Capacity: 1, Policy: BackpressureBlock}
+
+//setBackpressure creates queue of the strategy. Nil config removes it, so event loop is used again.
+func (b *BasicStrategy) setBackpressure(cfg *BackpressureConfig) {
+	if cfg == nil {
+		b.queue = nil
+		return
+	}
+	b.queue = b.newQueue(*cfg)
+}
+
+func (b *BasicStrategy) newQueue(cfg BackpressureConfig) *strategyQueue {
+	if b.handlersWaitGroup == nil {
+		b.handlersWaitGroup = &sync.WaitGroup{}
+	}
+	return &strategyQueue{
+		cfg:     cfg,
+		deliver: b.deliver,
+		dropped: b.onEventDropped,
+		restore: func(e Event) {
+			replaceInstruments(e, func(i *Instrument) *Instrument {
+				if i != nil && i.Symbol == b.symbol.Symbol {
+					return b.symbol
+				}
+				return i
+			})
+		},
+		cancel: func() <-chan struct{} {
+			return doneChan(b.ctx)
+		},
+		wg:    b.handlersWaitGroup,
+		stats: QueueStats{Symbol: b.symbol.Symbol, Strategy: b.name},
+	}
+}
+
+//onEventDropped tells engine that event wasn't delivered to the strategy. Event is nil if spilled event
+//couldn't be read back.
+func (b *BasicStrategy) onEventDropped(e event) {
+	dropped := &EventDroppedEvent{BaseEvent: be(b.mostRecentTime, b.symbol), Strategy: b.name}
+	if e != nil {
+		dropped.Time = e.getTime()
+		dropped.Event = e.getName()
+	}
+	b.handlersWaitGroup.Add(1)
+	go func() {
+		sendEvent(b.ctx, b.ch.events, dropped)
+		b.handlersWaitGroup.Done()
+	}()
+}
+
+func (b *BasicStrategy) queueStats() []QueueStats {
+	if b.queue == nil {
+		return nil
+	}
+	return []QueueStats{b.queue.getStats()}
+}
+
+//closeQueue removes spill file of the queue
+func (b *BasicStrategy) closeQueue() {
+	if b.queue == nil {
+		return
+	}
+	b.queue.mut.Lock()
+	defer b.queue.mut.Unlock()
+	if b.queue.spill != nil {
+		b.queue.spill.close()
+		b.queue.spill = nil
+	}
+}
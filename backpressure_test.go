@@ -0,0 +1,217 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//slowTestConsumer holds delivery of every event until it's released
+type slowTestConsumer struct {
+	mut       sync.Mutex
+	started   chan event
+	release   chan struct{}
+	delivered []event
+	dropped   []event
+}
+
+func newSlowTestConsumer() *slowTestConsumer {
+	return &slowTestConsumer{started: make(chan event, 100), release: make(chan struct{})}
+}
+
+func (s *slowTestConsumer) deliver(e event) {
+	s.started <- e
+	<-s.release
+	s.mut.Lock()
+	s.delivered = append(s.delivered, e)
+	s.mut.Unlock()
+}
+
+func (s *slowTestConsumer) drop(e event) {
+	s.mut.Lock()
+	s.dropped = append(s.dropped, e)
+	s.mut.Unlock()
+}
+
+func newTestStrategyQueue(cfg BackpressureConfig, c *slowTestConsumer) *strategyQueue {
+	return &strategyQueue{
+		cfg:     cfg,
+		deliver: c.deliver,
+		dropped: c.drop,
+		restore: func(e Event) {},
+		cancel:  func() <-chan struct{} { return nil },
+		wg:      &sync.WaitGroup{},
+	}
+}
+
+func newTestQueueCandles(n int) []event {
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	var events []event
+	for i := 0; i < n; i++ {
+		events = append(events, newTestCandleCloseEvent(10, 20, 9, 10+float64(i), tm.Add(time.Duration(i)*time.Minute), "1"))
+	}
+	return events
+}
+
+func TestStrategyQueue_Drop(t *testing.T) {
+	c := newSlowTestConsumer()
+	q := newTestStrategyQueue(BackpressureConfig{Capacity: 2, Policy: BackpressureDrop}, c)
+	candles := newTestQueueCandles(4)
+
+	assert.True(t, q.push(candles[0]))
+	<-c.started
+	assert.True(t, q.push(candles[1]))
+	assert.True(t, q.push(candles[2]))
+	assert.False(t, q.push(candles[3]))
+	//Broker events are never dropped
+	fill := &OrderFillEvent{BaseEvent: be(candles[3].getTime(), newTestInstrument()), OrdId: "1"}
+	assert.True(t, q.push(fill))
+	assert.Equal(t, 3, q.getStats().Depth)
+
+	close(c.release)
+	q.wg.Wait()
+	assert.Equal(t, []event{candles[0], candles[1], candles[2], fill}, c.delivered)
+	assert.Equal(t, []event{candles[3]}, c.dropped)
+	stats := q.getStats()
+	assert.Equal(t, 0, stats.Depth)
+	assert.Equal(t, 3, stats.MaxDepth)
+	assert.Equal(t, 1, stats.Dropped)
+	assert.Equal(t, 4, stats.Delivered)
+}
+
+func TestStrategyQueue_Block(t *testing.T) {
+	c := newSlowTestConsumer()
+	q := newTestStrategyQueue(BackpressureConfig{Capacity: 1, Policy: BackpressureBlock}, c)
+	candles := newTestQueueCandles(3)
+
+	q.push(candles[0])
+	<-c.started
+	q.push(candles[1])
+	pushed := make(chan struct{})
+	go func() {
+		q.push(candles[2])
+		close(pushed)
+	}()
+
+	select {
+	case <-pushed:
+		t.Fatal("Push should wait for free space")
+	case <-time.After(20 * time.Millisecond):
+	}
+	c.release <- struct{}{}
+	<-pushed
+	close(c.release)
+	q.wg.Wait()
+	assert.Equal(t, candles, c.delivered)
+	assert.Len(t, c.dropped, 0)
+}
+
+func TestStrategyQueue_Spill(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spill_test")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	c := newSlowTestConsumer()
+	q := newTestStrategyQueue(BackpressureConfig{Capacity: 1, Policy: BackpressureSpill, SpillDir: dir}, c)
+	candles := newTestQueueCandles(4)
+	q.push(candles[0])
+	<-c.started
+	for _, e := range candles[1:] {
+		assert.True(t, q.push(e))
+	}
+	stats := q.getStats()
+	assert.Equal(t, 2, stats.Spilled)
+	assert.Equal(t, 3, stats.Depth)
+
+	close(c.release)
+	q.wg.Wait()
+	if assert.Len(t, c.delivered, 4) {
+		for i, e := range c.delivered {
+			assert.Equal(t, candles[i].getTime(), e.getTime())
+			assert.Equal(t, candles[i].(*CandleCloseEvent).Candle.Close, e.(*CandleCloseEvent).Candle.Close)
+		}
+	}
+	assert.Len(t, c.dropped, 0)
+	q.spill.close()
+}
+
+func TestBasicStrategy_Backpressure(t *testing.T) {
+	st := newTestBasicStrategy()
+	us := &synchronousTestStrategy{}
+	st.userStrategy = us
+	st.nPeriods = 1
+	st.setBackpressure(&BackpressureConfig{Capacity: 10, Policy: BackpressureDrop})
+
+	for _, e := range newTestQueueCandles(3) {
+		st.notify(e)
+	}
+	st.shutDown()
+	assert.Equal(t, 3, us.candles)
+	stats := st.queueStats()
+	if assert.Len(t, stats, 1) {
+		assert.Equal(t, "Test", stats[0].Symbol)
+		assert.Equal(t, 3, stats[0].Delivered)
+	}
+}
+
+func TestEngine_SetBackpressure(t *testing.T) {
+	st := newTestBasicStrategy()
+	c := Engine{strategiesMap: map[string]ICoreStrategy{st.symbol.Symbol: st}, mut: &sync.Mutex{}}
+	assert.NotNil(t, c.SetBackpressure(BackpressureConfig{Capacity: 0, Policy: BackpressureDrop}))
+	assert.NotNil(t, c.SetBackpressure(BackpressureConfig{Capacity: 10, Policy: "Wait"}))
+	assert.Len(t, c.QueueStats(), 0)
+
+	assert.Nil(t, c.SetBackpressure(BackpressureConfig{Capacity: 10, Policy: BackpressureSpill}))
+	assert.NotNil(t, st.queue)
+	assert.Equal(t, []QueueStats{{Symbol: "Test"}}, c.QueueStats())
+}
+
+//blockingTickTestStrategy holds the first OnTick until it's released and remembers position in every OnTick
+type blockingTickTestStrategy struct {
+	DummyStrategy
+	started   chan struct{}
+	release   chan struct{}
+	positions []int64
+}
+
+func (s *blockingTickTestStrategy) OnTick(b *BasicStrategy, tick *Tick) {
+	if len(s.positions) == 0 {
+		s.started <- struct{}{}
+		<-s.release
+	}
+	s.positions = append(s.positions, b.Position())
+}
+
+func TestBasicStrategy_EventLoopOrder(t *testing.T) {
+	st := newTestBasicStrategy()
+	us := &blockingTickTestStrategy{started: make(chan struct{}), release: make(chan struct{})}
+	st.userStrategy = us
+	st.nPeriods = 1
+	st.ch.events = make(chan event, 10)
+
+	order := newTestOrder(10, OrderBuy, 100, "id1")
+	order.Ticker = st.symbol
+	assert.Nil(t, st.newOrder(order))
+	st.onOrderConfirmHandler(&OrderConfirmationEvent{OrdId: order.Id, BaseEvent: be(time.Now(), st.symbol)})
+
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	newTick := func(t time.Time) *NewTickEvent {
+		return &NewTickEvent{BaseEvent: be(t, st.symbol), Tick: &Tick{Tick: &marketdata.Tick{Datetime: t,
+			Symbol: "Test", LastPrice: 10, LastSize: 100, BidPrice: 9.99, AskPrice: 10.01}, Ticker: st.symbol}}
+	}
+	st.notify(newTick(tm))
+	<-us.started
+	//Fill doesn't wait for the tick handler and isn't handled in the middle of it
+	st.notify(&OrderFillEvent{OrdId: order.Id, Price: 10, Qty: 100, BaseEvent: be(tm, st.symbol)})
+	st.notify(newTick(tm.Add(time.Second)))
+	close(us.release)
+	st.shutDown()
+
+	assert.Equal(t, []int64{0, 100}, us.positions)
+	assert.Nil(t, st.queueStats())
+}
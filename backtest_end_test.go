@@ -0,0 +1,48 @@
+package engine
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+type backtestEndTestStrategy struct {
+	DummyStrategy
+	calls *[]string
+}
+
+func (s *backtestEndTestStrategy) OnBacktestEnd(b *BasicStrategy) {
+	*s.calls = append(*s.calls, b.symbol.Symbol)
+	if _, err := b.NewMarketOrder(OrderSell, 100, DayTIF, "Sim"); err != nil {
+		panic(err)
+	}
+}
+
+func TestEngine_eEndOfData(t *testing.T) {
+	var calls []string
+	events := make(chan event, 10)
+	strategies := make(map[string]ICoreStrategy)
+	tm := time.Date(2018, 3, 5, 16, 0, 0, 0, time.UTC)
+	for _, s := range []string{"B", "A"} {
+		st := NewBasicStrategy(&Instrument{Symbol: s}, 1, &backtestEndTestStrategy{calls: &calls})
+		st.init(CoreStrategyChannels{errors: make(chan error), events: events,
+			portfolio: make(chan *PortfolioNewPositionEvent, 1)})
+		st.mostRecentTime = tm
+		strategies[s] = st
+	}
+	c := Engine{strategiesMap: strategies, mut: &sync.Mutex{}, waitG: &sync.WaitGroup{},
+		terminationChan: make(chan struct{}, 1), portfolio: newPortfolio()}
+	_, ok := c.PerformanceReport()
+	assert.False(t, ok)
+
+	c.eEndOfData(&EndOfDataEvent{BaseEvent: be(time.Now(), &Instrument{})})
+	<-c.terminationChan
+	assert.Equal(t, []string{"A", "B"}, calls)
+	assert.Len(t, events, 2)
+	_, ok = c.PerformanceReport()
+	assert.True(t, ok)
+	for _, st := range strategies {
+		assert.Equal(t, tm, st.(*BasicStrategy).mostRecentTime)
+	}
+}
@@ -0,0 +1,130 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"errors"
+	"fmt"
+	"math"
+)
+
+const tradingDaysPerYear = 252
+
+//BenchmarkReport compares daily returns of the account with returns of the benchmark set with
+//Engine.SetBenchmark. Returns are measured between days both series have data. Alpha and InformationRatio
+//are annualized with 252 trading days. RelativeDrawdown is max drawdown of account equity divided by
+//benchmark price, as fraction.
+type BenchmarkReport struct {
+	Symbol           string
+	Days             int
+	Return           float64
+	BenchmarkReturn  float64
+	Alpha            float64
+	Beta             float64
+	TrackingError    float64
+	InformationRatio float64
+	RelativeDrawdown float64
+}
+
+//BenchmarkReport loads daily candles of the benchmark for period of equity curve from storage and compares
+//them with the account. Storage of BTM market data is used if storage is nil.
+func (c *Engine) BenchmarkReport(storage marketdata.Storage) (BenchmarkReport, error) {
+	c.portfolio.mut.RLock()
+	symbol := c.portfolio.benchmark
+	c.portfolio.mut.RUnlock()
+	if symbol == "" {
+		return BenchmarkReport{}, errors.New("Benchmark is not set. ")
+	}
+	if storage == nil {
+		if m, ok := c.md.(*BTM); ok {
+			storage = m.Storage
+		}
+	}
+	if storage == nil {
+		return BenchmarkReport{}, errors.New("No storage to load benchmark from. ")
+	}
+	days := c.portfolio.DailyReturns()
+	if len(days) == 0 {
+		return BenchmarkReport{}, errors.New("Equity curve is empty. ")
+	}
+	candles, err := storage.GetStoredCandles(symbol, "D", marketdata.DateRange{From: days[0].Date,
+		To: days[len(days)-1].Date})
+	if err != nil {
+		return BenchmarkReport{}, fmt.Errorf("Can't load benchmark %v: %v", symbol, err)
+	}
+	return compareWithBenchmark(symbol, days, candles), nil
+}
+
+//compareWithBenchmark calculates benchmark report from account equity and benchmark close of common days
+func compareWithBenchmark(symbol string, days []DailyReturn, candles marketdata.CandleArray) BenchmarkReport {
+	closes := make(map[string]float64)
+	for _, cn := range candles {
+		price := cn.AdjClose
+		if price <= 0 {
+			price = cn.Close
+		}
+		if price <= 0 || math.IsNaN(price) {
+			continue
+		}
+		closes[cn.Datetime.Format(calendarDateLayout)] = price
+	}
+
+	var equity, prices []float64
+	for _, d := range days {
+		if price, ok := closes[d.Date.Format(calendarDateLayout)]; ok {
+			equity = append(equity, d.Equity)
+			prices = append(prices, price)
+		}
+	}
+
+	r := BenchmarkReport{Symbol: symbol, Days: len(equity)}
+	nan := math.NaN()
+	r.Return, r.BenchmarkReturn, r.Alpha, r.Beta = nan, nan, nan, nan
+	r.TrackingError, r.InformationRatio, r.RelativeDrawdown = nan, nan, nan
+	if len(equity) < 2 || equity[0] <= 0 {
+		return r
+	}
+	r.Return = equity[len(equity)-1]/equity[0] - 1
+	r.BenchmarkReturn = prices[len(prices)-1]/prices[0] - 1
+
+	r.RelativeDrawdown = 0
+	peak := 0.0
+	for i := range equity {
+		rel := equity[i] / prices[i]
+		peak = math.Max(peak, rel)
+		if dd := 1 - rel/peak; dd > r.RelativeDrawdown {
+			r.RelativeDrawdown = dd
+		}
+	}
+
+	returns := make([]float64, 0, len(equity)-1)
+	bench := make([]float64, 0, len(equity)-1)
+	active := make([]float64, 0, len(equity)-1)
+	for i := 1; i < len(equity); i++ {
+		returns = append(returns, equity[i]/equity[i-1]-1)
+		bench = append(bench, prices[i]/prices[i-1]-1)
+		active = append(active, returns[i-1]-bench[i-1])
+	}
+	if len(returns) < 2 {
+		return r
+	}
+	cov, _, vb := covariance(returns, bench)
+	if vb > 0 {
+		r.Beta = cov / vb
+		r.Alpha = (mean(returns) - r.Beta*mean(bench)) * tradingDaysPerYear
+	}
+	activeReturns := returnsTracker{returns: active}
+	m, std := activeReturns.meanStdDev()
+	r.TrackingError = std * math.Sqrt(tradingDaysPerYear)
+	if std > 0 {
+		r.InformationRatio = m / std * math.Sqrt(tradingDaysPerYear)
+	}
+	return r
+}
+
+func mean(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
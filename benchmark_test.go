@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//benchmarkTestStorage returns the same candles for any request
+type benchmarkTestStorage struct {
+	candles marketdata.CandleArray
+}
+
+func (s *benchmarkTestStorage) GetStoredTicks(symbol string, dRange marketdata.DateRange, quotes bool,
+	trades bool) (marketdata.TickArray, error) {
+	return nil, nil
+}
+
+func (s *benchmarkTestStorage) GetStoredCandles(symbol string, tf string,
+	dRange marketdata.DateRange) (marketdata.CandleArray, error) {
+	return s.candles, nil
+}
+
+func newTestBenchmarkStorage(day time.Time) *benchmarkTestStorage {
+	s := &benchmarkTestStorage{}
+	//Benchmark has no data of the third day
+	for i, price := range []float64{100, 105, 99.75, 109.725} {
+		d := i
+		if i >= 2 {
+			d++
+		}
+		s.candles = append(s.candles, &marketdata.Candle{Datetime: day.AddDate(0, 0, d).Add(16 * time.Hour),
+			Symbol: "SPY", Close: price})
+	}
+	return s
+}
+
+func TestCompareWithBenchmark(t *testing.T) {
+	day := time.Date(2018, 3, 5, 0, 0, 0, 0, time.UTC)
+	var days []DailyReturn
+	for i, eq := range []float64{100, 110, 105, 99, 118.8} {
+		days = append(days, DailyReturn{Date: day.AddDate(0, 0, i), Equity: eq})
+	}
+
+	//Account moves twice as much as benchmark
+	r := compareWithBenchmark("SPY", days, newTestBenchmarkStorage(day).candles)
+	assert.Equal(t, "SPY", r.Symbol)
+	assert.Equal(t, 4, r.Days)
+	assert.InDelta(t, 0.188, r.Return, 0.0000001)
+	assert.InDelta(t, 0.09725, r.BenchmarkReturn, 0.0000001)
+	assert.InDelta(t, 2.0, r.Beta, 0.0000001)
+	assert.InDelta(t, 0.0, r.Alpha, 0.0000001)
+	assert.InDelta(t, 1-(99.0/99.75)/(110.0/105), r.RelativeDrawdown, 0.0000001)
+
+	active := []float64{0.05, -0.05, 0.1}
+	m := (active[0] + active[1] + active[2]) / 3
+	std := math.Sqrt(((active[0]-m)*(active[0]-m) + (active[1]-m)*(active[1]-m) + (active[2]-m)*(active[2]-m)) / 2)
+	assert.InDelta(t, std*math.Sqrt(252), r.TrackingError, 0.0000001)
+	assert.InDelta(t, m/std*math.Sqrt(252), r.InformationRatio, 0.0000001)
+
+	r = compareWithBenchmark("SPY", days[:1], newTestBenchmarkStorage(day).candles)
+	assert.True(t, math.IsNaN(r.Beta))
+	assert.True(t, math.IsNaN(r.Return))
+}
+
+func TestEngine_BenchmarkReport(t *testing.T) {
+	c := newTestEquityEngine()
+	day := time.Date(2018, 3, 5, 0, 0, 0, 0, time.UTC)
+	storage := newTestBenchmarkStorage(day)
+	_, err := c.BenchmarkReport(storage)
+	assert.NotNil(t, err)
+
+	c.SetBenchmark("SPY")
+	_, err = c.BenchmarkReport(nil)
+	assert.NotNil(t, err)
+	_, err = c.BenchmarkReport(storage)
+	assert.NotNil(t, err)
+
+	for i, eq := range []float64{10000, 11000, 10500, 9900, 11880} {
+		c.portfolio.cash = eq
+		c.portfolio.recordEquity(day.AddDate(0, 0, i).Add(16 * time.Hour))
+	}
+	c.md = &BTM{Storage: storage}
+	r, err := c.BenchmarkReport(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, r.Days)
+	assert.InDelta(t, 2.0, r.Beta, 0.0000001)
+
+	var buf bytes.Buffer
+	assert.Nil(t, c.WriteHTMLReport(&buf))
+	assert.Contains(t, buf.String(), "<h2>Benchmark SPY</h2>")
+}
@@ -0,0 +1,87 @@
+package engine
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+//BorrowRate is annual borrow fee of the symbol and rebate paid on short sale proceeds, e.g. 0.03 for 3%,
+//effective from given time until the next rate
+type BorrowRate struct {
+	From   time.Time
+	Fee    float64
+	Rebate float64
+}
+
+//borrowState keeps borrow rate schedule and borrow cost not yet charged from portfolio cash
+type borrowState struct {
+	rates   []BorrowRate
+	pending float64
+}
+
+//SetBorrowRates sets schedule of borrow rates of the strategy symbol. Net cost of open short position is accrued
+//for every day it's held. It reduces closed PnL of the trade and is charged from portfolio cash.
+func (b *BasicStrategy) SetBorrowRates(rates []BorrowRate) error {
+	for _, r := range rates {
+		if r.Fee < 0 || r.Rebate < 0 || math.IsNaN(r.Fee) || math.IsNaN(r.Rebate) {
+			return errors.New("Borrow fee and rebate can't be negative or NaN. ")
+		}
+	}
+	sorted := append([]BorrowRate{}, rates...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].From.Before(sorted[j].From)
+	})
+	b.borrow.rates = sorted
+	return nil
+}
+
+//rate returns net annual borrow rate effective at given time
+func (s *borrowState) rate(t time.Time) float64 {
+	rate := 0.0
+	for _, r := range s.rates {
+		if r.From.After(t) {
+			break
+		}
+		rate = r.Fee - r.Rebate
+	}
+	return rate
+}
+
+//accrueBorrow charges short trade for every day passed since the last accrual
+func (b *BasicStrategy) accrueBorrow(t *Trade, now time.Time) {
+	if t.Type != ShortTrade || len(b.borrow.rates) == 0 {
+		return
+	}
+	day := now.Truncate(24 * time.Hour)
+	if t.borrowDay.IsZero() {
+		t.borrowDay = t.OpenTime.Truncate(24 * time.Hour)
+	}
+	for t.borrowDay.Before(day) {
+		t.borrowDay = t.borrowDay.Add(24 * time.Hour)
+		cost := t.toAccount(t.MarketValue) * b.borrow.rate(t.borrowDay) / 365
+		t.BorrowCost += cost
+		t.ClosedPnL -= cost
+		b.borrow.pending += cost
+	}
+}
+
+//takeBorrowCost returns borrow cost which should be charged from portfolio cash
+func (b *BasicStrategy) takeBorrowCost() float64 {
+	cost := b.borrow.pending
+	b.borrow.pending = 0
+	return cost
+}
+
+//BorrowCost returns net borrow cost of all short trades of the strategy in account currency
+func (b *BasicStrategy) BorrowCost() float64 {
+	cost := 0.0
+	for _, t := range b.closedTrades {
+		cost += t.BorrowCost
+	}
+	for _, t := range b.trades() {
+		cost += t.BorrowCost
+	}
+	return cost
+}
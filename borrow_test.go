@@ -0,0 +1,47 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBasicStrategy_accrueBorrow(t *testing.T) {
+	st := newTestMarginStrategy(10000)
+	st.symbol.InitialMargin, st.symbol.MaintenanceMargin = 0, 0
+	tm := st.mostRecentTime
+	day := tm.Truncate(24 * time.Hour)
+
+	assert.NotNil(t, st.SetBorrowRates([]BorrowRate{{Fee: -0.01}}))
+	assert.Nil(t, st.SetBorrowRates([]BorrowRate{
+		{From: day.AddDate(0, 0, 2), Fee: 0.073},
+		{Fee: 0.05, Rebate: 0.0135},
+	}))
+
+	fillTestOrder(st, OrderSell, 100, 50)
+	assert.InDelta(t, 15000.0, st.Cash(), 0.0000001)
+
+	//Nothing is accrued on the open day
+	st.notify(newTestCandleCloseEvent(50, 50, 50, 50, tm.Add(time.Hour), "1"))
+	st.shutDown()
+	assert.Equal(t, 0.0, st.BorrowCost())
+
+	st.notify(newTestCandleCloseEvent(50, 50, 50, 50, tm.Add(24*time.Hour), "1"))
+	st.shutDown()
+	assert.InDelta(t, 0.5, st.BorrowCost(), 0.0000001)
+	assert.InDelta(t, -0.5, st.currentTrade.ClosedPnL, 0.0000001)
+	assert.InDelta(t, 14999.5, st.Cash(), 0.0000001)
+
+	//New rate is used from its date
+	st.notify(newTestCandleCloseEvent(50, 50, 50, 50, tm.Add(48*time.Hour), "1"))
+	st.shutDown()
+	assert.InDelta(t, 1.5, st.BorrowCost(), 0.0000001)
+	assert.InDelta(t, 14998.5, st.Cash(), 0.0000001)
+
+	//Long position isn't charged
+	fillTestOrder(st, OrderBuy, 200, 50)
+	st.notify(newTestCandleCloseEvent(50, 50, 50, 50, tm.Add(72*time.Hour), "1"))
+	st.shutDown()
+	assert.InDelta(t, 1.5, st.BorrowCost(), 0.0000001)
+}
@@ -1,6 +1,7 @@
 package engine
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math"
@@ -13,7 +14,7 @@ type eventArray []event
 
 func (t eventArray) sort() {
 	sort.SliceStable(t, func(i, j int) bool {
-		return t[i].getTime().Unix() < t[j].getTime().Unix()
+		return eventBefore(t[i], t[j])
 	})
 }
 
@@ -32,6 +33,16 @@ type simBrokerOrder struct {
 	StateUpdTime  time.Time
 	BrokerExecQty int64
 	BrokerPrice   float64
+	//BrokerQty is order qty after replacement. Zero means that qty wasn't replaced.
+	BrokerQty int64
+}
+
+//qty returns order qty on broker side. Linked order gets new qty only when strategy gets OrderReplacedEvent.
+func (o *simBrokerOrder) qty() int64 {
+	if o.BrokerQty > 0 {
+		return o.BrokerQty
+	}
+	return o.Qty
 }
 
 func (o *simBrokerOrder) getExpirationTime() time.Time {
@@ -39,18 +50,28 @@ func (o *simBrokerOrder) getExpirationTime() time.Time {
 	case GTCTIF:
 		return o.Time.AddDate(10, 0, 0)
 	case DayTIF:
-		nextDay := o.Time.AddDate(0, 0, 1)
-		return time.Date(nextDay.Year(), nextDay.Month(), nextDay.Day(), 0, 0, 0, 0, o.Time.Location())
+		//Engine cancels day orders at session close by EndOfDayEvent. Midnight is expiration for data without it.
+		//Orders placed on non trading day live until the end of the next session
+		day := o.Ticker.Exchange.LocalTime(o.Time)
+		if !o.Ticker.Exchange.IsTradingDay(day) {
+			day = o.Ticker.Exchange.NextTradingDay(day)
+		}
+		nextDay := day.AddDate(0, 0, 1)
+		return time.Date(nextDay.Year(), nextDay.Month(), nextDay.Day(), 0, 0, 0, 0, day.Location())
 	case AuctionTIF:
+		day := o.Ticker.Exchange.LocalTime(o.Time)
+		if !o.Ticker.Exchange.IsTradingDay(day) {
+			day = o.Ticker.Exchange.NextTradingDay(day)
+		}
 		if o.Type == MarketOnOpen || o.Type == LimitOnOpen {
 			mot := o.Ticker.Exchange.MarketOpenTime
-			t := time.Date(o.Time.Year(), o.Time.Month(), o.Time.Day(), mot.Hour, mot.Minute, mot.Second, 0, o.Time.Location())
+			t := time.Date(day.Year(), day.Month(), day.Day(), mot.Hour, mot.Minute, mot.Second, 0, day.Location())
 			return t.Add(3 * time.Minute)
 		}
 
 		if o.Type == MarketOnClose || o.Type == LimitOnClose {
-			mct := o.Ticker.Exchange.MarketCloseTime
-			t := time.Date(o.Time.Year(), o.Time.Month(), o.Time.Day(), mct.Hour, mct.Minute, mct.Second, 0, o.Time.Location())
+			mct := o.Ticker.Exchange.CloseTimeOf(day)
+			t := time.Date(day.Year(), day.Month(), day.Day(), mct.Hour, mct.Minute, mct.Second, 0, day.Location())
 			return t.Add(3 * time.Second)
 		}
 
@@ -79,15 +100,48 @@ type SimBroker struct {
 	delay                  int64
 	checkExecutionsOnTicks bool
 	strictLimitOrders      bool
+	costs                  CostModel
+	strict                 bool
 	workers                map[string]*simBrokerWorker
+	workersMut             *sync.RWMutex
+	errChan                chan error
+	events                 chan event
+	ctx                    context.Context
+	logger                 ILogger
+	poolSize               int
+	shards                 []*brokerShard
+	synchronous            bool
+	auctionParticipation   float64
 }
 
 func (b *SimBroker) Connect() {
-	fmt.Println("SimBroker connected")
+	b.logMessage("SimBroker connected")
 }
 
 func (b *SimBroker) Disconnect() {
-	fmt.Println("SimBroker connected")
+	b.logMessage("SimBroker disconnected")
+}
+
+//SetLogger sets logger of broker messages, errors and generated events. Without logger messages are printed
+//to stdout.
+func (b *SimBroker) SetLogger(l ILogger) {
+	b.logger = l
+	if b.workersMut == nil {
+		return
+	}
+	b.workersMut.Lock()
+	defer b.workersMut.Unlock()
+	for _, w := range b.workers {
+		w.logger = l
+	}
+}
+
+func (b *SimBroker) logMessage(message string) {
+	if b.logger == nil {
+		fmt.Println(message)
+		return
+	}
+	b.logger.Log(InfoLevel, message, nil)
 }
 
 func (b *SimBroker) Init(errChan chan error, events chan event, symbols []*Instrument) {
@@ -95,34 +149,193 @@ func (b *SimBroker) Init(errChan chan error, events chan event, symbols []*Instr
 		panic("No symbols specified")
 	}
 	b.workers = make(map[string]*simBrokerWorker)
+	b.workersMut = &sync.RWMutex{}
+	b.errChan = errChan
+	b.events = events
+	b.initPool()
 
 	for _, s := range symbols {
-		bw := simBrokerWorker{
+		b.workers[s.Symbol] = b.newWorker(s)
+	}
+}
+
+//newWorker creates worker of the symbol. Worker of the pool uses mutex and wait group of its shard and gets
+//orders map with the first order.
+func (b *SimBroker) newWorker(s *Instrument) *simBrokerWorker {
+	if shard := b.shardOf(s.Symbol); shard != nil {
+		return &simBrokerWorker{
 			symbol:            s,
-			errChan:           errChan,
-			events:            events,
+			errChan:           b.errChan,
+			events:            b.events,
+			ctx:               b.ctx,
 			delay:             b.delay,
 			strictLimitOrders: b.strictLimitOrders,
-			mpMutext:          &sync.RWMutex{},
-			waitGroup:         &sync.WaitGroup{},
-			orders:            make(map[string]*simBrokerOrder),
+			costs:             b.costs,
+			strict:            b.strict,
+			logger:            b.logger,
+			participation:     b.auctionParticipation,
+			mpMutext:          &shard.workerMut,
+			waitGroup:         &shard.workerWG,
+		}
+	}
+	bw := simBrokerWorker{
+		symbol:            s,
+		errChan:           b.errChan,
+		events:            b.events,
+		ctx:               b.ctx,
+		delay:             b.delay,
+		strictLimitOrders: b.strictLimitOrders,
+		costs:             b.costs,
+		strict:            b.strict,
+		logger:            b.logger,
+		participation:     b.auctionParticipation,
+		mpMutext:          &sync.RWMutex{},
+		waitGroup:         &sync.WaitGroup{},
+		orders:            make(map[string]*simBrokerOrder),
+	}
+	return &bw
+}
+
+func (b *SimBroker) Notify(e event) {
+	switch i := e.(type) {
+	case *SymbolAddedEvent:
+		b.onSymbolAdded(i)
+		return
+	case *SymbolRemovedEvent:
+		b.onSymbolRemoved(i)
+		return
+	case *OptionChainEvent:
+		b.onOptionChain(i)
+		return
+	case *DelistingEvent:
+		b.removeWorker(i, "Sim Broker: symbol is delisted")
+		return
+	}
+
+	b.workersMut.RLock()
+	w, ok := b.workers[e.getSymbol()]
+	b.workersMut.RUnlock()
+	if !ok {
+		b.onEventForUnknownSymbol(e)
+		return
+	}
+	b.dispatch(e.getSymbol(), func() { w.notify(e) })
+}
+
+//onSymbolAdded spins up worker for the symbol which entered the universe
+func (b *SimBroker) onSymbolAdded(e *SymbolAddedEvent) {
+	b.workersMut.Lock()
+	defer b.workersMut.Unlock()
+	if _, ok := b.workers[e.getSymbol()]; ok {
+		return
+	}
+	b.workers[e.getSymbol()] = b.newWorker(e.Ticker)
+}
+
+//onSymbolRemoved cancels all active orders of the symbol which left the universe and stops its worker
+func (b *SimBroker) onSymbolRemoved(e *SymbolRemovedEvent) {
+	b.removeWorker(e, "Sim Broker: symbol was removed from universe")
+}
+
+//removeWorker cancels all active orders of the symbol of the event, rejects its requests with the reason and
+//stops its worker. Orders of the symbol are rejected after it.
+func (b *SimBroker) removeWorker(e event, reason string) {
+	b.workersMut.Lock()
+	w, ok := b.workers[e.getSymbol()]
+	delete(b.workers, e.getSymbol())
+	b.workersMut.Unlock()
+	if !ok {
+		return
+	}
+	b.dispatch(e.getSymbol(), func() {
+		w.onSymbolRemoved(e, reason)
+		w.shutDown()
+	})
+}
+
+//onOptionChain passes option quotes as ticks to option workers. Workers are created for new options.
+func (b *SimBroker) onOptionChain(e *OptionChainEvent) {
+	for _, q := range e.Quotes {
+		b.workersMut.Lock()
+		w, ok := b.workers[q.Option.Symbol]
+		if !ok {
+			w = b.newWorker(q.Option)
+			b.workers[q.Option.Symbol] = w
 		}
-		b.workers[s.Symbol] = &bw
+		b.workersMut.Unlock()
 
+		tick := q.tick(e.getTime())
+		if !tick.HasTrade() {
+			continue
+		}
+		tickEvent := &NewTickEvent{BaseEvent: be(e.getTime(), q.Option), Tick: tick}
+		b.dispatch(q.Option.Symbol, func() { w.notify(tickEvent) })
 	}
 }
 
-func (b *SimBroker) Notify(e event) {
-	w := b.workers[e.getSymbol()]
-	w.notify(e)
+//onEventForUnknownSymbol rejects requests for symbols which don't have worker. Market data is ignored
+func (b *SimBroker) onEventForUnknownSymbol(e event) {
+	r := newRequestRejectEvent(e, "Sim Broker: symbol is not traded: "+e.getSymbol(), b.genTimeRoundTrip(e.getTime()))
+	if r == nil {
+		return
+	}
+	go func() {
+		sendEvent(b.ctx, b.events, r)
+	}()
+}
+
+func (b *SimBroker) genTimeRoundTrip(baseTime time.Time) time.Time {
+	return baseTime.Add(time.Duration(b.delay*2) * time.Millisecond)
+}
+
+//SetContext makes broker stop sending events when context is done
+func (b *SimBroker) SetContext(ctx context.Context) {
+	b.workersMut.Lock()
+	defer b.workersMut.Unlock()
+	b.ctx = ctx
+	for _, w := range b.workers {
+		w.ctx = ctx
+	}
 }
 
 func (b *SimBroker) shutDown() {
+	b.waitPool()
+	b.workersMut.RLock()
+	defer b.workersMut.RUnlock()
 	for _, w := range b.workers {
 		w.shutDown()
 	}
 }
 
+//newRequestRejectEvent creates reject event for strategy request. It returns nil if event is not a request
+func newRequestRejectEvent(e event, reason string, t time.Time) event {
+	var re event
+	switch i := e.(type) {
+	case *NewOrderEvent:
+		re = &OrderRejectedEvent{
+			OrdId:     i.LinkedOrder.Id,
+			Reason:    reason,
+			BaseEvent: be(t, i.Ticker),
+		}
+	case *OrderCancelRequestEvent:
+		re = &OrderCancelRejectEvent{
+			OrdId:     i.OrdId,
+			Reason:    reason,
+			BaseEvent: be(t, i.Ticker),
+		}
+	case *OrderReplaceRequestEvent:
+		re = &OrderReplaceRejectEvent{
+			OrdId:     i.OrdId,
+			Reason:    reason,
+			BaseEvent: be(t, i.Ticker),
+		}
+	default:
+		return nil
+	}
+	setCorrelationID(re, correlationIDOf(e))
+	return re
+}
+
 func (b *SimBroker) IsSimulated() bool {
 	return true
 }
@@ -132,8 +345,13 @@ type simBrokerWorker struct {
 	symbol            *Instrument
 	errChan           chan error
 	events            chan event
+	ctx               context.Context
 	delay             int64
 	strictLimitOrders bool
+	costs             CostModel
+	strict            bool
+	logger            ILogger
+	participation     float64
 
 	mpMutext        *sync.RWMutex
 	orders          map[string]*simBrokerOrder
@@ -158,8 +376,16 @@ func (b *simBrokerWorker) notify(e event) {
 		b.onCandleOpen(i)
 	case *CandleCloseEvent:
 		b.onCandleClose(i)
+	case *DataGapEvent:
+		b.onDataGap(i)
+	case *RollEvent:
+		b.onRoll(i)
+	case *EndOfDayEvent:
+		b.onEndOfDay(i)
+	case *AuctionImbalanceEvent:
+		b.onAuction(i)
 	default:
-		panic("Unexpected event type in broker: " + e.getName())
+		b.anomaly(e, "Unexpected event type in broker. ")
 	}
 }
 
@@ -211,7 +437,7 @@ func (b *simBrokerWorker) validateOrderForExecution(order *simBrokerOrder, expec
 		return &err
 	}
 
-	lvsQty := order.Qty - order.BrokerExecQty
+	lvsQty := order.qty() - order.BrokerExecQty
 	if lvsQty <= 0 {
 		return errors.New("Sim broker: Lvs qty is zero or less. Nothing to execute. ")
 	}
@@ -220,13 +446,20 @@ func (b *simBrokerWorker) validateOrderForExecution(order *simBrokerOrder, expec
 }
 
 func (b *simBrokerWorker) addBrokerEvent(e event) {
+	if ord, ok := b.orders[eventOrderID(e)]; ok && ord.Order != nil {
+		setCorrelationID(e, ord.CorrelationID)
+	}
+	if b.logger != nil {
+		b.logger.Log(DebugLevel, e.String(), eventLogFields(e))
+	}
 
 	switch i := e.(type) {
 
 	case *OrderConfirmationEvent:
 		ord, ok := b.orders[i.OrdId]
 		if !ok {
-			panic("Confirmation of not existing order")
+			b.anomaly(e, "Confirmation of not existing order. ")
+			return
 		}
 		ord.BrokerState = ConfirmedOrder
 		ord.StateUpdTime = e.getTime()
@@ -238,8 +471,8 @@ func (b *simBrokerWorker) addBrokerEvent(e event) {
 	case *OrderCancelEvent:
 		ord, ok := b.orders[i.OrdId]
 		if !ok {
-			msg := fmt.Sprintf("Can't find order %v in confirmed map to cancel it. ", i.OrdId)
-			panic(msg)
+			b.anomaly(e, fmt.Sprintf("Can't find order %v in confirmed map to cancel it. ", i.OrdId))
+			return
 		}
 
 		ord.BrokerState = CanceledOrder
@@ -248,28 +481,33 @@ func (b *simBrokerWorker) addBrokerEvent(e event) {
 	case *OrderReplacedEvent:
 		ord, ok := b.orders[i.OrdId]
 		if !ok {
-			msg := fmt.Sprintf("Can't find order %v in confirmed map to replace it. ", i.OrdId)
-			panic(msg)
+			b.anomaly(e, fmt.Sprintf("Can't find order %v in confirmed map to replace it. ", i.OrdId))
+			return
 		}
 
 		ord.StateUpdTime = e.getTime()
 		ord.BrokerPrice = i.NewPrice
+		if i.NewQty > 0 {
+			ord.BrokerQty = i.NewQty
+		}
 
 	case *OrderFillEvent:
 		ord, ok := b.orders[i.OrdId]
 		if !ok {
-			msg := fmt.Sprintf("Can't find order %v in confirmed map to fill it. ", i.OrdId)
-			panic(msg)
+			b.anomaly(e, fmt.Sprintf("Can't find order %v in confirmed map to fill it. ", i.OrdId))
+			return
 		}
 
 		execQty := i.Qty
+		if execQty > ord.qty()-ord.BrokerExecQty {
+			b.anomaly(e, fmt.Sprintf("Execution qty of order %v is larger than leaves qty. ", i.OrdId))
+			return
+		}
+		b.applyCosts(ord, i)
 
-		if execQty == ord.Qty-ord.BrokerExecQty {
+		if execQty == ord.qty()-ord.BrokerExecQty {
 			ord.BrokerState = FilledOrder
 		} else {
-			if execQty > ord.Qty-ord.BrokerExecQty {
-				panic("Large qty")
-			}
 			ord.BrokerState = PartialFilledOrder
 		}
 
@@ -278,7 +516,8 @@ func (b *simBrokerWorker) addBrokerEvent(e event) {
 	case *OrderRejectedEvent:
 		ord, ok := b.orders[i.OrdId]
 		if !ok {
-			panic("Confirmation of not existing order")
+			b.anomaly(e, "Rejection of not existing order. ")
+			return
 		}
 		if ord.BrokerState != ConfirmedOrder {
 			ord.BrokerState = RejectedOrder
@@ -296,16 +535,19 @@ func (b *simBrokerWorker) newError(e error) {
 	if b.errChan == nil {
 		panic("Simulated broker error chan is nil")
 	}
+	if b.logger != nil {
+		b.logger.Log(ErrorLevel, e.Error(), errorFields(e))
+	}
 	b.waitGroup.Add(1)
 	go func() {
-		b.errChan <- e
+		sendError(b.ctx, b.errChan, e)
 		b.waitGroup.Done()
 	}()
 
 }
 
 func (b *simBrokerWorker) shutDown() {
-	b.waitGroup.Wait()
+	waitContext(b.ctx, b.waitGroup)
 }
 
 //********* EVENT HANDLERS **********************************************************
@@ -313,6 +555,9 @@ func (b *simBrokerWorker) shutDown() {
 func (b *simBrokerWorker) onNewOrder(e *NewOrderEvent) {
 	b.mpMutext.Lock()
 	defer b.mpMutext.Unlock()
+	if b.orders == nil {
+		b.orders = make(map[string]*simBrokerOrder)
+	}
 
 	if !e.LinkedOrder.isValid() {
 		r := "Sim Broker: can't confirm order. Order is not valid"
@@ -459,7 +704,26 @@ func (b *simBrokerWorker) onReplaceRequest(e *OrderReplaceRequestEvent) {
 		return
 	}
 
-	if math.IsNaN(e.NewPrice) || e.NewPrice == 0 {
+	newPrice := e.NewPrice
+	if e.NewQty != 0 {
+		ord := b.orders[e.OrdId]
+		if e.NewQty <= ord.BrokerExecQty {
+			e := OrderReplaceRejectEvent{
+				BaseEvent: be(newEvTime, e.Ticker),
+				OrdId:     e.OrdId,
+				Reason:    fmt.Sprintf("Replace qty %v is not larger than executed qty %v", e.NewQty, ord.BrokerExecQty),
+			}
+			b.addBrokerEvent(&e)
+			return
+		}
+		if newPrice == 0 || math.IsNaN(newPrice) {
+			newPrice = ord.BrokerPrice
+		}
+	}
+
+	//Market orders have no price, so only their qty can be replaced
+	unpricedQty := e.NewQty != 0 && !b.orders[e.OrdId].IsPriced()
+	if !unpricedQty && (math.IsNaN(newPrice) || newPrice == 0) {
 		e := OrderReplaceRejectEvent{
 			BaseEvent: be(newEvTime, e.Ticker),
 			OrdId:     e.OrdId,
@@ -471,7 +735,8 @@ func (b *simBrokerWorker) onReplaceRequest(e *OrderReplaceRequestEvent) {
 
 	replacedEvent := OrderReplacedEvent{
 		OrdId:     e.OrdId,
-		NewPrice:  e.NewPrice,
+		NewPrice:  newPrice,
+		NewQty:    e.NewQty,
 		BaseEvent: be(newEvTime, e.Ticker),
 	}
 	b.addBrokerEvent(&replacedEvent)
@@ -479,7 +744,8 @@ func (b *simBrokerWorker) onReplaceRequest(e *OrderReplaceRequestEvent) {
 
 func (b *simBrokerWorker) onCandleOpen(e *CandleOpenEvent) {
 	if e.CandleTime.Before(b.lastCandleTime) {
-		panic("Candle before seen candle")
+		b.anomaly(e, "Candle before seen candle. ")
+		return
 	}
 	b.lastCandleTime = e.CandleTime
 	b.proceedStoredRequests(e.getTime())
@@ -488,7 +754,8 @@ func (b *simBrokerWorker) onCandleOpen(e *CandleOpenEvent) {
 
 func (b *simBrokerWorker) onCandleClose(e *CandleCloseEvent) {
 	if e.getTime().Before(b.lastCandleTime) {
-		panic("Candle before seen candle")
+		b.anomaly(e, "Candle before seen candle. ")
+		return
 	}
 	b.lastCandleTime = e.getTime()
 	b.proceedStoredRequests(e.getTime())
@@ -506,7 +773,8 @@ func (b *simBrokerWorker) onTick(e *NewTickEvent) {
 		return
 	}
 	if e.Tick.Datetime.Before(b.lastTickTime) {
-		panic("Tick before seen tick")
+		b.anomaly(e, "Tick before seen tick. ")
+		return
 	}
 	b.lastTickTime = e.Tick.Datetime
 	b.proceedStoredRequests(e.getTime())
@@ -514,6 +782,69 @@ func (b *simBrokerWorker) onTick(e *NewTickEvent) {
 
 }
 
+//onSymbolRemoved cancels active orders, rejects requests which weren't proceeded yet and sends out all
+//generated events. Worker shouldn't get any events after it.
+func (b *simBrokerWorker) onSymbolRemoved(e event, reason string) {
+	b.proceedStoredRequests(e.getTime())
+
+	b.mpMutext.Lock()
+	defer b.mpMutext.Unlock()
+
+	for _, r := range b.requestEvents {
+		if re := newRequestRejectEvent(r, reason, b.genTimeRoundTrip(r.getTime())); re != nil {
+			b.generatedEvents = append(b.generatedEvents, re)
+		}
+	}
+	b.requestEvents = nil
+
+	for _, o := range b.orders {
+		if o.isActive() {
+			cancelE := OrderCancelEvent{
+				OrdId:     o.Id,
+				BaseEvent: be(e.getTime(), o.Ticker),
+			}
+			b.addBrokerEvent(&cancelE)
+		}
+	}
+
+	b.generatedEvents.sort()
+	for _, ge := range b.generatedEvents {
+		sendEvent(b.ctx, b.events, ge)
+	}
+	b.generatedEvents = nil
+}
+
+//onDataGap proceeds requests sent before the gap and cancels orders which expired while there were no data.
+//Orders are never filled by prices from the other side of the gap, they will be checked on the next data.
+func (b *simBrokerWorker) onDataGap(e *DataGapEvent) {
+	b.proceedStoredRequests(e.From)
+
+	b.mpMutext.Lock()
+	defer b.mpMutext.Unlock()
+	for _, o := range b.orders {
+		if o.isActive() && o.StateUpdTime.Before(e.To) {
+			b.cancelByTif(o, e.To)
+		}
+	}
+}
+
+//onRoll moves prices of active orders by price difference between new and old front contracts
+func (b *simBrokerWorker) onRoll(e *RollEvent) {
+	b.proceedStoredRequests(e.getTime())
+
+	diff := e.Difference()
+	if diff == 0 {
+		return
+	}
+	b.mpMutext.Lock()
+	defer b.mpMutext.Unlock()
+	for _, o := range b.orders {
+		if o.isActive() && o.BrokerPrice != 0 {
+			o.BrokerPrice += diff
+		}
+	}
+}
+
 // ************ ORDER EXECUTORS *************************************************************
 
 func (b *simBrokerWorker) cancelByTif(o *simBrokerOrder, t time.Time) bool {
@@ -545,7 +876,7 @@ func (b *simBrokerWorker) proceedStoredRequests(beforeTime time.Time) {
 			case *OrderReplaceRequestEvent:
 				b.onReplaceRequest(i)
 			default:
-				panic("Unexpected event type")
+				b.anomaly(e, "Unexpected request event type. ")
 			}
 		} else {
 			eventsLeft = append(eventsLeft, e)
@@ -560,7 +891,7 @@ func (b *simBrokerWorker) findExecutions(mdEvent event) {
 	defer b.mpMutext.Unlock()
 
 	if len(b.orders) == 0 && len(b.generatedEvents) == 0 {
-		b.events <- mdEvent
+		sendEvent(b.ctx, b.events, mdEvent)
 		return
 	}
 
@@ -622,8 +953,17 @@ func (b *simBrokerWorker) findExecutions(mdEvent event) {
 				}
 			}
 		}
+	case *AuctionImbalanceEvent:
+		for _, o := range b.orders {
+			if o.isActive() && o.StateUpdTime.Before(i.getTime()) {
+				if b.cancelByTif(o, i.getTime()) {
+					continue
+				}
+				genEvents = append(genEvents, b.fillOnAuction(o, i)...)
+			}
+		}
 	default:
-		panic("Unexpected event type for simBrokerWorker")
+		b.anomaly(mdEvent, "Unexpected market data event type. ")
 
 	}
 
@@ -649,7 +989,7 @@ func (b *simBrokerWorker) findExecutions(mdEvent event) {
 	b.generatedEvents = eventsLeft
 
 	for _, e := range eventsToSend {
-		b.events <- e
+		sendEvent(b.ctx, b.events, e)
 	}
 
 }
@@ -665,7 +1005,7 @@ func (b *simBrokerWorker) findExecutionsOnCandleClose(o *simBrokerOrder, e *Cand
 	case MarketOnClose:
 		return b.fillOnCandleCloseMOC(o, e)
 	case MarketOrder:
-		panic("Not implemented")
+		b.anomaly(e, fmt.Sprintf("Execution of market order %v on candle close is not implemented. ", o.Id))
 	}
 
 	return nil
@@ -788,7 +1128,7 @@ func (b *simBrokerWorker) fillOnCandleCloseLimit(o *simBrokerOrder, e *CandleClo
 		BaseEvent: be(e.getTime(), e.Ticker),
 		OrdId:     o.Id,
 		Price:     fillPrice,
-		Qty:       o.Qty - o.BrokerExecQty,
+		Qty:       o.qty() - o.BrokerExecQty,
 	}
 
 	return &fe
@@ -803,7 +1143,7 @@ func (b *simBrokerWorker) fillOnCandleCloseMOC(o *simBrokerOrder, e *CandleClose
 		BaseEvent: be(e.getTime(), e.Ticker),
 		OrdId:     o.Id,
 		Price:     e.Candle.Close,
-		Qty:       o.Qty - o.BrokerExecQty,
+		Qty:       o.qty() - o.BrokerExecQty,
 	}
 
 	return &fe
@@ -843,7 +1183,7 @@ func (b *simBrokerWorker) fillOnCandleCloseLOC(o *simBrokerOrder, e *CandleClose
 		BaseEvent: be(e.getTime(), e.Ticker),
 		OrdId:     o.Id,
 		Price:     fillPrice,
-		Qty:       o.Qty - o.BrokerExecQty,
+		Qty:       o.qty() - o.BrokerExecQty,
 	}
 
 	return &fe
@@ -893,7 +1233,7 @@ func (b *simBrokerWorker) fillOnCandleCloseStop(o *simBrokerOrder, e *CandleClos
 		BaseEvent: be(e.getTime(), e.Ticker),
 		OrdId:     o.Id,
 		Price:     fillPrice,
-		Qty:       o.Qty - o.BrokerExecQty,
+		Qty:       o.qty() - o.BrokerExecQty,
 	}
 
 	return &fe
@@ -907,16 +1247,17 @@ func (b *simBrokerWorker) fillOnCandleOpenMOO(o *simBrokerOrder, e *CandleOpenEv
 			BaseEvent: be(e.getTime(), e.Ticker),
 			OrdId:     o.Id,
 			Price:     e.Price,
-			Qty:       o.Qty - o.BrokerExecQty,
+			Qty:       o.qty() - o.BrokerExecQty,
 		}
 
 		return &fe
 	}
-	if e.CandleTime.Hour() != o.Ticker.Exchange.MarketOpenTime.Hour {
+	candleTime := o.Ticker.Exchange.LocalTime(e.CandleTime)
+	if candleTime.Hour() != o.Ticker.Exchange.MarketOpenTime.Hour {
 		return nil
 	}
 
-	if e.CandleTime.Minute() != o.Ticker.Exchange.MarketOpenTime.Minute {
+	if candleTime.Minute() != o.Ticker.Exchange.MarketOpenTime.Minute {
 		return nil
 	}
 
@@ -924,7 +1265,7 @@ func (b *simBrokerWorker) fillOnCandleOpenMOO(o *simBrokerOrder, e *CandleOpenEv
 		BaseEvent: be(e.getTime(), e.Ticker),
 		OrdId:     o.Id,
 		Price:     e.Price,
-		Qty:       o.Qty - o.BrokerExecQty,
+		Qty:       o.qty() - o.BrokerExecQty,
 	}
 
 	return &fe
@@ -939,11 +1280,12 @@ func (b *simBrokerWorker) fillOnCandleOpenLOO(o *simBrokerOrder, e *CandleOpenEv
 	}
 
 	if !canBeFilled {
-		if e.CandleTime.Hour() != o.Ticker.Exchange.MarketOpenTime.Hour {
+		candleTime := o.Ticker.Exchange.LocalTime(e.CandleTime)
+		if candleTime.Hour() != o.Ticker.Exchange.MarketOpenTime.Hour {
 			return nil
 		}
 
-		if e.CandleTime.Minute() != o.Ticker.Exchange.MarketOpenTime.Minute {
+		if candleTime.Minute() != o.Ticker.Exchange.MarketOpenTime.Minute {
 			return nil
 		}
 
@@ -958,7 +1300,7 @@ func (b *simBrokerWorker) fillOnCandleOpenLOO(o *simBrokerOrder, e *CandleOpenEv
 					BaseEvent: be(e.getTime(), e.Ticker),
 					OrdId:     o.Id,
 					Price:     e.Price,
-					Qty:       o.Qty - o.BrokerExecQty,
+					Qty:       o.qty() - o.BrokerExecQty,
 				}
 
 				return &fe
@@ -969,7 +1311,7 @@ func (b *simBrokerWorker) fillOnCandleOpenLOO(o *simBrokerOrder, e *CandleOpenEv
 					BaseEvent: be(e.getTime(), e.Ticker),
 					OrdId:     o.Id,
 					Price:     e.Price,
-					Qty:       o.Qty - o.BrokerExecQty,
+					Qty:       o.qty() - o.BrokerExecQty,
 				}
 
 				return &fe
@@ -994,7 +1336,7 @@ func (b *simBrokerWorker) fillOnCandleOpenMarket(o *simBrokerOrder, e *CandleOpe
 		BaseEvent: be(e.getTime(), e.Ticker),
 		OrdId:     o.Id,
 		Price:     e.Price,
-		Qty:       o.Qty - o.BrokerExecQty,
+		Qty:       o.qty() - o.BrokerExecQty,
 	}
 
 	return &fe
@@ -1013,7 +1355,7 @@ func (b *simBrokerWorker) fillOnCandleOpenLimit(o *simBrokerOrder, e *CandleOpen
 				BaseEvent: be(e.getTime(), e.Ticker),
 				OrdId:     o.Id,
 				Price:     fillPrice,
-				Qty:       o.Qty - o.BrokerExecQty,
+				Qty:       o.qty() - o.BrokerExecQty,
 			}
 
 			return &fe
@@ -1024,7 +1366,7 @@ func (b *simBrokerWorker) fillOnCandleOpenLimit(o *simBrokerOrder, e *CandleOpen
 				BaseEvent: be(e.getTime(), e.Ticker),
 				OrdId:     o.Id,
 				Price:     fillPrice,
-				Qty:       o.Qty - o.BrokerExecQty,
+				Qty:       o.qty() - o.BrokerExecQty,
 			}
 
 			return &fe
@@ -1039,7 +1381,7 @@ func (b *simBrokerWorker) fillOnCandleOpenLimit(o *simBrokerOrder, e *CandleOpen
 			BaseEvent: be(e.getTime(), e.Ticker),
 			OrdId:     o.Id,
 			Price:     fillPrice,
-			Qty:       o.Qty - o.BrokerExecQty,
+			Qty:       o.qty() - o.BrokerExecQty,
 		}
 
 		return &fe
@@ -1058,7 +1400,7 @@ func (b *simBrokerWorker) fillOnCandleOpenStop(o *simBrokerOrder, e *CandleOpenE
 				BaseEvent: be(e.getTime(), e.Ticker),
 				OrdId:     o.Id,
 				Price:     e.Price,
-				Qty:       o.Qty - o.BrokerExecQty,
+				Qty:       o.qty() - o.BrokerExecQty,
 			}
 
 			return &fe
@@ -1069,7 +1411,7 @@ func (b *simBrokerWorker) fillOnCandleOpenStop(o *simBrokerOrder, e *CandleOpenE
 				BaseEvent: be(e.getTime(), e.Ticker),
 				OrdId:     o.Id,
 				Price:     e.Price,
-				Qty:       o.Qty - o.BrokerExecQty,
+				Qty:       o.qty() - o.BrokerExecQty,
 			}
 
 			return &fe
@@ -1156,9 +1498,9 @@ func (b *simBrokerWorker) fillOnTickLimitAuction(order *simBrokerOrder, tick *Ti
 		return generatedEvents
 	}
 
-	execQty := order.Qty
-	if execQty > tick.LastSize {
-		execQty = tick.LastSize
+	execQty := order.qty()
+	if execQty > order.Ticker.sizeToQty(tick.LastSize) {
+		execQty = order.Ticker.sizeToQty(tick.LastSize)
 	}
 
 	fillE := OrderFillEvent{
@@ -1170,7 +1512,7 @@ func (b *simBrokerWorker) fillOnTickLimitAuction(order *simBrokerOrder, tick *Ti
 
 	generatedEvents = append(generatedEvents, &fillE)
 
-	if execQty < order.Qty {
+	if execQty < order.qty() {
 		cancelE := OrderCancelEvent{
 			OrdId:     order.Id,
 			BaseEvent: be(b.genTimeRoundTrip(tick.Datetime), order.Ticker),
@@ -1205,7 +1547,7 @@ func (b *simBrokerWorker) fillOnTickMOO(order *simBrokerOrder, tick *Tick) event
 	fillE := OrderFillEvent{
 		OrdId:     order.Id,
 		Price:     tick.LastPrice,
-		Qty:       order.Qty,
+		Qty:       order.qty(),
 		BaseEvent: be(b.genTimeRoundTrip(tick.Datetime), order.Ticker),
 	}
 	return &fillE
@@ -1231,7 +1573,7 @@ func (b *simBrokerWorker) fillOnTickMOC(order *simBrokerOrder, tick *Tick) event
 	fillE := OrderFillEvent{
 		OrdId:     order.Id,
 		Price:     tick.LastPrice,
-		Qty:       order.Qty,
+		Qty:       order.qty(),
 		BaseEvent: be(b.genTimeRoundTrip(tick.Datetime), order.Ticker),
 	}
 
@@ -1251,14 +1593,14 @@ func (b *simBrokerWorker) fillOnTickLimit(order *simBrokerOrder, tick *Tick) eve
 		return nil
 	}
 
-	lvsQty := order.Qty - order.BrokerExecQty
+	lvsQty := order.qty() - order.BrokerExecQty
 
 	switch order.Side {
 	case OrderSell:
 		if tick.LastPrice > order.BrokerPrice {
 			qty := lvsQty
-			if tick.LastSize < int64(qty) {
-				qty = tick.LastSize
+			if order.Ticker.sizeToQty(tick.LastSize) < int64(qty) {
+				qty = order.Ticker.sizeToQty(tick.LastSize)
 			}
 
 			fillE := OrderFillEvent{
@@ -1272,8 +1614,8 @@ func (b *simBrokerWorker) fillOnTickLimit(order *simBrokerOrder, tick *Tick) eve
 		} else {
 			if tick.LastPrice == order.BrokerPrice && !b.strictLimitOrders {
 				qty := lvsQty
-				if tick.LastSize < int64(qty) {
-					qty = tick.LastSize
+				if order.Ticker.sizeToQty(tick.LastSize) < int64(qty) {
+					qty = order.Ticker.sizeToQty(tick.LastSize)
 				}
 
 				fillE := OrderFillEvent{
@@ -1292,8 +1634,8 @@ func (b *simBrokerWorker) fillOnTickLimit(order *simBrokerOrder, tick *Tick) eve
 	case OrderBuy:
 		if tick.LastPrice < order.BrokerPrice {
 			qty := lvsQty
-			if tick.LastSize < int64(qty) {
-				qty = tick.LastSize
+			if order.Ticker.sizeToQty(tick.LastSize) < int64(qty) {
+				qty = order.Ticker.sizeToQty(tick.LastSize)
 			}
 
 			fillE := OrderFillEvent{
@@ -1308,8 +1650,8 @@ func (b *simBrokerWorker) fillOnTickLimit(order *simBrokerOrder, tick *Tick) eve
 		} else {
 			if tick.LastPrice == order.BrokerPrice && !b.strictLimitOrders {
 				qty := lvsQty
-				if tick.LastSize < int64(qty) {
-					qty = tick.LastSize
+				if order.Ticker.sizeToQty(tick.LastSize) < int64(qty) {
+					qty = order.Ticker.sizeToQty(tick.LastSize)
 				}
 
 				fillE := OrderFillEvent{
@@ -1349,10 +1691,10 @@ func (b *simBrokerWorker) fillOnTickStop(order *simBrokerOrder, tick *Tick) even
 			return nil
 		}
 		price := tick.LastPrice
-		lvsQty := order.Qty - order.BrokerExecQty
+		lvsQty := order.qty() - order.BrokerExecQty
 		qty := lvsQty
-		if tick.LastSize < qty {
-			qty = tick.LastSize
+		if order.Ticker.sizeToQty(tick.LastSize) < qty {
+			qty = order.Ticker.sizeToQty(tick.LastSize)
 		}
 		if tick.HasQuote() {
 			price = tick.BidPrice
@@ -1372,10 +1714,10 @@ func (b *simBrokerWorker) fillOnTickStop(order *simBrokerOrder, tick *Tick) even
 			return nil
 		}
 		price := tick.LastPrice
-		lvsQty := order.Qty - order.BrokerExecQty
+		lvsQty := order.qty() - order.BrokerExecQty
 		qty := lvsQty
-		if tick.LastSize < qty {
-			qty = tick.LastSize
+		if order.Ticker.sizeToQty(tick.LastSize) < qty {
+			qty = order.Ticker.sizeToQty(tick.LastSize)
 		}
 		if tick.HasQuote() {
 			price = tick.AskPrice
@@ -1428,11 +1770,11 @@ func (b *simBrokerWorker) fillOnTickMarket(order *simBrokerOrder, tick *Tick) ev
 	if tick.HasQuote() {
 		var qty int64 = 0
 		price := math.NaN()
-		lvsQty := order.Qty - order.BrokerExecQty
+		lvsQty := order.qty() - order.BrokerExecQty
 
 		if order.Side == OrderBuy {
-			if lvsQty > tick.AskSize {
-				qty = tick.AskSize
+			if lvsQty > order.Ticker.sizeToQty(tick.AskSize) {
+				qty = order.Ticker.sizeToQty(tick.AskSize)
 			} else {
 				qty = lvsQty
 			}
@@ -1445,8 +1787,8 @@ func (b *simBrokerWorker) fillOnTickMarket(order *simBrokerOrder, tick *Tick) ev
 				return nil
 			}
 
-			if lvsQty > tick.BidSize {
-				qty = tick.BidSize
+			if lvsQty > order.Ticker.sizeToQty(tick.BidSize) {
+				qty = order.Ticker.sizeToQty(tick.BidSize)
 			} else {
 				qty = lvsQty
 			}
@@ -1471,7 +1813,7 @@ func (b *simBrokerWorker) fillOnTickMarket(order *simBrokerOrder, tick *Tick) ev
 		fillE := OrderFillEvent{
 			OrdId:     order.Id,
 			Price:     tick.LastPrice,
-			Qty:       order.Qty,
+			Qty:       order.qty(),
 			BaseEvent: be(b.genTimeRoundTrip(tick.Datetime), order.Ticker),
 		}
 
@@ -0,0 +1,119 @@
+package engine
+
+import (
+	"errors"
+	"hash/fnv"
+	"sync"
+)
+
+//brokerShardQueueSize is number of events which can wait for busy shard before broker Notify blocks
+const brokerShardQueueSize = 1024
+
+//brokerShard handles events of its symbols one by one in goroutine, so events of every symbol keep their order.
+//Workers of the shard share its mutex and wait group. Goroutine runs only while shard has events.
+type brokerShard struct {
+	tasks     chan func()
+	wg        sync.WaitGroup
+	workerMut sync.RWMutex
+	workerWG  sync.WaitGroup
+
+	mut     sync.Mutex
+	running bool
+}
+
+func newBrokerShard() *brokerShard {
+	return &brokerShard{tasks: make(chan func(), brokerShardQueueSize)}
+}
+
+//push queues task and starts goroutine of the shard if it's not running
+func (s *brokerShard) push(task func()) {
+	s.wg.Add(1)
+	s.tasks <- task
+	s.mut.Lock()
+	start := !s.running
+	s.running = true
+	s.mut.Unlock()
+	if start {
+		go s.run()
+	}
+}
+
+//run handles tasks until queue is empty
+func (s *brokerShard) run() {
+	for {
+		select {
+		case task := <-s.tasks:
+			task()
+			s.wg.Done()
+			continue
+		default:
+		}
+		s.mut.Lock()
+		if len(s.tasks) == 0 {
+			s.running = false
+			s.mut.Unlock()
+			return
+		}
+		s.mut.Unlock()
+	}
+}
+
+//wait waits until queued tasks are handled
+func (s *brokerShard) wait() {
+	s.wg.Wait()
+}
+
+//SetWorkerPool makes broker handle symbols in fixed number of goroutines instead of engine goroutines. Symbols
+//are spread across workers by hash of the symbol, so events of every symbol keep their order, and symbols of
+//one worker share its structures. Zero turns pool off. It should be called before the broker is passed
+//to the engine. Pool isn't used in deterministic mode.
+func (b *SimBroker) SetWorkerPool(workers int) error {
+	if workers < 0 {
+		return errors.New("Number of workers can't be negative. ")
+	}
+	if b.workersMut != nil {
+		return errors.New("Worker pool should be set before Init. ")
+	}
+	b.poolSize = workers
+	return nil
+}
+
+func (b *SimBroker) setSynchronous(on bool) {
+	b.synchronous = on
+}
+
+//initPool creates shards of the worker pool
+func (b *SimBroker) initPool() {
+	b.shards = nil
+	for i := 0; i < b.poolSize; i++ {
+		b.shards = append(b.shards, newBrokerShard())
+	}
+}
+
+//shardOf returns shard of the symbol or nil if broker has no pool
+func (b *SimBroker) shardOf(symbol string) *brokerShard {
+	if len(b.shards) == 0 {
+		return nil
+	}
+	h := fnv.New32a()
+	h.Write([]byte(symbol))
+	return b.shards[h.Sum32()%uint32(len(b.shards))]
+}
+
+//dispatch runs handler of the symbol event in shard of the symbol. Without pool and in deterministic mode
+//handler is run before dispatch returns.
+func (b *SimBroker) dispatch(symbol string, handler func()) {
+	s := b.shardOf(symbol)
+	if s == nil || b.synchronous {
+		handler()
+		return
+	}
+	s.push(handler)
+}
+
+//waitPool waits until all shards handle queued events
+func (b *SimBroker) waitPool() {
+	for _, s := range b.shards {
+		s.wait()
+	}
+}
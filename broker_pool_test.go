@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestPoolInstrument(symbol string) *Instrument {
+	inst := newTestInstrument()
+	inst.Symbol = symbol
+	return inst
+}
+
+func newTestPoolTick(inst *Instrument, t time.Time, price float64) *NewTickEvent {
+	return &NewTickEvent{BaseEvent: be(t, inst), Tick: &Tick{Tick: &marketdata.Tick{Datetime: t, Symbol: inst.Symbol,
+		LastPrice: price, LastSize: 100, BidPrice: price - 0.01, AskPrice: price + 0.01}, Ticker: inst}}
+}
+
+func TestSimBroker_SetWorkerPool(t *testing.T) {
+	b := &SimBroker{}
+	assert.NotNil(t, b.SetWorkerPool(-1))
+	assert.Nil(t, b.SetWorkerPool(4))
+	b.Init(make(chan error), make(chan event), []*Instrument{newTestInstrument()})
+	assert.Len(t, b.shards, 4)
+	assert.NotNil(t, b.SetWorkerPool(2))
+}
+
+//runTestPoolBroker sends orders and ticks of the symbols to the broker and returns names of broker events by symbol
+func runTestPoolBroker(t *testing.T, workers int, symbols []*Instrument) map[string][]string {
+	events := make(chan event, 100)
+	b := &SimBroker{delay: 10}
+	assert.Nil(t, b.SetWorkerPool(workers))
+	b.Init(make(chan error), events, symbols)
+
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	for _, s := range symbols {
+		order := newTestOrder(10, OrderBuy, 100, s.Symbol+"1")
+		order.Ticker = s
+		order.Type = MarketOrder
+		order.Price = math.NaN()
+		order.Time = tm
+		b.Notify(&NewOrderEvent{LinkedOrder: order, BaseEvent: be(tm, s)})
+	}
+	for i := 1; i <= 3; i++ {
+		for _, s := range symbols {
+			b.Notify(newTestPoolTick(s, tm.Add(time.Duration(i)*time.Second), 10))
+		}
+	}
+	b.shutDown()
+	close(events)
+
+	bySymbol := make(map[string][]string)
+	for e := range events {
+		bySymbol[e.getSymbol()] = append(bySymbol[e.getSymbol()], eventTypeName(e))
+	}
+	return bySymbol
+}
+
+func TestSimBroker_WorkerPool(t *testing.T) {
+	var symbols []*Instrument
+	for _, s := range []string{"SPY", "QQQ", "IWM", "DIA", "TLT"} {
+		symbols = append(symbols, newTestPoolInstrument(s))
+	}
+	b := &SimBroker{}
+	assert.Nil(t, b.SetWorkerPool(2))
+	b.Init(make(chan error), make(chan event), symbols)
+	//Workers of one shard share its structures and have no orders map until the first order
+	for _, s := range symbols {
+		w := b.workers[s.Symbol]
+		assert.True(t, w.mpMutext == &b.shardOf(s.Symbol).workerMut)
+		assert.Nil(t, w.orders)
+	}
+
+	expected := runTestPoolBroker(t, 0, symbols)
+	assert.Len(t, expected, len(symbols))
+	assert.Equal(t, "OrderConfirmationEvent", expected["SPY"][0])
+	//Events of every symbol are the same and in the same order as without pool
+	assert.Equal(t, expected, runTestPoolBroker(t, 2, symbols))
+}
+
+func TestSimBroker_WorkerPoolSynchronous(t *testing.T) {
+	b := &SimBroker{}
+	assert.Nil(t, b.SetWorkerPool(2))
+	b.Init(make(chan error), make(chan event), []*Instrument{newTestInstrument()})
+	c := Engine{broker: b, mut: &sync.Mutex{}}
+	c.SetDeterministic(true)
+
+	handled := false
+	b.dispatch("Test", func() { handled = true })
+	assert.True(t, handled)
+}
@@ -0,0 +1,240 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"encoding/json"
+	"hash/fnv"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const prepairedMetaExt = ".meta"
+
+//IStorageFingerprint can be implemented by market data storage. Fingerprint should change every time
+//stored data of the symbol in the date range changes. BTM uses it to check if prepaired data is up to date.
+type IStorageFingerprint interface {
+	Fingerprint(symbol string, dRange marketdata.DateRange) (string, error)
+}
+
+//prepairedDataMeta describes what is stored in prepaired data file. It's saved next to prepaired file.
+type prepairedDataMeta struct {
+	Symbols     []string
+	Mode        MarketDataMode
+	TimeFrame   string
+	FromDate    time.Time
+	ToDate      time.Time
+	Compressed  bool
+	Fingerprint string
+	File        string
+}
+
+//sameSource returns true if both meta describe data of the same symbols, mode and start date
+func (p *prepairedDataMeta) sameSource(o *prepairedDataMeta) bool {
+	if p.Mode != o.Mode || p.TimeFrame != o.TimeFrame || p.Compressed != o.Compressed {
+		return false
+	}
+	if !p.FromDate.Equal(o.FromDate) {
+		return false
+	}
+	return strings.Join(p.Symbols, ",") == strings.Join(o.Symbols, ",")
+}
+
+func (m *BTM) currentPrepairedMeta() *prepairedDataMeta {
+	symbols := make([]string, len(m.Symbols))
+	for i, s := range m.Symbols {
+		symbols[i] = s.Symbol
+	}
+	sort.Strings(symbols)
+
+	meta := prepairedDataMeta{
+		Symbols:    symbols,
+		Mode:       m.mode,
+		FromDate:   m.FromDate,
+		ToDate:     m.ToDate,
+		Compressed: m.CompressPrepairedData,
+		File:       path.Base(m.getPrepairedFilePath()),
+	}
+	if m.mode == MarketDataModeCandles {
+		meta.TimeFrame = m.candlesTimeFrame
+	}
+	return &meta
+}
+
+//sourceFingerprint combines storage fingerprints of all symbols for the date range. If storage doesn't
+//provide fingerprints it returns empty string and prepaired data is considered to be always up to date.
+func (m *BTM) sourceFingerprint(from time.Time, to time.Time) (string, error) {
+	fs, ok := m.Storage.(IStorageFingerprint)
+	if !ok {
+		return "", nil
+	}
+	meta := m.currentPrepairedMeta()
+	h := fnv.New64a()
+	for _, s := range meta.Symbols {
+		f, err := fs.Fingerprint(s, marketdata.DateRange{From: from, To: to})
+		if err != nil {
+			return "", err
+		}
+		if _, err := h.Write([]byte(s + ":" + f + ";")); err != nil {
+			return "", err
+		}
+	}
+	return strconv.FormatUint(h.Sum64(), 16), nil
+}
+
+func (m *BTM) writePrepairedMeta(meta *prepairedDataMeta) error {
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path.Join(m.Folder, meta.File+prepairedMetaExt), data, 0644)
+}
+
+func readPrepairedMeta(pth string) (*prepairedDataMeta, error) {
+	data, err := ioutil.ReadFile(pth)
+	if err != nil {
+		return nil, err
+	}
+	var meta prepairedDataMeta
+	err = json.Unmarshal(data, &meta)
+	if err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+//prepairedDataIsActual checks if prepaired file exists and was built from the same source data. Files without
+//meta are considered actual.
+func (m *BTM) prepairedDataIsActual() bool {
+	if !m.prepairedDataExists() {
+		return false
+	}
+	meta, err := readPrepairedMeta(m.getPrepairedFilePath() + prepairedMetaExt)
+	if err != nil {
+		return os.IsNotExist(err)
+	}
+	fingerprint, err := m.sourceFingerprint(m.FromDate, m.ToDate)
+	if err != nil {
+		m.newError(err)
+		return false
+	}
+	return meta.Fingerprint == fingerprint
+}
+
+//findExtendablePrepairedData looks for prepaired file with the same symbols and start date but earlier end
+//date which source data wasn't changed. The file with the latest end date is returned.
+func (m *BTM) findExtendablePrepairedData() *prepairedDataMeta {
+	files, err := ioutil.ReadDir(m.Folder)
+	if err != nil {
+		return nil
+	}
+	current := m.currentPrepairedMeta()
+	var found *prepairedDataMeta
+
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), prepairedMetaExt) {
+			continue
+		}
+		meta, err := readPrepairedMeta(path.Join(m.Folder, f.Name()))
+		if err != nil {
+			continue
+		}
+		if !meta.sameSource(current) || !meta.ToDate.Before(current.ToDate) {
+			continue
+		}
+		if _, err := os.Stat(path.Join(m.Folder, meta.File)); err != nil {
+			continue
+		}
+		fingerprint, err := m.sourceFingerprint(meta.FromDate, meta.ToDate)
+		if err != nil || fingerprint != meta.Fingerprint {
+			continue
+		}
+		if found == nil || meta.ToDate.After(found.ToDate) {
+			found = meta
+		}
+	}
+
+	return found
+}
+
+//prepareIncrementally extends existing prepaired file with new dates if there is one. Otherwise it
+//prepares data from scratch.
+func (m *BTM) prepareIncrementally() {
+	base := m.findExtendablePrepairedData()
+	if base == nil {
+		m.prepare()
+		return
+	}
+
+	if m.prepairedDataExists() {
+		err := m.clearPrepairedData()
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	basePath := path.Join(m.Folder, base.File)
+	err := os.Rename(basePath, m.getPrepairedFilePath())
+	if err != nil {
+		panic(err)
+	}
+	err = os.Remove(basePath + prepairedMetaExt)
+	if err != nil {
+		m.newError(err)
+	}
+
+	switch m.mode {
+	case MarketDataModeTicksQuotes, MarketDataModeTicks, MarketDataModeQuotes:
+		if m.streaming != nil {
+			m.prepareTicksStreaming(base.ToDate.AddDate(0, 0, 1), m.ToDate, m.prepareWorkers())
+			break
+		}
+		for d := base.ToDate.AddDate(0, 0, 1); !d.After(m.ToDate); d = d.AddDate(0, 0, 1) {
+			m.loadDateTicks(d)
+		}
+	case MarketDataModeCandles:
+		m.appendCandles(base.ToDate)
+	default:
+		panic("Unknown market data mode: " + string(m.mode))
+	}
+
+	m.savePrepairedMeta()
+}
+
+//appendCandles loads candles stored after given time and appends them to prepaired file
+func (m *BTM) appendCandles(after time.Time) {
+	rng := marketdata.DateRange{
+		From: after,
+		To:   m.ToDate,
+	}
+	var totalcandles marketdata.CandleArray
+	for _, s := range m.Symbols {
+		for _, c := range m.symbolCandles(s, rng) {
+			if c.Datetime.After(after) {
+				totalcandles = append(totalcandles, c)
+			}
+		}
+	}
+
+	totalcandles.Sort()
+	m.writeCandles(totalcandles)
+}
+
+//savePrepairedMeta writes meta of current prepaired file with fingerprint of the source data
+func (m *BTM) savePrepairedMeta() {
+	meta := m.currentPrepairedMeta()
+	fingerprint, err := m.sourceFingerprint(m.FromDate, m.ToDate)
+	if err != nil {
+		m.newError(err)
+		return
+	}
+	meta.Fingerprint = fingerprint
+	err = m.writePrepairedMeta(meta)
+	if err != nil {
+		m.newError(err)
+	}
+}
@@ -0,0 +1,87 @@
+package engine
+
+//AddFuturesChain makes BTM load data of all chain contracts and produce continuous series of chain root
+//symbol with RollEvent on every roll. Contracts market data is not passed further.
+func (m *BTM) AddFuturesChain(c *FuturesChain) {
+	if m.futuresChains == nil {
+		m.futuresChains = make(map[string]*FuturesChain)
+	}
+	for _, fc := range c.Contracts {
+		m.futuresChains[fc.Contract.Symbol] = c
+	}
+	m.addFuturesSymbols()
+}
+
+func (m *BTM) addFuturesSymbols() {
+	for _, c := range m.futuresChains {
+		var contracts []*Instrument
+		for _, fc := range c.Contracts {
+			contracts = append(contracts, fc.Contract)
+		}
+		m.addSymbols(contracts)
+	}
+}
+
+//isFuturesRoot returns true for continuous series symbols which don't have their own stored data
+func (m *BTM) isFuturesRoot(symbol string) bool {
+	for _, c := range m.futuresChains {
+		if c.Root.Symbol == symbol {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *BTM) resetFuturesChains() {
+	for _, c := range m.futuresChains {
+		c.reset()
+	}
+}
+
+//continuousEvent passes contract market data through the chain. It returns copy of the event for chain
+//root if contract is the front one or nil otherwise. History events of contracts are not supported
+//and are dropped.
+func continuousEvent(c *FuturesChain, e event) (event, *RollEvent) {
+	switch i := e.(type) {
+	case *NewTickEvent:
+		isFront, roll := c.onData(i.getSymbol(), i.getTime(), i.Tick.LastPrice, i.Tick.LastSize, 0)
+		if !isFront {
+			return nil, roll
+		}
+		raw := *i.Tick.Tick
+		raw.Symbol = c.Root.Symbol
+		return &NewTickEvent{
+			BaseEvent: be(i.getTime(), c.Root),
+			Tick:      &Tick{Tick: &raw, Ticker: c.Root},
+		}, roll
+	case *CandleOpenEvent:
+		isFront, roll := c.onData(i.getSymbol(), i.getTime(), i.Price, 0, 0)
+		if !isFront {
+			return nil, roll
+		}
+		ce := *i
+		ce.BaseEvent = be(i.getTime(), c.Root)
+		return &ce, roll
+	case *CandleCloseEvent:
+		isFront, roll := c.onData(i.getSymbol(), i.getTime(), i.Candle.Close, i.Candle.Volume, i.Candle.OpenInterest)
+		if !isFront {
+			return nil, roll
+		}
+		raw := *i.Candle.Candle
+		raw.Symbol = c.Root.Symbol
+		return &CandleCloseEvent{
+			BaseEvent: be(i.getTime(), c.Root),
+			Candle:    &Candle{Candle: &raw, Ticker: c.Root},
+			TimeFrame: i.TimeFrame,
+		}, roll
+	case *DataGapEvent:
+		if c.contractIndex(i.getSymbol()) != c.front {
+			return nil, nil
+		}
+		ge := *i
+		ge.BaseEvent = be(i.getTime(), c.Root)
+		return &ge, nil
+	default:
+		return nil, nil
+	}
+}
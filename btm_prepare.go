@@ -0,0 +1,240 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"bufio"
+	"container/heap"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//prepareChunk is part of the date range and of symbols prepared by one worker to its own file
+type prepareChunk struct {
+	from    time.Time
+	to      time.Time
+	file    string
+	symbols []*Instrument
+}
+
+//prepareChunks splits date range to one day chunks
+func (m *BTM) prepareChunks(from time.Time, to time.Time) []*prepareChunk {
+	var chunks []*prepareChunk
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		chunks = append(chunks, &prepareChunk{
+			from:    d,
+			to:      d,
+			file:    m.tempFile(".chunk"),
+			symbols: m.Symbols,
+		})
+	}
+	return chunks
+}
+
+//tempFile creates empty temporary file next to prepaired file and returns its path. Names are unique, so
+//clones of BTM which share folder and dates don't overwrite files of each other.
+func (m *BTM) tempFile(suffix string) string {
+	pth := m.getPrepairedFilePath()
+	f, err := ioutil.TempFile(path.Dir(pth), path.Base(pth)+suffix)
+	if err != nil {
+		panic(err)
+	}
+	if err := f.Close(); err != nil {
+		panic(err)
+	}
+	return f.Name()
+}
+
+func (m *BTM) prepareWorkers() int {
+	if m.PrepareWorkers > 0 {
+		return m.PrepareWorkers
+	}
+	return runtime.NumCPU()
+}
+
+//prepareTicksParallel loads ticks of one day chunks with pool of workers. Every chunk is written sorted
+//to temporary file and chunks are merge sorted to prepaired file by parts of one day per worker, so only
+//days which are loaded at the moment are kept in memory.
+func (m *BTM) prepareTicksParallel() {
+	workers := m.prepareWorkers()
+	if m.streaming != nil {
+		m.prepareTicksStreaming(m.FromDate, m.ToDate, workers)
+		return
+	}
+	for d := m.FromDate; !d.After(m.ToDate); d = d.AddDate(0, 0, workers) {
+		end := d.AddDate(0, 0, workers-1)
+		if end.After(m.ToDate) {
+			end = m.ToDate
+		}
+		m.prepareTicksChunks(m.prepareChunks(d, end), workers)
+	}
+}
+
+//prepareTicksChunks loads chunks with pool of workers and merges them to the end of prepaired file
+func (m *BTM) prepareTicksChunks(chunks []*prepareChunk, workers int) {
+	chunksChan := make(chan *prepareChunk, len(chunks))
+	for _, c := range chunks {
+		chunksChan <- c
+	}
+	close(chunksChan)
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range chunksChan {
+				m.prepareTicksChunk(c)
+			}
+		}()
+	}
+	wg.Wait()
+
+	defer func() {
+		for _, c := range chunks {
+			if err := os.Remove(c.file); err != nil && !os.IsNotExist(err) {
+				m.newError(err)
+			}
+		}
+	}()
+
+	m.mergeChunks(chunks)
+}
+
+func (m *BTM) prepareTicksChunk(c *prepareChunk) {
+	var ticks marketdata.TickArray
+	for d := c.from; !d.After(c.to); d = d.AddDate(0, 0, 1) {
+		ticks = append(ticks, m.symbolsTicks(d, c.symbols)...)
+	}
+	ticks.Sort()
+
+	f, err := os.Create(c.file)
+	if err != nil {
+		panic(err)
+	}
+	defer func() {
+		err := f.Close()
+		if err != nil {
+			panic(err)
+		}
+	}()
+
+	w := bufio.NewWriter(f)
+	m.writeTicks(w, ticks)
+	if err := w.Flush(); err != nil {
+		panic(err)
+	}
+}
+
+//chunkLine is the current line of chunk file during merge
+type chunkLine struct {
+	time    int64
+	line    string
+	scanner *bufio.Scanner
+	order   int
+}
+
+type chunkLinesHeap []*chunkLine
+
+func (h chunkLinesHeap) Len() int { return len(h) }
+func (h chunkLinesHeap) Less(i, j int) bool {
+	if h[i].time == h[j].time {
+		return h[i].order < h[j].order
+	}
+	return h[i].time < h[j].time
+}
+func (h chunkLinesHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *chunkLinesHeap) Push(x interface{}) { *h = append(*h, x.(*chunkLine)) }
+func (h *chunkLinesHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+//lineTime returns unix time from the first column of prepaired data line
+func lineTime(l string) int64 {
+	i := strings.IndexByte(l, ',')
+	if i < 0 {
+		return 0
+	}
+	t, _ := strconv.ParseInt(l[:i], 10, 64)
+	return t
+}
+
+//next reads next line of chunk. It returns false if chunk is over.
+func (c *chunkLine) next() bool {
+	if !c.scanner.Scan() {
+		return false
+	}
+	c.line = c.scanner.Text()
+	c.time = lineTime(c.line)
+	return true
+}
+
+//mergeChunks merges sorted chunk files to prepaired file. Lines with equal time keep chunks order.
+func (m *BTM) mergeChunks(chunks []*prepareChunk) {
+	h := &chunkLinesHeap{}
+	for i, c := range chunks {
+		f, err := os.Open(c.file)
+		if err != nil {
+			panic(err)
+		}
+		defer f.Close()
+
+		cl := &chunkLine{scanner: bufio.NewScanner(f), order: i}
+		if cl.next() {
+			heap.Push(h, cl)
+		}
+	}
+
+	if h.Len() == 0 {
+		return
+	}
+
+	out, err := m.openPrepairedDataForAppend()
+	if err != nil {
+		panic(err)
+	}
+	defer func() {
+		err := out.Close()
+		if err != nil {
+			panic(err)
+		}
+	}()
+
+	w := bufio.NewWriter(out)
+	for h.Len() > 0 {
+		cl := (*h)[0]
+		if _, err := w.WriteString(cl.line + "\n"); err != nil {
+			m.newError(err)
+		}
+		if cl.next() {
+			heap.Fix(h, 0)
+		} else {
+			heap.Pop(h)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		panic(err)
+	}
+}
+
+//writeTicks writes ticks with trades in prepaired data format
+func (m *BTM) writeTicks(w io.Writer, ticks marketdata.TickArray) {
+	for _, t := range ticks {
+		if !t.HasTrade() {
+			continue
+		}
+		if _, err := w.Write([]byte(t.String() + "\n")); err != nil {
+			m.newError(err)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package engine
+
+import (
+	"time"
+)
+
+//progressReporter counts events produced by BTM and creates BacktestProgressEvent not more often than
+//once per interval. Zero interval disables reporting.
+type progressReporter struct {
+	interval time.Duration
+	from     time.Time
+	to       time.Time
+
+	events     int64
+	wallStart  time.Time
+	lastReport time.Time
+	now        func() time.Time
+}
+
+func (p *progressReporter) start(from time.Time, to time.Time) {
+	if p.now == nil {
+		p.now = time.Now
+	}
+	p.from = from
+	p.to = to
+	p.events = 0
+	p.wallStart = p.now()
+	p.lastReport = p.wallStart
+}
+
+//count registers event with given simulated time and returns progress event if it's time to report
+func (p *progressReporter) count(simTime time.Time) *BacktestProgressEvent {
+	p.events++
+	if p.interval <= 0 {
+		return nil
+	}
+	now := p.now()
+	if now.Sub(p.lastReport) < p.interval {
+		return nil
+	}
+	p.lastReport = now
+	return p.progress(simTime)
+}
+
+func (p *progressReporter) progress(simTime time.Time) *BacktestProgressEvent {
+	percent := 100.0
+	if total := p.to.Sub(p.from); total > 0 {
+		percent = 100 * float64(simTime.Sub(p.from)) / float64(total)
+	}
+	if percent < 0 {
+		percent = 0
+	}
+	if percent > 100 {
+		percent = 100
+	}
+
+	var eta time.Duration
+	if percent > 0 {
+		elapsed := p.now().Sub(p.wallStart)
+		eta = time.Duration(float64(elapsed) * (100 - percent) / percent)
+	}
+
+	return &BacktestProgressEvent{
+		BaseEvent:       be(simTime, &Instrument{}),
+		Percent:         percent,
+		EventsProcessed: p.events,
+		SimTime:         simTime,
+		ETA:             eta,
+	}
+}
+
+//SetProgressInterval makes BTM produce BacktestProgressEvent every interval of wall clock time.
+//Zero interval disables progress events.
+func (m *BTM) SetProgressInterval(d time.Duration) {
+	m.progress.interval = d
+}
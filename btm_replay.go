@@ -0,0 +1,69 @@
+package engine
+
+import (
+	"time"
+)
+
+//replayThrottle delays market data events so that simulated time goes with given speed relative to
+//wall clock. Zero speed means no delays.
+type replayThrottle struct {
+	speed    float64
+	maxPause time.Duration
+
+	simStart  time.Time
+	wallStart time.Time
+	now       func() time.Time
+	sleep     func(time.Duration)
+}
+
+func newReplayThrottle() *replayThrottle {
+	return &replayThrottle{now: time.Now, sleep: time.Sleep}
+}
+
+//reset makes next event the starting point of replay
+func (r *replayThrottle) reset() {
+	r.simStart = time.Time{}
+	r.wallStart = time.Time{}
+}
+
+//wait blocks until wall clock reaches time of the simulated event. Pauses longer than maxPause
+//(nights, weekends) are shortened to maxPause.
+func (r *replayThrottle) wait(simTime time.Time) {
+	if r.speed <= 0 {
+		return
+	}
+	if r.simStart.IsZero() {
+		r.simStart = simTime
+		r.wallStart = r.now()
+		return
+	}
+
+	delay := r.wallStart.Add(time.Duration(float64(simTime.Sub(r.simStart)) / r.speed)).Sub(r.now())
+	if r.maxPause > 0 && delay > r.maxPause {
+		r.sleep(r.maxPause)
+		r.simStart = simTime
+		r.wallStart = r.now()
+		return
+	}
+	if delay > 0 {
+		r.sleep(delay)
+	}
+}
+
+//SetReplaySpeed makes BTM emit events with simulated time going speed times faster than real time.
+//1 is real time, 60 is one simulated minute per second. Zero emits events as fast as possible.
+func (m *BTM) SetReplaySpeed(speed float64) {
+	if m.replay == nil {
+		m.replay = newReplayThrottle()
+	}
+	m.replay.speed = speed
+}
+
+//SetReplayMaxPause limits wall clock pause between two events during replay with speed, so gaps in data
+//don't stop replay for hours. Zero means no limit.
+func (m *BTM) SetReplayMaxPause(d time.Duration) {
+	if m.replay == nil {
+		m.replay = newReplayThrottle()
+	}
+	m.replay.maxPause = d
+}
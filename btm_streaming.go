@@ -0,0 +1,256 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+//StreamingConfig bounds memory used by backtests of large universes. Every prepare worker loads data of
+//SymbolsPerChunk symbols for DaysPerChunk days at once. Sorted chunks are written to temporary files of the
+//folder and merged into prepaired data one date range after another. Side events, e.g. news or auction events,
+//are spilled to temporary files as soon as they are loaded and are read back in time order during the run.
+//Zero values mean 100 symbols and one day. DaysPerChunk isn't used for candles, which are loaded for the whole
+//period.
+type StreamingConfig struct {
+	SymbolsPerChunk int
+	DaysPerChunk    int
+}
+
+func (c *StreamingConfig) symbolsPerChunk() int {
+	if c.SymbolsPerChunk > 0 {
+		return c.SymbolsPerChunk
+	}
+	return 100
+}
+
+func (c *StreamingConfig) daysPerChunk() int {
+	if c.DaysPerChunk > 0 {
+		return c.DaysPerChunk
+	}
+	return 1
+}
+
+//SetStreaming enables streaming mode. Nil disables it. Prepaired data and events are the same as without it.
+func (m *BTM) SetStreaming(cfg *StreamingConfig) error {
+	if cfg != nil && (cfg.SymbolsPerChunk < 0 || cfg.DaysPerChunk < 0) {
+		return errors.New("Symbols and days per chunk can't be negative. ")
+	}
+	m.streaming = cfg
+	return nil
+}
+
+//symbolGroups splits symbols to groups loaded at once in streaming mode. Groups keep order of symbols.
+func (m *BTM) symbolGroups() [][]*Instrument {
+	size := m.streaming.symbolsPerChunk()
+	var groups [][]*Instrument
+	for i := 0; i < len(m.Symbols); i += size {
+		end := i + size
+		if end > len(m.Symbols) {
+			end = len(m.Symbols)
+		}
+		groups = append(groups, m.Symbols[i:end])
+	}
+	return groups
+}
+
+//prepareTicksStreaming prepares ticks of the date range by parts of DaysPerChunk days. Chunks of the part are
+//symbol groups, they are merged in order of groups, so ticks of the same time keep order of symbols.
+func (m *BTM) prepareTicksStreaming(from time.Time, to time.Time, workers int) {
+	groups := m.symbolGroups()
+	days := m.streaming.daysPerChunk()
+	for d := from; !d.After(to); d = d.AddDate(0, 0, days) {
+		end := d.AddDate(0, 0, days-1)
+		if end.After(to) {
+			end = to
+		}
+		chunks := make([]*prepareChunk, 0, len(groups))
+		for _, g := range groups {
+			chunks = append(chunks, &prepareChunk{
+				from:    d,
+				to:      end,
+				file:    m.tempFile(".chunk"),
+				symbols: g,
+			})
+		}
+		m.prepareTicksChunks(chunks, workers)
+	}
+}
+
+//prepareCandlesStreaming writes sorted candles of every symbol group to chunk file and merges chunks to
+//prepaired file. It returns number of loaded candles.
+func (m *BTM) prepareCandlesStreaming(rng marketdata.DateRange) int {
+	var chunks []*prepareChunk
+	defer func() {
+		for _, c := range chunks {
+			if err := os.Remove(c.file); err != nil && !os.IsNotExist(err) {
+				m.newError(err)
+			}
+		}
+	}()
+
+	loaded := 0
+	for _, g := range m.symbolGroups() {
+		var candles marketdata.CandleArray
+		for _, s := range g {
+			candles = append(candles, m.symbolCandles(s, rng)...)
+		}
+		if len(candles) == 0 {
+			continue
+		}
+		loaded += len(candles)
+		candles.Sort()
+		c := &prepareChunk{file: m.tempFile(".chunk"), symbols: g}
+		chunks = append(chunks, c)
+		if err := writeCandlesFile(c.file, candles); err != nil {
+			panic(err)
+		}
+	}
+	m.mergeChunks(chunks)
+	return loaded
+}
+
+func writeCandlesFile(pth string, candles marketdata.CandleArray) error {
+	f, err := os.Create(pth)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for _, c := range candles {
+		if _, err := w.WriteString(c.String() + "\n"); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+//sideEventsSpill keeps side events in temporary files, one sorted file per storage, and reads them back merged
+//in time order. Only the next event of every file is kept in memory. Methods can be called on nil spill.
+type sideEventsSpill struct {
+	parts   []*spillPart
+	symbols map[string]*Instrument
+}
+
+//spillPart is temporary file of spilled events with its next event
+type spillPart struct {
+	pth  string
+	file *os.File
+	dec  *json.Decoder
+	head event
+}
+
+//add writes sorted events to the file and opens it for reading
+func (s *sideEventsSpill) add(pth string, events eventArray) error {
+	f, err := os.Create(pth)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, e := range events {
+		if err := enc.Encode(EventJSON{Event: e}); err != nil {
+			f.Close()
+			os.Remove(pth)
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		os.Remove(pth)
+		return err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		f.Close()
+		os.Remove(pth)
+		return err
+	}
+	p := &spillPart{pth: pth, file: f, dec: json.NewDecoder(bufio.NewReader(f))}
+	if err := s.read(p); err != nil {
+		return err
+	}
+	s.parts = append(s.parts, p)
+	return nil
+}
+
+//read decodes the next event of the part. File of the part is removed when it's over.
+func (s *sideEventsSpill) read(p *spillPart) error {
+	var e EventJSON
+	err := p.dec.Decode(&e)
+	if err != nil {
+		p.head = nil
+		p.close()
+		if err == io.EOF {
+			return nil
+		}
+		return err
+	}
+	replaceInstruments(e.Event, func(i *Instrument) *Instrument {
+		if i == nil {
+			return nil
+		}
+		if inst, ok := s.symbols[i.Symbol]; ok {
+			return inst
+		}
+		return i
+	})
+	p.head = e.Event
+	return nil
+}
+
+func (p *spillPart) close() {
+	if p.file == nil {
+		return
+	}
+	p.file.Close()
+	os.Remove(p.pth)
+	p.file = nil
+}
+
+//next returns part with the earliest event
+func (s *sideEventsSpill) next() *spillPart {
+	if s == nil {
+		return nil
+	}
+	var first *spillPart
+	for _, p := range s.parts {
+		if p.head != nil && (first == nil || eventBefore(p.head, first.head)) {
+			first = p
+		}
+	}
+	return first
+}
+
+//peek returns the earliest spilled event or nil if there are no more
+func (s *sideEventsSpill) peek() event {
+	if p := s.next(); p != nil {
+		return p.head
+	}
+	return nil
+}
+
+//pop removes the earliest spilled event. Error of reading the next one ends its file.
+func (s *sideEventsSpill) pop() error {
+	if p := s.next(); p != nil {
+		return s.read(p)
+	}
+	return nil
+}
+
+//close removes all temporary files
+func (s *sideEventsSpill) close() {
+	if s == nil {
+		return
+	}
+	for _, p := range s.parts {
+		p.close()
+	}
+}
@@ -0,0 +1,176 @@
+package engine
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//assertNoChunks checks that temporary files of prepare and spill are removed
+func assertNoChunks(t *testing.T, folder string) {
+	files, err := ioutil.ReadDir(folder)
+	assert.Nil(t, err)
+	for _, f := range files {
+		assert.NotContains(t, f.Name(), ".chunk")
+		assert.NotContains(t, f.Name(), ".side")
+	}
+}
+
+func TestBTM_prepareStreaming(t *testing.T) {
+	folder, err := ioutil.TempDir("", "btm_streaming")
+	assert.Nil(t, err)
+	defer os.RemoveAll(folder)
+
+	assert.NotNil(t, (&BTM{}).SetStreaming(&StreamingConfig{SymbolsPerChunk: -1}))
+	serial := newTestBTMforSyntheticTicks(path.Join(folder, "serial"), 1)
+	streaming := newTestBTMforSyntheticTicks(path.Join(folder, "streaming"), 2)
+	assert.Nil(t, streaming.SetStreaming(&StreamingConfig{SymbolsPerChunk: 2, DaysPerChunk: 3}))
+	assert.Len(t, streaming.symbolGroups(), 2)
+	for _, b := range []*BTM{serial, streaming} {
+		assert.Nil(t, createDirIfNotExists(b.Folder))
+		b.prepare()
+	}
+	serialData, err := ioutil.ReadFile(serial.getPrepairedFilePath())
+	assert.Nil(t, err)
+	streamingData, err := ioutil.ReadFile(streaming.getPrepairedFilePath())
+	assert.Nil(t, err)
+	assert.NotEmpty(t, serialData)
+	assert.Equal(t, string(serialData), string(streamingData))
+	assertNoChunks(t, streaming.Folder)
+
+	//Candles of symbol groups are merged to the same file
+	candles := newTestWarmUpBTM(MarketDataModeCandles)
+	candles.Symbols = append(candles.Symbols, &Instrument{Symbol: "Sym3"})
+	candles.Folder = path.Join(folder, "candles")
+	assert.Nil(t, createDirIfNotExists(candles.Folder))
+	candles.prepare()
+	candlesData, err := ioutil.ReadFile(candles.getPrepairedFilePath())
+	assert.Nil(t, err)
+	assert.Nil(t, candles.clearPrepairedData())
+	assert.Nil(t, candles.SetStreaming(&StreamingConfig{SymbolsPerChunk: 1}))
+	candles.prepare()
+	streamingData, err = ioutil.ReadFile(candles.getPrepairedFilePath())
+	assert.Nil(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(string(candlesData)), "\n"), 15)
+	assert.Equal(t, string(candlesData), string(streamingData))
+	assertNoChunks(t, candles.Folder)
+}
+
+func TestSideEventsSpill(t *testing.T) {
+	folder, err := ioutil.TempDir("", "btm_spill")
+	assert.Nil(t, err)
+	defer os.RemoveAll(folder)
+	inst := newTestInstrument()
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	news := eventArray{
+		&NewsEvent{BaseEvent: be(tm, inst), Headline: "first"},
+		&NewsEvent{BaseEvent: be(tm.Add(2*time.Hour), inst), Headline: "third"},
+	}
+	dividends := eventArray{&DividendEvent{BaseEvent: be(tm.Add(time.Hour), inst), Amount: 0.5}}
+
+	var empty *sideEventsSpill
+	assert.Nil(t, empty.peek())
+	s := &sideEventsSpill{symbols: map[string]*Instrument{inst.Symbol: inst}}
+	assert.Nil(t, s.add(path.Join(folder, "news"), news))
+	assert.Nil(t, s.add(path.Join(folder, "dividends"), dividends))
+
+	var got []event
+	for e := s.peek(); e != nil; e = s.peek() {
+		got = append(got, e)
+		assert.Nil(t, s.pop())
+	}
+	if assert.Len(t, got, 3) {
+		assert.Equal(t, "first", got[0].(*NewsEvent).Headline)
+		assert.Equal(t, 0.5, got[1].(*DividendEvent).Amount)
+		assert.Equal(t, "third", got[2].(*NewsEvent).Headline)
+		assert.True(t, got[0].(*NewsEvent).Ticker == inst)
+		assert.Equal(t, tm.Add(time.Hour), got[1].getTime())
+	}
+
+	//Files are removed when they are read or spill is closed
+	files, err := ioutil.ReadDir(folder)
+	assert.Nil(t, err)
+	assert.Empty(t, files)
+	assert.Nil(t, s.add(path.Join(folder, "news"), news))
+	s.close()
+	files, err = ioutil.ReadDir(folder)
+	assert.Nil(t, err)
+	assert.Empty(t, files)
+}
+
+func TestEngine_Streaming(t *testing.T) {
+	dir, err := ioutil.TempDir("", "btm_streaming")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	build := func(folder string, streaming *StreamingConfig) func() (*Engine, error) {
+		return func() (*Engine, error) {
+			md := newTestBTMforSyntheticTicks(path.Join(dir, folder), 2)
+			if err := createDirIfNotExists(md.Folder); err != nil {
+				return nil, err
+			}
+			if err := md.SetAuction(&OpeningAuction{Lead: 10 * time.Minute, Interval: 5 * time.Minute}); err != nil {
+				return nil, err
+			}
+			if err := md.SetStreaming(streaming); err != nil {
+				return nil, err
+			}
+			return New(WithMarketData(md), WithBroker(&SimBroker{}),
+				WithStrategy(&Instrument{Symbol: "Sym1"}, &manifestTestStrategy{}, 1),
+				WithStrategy(&Instrument{Symbol: "Sym3"}, &manifestTestStrategy{}, 1))
+		}
+	}
+
+	//Streaming run has the same events and fills
+	memory, err := runRecorded(build("memory", nil), true)
+	assert.Nil(t, err)
+	streaming, err := runRecorded(build("streaming", &StreamingConfig{SymbolsPerChunk: 1}), true)
+	assert.Nil(t, err)
+	assert.Nil(t, compareRuns(memory, streaming))
+	assert.NotEmpty(t, streaming.fills)
+	assert.Contains(t, strings.Join(streaming.journal, "\n"), "AuctionImbalanceEvent")
+	assertNoChunks(t, path.Join(dir, "streaming"))
+}
+
+func TestEngine_StreamingClones(t *testing.T) {
+	dir, err := ioutil.TempDir("", "btm_streaming")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	md := newTestBTMforSyntheticTicks(dir, 2)
+	assert.Nil(t, md.SetAuction(&OpeningAuction{Lead: 10 * time.Minute, Interval: 5 * time.Minute}))
+	assert.Nil(t, md.SetStreaming(&StreamingConfig{SymbolsPerChunk: 1}))
+	build := func() (*Engine, error) {
+		return New(WithMarketData(md.clone()), WithBroker(&SimBroker{}),
+			WithStrategy(&Instrument{Symbol: "Sym1"}, &manifestTestStrategy{}, 1))
+	}
+	first, err := runRecorded(build, true)
+	assert.Nil(t, err)
+
+	//Clones which run at once in the same folder don't share temporary files
+	a, b := md.clone().tempFile(".side"), md.clone().tempFile(".side")
+	assert.NotEqual(t, a, b)
+	assert.True(t, strings.HasPrefix(path.Base(a), path.Base(md.getPrepairedFilePath())+".side"))
+	assert.Nil(t, os.Remove(a))
+	assert.Nil(t, os.Remove(b))
+	runs := make([]recordedRun, 3)
+	errs := make([]error, len(runs))
+	wg := &sync.WaitGroup{}
+	for i := range runs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			runs[i], errs[i] = runRecorded(build, true)
+		}(i)
+	}
+	wg.Wait()
+	for i := range runs {
+		assert.Nil(t, errs[i])
+		assert.Nil(t, compareRuns(first, runs[i]))
+	}
+	assertNoChunks(t, dir)
+}
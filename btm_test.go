@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"io/ioutil"
 	"os"
+	"path"
 	"strconv"
 	"strings"
 	"sync"
@@ -408,3 +409,268 @@ func TestBTM_RunCandles(t *testing.T) {
 	}*/
 
 }
+
+func TestDataGapDetector_check(t *testing.T) {
+	d := dataGapDetector{threshold: time.Hour}
+	inst := newTestInstrument()
+	start := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+
+	t.Log("First data for symbol is never a gap")
+	{
+		assert.Nil(t, d.check(inst, start))
+		d.seen(inst, start)
+	}
+
+	t.Log("Data inside threshold")
+	{
+		assert.Nil(t, d.check(inst, start.Add(time.Hour)))
+		d.seen(inst, start.Add(time.Hour))
+	}
+
+	t.Log("Data after the gap")
+	{
+		gap := d.check(inst, start.Add(5*time.Hour))
+		assert.NotNil(t, gap)
+		assert.Equal(t, start.Add(time.Hour), gap.From)
+		assert.Equal(t, start.Add(5*time.Hour), gap.To)
+		assert.Equal(t, 4*time.Hour, gap.Duration())
+		assert.Equal(t, inst.Symbol, gap.getSymbol())
+	}
+
+	t.Log("Zero threshold disables detection")
+	{
+		d.threshold = 0
+		assert.Nil(t, d.check(inst, start.Add(50*time.Hour)))
+	}
+}
+
+func countBTMTickEvents(t *testing.T, b *BTM) int {
+	go b.genTickEvents()
+	n := 0
+	for {
+		select {
+		case e := <-b.mdChan:
+			switch e.(type) {
+			case *NewTickEvent:
+				n++
+			case *EndOfDataEvent:
+				return n
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Not found EndOfDataEvent")
+			return n
+		}
+	}
+}
+
+func TestBTM_CompressedPrepairedData(t *testing.T) {
+	plain := newTestBTMforTicks()
+	err := plain.clearPrepairedData()
+	assert.Nil(t, err)
+	plain.prepare()
+
+	b := newTestBTMforTicks()
+	b.CompressPrepairedData = true
+	err = b.clearPrepairedData()
+	assert.Nil(t, err)
+
+	assert.NotEqual(t, plain.getPrepairedFilePath(), b.getPrepairedFilePath())
+	assert.True(t, strings.HasSuffix(b.getPrepairedFilePath(), ".gz"))
+
+	b.prepare()
+	assert.True(t, b.prepairedDataExists())
+
+	t.Log("Prepaired file is gzip stream")
+	{
+		f, err := os.Open(b.getPrepairedFilePath())
+		assert.Nil(t, err)
+		header := make([]byte, 2)
+		_, err = f.Read(header)
+		assert.Nil(t, err)
+		assert.Equal(t, []byte{0x1f, 0x8b}, header)
+		f.Close()
+	}
+
+	t.Log("Compressed and plain data produce the same events")
+	{
+		assert.Equal(t, countBTMTickEvents(t, plain), countBTMTickEvents(t, b))
+	}
+}
+
+type fingerprintStorageJSON struct {
+	mockStorageJSON
+	version int
+}
+
+func (s *fingerprintStorageJSON) Fingerprint(symbol string, dRange marketdata.DateRange) (string, error) {
+	return fmt.Sprintf("%v|%v|%v", symbol, dRange.To.Unix(), s.version), nil
+}
+
+func TestBTM_prepareIncrementally(t *testing.T) {
+	full := newTestBTMforTicks()
+	full.prepare()
+	expected := countBTMTickEvents(t, full)
+
+	folder := "./test_data/BTM_incremental"
+	err := os.RemoveAll(folder)
+	assert.Nil(t, err)
+	err = createDirIfNotExists(folder)
+	assert.Nil(t, err)
+
+	storage := &fingerprintStorageJSON{mockStorageJSON: mockStorageJSON{folder: "./test_data/json_storage/ticks/quotes_trades"}}
+
+	b := newTestBTMforTicks()
+	b.Folder = folder
+	b.Storage = storage
+	b.ToDate = time.Date(2018, 3, 6, 0, 0, 0, 0, time.UTC)
+
+	t.Log("Nothing to extend. Prepare from scratch")
+	{
+		assert.Nil(t, b.findExtendablePrepairedData())
+		assert.False(t, b.prepairedDataIsActual())
+		b.prepareIncrementally()
+		assert.True(t, b.prepairedDataIsActual())
+	}
+
+	t.Log("Changed source data makes prepaired data outdated")
+	{
+		storage.version++
+		assert.False(t, b.prepairedDataIsActual())
+		assert.Nil(t, b.findExtendablePrepairedData())
+		storage.version--
+		assert.True(t, b.prepairedDataIsActual())
+	}
+
+	t.Log("Extend prepaired data with new dates")
+	{
+		ext := newTestBTMforTicks()
+		ext.Folder = folder
+		ext.Storage = storage
+
+		base := ext.findExtendablePrepairedData()
+		assert.NotNil(t, base)
+		assert.Equal(t, b.ToDate, base.ToDate)
+
+		ext.prepareIncrementally()
+		assert.True(t, ext.prepairedDataIsActual())
+		assert.False(t, b.prepairedDataExists())
+		assert.True(t, prepairedDataIsSorted(ext.getPrepairedFilePath(), t))
+		assert.Equal(t, expected, countBTMTickEvents(t, ext))
+	}
+}
+
+func TestReplayThrottle_wait(t *testing.T) {
+	wall := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	var slept []time.Duration
+	r := newReplayThrottle()
+	r.now = func() time.Time { return wall }
+	r.sleep = func(d time.Duration) {
+		slept = append(slept, d)
+		wall = wall.Add(d)
+	}
+	sim := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+
+	t.Log("Zero speed doesn't wait")
+	{
+		r.wait(sim)
+		r.wait(sim.Add(time.Hour))
+		assert.Len(t, slept, 0)
+	}
+
+	t.Log("60x speed waits one second for simulated minute")
+	{
+		r.speed = 60
+		r.wait(sim)
+		r.wait(sim.Add(time.Minute))
+		r.wait(sim.Add(3 * time.Minute))
+		assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, slept)
+	}
+
+	t.Log("Long pauses are limited")
+	{
+		slept = nil
+		r.maxPause = 5 * time.Second
+		r.wait(sim.Add(24 * time.Hour))
+		r.wait(sim.Add(24*time.Hour + time.Minute))
+		assert.Equal(t, []time.Duration{5 * time.Second, time.Second}, slept)
+	}
+}
+
+func TestProgressReporter_count(t *testing.T) {
+	wall := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := progressReporter{
+		interval: time.Second,
+		now:      func() time.Time { return wall },
+	}
+	from := time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC)
+	p.start(from, from.AddDate(0, 0, 10))
+
+	assert.Nil(t, p.count(from.AddDate(0, 0, 1)))
+
+	wall = wall.Add(2 * time.Second)
+	e := p.count(from.AddDate(0, 0, 2))
+	assert.NotNil(t, e)
+	assert.InDelta(t, 20.0, e.Percent, 0.0001)
+	assert.Equal(t, int64(2), e.EventsProcessed)
+	assert.Equal(t, 8*time.Second, e.ETA)
+
+	assert.Nil(t, p.count(from.AddDate(0, 0, 3)))
+}
+
+func newTestBTMforSyntheticTicks(folder string, workers int) *BTM {
+	storage := NewSyntheticStorage(3, time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC), 100, &GBMProcess{Volatility: 0.3})
+	storage.TickInterval = 10 * time.Minute
+	b := BTM{
+		Symbols:        []*Instrument{{Symbol: "Sym1"}, {Symbol: "Sym2"}, {Symbol: "Sym3"}},
+		Folder:         folder,
+		mode:           MarketDataModeTicks,
+		FromDate:       time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC),
+		ToDate:         time.Date(2018, 3, 20, 0, 0, 0, 0, time.UTC),
+		Storage:        storage,
+		PrepareWorkers: workers,
+		waitGroup:      &sync.WaitGroup{},
+	}
+	b.Init(make(chan error), make(chan event))
+	return &b
+}
+
+func TestBTM_prepareTicksParallel(t *testing.T) {
+	folder, err := ioutil.TempDir("", "btm_prepare")
+	assert.Nil(t, err)
+	defer os.RemoveAll(folder)
+
+	serial := newTestBTMforSyntheticTicks(path.Join(folder, "serial"), 1)
+	parallel := newTestBTMforSyntheticTicks(path.Join(folder, "parallel"), 4)
+	for _, b := range []*BTM{serial, parallel} {
+		assert.Nil(t, createDirIfNotExists(b.Folder))
+		b.prepare()
+	}
+
+	serialData, err := ioutil.ReadFile(serial.getPrepairedFilePath())
+	assert.Nil(t, err)
+	parallelData, err := ioutil.ReadFile(parallel.getPrepairedFilePath())
+	assert.Nil(t, err)
+	assert.Equal(t, string(serialData), string(parallelData))
+
+	lines := strings.Split(strings.TrimSpace(string(parallelData)), "\n")
+	assert.Len(t, lines, 14*39*3)
+	for i := 1; i < len(lines); i++ {
+		assert.False(t, lineTime(lines[i]) < lineTime(lines[i-1]))
+	}
+
+	files, err := ioutil.ReadDir(parallel.Folder)
+	assert.Nil(t, err)
+	for _, f := range files {
+		assert.NotContains(t, f.Name(), ".chunk")
+	}
+
+	t.Log("Chunks have one day, so memory doesn't grow with date range")
+	{
+		chunks := parallel.prepareChunks(parallel.FromDate, parallel.ToDate)
+		assert.Len(t, chunks, 20)
+		for _, c := range chunks {
+			assert.Equal(t, c.from, c.to)
+			assert.Nil(t, os.Remove(c.file))
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"time"
+)
+
+const (
+	//maxWarmUpLookback limits how far before FromDate BTM looks for warm up candles
+	maxWarmUpLookback = 5 * 365 * 24 * time.Hour
+	//maxWarmUpTickDays limits how many days before FromDate BTM looks for warm up ticks
+	maxWarmUpTickDays = 30
+)
+
+//IWarmUpMarketData can be implemented by market data which can send history before the first event.
+//Periods is number of candles or ticks needed by every symbol.
+type IWarmUpMarketData interface {
+	RequestWarmUp(periods map[string]int)
+}
+
+//warmUpStrategy is implemented by strategies which tell how much history they need
+type warmUpStrategy interface {
+	warmUpPeriods() int
+}
+
+//RequestWarmUp makes BTM send CandlesHistoryEvent or TickHistoryEvent with data before FromDate for every
+//symbol before the first event of the run
+func (m *BTM) RequestWarmUp(periods map[string]int) {
+	m.warmUpPeriods = periods
+}
+
+//emitWarmUp sends history of all symbols which requested warm up
+func (m *BTM) emitWarmUp() {
+	for _, s := range m.Symbols {
+		n := m.warmUpPeriods[s.Symbol]
+		if n <= 0 || m.isFuturesRoot(s.Symbol) {
+			continue
+		}
+		if m.mode == MarketDataModeCandles {
+			candles := m.warmUpCandles(s, n)
+			if len(candles) == 0 {
+				continue
+			}
+			m.send(&CandlesHistoryEvent{BaseEvent: be(candles[len(candles)-1].Datetime, s), Candles: candles})
+			continue
+		}
+		ticks := m.warmUpTicks(s, n)
+		if len(ticks) == 0 {
+			continue
+		}
+		m.send(&TickHistoryEvent{BaseEvent: be(ticks[len(ticks)-1].Datetime, s), Ticks: ticks})
+	}
+}
+
+//warmUpCandles returns last n candles before FromDate. Lookback period is doubled until there are enough
+//candles, so weekends and holidays are handled.
+func (m *BTM) warmUpCandles(s *Instrument, n int) CandleArray {
+	step, err := timeFrameDuration(m.candlesTimeFrame)
+	if err != nil {
+		m.newError(err)
+		return nil
+	}
+	lookback := step * time.Duration(n)
+	if lookback < 24*time.Hour {
+		lookback = 24 * time.Hour
+	}
+	for {
+		rng := marketdata.DateRange{From: m.FromDate.Add(-lookback), To: m.FromDate.Add(-time.Nanosecond)}
+		raw, err := m.Storage.GetStoredCandles(s.Symbol, m.candlesTimeFrame, rng)
+		if err != nil && raw == nil {
+			m.newError(err)
+			return nil
+		}
+		s.normalizeCandles(raw)
+		if len(raw) >= n || lookback >= maxWarmUpLookback {
+			var candles CandleArray
+			for _, c := range raw {
+				if c.Datetime.Before(m.FromDate) {
+					candles = append(candles, &Candle{Candle: c, Ticker: s})
+				}
+			}
+			if len(candles) > n {
+				candles = candles[len(candles)-n:]
+			}
+			return candles
+		}
+		lookback *= 2
+	}
+}
+
+//warmUpTicks returns last n trade ticks of trading days before FromDate
+func (m *BTM) warmUpTicks(s *Instrument, n int) TickArray {
+	loadQuotes := m.mode == MarketDataModeTicksQuotes || m.mode == MarketDataModeQuotes
+	var ticks TickArray
+	d := m.FromDate
+	for i := 0; i < maxWarmUpTickDays && len(ticks) < n; i++ {
+		d = d.AddDate(0, 0, -1)
+		if !s.Exchange.IsTradingDay(d) {
+			continue
+		}
+		rng := marketdata.DateRange{
+			From: time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC),
+			To:   time.Date(d.Year(), d.Month(), d.Day(), 23, 59, 59, 59, time.UTC),
+		}
+		raw, err := m.Storage.GetStoredTicks(s.Symbol, rng, loadQuotes, true)
+		if err != nil && raw == nil {
+			m.newError(err)
+			return nil
+		}
+		s.normalizeTicks(raw)
+		raw.Sort()
+		var day TickArray
+		for _, t := range raw {
+			if t.HasTrade() && t.Datetime.Before(m.FromDate) {
+				day = append(day, &Tick{Tick: t, Ticker: s})
+			}
+		}
+		ticks = append(day, ticks...)
+	}
+	if len(ticks) > n {
+		ticks = ticks[len(ticks)-n:]
+	}
+	return ticks
+}
@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestWarmUpBTM(mode MarketDataMode) *BTM {
+	storage := NewSyntheticStorage(7, time.Date(2018, 2, 1, 0, 0, 0, 0, time.UTC), 100, &GBMProcess{Volatility: 0.2})
+	storage.TickInterval = time.Hour
+	m := BTM{
+		Symbols:          []*Instrument{{Symbol: "Sym1"}, {Symbol: "Sym2"}},
+		FromDate:         time.Date(2018, 3, 5, 0, 0, 0, 0, time.UTC),
+		ToDate:           time.Date(2018, 3, 9, 0, 0, 0, 0, time.UTC),
+		Storage:          storage,
+		mode:             mode,
+		candlesTimeFrame: "D",
+		waitGroup:        &sync.WaitGroup{},
+		mdChan:           make(chan event, 10),
+	}
+	return &m
+}
+
+func TestBTM_emitWarmUpCandles(t *testing.T) {
+	m := newTestWarmUpBTM(MarketDataModeCandles)
+	m.RequestWarmUp(map[string]int{"Sym1": 8})
+	m.emitWarmUp()
+
+	assert.Len(t, m.mdChan, 1)
+	e := (<-m.mdChan).(*CandlesHistoryEvent)
+	assert.Equal(t, "Sym1", e.getSymbol())
+	assert.Len(t, e.Candles, 8)
+	assert.True(t, e.Candles[7].Datetime.Before(m.FromDate))
+	assert.Equal(t, time.Date(2018, 3, 2, 0, 0, 0, 0, time.UTC), e.Candles[7].Datetime)
+}
+
+func TestBTM_emitWarmUpTicks(t *testing.T) {
+	m := newTestWarmUpBTM(MarketDataModeTicks)
+	m.RequestWarmUp(map[string]int{"Sym1": 10, "Sym2": 3})
+	m.emitWarmUp()
+
+	assert.Len(t, m.mdChan, 2)
+	e := (<-m.mdChan).(*TickHistoryEvent)
+	assert.Len(t, e.Ticks, 10)
+	assert.True(t, e.Ticks[9].Datetime.Before(m.FromDate))
+	assert.True(t, e.Ticks[0].Datetime.Before(e.Ticks[9].Datetime))
+	e = (<-m.mdChan).(*TickHistoryEvent)
+	assert.Equal(t, "Sym2", e.getSymbol())
+	assert.Len(t, e.Ticks, 3)
+}
+
+func TestBasicStrategy_historyUpdatesIndicators(t *testing.T) {
+	st := newTestBasicStrategy()
+	st.AddIndicator("sma", NewSMA(2))
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	st.onCandleHistoryHandler(&CandlesHistoryEvent{
+		BaseEvent: be(tm, st.symbol),
+		Candles: CandleArray{
+			newTestMinuteCandle(tm.Add(time.Minute), 12, 12, 12, 12),
+			newTestMinuteCandle(tm, 10, 10, 10, 10),
+		},
+	})
+	assert.InDelta(t, 11.0, st.Indicator("sma").Value(), 0.0000001)
+	assert.Equal(t, 20, st.warmUpPeriods())
+}
+
+//warmUpTestStrategy records if indicator was ready at the first closed candle
+type warmUpTestStrategy struct {
+	DummyStrategy
+	closes int
+	ready  bool
+}
+
+func (s *warmUpTestStrategy) OnCandleClose(b *BasicStrategy, candle *Candle) {
+	if s.closes == 0 {
+		s.ready = b.Indicator("sma").Ready()
+	}
+	s.closes++
+}
+
+func TestEngine_WarmUpCandles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "warmup")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	md := newTestWarmUpBTM(MarketDataModeCandles)
+	md.Folder = dir
+	user := &warmUpTestStrategy{}
+	st := NewBasicStrategy(&Instrument{Symbol: "Sym1"}, 5, user)
+	st.AddIndicator("sma", NewSMA(5))
+	e, err := New(WithMarketData(md), WithBroker(&SimBroker{}), WithCoreStrategy(st))
+	assert.Nil(t, err)
+	e.SetWarmUp(true)
+	e.Run()
+	assert.Nil(t, e.Status().Err)
+	assert.True(t, user.closes > 0)
+	assert.True(t, user.ready)
+}
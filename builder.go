@@ -0,0 +1,284 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+//PortfolioConfig is initial state and limits of the portfolio. Zero values keep engine defaults.
+type PortfolioConfig struct {
+	InitialCapital  float64
+	AccountCurrency string
+	MaxDrawdown     float64
+	Allocation      map[string]float64
+}
+
+//engineConfig is collected by options and validated before engine is created
+type engineConfig struct {
+	broker     IBroker
+	md         IMarketData
+	mode       EngineMode
+	logEvents  bool
+	strategies map[string]ICoreStrategy
+	factory    StrategyFactory
+	portfolio  *PortfolioConfig
+	queue      *BackpressureConfig
+	metrics    IMetrics
+	disable    bool
+	logger     ILogger
+
+	runMode       RunMode
+	liveMD        IMarketData
+	liveBroker    IBroker
+	liveConfirmed bool
+}
+
+//Option configures engine created by New
+type Option func(c *engineConfig) error
+
+//WithBroker sets broker of the engine
+func WithBroker(b IBroker) Option {
+	return func(c *engineConfig) error {
+		if b == nil {
+			return errors.New("Broker is nil. ")
+		}
+		c.broker = b
+		return nil
+	}
+}
+
+//WithMarketData sets market data of the engine
+func WithMarketData(md IMarketData) Option {
+	return func(c *engineConfig) error {
+		if md == nil {
+			return errors.New("Market data is nil. ")
+		}
+		c.md = md
+		return nil
+	}
+}
+
+//WithStrategy adds basic strategy of the symbol with given user strategy. User strategy gets market data
+//callbacks when strategy has at least nPeriods candles.
+func WithStrategy(symbol *Instrument, userStrategy IUserStrategy, nPeriods int) Option {
+	return func(c *engineConfig) error {
+		if err := checkStrategy(symbol, userStrategy, nPeriods); err != nil {
+			return err
+		}
+		return c.addStrategy(symbol.Symbol, NewBasicStrategy(symbol, nPeriods, userStrategy))
+	}
+}
+
+//WithNamedStrategy adds strategy with unique name to StrategyGroup of the symbol, so several strategies can
+//trade one symbol. Each of them has its own trades and orders.
+func WithNamedStrategy(name string, symbol *Instrument, userStrategy IUserStrategy, nPeriods int) Option {
+	return func(c *engineConfig) error {
+		if err := checkStrategy(symbol, userStrategy, nPeriods); err != nil {
+			return err
+		}
+		st, ok := c.strategies[symbol.Symbol]
+		if !ok {
+			st = NewStrategyGroup(symbol)
+			c.strategies[symbol.Symbol] = st
+		}
+		group, ok := st.(*StrategyGroup)
+		if !ok {
+			return fmt.Errorf("Strategy of %v is added without name. ", symbol.Symbol)
+		}
+		return group.Add(name, NewBasicStrategy(symbol, nPeriods, userStrategy))
+	}
+}
+
+func checkStrategy(symbol *Instrument, userStrategy IUserStrategy, nPeriods int) error {
+	if symbol == nil || symbol.Symbol == "" {
+		return errors.New("Strategy symbol is empty. ")
+	}
+	if userStrategy == nil {
+		return fmt.Errorf("User strategy of %v is nil. ", symbol.Symbol)
+	}
+	if nPeriods < 1 {
+		return fmt.Errorf("Strategy of %v should have at least one period. ", symbol.Symbol)
+	}
+	return nil
+}
+
+//WithCoreStrategy adds strategy which is already created, e.g. basic strategy with stop loss settings
+func WithCoreStrategy(st ICoreStrategy) Option {
+	return func(c *engineConfig) error {
+		if st == nil || st.getInstrument() == nil || st.getInstrument().Symbol == "" {
+			return errors.New("Strategy or its symbol is empty. ")
+		}
+		return c.addStrategy(st.getInstrument().Symbol, st)
+	}
+}
+
+//WithStrategyFactory sets factory of strategies for symbols added to the universe during the run
+func WithStrategyFactory(f StrategyFactory) Option {
+	return func(c *engineConfig) error {
+		c.factory = f
+		return nil
+	}
+}
+
+//WithMode sets mode of the engine. Default mode is BacktestMode.
+func WithMode(mode EngineMode) Option {
+	return func(c *engineConfig) error {
+		if mode != BacktestMode && mode != MarketReplayMode {
+			return fmt.Errorf("Unknown engine mode %v. ", mode)
+		}
+		c.mode = mode
+		return nil
+	}
+}
+
+//WithEventLogging makes strategies write their events to StrategyLogs folder
+func WithEventLogging(enabled bool) Option {
+	return func(c *engineConfig) error {
+		c.logEvents = enabled
+		return nil
+	}
+}
+
+//WithPortfolio sets initial capital, account currency, drawdown limit and allocations of the portfolio
+func WithPortfolio(p PortfolioConfig) Option {
+	return func(c *engineConfig) error {
+		if p.InitialCapital < 0 {
+			return errors.New("Initial capital can't be negative. ")
+		}
+		if p.Allocation != nil {
+			//Check weights before engine is created
+			if err := newPortfolio().setAllocation(p.Allocation); err != nil {
+				return err
+			}
+		}
+		if p.MaxDrawdown < 0 || p.MaxDrawdown >= 1 || math.IsNaN(p.MaxDrawdown) {
+			return errors.New("Max drawdown should be in [0, 1) range. ")
+		}
+		c.portfolio = &p
+		return nil
+	}
+}
+
+//WithBackpressure puts bounded queue with given policy between engine and every strategy
+func WithBackpressure(cfg BackpressureConfig) Option {
+	return func(c *engineConfig) error {
+		if err := cfg.validate(); err != nil {
+			return err
+		}
+		c.queue = &cfg
+		return nil
+	}
+}
+
+//WithMetrics sets receiver of runtime metrics of engine and strategies
+func WithMetrics(m IMetrics) Option {
+	return func(c *engineConfig) error {
+		c.metrics = m
+		return nil
+	}
+}
+
+//WithDisableOnPanic disables strategy after panic of its user strategy
+func WithDisableOnPanic() Option {
+	return func(c *engineConfig) error {
+		c.disable = true
+		return nil
+	}
+}
+
+//WithLogger sets logger of engine, broker, market data and strategies
+func WithLogger(l ILogger) Option {
+	return func(c *engineConfig) error {
+		c.logger = l
+		return nil
+	}
+}
+
+func (c *engineConfig) addStrategy(symbol string, st ICoreStrategy) error {
+	if _, ok := c.strategies[symbol]; ok {
+		return fmt.Errorf("Strategy of %v is added twice. Use WithNamedStrategy to run several strategies on one symbol. ", symbol)
+	}
+	c.strategies[symbol] = st
+	return nil
+}
+
+func (c *engineConfig) validate() error {
+	if len(c.strategies) == 0 && c.factory == nil {
+		return errors.New("Engine needs at least one strategy or strategy factory. ")
+	}
+	if c.portfolio != nil {
+		for s := range c.portfolio.Allocation {
+			if _, ok := c.strategies[s]; !ok && c.factory == nil {
+				return fmt.Errorf("Allocation is set for %v, but there is no its strategy. ", s)
+			}
+		}
+	}
+	for s, st := range c.strategies {
+		if ps, ok := st.(paramsStrategy); ok {
+			if err := ps.validateParams(); err != nil {
+				return fmt.Errorf("Invalid parameters of %v strategy: %v", s, err)
+			}
+		}
+	}
+	return nil
+}
+
+//New creates engine from options. It checks configuration, connects strategies, broker and market data and
+//applies portfolio settings, so engine is ready to Run.
+func New(opts ...Option) (*Engine, error) {
+	cfg := engineConfig{mode: BacktestMode, runMode: RunModeBacktest, strategies: make(map[string]ICoreStrategy)}
+	for _, o := range opts {
+		if err := o(&cfg); err != nil {
+			return nil, err
+		}
+	}
+	md, broker, err := cfg.endpoints()
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	e := NewEngine(cfg.strategies, broker, md, cfg.mode, cfg.logEvents)
+	e.runMode = cfg.runMode
+	if cfg.runMode == RunModePaper {
+		//Simulated broker gets live market data
+		e.SetClock(WallClock{})
+	}
+	if cfg.factory != nil {
+		e.SetStrategyFactory(cfg.factory)
+	}
+	if cfg.queue != nil {
+		e.SetBackpressure(*cfg.queue)
+	}
+	if cfg.metrics != nil {
+		e.SetMetrics(cfg.metrics)
+	}
+	if cfg.disable {
+		e.SetDisableOnPanic(true)
+	}
+	if cfg.logger != nil {
+		e.SetLogger(cfg.logger)
+	}
+	if p := cfg.portfolio; p != nil {
+		if p.AccountCurrency != "" {
+			e.SetAccountCurrency(p.AccountCurrency)
+		}
+		if p.InitialCapital > 0 {
+			e.SetInitialCapital(p.InitialCapital)
+		}
+		if p.MaxDrawdown != 0 {
+			if err := e.SetMaxDrawdown(p.MaxDrawdown); err != nil {
+				return nil, err
+			}
+		}
+		if p.Allocation != nil {
+			if err := e.SetAllocation(p.Allocation); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return e, nil
+}
@@ -0,0 +1,100 @@
+package engine
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew_Validation(t *testing.T) {
+	inst := newTestInstrument()
+	md := &BTM{}
+	broker := newTestSimBroker()
+
+	cases := []struct {
+		name string
+		opts []Option
+	}{
+		{"no broker", []Option{WithMarketData(md), WithStrategy(inst, &DummyStrategy{}, 1)}},
+		{"no market data", []Option{WithBroker(broker), WithStrategy(inst, &DummyStrategy{}, 1)}},
+		{"no strategies", []Option{WithBroker(broker), WithMarketData(md)}},
+		{"nil broker", []Option{WithBroker(nil)}},
+		{"nil symbol", []Option{WithStrategy(nil, &DummyStrategy{}, 1)}},
+		{"nil user strategy", []Option{WithStrategy(inst, nil, 1)}},
+		{"zero periods", []Option{WithStrategy(inst, &DummyStrategy{}, 0)}},
+		{"duplicate symbol", []Option{WithStrategy(inst, &DummyStrategy{}, 1), WithStrategy(inst, &DummyStrategy{}, 2)}},
+		{"unknown mode", []Option{WithMode(EngineMode("Live"))}},
+		{"negative capital", []Option{WithPortfolio(PortfolioConfig{InitialCapital: -1})}},
+		{"wrong drawdown", []Option{WithPortfolio(PortfolioConfig{MaxDrawdown: 1.5})}},
+		{"wrong allocation", []Option{WithPortfolio(PortfolioConfig{Allocation: map[string]float64{inst.Symbol: 2}})}},
+		{"allocation without strategy", []Option{WithBroker(broker), WithMarketData(md), WithStrategy(inst, &DummyStrategy{}, 1),
+			WithPortfolio(PortfolioConfig{Allocation: map[string]float64{"Other": 0.5}})}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			e, err := New(tc.opts...)
+			assert.Error(t, err)
+			assert.Nil(t, e)
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := os.Stat("log.txt"); os.IsNotExist(err) {
+		defer os.Remove("log.txt")
+	}
+	inst := newTestInstrument()
+	broker := newTestSimBroker()
+	md := &BTM{}
+
+	e, err := New(
+		WithBroker(broker),
+		WithMarketData(md),
+		WithStrategy(inst, &DummyStrategy{}, 5),
+		WithPortfolio(PortfolioConfig{InitialCapital: 50000, AccountCurrency: "EUR", MaxDrawdown: 0.2,
+			Allocation: map[string]float64{inst.Symbol: 0.5}}),
+	)
+
+	assert.Nil(t, err)
+	if !assert.NotNil(t, e) {
+		return
+	}
+	assert.Equal(t, BacktestMode, e.engineMode)
+	assert.Len(t, e.strategiesMap, 1)
+	st := e.strategiesMap[inst.Symbol].(*BasicStrategy)
+	assert.Equal(t, 5, st.nPeriods)
+	assert.Equal(t, inst, st.getInstrument())
+	assert.Equal(t, []*Instrument{inst}, md.Symbols)
+	assert.NotNil(t, e.bus)
+}
+
+func TestNew_NamedStrategies(t *testing.T) {
+	if _, err := os.Stat("log.txt"); os.IsNotExist(err) {
+		defer os.Remove("log.txt")
+	}
+	inst := newTestInstrument()
+	other := &Instrument{Symbol: "Other"}
+
+	e, err := New(
+		WithBroker(newTestSimBroker()),
+		WithMarketData(&BTM{}),
+		WithNamedStrategy("fast", inst, &DummyStrategy{}, 5),
+		WithNamedStrategy("slow", inst, &DummyStrategy{}, 50),
+		WithStrategy(other, &DummyStrategy{}, 1),
+	)
+	assert.Nil(t, err)
+	if !assert.NotNil(t, e) {
+		return
+	}
+	assert.Len(t, e.strategiesMap, 2)
+	g := e.strategiesMap[inst.Symbol].(*StrategyGroup)
+	assert.Equal(t, []string{"fast", "slow"}, g.Names())
+	assert.Equal(t, 50, g.warmUpPeriods())
+
+	_, err = New(WithStrategy(inst, &DummyStrategy{}, 1), WithNamedStrategy("fast", inst, &DummyStrategy{}, 5))
+	assert.Error(t, err)
+	_, err = New(WithNamedStrategy("fast", inst, &DummyStrategy{}, 5), WithNamedStrategy("fast", inst, &DummyStrategy{}, 5))
+	assert.Error(t, err)
+}
@@ -0,0 +1,137 @@
+package engine
+
+import (
+	"time"
+)
+
+const calendarDateLayout = "2006-01-02"
+
+//TradingCalendar describes days when exchange is closed and days with shortened sessions. Saturdays and
+//sundays are non trading days unless weekend is changed with SetWeekend.
+type TradingCalendar struct {
+	holidays    map[string]struct{}
+	earlyCloses map[string]TimeOfDay
+	weekend     map[time.Weekday]struct{}
+}
+
+func NewTradingCalendar() *TradingCalendar {
+	c := TradingCalendar{
+		holidays:    make(map[string]struct{}),
+		earlyCloses: make(map[string]TimeOfDay),
+		weekend: map[time.Weekday]struct{}{
+			time.Saturday: {},
+			time.Sunday:   {},
+		},
+	}
+	return &c
+}
+
+//AddHoliday marks dates as non trading days
+func (c *TradingCalendar) AddHoliday(dates ...time.Time) {
+	for _, d := range dates {
+		c.holidays[d.Format(calendarDateLayout)] = struct{}{}
+	}
+}
+
+//AddEarlyClose sets session close time for the date
+func (c *TradingCalendar) AddEarlyClose(date time.Time, closeTime TimeOfDay) {
+	c.earlyCloses[date.Format(calendarDateLayout)] = closeTime
+}
+
+//SetWeekend replaces default saturday and sunday weekend
+func (c *TradingCalendar) SetWeekend(days ...time.Weekday) {
+	c.weekend = make(map[time.Weekday]struct{})
+	for _, d := range days {
+		c.weekend[d] = struct{}{}
+	}
+}
+
+//isTradingDay and earlyClose expect time in exchange time zone
+func (c *TradingCalendar) isTradingDay(t time.Time) bool {
+	if _, ok := c.weekend[t.Weekday()]; ok {
+		return false
+	}
+	if _, ok := c.holidays[t.Format(calendarDateLayout)]; ok {
+		return false
+	}
+	return true
+}
+
+func (c *TradingCalendar) earlyClose(t time.Time) (TimeOfDay, bool) {
+	tod, ok := c.earlyCloses[t.Format(calendarDateLayout)]
+	return tod, ok
+}
+
+//IsTradingDay returns true if exchange has session on the date of t. Exchanges without calendar
+//trade every day.
+func (e *Exchange) IsTradingDay(t time.Time) bool {
+	if e.Calendar == nil {
+		return true
+	}
+	return e.Calendar.isTradingDay(e.LocalTime(t))
+}
+
+//NextTradingDay returns start of the first trading day after the date of t
+func (e *Exchange) NextTradingDay(t time.Time) time.Time {
+	t = e.LocalTime(t)
+	d := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location()).AddDate(0, 0, 1)
+	for i := 0; i < 366 && !e.IsTradingDay(d); i++ {
+		d = d.AddDate(0, 0, 1)
+	}
+	return d
+}
+
+//CloseTimeOf returns session close time for the date of t. Early closes from calendar are taken into account.
+func (e *Exchange) CloseTimeOf(t time.Time) TimeOfDay {
+	if e.Calendar != nil {
+		if tod, ok := e.Calendar.earlyClose(e.LocalTime(t)); ok {
+			return tod
+		}
+	}
+	return e.MarketCloseTime
+}
+
+//SessionOpen returns session open time on the date of t. If there is no session on this date, false is returned
+func (e *Exchange) SessionOpen(t time.Time) (time.Time, bool) {
+	if !e.IsTradingDay(t) {
+		return time.Time{}, false
+	}
+	t = e.LocalTime(t)
+	mot := e.MarketOpenTime
+	return time.Date(t.Year(), t.Month(), t.Day(), mot.Hour, mot.Minute, mot.Second, 0, t.Location()), true
+}
+
+//SessionClose returns session close time on the date of t. If there is no session on this date, false is returned
+func (e *Exchange) SessionClose(t time.Time) (time.Time, bool) {
+	if !e.IsTradingDay(t) {
+		return time.Time{}, false
+	}
+	t = e.LocalTime(t)
+	mct := e.CloseTimeOf(t)
+	return time.Date(t.Year(), t.Month(), t.Day(), mct.Hour, mct.Minute, mct.Second, 0, t.Location()), true
+}
+
+//sessionDuration returns how long exchange was in session between two moments. Time outside of sessions,
+//weekends and holidays are not counted.
+func (e *Exchange) sessionDuration(from time.Time, to time.Time) time.Duration {
+	var total time.Duration
+	start := e.LocalTime(from)
+	d := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	for !d.After(to) {
+		start, ok := e.SessionOpen(d)
+		if ok {
+			end, _ := e.SessionClose(d)
+			if start.Before(from) {
+				start = from
+			}
+			if end.After(to) {
+				end = to
+			}
+			if end.After(start) {
+				total += end.Sub(start)
+			}
+		}
+		d = d.AddDate(0, 0, 1)
+	}
+	return total
+}
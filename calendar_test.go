@@ -0,0 +1,74 @@
+package engine
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func newTestCalendarInstrument() *Instrument {
+	inst := newTestInstrument()
+	cal := NewTradingCalendar()
+	cal.AddHoliday(time.Date(2018, 7, 4, 0, 0, 0, 0, time.UTC))
+	cal.AddEarlyClose(time.Date(2018, 7, 3, 0, 0, 0, 0, time.UTC), TimeOfDay{13, 0, 0})
+	inst.Exchange.Calendar = cal
+	return inst
+}
+
+func TestExchange_Calendar(t *testing.T) {
+	e := newTestCalendarInstrument().Exchange
+
+	assert.True(t, e.IsTradingDay(time.Date(2018, 7, 3, 10, 0, 0, 0, time.UTC)))
+	assert.False(t, e.IsTradingDay(time.Date(2018, 7, 4, 10, 0, 0, 0, time.UTC)))
+	assert.False(t, e.IsTradingDay(time.Date(2018, 7, 7, 10, 0, 0, 0, time.UTC)))
+
+	assert.Equal(t, time.Date(2018, 7, 5, 0, 0, 0, 0, time.UTC), e.NextTradingDay(time.Date(2018, 7, 3, 10, 0, 0, 0, time.UTC)))
+	assert.Equal(t, time.Date(2018, 7, 9, 0, 0, 0, 0, time.UTC), e.NextTradingDay(time.Date(2018, 7, 6, 10, 0, 0, 0, time.UTC)))
+
+	assert.Equal(t, TimeOfDay{13, 0, 0}, e.CloseTimeOf(time.Date(2018, 7, 3, 10, 0, 0, 0, time.UTC)))
+	assert.Equal(t, TimeOfDay{16, 0, 0}, e.CloseTimeOf(time.Date(2018, 7, 5, 10, 0, 0, 0, time.UTC)))
+
+	_, ok := e.SessionOpen(time.Date(2018, 7, 4, 10, 0, 0, 0, time.UTC))
+	assert.False(t, ok)
+
+	t.Log("Exchange without calendar trades every day")
+	{
+		e := newTestInstrument().Exchange
+		assert.True(t, e.IsTradingDay(time.Date(2018, 7, 7, 10, 0, 0, 0, time.UTC)))
+		assert.Equal(t, TimeOfDay{16, 0, 0}, e.CloseTimeOf(time.Date(2018, 7, 3, 10, 0, 0, 0, time.UTC)))
+	}
+}
+
+func TestExchange_sessionDuration(t *testing.T) {
+	e := newTestCalendarInstrument().Exchange
+
+	t.Log("Early close and holiday are not counted")
+	{
+		d := e.sessionDuration(time.Date(2018, 7, 3, 12, 0, 0, 0, time.UTC), time.Date(2018, 7, 5, 9, 40, 0, 0, time.UTC))
+		assert.Equal(t, time.Hour+10*time.Minute, d)
+	}
+
+	t.Log("Weekend is not counted")
+	{
+		d := e.sessionDuration(time.Date(2018, 7, 6, 15, 59, 0, 0, time.UTC), time.Date(2018, 7, 9, 9, 31, 0, 0, time.UTC))
+		assert.Equal(t, 2*time.Minute, d)
+	}
+}
+
+func TestSimBrokerOrder_getExpirationTimeWithCalendar(t *testing.T) {
+	inst := newTestCalendarInstrument()
+
+	t.Log("Day order placed on holiday lives until the end of next trading day")
+	{
+		o := simBrokerOrder{Order: &Order{Ticker: inst, Tif: DayTIF, Type: LimitOrder}}
+		o.Time = time.Date(2018, 7, 4, 10, 0, 0, 0, time.UTC)
+		assert.Equal(t, time.Date(2018, 7, 6, 0, 0, 0, 0, time.UTC), o.getExpirationTime())
+	}
+
+	t.Log("MOC order expires after early close")
+	{
+		o := simBrokerOrder{Order: &Order{Ticker: inst, Tif: AuctionTIF, Type: MarketOnClose}}
+		o.Time = time.Date(2018, 7, 3, 10, 0, 0, 0, time.UTC)
+		assert.Equal(t, time.Date(2018, 7, 3, 13, 0, 3, 0, time.UTC), o.getExpirationTime())
+	}
+}
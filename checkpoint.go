@@ -0,0 +1,525 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+//BacktestCheckpoint is state of deterministic backtest after Offset market data events. Run resumed from the
+//checkpoint skips these events and continues with restored portfolio, strategies and broker orders.
+type BacktestCheckpoint struct {
+	Time       time.Time
+	Offset     int64
+	ConfigHash string
+	Portfolio  PortfolioState
+	Strategies map[string]*StrategyCheckpoint
+	Orders     []BrokerOrderState
+	History    PortfolioHistory
+}
+
+//StrategyCheckpoint is market data buffers and closed trades of the strategy. UserState is saved by user
+//strategy which implements ICheckpointUserStrategy.
+type StrategyCheckpoint struct {
+	Ticks              []marketdata.Tick
+	Candles            []marketdata.Candle
+	LastCandleOpen     float64
+	LastCandleOpenTime time.Time
+	MostRecentTime     time.Time
+	ClosedTrades       []ClosedTradeInfo
+	UserState          []byte
+	//Members are checkpoints of strategies of StrategyGroup by name
+	Members map[string]*StrategyCheckpoint
+}
+
+//BrokerOrderState is active order on simulated broker side
+type BrokerOrderState struct {
+	Symbol        string
+	Order         WorkingOrder
+	BrokerState   OrderState
+	StateUpdTime  time.Time
+	BrokerExecQty int64
+	BrokerPrice   float64
+	BrokerQty     int64
+}
+
+//PortfolioHistory is equity curve and samples of returns of the portfolio
+type PortfolioHistory struct {
+	Equity             []EquityPoint
+	EquityStart        float64
+	ReturnsStart       time.Time
+	ReturnsStartEquity float64
+	PeriodStart        time.Time
+	LastEquity         float64
+	Returns            []float64
+	Snapshots          []PortfolioSnapshot
+}
+
+//ICheckpointUserStrategy can be implemented by user strategy to keep its own state, e.g. counters or
+//indicator values, in backtest checkpoints
+type ICheckpointUserStrategy interface {
+	SaveCheckpoint() ([]byte, error)
+	RestoreCheckpoint(data []byte) error
+}
+
+//checkpointBroker is implemented by brokers which can save and restore their orders
+type checkpointBroker interface {
+	//idle returns true if broker has no events waiting for delivery
+	idle() bool
+	saveOrders() []BrokerOrderState
+	restoreOrders(orders []BrokerOrderState, linked map[string]*Order) error
+}
+
+//checkpointMarketData is implemented by market data which can skip events handled before checkpoint
+type checkpointMarketData interface {
+	skipEvents(n int64)
+}
+
+//checkpointStrategy is implemented by strategies which keep market data buffers in checkpoints
+type checkpointStrategy interface {
+	saveCheckpoint() (*StrategyCheckpoint, error)
+	restoreCheckpoint(s *StrategyCheckpoint) error
+	workingOrders() []*Order
+}
+
+//checkpointState keeps checkpoint settings and number of market data events handled in the run
+type checkpointState struct {
+	mut      sync.Mutex
+	path     string
+	interval time.Duration
+	lastSave time.Time
+	offset   int64
+}
+
+//SetCheckpoint makes deterministic backtest save checkpoint to the file every interval of market time. If the file
+//exists when the run starts, the run resumes from it. Checkpoint is removed when the run reaches end of data.
+//Timers, indicators, exit orders and scheduled custom events aren't saved, strategies which depend on them should
+//restore them from ICheckpointUserStrategy. Empty path disables checkpoints.
+func (c *Engine) SetCheckpoint(path string, interval time.Duration) error {
+	if path != "" && interval <= 0 {
+		return errors.New("Checkpoint interval should be positive. ")
+	}
+	c.checkpoint.mut.Lock()
+	c.checkpoint.path = path
+	c.checkpoint.interval = interval
+	c.checkpoint.mut.Unlock()
+	return nil
+}
+
+//countMarketData counts market data event handled by the engine
+func (c *Engine) countMarketData() {
+	c.checkpoint.mut.Lock()
+	c.checkpoint.offset++
+	c.checkpoint.mut.Unlock()
+}
+
+//checkpointPeriodically saves checkpoint if interval of market time is passed since the last one. Broker with
+//undelivered events postpones the checkpoint.
+func (c *Engine) checkpointPeriodically(t time.Time) {
+	c.checkpoint.mut.Lock()
+	cp := &c.checkpoint
+	if cp.lastSave.IsZero() {
+		cp.lastSave = t
+	}
+	due := cp.path != "" && !t.Before(cp.lastSave.Add(cp.interval))
+	c.checkpoint.mut.Unlock()
+	if !due {
+		return
+	}
+	if b, ok := c.broker.(checkpointBroker); !ok || !b.idle() {
+		return
+	}
+	if err := c.saveCheckpoint(t); err != nil {
+		c.logError(err)
+	}
+}
+
+//saveCheckpoint writes state of the run at time of the last market data
+func (c *Engine) saveCheckpoint(t time.Time) error {
+	b, ok := c.broker.(checkpointBroker)
+	if !ok {
+		return errors.New("Broker doesn't support checkpoints. ")
+	}
+	hash, err := c.configHash()
+	if err != nil {
+		return err
+	}
+	c.checkpoint.mut.Lock()
+	path := c.checkpoint.path
+	cp := BacktestCheckpoint{Time: t, Offset: c.checkpoint.offset, ConfigHash: hash}
+	c.checkpoint.lastSave = t
+	c.checkpoint.mut.Unlock()
+
+	cp.Portfolio = c.portfolioState(t)
+	cp.Strategies = make(map[string]*StrategyCheckpoint)
+	for s, st := range c.strategies() {
+		cs, ok := st.(checkpointStrategy)
+		if !ok {
+			return fmt.Errorf("Strategy of %v doesn't support checkpoints. ", s)
+		}
+		if cp.Strategies[s], err = cs.saveCheckpoint(); err != nil {
+			return err
+		}
+	}
+	cp.Orders = b.saveOrders()
+	cp.History = c.portfolio.history()
+	return writeCheckpoint(path, &cp)
+}
+
+//strategies returns copy of strategies map
+func (c *Engine) strategies() map[string]ICoreStrategy {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	strategies := make(map[string]ICoreStrategy, len(c.strategiesMap))
+	for s, st := range c.strategiesMap {
+		strategies[s] = st
+	}
+	return strategies
+}
+
+//resumeCheckpoint restores state of the run from checkpoint file if it exists. Market data skips events
+//handled before the checkpoint.
+func (c *Engine) resumeCheckpoint() error {
+	c.checkpoint.mut.Lock()
+	path := c.checkpoint.path
+	c.checkpoint.mut.Unlock()
+	if path == "" {
+		return nil
+	}
+	cp, err := readCheckpoint(path)
+	if err != nil || cp == nil {
+		return err
+	}
+	md, ok := c.md.(checkpointMarketData)
+	if !ok {
+		return errors.New("Market data doesn't support checkpoints. ")
+	}
+	b, ok := c.broker.(checkpointBroker)
+	if !ok {
+		return errors.New("Broker doesn't support checkpoints. ")
+	}
+	if !c.isDeterministic() {
+		return errors.New("Run can be resumed from checkpoint only in deterministic mode. ")
+	}
+	hash, err := c.configHash()
+	if err != nil {
+		return err
+	}
+	if hash != cp.ConfigHash {
+		return fmt.Errorf("Checkpoint %v is made by run with other configuration. ", path)
+	}
+
+	c.restorePortfolioState(&cp.Portfolio)
+	linked := make(map[string]*Order)
+	for s, st := range c.strategies() {
+		cs, ok := st.(checkpointStrategy)
+		if !ok {
+			return fmt.Errorf("Strategy of %v doesn't support checkpoints. ", s)
+		}
+		if scp, ok := cp.Strategies[s]; ok {
+			if err := cs.restoreCheckpoint(scp); err != nil {
+				return err
+			}
+		}
+		for _, o := range cs.workingOrders() {
+			linked[o.Id] = o
+		}
+	}
+	if err := b.restoreOrders(cp.Orders, linked); err != nil {
+		return err
+	}
+	c.portfolio.restoreHistory(&cp.History)
+	c.advanceClock(cp.Time)
+
+	c.checkpoint.mut.Lock()
+	c.checkpoint.offset = cp.Offset
+	c.checkpoint.lastSave = cp.Time
+	c.checkpoint.mut.Unlock()
+	md.skipEvents(cp.Offset)
+	c.logMessage(fmt.Sprintf("Run is resumed from checkpoint of %v", cp.Time))
+	return nil
+}
+
+//removeCheckpoint deletes checkpoint of the finished run, so the next run starts from the beginning
+func (c *Engine) removeCheckpoint() {
+	c.checkpoint.mut.Lock()
+	path := c.checkpoint.path
+	c.checkpoint.mut.Unlock()
+	if path == "" {
+		return
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		c.logError(err)
+	}
+}
+
+//writeCheckpoint writes checkpoint to temporary file and renames it, so checkpoint is never left half written
+func writeCheckpoint(path string, cp *BacktestCheckpoint) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := gob.NewEncoder(f).Encode(cp); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+//readCheckpoint returns nil checkpoint if file doesn't exist
+func readCheckpoint(path string) (*BacktestCheckpoint, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var cp BacktestCheckpoint
+	if err := gob.NewDecoder(f).Decode(&cp); err != nil {
+		return nil, fmt.Errorf("Can't read checkpoint %v: %v", path, err)
+	}
+	return &cp, nil
+}
+
+func (b *BasicStrategy) saveCheckpoint() (*StrategyCheckpoint, error) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	s := StrategyCheckpoint{
+		LastCandleOpen:     b.lastCandleOpen,
+		LastCandleOpenTime: b.lastCandleOpenTime,
+		MostRecentTime:     b.mostRecentTime,
+	}
+	for _, t := range b.Ticks {
+		s.Ticks = append(s.Ticks, *t.Tick)
+	}
+	for _, cn := range b.Candles {
+		s.Candles = append(s.Candles, *cn.Candle)
+	}
+	for _, t := range b.closedTrades {
+		s.ClosedTrades = append(s.ClosedTrades, newClosedTradeInfo(t))
+	}
+	if us, ok := b.userStrategy.(ICheckpointUserStrategy); ok {
+		data, err := us.SaveCheckpoint()
+		if err != nil {
+			return nil, fmt.Errorf("Can't save checkpoint of %v: %v", b.symbol.Symbol, err)
+		}
+		s.UserState = data
+	}
+	return &s, nil
+}
+
+//restoreCheckpoint replaces market data buffers and closed trades of the strategy. Trades and orders are
+//restored by restoreState.
+func (b *BasicStrategy) restoreCheckpoint(s *StrategyCheckpoint) error {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	b.lastCandleOpen = s.LastCandleOpen
+	b.lastCandleOpenTime = s.LastCandleOpenTime
+	b.mostRecentTime = s.MostRecentTime
+	b.Ticks = make(TickArray, len(s.Ticks))
+	for i := range s.Ticks {
+		b.Ticks[i] = &Tick{Tick: &s.Ticks[i], Ticker: b.symbol}
+	}
+	b.Candles = make(CandleArray, len(s.Candles))
+	for i := range s.Candles {
+		b.Candles[i] = &Candle{Candle: &s.Candles[i], Ticker: b.symbol}
+	}
+	b.closedTrades = make([]*Trade, len(s.ClosedTrades))
+	for i := range s.ClosedTrades {
+		b.closedTrades[i] = s.ClosedTrades[i].trade(b.symbol)
+	}
+	if us, ok := b.userStrategy.(ICheckpointUserStrategy); ok && s.UserState != nil {
+		if err := us.RestoreCheckpoint(s.UserState); err != nil {
+			return fmt.Errorf("Can't restore checkpoint of %v: %v", b.symbol.Symbol, err)
+		}
+	}
+	return nil
+}
+
+//workingOrders returns confirmed orders of open trades
+func (b *BasicStrategy) workingOrders() []*Order {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	var orders []*Order
+	for _, t := range b.trades() {
+		for _, o := range t.ConfirmedOrders {
+			orders = append(orders, o)
+		}
+	}
+	return orders
+}
+
+func (g *StrategyGroup) saveCheckpoint() (*StrategyCheckpoint, error) {
+	s := StrategyCheckpoint{Members: make(map[string]*StrategyCheckpoint)}
+	for _, name := range g.names {
+		ms, err := g.members[name].saveCheckpoint()
+		if err != nil {
+			return nil, err
+		}
+		s.Members[name] = ms
+	}
+	return &s, nil
+}
+
+func (g *StrategyGroup) restoreCheckpoint(s *StrategyCheckpoint) error {
+	if s.Members == nil {
+		return fmt.Errorf("Can't restore checkpoint of %v. It's not checkpoint of strategy group. ", g.symbol.Symbol)
+	}
+	for _, name := range g.names {
+		if ms, ok := s.Members[name]; ok {
+			if err := g.members[name].restoreCheckpoint(ms); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (g *StrategyGroup) workingOrders() []*Order {
+	var orders []*Order
+	g.forEach(func(st *BasicStrategy) { orders = append(orders, st.workingOrders()...) })
+	return orders
+}
+
+//trade creates closed trade of the instrument from its summary
+func (i *ClosedTradeInfo) trade(inst *Instrument) *Trade {
+	t := newFlatTrade(inst)
+	t.Id = i.Id
+	t.Type = ClosedTrade
+	t.Direction = i.Direction
+	t.ExitQty = i.Qty
+	t.Entries = i.Entries
+	t.OpenPrice = i.EntryPrice
+	t.ExitPrice = i.ExitPrice
+	t.OpenTime = i.OpenTime
+	t.CloseTime = i.CloseTime
+	t.ClosedPnL = i.PnL
+	t.Commission = i.Commission
+	t.Slippage = i.Slippage
+	t.CorrelationID = i.CorrelationID
+	t.MAE, t.MFE = i.MAE, i.MFE
+	return t
+}
+
+func (p *portfolioHandler) history() PortfolioHistory {
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	return PortfolioHistory{
+		Equity:             append([]EquityPoint{}, p.curve.points...),
+		EquityStart:        p.curve.startEquity,
+		ReturnsStart:       p.returns.start,
+		ReturnsStartEquity: p.returns.startEquity,
+		PeriodStart:        p.returns.periodStart,
+		LastEquity:         p.returns.lastEquity,
+		Returns:            append([]float64{}, p.returns.returns...),
+		Snapshots:          append([]PortfolioSnapshot{}, p.returns.snapshots...),
+	}
+}
+
+func (p *portfolioHandler) restoreHistory(h *PortfolioHistory) {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	p.curve.points = h.Equity
+	p.curve.startEquity = h.EquityStart
+	p.returns.start = h.ReturnsStart
+	p.returns.startEquity = h.ReturnsStartEquity
+	p.returns.periodStart = h.PeriodStart
+	p.returns.lastEquity = h.LastEquity
+	p.returns.returns = h.Returns
+	p.returns.snapshots = h.Snapshots
+}
+
+//idle returns true if no worker holds events
+func (b *SimBroker) idle() bool {
+	b.workersMut.RLock()
+	defer b.workersMut.RUnlock()
+	for _, w := range b.workers {
+		w.mpMutext.RLock()
+		pending := len(w.generatedEvents) + len(w.requestEvents)
+		w.mpMutext.RUnlock()
+		if pending > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+//saveOrders returns active orders of all workers sorted by symbol and id
+func (b *SimBroker) saveOrders() []BrokerOrderState {
+	b.workersMut.RLock()
+	defer b.workersMut.RUnlock()
+	var orders []BrokerOrderState
+	for s, w := range b.workers {
+		w.mpMutext.RLock()
+		for _, o := range w.orders {
+			switch o.BrokerState {
+			case FilledOrder, CanceledOrder, RejectedOrder:
+				continue
+			}
+			orders = append(orders, BrokerOrderState{
+				Symbol:        s,
+				Order:         newWorkingOrder(o.Order),
+				BrokerState:   o.BrokerState,
+				StateUpdTime:  o.StateUpdTime,
+				BrokerExecQty: o.BrokerExecQty,
+				BrokerPrice:   o.BrokerPrice,
+				BrokerQty:     o.BrokerQty,
+			})
+		}
+		w.mpMutext.RUnlock()
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		if orders[i].Symbol != orders[j].Symbol {
+			return orders[i].Symbol < orders[j].Symbol
+		}
+		return orders[i].Order.Id < orders[j].Order.Id
+	})
+	return orders
+}
+
+//restoreOrders adds saved orders to workers of their symbols. Order of the strategy with the same id is linked to
+//broker order, so both see the same order as before checkpoint.
+func (b *SimBroker) restoreOrders(orders []BrokerOrderState, linked map[string]*Order) error {
+	b.workersMut.RLock()
+	defer b.workersMut.RUnlock()
+	for i := range orders {
+		s := &orders[i]
+		w, ok := b.workers[s.Symbol]
+		if !ok {
+			return fmt.Errorf("Can't restore order %v. Broker doesn't trade %v. ", s.Order.Id, s.Symbol)
+		}
+		o, ok := linked[s.Order.Id]
+		if !ok {
+			o = s.Order.order(w.symbol)
+		}
+		w.mpMutext.Lock()
+		if w.orders == nil {
+			w.orders = make(map[string]*simBrokerOrder)
+		}
+		w.orders[o.Id] = &simBrokerOrder{
+			Order:         o,
+			BrokerState:   s.BrokerState,
+			StateUpdTime:  s.StateUpdTime,
+			BrokerExecQty: s.BrokerExecQty,
+			BrokerPrice:   s.BrokerPrice,
+			BrokerQty:     s.BrokerQty,
+		}
+		w.mpMutext.Unlock()
+	}
+	return nil
+}
+
+func (m *BTM) skipEvents(n int64) {
+	m.skip = n
+}
@@ -0,0 +1,156 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//checkpointTestStrategy keeps counter of ticks in checkpoints
+type checkpointTestStrategy struct {
+	manifestTestStrategy
+}
+
+func (s *checkpointTestStrategy) SaveCheckpoint() ([]byte, error) {
+	return []byte(strconv.Itoa(s.ticks)), nil
+}
+
+func (s *checkpointTestStrategy) RestoreCheckpoint(data []byte) error {
+	n, err := strconv.Atoi(string(data))
+	s.ticks = n
+	return err
+}
+
+func TestBasicStrategy_checkpoint(t *testing.T) {
+	st := newTestBasicStrategy()
+	us := &checkpointTestStrategy{}
+	us.ticks = 7
+	st.userStrategy = us
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	st.Ticks = append(st.Ticks, &Tick{Tick: &marketdata.Tick{Datetime: tm, Symbol: st.symbol.Symbol, LastPrice: 10,
+		LastSize: 100}, Ticker: st.symbol})
+	st.Candles = append(st.Candles, newTestCandleCloseEvent(10, 11, 9, 10.5, tm, "1").Candle)
+	st.closedTrades = append(st.closedTrades, &Trade{Ticker: st.symbol, Id: "1", Direction: LongTrade, ExitQty: 100,
+		ClosedPnL: 50, Commission: 1, OpenTime: tm, CloseTime: tm.Add(time.Hour), MAE: 10, MFE: 60})
+	st.mostRecentTime = tm
+
+	cp, err := st.saveCheckpoint()
+	assert.Nil(t, err)
+	restored := newTestBasicStrategy()
+	restoredUS := &checkpointTestStrategy{}
+	restored.userStrategy = restoredUS
+	assert.Nil(t, restored.restoreCheckpoint(cp))
+	assert.Equal(t, 7, restoredUS.ticks)
+	assert.Equal(t, tm, restored.mostRecentTime)
+	if assert.Len(t, restored.Ticks, 1) && assert.Len(t, restored.Candles, 1) {
+		assert.Equal(t, 10.0, restored.Ticks[0].LastPrice)
+		assert.Equal(t, restored.symbol, restored.Ticks[0].Ticker)
+		assert.Equal(t, 10.5, restored.Candles[0].Close)
+	}
+	assert.Equal(t, st.ClosedTrades(), restored.ClosedTrades())
+
+	g := NewStrategyGroup(st.symbol)
+	assert.NotNil(t, g.restoreCheckpoint(cp))
+}
+
+func TestSimBroker_restoreOrders(t *testing.T) {
+	b := &SimBroker{}
+	inst := newTestInstrument()
+	b.Init(make(chan error), make(chan event, 10), []*Instrument{inst})
+	order := &Order{Id: "1", Side: OrderBuy, Qty: 100, Ticker: inst, State: ConfirmedOrder, Price: 10,
+		Type: LimitOrder, Tif: DayTIF}
+	w := b.workers[inst.Symbol]
+	w.orders["1"] = &simBrokerOrder{Order: order, BrokerState: ConfirmedOrder, BrokerPrice: 10}
+	w.orders["2"] = &simBrokerOrder{Order: &Order{Id: "2"}, BrokerState: FilledOrder}
+	assert.True(t, b.idle())
+
+	orders := b.saveOrders()
+	if assert.Len(t, orders, 1) {
+		assert.Equal(t, inst.Symbol, orders[0].Symbol)
+		assert.Equal(t, int64(100), orders[0].Order.Qty)
+	}
+
+	other := &SimBroker{}
+	other.Init(make(chan error), make(chan event, 10), []*Instrument{inst})
+	linked := &Order{Id: "1"}
+	assert.Nil(t, other.restoreOrders(orders, map[string]*Order{"1": linked}))
+	assert.True(t, other.workers[inst.Symbol].orders["1"].Order == linked)
+	assert.Equal(t, 10.0, other.workers[inst.Symbol].orders["1"].BrokerPrice)
+
+	orders[0].Symbol = "Unknown"
+	assert.NotNil(t, other.restoreOrders(orders, nil))
+}
+
+func TestEngine_Checkpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoint")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	cpPath := path.Join(dir, "run.checkpoint")
+	type run struct {
+		e     *Engine
+		fills *fillRecorder
+	}
+	newRun := func() run {
+		e, err := New(WithMarketData(newTestBTMforSyntheticTicks(dir, 1)), WithBroker(&SimBroker{}),
+			WithStrategy(&Instrument{Symbol: "Sym1"}, &checkpointTestStrategy{}, 1))
+		assert.Nil(t, err)
+		e.SetDeterministic(true)
+		assert.NotNil(t, e.SetCheckpoint(cpPath, 0))
+		assert.Nil(t, e.SetCheckpoint(cpPath, time.Hour))
+		return run{e: e, fills: recordFills(e)}
+	}
+
+	full := newRun()
+	full.e.Run()
+	reference := full.fills.result()
+	_, err = os.Stat(cpPath)
+	assert.True(t, os.IsNotExist(err))
+	if !assert.True(t, len(reference) > 10) {
+		return
+	}
+
+	//Run is interrupted after the half of fills
+	interrupted := newRun()
+	ctx, cancel := context.WithCancel(context.Background())
+	var once sync.Once
+	interrupted.e.EventBus().Subscribe(EventFilter{Types: []string{"OrderFillEvent"}}, func(e Event) {
+		if len(interrupted.fills.result()) >= len(reference)/2 {
+			once.Do(cancel)
+		}
+	})
+	interrupted.e.RunContext(ctx)
+	cp, err := readCheckpoint(cpPath)
+	assert.Nil(t, err)
+	if !assert.NotNil(t, cp) {
+		return
+	}
+	assert.True(t, cp.Offset > 0)
+
+	resumed := newRun()
+	resumed.e.Run()
+	assert.Nil(t, resumed.e.Status().Err)
+	fills := resumed.fills.result()
+	if assert.True(t, len(fills) > 0 && len(fills) < len(reference)) {
+		assert.Equal(t, reference[len(reference)-len(fills):], fills)
+	}
+	fullPerf, _ := full.e.PerformanceReport()
+	resumedPerf, ok := resumed.e.PerformanceReport()
+	assert.True(t, ok)
+	assert.InDelta(t, fullPerf.FinalEquity, resumedPerf.FinalEquity, 0.0000001)
+	assert.Equal(t, fullPerf.Trades, resumedPerf.Trades)
+	assert.Equal(t, len(full.e.portfolio.EquityCurve()), len(resumed.e.portfolio.EquityCurve()))
+
+	//Checkpoint of other configuration isn't used
+	assert.Nil(t, writeCheckpoint(cpPath, &BacktestCheckpoint{ConfigHash: "other"}))
+	other := newRun()
+	other.e.Run()
+	assert.NotNil(t, other.e.Status().Err)
+}
@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"sync"
+	"time"
+)
+
+//Clock is source of current time for engine and strategies. It lets the same code run in backtest, where time
+//is moved by market data, and in live mode, where it's wall clock time. Tests can set their own clock to
+//control time of timers, orders and requests.
+type Clock interface {
+	Now() time.Time
+}
+
+//WallClock is time of the machine
+type WallClock struct{}
+
+func (WallClock) Now() time.Time {
+	return time.Now()
+}
+
+//SimClock is simulated time. Engine moves it forward by market data. It never goes back.
+type SimClock struct {
+	mut sync.RWMutex
+	now time.Time
+}
+
+func NewSimClock(start time.Time) *SimClock {
+	return &SimClock{now: start}
+}
+
+func (c *SimClock) Now() time.Time {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+	return c.now
+}
+
+//Advance moves clock to given time if it's later than current one
+func (c *SimClock) Advance(t time.Time) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	if t.After(c.now) {
+		c.now = t
+	}
+}
+
+//SetClock replaces clock of the engine. By default engine with simulated broker has SimClock and live
+//engine has WallClock. Strategies stamp orders and requests with time of the clock unless it's SimClock,
+//then they use time of their last event, so results don't depend on speed of other strategies.
+func (c *Engine) SetClock(clock Clock) {
+	c.mut.Lock()
+	c.clock = clock
+	strategies := make([]ICoreStrategy, 0, len(c.strategiesMap))
+	for _, st := range c.strategiesMap {
+		strategies = append(strategies, st)
+	}
+	c.mut.Unlock()
+	for _, st := range strategies {
+		st.setClock(strategyClock(clock))
+	}
+}
+
+//Clock returns current clock of the engine
+func (c *Engine) Clock() Clock {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	if c.clock == nil {
+		c.clock = NewSimClock(time.Time{})
+	}
+	return c.clock
+}
+
+//now returns time of the engine clock
+func (c *Engine) now() time.Time {
+	return c.Clock().Now()
+}
+
+//advanceClock moves simulated clock to time of market data event
+func (c *Engine) advanceClock(t time.Time) {
+	if sc, ok := c.Clock().(*SimClock); ok {
+		sc.Advance(t)
+	}
+}
+
+//strategyClock returns clock strategies stamp their requests with. It's nil for simulated clock.
+func strategyClock(clock Clock) Clock {
+	if _, ok := clock.(*SimClock); ok {
+		return nil
+	}
+	return clock
+}
+
+func (b *BasicStrategy) setClock(clock Clock) {
+	b.clock = clock
+}
+
+//now returns time of new orders and requests of the strategy
+func (b *BasicStrategy) now() time.Time {
+	if b.clock != nil {
+		return b.clock.Now()
+	}
+	return b.mostRecentTime
+}
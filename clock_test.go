@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+func TestSimClock(t *testing.T) {
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	c := NewSimClock(tm)
+	assert.Equal(t, tm, c.Now())
+	c.Advance(tm.Add(time.Minute))
+	assert.Equal(t, tm.Add(time.Minute), c.Now())
+	//Late data doesn't move time back
+	c.Advance(tm)
+	assert.Equal(t, tm.Add(time.Minute), c.Now())
+}
+
+func TestEngine_SetClock(t *testing.T) {
+	st := newTestBasicStrategy()
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	st.mostRecentTime = tm
+	c := Engine{strategiesMap: map[string]ICoreStrategy{st.symbol.Symbol: st}, mut: &sync.Mutex{}}
+
+	sim := NewSimClock(time.Time{})
+	c.SetClock(sim)
+	c.advanceClock(tm.Add(time.Hour))
+	assert.Equal(t, tm.Add(time.Hour), c.now())
+	//Strategy uses time of its own events in simulation
+	assert.Equal(t, tm, st.now())
+
+	live := fixedClock(tm.Add(5 * time.Second))
+	c.SetClock(live)
+	c.advanceClock(tm.Add(2 * time.Hour))
+	assert.Equal(t, tm.Add(5*time.Second), c.now())
+	assert.Equal(t, tm.Add(5*time.Second), st.now())
+}
+
+func TestBasicStrategy_ClockOrderTime(t *testing.T) {
+	st := newTestBasicStrategy()
+	st.ch.events = make(chan event, 5)
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	st.mostRecentTime = tm
+	st.setClock(fixedClock(tm.Add(3 * time.Second)))
+
+	_, err := st.NewMarketOrder(OrderBuy, 100, DayTIF, "Sim")
+	assert.Nil(t, err)
+	e := (<-st.ch.events).(*NewOrderEvent)
+	assert.Equal(t, tm.Add(3*time.Second), e.getTime())
+	assert.Equal(t, tm.Add(3*time.Second), e.LinkedOrder.Time)
+}
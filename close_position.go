@@ -0,0 +1,50 @@
+package engine
+
+import "fmt"
+
+//CancelAllOrders sends cancel request for every confirmed order of the strategy. Orders which already wait for
+//cancel confirmation are skipped. Stop loss and take profit orders are maintained by exit levels and are not
+//canceled. Last error is returned if some requests were not sent.
+func (b *BasicStrategy) CancelAllOrders() error {
+	var lastErr error
+	for id := range b.OpenOrders() {
+		if b.isExitOrder(id) {
+			continue
+		}
+		if _, ok := b.waitingConfirmation["$CAN$"+id]; ok {
+			continue
+		}
+		if err := b.CancelOrder(id); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+//ClosePosition cancels all orders and sends order which offsets current position. Order type should be
+//MarketOrder or LimitOrder, price is ignored for market order. Empty id is returned if position is flat.
+//In hedging mode both sub-positions are closed and id of the last order is returned.
+func (b *BasicStrategy) ClosePosition(orderType OrderType, price float64, destination string) (string, error) {
+	if orderType != MarketOrder && orderType != LimitOrder {
+		return "", fmt.Errorf("Can't close position with %v order. ", orderType)
+	}
+	if err := b.CancelAllOrders(); err != nil {
+		b.newError(err)
+	}
+	if b.shortTrade != nil {
+		return b.closeSubPositions(orderType, price, destination)
+	}
+	pos := b.Position()
+	if pos == 0 {
+		return "", nil
+	}
+	side := OrderSell
+	if pos < 0 {
+		side = OrderBuy
+		pos = -pos
+	}
+	if orderType == MarketOrder {
+		return b.NewMarketOrder(side, pos, DayTIF, destination)
+	}
+	return b.NewLimitOrder(price, side, pos, DayTIF, destination)
+}
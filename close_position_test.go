@@ -0,0 +1,47 @@
+package engine
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestBasicStrategy_ClosePosition(t *testing.T) {
+	st := newTestBasicStrategy()
+	st.ch.events = make(chan event, 10)
+	go func() {
+		for range st.ch.errors {
+		}
+	}()
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	st.mostRecentTime = tm
+
+	id, _ := st.NewLimitOrder(50, OrderBuy, 200, GTCTIF, "Sim")
+	st.proxyEvent(&OrderConfirmationEvent{BaseEvent: be(tm, st.symbol), OrdId: id})
+	st.proxyEvent(&OrderFillEvent{BaseEvent: be(tm, st.symbol), OrdId: id, Price: 50, Qty: 100})
+	st.SetStopLoss(45, "Sim")
+	stopID := st.stopLoss.id
+	st.proxyEvent(&OrderConfirmationEvent{BaseEvent: be(tm, st.symbol), OrdId: stopID})
+	for len(st.ch.events) > 0 {
+		<-st.ch.events
+	}
+
+	closeID, err := st.ClosePosition(LimitOrder, 51, "Sim")
+	assert.Nil(t, err)
+	assert.Len(t, st.ch.events, 2)
+	cancel := (<-st.ch.events).(*OrderCancelRequestEvent)
+	assert.Equal(t, id, cancel.OrdId)
+	ord := (<-st.ch.events).(*NewOrderEvent).LinkedOrder
+	assert.Equal(t, closeID, ord.Id)
+	assert.Equal(t, OrderSell, ord.Side)
+	assert.Equal(t, int64(100), ord.Qty)
+	assert.Equal(t, 51.0, ord.Price)
+
+	//Second cancel request isn't sent while the first one waits for confirmation
+	assert.Nil(t, st.CancelAllOrders())
+	assert.Len(t, st.ch.events, 0)
+
+	_, err = st.ClosePosition(StopOrder, 51, "Sim")
+	assert.NotNil(t, err)
+	st.shutDown()
+}
@@ -0,0 +1,58 @@
+package engine
+
+import (
+	"context"
+	"sync"
+)
+
+//IContextAware can be implemented by broker or market data to observe cancellation of the engine run.
+//Context is set before Connect and is done when run is stopped.
+type IContextAware interface {
+	SetContext(ctx context.Context)
+}
+
+//doneChan returns done channel of the context or nil channel, which is never ready, if context is not set
+func doneChan(ctx context.Context) <-chan struct{} {
+	if ctx == nil {
+		return nil
+	}
+	return ctx.Done()
+}
+
+//sendEvent sends event to the channel unless context is done first. It returns false if event wasn't sent.
+func sendEvent(ctx context.Context, ch chan event, e event) bool {
+	select {
+	case ch <- e:
+		return true
+	case <-doneChan(ctx):
+		return false
+	}
+}
+
+//sendError sends error to the channel unless context is done first
+func sendError(ctx context.Context, ch chan error, err error) {
+	select {
+	case ch <- err:
+	case <-doneChan(ctx):
+	}
+}
+
+//waitContext waits for the wait group, but stops waiting when context is done, so stuck goroutines can't
+//hang shutdown. It returns false if context was done first.
+func waitContext(ctx context.Context, wg *sync.WaitGroup) bool {
+	if ctx == nil {
+		wg.Wait()
+		return true
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,64 @@
+package engine
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+type contextTestStrategy struct {
+	DummyStrategy
+	started chan struct{}
+}
+
+func (d *contextTestStrategy) OnCandleClose(b *BasicStrategy, candle *Candle) {
+	close(d.started)
+	<-b.Context().Done()
+	//Nobody reads strategy events, but order doesn't block after cancellation
+	b.NewMarketOrder(OrderBuy, 100, DayTIF, "Sim")
+}
+
+func TestWaitContext(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	assert.False(t, waitContext(ctx, wg))
+
+	wg.Done()
+	assert.True(t, waitContext(context.Background(), wg))
+	assert.True(t, waitContext(nil, wg))
+}
+
+func TestSendEvent(t *testing.T) {
+	ch := make(chan event, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	assert.True(t, sendEvent(ctx, ch, &NewTickEvent{}))
+	cancel()
+	assert.False(t, sendEvent(ctx, ch, &NewTickEvent{}))
+}
+
+func TestBasicStrategy_Context(t *testing.T) {
+	st := newTestBasicStrategy()
+	us := &contextTestStrategy{started: make(chan struct{})}
+	st.userStrategy = us
+	st.nPeriods = 1
+	assert.NotNil(t, st.Context())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	st.setContext(ctx)
+	st.notify(newTestCandleCloseEvent(10, 10, 10, 10, time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC), "1"))
+	<-us.started
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	st.shutDown()
+	assert.NotNil(t, st.Context().Err())
+}
@@ -1,9 +1,12 @@
 package engine
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"sort"
 	"sync"
 	"time"
 )
@@ -23,10 +26,15 @@ func createDirIfNotExists(dirPath string) error {
 	return nil
 }
 
+//StrategyFactory creates strategy for the symbol which entered the universe during the run
+type StrategyFactory func(symbol *Instrument) ICoreStrategy
+
 type Engine struct {
-	broker        IBroker
-	md            IMarketData
-	strategiesMap map[string]ICoreStrategy
+	broker          IBroker
+	md              IMarketData
+	strategiesMap   map[string]ICoreStrategy
+	strategyFactory StrategyFactory
+	progressHandler func(e *BacktestProgressEvent)
 
 	portfolio       *portfolioHandler
 	terminationChan chan struct{}
@@ -37,10 +45,42 @@ type Engine struct {
 	events     chan event
 	log        log.Logger
 	engineMode EngineMode
+	runMode    RunMode
+	logEvents  bool
+	warmUp     bool
+
+	customEvents    eventArray
+	portfolioEvents eventArray
+	riskManager     *RiskManager
+	persist         persistState
+	bus             *EventBus
+	journal         IEventJournal
+	shutdown        shutdownState
+	strict          bool
+	deterministic   bool
+	clock           Clock
+	pause           pauseGate
+	deadLetters     deadLetterState
+	backpressure    *BackpressureConfig
+	metrics         metricsState
+	endOfDay        endOfDayState
+	performance     performanceState
+	manifest        manifestState
+	checkpoint      checkpointState
+	disableOnPanic  bool
+	lifecycle       lifecycle
+	strategyLogger  ILogger
+	logger          ILogger
 
 	histDataTimeBack time.Duration
 	mut              *sync.Mutex
 	waitG            *sync.WaitGroup
+
+	//ctx is done when run is stopped or finished. Context of market data is canceled by cancelMD also when
+	//graceful shutdown stops market data loop.
+	ctx      context.Context
+	cancel   context.CancelFunc
+	cancelMD context.CancelFunc
 }
 
 func NewEngine(sp map[string]ICoreStrategy, broker IBroker, md IMarketData, mode EngineMode, logEvents bool) *Engine {
@@ -59,39 +99,40 @@ func NewEngine(sp map[string]ICoreStrategy, broker IBroker, md IMarketData, mode
 
 	events := make(chan event, 500)
 
-	for k := range sp {
-		cc := CoreStrategyChannels{
-			errors:    errChan,
-			events:    events,
-			portfolio: portfolioChan,
-		}
-
-		sp[k].init(cc)
-		sp[k].setPortfolio(portfolio)
-		if logEvents {
-			sp[k].enableEventLogging()
-		}
+	eng := Engine{
+		broker:        broker,
+		md:            md,
+		events:        events,
+		strategiesMap: sp,
+		errChan:       errChan,
+		portfolioChan: portfolioChan,
+		portfolio:     portfolio,
+		logEvents:     logEvents,
+		bus:           NewEventBus(),
+	}
+	eng.ctx, eng.cancel = context.WithCancel(context.Background())
+	eng.lifecycle.transition(StateCreated, nil)
+	if broker.IsSimulated() {
+		eng.clock = NewSimClock(time.Time{})
+	} else {
+		eng.clock = WallClock{}
+	}
 
+	for k := range sp {
+		eng.initStrategy(sp[k])
 		tickers = append(tickers, sp[k].getInstrument())
-
 	}
+	//Ticks of the same time are prepaired in order of symbols, so it shouldn't depend on map order
+	sort.Slice(tickers, func(i, j int) bool { return tickers[i].Symbol < tickers[j].Symbol })
 
 	broker.Init(errChan, events, tickers)
 	mdChan := make(chan event)
 	md.Init(errChan, mdChan)
 	md.SetSymbols(tickers)
-	eng := Engine{
-		broker:         broker,
-		md:             md,
-		events:         events,
-		strategiesMap:  sp,
-		errChan:        errChan,
-		marketDataChan: mdChan,
-	}
+	eng.lifecycle.transition(StateInitialized, nil)
 
+	eng.marketDataChan = mdChan
 	eng.engineMode = mode
-	eng.portfolioChan = portfolioChan
-	eng.portfolio = portfolio
 	eng.prepareLogger()
 
 	eng.histDataTimeBack = time.Duration(20) * time.Minute
@@ -100,16 +141,146 @@ func NewEngine(sp map[string]ICoreStrategy, broker IBroker, md IMarketData, mode
 
 	eng.mut = &sync.Mutex{}
 	eng.waitG = &sync.WaitGroup{}
+	portfolio.fx.onError = eng.logError
 
 	return &eng
 }
 
+func (c *Engine) initStrategy(st ICoreStrategy) {
+	cc := CoreStrategyChannels{
+		errors:    c.errChan,
+		events:    c.events,
+		portfolio: c.portfolioChan,
+	}
+
+	st.init(cc)
+	st.setContext(c.ctx)
+	st.setPortfolio(c.portfolio)
+	st.setStrictMode(c.strict)
+	st.setSynchronous(c.deterministic)
+	st.setClock(strategyClock(c.clock))
+	st.setBackpressure(c.backpressure)
+	if ms, ok := st.(metricsStrategy); ok {
+		ms.setMetrics(c.getMetrics())
+	}
+	if ps, ok := st.(panicStrategy); ok {
+		ps.setDisableOnPanic(c.disableOnPanic)
+	}
+	if c.strategyLogger != nil {
+		st.setLogger(c.strategyLogger)
+	} else if c.logger != nil {
+		st.setLogger(WithFields(c.logger, LogFields{"component": "strategy"}))
+	} else if c.logEvents {
+		st.enableEventLogging()
+	}
+}
+
+//SetStrategyLogger sets logger of events and errors of all strategies instead of files in StrategyLogs folder.
+//Records of every strategy have its symbol in fields.
+func (c *Engine) SetStrategyLogger(l ILogger) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.strategyLogger = l
+	for _, st := range c.strategiesMap {
+		st.setLogger(l)
+	}
+}
+
+//SetLogger sets logger of the engine instead of log.txt. Broker and market data which implement ILoggerAware
+//and strategies without logger of SetStrategyLogger write to it as well. Records have component field.
+//It should be called before Run.
+func (c *Engine) SetLogger(l ILogger) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.logger = l
+	if l == nil {
+		return
+	}
+	if b, ok := c.broker.(ILoggerAware); ok {
+		b.SetLogger(WithFields(l, LogFields{"component": "broker"}))
+	}
+	if md, ok := c.md.(ILoggerAware); ok {
+		md.SetLogger(WithFields(l, LogFields{"component": "marketdata"}))
+	}
+	if c.strategyLogger == nil {
+		for _, st := range c.strategiesMap {
+			st.setLogger(WithFields(l, LogFields{"component": "strategy"}))
+		}
+	}
+}
+
+//SetProgressHandler sets function which is called for every BacktestProgressEvent from market data
+func (c *Engine) SetProgressHandler(h func(e *BacktestProgressEvent)) {
+	c.progressHandler = h
+}
+
+//SetStrategyFactory sets function which creates strategies for symbols entering the universe during the run.
+//Symbols which already have strategy keep it.
+func (c *Engine) SetStrategyFactory(f StrategyFactory) {
+	c.strategyFactory = f
+}
+
+//SetAccountCurrency sets currency of the account. PnL of FX instruments is converted from quote currency
+//to account currency with rates from ticks of FX instruments and rates set with FXRates.
+func (c *Engine) SetAccountCurrency(currency string) {
+	c.portfolio.fx.setAccountCurrency(currency)
+}
+
+//Portfolio returns read only view of positions, cash and equity of all strategies
+func (c *Engine) Portfolio() IPortfolio {
+	return c.portfolio
+}
+
+//SetInitialCapital sets starting cash of the account. Strategies use equity for position sizing.
+func (c *Engine) SetInitialCapital(capital float64) {
+	c.portfolio.setCapital(capital)
+}
+
+//FXRates returns exchange rates used for PnL conversion. Rates of currencies without traded FX instruments
+//can be set here.
+func (c *Engine) FXRates() *FXRates {
+	return c.portfolio.fx.rates
+}
+
+//SetWarmUp enables warm up phase. Before the first event market data sends history of nPeriods candles or
+//ticks to every strategy, so strategies start with full buffers and indicators. Market data should implement
+//IWarmUpMarketData.
+func (c *Engine) SetWarmUp(enabled bool) {
+	c.warmUp = enabled
+}
+
+func (c *Engine) requestWarmUp() {
+	md, ok := c.md.(IWarmUpMarketData)
+	if !ok {
+		c.logError(errors.New("Market data doesn't support warm up. "))
+		return
+	}
+	periods := make(map[string]int)
+	c.mut.Lock()
+	for s, st := range c.strategiesMap {
+		if ws, ok := st.(warmUpStrategy); ok {
+			periods[s] = ws.warmUpPeriods()
+		}
+	}
+	c.mut.Unlock()
+	md.RequestWarmUp(periods)
+}
+
 func (c *Engine) SetHistoryTimeBack(duration time.Duration) {
 	c.histDataTimeBack = duration
 }
 
+func (c *Engine) findStrategy(symbol string) (ICoreStrategy, bool) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	st, ok := c.strategiesMap[symbol]
+	return st, ok
+}
+
 func (c *Engine) getSymbolStrategy(symbol string) ICoreStrategy {
+	c.mut.Lock()
 	st, ok := c.strategiesMap[symbol]
+	c.mut.Unlock()
 	if !ok {
 		panic("Strategy for %v not found in map")
 	}
@@ -125,15 +296,32 @@ func (c *Engine) prepareLogger() {
 }
 
 func (c *Engine) logError(err error) {
+	if c.logger != nil {
+		c.printError(err)
+		return
+	}
 	go func() {
 		c.waitG.Add(1)
-		out := fmt.Sprintf("ERROR ||| %v .", err)
-		c.log.Print(out)
+		c.printError(err)
 		c.waitG.Done()
 	}()
 }
 
+//printError logs error from the calling goroutine. It's used by goroutines which engine already waits for,
+//so logging doesn't add to wait group while engine waits on it.
+func (c *Engine) printError(err error) {
+	if l := c.logger; l != nil {
+		l.Log(ErrorLevel, err.Error(), errorFields(err))
+		return
+	}
+	c.log.Print(fmt.Sprintf("ERROR ||| %v .", err))
+}
+
 func (c *Engine) logMessage(message string) {
+	if l := c.logger; l != nil {
+		l.Log(InfoLevel, message, nil)
+		return
+	}
 	go func() {
 		c.waitG.Add(1)
 		c.log.Print(message)
@@ -150,6 +338,10 @@ func (c *Engine) eCandleOpen(e *CandleOpenEvent) {
 }
 
 func (c *Engine) eCandleClose(e *CandleCloseEvent) {
+	if c.riskManager != nil {
+		c.riskManager.setLastPrice(e.Ticker.Symbol, e.Candle.Close)
+	}
+	c.portfolio.onPrice(e.Ticker.Symbol, e.Candle.Close)
 	if c.broker.IsSimulated() {
 		c.broker.Notify(e)
 	}
@@ -159,9 +351,17 @@ func (c *Engine) eCandleClose(e *CandleCloseEvent) {
 
 func (c *Engine) eTick(e *NewTickEvent) {
 	if e.Tick.Symbol == "" {
-		panic("Tick symbol is empty")
+		c.anomaly(e, "Tick symbol is empty. ")
+		return
 	}
 
+	c.portfolio.fx.onTick(e.Tick)
+	if e.Tick.HasTrade() {
+		if c.riskManager != nil {
+			c.riskManager.setLastPrice(e.Tick.Symbol, e.Tick.LastPrice)
+		}
+		c.portfolio.onPrice(e.Tick.Symbol, e.Tick.LastPrice)
+	}
 	st := c.getSymbolStrategy(e.Tick.Symbol)
 
 	if c.broker.IsSimulated() {
@@ -171,8 +371,85 @@ func (c *Engine) eTick(e *NewTickEvent) {
 
 }
 
-func (c *Engine) eCandleHistory(e *CandlesHistoryEvent) {
+func (c *Engine) eDataGap(e *DataGapEvent) {
+	if c.broker.IsSimulated() {
+		c.broker.Notify(e)
+	}
+	st := c.getSymbolStrategy(e.Ticker.Symbol)
+	st.notify(e)
+}
 
+func (c *Engine) eRoll(e *RollEvent) {
+	if c.broker.IsSimulated() {
+		c.broker.Notify(e)
+	}
+	st := c.getSymbolStrategy(e.getSymbol())
+	st.notify(e)
+}
+
+//eOptionChain passes option chain to strategy of the underlying and option quotes as ticks to strategies
+//of the options
+func (c *Engine) eOptionChain(e *OptionChainEvent) {
+	if c.broker.IsSimulated() {
+		c.broker.Notify(e)
+	}
+	if st, ok := c.findStrategy(e.getSymbol()); ok {
+		st.notify(e)
+	}
+	for _, q := range e.Quotes {
+		if st, ok := c.findStrategy(q.Option.Symbol); ok {
+			st.notify(&NewTickEvent{BaseEvent: be(e.getTime(), q.Option), Tick: q.tick(e.getTime())})
+		}
+	}
+}
+
+func (c *Engine) eFundamentalData(e *FundamentalDataEvent) {
+	if st, ok := c.findStrategy(e.getSymbol()); ok {
+		st.notify(e)
+	}
+}
+
+func (c *Engine) eNews(e *NewsEvent) {
+	if st, ok := c.findStrategy(e.getSymbol()); ok {
+		st.notify(e)
+	}
+}
+
+func (c *Engine) eBacktestProgress(e *BacktestProgressEvent) {
+	if c.progressHandler != nil {
+		c.progressHandler(e)
+	}
+}
+
+//eSymbolAdded creates strategy for the new universe symbol if it doesn't have one yet
+func (c *Engine) eSymbolAdded(e *SymbolAddedEvent) {
+	c.mut.Lock()
+	st, ok := c.strategiesMap[e.getSymbol()]
+	if !ok {
+		if c.strategyFactory == nil {
+			c.mut.Unlock()
+			c.logError(errors.New("Can't create strategy for added symbol. Strategy factory is not set: " + e.getSymbol()))
+			return
+		}
+		st = c.strategyFactory(e.Ticker)
+		c.initStrategy(st)
+		c.strategiesMap[e.getSymbol()] = st
+	}
+	c.mut.Unlock()
+
+	c.broker.Notify(e)
+	st.notify(e)
+}
+
+func (c *Engine) eSymbolRemoved(e *SymbolRemovedEvent) {
+	c.broker.Notify(e)
+	st := c.getSymbolStrategy(e.getSymbol())
+	st.notify(e)
+}
+
+func (c *Engine) eCandleHistory(e *CandlesHistoryEvent) {
+	st := c.getSymbolStrategy(e.Ticker.Symbol)
+	st.notify(e)
 }
 
 func (c *Engine) eTickHistory(e *TickHistoryEvent) {
@@ -189,9 +466,31 @@ func (c *Engine) eUpdatePortfolio(e *PortfolioNewPositionEvent) {
 }
 
 func (c *Engine) eEndOfData(e *EndOfDataEvent) {
+	c.mut.Lock()
+	symbols := make([]string, 0, len(c.strategiesMap))
+	for s := range c.strategiesMap {
+		symbols = append(symbols, s)
+	}
+	c.mut.Unlock()
+	sort.Strings(symbols)
+
+	//Strategies get the last callback before termination
+	for _, s := range symbols {
+		if st, ok := c.findStrategy(s); ok {
+			st.notify(e)
+			st.shutDown()
+		}
+	}
+	c.portfolio.recordEquity(c.now())
+	c.buildPerformanceReport(c.now())
+	c.saveHTMLReport()
+
 	c.waitG.Add(1)
 	go func() {
-		c.terminationChan <- struct{}{}
+		select {
+		case c.terminationChan <- struct{}{}:
+		case <-doneChan(c.ctx):
+		}
 		c.waitG.Done()
 	}()
 
@@ -202,12 +501,50 @@ func (c *Engine) errorIsCritical(err error) bool {
 }
 
 func (c *Engine) listendMD() {
-	fmt.Println("Listen RealTime")
+	c.logMessage("Listen RealTime")
+	stop := c.stopChan()
+	deterministic := c.isDeterministic()
+	warmingUp := c.State() == StateWarmingUp
 Loop:
 	for {
+		if !c.pause.acquire(doneChan(c.ctx), stop) {
+			select {
+			case <-stop:
+				c.stopMD()
+			default:
+				c.logMessage("Market data loop canceled")
+			}
+			break Loop
+		}
 		select {
 		case e := <-c.marketDataChan:
+			c.countMarketData()
+			c.bus.Publish(e)
+			c.countEvent(e)
+			c.firePortfolioEvents()
+			if isTimerClockEvent(e) {
+				if warmingUp {
+					c.warmUpDone()
+					warmingUp = false
+				}
+				c.advanceClock(e.getTime())
+				c.portfolio.accrueInterest(e.getTime())
+				c.checkStalePositions(e.getTime())
+				c.portfolio.sampleReturns(e.getTime())
+				c.portfolio.recordEquity(e.getTime())
+				c.portfolio.reallocate(e.getTime())
+				c.savePortfolioPeriodically(e.getTime())
+				c.sampleDepths()
+			}
+			if c.broker.IsSimulated() && isTimerClockEvent(e) {
+				c.fireTimers(e.getTime())
+				c.fireCustomEvents(e.getTime())
+			}
 			switch i := e.(type) {
+			case *TimerTickEvent:
+				c.fireTimers(c.now())
+				c.fireCustomEvents(c.now())
+				c.fireEndOfDay(c.now(), "")
 			case *NewTickEvent:
 				c.eTick(i)
 			case *CandleCloseEvent:
@@ -218,30 +555,113 @@ Loop:
 				c.eCandleHistory(i)
 			case *TickHistoryEvent:
 				c.eTickHistory(i)
+			case *DataGapEvent:
+				c.eDataGap(i)
+			case *RollEvent:
+				c.eRoll(i)
+			case *OptionChainEvent:
+				c.eOptionChain(i)
+			case *FundamentalDataEvent:
+				c.eFundamentalData(i)
+			case *NewsEvent:
+				c.eNews(i)
+			case *DividendEvent:
+				c.eDividend(i)
+			case *AuctionImbalanceEvent:
+				c.eAuction(i)
+			case *DelistingEvent:
+				c.eDelisting(i)
+			case *BacktestProgressEvent:
+				c.eBacktestProgress(i)
+			case *SymbolAddedEvent:
+				c.eSymbolAdded(i)
+			case *SymbolRemovedEvent:
+				c.eSymbolRemoved(i)
 			case *EndOfDataEvent:
 				c.logMessage("EOD event")
+				c.flushBroker()
 				c.eEndOfData(i)
+				c.removeCheckpoint()
 				break Loop
 			}
+			if c.broker.IsSimulated() && isTimerClockEvent(e) {
+				c.fireEndOfDay(e.getTime(), e.getSymbol())
+			}
+			if deterministic {
+				c.proceedEvents()
+				c.checkpointPeriodically(e.getTime())
+			}
+		case <-stop:
+			c.stopMD()
+			break Loop
+		case <-doneChan(c.ctx):
+			c.logMessage("Market data loop canceled")
+			break Loop
+		}
+	}
+
+}
+
+//isTimerClockEvent returns true for market data events which move simulated time forward
+func isTimerClockEvent(e event) bool {
+	switch e.(type) {
+	case *TimerTickEvent, *CandlesHistoryEvent, *TickHistoryEvent, *BacktestProgressEvent, *EndOfDataEvent:
+		return false
+	}
+	return !e.getTime().IsZero()
+}
 
+//fireTimers notifies strategies about their timers due not later than now. With simulated broker now is
+//time of market data event, so timers fire deterministically before data of the same time.
+//Otherwise it's wall clock time from timer producer.
+func (c *Engine) fireTimers(now time.Time) {
+	var timers eventArray
+	c.mut.Lock()
+	for _, st := range c.strategiesMap {
+		if ts, ok := st.(timerStrategy); ok {
+			timers = append(timers, ts.dueTimers(now)...)
 		}
 	}
+	c.mut.Unlock()
+	if len(timers) == 0 {
+		return
+	}
 
+	sort.SliceStable(timers, func(i, j int) bool {
+		//Timers of different strategies are created in random order, so symbol is compared before sequence
+		if timers[i].getTime().Equal(timers[j].getTime()) && timers[i].getSymbol() != timers[j].getSymbol() {
+			return timers[i].getSymbol() < timers[j].getSymbol()
+		}
+		return eventBefore(timers[i], timers[j])
+	})
+	for _, e := range timers {
+		if st, ok := c.findStrategy(e.getSymbol()); ok {
+			st.notify(e)
+		}
+	}
 }
 
 func (c *Engine) listenEvents() {
+	events, portfolio := c.events, c.portfolioChan
+	if c.isDeterministic() {
+		//Market data loop handles them
+		events, portfolio = nil, nil
+	}
 LOOP:
 	for {
 		select {
-		case e := <-c.events:
+		case e := <-events:
 			c.proxyEvent(e)
-		case e := <-c.portfolioChan:
+		case e := <-portfolio:
 			c.eUpdatePortfolio(e)
 		case e := <-c.errChan:
 			c.logError(e)
 		case <-c.terminationChan:
 			c.logMessage("Events loop terminated")
 			break LOOP
+		case <-doneChan(c.ctx):
+			c.logMessage("Events loop canceled")
+			break LOOP
 
 		}
 	}
@@ -249,14 +669,47 @@ LOOP:
 }
 
 func (c *Engine) proxyEvent(e event) {
-	st := c.getSymbolStrategy(e.getSymbol())
+	if !isMarketDataEvent(e) {
+		c.bus.Publish(e)
+	}
+	c.countEvent(e)
 	switch e.(type) {
 	case *NewOrderEvent:
-		c.broker.Notify(e)
+		if r := c.checkNewOrder(e.(*NewOrderEvent)); r != nil {
+			setCorrelationID(r, correlationIDOf(e))
+			c.logMessage(r.String())
+			c.trackOrder(r)
+			c.getSymbolStrategy(r.getSymbol()).notify(r)
+			return
+		}
+		c.deliverRequest(e)
+		return
 	case *OrderCancelRequestEvent:
-		c.broker.Notify(e)
+		c.deliverRequest(e)
+		return
 	case *OrderReplaceRequestEvent:
-		c.broker.Notify(e)
+		c.deliverRequest(e)
+		return
+	case *CustomEvent:
+		c.PublishCustomEvent(e.(*CustomEvent))
+		return
+	case *StrategyHaltedEvent, *EventDroppedEvent, *StrategyErrorEvent:
+		c.logMessage(e.String())
+		return
+	case *MarginCallEvent, *PortfolioKillSwitchEvent:
+		c.onPortfolioEvent(e)
+		return
+	}
+
+	st, ok := c.findStrategy(e.getSymbol())
+	if !ok {
+		//Broker passes back market data of the symbols without strategy, e.g. options of the chain
+		if _, isTick := e.(*NewTickEvent); isTick {
+			return
+		}
+		st = c.getSymbolStrategy(e.getSymbol())
+	}
+	switch e.(type) {
 	case *OrderCancelEvent:
 		st.notify(e)
 	case *OrderCancelRejectEvent:
@@ -276,14 +729,73 @@ func (c *Engine) proxyEvent(e event) {
 }
 
 func (c *Engine) Run() {
+	c.RunContext(context.Background())
+}
+
+//RunContext runs engine until end of data or until context is done. Strategies, broker and market data
+//which implement IContextAware get context of the run, so shutdown doesn't hang on stuck handlers
+//after cancellation.
+func (c *Engine) RunContext(ctx context.Context) {
+	if err := c.startRun(); err != nil {
+		c.logError(err)
+		return
+	}
+	c.mut.Lock()
+	c.ctx, c.cancel = context.WithCancel(ctx)
+	mdCtx, cancelMD := context.WithCancel(c.ctx)
+	c.cancelMD = cancelMD
+	for _, st := range c.strategiesMap {
+		st.setContext(c.ctx)
+	}
+	c.mut.Unlock()
+	if b, ok := c.broker.(IContextAware); ok {
+		b.SetContext(c.ctx)
+	}
+	if md, ok := c.md.(IContextAware); ok {
+		md.SetContext(mdCtx)
+	}
+	defer c.endRun()
+	defer c.cancel()
+
+	if err := c.restorePortfolio(); err != nil {
+		c.logError(err)
+	}
+	if err := c.checkUniverse(); err != nil {
+		c.fail(err)
+		return
+	}
 	c.md.Connect()
 	c.broker.Connect()
+	if err := c.checkConnected(); err != nil {
+		c.broker.Disconnect()
+		c.fail(err)
+		return
+	}
+	if err := c.resumeCheckpoint(); err != nil {
+		c.broker.Disconnect()
+		c.fail(err)
+		return
+	}
 	c.logMessage("Engine Run")
 	c.md.RequestHistoricalData(c.histDataTimeBack)
 	c.logMessage("Request historical market data")
+	if c.warmUp {
+		c.requestWarmUp()
+	}
 	c.md.Run()
+	//Backtest data is prepared by Run, so it can be fingerprinted now
+	c.startManifest()
 	c.logMessage("Market data listen quotes")
 
+	var timer *TimerEventProducer
+	if !c.broker.IsSimulated() {
+		timer = &TimerEventProducer{}
+		timer.SetDelay(1000)
+		timer.SetFraction(1)
+		timer.Connect(c.errChan, c.marketDataChan)
+		timer.Run()
+	}
+
 	wg := &sync.WaitGroup{}
 	wg.Add(2)
 	go func() {
@@ -294,24 +806,52 @@ func (c *Engine) Run() {
 
 	go func() {
 		c.listendMD()
+		if timer != nil {
+			timer.Stop()
+		}
 		wg.Done()
 		c.logMessage("MD done")
 	}()
 
 	wg.Wait()
 
+	c.stopping()
 	c.broker.Disconnect()
 	c.shutDown()
+	c.lifecycle.transition(StateStopped, nil)
 
 }
 
+//Stop cancels current run. Run returns without waiting for handlers which don't observe cancellation.
+//Use Shutdown to stop without losing events.
+func (c *Engine) Stop() {
+	c.mut.Lock()
+	cancel := c.cancel
+	c.mut.Unlock()
+	c.stopping()
+	if cancel != nil {
+		cancel()
+	}
+}
+
 func (c *Engine) shutDown() {
 	c.logMessage("Shutting down...")
+	stopDrain := c.drainEvents()
+	c.mut.Lock()
+	strategies := make([]ICoreStrategy, 0, len(c.strategiesMap))
 	for _, st := range c.strategiesMap {
+		strategies = append(strategies, st)
+	}
+	c.mut.Unlock()
+	//Engine isn't locked while strategies finish, drained events are routed to them
+	for _, st := range strategies {
 		st.shutDown()
 	}
 	c.broker.shutDown()
 	c.md.ShutDown()
-	c.waitG.Wait()
+	waitContext(c.ctx, c.waitG)
+	stopDrain()
+	c.flushJournal()
+	c.savePortfolio()
 	c.logMessage("Done!")
 }
@@ -117,12 +117,6 @@ func newTestStrategyWithLogic(symbol *Instrument) *BasicStrategy {
 	}
 
 	bs.init(cc)
-	bs.mdChan = make(chan event, 2)
-	bs.handlersWaitGroup = &sync.WaitGroup{}
-	go func() {
-		bs.mdChan <- &NewTickEvent{}
-		//bs.mdChan <- &NewTickEvent{}
-	}()
 
 	return &bs
 
@@ -0,0 +1,182 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+)
+
+//symbolReturns keeps price returns of the symbol for the same periods as portfolio returns
+type symbolReturns struct {
+	last        float64
+	periodPrice float64
+	returns     []float64
+}
+
+//onPrice remembers last price of the symbol. It's called by engine for every tick with trade and candle close.
+func (p *portfolioHandler) onPrice(symbol string, price float64) {
+	if math.IsNaN(price) || price <= 0 {
+		return
+	}
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	if p.prices == nil {
+		p.prices = make(map[string]*symbolReturns)
+	}
+	s, ok := p.prices[symbol]
+	if !ok {
+		s = &symbolReturns{}
+		p.prices[symbol] = s
+	}
+	s.last = price
+}
+
+//sampleSymbolReturns adds returns of the finished period for all symbols with prices. It's called with
+//portfolio lock held.
+func (p *portfolioHandler) sampleSymbolReturns() {
+	for _, s := range p.prices {
+		if s.last <= 0 {
+			continue
+		}
+		if s.periodPrice > 0 {
+			s.returns = append(s.returns, s.last/s.periodPrice-1)
+			if len(s.returns) > p.returns.window {
+				s.returns = append([]float64{}, s.returns[len(s.returns)-p.returns.window:]...)
+			}
+		}
+		s.periodPrice = s.last
+	}
+}
+
+//alignedReturns returns last returns of both symbols for the same periods
+func (p *portfolioHandler) alignedReturns(a string, b string) ([]float64, []float64) {
+	sa, okA := p.prices[a]
+	sb, okB := p.prices[b]
+	if !okA || !okB {
+		return nil, nil
+	}
+	n := len(sa.returns)
+	if len(sb.returns) < n {
+		n = len(sb.returns)
+	}
+	return sa.returns[len(sa.returns)-n:], sb.returns[len(sb.returns)-n:]
+}
+
+//covariance returns sample covariance of x and y and sample variances of them
+func covariance(x []float64, y []float64) (float64, float64, float64) {
+	n := float64(len(x))
+	var mx, my float64
+	for i := range x {
+		mx += x[i]
+		my += y[i]
+	}
+	mx /= n
+	my /= n
+	var cov, vx, vy float64
+	for i := range x {
+		cov += (x[i] - mx) * (y[i] - my)
+		vx += (x[i] - mx) * (x[i] - mx)
+		vy += (y[i] - my) * (y[i] - my)
+	}
+	return cov / (n - 1), vx / (n - 1), vy / (n - 1)
+}
+
+//Correlation is correlation of price returns of two symbols over the last returns periods. It's NaN if
+//there are less than three common returns or one of the symbols had constant price.
+func (p *portfolioHandler) Correlation(a string, b string) float64 {
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	x, y := p.alignedReturns(a, b)
+	if len(x) < 3 {
+		return math.NaN()
+	}
+	cov, vx, vy := covariance(x, y)
+	if vx == 0 || vy == 0 {
+		return math.NaN()
+	}
+	return cov / math.Sqrt(vx*vy)
+}
+
+//Beta is sensitivity of symbol returns to returns of the benchmark set with Engine.SetBenchmark
+func (p *portfolioHandler) Beta(symbol string) float64 {
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	x, y := p.alignedReturns(symbol, p.benchmark)
+	if len(x) < 3 {
+		return math.NaN()
+	}
+	cov, _, vb := covariance(x, y)
+	if vb == 0 {
+		return math.NaN()
+	}
+	return cov / vb
+}
+
+//PortfolioBeta is sum of betas of positions weighted by their market value as fraction of equity. Positions
+//without beta are skipped.
+func (p *portfolioHandler) PortfolioBeta() float64 {
+	equity := p.Equity()
+	if equity <= 0 {
+		return math.NaN()
+	}
+	beta := 0.0
+	for s, pos := range p.Positions() {
+		b := p.Beta(s)
+		if math.IsNaN(b) {
+			continue
+		}
+		beta += b * p.fx.toAccount(pos.MarketValue, pos.Currency) / equity
+	}
+	return beta
+}
+
+func (p *portfolioHandler) setBenchmark(symbol string) {
+	p.mut.Lock()
+	p.benchmark = symbol
+	p.mut.Unlock()
+}
+
+//SetBenchmark sets symbol which returns are used for beta of positions. Engine should get market data of
+//the benchmark, e.g. with strategy which doesn't trade.
+func (c *Engine) SetBenchmark(symbol string) {
+	c.portfolio.setBenchmark(symbol)
+}
+
+//CorrelationCheck vetoes orders which increase total absolute market value of positions correlated with the
+//order symbol above MaxCorrelatedExposure. Positions are correlated if correlation of their returns is
+//MinCorrelation or higher. Symbol of the order is always counted.
+type CorrelationCheck struct {
+	MinCorrelation        float64
+	MaxCorrelatedExposure float64
+}
+
+func (c *CorrelationCheck) CheckOrder(o *Order, lastPrice float64, p IPortfolio) error {
+	price := orderPrice(o, lastPrice)
+	if c.MaxCorrelatedExposure <= 0 || math.IsNaN(price) {
+		return nil
+	}
+	symbol := o.Ticker.Symbol
+	var qty int64
+	exposure := 0.0
+	for s, pos := range p.Positions() {
+		if s == symbol {
+			qty = pos.Qty
+			continue
+		}
+		if p.Correlation(symbol, s) >= c.MinCorrelation {
+			exposure += math.Abs(pos.MarketValue)
+		}
+	}
+	projected := qty + o.Qty
+	if o.Side == OrderSell {
+		projected = qty - o.Qty
+	}
+	if abs64(projected) <= abs64(qty) {
+		return nil
+	}
+	exposure += price * o.Ticker.QtyFloat(abs64(projected)) * o.Ticker.multiplier()
+	if exposure > c.MaxCorrelatedExposure {
+		return fmt.Errorf("Exposure %v of positions correlated with %v would exceed limit %v. ", exposure,
+			symbol, c.MaxCorrelatedExposure)
+	}
+	return nil
+}
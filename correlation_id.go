@@ -0,0 +1,64 @@
+package engine
+
+import (
+	"strconv"
+)
+
+//decisionState is correlation ID of the current decision of the strategy
+type decisionState struct {
+	id  string
+	seq uint64
+}
+
+//CorrelationID returns ID of the current decision. Every callback of user strategy is new decision, orders
+//sent from it get its ID, as well as their requests, broker events and portfolio updates. It's empty outside
+//of callbacks.
+func (b *BasicStrategy) CorrelationID() string {
+	return b.decision.id
+}
+
+//SetCorrelationID replaces ID of the current decision, e.g. with ID of external signal. Orders sent after it
+//get the new ID. Outside of callbacks it's ID of orders until callback starts new decision.
+func (b *BasicStrategy) SetCorrelationID(id string) {
+	b.decision.id = id
+}
+
+//newCorrelationID returns unique ID of new decision of the strategy
+func (b *BasicStrategy) newCorrelationID() string {
+	b.decision.seq++
+	return b.orderIDPrefix() + "D" + strconv.FormatUint(b.decision.seq, 10)
+}
+
+//startDecision starts new decision for callback of user strategy. Returned function ends it.
+func (b *BasicStrategy) startDecision() func() {
+	b.decision.id = b.newCorrelationID()
+	return func() {
+		b.decision.id = ""
+	}
+}
+
+//orderCorrelationID returns correlation ID of the order. Order sent without decision is decision itself.
+func (b *BasicStrategy) orderCorrelationID(order *Order) string {
+	if order.CorrelationID != "" {
+		return order.CorrelationID
+	}
+	if b.decision.id != "" {
+		return b.decision.id
+	}
+	return b.newCorrelationID()
+}
+
+//correlationIDOf returns correlation ID of the event
+func correlationIDOf(e event) string {
+	if b, ok := e.(interface{ base() *BaseEvent }); ok {
+		return b.base().CorrelationID
+	}
+	return ""
+}
+
+//setCorrelationID sets correlation ID of the event unless it already has one
+func setCorrelationID(e event, id string) {
+	if b, ok := e.(interface{ base() *BaseEvent }); ok && b.base().CorrelationID == "" {
+		b.base().CorrelationID = id
+	}
+}
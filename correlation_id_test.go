@@ -0,0 +1,100 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBasicStrategy_CorrelationID(t *testing.T) {
+	st := newTestBasicStrategy()
+	st.ch.events = make(chan event, 10)
+	assert.Equal(t, "", st.CorrelationID())
+
+	var decision string
+	var ordID string
+	st.callUserStrategy("OnCandleClose", func() {
+		decision = st.CorrelationID()
+		id, err := st.NewLimitOrder(10, OrderBuy, 100, GTCTIF, "Sim")
+		assert.Nil(t, err)
+		ordID = id
+	})
+	assert.NotEqual(t, "", decision)
+	assert.Equal(t, "", st.CorrelationID())
+
+	e := (<-st.ch.events).(*NewOrderEvent)
+	assert.Equal(t, decision, e.CorrelationID)
+	assert.Equal(t, decision, e.LinkedOrder.CorrelationID)
+	assert.Equal(t, ordID, e.LinkedOrder.Id)
+
+	//Every callback is new decision
+	st.callUserStrategy("OnCandleClose", func() {
+		assert.NotEqual(t, decision, st.CorrelationID())
+	})
+
+	//Order outside of callbacks is decision itself
+	_, err := st.NewLimitOrder(9, OrderBuy, 100, GTCTIF, "Sim")
+	assert.Nil(t, err)
+	other := (<-st.ch.events).(*NewOrderEvent)
+	assert.NotEqual(t, "", other.CorrelationID)
+	assert.NotEqual(t, decision, other.CorrelationID)
+
+	st.callUserStrategy("OnTick", func() {
+		st.SetCorrelationID("signal-1")
+		st.NewLimitOrder(8, OrderBuy, 100, GTCTIF, "Sim")
+	})
+	assert.Equal(t, "signal-1", (<-st.ch.events).(*NewOrderEvent).CorrelationID)
+}
+
+func TestBasicStrategy_CorrelationIDOfRequestsAndFills(t *testing.T) {
+	st := newTestBasicStrategy()
+	st.ch.events = make(chan event, 10)
+	order := newTestOrder(10, OrderBuy, 100, "id1")
+	order.Ticker = st.symbol
+	order.CorrelationID = "D1"
+	assert.Nil(t, st.newOrder(order))
+	assert.Equal(t, "D1", (<-st.ch.events).(*NewOrderEvent).CorrelationID)
+
+	st.onOrderConfirmHandler(&OrderConfirmationEvent{OrdId: order.Id, BaseEvent: be(time.Now(), order.Ticker)})
+	assert.Nil(t, st.ReplaceOrder(order.Id, 10.5))
+	assert.Equal(t, "D1", (<-st.ch.events).(*OrderReplaceRequestEvent).CorrelationID)
+	assert.Nil(t, st.CancelOrder(order.Id))
+	assert.Equal(t, "D1", (<-st.ch.events).(*OrderCancelRequestEvent).CorrelationID)
+
+	fill := &OrderFillEvent{OrdId: order.Id, Price: 11, Qty: 100, BaseEvent: be(time.Now(), order.Ticker)}
+	fill.CorrelationID = "D1"
+	st.onOrderFillHandler(fill)
+	assert.Equal(t, "D1", st.currentTrade.CorrelationID)
+	assert.Equal(t, "D1", (<-st.ch.portfolio).CorrelationID)
+}
+
+func TestSimBroker_CorrelationID(t *testing.T) {
+	b := newTestSimBrokerWorker()
+	order := newTestOrder(10.1, OrderSell, 100, "id1")
+	order.CorrelationID = "D1"
+	v := putNewOrderToWorkerAndGetBrokerEvent(b, order)
+	assert.Equal(t, "D1", v.(*OrderConfirmationEvent).CorrelationID)
+
+	v = putCancelRequestToWorkerAndGetBrokerEvent(b, "id1")
+	assert.Equal(t, "D1", v.(*OrderCancelEvent).CorrelationID)
+
+	req := &NewOrderEvent{LinkedOrder: newTestOrder(10, OrderBuy, 100, "id2"), BaseEvent: be(time.Now(), order.Ticker)}
+	req.CorrelationID = "D2"
+	rejected := newRequestRejectEvent(req, "Not delivered", time.Now())
+	assert.Equal(t, "D2", rejected.(*OrderRejectedEvent).CorrelationID)
+}
+
+func TestMarshalEvent_CorrelationID(t *testing.T) {
+	e := &OrderFillEvent{OrdId: "1", Price: 10, Qty: 100, BaseEvent: be(time.Now(), newTestInstrument())}
+	e.CorrelationID = "D1"
+	data, err := MarshalEvent(e)
+	assert.Nil(t, err)
+	restored, err := UnmarshalEvent(data)
+	assert.Nil(t, err)
+	assert.Equal(t, "D1", restored.(*OrderFillEvent).CorrelationID)
+
+	fields := eventLogFields(e)
+	assert.Equal(t, "1", fields["orderID"])
+	assert.Equal(t, "D1", fields["correlationID"])
+}
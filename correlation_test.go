@@ -0,0 +1,53 @@
+package engine
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPortfolio_Correlation(t *testing.T) {
+	p := newPortfolio()
+	p.setCapital(10000)
+	assert.Nil(t, p.setReturnsPeriod(time.Hour, 10, 0.95))
+	p.setBenchmark("SPY")
+
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	spy := []float64{100, 101, 99, 102, 103, 101}
+	for i := range spy {
+		p.onPrice("SPY", spy[i])
+		//A moves twice as much as benchmark, B moves opposite to it
+		p.onPrice("A", 50*(1+2*(spy[i]/100-1)))
+		p.onPrice("B", 20*(1-(spy[i]/100-1)))
+		if i >= 2 {
+			p.onPrice("C", spy[i])
+		}
+		p.sampleReturns(tm.Add(time.Duration(i) * time.Hour))
+	}
+
+	assert.InDelta(t, 1.0, p.Correlation("A", "SPY"), 0.01)
+	assert.InDelta(t, -1.0, p.Correlation("B", "SPY"), 0.01)
+	assert.InDelta(t, 2.0, p.Beta("A"), 0.1)
+	assert.InDelta(t, 1.0, p.Beta("SPY"), 0.0000001)
+	//C has 3 returns
+	assert.False(t, math.IsNaN(p.Correlation("C", "A")))
+	assert.True(t, math.IsNaN(p.Correlation("D", "A")))
+
+	p.positions["SPY"] = PositionInfo{Symbol: "SPY", Qty: 20, MarketValue: 2000}
+	p.positions["B"] = PositionInfo{Symbol: "B", Qty: 100, MarketValue: 2000}
+	assert.InDelta(t, (2000+2000*p.Beta("B"))/14000, p.PortfolioBeta(), 0.0000001)
+
+	c := CorrelationCheck{MinCorrelation: 0.8, MaxCorrelatedExposure: 3000}
+	ord := newTestRiskOrder(OrderBuy, 21, 50)
+	ord.Ticker.Symbol = "A"
+	assert.NotNil(t, c.CheckOrder(ord, 50, p))
+	ord.Qty = 20
+	assert.Nil(t, c.CheckOrder(ord, 50, p))
+	//B isn't correlated with A
+	c.MaxCorrelatedExposure = 2500
+	ord.Qty = 10
+	assert.Nil(t, c.CheckOrder(ord, 50, p))
+	ord.Qty = 11
+	assert.NotNil(t, c.CheckOrder(ord, 50, p))
+}
@@ -0,0 +1,168 @@
+package engine
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+)
+
+//costFill is execution of the trade kept to re-price it with other costs. Price is market price without
+//slippage, Share is part of the fill which belongs to the trade.
+type costFill struct {
+	Type  OrderType
+	Side  OrderSide
+	Qty   int64
+	Price float64
+	Share float64
+}
+
+//CostSensitivity is result of the finished run re-priced with other cost model. Decisions of strategies and
+//executions are the same, only commission and slippage of closed trades are changed. TotalReturn is return
+//of the run with difference of costs of closed trades.
+type CostSensitivity struct {
+	Costs       CostModel
+	Commission  float64
+	Slippage    float64
+	NetPnL      float64
+	TotalReturn float64
+	Stats       TradeStats
+}
+
+//repricedTradesStrategy is implemented by strategies which can re-price completed trades
+type repricedTradesStrategy interface {
+	repricedTrades(m CostModel) []ClosedTradeInfo
+}
+
+//recordCostFill keeps the fill of the trade for re-pricing. Execution which reverses position is split by qty
+//between closed trade and the new one, like its costs.
+func (b *BasicStrategy) recordCostFill(trade *Trade, newPos *Trade, prevPos int64, o *Order, e *OrderFillEvent) {
+	if o == nil || o.Destination == delistingDestination {
+		return
+	}
+	f := costFill{Type: o.Type, Side: o.Side, Qty: e.Qty, Price: e.Price, Share: 1}
+	if units := b.symbol.QtyFloat(e.Qty) * b.symbol.multiplier(); e.Slippage != 0 && units > 0 {
+		move := e.Slippage / units
+		if o.Side == OrderSell {
+			move = -move
+		}
+		f.Price -= move
+	}
+	if newPos != nil && e.Qty > 0 {
+		f.Share = math.Min(1, math.Abs(float64(prevPos))/float64(e.Qty))
+		n := f
+		n.Share = 1 - f.Share
+		if n.Share > 0 {
+			newPos.costFills = append(newPos.costFills, n)
+		}
+	}
+	trade.costFills = append(trade.costFills, f)
+}
+
+//costs returns commission and slippage of the fill in quote currency, calculated like simulated broker does
+func (f costFill) costs(inst *Instrument, m CostModel) (commission float64, slippage float64) {
+	units := inst.QtyFloat(f.Qty) * inst.multiplier()
+	price := f.Price
+	switch f.Type {
+	case MarketOrder, StopOrder, MarketOnOpen, MarketOnClose:
+		move := m.SlippageTicks * inst.MinTick
+		if f.Side == OrderSell {
+			move = -move
+		}
+		if move != 0 && price+move > 0 {
+			price += move
+			slippage = math.Abs(move) * units
+		}
+	}
+	return m.fees(inst, f.Side, f.Qty, price).Total(), slippage
+}
+
+//repricedTrades returns completed trades of the strategy with costs of the model. Trades without kept fills,
+//e.g. restored from checkpoint, keep costs of the run.
+func (b *BasicStrategy) repricedTrades(m CostModel) []ClosedTradeInfo {
+	trades := make([]ClosedTradeInfo, 0, len(b.closedTrades))
+	for _, t := range b.closedTrades {
+		info := newClosedTradeInfo(t)
+		if len(t.costFills) > 0 {
+			gross := t.ClosedPnL + t.Slippage
+			info.Commission, info.Slippage = 0, 0
+			for _, f := range t.costFills {
+				commission, slippage := f.costs(t.Ticker, m)
+				info.Commission += t.toAccount(commission) * f.Share
+				info.Slippage += t.toAccount(slippage) * f.Share
+			}
+			info.PnL = gross - info.Slippage
+			info.NetPnL = info.PnL - info.Commission
+		}
+		trades = append(trades, info)
+	}
+	return trades
+}
+
+//repricedTrades returns completed trades of all strategies of the group with costs of the model
+func (g *StrategyGroup) repricedTrades(m CostModel) []ClosedTradeInfo {
+	var trades []ClosedTradeInfo
+	g.forEach(func(st *BasicStrategy) { trades = append(trades, st.repricedTrades(m)...) })
+	return trades
+}
+
+//CostSensitivity re-prices closed trades of the finished run with every cost model, so fragility of the result
+//to cost assumptions can be seen without running strategies again. Minimum commission is applied to every
+//fill, as simulated broker does.
+func (c *Engine) CostSensitivity(models []CostModel) ([]CostSensitivity, error) {
+	perf, ok := c.PerformanceReport()
+	if !ok {
+		return nil, errors.New("Run isn't finished. ")
+	}
+	for _, m := range models {
+		if err := m.validate(); err != nil {
+			return nil, err
+		}
+	}
+	runNet := 0.0
+	for _, t := range c.ClosedTrades() {
+		runNet += t.NetPnL
+	}
+
+	rows := make([]CostSensitivity, 0, len(models))
+	for _, m := range models {
+		var trades []ClosedTradeInfo
+		c.mut.Lock()
+		for _, st := range c.strategiesMap {
+			if rs, ok := st.(repricedTradesStrategy); ok {
+				trades = append(trades, rs.repricedTrades(m)...)
+			}
+		}
+		c.mut.Unlock()
+		r := CostSensitivity{Costs: m, Stats: calcTradeStats(trades), TotalReturn: math.NaN()}
+		for _, t := range trades {
+			r.Commission += t.Commission
+			r.Slippage += t.Slippage
+			r.NetPnL += t.NetPnL
+		}
+		if perf.InitialEquity > 0 {
+			r.TotalReturn = (perf.FinalEquity+r.NetPnL-runNet)/perf.InitialEquity - 1
+		}
+		rows = append(rows, r)
+	}
+	return rows, nil
+}
+
+//WriteCostSensitivityCSV writes sensitivity table with header line, one row per cost model
+func WriteCostSensitivityCSV(w io.Writer, rows []CostSensitivity) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"per_unit", "percent", "minimum", "slippage_ticks", "exchange_fee", "sec_fee_rate",
+		"taf_per_unit", "taf_max", "commission", "slippage", "net_pnl", "total_return", "trades", "win_rate",
+		"expectancy"})
+	for _, r := range rows {
+		cw.Write([]string{formatFloat(r.Costs.PerUnit), formatFloat(r.Costs.Percent), formatFloat(r.Costs.Minimum),
+			formatFloat(r.Costs.SlippageTicks), formatFloat(r.Costs.ExchangeFee), formatFloat(r.Costs.SECFeeRate),
+			formatFloat(r.Costs.TAFPerUnit), formatFloat(r.Costs.TAFMax), formatFloat(r.Commission),
+			formatFloat(r.Slippage),
+			formatFloat(r.NetPnL), formatFloat(r.TotalReturn), strconv.Itoa(r.Stats.Trades),
+			formatFloat(r.Stats.WinRate), formatFloat(r.Stats.Expectancy)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,111 @@
+package engine
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCostFill_costs(t *testing.T) {
+	inst := &Instrument{Symbol: "Test", MinTick: 0.01, LotSize: 1}
+	f := costFill{Type: MarketOrder, Side: OrderSell, Qty: 100, Price: 20, Share: 1}
+	commission, slippage := f.costs(inst, CostModel{PerUnit: 0.005, Percent: 0.001, SlippageTicks: 2})
+	assert.InDelta(t, 2.0, slippage, 0.0000001)
+	assert.InDelta(t, 0.5+0.001*19.98*100, commission, 0.0000001)
+
+	//Limit orders have no slippage, minimum commission is applied to the fill
+	f.Type = LimitOrder
+	commission, slippage = f.costs(inst, CostModel{PerUnit: 0.005, Minimum: 1, SlippageTicks: 2})
+	assert.Equal(t, 0.0, slippage)
+	assert.Equal(t, 1.0, commission)
+}
+
+func TestBasicStrategy_recordCostFill(t *testing.T) {
+	st := newTestMarginStrategy(100000)
+	st.symbol.MinTick = 0.01
+	fillTestOrder(st, OrderBuy, 100, 50)
+	trade := st.currentTrade
+
+	//Reversing fill is split between closed trade and the new one, price is restored without slippage
+	id, err := st.NewMarketOrder(OrderSell, 300, DayTIF, "Sim")
+	assert.Nil(t, err)
+	tm := st.mostRecentTime
+	st.proxyEvent(&OrderConfirmationEvent{BaseEvent: be(tm, st.symbol), OrdId: id})
+	st.proxyEvent(&OrderFillEvent{BaseEvent: be(tm, st.symbol), OrdId: id, Price: 49.98, Qty: 300, Slippage: 6})
+	if assert.Len(t, trade.costFills, 2) {
+		f := trade.costFills[1]
+		assert.InDelta(t, 50.0, f.Price, 0.0000001)
+		assert.InDelta(t, 1.0/3, f.Share, 0.0000001)
+	}
+	if assert.Len(t, st.currentTrade.costFills, 1) {
+		assert.InDelta(t, 2.0/3, st.currentTrade.costFills[0].Share, 0.0000001)
+	}
+
+	//Re-pricing without costs returns trade PnL without slippage
+	trades := st.repricedTrades(CostModel{})
+	if assert.Len(t, trades, 1) {
+		assert.InDelta(t, 0.0, trades[0].NetPnL, 0.0000001)
+		assert.InDelta(t, 0.0, trades[0].Slippage, 0.0000001)
+	}
+	trades = st.repricedTrades(CostModel{SlippageTicks: 1})
+	assert.InDelta(t, -2.0, trades[0].NetPnL, 0.0000001)
+}
+
+func TestEngine_CostSensitivity(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cost_sweep")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	md := newTestBTMforSyntheticTicks(dir, 1)
+	broker := &SimBroker{}
+	runCosts := CostModel{PerUnit: 0.01, Minimum: 0.5, SlippageTicks: 1}
+	assert.Nil(t, broker.SetCostModel(runCosts))
+	inst := &Instrument{Symbol: "Sym1", MinTick: 0.01}
+	e, err := New(WithMarketData(md), WithBroker(broker), WithStrategy(inst, &manifestTestStrategy{}, 1),
+		WithPortfolio(PortfolioConfig{InitialCapital: 10000}))
+	assert.Nil(t, err)
+	_, err = e.CostSensitivity([]CostModel{runCosts})
+	assert.NotNil(t, err)
+	e.SetDeterministic(true)
+	e.Run()
+	assert.Nil(t, e.Status().Err)
+
+	_, err = e.CostSensitivity([]CostModel{{Percent: -1}})
+	assert.NotNil(t, err)
+	rows, err := e.CostSensitivity([]CostModel{{}, runCosts, {PerUnit: 0.05, Minimum: 1, SlippageTicks: 5}})
+	assert.Nil(t, err)
+	if !assert.Len(t, rows, 3) {
+		return
+	}
+
+	//Costs of the run reproduce its result
+	perf, _ := e.PerformanceReport()
+	trades := e.ClosedTrades()
+	assert.True(t, len(trades) > 5)
+	run := rows[1]
+	assert.Equal(t, calcTradeStats(trades), run.Stats)
+	assert.InDelta(t, perf.TotalReturn, run.TotalReturn, 0.0000001)
+	net := 0.0
+	for _, tr := range trades {
+		net += tr.NetPnL
+	}
+	assert.InDelta(t, net, run.NetPnL, 0.0000001)
+	assert.True(t, run.Commission > 0)
+	assert.True(t, run.Slippage > 0)
+
+	//Result is worse with higher costs
+	assert.Equal(t, 0.0, rows[0].Commission)
+	assert.Equal(t, 0.0, rows[0].Slippage)
+	assert.InDelta(t, net+run.Commission+run.Slippage, rows[0].NetPnL, 0.0000001)
+	assert.True(t, rows[2].NetPnL < run.NetPnL)
+	assert.True(t, rows[2].TotalReturn < run.TotalReturn)
+
+	var buf bytes.Buffer
+	assert.Nil(t, WriteCostSensitivityCSV(&buf, rows))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 4)
+	assert.True(t, strings.HasPrefix(lines[2], "0.01,0,0.5,1,"))
+}
@@ -0,0 +1,153 @@
+package engine
+
+import (
+	"errors"
+	"math"
+)
+
+//CostModel is commission and slippage of simulated executions in quote currency. Commission of the fill is
+//PerUnit for every qty unit plus Percent of execution value, but not less than Minimum. Market and stop orders
+//are executed SlippageTicks min ticks worse than market price. ExchangeFee is charged for every qty unit. Sells
+//pay regulatory fees: SECFeeRate of execution value and TAFPerUnit for every qty unit, but not more than
+//TAFMax if it's set.
+type CostModel struct {
+	PerUnit       float64
+	Percent       float64
+	Minimum       float64
+	SlippageTicks float64
+	ExchangeFee   float64
+	SECFeeRate    float64
+	TAFPerUnit    float64
+	TAFMax        float64
+}
+
+//FillFees are fee components of the fill in quote currency
+type FillFees struct {
+	Commission  float64
+	ExchangeFee float64
+	SECFee      float64
+	TAF         float64
+}
+
+//Total returns sum of all fees
+func (f FillFees) Total() float64 {
+	return f.Commission + f.ExchangeFee + f.SECFee + f.TAF
+}
+
+func (f FillFees) add(o FillFees) FillFees {
+	return FillFees{
+		Commission:  f.Commission + o.Commission,
+		ExchangeFee: f.ExchangeFee + o.ExchangeFee,
+		SECFee:      f.SECFee + o.SECFee,
+		TAF:         f.TAF + o.TAF,
+	}
+}
+
+//SetCostModel sets commission and slippage of executions of all symbols
+func (b *SimBroker) SetCostModel(m CostModel) error {
+	if err := m.validate(); err != nil {
+		return err
+	}
+	b.costs = m
+	if b.workersMut == nil {
+		return nil
+	}
+	b.workersMut.RLock()
+	defer b.workersMut.RUnlock()
+	for _, w := range b.workers {
+		w.mpMutext.Lock()
+		w.costs = m
+		w.mpMutext.Unlock()
+	}
+	return nil
+}
+
+func (m CostModel) validate() error {
+	for _, v := range []float64{m.PerUnit, m.Percent, m.Minimum, m.SlippageTicks, m.ExchangeFee, m.SECFeeRate,
+		m.TAFPerUnit, m.TAFMax} {
+		if v < 0 || math.IsNaN(v) {
+			return errors.New("Commission and slippage can't be negative or NaN. ")
+		}
+	}
+	return nil
+}
+
+//applyCosts moves price of the fill by slippage and sets commission and slippage of the fill
+func (b *simBrokerWorker) applyCosts(o *simBrokerOrder, e *OrderFillEvent) {
+	inst := o.Ticker
+	units := inst.QtyFloat(e.Qty) * inst.multiplier()
+	switch o.Type {
+	case MarketOrder, StopOrder, MarketOnOpen, MarketOnClose:
+		move := b.costs.SlippageTicks * inst.MinTick
+		if o.Side == OrderSell {
+			move = -move
+		}
+		if move != 0 && e.Price+move > 0 {
+			e.Price += move
+			e.Slippage = math.Abs(move) * units
+		}
+	}
+	e.Fees = b.costs.fees(inst, o.Side, e.Qty, e.Price)
+	e.Commission = e.Fees.Total()
+}
+
+//fees returns fees of the fill at execution price
+func (m CostModel) fees(inst *Instrument, side OrderSide, qty int64, price float64) FillFees {
+	units := inst.QtyFloat(qty) * inst.multiplier()
+	f := FillFees{
+		Commission:  math.Max(m.PerUnit*inst.QtyFloat(qty)+m.Percent*price*units, m.Minimum),
+		ExchangeFee: m.ExchangeFee * inst.QtyFloat(qty),
+	}
+	if side == OrderSell {
+		f.SECFee = m.SECFeeRate * price * units
+		f.TAF = m.TAFPerUnit * inst.QtyFloat(qty)
+		if m.TAFMax > 0 && f.TAF > m.TAFMax {
+			f.TAF = m.TAFMax
+		}
+	}
+	return f
+}
+
+//ExecutionCosts is summary of commission and slippage of the strategy in account currency. GrossPnL is PnL of
+//closed trades without slippage, NetPnL is PnL of closed trades minus commission.
+type ExecutionCosts struct {
+	Commission float64
+	Slippage   float64
+	GrossPnL   float64
+	NetPnL     float64
+}
+
+//attributeCosts adds commission and slippage of the fill to the trade. Costs of execution which reverses
+//position are split by qty between closed trade and the new one.
+func (b *BasicStrategy) attributeCosts(trade *Trade, newPos *Trade, prevPos int64, e *OrderFillEvent) {
+	if e.Commission == 0 && e.Slippage == 0 {
+		return
+	}
+	commission := trade.toAccount(e.Commission)
+	slippage := trade.toAccount(e.Slippage)
+	share := 1.0
+	if newPos != nil && e.Qty > 0 {
+		share = math.Min(1, math.Abs(float64(prevPos))/float64(e.Qty))
+		newPos.Commission += commission * (1 - share)
+		newPos.Slippage += slippage * (1 - share)
+	}
+	trade.Commission += commission * share
+	trade.Slippage += slippage * share
+}
+
+//ExecutionCosts returns commission and slippage of all trades of the strategy and their effect on PnL of closed
+//trades
+func (b *BasicStrategy) ExecutionCosts() ExecutionCosts {
+	var c ExecutionCosts
+	for _, t := range b.closedTrades {
+		c.Commission += t.Commission
+		c.Slippage += t.Slippage
+		c.GrossPnL += t.ClosedPnL + t.Slippage
+		c.NetPnL += t.ClosedPnL - t.Commission
+	}
+	for _, t := range b.trades() {
+		c.Commission += t.Commission
+		c.Slippage += t.Slippage
+	}
+	return c
+}
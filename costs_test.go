@@ -0,0 +1,90 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimBroker_SetCostModel(t *testing.T) {
+	b := SimBroker{}
+	assert.NotNil(t, b.SetCostModel(CostModel{PerUnit: -0.01}))
+	assert.Nil(t, b.SetCostModel(CostModel{PerUnit: 0.01}))
+	assert.Equal(t, 0.01, b.costs.PerUnit)
+}
+
+func TestSimBrokerWorker_applyCosts(t *testing.T) {
+	inst := &Instrument{Symbol: "Test", MinTick: 0.01, LotSize: 1}
+	w := simBrokerWorker{costs: CostModel{PerUnit: 0.01, Minimum: 1, SlippageTicks: 2}}
+
+	o := &simBrokerOrder{Order: &Order{Side: OrderBuy, Type: MarketOrder, Ticker: inst}}
+	e := OrderFillEvent{Price: 50, Qty: 50}
+	w.applyCosts(o, &e)
+	assert.InDelta(t, 50.02, e.Price, 0.0000001)
+	assert.InDelta(t, 1.0, e.Slippage, 0.0000001)
+	assert.Equal(t, 1.0, e.Commission)
+
+	//Limit orders don't slip
+	o = &simBrokerOrder{Order: &Order{Side: OrderSell, Type: LimitOrder, Ticker: inst}}
+	e = OrderFillEvent{Price: 50, Qty: 200}
+	w.applyCosts(o, &e)
+	assert.Equal(t, 50.0, e.Price)
+	assert.Equal(t, 0.0, e.Slippage)
+	assert.InDelta(t, 2.0, e.Commission, 0.0000001)
+
+	w.costs = CostModel{Percent: 0.001, SlippageTicks: 1}
+	o = &simBrokerOrder{Order: &Order{Side: OrderSell, Type: StopOrder, Ticker: inst}}
+	e = OrderFillEvent{Price: 50, Qty: 100}
+	w.applyCosts(o, &e)
+	assert.InDelta(t, 49.99, e.Price, 0.0000001)
+	assert.InDelta(t, 4.999, e.Commission, 0.0000001)
+
+	//Sells pay regulatory fees, TAF is capped
+	w.costs = CostModel{PerUnit: 0.01, ExchangeFee: 0.003, SECFeeRate: 0.0000278, TAFPerUnit: 0.000166, TAFMax: 0.01}
+	o = &simBrokerOrder{Order: &Order{Side: OrderSell, Type: LimitOrder, Ticker: inst}}
+	e = OrderFillEvent{Price: 50, Qty: 100}
+	w.applyCosts(o, &e)
+	assert.InDelta(t, 1.0, e.Fees.Commission, 0.0000001)
+	assert.InDelta(t, 0.3, e.Fees.ExchangeFee, 0.0000001)
+	assert.InDelta(t, 0.139, e.Fees.SECFee, 0.0000001)
+	assert.InDelta(t, 0.01, e.Fees.TAF, 0.0000001)
+	assert.InDelta(t, 1.449, e.Commission, 0.0000001)
+	o.Side = OrderBuy
+	e = OrderFillEvent{Price: 50, Qty: 100}
+	w.applyCosts(o, &e)
+	assert.Equal(t, 0.0, e.Fees.SECFee+e.Fees.TAF)
+	assert.InDelta(t, 1.3, e.Commission, 0.0000001)
+}
+
+func TestBasicStrategy_ExecutionCosts(t *testing.T) {
+	st := newTestMarginStrategy(10000)
+	st.symbol.InitialMargin, st.symbol.MaintenanceMargin = 0, 0
+	tm := st.mostRecentTime
+	fill := func(side OrderSide, qty int64, price float64, commission float64, slippage float64) {
+		id, err := st.NewMarketOrder(side, qty, DayTIF, "Sim")
+		assert.Nil(t, err)
+		st.proxyEvent(&OrderConfirmationEvent{BaseEvent: be(tm, st.symbol), OrdId: id})
+		st.proxyEvent(&OrderFillEvent{BaseEvent: be(tm, st.symbol), OrdId: id, Price: price, Qty: qty,
+			Commission: commission, Slippage: slippage})
+	}
+
+	fill(OrderBuy, 100, 50, 1, 2)
+	assert.Equal(t, ExecutionCosts{Commission: 1, Slippage: 2}, st.ExecutionCosts())
+	assert.InDelta(t, 4999.0, st.Cash(), 0.0000001)
+
+	//Commission of reversing execution is split by qty
+	fill(OrderSell, 300, 55, 3, 0)
+	closed := st.ClosedTrades()
+	assert.Len(t, closed, 1)
+	assert.InDelta(t, 2.0, closed[0].Commission, 0.0000001)
+	assert.InDelta(t, 2.0, closed[0].Slippage, 0.0000001)
+	assert.InDelta(t, 498.0, closed[0].NetPnL, 0.0000001)
+	assert.InDelta(t, 2.0, st.currentTrade.Commission, 0.0000001)
+
+	c := st.ExecutionCosts()
+	assert.InDelta(t, 4.0, c.Commission, 0.0000001)
+	assert.InDelta(t, 2.0, c.Slippage, 0.0000001)
+	assert.InDelta(t, 502.0, c.GrossPnL, 0.0000001)
+	assert.InDelta(t, 498.0, c.NetPnL, 0.0000001)
+	assert.InDelta(t, 21496.0, st.Cash(), 0.0000001)
+}
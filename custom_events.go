@@ -0,0 +1,69 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+//PublishCustomEvent puts custom event to the engine. With simulated broker event is delivered before the first
+//market data event which is not earlier than event time. Otherwise it's delivered with the next timer tick.
+//Event without time gets current time in live mode and is delivered with the next market data in backtest.
+func (c *Engine) PublishCustomEvent(e *CustomEvent) {
+	if e.Time.IsZero() && !c.broker.IsSimulated() {
+		e.Time = c.now()
+	}
+	c.mut.Lock()
+	c.customEvents = append(c.customEvents, e)
+	c.mut.Unlock()
+}
+
+//fireCustomEvents notifies strategies about custom events with time not later than now in time order
+func (c *Engine) fireCustomEvents(now time.Time) {
+	var due eventArray
+	c.mut.Lock()
+	if len(c.customEvents) > 0 {
+		sort.SliceStable(c.customEvents, func(i, j int) bool {
+			return eventBefore(c.customEvents[i], c.customEvents[j])
+		})
+		n := sort.Search(len(c.customEvents), func(i int) bool {
+			return c.customEvents[i].getTime().After(now)
+		})
+		due = c.customEvents[:n]
+		c.customEvents = append(eventArray{}, c.customEvents[n:]...)
+	}
+	c.mut.Unlock()
+
+	for _, e := range due {
+		c.deliverCustomEvent(e.(*CustomEvent))
+	}
+}
+
+func (c *Engine) deliverCustomEvent(e *CustomEvent) {
+	if e.Ticker != nil {
+		st, ok := c.findStrategy(e.Ticker.Symbol)
+		if !ok {
+			c.logError(fmt.Errorf("Custom event %v for symbol %v without strategy. ", e.Name, e.Ticker.Symbol))
+			return
+		}
+		st.notify(e)
+		return
+	}
+
+	c.mut.Lock()
+	symbols := make([]string, 0, len(c.strategiesMap))
+	for s := range c.strategiesMap {
+		symbols = append(symbols, s)
+	}
+	c.mut.Unlock()
+	sort.Strings(symbols)
+	for _, s := range symbols {
+		st, ok := c.findStrategy(s)
+		if !ok {
+			continue
+		}
+		copied := *e
+		copied.Ticker = st.getInstrument()
+		st.notify(&copied)
+	}
+}
@@ -0,0 +1,56 @@
+package engine
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+type customEventTestStrategy struct {
+	DummyStrategy
+	events []string
+}
+
+func (s *customEventTestStrategy) OnCustomEvent(b *BasicStrategy, e *CustomEvent) {
+	s.events = append(s.events, e.getSymbol()+" "+e.Name+" "+e.Payload.(string))
+}
+
+func TestEngine_fireCustomEvents(t *testing.T) {
+	us1 := &customEventTestStrategy{}
+	st1 := NewBasicStrategy(&Instrument{Symbol: "A"}, 1, us1)
+	us2 := &customEventTestStrategy{}
+	st2 := NewBasicStrategy(&Instrument{Symbol: "B"}, 1, us2)
+	for _, st := range []*BasicStrategy{st1, st2} {
+		st.init(CoreStrategyChannels{errors: make(chan error), events: make(chan event, 1),
+			portfolio: make(chan *PortfolioNewPositionEvent, 1)})
+	}
+	c := Engine{strategiesMap: map[string]ICoreStrategy{"A": st1, "B": st2}, mut: &sync.Mutex{}}
+
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	c.PublishCustomEvent(&CustomEvent{BaseEvent: be(tm.Add(time.Minute), st2.symbol), Name: "signal", Payload: "2"})
+	c.PublishCustomEvent(&CustomEvent{BaseEvent: be(tm, nil), Name: "signal", Payload: "1"})
+
+	c.fireCustomEvents(tm.Add(-time.Second))
+	st1.handlersWaitGroup.Wait()
+	st2.handlersWaitGroup.Wait()
+	assert.Len(t, us1.events, 0)
+
+	c.fireCustomEvents(tm)
+	st1.handlersWaitGroup.Wait()
+	st2.handlersWaitGroup.Wait()
+	assert.Equal(t, []string{"A signal 1"}, us1.events)
+	assert.Equal(t, []string{"B signal 1"}, us2.events)
+	assert.Len(t, c.customEvents, 1)
+
+	//Strategy publishes event through engine events
+	st1.mostRecentTime = tm.Add(30 * time.Second)
+	st1.PublishCustomEvent("model", "3", st2.symbol)
+	c.proxyEvent(<-st1.ch.events)
+	c.fireCustomEvents(tm.Add(time.Hour))
+	st1.shutDown()
+	st2.shutDown()
+	assert.Equal(t, []string{"A signal 1"}, us1.events)
+	assert.Equal(t, []string{"B signal 1", "B model 3", "B signal 2"}, us2.events)
+	assert.Len(t, c.customEvents, 0)
+}
@@ -0,0 +1,362 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+func getJSON(client *http.Client, u string, v interface{}) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Get(u)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &ErrProviderResponse{URL: u, StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+//*************** Polygon ***************************************************
+
+//PolygonProvider downloads trades and aggregates from polygon.io REST API
+type PolygonProvider struct {
+	APIKey  string
+	BaseURL string
+	Client  *http.Client
+}
+
+func NewPolygonProvider(apiKey string) *PolygonProvider {
+	return &PolygonProvider{APIKey: apiKey, BaseURL: "https://api.polygon.io"}
+}
+
+func (p *PolygonProvider) Name() string {
+	return "Polygon"
+}
+
+type polygonTradesResponse struct {
+	Results []struct {
+		SipTimestamp int64   `json:"sip_timestamp"`
+		Price        float64 `json:"price"`
+		Size         float64 `json:"size"`
+		Exchange     int     `json:"exchange"`
+	} `json:"results"`
+	NextURL string `json:"next_url"`
+}
+
+func (p *PolygonProvider) DownloadTicks(symbol string, dRange marketdata.DateRange) (marketdata.TickArray, error) {
+	q := url.Values{}
+	q.Set("timestamp.gte", strconv.FormatInt(dRange.From.UnixNano(), 10))
+	q.Set("timestamp.lte", strconv.FormatInt(dRange.To.UnixNano(), 10))
+	q.Set("limit", "50000")
+	q.Set("order", "asc")
+	u := fmt.Sprintf("%v/v3/trades/%v?%v", p.BaseURL, url.PathEscape(symbol), q.Encode())
+
+	var ticks marketdata.TickArray
+	for u != "" {
+		var resp polygonTradesResponse
+		if err := getJSON(p.Client, u+"&apiKey="+url.QueryEscape(p.APIKey), &resp); err != nil {
+			return nil, err
+		}
+		for _, r := range resp.Results {
+			ticks = append(ticks, &marketdata.Tick{
+				Datetime:  time.Unix(0, r.SipTimestamp).UTC(),
+				Symbol:    symbol,
+				LastPrice: r.Price,
+				LastSize:  int64(r.Size),
+				LastExch:  strconv.Itoa(r.Exchange),
+			})
+		}
+		u = resp.NextURL
+	}
+	return ticks, nil
+}
+
+type polygonAggsResponse struct {
+	Results []struct {
+		T int64   `json:"t"`
+		O float64 `json:"o"`
+		H float64 `json:"h"`
+		L float64 `json:"l"`
+		C float64 `json:"c"`
+		V float64 `json:"v"`
+	} `json:"results"`
+	NextURL string `json:"next_url"`
+}
+
+func (p *PolygonProvider) DownloadCandles(symbol string, timeFrame string, dRange marketdata.DateRange) (marketdata.CandleArray, error) {
+	multiplier, timespan := "1", ""
+	switch timeFrame {
+	case "D":
+		timespan = "day"
+	case "W":
+		timespan = "week"
+	default:
+		if _, err := timeFrameDuration(timeFrame); err != nil {
+			return nil, err
+		}
+		multiplier, timespan = timeFrame, "minute"
+	}
+	u := fmt.Sprintf("%v/v2/aggs/ticker/%v/range/%v/%v/%v/%v?adjusted=false&sort=asc&limit=50000",
+		p.BaseURL, url.PathEscape(symbol), multiplier, timespan, dRange.From.Format("2006-01-02"), dRange.To.Format("2006-01-02"))
+
+	var candles marketdata.CandleArray
+	for u != "" {
+		var resp polygonAggsResponse
+		if err := getJSON(p.Client, u+"&apiKey="+url.QueryEscape(p.APIKey), &resp); err != nil {
+			return nil, err
+		}
+		for _, r := range resp.Results {
+			candles = append(candles, &marketdata.Candle{
+				Datetime: time.Unix(0, r.T*int64(time.Millisecond)).UTC(),
+				Symbol:   symbol,
+				Open:     r.O,
+				High:     r.H,
+				Low:      r.L,
+				Close:    r.C,
+				AdjClose: r.C,
+				Volume:   int64(r.V),
+			})
+		}
+		u = resp.NextURL
+	}
+	return candles, nil
+}
+
+//*************** Tiingo ****************************************************
+
+//TiingoProvider downloads end of day prices and IEX intraday bars from Tiingo. Tiingo doesn't provide ticks.
+type TiingoProvider struct {
+	Token   string
+	BaseURL string
+	Client  *http.Client
+}
+
+func NewTiingoProvider(token string) *TiingoProvider {
+	return &TiingoProvider{Token: token, BaseURL: "https://api.tiingo.com"}
+}
+
+func (p *TiingoProvider) Name() string {
+	return "Tiingo"
+}
+
+func (p *TiingoProvider) DownloadTicks(symbol string, dRange marketdata.DateRange) (marketdata.TickArray, error) {
+	return nil, &ErrProviderNotSupported{Provider: p.Name(), Message: "ticks"}
+}
+
+type tiingoPrice struct {
+	Date     time.Time `json:"date"`
+	Open     float64   `json:"open"`
+	High     float64   `json:"high"`
+	Low      float64   `json:"low"`
+	Close    float64   `json:"close"`
+	AdjClose float64   `json:"adjClose"`
+	Volume   float64   `json:"volume"`
+}
+
+func (p *TiingoProvider) DownloadCandles(symbol string, timeFrame string, dRange marketdata.DateRange) (marketdata.CandleArray, error) {
+	q := url.Values{}
+	q.Set("startDate", dRange.From.Format("2006-01-02"))
+	q.Set("endDate", dRange.To.Format("2006-01-02"))
+	q.Set("token", p.Token)
+
+	var u string
+	switch timeFrame {
+	case "D", "W":
+		if timeFrame == "W" {
+			q.Set("resampleFreq", "weekly")
+		}
+		u = fmt.Sprintf("%v/tiingo/daily/%v/prices?%v", p.BaseURL, url.PathEscape(symbol), q.Encode())
+	default:
+		if _, err := timeFrameDuration(timeFrame); err != nil {
+			return nil, err
+		}
+		q.Set("resampleFreq", timeFrame+"min")
+		u = fmt.Sprintf("%v/iex/%v/prices?%v", p.BaseURL, url.PathEscape(symbol), q.Encode())
+	}
+
+	var prices []tiingoPrice
+	if err := getJSON(p.Client, u, &prices); err != nil {
+		return nil, err
+	}
+
+	var candles marketdata.CandleArray
+	for _, r := range prices {
+		adjClose := r.AdjClose
+		if adjClose == 0 {
+			adjClose = r.Close
+		}
+		candles = append(candles, &marketdata.Candle{
+			Datetime: r.Date.UTC(),
+			Symbol:   symbol,
+			Open:     r.Open,
+			High:     r.High,
+			Low:      r.Low,
+			Close:    r.Close,
+			AdjClose: adjClose,
+			Volume:   int64(r.Volume),
+		})
+	}
+	return candles, nil
+}
+
+//*************** Binance ***************************************************
+
+//BinanceProvider downloads aggregated trades and klines from Binance public API. Fractional volumes
+//are truncated to integers.
+type BinanceProvider struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+func NewBinanceProvider() *BinanceProvider {
+	return &BinanceProvider{BaseURL: "https://api.binance.com"}
+}
+
+func (p *BinanceProvider) Name() string {
+	return "Binance"
+}
+
+const binanceLimit = 1000
+
+type binanceAggTrade struct {
+	ID    int64  `json:"a"`
+	Price string `json:"p"`
+	Qty   string `json:"q"`
+	Time  int64  `json:"T"`
+}
+
+func binanceMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func (p *BinanceProvider) DownloadTicks(symbol string, dRange marketdata.DateRange) (marketdata.TickArray, error) {
+	var ticks marketdata.TickArray
+	//Binance allows at most one hour between start and end time of aggregated trades request
+	for from := dRange.From; from.Before(dRange.To); from = from.Add(time.Hour) {
+		to := from.Add(time.Hour - time.Millisecond)
+		if to.After(dRange.To) {
+			to = dRange.To
+		}
+		start := binanceMillis(from)
+		for {
+			u := fmt.Sprintf("%v/api/v3/aggTrades?symbol=%v&startTime=%v&endTime=%v&limit=%v",
+				p.BaseURL, url.QueryEscape(symbol), start, binanceMillis(to), binanceLimit)
+			var trades []binanceAggTrade
+			if err := getJSON(p.Client, u, &trades); err != nil {
+				return nil, err
+			}
+			for _, tr := range trades {
+				price, err := strconv.ParseFloat(tr.Price, 64)
+				if err != nil {
+					return nil, err
+				}
+				qty, err := strconv.ParseFloat(tr.Qty, 64)
+				if err != nil {
+					return nil, err
+				}
+				ticks = append(ticks, &marketdata.Tick{
+					Datetime:  time.Unix(0, tr.Time*int64(time.Millisecond)).UTC(),
+					Symbol:    symbol,
+					LastPrice: price,
+					LastSize:  int64(qty),
+				})
+			}
+			if len(trades) < binanceLimit {
+				break
+			}
+			start = trades[len(trades)-1].Time + 1
+		}
+	}
+	return ticks, nil
+}
+
+func binanceInterval(timeFrame string) (string, error) {
+	switch timeFrame {
+	case "D":
+		return "1d", nil
+	case "W":
+		return "1w", nil
+	case "1", "3", "5", "15", "30":
+		return timeFrame + "m", nil
+	case "60", "120", "240", "360", "480", "720":
+		d, _ := timeFrameDuration(timeFrame)
+		return strconv.Itoa(int(d.Hours())) + "h", nil
+	default:
+		return "", &ErrProviderNotSupported{Provider: "Binance", Message: "timeframe " + timeFrame}
+	}
+}
+
+func (p *BinanceProvider) DownloadCandles(symbol string, timeFrame string, dRange marketdata.DateRange) (marketdata.CandleArray, error) {
+	interval, err := binanceInterval(timeFrame)
+	if err != nil {
+		return nil, err
+	}
+
+	var candles marketdata.CandleArray
+	start := binanceMillis(dRange.From)
+	end := binanceMillis(dRange.To)
+	for start <= end {
+		u := fmt.Sprintf("%v/api/v3/klines?symbol=%v&interval=%v&startTime=%v&endTime=%v&limit=%v",
+			p.BaseURL, url.QueryEscape(symbol), interval, start, end, binanceLimit)
+		var klines [][]interface{}
+		if err := getJSON(p.Client, u, &klines); err != nil {
+			return nil, err
+		}
+		for _, k := range klines {
+			c, err := parseBinanceKline(symbol, k)
+			if err != nil {
+				return nil, err
+			}
+			candles = append(candles, c)
+		}
+		if len(klines) < binanceLimit {
+			break
+		}
+		start = binanceMillis(candles[len(candles)-1].Datetime) + 1
+	}
+	return candles, nil
+}
+
+//parseBinanceKline parses kline array: open time, open, high, low, close, volume, ...
+func parseBinanceKline(symbol string, k []interface{}) (*marketdata.Candle, error) {
+	if len(k) < 6 {
+		return nil, fmt.Errorf("Unexpected kline format: %v", k)
+	}
+	openTime, ok := k[0].(float64)
+	if !ok {
+		return nil, fmt.Errorf("Unexpected kline open time: %v", k[0])
+	}
+	var values [5]float64
+	for i := range values {
+		s, ok := k[i+1].(string)
+		if !ok {
+			return nil, fmt.Errorf("Unexpected kline value: %v", k[i+1])
+		}
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	c := marketdata.Candle{
+		Datetime: time.Unix(0, int64(openTime)*int64(time.Millisecond)).UTC(),
+		Symbol:   symbol,
+		Open:     values[0],
+		High:     values[1],
+		Low:      values[2],
+		Close:    values[3],
+		AdjClose: values[3],
+		Volume:   int64(values[4]),
+	}
+	return &c, nil
+}
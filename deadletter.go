@@ -0,0 +1,195 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+//IDeliveryBroker can be implemented by broker which knows if strategy request was delivered, e.g. live broker
+//with network connection. Engine sends new order, cancel and replace requests with Deliver instead of Notify
+//and retries them on error.
+type IDeliveryBroker interface {
+	Deliver(e event) error
+}
+
+//RetryPolicy sets how engine retries requests which broker failed to deliver. Delay before the next attempt is
+//multiplied by Multiplier after every attempt, but it's not longer than MaxBackoff.
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+	Multiplier  float64
+}
+
+var defaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	Backoff:     100 * time.Millisecond,
+	MaxBackoff:  5 * time.Second,
+	Multiplier:  2,
+}
+
+func (p RetryPolicy) next(delay time.Duration) time.Duration {
+	delay = time.Duration(float64(delay) * p.Multiplier)
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	return delay
+}
+
+//DeadLetter is request which wasn't delivered to broker after all attempts
+type DeadLetter struct {
+	Request  event
+	Symbol   string
+	OrdId    string
+	Err      error
+	Attempts int
+	Time     time.Time
+}
+
+//deadLetterState is retry policy and queue of undelivered requests
+type deadLetterState struct {
+	policy  *RetryPolicy
+	letters []DeadLetter
+}
+
+//SetDeliveryRetry sets retry policy of requests to broker which implements IDeliveryBroker. Requests which
+//are not delivered after MaxAttempts are put to dead letter queue and strategy gets
+//StrategyRequestNotDeliveredEvent, so its order is rejected and it doesn't wait for the answer forever.
+func (c *Engine) SetDeliveryRetry(policy RetryPolicy) error {
+	if policy.MaxAttempts < 1 {
+		return errors.New("Retry policy should have at least one attempt. ")
+	}
+	if policy.Backoff < 0 || policy.MaxBackoff < 0 || policy.Multiplier < 1 {
+		return errors.New("Retry backoff can't be negative and multiplier can't be less than 1. ")
+	}
+	c.mut.Lock()
+	c.deadLetters.policy = &policy
+	c.mut.Unlock()
+	return nil
+}
+
+//DeadLetters returns requests which weren't delivered to broker
+func (c *Engine) DeadLetters() []DeadLetter {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return append([]DeadLetter{}, c.deadLetters.letters...)
+}
+
+func (c *Engine) retryPolicy() RetryPolicy {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	if c.deadLetters.policy == nil {
+		return defaultRetryPolicy
+	}
+	return *c.deadLetters.policy
+}
+
+//deliverRequest passes strategy request to broker. Failed requests are retried in background, so market
+//data and other requests are not delayed. Requests before broker is initialized or after the run are not
+//delivered.
+func (c *Engine) deliverRequest(e event) {
+	if !c.canNotifyBroker() {
+		c.deadLetter(e, fmt.Errorf("Engine is %v. ", c.State()), 0)
+		return
+	}
+	d, ok := c.broker.(IDeliveryBroker)
+	if !ok {
+		c.broker.Notify(e)
+		return
+	}
+	err := d.Deliver(e)
+	if err == nil {
+		return
+	}
+	policy := c.retryPolicy()
+	c.waitG.Add(1)
+	go func() {
+		defer c.waitG.Done()
+		attempts := 1
+		delay := policy.Backoff
+	Retry:
+		for attempts < policy.MaxAttempts {
+			select {
+			case <-time.After(delay):
+			case <-doneChan(c.ctx):
+				break Retry
+			}
+			attempts++
+			if err = d.Deliver(e); err == nil {
+				return
+			}
+			delay = policy.next(delay)
+		}
+		c.deadLetter(e, err, attempts)
+	}()
+}
+
+//deadLetter puts request to dead letter queue and tells strategy that it wasn't delivered
+func (c *Engine) deadLetter(e event, err error, attempts int) {
+	letter := DeadLetter{
+		Request:  e,
+		Symbol:   e.getSymbol(),
+		OrdId:    requestOrderID(e),
+		Err:      err,
+		Attempts: attempts,
+		Time:     c.now(),
+	}
+	c.mut.Lock()
+	c.deadLetters.letters = append(c.deadLetters.letters, letter)
+	c.mut.Unlock()
+	c.printError(fmt.Errorf("Request %v of %v is not delivered after %v attempts: %v", letter.OrdId,
+		letter.Symbol, attempts, err))
+
+	st, ok := c.findStrategy(letter.Symbol)
+	if !ok {
+		return
+	}
+	reason := "Not delivered"
+	if err != nil {
+		reason = err.Error()
+	}
+	notDelivered := &StrategyRequestNotDeliveredEvent{
+		BaseEvent: be(letter.Time, st.getInstrument()),
+		Request:   e,
+		Reason:    reason,
+	}
+	notDelivered.CorrelationID = correlationIDOf(e)
+	c.trackOrder(notDelivered)
+	st.notify(notDelivered)
+}
+
+//requestOrderID returns ID of the order strategy request is about
+func requestOrderID(e event) string {
+	switch r := e.(type) {
+	case *NewOrderEvent:
+		if r.LinkedOrder != nil {
+			return r.LinkedOrder.Id
+		}
+	case *OrderCancelRequestEvent:
+		return r.OrdId
+	case *OrderReplaceRequestEvent:
+		return r.OrdId
+	}
+	return ""
+}
+
+//onStrategyRequestNotDeliveredEventHandler handles undelivered request as rejected by broker, so order state
+//and counters of requests waiting for answer are updated
+func (b *BasicStrategy) onStrategyRequestNotDeliveredEventHandler(e *StrategyRequestNotDeliveredEvent) {
+	if e.Request == nil {
+		b.newError(errors.New("onStrategyRequestNotDeliveredEventHandler got event with nil Request field"))
+		return
+	}
+	reason := "Request is not delivered: " + e.Reason
+	switch r := e.Request.(type) {
+	case *NewOrderEvent:
+		b.onOrderRejectedHandler(&OrderRejectedEvent{BaseEvent: e.BaseEvent, OrdId: requestOrderID(r), Reason: reason})
+	case *OrderCancelRequestEvent:
+		b.onOrderCancelRejectHandler(&OrderCancelRejectEvent{BaseEvent: e.BaseEvent, OrdId: r.OrdId, Reason: reason})
+	case *OrderReplaceRequestEvent:
+		b.onOrderReplaceRejectHandler(&OrderReplaceRejectEvent{BaseEvent: e.BaseEvent, OrdId: r.OrdId, Reason: reason})
+	default:
+		b.anomaly(e, "Undelivered request of unknown type. ")
+	}
+}
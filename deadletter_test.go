@@ -0,0 +1,102 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//failingDeliveryBroker fails to deliver the first failures requests
+type failingDeliveryBroker struct {
+	SimBroker
+	failures  int
+	attempts  int
+	delivered []event
+}
+
+func (b *failingDeliveryBroker) Deliver(e event) error {
+	b.attempts++
+	if b.attempts <= b.failures {
+		return errors.New("Connection lost")
+	}
+	b.delivered = append(b.delivered, e)
+	return nil
+}
+
+func newTestDeadLetterEngine(broker IBroker) (*Engine, *BasicStrategy) {
+	st := newTestBasicStrategy()
+	st.ch.events = make(chan event, 10)
+	st.mostRecentTime = time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	c := &Engine{broker: broker, strategiesMap: map[string]ICoreStrategy{st.symbol.Symbol: st},
+		mut: &sync.Mutex{}, waitG: &sync.WaitGroup{}, clock: NewSimClock(st.mostRecentTime)}
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	c.log.SetOutput(ioutil.Discard)
+	return c, st
+}
+
+func TestEngine_deliverRequestDeadLetter(t *testing.T) {
+	broker := &failingDeliveryBroker{failures: 10}
+	c, st := newTestDeadLetterEngine(broker)
+	assert.Nil(t, c.SetDeliveryRetry(RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, Multiplier: 2}))
+
+	id, err := st.NewLimitOrder(10, OrderBuy, 100, GTCTIF, "Sim")
+	assert.Nil(t, err)
+	assert.Equal(t, int32(1), st.waitingN)
+
+	c.deliverRequest(<-st.ch.events)
+	c.waitG.Wait()
+	st.handlersWaitGroup.Wait()
+
+	assert.Equal(t, 3, broker.attempts)
+	letters := c.DeadLetters()
+	if assert.Len(t, letters, 1) {
+		assert.Equal(t, id, letters[0].OrdId)
+		assert.Equal(t, "Test", letters[0].Symbol)
+		assert.Equal(t, 3, letters[0].Attempts)
+		assert.EqualError(t, letters[0].Err, "Connection lost")
+	}
+
+	//Strategy doesn't wait for the answer anymore
+	assert.Equal(t, int32(0), st.waitingN)
+	info, err := st.OrderStatus(id)
+	assert.Nil(t, err)
+	assert.Equal(t, RejectedOrder, info.State)
+	assert.Contains(t, info.RejectReason, "Connection lost")
+}
+
+func TestEngine_deliverRequestRetry(t *testing.T) {
+	broker := &failingDeliveryBroker{failures: 1}
+	c, st := newTestDeadLetterEngine(broker)
+	assert.Nil(t, c.SetDeliveryRetry(RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, Multiplier: 1}))
+
+	_, err := st.NewLimitOrder(10, OrderBuy, 100, GTCTIF, "Sim")
+	assert.Nil(t, err)
+	c.deliverRequest(<-st.ch.events)
+	c.waitG.Wait()
+
+	assert.Equal(t, 2, broker.attempts)
+	assert.Len(t, broker.delivered, 1)
+	assert.Len(t, c.DeadLetters(), 0)
+	assert.Equal(t, int32(1), st.waitingN)
+}
+
+func TestEngine_SetDeliveryRetry(t *testing.T) {
+	c := Engine{mut: &sync.Mutex{}}
+	assert.Equal(t, defaultRetryPolicy, c.retryPolicy())
+	assert.NotNil(t, c.SetDeliveryRetry(RetryPolicy{MaxAttempts: 0}))
+	assert.NotNil(t, c.SetDeliveryRetry(RetryPolicy{MaxAttempts: 2, Backoff: -time.Second, Multiplier: 2}))
+	assert.NotNil(t, c.SetDeliveryRetry(RetryPolicy{MaxAttempts: 2, Multiplier: 0.5}))
+	assert.Nil(t, c.SetDeliveryRetry(RetryPolicy{MaxAttempts: 2, Multiplier: 1}))
+	assert.Equal(t, 2, c.retryPolicy().MaxAttempts)
+}
+
+func TestRetryPolicy_next(t *testing.T) {
+	p := RetryPolicy{Multiplier: 3, MaxBackoff: time.Second}
+	assert.Equal(t, 300*time.Millisecond, p.next(100*time.Millisecond))
+	assert.Equal(t, time.Second, p.next(900*time.Millisecond))
+}
@@ -0,0 +1,157 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+//delistingDestination is destination of orders which close positions at delisting
+const delistingDestination = "Delisting"
+
+//Delisting is the first date symbol isn't traded and price open positions are settled at. Bankrupt symbol
+//should have minimal positive price.
+type Delisting struct {
+	Date  time.Time
+	Price float64
+}
+
+//IDelistingStorage can be implemented by market data storage of survivorship-bias-free data. BTM gets delisting
+//of every symbol from it, nil is returned for symbol which is still traded.
+type IDelistingStorage interface {
+	GetDelisting(symbol *Instrument) (*Delisting, error)
+}
+
+//IDelistingUserStrategy can be implemented by user strategy to be notified about delisting of its symbol.
+//Position is already closed at delisting price when it's called.
+type IDelistingUserStrategy interface {
+	OnDelisting(b *BasicStrategy, e *DelistingEvent)
+}
+
+//SetDelisting sets delisting of the symbol. It replaces delisting from storage. BTM doesn't send data of the
+//symbol from delisting date, DelistingEvent is sent instead.
+func (m *BTM) SetDelisting(symbol string, d Delisting) error {
+	if math.IsNaN(d.Price) || d.Price <= 0 {
+		return fmt.Errorf("Delisting price of %v should be positive. ", symbol)
+	}
+	if d.Date.IsZero() {
+		return fmt.Errorf("Delisting date of %v is not set. ", symbol)
+	}
+	found := false
+	for _, s := range m.Symbols {
+		if s.Symbol == symbol {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("Symbol %v is not in market data symbols. ", symbol)
+	}
+	if m.delistings == nil {
+		m.delistings = make(map[string]Delisting)
+	}
+	m.delistings[symbol] = d
+	return nil
+}
+
+//loadDelistings returns delisting events of symbols delisted within the period and keeps delisting dates of all
+//delisted symbols, so their data after delisting is not sent
+func (m *BTM) loadDelistings() eventArray {
+	m.delistedFrom = make(map[string]time.Time)
+	s, hasStorage := m.Storage.(IDelistingStorage)
+	var events eventArray
+	for _, inst := range m.Symbols {
+		d, ok := m.delistings[inst.Symbol]
+		if !ok && hasStorage {
+			stored, err := s.GetDelisting(inst)
+			if err != nil {
+				m.newError(err)
+				continue
+			}
+			if stored == nil {
+				continue
+			}
+			if stored.Price <= 0 || math.IsNaN(stored.Price) {
+				m.newError(fmt.Errorf("Delisting price of %v should be positive. ", inst.Symbol))
+				continue
+			}
+			d, ok = *stored, true
+		}
+		if !ok {
+			continue
+		}
+		m.delistedFrom[inst.Symbol] = d.Date
+		if d.Date.Before(m.FromDate) || d.Date.After(m.ToDate.AddDate(0, 0, 1)) {
+			continue
+		}
+		events = append(events, &DelistingEvent{BaseEvent: be(d.Date, inst), Price: d.Price})
+	}
+	return events
+}
+
+//isDelisted returns true for data of the symbol at or after its delisting date
+func (m *BTM) isDelisted(e event) bool {
+	if _, ok := e.(*DelistingEvent); ok {
+		return false
+	}
+	from, ok := m.delistedFrom[e.getSymbol()]
+	return ok && !e.getTime().Before(from)
+}
+
+func (c *Engine) eDelisting(e *DelistingEvent) {
+	c.portfolio.onPrice(e.getSymbol(), e.Price)
+	if c.broker.IsSimulated() {
+		c.broker.Notify(e)
+	}
+	if st, ok := c.findStrategy(e.getSymbol()); ok {
+		st.notify(e)
+	}
+}
+
+//onDelistingHandler closes open trades at delisting price. Fills are made by strategy, because there is no
+//market for the symbol anymore. Broker cancels orders of the symbol and rejects new ones.
+func (b *BasicStrategy) onDelistingHandler(e *DelistingEvent) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	if e.getTime().After(b.mostRecentTime) {
+		b.mostRecentTime = e.getTime()
+	}
+	b.delisted = true
+	for i, t := range b.trades() {
+		pos := t.position()
+		if pos == 0 {
+			continue
+		}
+		side := OrderSell
+		if pos < 0 {
+			side = OrderBuy
+			pos = -pos
+		}
+		o := &Order{
+			Id:           fmt.Sprintf("%vDLS%v", b.orderIDPrefix(), i),
+			Ticker:       b.symbol,
+			Side:         side,
+			Qty:          pos,
+			Type:         MarketOrder,
+			Tif:          DayTIF,
+			Destination:  delistingDestination,
+			Price:        math.NaN(),
+			ExecPrice:    math.NaN(),
+			State:        ConfirmedOrder,
+			Time:         e.getTime(),
+			PositionSide: t.Direction,
+		}
+		t.ConfirmedOrders[o.Id] = o
+		t.AllOrdersIDMap[o.Id] = struct{}{}
+		b.applyFill(&OrderFillEvent{OrdId: o.Id, Qty: pos, Price: e.Price, BaseEvent: be(e.getTime(), b.symbol)})
+	}
+	if us, ok := b.userStrategy.(IDelistingUserStrategy); ok {
+		b.callUserStrategy("OnDelisting", func() { us.OnDelisting(b, e) })
+	}
+}
+
+//IsDelisted returns true after symbol of the strategy was delisted
+func (b *BasicStrategy) IsDelisted() bool {
+	return b.delisted
+}
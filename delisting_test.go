@@ -0,0 +1,112 @@
+package engine
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBTM_loadDelistings(t *testing.T) {
+	dir, err := ioutil.TempDir("", "delisting")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	md := newTestBTMforSyntheticTicks(dir, 1)
+	date := time.Date(2018, 3, 7, 0, 0, 0, 0, time.UTC)
+
+	assert.NotNil(t, md.SetDelisting("Sym1", Delisting{Date: date}))
+	assert.NotNil(t, md.SetDelisting("Sym1", Delisting{Price: 5}))
+	assert.NotNil(t, md.SetDelisting("Other", Delisting{Date: date, Price: 5}))
+	assert.Nil(t, md.SetDelisting("Sym1", Delisting{Date: date, Price: 5}))
+	assert.Nil(t, md.SetDelisting("Sym2", Delisting{Date: date.AddDate(1, 0, 0), Price: 5}))
+
+	//Only delisting within the period is sent, data after every delisting date is skipped
+	events := md.loadDelistings()
+	if assert.Len(t, events, 1) {
+		e := events[0].(*DelistingEvent)
+		assert.Equal(t, "Sym1", e.getSymbol())
+		assert.Equal(t, date, e.getTime())
+		assert.Equal(t, 5.0, e.Price)
+		assert.False(t, md.isDelisted(e))
+	}
+	sym1, sym3 := md.Symbols[0], md.Symbols[2]
+	assert.False(t, md.isDelisted(&NewTickEvent{BaseEvent: be(date.Add(-time.Minute), sym1)}))
+	assert.True(t, md.isDelisted(&NewTickEvent{BaseEvent: be(date, sym1)}))
+	assert.False(t, md.isDelisted(&NewTickEvent{BaseEvent: be(date, sym3)}))
+}
+
+func TestBasicStrategy_onDelistingHandler(t *testing.T) {
+	st := newTestMarginStrategy(10000)
+	fillTestOrder(st, OrderBuy, 100, 50)
+	tm := st.mostRecentTime.Add(time.Hour)
+
+	st.notify(&DelistingEvent{BaseEvent: be(tm, st.symbol), Price: 40})
+	st.shutDown()
+	assert.True(t, st.IsDelisted())
+	assert.Equal(t, int64(0), st.Position())
+	if assert.Len(t, st.closedTrades, 1) {
+		assert.InDelta(t, -1000.0, st.closedTrades[0].ClosedPnL, 0.0000001)
+		assert.Equal(t, tm, st.closedTrades[0].CloseTime)
+	}
+	assert.InDelta(t, 9000.0, st.Cash(), 0.0000001)
+
+	_, err := st.NewMarketOrder(OrderBuy, 100, DayTIF, "Sim")
+	assert.NotNil(t, err)
+}
+
+//delistingTestStrategy buys on the first tick and keeps time of the last one
+type delistingTestStrategy struct {
+	DummyStrategy
+	lastTick  time.Time
+	delisting *DelistingEvent
+	orderErr  error
+}
+
+func (s *delistingTestStrategy) OnTick(b *BasicStrategy, tick *Tick) {
+	if s.lastTick.IsZero() {
+		if _, err := b.NewMarketOrder(OrderSell, 10, DayTIF, "Sim"); err != nil {
+			panic(err)
+		}
+	}
+	s.lastTick = tick.Datetime
+}
+
+func (s *delistingTestStrategy) OnDelisting(b *BasicStrategy, e *DelistingEvent) {
+	s.delisting = e
+	_, s.orderErr = b.NewMarketOrder(OrderBuy, 10, DayTIF, "Sim")
+}
+
+func TestEngine_Delisting(t *testing.T) {
+	dir, err := ioutil.TempDir("", "delisting")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	md := newTestBTMforSyntheticTicks(dir, 1)
+	date := time.Date(2018, 3, 7, 0, 0, 0, 0, time.UTC)
+	assert.Nil(t, md.SetDelisting("Sym1", Delisting{Date: date, Price: 0.5}))
+	st := &delistingTestStrategy{}
+	e, err := New(WithMarketData(md), WithBroker(&SimBroker{}), WithStrategy(&Instrument{Symbol: "Sym1"}, st, 1))
+	assert.Nil(t, err)
+	e.SetDeterministic(true)
+	e.Run()
+	assert.Nil(t, e.Status().Err)
+
+	assert.False(t, st.lastTick.IsZero())
+	assert.True(t, st.lastTick.Before(date))
+	if !assert.NotNil(t, st.delisting) {
+		return
+	}
+	assert.Equal(t, date, st.delisting.getTime())
+	assert.NotNil(t, st.orderErr)
+
+	//Short position is covered at delisting price
+	b := e.strategiesMap["Sym1"].(*BasicStrategy)
+	assert.Equal(t, int64(0), b.Position())
+	if assert.Len(t, b.closedTrades, 1) {
+		tr := b.closedTrades[0]
+		assert.Equal(t, ShortTrade, tr.Direction)
+		assert.Equal(t, date, tr.CloseTime)
+		assert.InDelta(t, (tr.OpenPrice-0.5)*10, tr.ClosedPnL, 0.0000001)
+	}
+}
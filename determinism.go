@@ -0,0 +1,246 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//DeterminismReport is result of the determinism check: manifest, number of journal events and fills of the
+//first run
+type DeterminismReport struct {
+	Runs     int
+	Manifest RunManifest
+	Events   int
+	Fills    []RunFill
+}
+
+//TestReporter is part of testing.TB used by AssertDeterministic, so the check can be used from tests of other
+//packages
+type TestReporter interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+//CheckDeterminism builds and runs engine the given number of times in deterministic mode and checks that every
+//run has the same manifest, the same journal of events and the same fills as the first one. Build should create
+//new engine with new market data, broker and strategies every time. Events are compared as JSON without
+//sequence numbers, random order ids are compared by order of their appearance and events with wall clock
+//times are skipped. Error describes the first difference.
+func CheckDeterminism(build func() (*Engine, error), runs int) (DeterminismReport, error) {
+	if runs < 2 {
+		return DeterminismReport{}, errors.New("At least two runs are needed to check determinism. ")
+	}
+	r, err := checkRuns(build, runs, true)
+	return DeterminismReport{Runs: runs, Manifest: r.manifest, Events: len(r.journal), Fills: r.fills}, err
+}
+
+//AssertDeterministic reports error to the test if runs of the engine made by build aren't identical
+func AssertDeterministic(t TestReporter, runs int, build func() (*Engine, error)) bool {
+	t.Helper()
+	if _, err := CheckDeterminism(build, runs); err != nil {
+		t.Errorf("Run isn't deterministic: %v", err)
+		return false
+	}
+	return true
+}
+
+//recordedRun is manifest, fills and, if it was recorded, normalized journal of the run
+type recordedRun struct {
+	manifest RunManifest
+	fills    []RunFill
+	journal  []string
+}
+
+//checkRuns runs engine the given number of times and compares every run with the first one, which is returned
+func checkRuns(build func() (*Engine, error), runs int, journal bool) (recordedRun, error) {
+	var first recordedRun
+	for i := 0; i < runs; i++ {
+		r, err := runRecorded(build, journal)
+		if err != nil {
+			return first, err
+		}
+		if i == 0 {
+			first = r
+			continue
+		}
+		if err := compareRuns(first, r); err != nil {
+			return first, fmt.Errorf("Run %v: %v", i+1, err)
+		}
+	}
+	return first, nil
+}
+
+func runRecorded(build func() (*Engine, error), journal bool) (recordedRun, error) {
+	e, err := build()
+	if err != nil {
+		return recordedRun{}, err
+	}
+	fills := recordFills(e)
+	var j *journalRecorder
+	if journal {
+		j = &journalRecorder{}
+		e.EventBus().Subscribe(EventFilter{}, j.onEvent)
+	}
+	e.SetDeterministic(true)
+	e.Run()
+	if status := e.Status(); status.Err != nil {
+		return recordedRun{}, status.Err
+	}
+	r := recordedRun{fills: fills.result()}
+	if r.manifest, err = e.Manifest(); err != nil {
+		return recordedRun{}, err
+	}
+	if j != nil {
+		if r.journal, err = j.result(); err != nil {
+			return recordedRun{}, err
+		}
+	}
+	return r, nil
+}
+
+func compareRuns(a recordedRun, b recordedRun) error {
+	ma, mb := a.manifest, b.manifest
+	if ma.ConfigHash != mb.ConfigHash || ma.DataFingerprint != mb.DataFingerprint || !equalSeeds(ma.Seeds, mb.Seeds) {
+		return fmt.Errorf("Manifests of runs differ: %+v and %+v. ", ma, mb)
+	}
+	for i := 0; i < len(a.journal) && i < len(b.journal); i++ {
+		if a.journal[i] != b.journal[i] {
+			return fmt.Errorf("Event %v differs: %v and %v. ", i, a.journal[i], b.journal[i])
+		}
+	}
+	if len(a.journal) != len(b.journal) {
+		return fmt.Errorf("Runs have %v and %v events. ", len(a.journal), len(b.journal))
+	}
+	for i := 0; i < len(a.fills) && i < len(b.fills); i++ {
+		if a.fills[i] != b.fills[i] {
+			return fmt.Errorf("Fill %v differs: %+v and %+v. ", i, a.fills[i], b.fills[i])
+		}
+	}
+	if len(a.fills) != len(b.fills) {
+		return fmt.Errorf("Runs have %v and %v fills. ", len(a.fills), len(b.fills))
+	}
+	return nil
+}
+
+//journalRecorder keeps events of the run from event bus as JSON which can be compared between runs
+type journalRecorder struct {
+	mut    sync.Mutex
+	ids    []string
+	known  map[string]struct{}
+	events []string
+	err    error
+}
+
+func (j *journalRecorder) onEvent(e Event) {
+	j.mut.Lock()
+	defer j.mut.Unlock()
+	if j.err != nil {
+		return
+	}
+	switch e := e.(type) {
+	case *RunManifestEvent, *BacktestProgressEvent:
+		//Creation time and progress depend on wall clock
+		return
+	case *NewOrderEvent:
+		if e.LinkedOrder != nil {
+			j.addID(e.LinkedOrder.Id)
+		}
+	}
+	v, err := encodeValue(reflect.ValueOf(e))
+	if err != nil {
+		j.err = err
+		return
+	}
+	if _, ok := e.(*EndOfDataEvent); ok {
+		//Market data ends data with current time
+		delete(v.(map[string]interface{}), "Time")
+	}
+	if v, err = withoutSeq(v); err != nil {
+		j.err = err
+		return
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(map[string]interface{}{"Type": eventTypeName(e), "Event": v}); err != nil {
+		j.err = err
+		return
+	}
+	j.events = append(j.events, strings.TrimSpace(buf.String()))
+}
+
+func (j *journalRecorder) addID(id string) {
+	if j.known == nil {
+		j.known = make(map[string]struct{})
+	}
+	if _, ok := j.known[id]; ok || id == "" {
+		return
+	}
+	j.known[id] = struct{}{}
+	j.ids = append(j.ids, id)
+}
+
+//result returns events with order ids replaced by numbers of orders. Longer ids are replaced first, so id which
+//is prefix of other one doesn't break it.
+func (j *journalRecorder) result() ([]string, error) {
+	j.mut.Lock()
+	defer j.mut.Unlock()
+	if j.err != nil {
+		return nil, j.err
+	}
+	pairs := make([][2]string, len(j.ids))
+	for i, id := range j.ids {
+		pairs[i] = [2]string{id, "#order" + strconv.Itoa(i+1)}
+	}
+	sort.SliceStable(pairs, func(a, b int) bool { return len(pairs[a][0]) > len(pairs[b][0]) })
+	args := make([]string, 0, 2*len(pairs))
+	for _, p := range pairs {
+		args = append(args, p[0], p[1])
+	}
+	r := strings.NewReplacer(args...)
+	events := make([]string, len(j.events))
+	for i, e := range j.events {
+		events[i] = r.Replace(e)
+	}
+	return events, nil
+}
+
+//withoutSeq returns encoded event without sequence numbers of the event and of events nested in it
+func withoutSeq(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		delete(v, "Seq")
+		for k, item := range v {
+			item, err := withoutSeq(item)
+			if err != nil {
+				return nil, err
+			}
+			v[k] = item
+		}
+	case []interface{}:
+		for i, item := range v {
+			item, err := withoutSeq(item)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = item
+		}
+	case EventJSON:
+		inner, err := encodeValue(reflect.ValueOf(v.Event))
+		if err != nil {
+			return nil, err
+		}
+		if inner, err = withoutSeq(inner); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"Type": eventTypeName(v.Event), "Event": inner}, nil
+	}
+	return v, nil
+}
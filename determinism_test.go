@@ -0,0 +1,90 @@
+package engine
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//limitTestStrategy sends limit order which is never filled at price depending on offset
+type limitTestStrategy struct {
+	manifestTestStrategy
+	offset float64
+	sent   bool
+}
+
+func (s *limitTestStrategy) OnTick(b *BasicStrategy, tick *Tick) {
+	s.manifestTestStrategy.OnTick(b, tick)
+	if s.sent {
+		return
+	}
+	s.sent = true
+	if _, err := b.NewLimitOrder(1+s.offset, OrderBuy, 1, GTCTIF, "Sim"); err != nil {
+		panic(err)
+	}
+}
+
+//testReporter keeps errors reported by determinism check
+type testReporter struct {
+	errors []string
+}
+
+func (r *testReporter) Helper() {}
+
+func (r *testReporter) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestCheckDeterminism(t *testing.T) {
+	dir, err := ioutil.TempDir("", "determinism")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	data := newTestBTMforSyntheticTicks(dir, 1)
+	build := func(offset func() float64) func() (*Engine, error) {
+		return func() (*Engine, error) {
+			return New(WithMarketData(data.clone()), WithBroker(&SimBroker{}),
+				WithStrategy(&Instrument{Symbol: "Sym1"}, &limitTestStrategy{offset: offset()}, 1))
+		}
+	}
+	same := func() float64 { return 0 }
+
+	_, err = CheckDeterminism(build(same), 1)
+	assert.NotNil(t, err)
+	r, err := CheckDeterminism(build(same), 3)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, r.Runs)
+	assert.NotEmpty(t, r.Manifest.ConfigHash)
+	assert.NotEmpty(t, r.Fills)
+	assert.True(t, r.Events > len(r.Fills))
+
+	//Random order ids are replaced by numbers of orders
+	journal, err := checkRuns(build(same), 1, true)
+	assert.Nil(t, err)
+	assert.Contains(t, strings.Join(journal.journal, "\n"), `"OrdId":"#order1"`)
+	assert.NotContains(t, strings.Join(journal.journal, "\n"), `"Seq"`)
+
+	//Order which isn't filled doesn't change fills, but changes journal
+	runs := 0
+	changed := build(func() float64 {
+		runs++
+		if runs == 3 {
+			return 0.5
+		}
+		return 0
+	})
+	_, err = CheckDeterminism(changed, 3)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "Run 3: Event")
+	}
+
+	reporter := &testReporter{}
+	assert.True(t, AssertDeterministic(reporter, 2, build(same)))
+	assert.Empty(t, reporter.errors)
+	runs = 0
+	assert.False(t, AssertDeterministic(reporter, 3, changed))
+	assert.Len(t, reporter.errors, 1)
+}
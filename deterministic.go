@@ -0,0 +1,54 @@
+package engine
+
+//SetDeterministic makes engine process every market data event completely before the next one: strategy
+//handlers are waited for, portfolio is updated by strategies directly and orders, broker answers and their
+//consequences are handled in market data loop until there are no more events. Results of such run don't depend
+//on goroutine scheduling, so they are the same from run to run. Events of one step shouldn't overflow
+//events channel, because nobody reads it while market data loop is busy.
+func (c *Engine) SetDeterministic(on bool) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.deterministic = on
+	for _, st := range c.strategiesMap {
+		st.setSynchronous(on)
+	}
+	if b, ok := c.broker.(synchronousBroker); ok {
+		b.setSynchronous(on)
+	}
+}
+
+//synchronousBroker is implemented by brokers which can handle events in goroutines of their own. Deterministic
+//engine makes them handle events before Notify returns.
+type synchronousBroker interface {
+	setSynchronous(on bool)
+}
+
+func (c *Engine) isDeterministic() bool {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.deterministic
+}
+
+//proceedEvents handles events of strategies and broker in deterministic mode until there are no more.
+//Events are handled before portfolio updates, so the order doesn't depend on readiness of channels.
+func (c *Engine) proceedEvents() {
+	for {
+		select {
+		case e := <-c.events:
+			c.proxyEvent(e)
+			continue
+		default:
+		}
+		select {
+		case e := <-c.portfolioChan:
+			c.portfolio.onNewTrade(e.trade)
+			continue
+		default:
+		}
+		return
+	}
+}
+
+func (b *BasicStrategy) setSynchronous(on bool) {
+	b.synchronous = on
+}
@@ -0,0 +1,59 @@
+package engine
+
+import (
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type synchronousTestStrategy struct {
+	DummyStrategy
+	candles int
+}
+
+func (s *synchronousTestStrategy) OnCandleClose(b *BasicStrategy, candle *Candle) {
+	//Slow handler is still done before notify returns
+	time.Sleep(5 * time.Millisecond)
+	s.candles++
+}
+
+func TestBasicStrategy_Synchronous(t *testing.T) {
+	st := newTestBasicStrategy()
+	us := &synchronousTestStrategy{}
+	st.userStrategy = us
+	st.nPeriods = 1
+	st.setSynchronous(true)
+
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		st.notify(newTestCandleCloseEvent(10, 10, 10, 10, tm.Add(time.Duration(i)*time.Minute), "1"))
+		assert.Equal(t, i+1, us.candles)
+	}
+}
+
+func TestEngine_SetDeterministic(t *testing.T) {
+	st := newTestBasicStrategy()
+	c := Engine{strategiesMap: map[string]ICoreStrategy{st.symbol.Symbol: st}, mut: &sync.Mutex{}}
+	c.SetDeterministic(true)
+	assert.True(t, st.synchronous)
+	assert.True(t, c.isDeterministic())
+	c.SetDeterministic(false)
+	assert.False(t, st.synchronous)
+}
+
+func TestEngine_proceedEvents(t *testing.T) {
+	c := Engine{events: make(chan event, 5), portfolioChan: make(chan *PortfolioNewPositionEvent, 5),
+		portfolio: newPortfolio(), mut: &sync.Mutex{}, waitG: &sync.WaitGroup{}}
+	c.log.SetOutput(ioutil.Discard)
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	c.events <- &CustomEvent{BaseEvent: be(tm, newTestInstrument()), Name: "1"}
+	c.events <- &CustomEvent{BaseEvent: be(tm, newTestInstrument()), Name: "2"}
+
+	c.proceedEvents()
+	assert.Len(t, c.events, 0)
+	assert.Len(t, c.customEvents, 2)
+	assert.Equal(t, "1", c.customEvents[0].(*CustomEvent).Name)
+}
@@ -0,0 +1,142 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"errors"
+	"math"
+	"time"
+)
+
+//Dividend is cash paid for one unit of qty to holders of the symbol on ExDate
+type Dividend struct {
+	ExDate time.Time
+	Amount float64
+}
+
+//IDividendStorage can be implemented by market data storage which has dividends. BTM merges dividends of the
+//symbols it works with into market data stream as DividendEvent.
+type IDividendStorage interface {
+	GetDividends(symbol *Instrument, dRange marketdata.DateRange) ([]*Dividend, error)
+}
+
+//IDividendUserStrategy can be implemented by user strategy to be notified about dividends of its symbol
+type IDividendUserStrategy interface {
+	OnDividend(b *BasicStrategy, e *DividendEvent)
+}
+
+//loadDividends returns dividend events of all symbols
+func (m *BTM) loadDividends() eventArray {
+	s, ok := m.Storage.(IDividendStorage)
+	if !ok {
+		return nil
+	}
+	rng := marketdata.DateRange{From: m.FromDate, To: m.ToDate.AddDate(0, 0, 1)}
+
+	var events eventArray
+	for _, inst := range m.Symbols {
+		dividends, err := s.GetDividends(inst, rng)
+		if err != nil {
+			m.newError(err)
+			continue
+		}
+		for _, d := range dividends {
+			events = append(events, &DividendEvent{
+				BaseEvent: be(d.ExDate, inst),
+				Amount:    d.Amount,
+			})
+		}
+	}
+	return events
+}
+
+func (c *Engine) eDividend(e *DividendEvent) {
+	if st, ok := c.findStrategy(e.getSymbol()); ok {
+		st.notify(e)
+	}
+}
+
+//DividendIncome returns dividends received minus dividends paid for short positions in account currency
+func (b *BasicStrategy) DividendIncome() float64 {
+	return b.dividendIncome
+}
+
+func (b *BasicStrategy) onDividendHandler(e *DividendEvent) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	if e.getTime().After(b.mostRecentTime) {
+		b.mostRecentTime = e.getTime()
+	}
+	//Long position receives dividend, short one pays it to the lender
+	if pos := b.Position(); pos != 0 && e.Amount != 0 {
+		flow := b.unitValue(e.Amount) * float64(pos)
+		b.dividendIncome += flow
+		if b.portfolio != nil {
+			b.portfolio.updatePositionCash(b.positionInfo(), flow)
+		}
+		b.checkRiskLimits()
+	}
+	if us, ok := b.userStrategy.(IDividendUserStrategy); ok {
+		us.OnDividend(b, e)
+	}
+}
+
+//InterestRates are annual interest rates of the account, e.g. 0.05 for 5%. Debit rate is charged on negative
+//cash balance, credit rate is paid on positive one.
+type InterestRates struct {
+	Debit  float64
+	Credit float64
+}
+
+//interestState keeps interest rates and day of the last accrual
+type interestState struct {
+	rates   InterestRates
+	lastDay time.Time
+	total   float64
+}
+
+func (p *portfolioHandler) setInterestRates(rates InterestRates) {
+	p.mut.Lock()
+	p.interest.rates = rates
+	p.mut.Unlock()
+}
+
+//accrueInterest changes cash by interest of every day passed since the last accrual. Interest is compounded
+//daily on 365 days year basis.
+func (p *portfolioHandler) accrueInterest(t time.Time) {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	day := t.Truncate(24 * time.Hour)
+	s := &p.interest
+	if s.lastDay.IsZero() {
+		s.lastDay = day
+		return
+	}
+	for s.lastDay.Before(day) {
+		s.lastDay = s.lastDay.Add(24 * time.Hour)
+		rate := s.rates.Credit
+		if p.cash < 0 {
+			rate = s.rates.Debit
+		}
+		interest := p.cash * rate / 365
+		p.cash += interest
+		s.total += interest
+	}
+}
+
+//Interest is total interest accrued on cash balance. It's negative if account paid more than received.
+func (p *portfolioHandler) Interest() float64 {
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	return p.interest.total
+}
+
+//SetInterestRates sets annual interest rates of the cash balance. Interest is accrued with market data of
+//every new day.
+func (c *Engine) SetInterestRates(rates InterestRates) error {
+	if rates.Debit < 0 || rates.Credit < 0 || math.IsNaN(rates.Debit) || math.IsNaN(rates.Credit) {
+		return errors.New("Interest rates can't be negative or NaN. ")
+	}
+	c.portfolio.setInterestRates(rates)
+	return nil
+}
@@ -0,0 +1,89 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+type mockDividendStorage struct {
+	*SyntheticStorage
+	data map[string][]*Dividend
+}
+
+func (s *mockDividendStorage) GetDividends(symbol *Instrument, dRange marketdata.DateRange) ([]*Dividend, error) {
+	return s.data[symbol.Symbol], nil
+}
+
+func TestBTM_loadDividends(t *testing.T) {
+	from := time.Date(2018, 3, 5, 0, 0, 0, 0, time.UTC)
+	storage := mockDividendStorage{
+		SyntheticStorage: NewSyntheticStorage(1, from, 100, &GBMProcess{Volatility: 0.2}),
+		data: map[string][]*Dividend{
+			"Sym1": {{ExDate: from.Add(48 * time.Hour), Amount: 0.5}},
+		},
+	}
+	m := BTM{
+		Symbols:   []*Instrument{{Symbol: "Sym1"}, {Symbol: "Sym2"}},
+		FromDate:  from,
+		ToDate:    from.AddDate(0, 0, 5),
+		Storage:   &storage,
+		waitGroup: &sync.WaitGroup{},
+	}
+	events := m.loadSideEvents()
+	assert.Len(t, events, 1)
+	e, ok := events[0].(*DividendEvent)
+	assert.True(t, ok)
+	assert.Equal(t, "Sym1", e.getSymbol())
+	assert.Equal(t, 0.5, e.Amount)
+}
+
+func TestBasicStrategy_onDividend(t *testing.T) {
+	st := newTestMarginStrategy(10000)
+	st.symbol.InitialMargin, st.symbol.MaintenanceMargin = 0, 0
+	tm := st.mostRecentTime
+
+	st.notify(&DividendEvent{BaseEvent: be(tm, st.symbol), Amount: 1})
+	st.shutDown()
+	assert.Equal(t, 0.0, st.DividendIncome())
+
+	fillTestOrder(st, OrderBuy, 100, 50)
+	st.notify(&DividendEvent{BaseEvent: be(tm.Add(time.Hour), st.symbol), Amount: 0.5})
+	st.shutDown()
+	assert.InDelta(t, 50.0, st.DividendIncome(), 0.0000001)
+	assert.InDelta(t, 5050.0, st.Cash(), 0.0000001)
+
+	//Short position pays dividend
+	fillTestOrder(st, OrderSell, 300, 50)
+	st.notify(&DividendEvent{BaseEvent: be(tm.Add(2*time.Hour), st.symbol), Amount: 0.5})
+	st.shutDown()
+	assert.InDelta(t, -50.0, st.DividendIncome(), 0.0000001)
+	assert.InDelta(t, 19950.0, st.Cash(), 0.0000001)
+}
+
+func TestPortfolio_accrueInterest(t *testing.T) {
+	p := newPortfolio()
+	p.setCapital(-36500)
+	c := Engine{portfolio: p}
+	assert.NotNil(t, c.SetInterestRates(InterestRates{Debit: -1}))
+	assert.Nil(t, c.SetInterestRates(InterestRates{Debit: 0.1, Credit: 0.01}))
+
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	p.accrueInterest(tm)
+	p.accrueInterest(tm.Add(5 * time.Hour))
+	assert.Equal(t, 0.0, p.Interest())
+
+	p.accrueInterest(tm.Add(24 * time.Hour))
+	assert.InDelta(t, -10.0, p.Interest(), 0.0000001)
+	p.accrueInterest(tm.Add(72 * time.Hour))
+	assert.InDelta(t, -36500*(math.Pow(1+0.1/365, 3)-1), p.Interest(), 0.0000001)
+
+	//Positive cash earns credit rate
+	p.setCapital(100)
+	cash := p.Cash()
+	p.accrueInterest(tm.Add(96 * time.Hour))
+	assert.InDelta(t, cash*(1+0.01/365), p.Cash(), 0.0000001)
+}
@@ -0,0 +1,180 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"fmt"
+	"github.com/pkg/errors"
+	"net/http"
+	"time"
+)
+
+//IDataProvider downloads historical market data from external source. Timeframes are in engine format:
+//number of minutes or "D" and "W".
+type IDataProvider interface {
+	Name() string
+	DownloadTicks(symbol string, dRange marketdata.DateRange) (marketdata.TickArray, error)
+	DownloadCandles(symbol string, timeFrame string, dRange marketdata.DateRange) (marketdata.CandleArray, error)
+}
+
+//IStorageWriter is implemented by market data storage which can save downloaded data in the layout
+//BTM reads it from, e.g. JSONStorage.
+type IStorageWriter interface {
+	SaveTicks(symbol string, ticks marketdata.TickArray) error
+	SaveCandles(symbol string, timeFrame string, candles marketdata.CandleArray) error
+}
+
+//ErrProviderNotSupported is returned by providers which can't download requested kind of data
+type ErrProviderNotSupported struct {
+	Provider string
+	Message  string
+}
+
+func (e *ErrProviderNotSupported) Error() string {
+	return fmt.Sprintf("Provider %v doesn't support: %v", e.Provider, e.Message)
+}
+
+func (e *ErrProviderNotSupported) Code() ErrCode {
+	return ErrCodeProviderNotSupported
+}
+
+func (e *ErrProviderNotSupported) Is(target error) bool {
+	return isCode(e.Code(), target)
+}
+
+//ErrProviderResponse is returned by providers when request fails with HTTP status
+type ErrProviderResponse struct {
+	URL        string
+	StatusCode int
+	Status     string
+}
+
+func (e *ErrProviderResponse) Error() string {
+	return fmt.Sprintf("Request %v failed with status: %v", e.URL, e.Status)
+}
+
+func (e *ErrProviderResponse) Code() ErrCode {
+	return ErrCodeProviderResponse
+}
+
+func (e *ErrProviderResponse) Is(target error) bool {
+	return isCode(e.Code(), target)
+}
+
+//retryable returns true for rate limits and server errors. Other statuses, e.g. bad request or failed
+//authorization, are the same on retry.
+func (e *ErrProviderResponse) retryable() bool {
+	switch {
+	case e.StatusCode == http.StatusTooManyRequests, e.StatusCode == http.StatusTeapot:
+		//Binance responds with 418 when IP is banned for ignoring rate limits
+		return true
+	case e.StatusCode >= 500:
+		return true
+	}
+	return false
+}
+
+//DataDownloader loads historical data from provider and puts it to storage. Ticks are downloaded and
+//saved day by day, candles are downloaded for the whole range at once. Failed requests are retried Retries
+//times, delay before retry starts from Backoff and doubles every time.
+type DataDownloader struct {
+	Provider IDataProvider
+	Storage  IStorageWriter
+	Retries  int
+	Backoff  time.Duration
+}
+
+func NewDataDownloader(provider IDataProvider, storage IStorageWriter) *DataDownloader {
+	d := DataDownloader{
+		Provider: provider,
+		Storage:  storage,
+		Retries:  3,
+		Backoff:  time.Second,
+	}
+	return &d
+}
+
+//DownloadTicks downloads and saves ticks for every symbol and every day of date range
+func (d *DataDownloader) DownloadTicks(symbols []string, dRange marketdata.DateRange) error {
+	for _, s := range symbols {
+		for day := dRange.From; !day.After(dRange.To); day = day.AddDate(0, 0, 1) {
+			rng := marketdata.DateRange{
+				From: time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC),
+				To:   time.Date(day.Year(), day.Month(), day.Day(), 23, 59, 59, 59, time.UTC),
+			}
+			var ticks marketdata.TickArray
+			err := d.withRetries(func() error {
+				var err error
+				ticks, err = d.Provider.DownloadTicks(s, rng)
+				return err
+			})
+			if err != nil {
+				return errors.Wrapf(err, "Can't download %v ticks for %v from %v", s, day.Format("2006-01-02"), d.Provider.Name())
+			}
+			if len(ticks) == 0 {
+				continue
+			}
+			for _, t := range ticks {
+				t.Symbol = s
+			}
+			ticks.Sort()
+			if err := d.Storage.SaveTicks(s, ticks); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+//DownloadCandles downloads and saves candles of the timeframe for every symbol
+func (d *DataDownloader) DownloadCandles(symbols []string, timeFrame string, dRange marketdata.DateRange) error {
+	if _, err := timeFrameDuration(timeFrame); err != nil {
+		return err
+	}
+	for _, s := range symbols {
+		var candles marketdata.CandleArray
+		err := d.withRetries(func() error {
+			var err error
+			candles, err = d.Provider.DownloadCandles(s, timeFrame, dRange)
+			return err
+		})
+		if err != nil {
+			return errors.Wrapf(err, "Can't download %v candles for %v from %v", timeFrame, s, d.Provider.Name())
+		}
+		if len(candles) == 0 {
+			continue
+		}
+		for _, c := range candles {
+			c.Symbol = s
+		}
+		candles.Sort()
+		if err := d.Storage.SaveCandles(s, timeFrame, candles); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//withRetries calls f until it succeeds or retries are over. Errors which are the same on retry, e.g. not
+//supported data or failed authorization, are not retried.
+func (d *DataDownloader) withRetries(f func() error) error {
+	delay := d.Backoff
+	for i := 0; ; i++ {
+		err := f()
+		if err == nil || i >= d.Retries || !retryable(err) {
+			return err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+func retryable(err error) bool {
+	if ErrorCode(err) == ErrCodeProviderNotSupported {
+		return false
+	}
+	var re *ErrProviderResponse
+	if errors.As(err, &re) {
+		return re.retryable()
+	}
+	return true
+}
@@ -0,0 +1,97 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestDataDownloader_DownloadCandles(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v3/klines", r.URL.Path)
+		assert.Equal(t, "5m", r.URL.Query().Get("interval"))
+		fmt.Fprint(w, `[[1520244000000,"10.0","11.0","9.5","10.5","150.7",1520244299999],
+			[1520244300000,"10.5","12.0","10.0","11.0","99",1520244599999]]`)
+	}))
+	defer srv.Close()
+
+	folder, err := ioutil.TempDir("", "downloader")
+	assert.Nil(t, err)
+	defer os.RemoveAll(folder)
+
+	provider := NewBinanceProvider()
+	provider.BaseURL = srv.URL
+	storage := NewJSONStorage(folder)
+	d := NewDataDownloader(provider, storage)
+
+	rng := marketdata.DateRange{
+		From: time.Date(2018, 3, 5, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2018, 3, 5, 23, 59, 0, 0, time.UTC),
+	}
+	err = d.DownloadCandles([]string{"BTCUSDT"}, "5", rng)
+	assert.Nil(t, err)
+
+	candles, err := storage.GetStoredCandles("BTCUSDT", "5", rng)
+	assert.Nil(t, err)
+	assert.Len(t, candles, 2)
+	assert.Equal(t, "BTCUSDT", candles[0].Symbol)
+	assert.Equal(t, 10.5, candles[0].Close)
+	assert.Equal(t, int64(150), candles[0].Volume)
+	assert.True(t, candles[1].Datetime.Equal(time.Date(2018, 3, 5, 10, 5, 0, 0, time.UTC)))
+
+	t.Log("Not supported data is not retried")
+	{
+		calls := 0
+		tiingo := NewTiingoProvider("token")
+		d := NewDataDownloader(tiingo, storage)
+		err := d.withRetries(func() error {
+			calls++
+			_, err := tiingo.DownloadTicks("Test", rng)
+			return err
+		})
+		assert.IsType(t, &ErrProviderNotSupported{}, err)
+		assert.Equal(t, 1, calls)
+	}
+}
+
+func TestDataDownloader_withRetries(t *testing.T) {
+	status := http.StatusTooManyRequests
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	provider := NewBinanceProvider()
+	provider.BaseURL = srv.URL
+	d := NewDataDownloader(provider, nil)
+	d.Backoff = 10 * time.Millisecond
+	rng := marketdata.DateRange{
+		From: time.Date(2018, 3, 5, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2018, 3, 5, 23, 59, 0, 0, time.UTC),
+	}
+	download := func() error {
+		_, err := provider.DownloadCandles("BTCUSDT", "5", rng)
+		return err
+	}
+
+	start := time.Now()
+	err := d.withRetries(download)
+	assert.Equal(t, ErrCodeProviderResponse, ErrorCode(err))
+	assert.Equal(t, 4, calls)
+	assert.True(t, time.Since(start) >= 70*time.Millisecond)
+
+	t.Log("Authorization errors are not retried")
+	{
+		status, calls = http.StatusUnauthorized, 0
+		assert.NotNil(t, d.withRetries(download))
+		assert.Equal(t, 1, calls)
+	}
+}
@@ -0,0 +1,123 @@
+package engine
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+//IKillSwitchUserStrategy can be implemented by user strategy to be notified when portfolio kill switch is
+//triggered
+type IKillSwitchUserStrategy interface {
+	OnKillSwitch(b *BasicStrategy, e *PortfolioKillSwitchEvent)
+}
+
+//drawdownState keeps peak equity of the account and state of the kill switch. Max drawdown is fraction of peak
+//equity, zero disables kill switch.
+type drawdownState struct {
+	maxDrawdown float64
+	peak        float64
+	drawdown    float64
+	killed      bool
+}
+
+//PeakEquity is max equity of the account seen so far
+func (p *portfolioHandler) PeakEquity() float64 {
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	return p.dd.peak
+}
+
+//Drawdown is current loss of equity from peak as fraction of peak equity
+func (p *portfolioHandler) Drawdown() float64 {
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	return p.dd.drawdown
+}
+
+//KillSwitchActive returns true after drawdown exceeded max drawdown until kill switch is reset
+func (p *portfolioHandler) KillSwitchActive() bool {
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	return p.dd.killed
+}
+
+func (p *portfolioHandler) setMaxDrawdown(fraction float64) {
+	p.mut.Lock()
+	p.dd.maxDrawdown = fraction
+	p.mut.Unlock()
+}
+
+//resetKillSwitch allows opening positions again. Peak equity starts from current equity, so kill switch isn't
+//triggered again by the same drawdown.
+func (p *portfolioHandler) resetKillSwitch() {
+	equity := p.Equity()
+	p.mut.Lock()
+	p.dd.killed = false
+	p.dd.peak = equity
+	p.dd.drawdown = 0
+	p.mut.Unlock()
+}
+
+//checkDrawdown updates peak equity and drawdown. PortfolioKillSwitchEvent is returned when drawdown exceeds
+//max drawdown for the first time since reset.
+func (p *portfolioHandler) checkDrawdown(t time.Time, inst *Instrument) *PortfolioKillSwitchEvent {
+	equity := p.Equity()
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	if equity > p.dd.peak {
+		p.dd.peak = equity
+	}
+	if p.dd.peak <= 0 {
+		return nil
+	}
+	p.dd.drawdown = (p.dd.peak - equity) / p.dd.peak
+	if p.dd.killed || p.dd.maxDrawdown <= 0 || p.dd.drawdown < p.dd.maxDrawdown {
+		return nil
+	}
+	p.dd.killed = true
+	return &PortfolioKillSwitchEvent{
+		BaseEvent:  be(t, inst),
+		Equity:     equity,
+		PeakEquity: p.dd.peak,
+		Drawdown:   p.dd.drawdown,
+	}
+}
+
+//checkDrawdown notifies engine when portfolio kill switch is triggered
+func (b *BasicStrategy) checkDrawdown() {
+	if b.portfolio == nil {
+		return
+	}
+	if e := b.portfolio.checkDrawdown(b.mostRecentTime, b.symbol); e != nil {
+		b.newSignal(e)
+	}
+}
+
+func (b *BasicStrategy) onKillSwitchHandler(e *PortfolioKillSwitchEvent) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	if e.getTime().After(b.mostRecentTime) {
+		b.mostRecentTime = e.getTime()
+	}
+	if us, ok := b.userStrategy.(IKillSwitchUserStrategy); ok {
+		us.OnKillSwitch(b, e)
+	}
+}
+
+//SetMaxDrawdown sets drawdown of the portfolio equity from its peak, e.g. 0.2 for 20%, which triggers kill
+//switch. While kill switch is active all strategies can send only orders which reduce position. Zero
+//disables kill switch.
+func (c *Engine) SetMaxDrawdown(fraction float64) error {
+	if fraction < 0 || fraction >= 1 || math.IsNaN(fraction) {
+		return errors.New("Max drawdown should be in [0, 1) range. ")
+	}
+	c.portfolio.setMaxDrawdown(fraction)
+	return nil
+}
+
+//ResetKillSwitch allows strategies to open positions again after kill switch was triggered
+func (c *Engine) ResetKillSwitch() {
+	c.portfolio.resetKillSwitch()
+}
@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+)
+
+type killSwitchTestStrategy struct {
+	DummyStrategy
+	events []*PortfolioKillSwitchEvent
+}
+
+func (s *killSwitchTestStrategy) OnKillSwitch(b *BasicStrategy, e *PortfolioKillSwitchEvent) {
+	s.events = append(s.events, e)
+}
+
+func TestPortfolio_KillSwitch(t *testing.T) {
+	st := newTestMarginStrategy(10000)
+	st.symbol.InitialMargin, st.symbol.MaintenanceMargin = 0, 0
+	us := &killSwitchTestStrategy{}
+	st.userStrategy = us
+	c := Engine{strategiesMap: map[string]ICoreStrategy{"Test": st}, portfolio: st.portfolio, mut: &sync.Mutex{},
+		waitG: &sync.WaitGroup{}}
+	c.log.SetOutput(ioutil.Discard)
+	assert.NotNil(t, c.SetMaxDrawdown(1.5))
+	assert.Nil(t, c.SetMaxDrawdown(0.1))
+
+	fillTestOrder(st, OrderBuy, 100, 50)
+	for len(st.ch.events) > 0 {
+		<-st.ch.events
+	}
+	st.notify(newTestCandleCloseEvent(55, 55, 55, 55, st.mostRecentTime.Add(time.Minute), "1"))
+	st.shutDown()
+	assert.InDelta(t, 10500.0, st.Portfolio().PeakEquity(), 0.0000001)
+
+	st.notify(newTestCandleCloseEvent(50, 50, 50, 50, st.mostRecentTime.Add(2*time.Minute), "1"))
+	st.shutDown()
+	assert.InDelta(t, 500.0/10500, st.Portfolio().Drawdown(), 0.0000001)
+	assert.Len(t, st.ch.events, 0)
+
+	st.notify(newTestCandleCloseEvent(44, 44, 44, 44, st.mostRecentTime.Add(3*time.Minute), "1"))
+	st.shutDown()
+	st.notify(newTestCandleCloseEvent(43, 43, 43, 43, st.mostRecentTime.Add(4*time.Minute), "1"))
+	st.shutDown()
+	//Kill switch event is produced once
+	assert.Len(t, st.ch.events, 1)
+	e := (<-st.ch.events).(*PortfolioKillSwitchEvent)
+	assert.InDelta(t, 9400.0, e.Equity, 0.0000001)
+	assert.InDelta(t, 10500.0, e.PeakEquity, 0.0000001)
+	assert.True(t, st.Portfolio().KillSwitchActive())
+
+	c.proxyEvent(e)
+	c.firePortfolioEvents()
+	st.shutDown()
+	assert.Len(t, us.events, 1)
+
+	_, err := st.NewLimitOrder(40, OrderBuy, 10, GTCTIF, "Sim")
+	assert.NotNil(t, err)
+	_, err = st.NewLimitOrder(45, OrderSell, 50, GTCTIF, "Sim")
+	assert.Nil(t, err)
+
+	c.ResetKillSwitch()
+	assert.False(t, st.Portfolio().KillSwitchActive())
+	assert.InDelta(t, 9300.0, st.Portfolio().PeakEquity(), 0.0000001)
+	_, err = st.NewLimitOrder(40, OrderBuy, 10, GTCTIF, "Sim")
+	assert.Nil(t, err)
+}
@@ -0,0 +1,135 @@
+package engine
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+//IEndOfDayUserStrategy can be implemented by user strategy to get session close of its symbol, e.g. to
+//flatten position or roll up daily stats
+type IEndOfDayUserStrategy interface {
+	OnEndOfDay(b *BasicStrategy, e *EndOfDayEvent)
+}
+
+//endOfDayState is the next session close of every symbol
+type endOfDayState struct {
+	mut    sync.Mutex
+	closes map[string]*strategyTimer
+	//earliest is the first session close of all symbols
+	earliest time.Time
+}
+
+//due returns end of day events of instruments whose session closed not later than now. Session of the symbol
+//of current market data event is over when data of the close time comes, other symbols wait for data after
+//the close, so the last data of the session is handled before its end of day. If several sessions closed
+//since last check, only the last one is reported. Instruments without close time have no sessions.
+func (s *endOfDayState) due(now time.Time, symbol string, instruments []*Instrument) []event {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if s.closes == nil {
+		s.closes = make(map[string]*strategyTimer)
+	}
+	if len(s.closes) == len(instruments) && now.Before(s.earliest) {
+		return nil
+	}
+
+	var events []event
+	s.earliest = time.Time{}
+	for _, inst := range instruments {
+		t, ok := s.closes[inst.Symbol]
+		if !ok {
+			t = &strategyTimer{kind: sessionCloseTimer}
+			if inst.Exchange.MarketCloseTime != (TimeOfDay{}) {
+				t.next = t.nextAfter(now.Add(-time.Nanosecond), &inst.Exchange)
+			}
+			s.closes[inst.Symbol] = t
+		}
+		if t.next.IsZero() {
+			continue
+		}
+		if !t.next.After(now) && (inst.Symbol == symbol || now.After(t.next)) {
+			closed := t.next
+			for !t.next.After(now) {
+				closed = t.next
+				t.next = t.nextAfter(t.next, &inst.Exchange)
+			}
+			l := inst.Exchange.LocalTime(closed)
+			events = append(events, &EndOfDayEvent{
+				BaseEvent: be(closed, inst),
+				Date:      time.Date(l.Year(), l.Month(), l.Day(), 0, 0, 0, 0, l.Location()),
+			})
+		}
+		if s.earliest.IsZero() || t.next.Before(s.earliest) {
+			s.earliest = t.next
+		}
+	}
+	return events
+}
+
+//fireEndOfDay notifies simulated broker and strategies about session closes not later than now. Symbol is
+//symbol of market data event which moved time to now.
+func (c *Engine) fireEndOfDay(now time.Time, symbol string) {
+	c.mut.Lock()
+	instruments := make([]*Instrument, 0, len(c.strategiesMap))
+	for _, st := range c.strategiesMap {
+		instruments = append(instruments, st.getInstrument())
+	}
+	c.mut.Unlock()
+	//Events of the same time get sequence numbers in order of symbols
+	sort.Slice(instruments, func(i, j int) bool { return instruments[i].Symbol < instruments[j].Symbol })
+
+	due := c.endOfDay.due(now, symbol, instruments)
+	if len(due) == 0 {
+		return
+	}
+	eventArray(due).sort()
+	for _, e := range due {
+		c.bus.Publish(e)
+		c.countEvent(e)
+		if c.broker.IsSimulated() {
+			c.broker.Notify(e)
+		}
+		if st, ok := c.findStrategy(e.getSymbol()); ok {
+			st.notify(e)
+		}
+	}
+}
+
+func (b *BasicStrategy) onEndOfDayHandler(e *EndOfDayEvent) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	if e.getTime().After(b.mostRecentTime) {
+		b.mostRecentTime = e.getTime()
+	}
+	b.accrueFinancing(e)
+	if us, ok := b.userStrategy.(IEndOfDayUserStrategy); ok {
+		b.callUserStrategy("OnEndOfDay", func() { us.OnEndOfDay(b, e) })
+	}
+}
+
+//onEndOfDay proceeds requests sent before session close, cancels day orders and sends out generated events
+//up to the close
+func (b *simBrokerWorker) onEndOfDay(e *EndOfDayEvent) {
+	b.proceedStoredRequests(e.getTime())
+
+	b.mpMutext.Lock()
+	defer b.mpMutext.Unlock()
+	for _, o := range b.orders {
+		if o.isActive() && o.Tif == DayTIF && !o.Time.After(e.getTime()) {
+			b.addBrokerEvent(&OrderCancelEvent{OrdId: o.Id, BaseEvent: be(e.getTime(), o.Ticker)})
+		}
+	}
+
+	b.generatedEvents.sort()
+	var eventsLeft eventArray
+	for _, ge := range b.generatedEvents {
+		if ge.getTime().After(e.getTime()) {
+			eventsLeft = append(eventsLeft, ge)
+			continue
+		}
+		sendEvent(b.ctx, b.events, ge)
+	}
+	b.generatedEvents = eventsLeft
+}
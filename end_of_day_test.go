@@ -0,0 +1,87 @@
+package engine
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//endOfDayTestStrategy remembers dates of end of day callbacks
+type endOfDayTestStrategy struct {
+	DummyStrategy
+	dates []time.Time
+}
+
+func (s *endOfDayTestStrategy) OnEndOfDay(b *BasicStrategy, e *EndOfDayEvent) {
+	s.dates = append(s.dates, e.Date)
+}
+
+func TestEndOfDayState_due(t *testing.T) {
+	inst := newTestInstrument()
+	other := newTestInstrument()
+	other.Symbol = "Other"
+	noSession := &Instrument{Symbol: "Crypto"}
+	instruments := []*Instrument{inst, other, noSession}
+	s := endOfDayState{}
+
+	day := time.Date(2018, 3, 5, 0, 0, 0, 0, time.UTC)
+	assert.Len(t, s.due(day.Add(15*time.Hour), "Test", instruments), 0)
+
+	//Symbol of data of the close time gets its end of day, other symbol waits for data after the close
+	events := s.due(day.Add(16*time.Hour), "Test", instruments)
+	if assert.Len(t, events, 1) {
+		e := events[0].(*EndOfDayEvent)
+		assert.Equal(t, "Test", e.getSymbol())
+		assert.Equal(t, day.Add(16*time.Hour), e.getTime())
+		assert.Equal(t, day, e.Date)
+	}
+	events = s.due(day.Add(16*time.Hour+time.Second), "Test", instruments)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, "Other", events[0].getSymbol())
+	}
+	assert.Len(t, s.due(day.Add(20*time.Hour), "Other", instruments), 0)
+
+	//Only the last of missed sessions is reported
+	events = s.due(day.AddDate(0, 0, 3).Add(10*time.Hour), "", instruments)
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, day.AddDate(0, 0, 2), events[0].(*EndOfDayEvent).Date)
+	}
+}
+
+func TestSimBroker_EndOfDay(t *testing.T) {
+	b := newTestSimBrokerWorker()
+	b.events = make(chan event, 10)
+	day := newTestDayBrokerOrder(10, OrderBuy, 100, "day")
+	gtc := newTestGtcBrokerOrder(10, OrderBuy, 100, "gtc")
+	b.orders[day.Id] = day
+	b.orders[gtc.Id] = gtc
+
+	tm := time.Date(2010, 1, 5, 16, 0, 0, 0, time.UTC)
+	b.notify(&EndOfDayEvent{BaseEvent: be(tm, day.Ticker), Date: time.Date(2010, 1, 5, 0, 0, 0, 0, time.UTC)})
+
+	assert.Equal(t, CanceledOrder, day.BrokerState)
+	assert.Equal(t, ConfirmedOrder, gtc.BrokerState)
+	if assert.Len(t, b.events, 1) {
+		e := (<-b.events).(*OrderCancelEvent)
+		assert.Equal(t, "day", e.OrdId)
+		assert.Equal(t, tm, e.getTime())
+	}
+}
+
+func TestEngine_fireEndOfDay(t *testing.T) {
+	st := newTestBasicStrategy()
+	us := &endOfDayTestStrategy{}
+	st.userStrategy = us
+	st.setSynchronous(true)
+	c := Engine{broker: newTestSimBroker(), strategiesMap: map[string]ICoreStrategy{st.symbol.Symbol: st},
+		mut: &sync.Mutex{}}
+
+	day := time.Date(2018, 3, 5, 0, 0, 0, 0, time.UTC)
+	c.fireEndOfDay(day.Add(10*time.Hour), "Test")
+	c.fireEndOfDay(day.Add(16*time.Hour), "Test")
+	c.fireEndOfDay(day.Add(17*time.Hour), "Test")
+	assert.Equal(t, []time.Time{day}, us.dates)
+	assert.Equal(t, day.Add(16*time.Hour), st.mostRecentTime)
+}
@@ -0,0 +1,178 @@
+package engine
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+const defaultEquityResolution = time.Hour
+
+//EquityPoint is state of the account at the last market data of resolution interval
+type EquityPoint struct {
+	Time          time.Time
+	Equity        float64
+	Cash          float64
+	GrossExposure float64
+	NetExposure   float64
+}
+
+//DailyReturn is equity at the last point of the day and its return to the previous day. Return of the first
+//day is measured from the first point of the curve.
+type DailyReturn struct {
+	Date   time.Time
+	Equity float64
+	Return float64
+}
+
+//equityCurve keeps one point for every resolution interval market data came in
+type equityCurve struct {
+	resolution  time.Duration
+	startEquity float64
+	points      []EquityPoint
+}
+
+//SetEquityResolution sets interval of equity curve points. Default is one hour. Points recorded before are
+//dropped.
+func (c *Engine) SetEquityResolution(resolution time.Duration) error {
+	if resolution <= 0 {
+		return errors.New("Equity resolution should be positive. ")
+	}
+	p := c.portfolio
+	p.mut.Lock()
+	p.curve = equityCurve{resolution: resolution}
+	p.mut.Unlock()
+	return nil
+}
+
+//recordEquity updates point of the interval of t. Point of the interval is replaced until data of the next
+//interval comes.
+func (p *portfolioHandler) recordEquity(t time.Time) {
+	point := EquityPoint{Time: t, Equity: p.Equity(), GrossExposure: p.GrossExposure(), NetExposure: p.NetExposure()}
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	point.Cash = p.cash
+	c := &p.curve
+	if c.resolution == 0 {
+		c.resolution = defaultEquityResolution
+	}
+	n := len(c.points)
+	if n == 0 {
+		c.startEquity = point.Equity
+		c.points = append(c.points, point)
+		return
+	}
+	last := c.points[n-1].Time
+	switch {
+	case t.Before(last):
+	case t.Truncate(c.resolution).Equal(last.Truncate(c.resolution)):
+		c.points[n-1] = point
+	default:
+		c.points = append(c.points, point)
+	}
+}
+
+//EquityCurve returns copy of recorded equity points
+func (p *portfolioHandler) EquityCurve() []EquityPoint {
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	return append([]EquityPoint{}, p.curve.points...)
+}
+
+//DailyReturns returns returns of equity curve by calendar day of point times
+func (p *portfolioHandler) DailyReturns() []DailyReturn {
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	var days []DailyReturn
+	for _, pt := range p.curve.points {
+		y, m, d := pt.Time.Date()
+		date := time.Date(y, m, d, 0, 0, 0, 0, pt.Time.Location())
+		if n := len(days); n > 0 && days[n-1].Date.Equal(date) {
+			days[n-1].Equity = pt.Equity
+			continue
+		}
+		days = append(days, DailyReturn{Date: date, Equity: pt.Equity})
+	}
+	prev := p.curve.startEquity
+	for i := range days {
+		days[i].Return = days[i].Equity/prev - 1
+		prev = days[i].Equity
+	}
+	return days
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+//WriteEquityCSV writes equity curve with header line
+func (c *Engine) WriteEquityCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"time", "equity", "cash", "gross_exposure", "net_exposure"})
+	for _, pt := range c.portfolio.EquityCurve() {
+		cw.Write([]string{pt.Time.Format(time.RFC3339Nano), formatFloat(pt.Equity), formatFloat(pt.Cash),
+			formatFloat(pt.GrossExposure), formatFloat(pt.NetExposure)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+//WriteDailyReturnsCSV writes daily returns with header line
+func (c *Engine) WriteDailyReturnsCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"date", "equity", "return"})
+	for _, d := range c.portfolio.DailyReturns() {
+		cw.Write([]string{d.Date.Format(calendarDateLayout), formatFloat(d.Equity), formatFloat(d.Return)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+//equityExport is JSON document with equity curve and daily returns
+type equityExport struct {
+	Equity       []EquityPoint
+	DailyReturns []DailyReturn
+}
+
+//WriteEquityJSON writes equity curve and daily returns as one JSON document
+func (c *Engine) WriteEquityJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(equityExport{
+		Equity:       c.portfolio.EquityCurve(),
+		DailyReturns: c.portfolio.DailyReturns(),
+	})
+}
+
+//SaveEquity writes equity curve to savePath_equity.csv, daily returns to savePath_returns.csv and both of them
+//to savePath_equity.json
+func (c *Engine) SaveEquity(savePath string) error {
+	files := []struct {
+		path  string
+		write func(w io.Writer) error
+	}{
+		{savePath + "_equity.csv", c.WriteEquityCSV},
+		{savePath + "_returns.csv", c.WriteDailyReturnsCSV},
+		{savePath + "_equity.json", c.WriteEquityJSON},
+	}
+	for _, f := range files {
+		if err := writeFile(f.path, f.write); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeFile(path string, write func(w io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,90 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestEquityEngine() *Engine {
+	c := &Engine{portfolio: newPortfolio(), mut: &sync.Mutex{}}
+	c.portfolio.setCapital(10000)
+	return c
+}
+
+func TestPortfolio_recordEquity(t *testing.T) {
+	c := newTestEquityEngine()
+	assert.NotNil(t, c.SetEquityResolution(0))
+	assert.Nil(t, c.SetEquityResolution(12*time.Hour))
+	p := c.portfolio
+
+	day := time.Date(2018, 3, 5, 0, 0, 0, 0, time.UTC)
+	p.recordEquity(day.Add(10 * time.Hour))
+	p.cash = 10100
+	//The last data of the interval replaces its point
+	p.recordEquity(day.Add(11 * time.Hour))
+	p.recordEquity(day.Add(9 * time.Hour))
+	p.cash = 10200
+	p.positions["Test"] = PositionInfo{Symbol: "Test", Qty: -10, MarketValue: -100}
+	p.recordEquity(day.Add(15 * time.Hour))
+	p.cash = 9999
+	p.recordEquity(day.AddDate(0, 0, 1).Add(10 * time.Hour))
+
+	points := p.EquityCurve()
+	if assert.Len(t, points, 3) {
+		assert.Equal(t, EquityPoint{Time: day.Add(11 * time.Hour), Equity: 10100, Cash: 10100}, points[0])
+		assert.Equal(t, EquityPoint{Time: day.Add(15 * time.Hour), Equity: 10100, Cash: 10200,
+			GrossExposure: 100, NetExposure: -100}, points[1])
+		assert.Equal(t, 9899.0, points[2].Equity)
+	}
+
+	returns := p.DailyReturns()
+	if assert.Len(t, returns, 2) {
+		assert.Equal(t, day, returns[0].Date)
+		assert.Equal(t, 10100.0, returns[0].Equity)
+		assert.InDelta(t, 0.01, returns[0].Return, 0.0000001)
+		assert.Equal(t, day.AddDate(0, 0, 1), returns[1].Date)
+		assert.InDelta(t, 9899.0/10100-1, returns[1].Return, 0.0000001)
+	}
+}
+
+func TestEngine_WriteEquity(t *testing.T) {
+	c := newTestEquityEngine()
+	day := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	c.portfolio.recordEquity(day)
+	c.portfolio.cash = 12500
+	c.portfolio.recordEquity(day.AddDate(0, 0, 1))
+
+	var buf bytes.Buffer
+	assert.Nil(t, c.WriteEquityCSV(&buf))
+	assert.Equal(t, "time,equity,cash,gross_exposure,net_exposure\n"+
+		"2018-03-05T10:00:00Z,10000,10000,0,0\n"+
+		"2018-03-06T10:00:00Z,12500,12500,0,0\n", buf.String())
+
+	buf.Reset()
+	assert.Nil(t, c.WriteDailyReturnsCSV(&buf))
+	assert.Equal(t, "date,equity,return\n2018-03-05,10000,0\n2018-03-06,12500,0.25\n", buf.String())
+
+	buf.Reset()
+	assert.Nil(t, c.WriteEquityJSON(&buf))
+	var restored equityExport
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &restored))
+	assert.Equal(t, c.portfolio.EquityCurve(), restored.Equity)
+	assert.Len(t, restored.DailyReturns, 2)
+
+	dir, err := ioutil.TempDir("", "equity")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	assert.Nil(t, c.SaveEquity(filepath.Join(dir, "run")))
+	for _, name := range []string{"run_equity.csv", "run_returns.csv", "run_equity.json"} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		assert.Nil(t, err)
+	}
+}
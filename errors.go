@@ -1,7 +1,10 @@
 package engine
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 )
 
 type ErrBrokenTick struct {
@@ -115,3 +118,226 @@ func (e *ErrOrderIdIncorrect) Error() string {
 	return fmt.Sprintf("%v: ErrOrderIdIncorrect (id:%v). %v", e.Caller, e.OrdId, e.Message)
 
 }
+
+//ErrDataAnomaly is sent instead of panic when event can't be processed, e.g. market data is out of order or
+//event refers to unknown order. Event is skipped unless strict mode is enabled.
+type ErrDataAnomaly struct {
+	Symbol  string
+	Event   string
+	Time    time.Time
+	Message string
+	Caller  string
+}
+
+func (e *ErrDataAnomaly) Error() string {
+	return fmt.Sprintf("%v: ErrDataAnomaly (symbol:%v, event:%v, time:%v). %v", e.Caller, e.Symbol, e.Event,
+		e.Time, e.Message)
+
+}
+
+func newErrDataAnomaly(e event, message string, caller string) *ErrDataAnomaly {
+	return &ErrDataAnomaly{
+		Symbol:  e.getSymbol(),
+		Event:   e.getName(),
+		Time:    e.getTime(),
+		Message: message,
+		Caller:  caller,
+	}
+}
+
+//ErrCode is stable machine readable kind of engine error. Codes are grouped by category, which is the part
+//of the code before dot. ErrCode is error itself, so errors.Is(err, ErrCodeInvalidOrder) or
+//errors.Is(err, ErrCategoryOrder) can be used with errors from errChan and with errors wrapping them.
+type ErrCode string
+
+const (
+	ErrCategoryMarketData ErrCode = "marketdata"
+	ErrCategoryOrder      ErrCode = "order"
+	ErrCategoryStrategy   ErrCode = "strategy"
+	ErrCategoryEngine     ErrCode = "engine"
+	ErrCategoryProvider   ErrCode = "provider"
+
+	ErrCodeUnknown              ErrCode = "unknown"
+	ErrCodeBrokenTick           ErrCode = "marketdata.broken_tick"
+	ErrCodeDataAnomaly          ErrCode = "marketdata.anomaly"
+	ErrCodeInvalidRequestPrice  ErrCode = "order.invalid_request_price"
+	ErrCodeInvalidOrder         ErrCode = "order.invalid"
+	ErrCodeUnknownOrderSide     ErrCode = "order.unknown_side"
+	ErrCodeUnknownOrderType     ErrCode = "order.unknown_type"
+	ErrCodeUnexpectedOrderType  ErrCode = "order.unexpected_type"
+	ErrCodeUnexpectedOrderState ErrCode = "order.unexpected_state"
+	ErrCodeOrderNotFound        ErrCode = "order.not_found"
+	ErrCodeOrderIdIncorrect     ErrCode = "order.incorrect_id"
+	ErrCodeStrategyPanic        ErrCode = "strategy.panic"
+	ErrCodeInvalidTransition    ErrCode = "engine.invalid_transition"
+	ErrCodeNotConnected         ErrCode = "engine.not_connected"
+	ErrCodeNoStrategyFactory    ErrCode = "engine.no_strategy_factory"
+	ErrCodeProviderNotSupported ErrCode = "provider.not_supported"
+	ErrCodeProviderResponse     ErrCode = "provider.response"
+)
+
+func (c ErrCode) Error() string {
+	return string(c)
+}
+
+//Category returns category of the code, e.g. "order" for "order.invalid"
+func (c ErrCode) Category() ErrCode {
+	if i := strings.Index(string(c), "."); i >= 0 {
+		return c[:i]
+	}
+	return c
+}
+
+//ICodedError is implemented by all errors of the engine
+type ICodedError interface {
+	error
+	Code() ErrCode
+}
+
+//ErrorCode returns code of the first engine error in the chain of wrapped errors. It's ErrCodeUnknown for
+//other errors.
+func ErrorCode(err error) ErrCode {
+	var ce ICodedError
+	if errors.As(err, &ce) {
+		return ce.Code()
+	}
+	return ErrCodeUnknown
+}
+
+//isCode is Is method of engine errors. Error matches its code and category of its code.
+func isCode(code ErrCode, target error) bool {
+	c, ok := target.(ErrCode)
+	return ok && (c == code || c == code.Category())
+}
+
+//EngineError is engine error without specific fields. Err is wrapped cause.
+type EngineError struct {
+	Kind    ErrCode
+	Message string
+	Caller  string
+	Err     error
+}
+
+//WrapError wraps err with code, so callers can branch on it. It returns nil for nil err.
+func WrapError(code ErrCode, caller string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &EngineError{Kind: code, Caller: caller, Err: err}
+}
+
+func (e *EngineError) Error() string {
+	s := e.Message
+	if e.Err != nil {
+		if s != "" {
+			s += ": "
+		}
+		s += e.Err.Error()
+	}
+	if e.Caller != "" {
+		s = e.Caller + ": " + s
+	}
+	return s
+}
+
+func (e *EngineError) Unwrap() error {
+	return e.Err
+}
+
+func (e *EngineError) Code() ErrCode {
+	return e.Kind
+}
+
+func (e *EngineError) Is(target error) bool {
+	return isCode(e.Kind, target)
+}
+
+func (e *ErrBrokenTick) Code() ErrCode {
+	return ErrCodeBrokenTick
+}
+
+func (e *ErrBrokenTick) Is(target error) bool {
+	return isCode(e.Code(), target)
+}
+
+func (e *ErrInvalidRequestPrice) Code() ErrCode {
+	return ErrCodeInvalidRequestPrice
+}
+
+func (e *ErrInvalidRequestPrice) Is(target error) bool {
+	return isCode(e.Code(), target)
+}
+
+func (e *ErrInvalidOrder) Code() ErrCode {
+	return ErrCodeInvalidOrder
+}
+
+func (e *ErrInvalidOrder) Is(target error) bool {
+	return isCode(e.Code(), target)
+}
+
+func (e *ErrUnknownOrderSide) Code() ErrCode {
+	return ErrCodeUnknownOrderSide
+}
+
+func (e *ErrUnknownOrderSide) Is(target error) bool {
+	return isCode(e.Code(), target)
+}
+
+func (e *ErrUnknownOrderType) Code() ErrCode {
+	return ErrCodeUnknownOrderType
+}
+
+func (e *ErrUnknownOrderType) Is(target error) bool {
+	return isCode(e.Code(), target)
+}
+
+func (e *ErrUnexpectedOrderType) Code() ErrCode {
+	return ErrCodeUnexpectedOrderType
+}
+
+func (e *ErrUnexpectedOrderType) Is(target error) bool {
+	return isCode(e.Code(), target)
+}
+
+func (e *ErrUnexpectedOrderState) Code() ErrCode {
+	return ErrCodeUnexpectedOrderState
+}
+
+func (e *ErrUnexpectedOrderState) Is(target error) bool {
+	return isCode(e.Code(), target)
+}
+
+//Code of ErrOrderNotFoundInOrdersMap is code of ErrOrderNotFoundInConfirmedMap as well
+func (e *ErrOrderNotFoundInOrdersMap) Code() ErrCode {
+	return ErrCodeOrderNotFound
+}
+
+func (e *ErrOrderNotFoundInOrdersMap) Is(target error) bool {
+	return isCode(e.Code(), target)
+}
+
+//As makes errors.As find ErrOrderNotFoundInOrdersMap in ErrOrderNotFoundInConfirmedMap
+func (e *ErrOrderNotFoundInConfirmedMap) As(target interface{}) bool {
+	if t, ok := target.(**ErrOrderNotFoundInOrdersMap); ok {
+		*t = &e.ErrOrderNotFoundInOrdersMap
+		return true
+	}
+	return false
+}
+
+func (e *ErrOrderIdIncorrect) Code() ErrCode {
+	return ErrCodeOrderIdIncorrect
+}
+
+func (e *ErrOrderIdIncorrect) Is(target error) bool {
+	return isCode(e.Code(), target)
+}
+
+func (e *ErrDataAnomaly) Code() ErrCode {
+	return ErrCodeDataAnomaly
+}
+
+func (e *ErrDataAnomaly) Is(target error) bool {
+	return isCode(e.Code(), target)
+}
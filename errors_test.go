@@ -0,0 +1,81 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorCodes(t *testing.T) {
+	cases := []struct {
+		err  ICodedError
+		code ErrCode
+	}{
+		{&ErrBrokenTick{}, ErrCodeBrokenTick},
+		{&ErrInvalidRequestPrice{}, ErrCodeInvalidRequestPrice},
+		{&ErrInvalidOrder{}, ErrCodeInvalidOrder},
+		{&ErrUnknownOrderSide{}, ErrCodeUnknownOrderSide},
+		{&ErrUnknownOrderType{}, ErrCodeUnknownOrderType},
+		{&ErrUnexpectedOrderType{}, ErrCodeUnexpectedOrderType},
+		{&ErrUnexpectedOrderState{}, ErrCodeUnexpectedOrderState},
+		{&ErrOrderNotFoundInOrdersMap{}, ErrCodeOrderNotFound},
+		{&ErrOrderNotFoundInConfirmedMap{}, ErrCodeOrderNotFound},
+		{&ErrOrderIdIncorrect{}, ErrCodeOrderIdIncorrect},
+		{&ErrDataAnomaly{}, ErrCodeDataAnomaly},
+		{&ErrProviderNotSupported{}, ErrCodeProviderNotSupported},
+		{&ErrInvalidTransition{}, ErrCodeInvalidTransition},
+		{&EngineError{Kind: ErrCodeStrategyPanic}, ErrCodeStrategyPanic},
+	}
+	for _, tc := range cases {
+		t.Run(string(tc.code), func(t *testing.T) {
+			assert.Equal(t, tc.code, tc.err.Code())
+			wrapped := fmt.Errorf("Run failed: %w", tc.err)
+			assert.Equal(t, tc.code, ErrorCode(wrapped))
+			assert.True(t, errors.Is(wrapped, tc.code))
+			assert.True(t, errors.Is(wrapped, tc.code.Category()))
+			assert.False(t, errors.Is(wrapped, ErrCodeUnknown))
+		})
+	}
+	assert.Equal(t, ErrCodeUnknown, ErrorCode(errors.New("Connection lost")))
+	assert.Equal(t, ErrCodeUnknown, ErrorCode(nil))
+}
+
+func TestErrorCategory(t *testing.T) {
+	assert.Equal(t, ErrCategoryOrder, ErrCodeInvalidOrder.Category())
+	assert.Equal(t, ErrCategoryMarketData, ErrCodeBrokenTick.Category())
+	assert.Equal(t, ErrCodeUnknown, ErrCodeUnknown.Category())
+
+	err := &ErrInvalidOrder{OrdId: "1"}
+	assert.True(t, errors.Is(err, ErrCategoryOrder))
+	assert.False(t, errors.Is(err, ErrCategoryMarketData))
+	assert.False(t, errors.Is(err, ErrCodeUnknownOrderSide))
+}
+
+func TestErrOrderNotFoundInConfirmedMap_As(t *testing.T) {
+	err := &ErrOrderNotFoundInConfirmedMap{ErrOrderNotFoundInOrdersMap{OrdId: "1", Caller: "Trade"}}
+	var notFound *ErrOrderNotFoundInOrdersMap
+	if assert.True(t, errors.As(fmt.Errorf("%w", err), &notFound)) {
+		assert.Equal(t, "1", notFound.OrdId)
+	}
+}
+
+func TestWrapError(t *testing.T) {
+	assert.Nil(t, WrapError(ErrCodeNotConnected, "Broker", nil))
+
+	cause := errors.New("Connection refused")
+	err := WrapError(ErrCodeNotConnected, "Broker", cause)
+	assert.EqualError(t, err, "Broker: Connection refused")
+	assert.True(t, errors.Is(err, cause))
+	assert.True(t, errors.Is(err, ErrCodeNotConnected))
+	assert.True(t, errors.Is(err, ErrCategoryEngine))
+
+	//Code of the outer error wins
+	err = WrapError(ErrCodeNotConnected, "", &ErrBrokenTick{})
+	assert.Equal(t, ErrCodeNotConnected, ErrorCode(err))
+	assert.True(t, errors.Is(err, ErrCodeBrokenTick))
+
+	err = &EngineError{Kind: ErrCodeNotConnected, Message: "Market data is not connected. "}
+	assert.EqualError(t, err, "Market data is not connected. ")
+}
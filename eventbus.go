@@ -0,0 +1,130 @@
+package engine
+
+import (
+	"sort"
+	"sync"
+)
+
+//Event is event which passes through the engine. Subscribers use type switch to get concrete event.
+type Event interface {
+	event
+}
+
+//EventHandler is called by bus for every event matching subscription filter. It's called synchronously in
+//goroutine of the publisher, so it shouldn't block or modify event.
+type EventHandler func(e Event)
+
+//EventFilter selects events of subscription. Types are names of event types, e.g. "OrderFillEvent". Empty
+//types or symbols match all events.
+type EventFilter struct {
+	Types   []string
+	Symbols []string
+}
+
+type subscription struct {
+	id      int
+	types   map[string]struct{}
+	symbols map[string]struct{}
+	handler EventHandler
+}
+
+func toSet(items []string) map[string]struct{} {
+	if len(items) == 0 {
+		return nil
+	}
+	set := make(map[string]struct{}, len(items))
+	for _, i := range items {
+		set[i] = struct{}{}
+	}
+	return set
+}
+
+func (s *subscription) match(e event) bool {
+	if s.types != nil {
+		if _, ok := s.types[eventTypeName(e)]; !ok {
+			return false
+		}
+	}
+	if s.symbols != nil {
+		if _, ok := s.symbols[e.getSymbol()]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+//EventBus delivers events of the engine to subscribers, so recorders, metrics or UI can observe market data,
+//requests of strategies and broker answers without changes of broker or strategy code
+type EventBus struct {
+	mut    sync.RWMutex
+	subs   []*subscription
+	nextID int
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+//Subscribe adds handler of events matching the filter and returns id of subscription. Handlers get events in
+//order of subscription.
+func (b *EventBus) Subscribe(f EventFilter, h EventHandler) int {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	b.nextID++
+	b.subs = append(b.subs, &subscription{
+		id:      b.nextID,
+		types:   toSet(f.Types),
+		symbols: toSet(f.Symbols),
+		handler: h,
+	})
+	return b.nextID
+}
+
+//Unsubscribe removes subscription. It returns false if subscription is not found.
+func (b *EventBus) Unsubscribe(id int) bool {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	i := sort.Search(len(b.subs), func(i int) bool { return b.subs[i].id >= id })
+	if i == len(b.subs) || b.subs[i].id != id {
+		return false
+	}
+	b.subs = append(b.subs[:i:i], b.subs[i+1:]...)
+	return true
+}
+
+//Publish passes event to handlers of matching subscriptions
+func (b *EventBus) Publish(e Event) {
+	if b == nil || e == nil {
+		return
+	}
+	b.mut.RLock()
+	subs := b.subs
+	b.mut.RUnlock()
+	for _, s := range subs {
+		if s.match(e) {
+			s.handler(e)
+		}
+	}
+}
+
+//isMarketDataEvent returns true for events produced by market data. Simulated broker passes them back with
+//its events, engine doesn't publish them twice.
+func isMarketDataEvent(e event) bool {
+	switch e.(type) {
+	case *CandleOpenEvent, *CandleCloseEvent, *CandlesHistoryEvent, *NewTickEvent, *TickHistoryEvent,
+		*TimerTickEvent, *DataGapEvent, *BacktestProgressEvent, *RollEvent, *OptionChainEvent,
+		*FundamentalDataEvent, *DividendEvent, *NewsEvent, *SymbolAddedEvent, *SymbolRemovedEvent,
+		*EndOfDataEvent, *AuctionImbalanceEvent, *DelistingEvent:
+		return true
+	}
+	return false
+}
+
+//EventBus returns bus with all market data events and events between strategies and broker. Subscribe before
+//the run, so no events are missed.
+func (c *Engine) EventBus() *EventBus {
+	if c.bus == nil {
+		c.bus = NewEventBus()
+	}
+	return c.bus
+}
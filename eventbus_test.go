@@ -0,0 +1,58 @@
+package engine
+
+import (
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_Subscribe(t *testing.T) {
+	bus := NewEventBus()
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	spy := &Instrument{Symbol: "SPY"}
+	qqq := &Instrument{Symbol: "QQQ"}
+
+	var all, fills, spyFills []string
+	bus.Subscribe(EventFilter{}, func(e Event) {
+		all = append(all, e.getName())
+	})
+	fillsID := bus.Subscribe(EventFilter{Types: []string{"OrderFillEvent"}}, func(e Event) {
+		fills = append(fills, e.getSymbol())
+	})
+	bus.Subscribe(EventFilter{Types: []string{"OrderFillEvent"}, Symbols: []string{"SPY"}}, func(e Event) {
+		spyFills = append(spyFills, e.(*OrderFillEvent).OrdId)
+	})
+
+	bus.Publish(&NewTickEvent{BaseEvent: be(tm, spy), Tick: &Tick{}})
+	bus.Publish(&OrderFillEvent{BaseEvent: be(tm, spy), OrdId: "1"})
+	bus.Publish(&OrderFillEvent{BaseEvent: be(tm, qqq), OrdId: "2"})
+
+	assert.Equal(t, []string{"NewTickEvent", "OrderFillEvent", "OrderFillEvent"}, all)
+	assert.Equal(t, []string{"SPY", "QQQ"}, fills)
+	assert.Equal(t, []string{"1"}, spyFills)
+
+	assert.True(t, bus.Unsubscribe(fillsID))
+	assert.False(t, bus.Unsubscribe(fillsID))
+	bus.Publish(&OrderFillEvent{BaseEvent: be(tm, spy), OrdId: "3"})
+	assert.Equal(t, []string{"SPY", "QQQ"}, fills)
+	assert.Equal(t, []string{"1", "3"}, spyFills)
+	assert.Len(t, all, 4)
+}
+
+func TestEngine_EventBus(t *testing.T) {
+	c := Engine{waitG: &sync.WaitGroup{}}
+	c.log.SetOutput(ioutil.Discard)
+	//Engine without subscribers doesn't fail
+	c.proxyEvent(&StrategyHaltedEvent{BaseEvent: be(time.Now(), newTestInstrument())})
+
+	var got []Event
+	c.EventBus().Subscribe(EventFilter{Types: []string{"StrategyHaltedEvent"}}, func(e Event) {
+		got = append(got, e)
+	})
+	e := &StrategyHaltedEvent{BaseEvent: be(time.Now(), newTestInstrument()), Reason: "Test"}
+	c.proxyEvent(e)
+	assert.Equal(t, []Event{e}, got)
+}
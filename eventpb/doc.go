@@ -0,0 +1,5 @@
+//Package eventpb has protobuf messages of engine events. Engine converts events to them and back with
+//EventToProto and EventFromProto.
+package eventpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative events.proto
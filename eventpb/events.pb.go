@@ -0,0 +1,5142 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: events.proto
+
+//Messages of engine events. Every registered event type has message with the same name, fields are fields of the
+//Go type in snake case. Event is envelope with exactly one of them.
+
+package eventpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type TimeOfDay struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hour          int64                  `protobuf:"varint,1,opt,name=hour,proto3" json:"hour,omitempty"`
+	Minute        int64                  `protobuf:"varint,2,opt,name=minute,proto3" json:"minute,omitempty"`
+	Second        int64                  `protobuf:"varint,3,opt,name=second,proto3" json:"second,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TimeOfDay) Reset() {
+	*x = TimeOfDay{}
+	mi := &file_events_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TimeOfDay) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TimeOfDay) ProtoMessage() {}
+
+func (x *TimeOfDay) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TimeOfDay.ProtoReflect.Descriptor instead.
+func (*TimeOfDay) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TimeOfDay) GetHour() int64 {
+	if x != nil {
+		return x.Hour
+	}
+	return 0
+}
+
+func (x *TimeOfDay) GetMinute() int64 {
+	if x != nil {
+		return x.Minute
+	}
+	return 0
+}
+
+func (x *TimeOfDay) GetSecond() int64 {
+	if x != nil {
+		return x.Second
+	}
+	return 0
+}
+
+// Exchange is written without trading calendar
+type Exchange struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Name            string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	MarketOpenTime  *TimeOfDay             `protobuf:"bytes,2,opt,name=market_open_time,json=marketOpenTime,proto3" json:"market_open_time,omitempty"`
+	MarketCloseTime *TimeOfDay             `protobuf:"bytes,3,opt,name=market_close_time,json=marketCloseTime,proto3" json:"market_close_time,omitempty"`
+	Location        string                 `protobuf:"bytes,4,opt,name=location,proto3" json:"location,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Exchange) Reset() {
+	*x = Exchange{}
+	mi := &file_events_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Exchange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Exchange) ProtoMessage() {}
+
+func (x *Exchange) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Exchange.ProtoReflect.Descriptor instead.
+func (*Exchange) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Exchange) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Exchange) GetMarketOpenTime() *TimeOfDay {
+	if x != nil {
+		return x.MarketOpenTime
+	}
+	return nil
+}
+
+func (x *Exchange) GetMarketCloseTime() *TimeOfDay {
+	if x != nil {
+		return x.MarketCloseTime
+	}
+	return nil
+}
+
+func (x *Exchange) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+type OptionSpec struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Underlying    *Instrument            `protobuf:"bytes,1,opt,name=underlying,proto3" json:"underlying,omitempty"`
+	Strike        float64                `protobuf:"fixed64,2,opt,name=strike,proto3" json:"strike,omitempty"`
+	Expiry        *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=expiry,proto3" json:"expiry,omitempty"`
+	Right         string                 `protobuf:"bytes,4,opt,name=right,proto3" json:"right,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OptionSpec) Reset() {
+	*x = OptionSpec{}
+	mi := &file_events_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OptionSpec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OptionSpec) ProtoMessage() {}
+
+func (x *OptionSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OptionSpec.ProtoReflect.Descriptor instead.
+func (*OptionSpec) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *OptionSpec) GetUnderlying() *Instrument {
+	if x != nil {
+		return x.Underlying
+	}
+	return nil
+}
+
+func (x *OptionSpec) GetStrike() float64 {
+	if x != nil {
+		return x.Strike
+	}
+	return 0
+}
+
+func (x *OptionSpec) GetExpiry() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Expiry
+	}
+	return nil
+}
+
+func (x *OptionSpec) GetRight() string {
+	if x != nil {
+		return x.Right
+	}
+	return ""
+}
+
+type FXSpec struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Base          string                 `protobuf:"bytes,1,opt,name=base,proto3" json:"base,omitempty"`
+	Quote         string                 `protobuf:"bytes,2,opt,name=quote,proto3" json:"quote,omitempty"`
+	PipSize       float64                `protobuf:"fixed64,3,opt,name=pip_size,json=pipSize,proto3" json:"pip_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FXSpec) Reset() {
+	*x = FXSpec{}
+	mi := &file_events_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FXSpec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FXSpec) ProtoMessage() {}
+
+func (x *FXSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FXSpec.ProtoReflect.Descriptor instead.
+func (*FXSpec) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *FXSpec) GetBase() string {
+	if x != nil {
+		return x.Base
+	}
+	return ""
+}
+
+func (x *FXSpec) GetQuote() string {
+	if x != nil {
+		return x.Quote
+	}
+	return ""
+}
+
+func (x *FXSpec) GetPipSize() float64 {
+	if x != nil {
+		return x.PipSize
+	}
+	return 0
+}
+
+type Instrument struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Symbol            string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Exchange          *Exchange              `protobuf:"bytes,2,opt,name=exchange,proto3" json:"exchange,omitempty"`
+	MinTick           float64                `protobuf:"fixed64,3,opt,name=min_tick,json=minTick,proto3" json:"min_tick,omitempty"`
+	LotSize           int64                  `protobuf:"varint,4,opt,name=lot_size,json=lotSize,proto3" json:"lot_size,omitempty"`
+	DataTimeZone      string                 `protobuf:"bytes,5,opt,name=data_time_zone,json=dataTimeZone,proto3" json:"data_time_zone,omitempty"`
+	Multiplier        float64                `protobuf:"fixed64,6,opt,name=multiplier,proto3" json:"multiplier,omitempty"`
+	QtyPrecision      int64                  `protobuf:"varint,7,opt,name=qty_precision,json=qtyPrecision,proto3" json:"qty_precision,omitempty"`
+	InitialMargin     float64                `protobuf:"fixed64,8,opt,name=initial_margin,json=initialMargin,proto3" json:"initial_margin,omitempty"`
+	MaintenanceMargin float64                `protobuf:"fixed64,9,opt,name=maintenance_margin,json=maintenanceMargin,proto3" json:"maintenance_margin,omitempty"`
+	Sector            string                 `protobuf:"bytes,10,opt,name=sector,proto3" json:"sector,omitempty"`
+	Option            *OptionSpec            `protobuf:"bytes,11,opt,name=option,proto3" json:"option,omitempty"`
+	Fx                *FXSpec                `protobuf:"bytes,12,opt,name=fx,proto3" json:"fx,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Instrument) Reset() {
+	*x = Instrument{}
+	mi := &file_events_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Instrument) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Instrument) ProtoMessage() {}
+
+func (x *Instrument) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Instrument.ProtoReflect.Descriptor instead.
+func (*Instrument) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Instrument) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *Instrument) GetExchange() *Exchange {
+	if x != nil {
+		return x.Exchange
+	}
+	return nil
+}
+
+func (x *Instrument) GetMinTick() float64 {
+	if x != nil {
+		return x.MinTick
+	}
+	return 0
+}
+
+func (x *Instrument) GetLotSize() int64 {
+	if x != nil {
+		return x.LotSize
+	}
+	return 0
+}
+
+func (x *Instrument) GetDataTimeZone() string {
+	if x != nil {
+		return x.DataTimeZone
+	}
+	return ""
+}
+
+func (x *Instrument) GetMultiplier() float64 {
+	if x != nil {
+		return x.Multiplier
+	}
+	return 0
+}
+
+func (x *Instrument) GetQtyPrecision() int64 {
+	if x != nil {
+		return x.QtyPrecision
+	}
+	return 0
+}
+
+func (x *Instrument) GetInitialMargin() float64 {
+	if x != nil {
+		return x.InitialMargin
+	}
+	return 0
+}
+
+func (x *Instrument) GetMaintenanceMargin() float64 {
+	if x != nil {
+		return x.MaintenanceMargin
+	}
+	return 0
+}
+
+func (x *Instrument) GetSector() string {
+	if x != nil {
+		return x.Sector
+	}
+	return ""
+}
+
+func (x *Instrument) GetOption() *OptionSpec {
+	if x != nil {
+		return x.Option
+	}
+	return nil
+}
+
+func (x *Instrument) GetFx() *FXSpec {
+	if x != nil {
+		return x.Fx
+	}
+	return nil
+}
+
+type BaseEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	Ticker        *Instrument            `protobuf:"bytes,2,opt,name=ticker,proto3" json:"ticker,omitempty"`
+	Seq           uint64                 `protobuf:"varint,3,opt,name=seq,proto3" json:"seq,omitempty"`
+	CorrelationId string                 `protobuf:"bytes,4,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BaseEvent) Reset() {
+	*x = BaseEvent{}
+	mi := &file_events_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BaseEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BaseEvent) ProtoMessage() {}
+
+func (x *BaseEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BaseEvent.ProtoReflect.Descriptor instead.
+func (*BaseEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *BaseEvent) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *BaseEvent) GetTicker() *Instrument {
+	if x != nil {
+		return x.Ticker
+	}
+	return nil
+}
+
+func (x *BaseEvent) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *BaseEvent) GetCorrelationId() string {
+	if x != nil {
+		return x.CorrelationId
+	}
+	return ""
+}
+
+// MarketDataCandle is candle of market data package
+type MarketDataCandle struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Datetime      *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=datetime,proto3" json:"datetime,omitempty"`
+	Symbol        string                 `protobuf:"bytes,2,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Open          float64                `protobuf:"fixed64,3,opt,name=open,proto3" json:"open,omitempty"`
+	High          float64                `protobuf:"fixed64,4,opt,name=high,proto3" json:"high,omitempty"`
+	Low           float64                `protobuf:"fixed64,5,opt,name=low,proto3" json:"low,omitempty"`
+	Close         float64                `protobuf:"fixed64,6,opt,name=close,proto3" json:"close,omitempty"`
+	AdjClose      float64                `protobuf:"fixed64,7,opt,name=adj_close,json=adjClose,proto3" json:"adj_close,omitempty"`
+	Volume        int64                  `protobuf:"varint,8,opt,name=volume,proto3" json:"volume,omitempty"`
+	OpenInterest  int64                  `protobuf:"varint,9,opt,name=open_interest,json=openInterest,proto3" json:"open_interest,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MarketDataCandle) Reset() {
+	*x = MarketDataCandle{}
+	mi := &file_events_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MarketDataCandle) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MarketDataCandle) ProtoMessage() {}
+
+func (x *MarketDataCandle) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MarketDataCandle.ProtoReflect.Descriptor instead.
+func (*MarketDataCandle) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *MarketDataCandle) GetDatetime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Datetime
+	}
+	return nil
+}
+
+func (x *MarketDataCandle) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *MarketDataCandle) GetOpen() float64 {
+	if x != nil {
+		return x.Open
+	}
+	return 0
+}
+
+func (x *MarketDataCandle) GetHigh() float64 {
+	if x != nil {
+		return x.High
+	}
+	return 0
+}
+
+func (x *MarketDataCandle) GetLow() float64 {
+	if x != nil {
+		return x.Low
+	}
+	return 0
+}
+
+func (x *MarketDataCandle) GetClose() float64 {
+	if x != nil {
+		return x.Close
+	}
+	return 0
+}
+
+func (x *MarketDataCandle) GetAdjClose() float64 {
+	if x != nil {
+		return x.AdjClose
+	}
+	return 0
+}
+
+func (x *MarketDataCandle) GetVolume() int64 {
+	if x != nil {
+		return x.Volume
+	}
+	return 0
+}
+
+func (x *MarketDataCandle) GetOpenInterest() int64 {
+	if x != nil {
+		return x.OpenInterest
+	}
+	return 0
+}
+
+type Candle struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Candle        *MarketDataCandle      `protobuf:"bytes,1,opt,name=candle,proto3" json:"candle,omitempty"`
+	Ticker        *Instrument            `protobuf:"bytes,2,opt,name=ticker,proto3" json:"ticker,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Candle) Reset() {
+	*x = Candle{}
+	mi := &file_events_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Candle) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Candle) ProtoMessage() {}
+
+func (x *Candle) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Candle.ProtoReflect.Descriptor instead.
+func (*Candle) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Candle) GetCandle() *MarketDataCandle {
+	if x != nil {
+		return x.Candle
+	}
+	return nil
+}
+
+func (x *Candle) GetTicker() *Instrument {
+	if x != nil {
+		return x.Ticker
+	}
+	return nil
+}
+
+// MarketDataTick is tick of market data package
+type MarketDataTick struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Datetime      *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=datetime,proto3" json:"datetime,omitempty"`
+	Symbol        string                 `protobuf:"bytes,2,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	LastPrice     float64                `protobuf:"fixed64,3,opt,name=last_price,json=lastPrice,proto3" json:"last_price,omitempty"`
+	LastSize      int64                  `protobuf:"varint,4,opt,name=last_size,json=lastSize,proto3" json:"last_size,omitempty"`
+	LastExch      string                 `protobuf:"bytes,5,opt,name=last_exch,json=lastExch,proto3" json:"last_exch,omitempty"`
+	BidPrice      float64                `protobuf:"fixed64,6,opt,name=bid_price,json=bidPrice,proto3" json:"bid_price,omitempty"`
+	BidSize       int64                  `protobuf:"varint,7,opt,name=bid_size,json=bidSize,proto3" json:"bid_size,omitempty"`
+	BidExch       string                 `protobuf:"bytes,8,opt,name=bid_exch,json=bidExch,proto3" json:"bid_exch,omitempty"`
+	AskPrice      float64                `protobuf:"fixed64,9,opt,name=ask_price,json=askPrice,proto3" json:"ask_price,omitempty"`
+	AskSize       int64                  `protobuf:"varint,10,opt,name=ask_size,json=askSize,proto3" json:"ask_size,omitempty"`
+	AskExch       string                 `protobuf:"bytes,11,opt,name=ask_exch,json=askExch,proto3" json:"ask_exch,omitempty"`
+	CondQuote     string                 `protobuf:"bytes,12,opt,name=cond_quote,json=condQuote,proto3" json:"cond_quote,omitempty"`
+	Cond1         string                 `protobuf:"bytes,13,opt,name=cond1,proto3" json:"cond1,omitempty"`
+	Cond2         string                 `protobuf:"bytes,14,opt,name=cond2,proto3" json:"cond2,omitempty"`
+	Cond3         string                 `protobuf:"bytes,15,opt,name=cond3,proto3" json:"cond3,omitempty"`
+	Cond4         string                 `protobuf:"bytes,16,opt,name=cond4,proto3" json:"cond4,omitempty"`
+	IsOpening     bool                   `protobuf:"varint,17,opt,name=is_opening,json=isOpening,proto3" json:"is_opening,omitempty"`
+	IsClosing     bool                   `protobuf:"varint,18,opt,name=is_closing,json=isClosing,proto3" json:"is_closing,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MarketDataTick) Reset() {
+	*x = MarketDataTick{}
+	mi := &file_events_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MarketDataTick) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MarketDataTick) ProtoMessage() {}
+
+func (x *MarketDataTick) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MarketDataTick.ProtoReflect.Descriptor instead.
+func (*MarketDataTick) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *MarketDataTick) GetDatetime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Datetime
+	}
+	return nil
+}
+
+func (x *MarketDataTick) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *MarketDataTick) GetLastPrice() float64 {
+	if x != nil {
+		return x.LastPrice
+	}
+	return 0
+}
+
+func (x *MarketDataTick) GetLastSize() int64 {
+	if x != nil {
+		return x.LastSize
+	}
+	return 0
+}
+
+func (x *MarketDataTick) GetLastExch() string {
+	if x != nil {
+		return x.LastExch
+	}
+	return ""
+}
+
+func (x *MarketDataTick) GetBidPrice() float64 {
+	if x != nil {
+		return x.BidPrice
+	}
+	return 0
+}
+
+func (x *MarketDataTick) GetBidSize() int64 {
+	if x != nil {
+		return x.BidSize
+	}
+	return 0
+}
+
+func (x *MarketDataTick) GetBidExch() string {
+	if x != nil {
+		return x.BidExch
+	}
+	return ""
+}
+
+func (x *MarketDataTick) GetAskPrice() float64 {
+	if x != nil {
+		return x.AskPrice
+	}
+	return 0
+}
+
+func (x *MarketDataTick) GetAskSize() int64 {
+	if x != nil {
+		return x.AskSize
+	}
+	return 0
+}
+
+func (x *MarketDataTick) GetAskExch() string {
+	if x != nil {
+		return x.AskExch
+	}
+	return ""
+}
+
+func (x *MarketDataTick) GetCondQuote() string {
+	if x != nil {
+		return x.CondQuote
+	}
+	return ""
+}
+
+func (x *MarketDataTick) GetCond1() string {
+	if x != nil {
+		return x.Cond1
+	}
+	return ""
+}
+
+func (x *MarketDataTick) GetCond2() string {
+	if x != nil {
+		return x.Cond2
+	}
+	return ""
+}
+
+func (x *MarketDataTick) GetCond3() string {
+	if x != nil {
+		return x.Cond3
+	}
+	return ""
+}
+
+func (x *MarketDataTick) GetCond4() string {
+	if x != nil {
+		return x.Cond4
+	}
+	return ""
+}
+
+func (x *MarketDataTick) GetIsOpening() bool {
+	if x != nil {
+		return x.IsOpening
+	}
+	return false
+}
+
+func (x *MarketDataTick) GetIsClosing() bool {
+	if x != nil {
+		return x.IsClosing
+	}
+	return false
+}
+
+type Tick struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tick          *MarketDataTick        `protobuf:"bytes,1,opt,name=tick,proto3" json:"tick,omitempty"`
+	Ticker        *Instrument            `protobuf:"bytes,2,opt,name=ticker,proto3" json:"ticker,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Tick) Reset() {
+	*x = Tick{}
+	mi := &file_events_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Tick) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Tick) ProtoMessage() {}
+
+func (x *Tick) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Tick.ProtoReflect.Descriptor instead.
+func (*Tick) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Tick) GetTick() *MarketDataTick {
+	if x != nil {
+		return x.Tick
+	}
+	return nil
+}
+
+func (x *Tick) GetTicker() *Instrument {
+	if x != nil {
+		return x.Ticker
+	}
+	return nil
+}
+
+type Order struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Side          string                 `protobuf:"bytes,1,opt,name=side,proto3" json:"side,omitempty"`
+	Qty           int64                  `protobuf:"varint,2,opt,name=qty,proto3" json:"qty,omitempty"`
+	ExecQty       int64                  `protobuf:"varint,3,opt,name=exec_qty,json=execQty,proto3" json:"exec_qty,omitempty"`
+	Ticker        *Instrument            `protobuf:"bytes,4,opt,name=ticker,proto3" json:"ticker,omitempty"`
+	State         string                 `protobuf:"bytes,5,opt,name=state,proto3" json:"state,omitempty"`
+	Price         float64                `protobuf:"fixed64,6,opt,name=price,proto3" json:"price,omitempty"`
+	ExecPrice     float64                `protobuf:"fixed64,7,opt,name=exec_price,json=execPrice,proto3" json:"exec_price,omitempty"`
+	Type          string                 `protobuf:"bytes,8,opt,name=type,proto3" json:"type,omitempty"`
+	Tif           string                 `protobuf:"bytes,9,opt,name=tif,proto3" json:"tif,omitempty"`
+	Destination   string                 `protobuf:"bytes,10,opt,name=destination,proto3" json:"destination,omitempty"`
+	Id            string                 `protobuf:"bytes,11,opt,name=id,proto3" json:"id,omitempty"`
+	Mark1         string                 `protobuf:"bytes,12,opt,name=mark1,proto3" json:"mark1,omitempty"`
+	Mark2         string                 `protobuf:"bytes,13,opt,name=mark2,proto3" json:"mark2,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=time,proto3" json:"time,omitempty"`
+	PositionSide  string                 `protobuf:"bytes,15,opt,name=position_side,json=positionSide,proto3" json:"position_side,omitempty"`
+	CorrelationId string                 `protobuf:"bytes,16,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Order) Reset() {
+	*x = Order{}
+	mi := &file_events_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Order) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Order) ProtoMessage() {}
+
+func (x *Order) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Order.ProtoReflect.Descriptor instead.
+func (*Order) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Order) GetSide() string {
+	if x != nil {
+		return x.Side
+	}
+	return ""
+}
+
+func (x *Order) GetQty() int64 {
+	if x != nil {
+		return x.Qty
+	}
+	return 0
+}
+
+func (x *Order) GetExecQty() int64 {
+	if x != nil {
+		return x.ExecQty
+	}
+	return 0
+}
+
+func (x *Order) GetTicker() *Instrument {
+	if x != nil {
+		return x.Ticker
+	}
+	return nil
+}
+
+func (x *Order) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *Order) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *Order) GetExecPrice() float64 {
+	if x != nil {
+		return x.ExecPrice
+	}
+	return 0
+}
+
+func (x *Order) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Order) GetTif() string {
+	if x != nil {
+		return x.Tif
+	}
+	return ""
+}
+
+func (x *Order) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+func (x *Order) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Order) GetMark1() string {
+	if x != nil {
+		return x.Mark1
+	}
+	return ""
+}
+
+func (x *Order) GetMark2() string {
+	if x != nil {
+		return x.Mark2
+	}
+	return ""
+}
+
+func (x *Order) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *Order) GetPositionSide() string {
+	if x != nil {
+		return x.PositionSide
+	}
+	return ""
+}
+
+func (x *Order) GetCorrelationId() string {
+	if x != nil {
+		return x.CorrelationId
+	}
+	return ""
+}
+
+type OptionQuote struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Option        *Instrument            `protobuf:"bytes,1,opt,name=option,proto3" json:"option,omitempty"`
+	Bid           float64                `protobuf:"fixed64,2,opt,name=bid,proto3" json:"bid,omitempty"`
+	BidSize       int64                  `protobuf:"varint,3,opt,name=bid_size,json=bidSize,proto3" json:"bid_size,omitempty"`
+	Ask           float64                `protobuf:"fixed64,4,opt,name=ask,proto3" json:"ask,omitempty"`
+	AskSize       int64                  `protobuf:"varint,5,opt,name=ask_size,json=askSize,proto3" json:"ask_size,omitempty"`
+	Last          float64                `protobuf:"fixed64,6,opt,name=last,proto3" json:"last,omitempty"`
+	Volume        int64                  `protobuf:"varint,7,opt,name=volume,proto3" json:"volume,omitempty"`
+	OpenInterest  int64                  `protobuf:"varint,8,opt,name=open_interest,json=openInterest,proto3" json:"open_interest,omitempty"`
+	ImpliedVol    float64                `protobuf:"fixed64,9,opt,name=implied_vol,json=impliedVol,proto3" json:"implied_vol,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OptionQuote) Reset() {
+	*x = OptionQuote{}
+	mi := &file_events_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OptionQuote) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OptionQuote) ProtoMessage() {}
+
+func (x *OptionQuote) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OptionQuote.ProtoReflect.Descriptor instead.
+func (*OptionQuote) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *OptionQuote) GetOption() *Instrument {
+	if x != nil {
+		return x.Option
+	}
+	return nil
+}
+
+func (x *OptionQuote) GetBid() float64 {
+	if x != nil {
+		return x.Bid
+	}
+	return 0
+}
+
+func (x *OptionQuote) GetBidSize() int64 {
+	if x != nil {
+		return x.BidSize
+	}
+	return 0
+}
+
+func (x *OptionQuote) GetAsk() float64 {
+	if x != nil {
+		return x.Ask
+	}
+	return 0
+}
+
+func (x *OptionQuote) GetAskSize() int64 {
+	if x != nil {
+		return x.AskSize
+	}
+	return 0
+}
+
+func (x *OptionQuote) GetLast() float64 {
+	if x != nil {
+		return x.Last
+	}
+	return 0
+}
+
+func (x *OptionQuote) GetVolume() int64 {
+	if x != nil {
+		return x.Volume
+	}
+	return 0
+}
+
+func (x *OptionQuote) GetOpenInterest() int64 {
+	if x != nil {
+		return x.OpenInterest
+	}
+	return 0
+}
+
+func (x *OptionQuote) GetImpliedVol() float64 {
+	if x != nil {
+		return x.ImpliedVol
+	}
+	return 0
+}
+
+type FillFees struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Commission    float64                `protobuf:"fixed64,1,opt,name=commission,proto3" json:"commission,omitempty"`
+	ExchangeFee   float64                `protobuf:"fixed64,2,opt,name=exchange_fee,json=exchangeFee,proto3" json:"exchange_fee,omitempty"`
+	SecFee        float64                `protobuf:"fixed64,3,opt,name=sec_fee,json=secFee,proto3" json:"sec_fee,omitempty"`
+	Taf           float64                `protobuf:"fixed64,4,opt,name=taf,proto3" json:"taf,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FillFees) Reset() {
+	*x = FillFees{}
+	mi := &file_events_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FillFees) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FillFees) ProtoMessage() {}
+
+func (x *FillFees) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FillFees.ProtoReflect.Descriptor instead.
+func (*FillFees) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *FillFees) GetCommission() float64 {
+	if x != nil {
+		return x.Commission
+	}
+	return 0
+}
+
+func (x *FillFees) GetExchangeFee() float64 {
+	if x != nil {
+		return x.ExchangeFee
+	}
+	return 0
+}
+
+func (x *FillFees) GetSecFee() float64 {
+	if x != nil {
+		return x.SecFee
+	}
+	return 0
+}
+
+func (x *FillFees) GetTaf() float64 {
+	if x != nil {
+		return x.Taf
+	}
+	return 0
+}
+
+type RunManifest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	EngineVersion   string                 `protobuf:"bytes,1,opt,name=engine_version,json=engineVersion,proto3" json:"engine_version,omitempty"`
+	GoVersion       string                 `protobuf:"bytes,2,opt,name=go_version,json=goVersion,proto3" json:"go_version,omitempty"`
+	ConfigHash      string                 `protobuf:"bytes,3,opt,name=config_hash,json=configHash,proto3" json:"config_hash,omitempty"`
+	DataFingerprint string                 `protobuf:"bytes,4,opt,name=data_fingerprint,json=dataFingerprint,proto3" json:"data_fingerprint,omitempty"`
+	Seeds           map[string]int64       `protobuf:"bytes,5,rep,name=seeds,proto3" json:"seeds,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	Created         *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created,proto3" json:"created,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *RunManifest) Reset() {
+	*x = RunManifest{}
+	mi := &file_events_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunManifest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunManifest) ProtoMessage() {}
+
+func (x *RunManifest) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunManifest.ProtoReflect.Descriptor instead.
+func (*RunManifest) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *RunManifest) GetEngineVersion() string {
+	if x != nil {
+		return x.EngineVersion
+	}
+	return ""
+}
+
+func (x *RunManifest) GetGoVersion() string {
+	if x != nil {
+		return x.GoVersion
+	}
+	return ""
+}
+
+func (x *RunManifest) GetConfigHash() string {
+	if x != nil {
+		return x.ConfigHash
+	}
+	return ""
+}
+
+func (x *RunManifest) GetDataFingerprint() string {
+	if x != nil {
+		return x.DataFingerprint
+	}
+	return ""
+}
+
+func (x *RunManifest) GetSeeds() map[string]int64 {
+	if x != nil {
+		return x.Seeds
+	}
+	return nil
+}
+
+func (x *RunManifest) GetCreated() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Created
+	}
+	return nil
+}
+
+type CandleOpenEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	CandleTime    *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=candle_time,json=candleTime,proto3" json:"candle_time,omitempty"`
+	Price         float64                `protobuf:"fixed64,3,opt,name=price,proto3" json:"price,omitempty"`
+	TimeFrame     string                 `protobuf:"bytes,4,opt,name=time_frame,json=timeFrame,proto3" json:"time_frame,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CandleOpenEvent) Reset() {
+	*x = CandleOpenEvent{}
+	mi := &file_events_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CandleOpenEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CandleOpenEvent) ProtoMessage() {}
+
+func (x *CandleOpenEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CandleOpenEvent.ProtoReflect.Descriptor instead.
+func (*CandleOpenEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *CandleOpenEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+func (x *CandleOpenEvent) GetCandleTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CandleTime
+	}
+	return nil
+}
+
+func (x *CandleOpenEvent) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *CandleOpenEvent) GetTimeFrame() string {
+	if x != nil {
+		return x.TimeFrame
+	}
+	return ""
+}
+
+type CandleCloseEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	Candle        *Candle                `protobuf:"bytes,2,opt,name=candle,proto3" json:"candle,omitempty"`
+	TimeFrame     string                 `protobuf:"bytes,3,opt,name=time_frame,json=timeFrame,proto3" json:"time_frame,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CandleCloseEvent) Reset() {
+	*x = CandleCloseEvent{}
+	mi := &file_events_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CandleCloseEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CandleCloseEvent) ProtoMessage() {}
+
+func (x *CandleCloseEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CandleCloseEvent.ProtoReflect.Descriptor instead.
+func (*CandleCloseEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *CandleCloseEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+func (x *CandleCloseEvent) GetCandle() *Candle {
+	if x != nil {
+		return x.Candle
+	}
+	return nil
+}
+
+func (x *CandleCloseEvent) GetTimeFrame() string {
+	if x != nil {
+		return x.TimeFrame
+	}
+	return ""
+}
+
+type CandlesHistoryEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	Candles       []*Candle              `protobuf:"bytes,2,rep,name=candles,proto3" json:"candles,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CandlesHistoryEvent) Reset() {
+	*x = CandlesHistoryEvent{}
+	mi := &file_events_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CandlesHistoryEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CandlesHistoryEvent) ProtoMessage() {}
+
+func (x *CandlesHistoryEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CandlesHistoryEvent.ProtoReflect.Descriptor instead.
+func (*CandlesHistoryEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *CandlesHistoryEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+func (x *CandlesHistoryEvent) GetCandles() []*Candle {
+	if x != nil {
+		return x.Candles
+	}
+	return nil
+}
+
+type NewTickEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	Tick          *Tick                  `protobuf:"bytes,2,opt,name=tick,proto3" json:"tick,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NewTickEvent) Reset() {
+	*x = NewTickEvent{}
+	mi := &file_events_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NewTickEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NewTickEvent) ProtoMessage() {}
+
+func (x *NewTickEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NewTickEvent.ProtoReflect.Descriptor instead.
+func (*NewTickEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *NewTickEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+func (x *NewTickEvent) GetTick() *Tick {
+	if x != nil {
+		return x.Tick
+	}
+	return nil
+}
+
+type TickHistoryEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	Ticks         []*Tick                `protobuf:"bytes,2,rep,name=ticks,proto3" json:"ticks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TickHistoryEvent) Reset() {
+	*x = TickHistoryEvent{}
+	mi := &file_events_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TickHistoryEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TickHistoryEvent) ProtoMessage() {}
+
+func (x *TickHistoryEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TickHistoryEvent.ProtoReflect.Descriptor instead.
+func (*TickHistoryEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *TickHistoryEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+func (x *TickHistoryEvent) GetTicks() []*Tick {
+	if x != nil {
+		return x.Ticks
+	}
+	return nil
+}
+
+type NewOrderEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	LinkedOrder   *Order                 `protobuf:"bytes,2,opt,name=linked_order,json=linkedOrder,proto3" json:"linked_order,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NewOrderEvent) Reset() {
+	*x = NewOrderEvent{}
+	mi := &file_events_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NewOrderEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NewOrderEvent) ProtoMessage() {}
+
+func (x *NewOrderEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NewOrderEvent.ProtoReflect.Descriptor instead.
+func (*NewOrderEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *NewOrderEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+func (x *NewOrderEvent) GetLinkedOrder() *Order {
+	if x != nil {
+		return x.LinkedOrder
+	}
+	return nil
+}
+
+type OrderConfirmationEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	OrdId         string                 `protobuf:"bytes,2,opt,name=ord_id,json=ordId,proto3" json:"ord_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderConfirmationEvent) Reset() {
+	*x = OrderConfirmationEvent{}
+	mi := &file_events_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderConfirmationEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderConfirmationEvent) ProtoMessage() {}
+
+func (x *OrderConfirmationEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderConfirmationEvent.ProtoReflect.Descriptor instead.
+func (*OrderConfirmationEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *OrderConfirmationEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+func (x *OrderConfirmationEvent) GetOrdId() string {
+	if x != nil {
+		return x.OrdId
+	}
+	return ""
+}
+
+type OrderFillEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	OrdId         string                 `protobuf:"bytes,2,opt,name=ord_id,json=ordId,proto3" json:"ord_id,omitempty"`
+	Price         float64                `protobuf:"fixed64,3,opt,name=price,proto3" json:"price,omitempty"`
+	Qty           int64                  `protobuf:"varint,4,opt,name=qty,proto3" json:"qty,omitempty"`
+	Commission    float64                `protobuf:"fixed64,5,opt,name=commission,proto3" json:"commission,omitempty"`
+	Slippage      float64                `protobuf:"fixed64,6,opt,name=slippage,proto3" json:"slippage,omitempty"`
+	Fees          *FillFees              `protobuf:"bytes,7,opt,name=fees,proto3" json:"fees,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderFillEvent) Reset() {
+	*x = OrderFillEvent{}
+	mi := &file_events_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderFillEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderFillEvent) ProtoMessage() {}
+
+func (x *OrderFillEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderFillEvent.ProtoReflect.Descriptor instead.
+func (*OrderFillEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *OrderFillEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+func (x *OrderFillEvent) GetOrdId() string {
+	if x != nil {
+		return x.OrdId
+	}
+	return ""
+}
+
+func (x *OrderFillEvent) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *OrderFillEvent) GetQty() int64 {
+	if x != nil {
+		return x.Qty
+	}
+	return 0
+}
+
+func (x *OrderFillEvent) GetCommission() float64 {
+	if x != nil {
+		return x.Commission
+	}
+	return 0
+}
+
+func (x *OrderFillEvent) GetSlippage() float64 {
+	if x != nil {
+		return x.Slippage
+	}
+	return 0
+}
+
+func (x *OrderFillEvent) GetFees() *FillFees {
+	if x != nil {
+		return x.Fees
+	}
+	return nil
+}
+
+type OrderCancelEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	OrdId         string                 `protobuf:"bytes,2,opt,name=ord_id,json=ordId,proto3" json:"ord_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderCancelEvent) Reset() {
+	*x = OrderCancelEvent{}
+	mi := &file_events_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderCancelEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderCancelEvent) ProtoMessage() {}
+
+func (x *OrderCancelEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderCancelEvent.ProtoReflect.Descriptor instead.
+func (*OrderCancelEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *OrderCancelEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+func (x *OrderCancelEvent) GetOrdId() string {
+	if x != nil {
+		return x.OrdId
+	}
+	return ""
+}
+
+type OrderCancelRejectEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	OrdId         string                 `protobuf:"bytes,2,opt,name=ord_id,json=ordId,proto3" json:"ord_id,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderCancelRejectEvent) Reset() {
+	*x = OrderCancelRejectEvent{}
+	mi := &file_events_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderCancelRejectEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderCancelRejectEvent) ProtoMessage() {}
+
+func (x *OrderCancelRejectEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderCancelRejectEvent.ProtoReflect.Descriptor instead.
+func (*OrderCancelRejectEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *OrderCancelRejectEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+func (x *OrderCancelRejectEvent) GetOrdId() string {
+	if x != nil {
+		return x.OrdId
+	}
+	return ""
+}
+
+func (x *OrderCancelRejectEvent) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type OrderCancelRequestEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	OrdId         string                 `protobuf:"bytes,2,opt,name=ord_id,json=ordId,proto3" json:"ord_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderCancelRequestEvent) Reset() {
+	*x = OrderCancelRequestEvent{}
+	mi := &file_events_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderCancelRequestEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderCancelRequestEvent) ProtoMessage() {}
+
+func (x *OrderCancelRequestEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderCancelRequestEvent.ProtoReflect.Descriptor instead.
+func (*OrderCancelRequestEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *OrderCancelRequestEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+func (x *OrderCancelRequestEvent) GetOrdId() string {
+	if x != nil {
+		return x.OrdId
+	}
+	return ""
+}
+
+type OrderReplaceRequestEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	OrdId         string                 `protobuf:"bytes,2,opt,name=ord_id,json=ordId,proto3" json:"ord_id,omitempty"`
+	NewPrice      float64                `protobuf:"fixed64,3,opt,name=new_price,json=newPrice,proto3" json:"new_price,omitempty"`
+	NewQty        int64                  `protobuf:"varint,4,opt,name=new_qty,json=newQty,proto3" json:"new_qty,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderReplaceRequestEvent) Reset() {
+	*x = OrderReplaceRequestEvent{}
+	mi := &file_events_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderReplaceRequestEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderReplaceRequestEvent) ProtoMessage() {}
+
+func (x *OrderReplaceRequestEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderReplaceRequestEvent.ProtoReflect.Descriptor instead.
+func (*OrderReplaceRequestEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *OrderReplaceRequestEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+func (x *OrderReplaceRequestEvent) GetOrdId() string {
+	if x != nil {
+		return x.OrdId
+	}
+	return ""
+}
+
+func (x *OrderReplaceRequestEvent) GetNewPrice() float64 {
+	if x != nil {
+		return x.NewPrice
+	}
+	return 0
+}
+
+func (x *OrderReplaceRequestEvent) GetNewQty() int64 {
+	if x != nil {
+		return x.NewQty
+	}
+	return 0
+}
+
+type OrderReplaceRejectEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	OrdId         string                 `protobuf:"bytes,2,opt,name=ord_id,json=ordId,proto3" json:"ord_id,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderReplaceRejectEvent) Reset() {
+	*x = OrderReplaceRejectEvent{}
+	mi := &file_events_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderReplaceRejectEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderReplaceRejectEvent) ProtoMessage() {}
+
+func (x *OrderReplaceRejectEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderReplaceRejectEvent.ProtoReflect.Descriptor instead.
+func (*OrderReplaceRejectEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *OrderReplaceRejectEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+func (x *OrderReplaceRejectEvent) GetOrdId() string {
+	if x != nil {
+		return x.OrdId
+	}
+	return ""
+}
+
+func (x *OrderReplaceRejectEvent) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type OrderReplacedEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	OrdId         string                 `protobuf:"bytes,2,opt,name=ord_id,json=ordId,proto3" json:"ord_id,omitempty"`
+	NewPrice      float64                `protobuf:"fixed64,3,opt,name=new_price,json=newPrice,proto3" json:"new_price,omitempty"`
+	NewQty        int64                  `protobuf:"varint,4,opt,name=new_qty,json=newQty,proto3" json:"new_qty,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderReplacedEvent) Reset() {
+	*x = OrderReplacedEvent{}
+	mi := &file_events_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderReplacedEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderReplacedEvent) ProtoMessage() {}
+
+func (x *OrderReplacedEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderReplacedEvent.ProtoReflect.Descriptor instead.
+func (*OrderReplacedEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *OrderReplacedEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+func (x *OrderReplacedEvent) GetOrdId() string {
+	if x != nil {
+		return x.OrdId
+	}
+	return ""
+}
+
+func (x *OrderReplacedEvent) GetNewPrice() float64 {
+	if x != nil {
+		return x.NewPrice
+	}
+	return 0
+}
+
+func (x *OrderReplacedEvent) GetNewQty() int64 {
+	if x != nil {
+		return x.NewQty
+	}
+	return 0
+}
+
+type OrderRejectedEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	OrdId         string                 `protobuf:"bytes,2,opt,name=ord_id,json=ordId,proto3" json:"ord_id,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderRejectedEvent) Reset() {
+	*x = OrderRejectedEvent{}
+	mi := &file_events_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderRejectedEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderRejectedEvent) ProtoMessage() {}
+
+func (x *OrderRejectedEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderRejectedEvent.ProtoReflect.Descriptor instead.
+func (*OrderRejectedEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *OrderRejectedEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+func (x *OrderRejectedEvent) GetOrdId() string {
+	if x != nil {
+		return x.OrdId
+	}
+	return ""
+}
+
+func (x *OrderRejectedEvent) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type StrategyRequestNotDeliveredEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	Request       *Event                 `protobuf:"bytes,2,opt,name=request,proto3" json:"request,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StrategyRequestNotDeliveredEvent) Reset() {
+	*x = StrategyRequestNotDeliveredEvent{}
+	mi := &file_events_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StrategyRequestNotDeliveredEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StrategyRequestNotDeliveredEvent) ProtoMessage() {}
+
+func (x *StrategyRequestNotDeliveredEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StrategyRequestNotDeliveredEvent.ProtoReflect.Descriptor instead.
+func (*StrategyRequestNotDeliveredEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *StrategyRequestNotDeliveredEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+func (x *StrategyRequestNotDeliveredEvent) GetRequest() *Event {
+	if x != nil {
+		return x.Request
+	}
+	return nil
+}
+
+func (x *StrategyRequestNotDeliveredEvent) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type TimerTickEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TimerTickEvent) Reset() {
+	*x = TimerTickEvent{}
+	mi := &file_events_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TimerTickEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TimerTickEvent) ProtoMessage() {}
+
+func (x *TimerTickEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TimerTickEvent.ProtoReflect.Descriptor instead.
+func (*TimerTickEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *TimerTickEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+type TimerEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TimerEvent) Reset() {
+	*x = TimerEvent{}
+	mi := &file_events_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TimerEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TimerEvent) ProtoMessage() {}
+
+func (x *TimerEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TimerEvent.ProtoReflect.Descriptor instead.
+func (*TimerEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *TimerEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+func (x *TimerEvent) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type DataGapEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	From          *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To            *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DataGapEvent) Reset() {
+	*x = DataGapEvent{}
+	mi := &file_events_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DataGapEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DataGapEvent) ProtoMessage() {}
+
+func (x *DataGapEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DataGapEvent.ProtoReflect.Descriptor instead.
+func (*DataGapEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *DataGapEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+func (x *DataGapEvent) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *DataGapEvent) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+type BacktestProgressEvent struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent       *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	Percent         float64                `protobuf:"fixed64,2,opt,name=percent,proto3" json:"percent,omitempty"`
+	EventsProcessed int64                  `protobuf:"varint,3,opt,name=events_processed,json=eventsProcessed,proto3" json:"events_processed,omitempty"`
+	SimTime         *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=sim_time,json=simTime,proto3" json:"sim_time,omitempty"`
+	Eta             *durationpb.Duration   `protobuf:"bytes,5,opt,name=eta,proto3" json:"eta,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *BacktestProgressEvent) Reset() {
+	*x = BacktestProgressEvent{}
+	mi := &file_events_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BacktestProgressEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BacktestProgressEvent) ProtoMessage() {}
+
+func (x *BacktestProgressEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BacktestProgressEvent.ProtoReflect.Descriptor instead.
+func (*BacktestProgressEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *BacktestProgressEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+func (x *BacktestProgressEvent) GetPercent() float64 {
+	if x != nil {
+		return x.Percent
+	}
+	return 0
+}
+
+func (x *BacktestProgressEvent) GetEventsProcessed() int64 {
+	if x != nil {
+		return x.EventsProcessed
+	}
+	return 0
+}
+
+func (x *BacktestProgressEvent) GetSimTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.SimTime
+	}
+	return nil
+}
+
+func (x *BacktestProgressEvent) GetEta() *durationpb.Duration {
+	if x != nil {
+		return x.Eta
+	}
+	return nil
+}
+
+type RollEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	From          *Instrument            `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To            *Instrument            `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	FromPrice     float64                `protobuf:"fixed64,4,opt,name=from_price,json=fromPrice,proto3" json:"from_price,omitempty"`
+	ToPrice       float64                `protobuf:"fixed64,5,opt,name=to_price,json=toPrice,proto3" json:"to_price,omitempty"`
+	BackAdjust    string                 `protobuf:"bytes,6,opt,name=back_adjust,json=backAdjust,proto3" json:"back_adjust,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RollEvent) Reset() {
+	*x = RollEvent{}
+	mi := &file_events_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RollEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RollEvent) ProtoMessage() {}
+
+func (x *RollEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RollEvent.ProtoReflect.Descriptor instead.
+func (*RollEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *RollEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+func (x *RollEvent) GetFrom() *Instrument {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *RollEvent) GetTo() *Instrument {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *RollEvent) GetFromPrice() float64 {
+	if x != nil {
+		return x.FromPrice
+	}
+	return 0
+}
+
+func (x *RollEvent) GetToPrice() float64 {
+	if x != nil {
+		return x.ToPrice
+	}
+	return 0
+}
+
+func (x *RollEvent) GetBackAdjust() string {
+	if x != nil {
+		return x.BackAdjust
+	}
+	return ""
+}
+
+type OptionChainEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	Quotes        []*OptionQuote         `protobuf:"bytes,2,rep,name=quotes,proto3" json:"quotes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OptionChainEvent) Reset() {
+	*x = OptionChainEvent{}
+	mi := &file_events_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OptionChainEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OptionChainEvent) ProtoMessage() {}
+
+func (x *OptionChainEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OptionChainEvent.ProtoReflect.Descriptor instead.
+func (*OptionChainEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *OptionChainEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+func (x *OptionChainEvent) GetQuotes() []*OptionQuote {
+	if x != nil {
+		return x.Quotes
+	}
+	return nil
+}
+
+type FundamentalDataEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	EarningsDate  *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=earnings_date,json=earningsDate,proto3" json:"earnings_date,omitempty"`
+	Eps           float64                `protobuf:"fixed64,3,opt,name=eps,proto3" json:"eps,omitempty"`
+	EpsEstimate   float64                `protobuf:"fixed64,4,opt,name=eps_estimate,json=epsEstimate,proto3" json:"eps_estimate,omitempty"`
+	Ratios        map[string]float64     `protobuf:"bytes,5,rep,name=ratios,proto3" json:"ratios,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FundamentalDataEvent) Reset() {
+	*x = FundamentalDataEvent{}
+	mi := &file_events_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FundamentalDataEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FundamentalDataEvent) ProtoMessage() {}
+
+func (x *FundamentalDataEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FundamentalDataEvent.ProtoReflect.Descriptor instead.
+func (*FundamentalDataEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *FundamentalDataEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+func (x *FundamentalDataEvent) GetEarningsDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EarningsDate
+	}
+	return nil
+}
+
+func (x *FundamentalDataEvent) GetEps() float64 {
+	if x != nil {
+		return x.Eps
+	}
+	return 0
+}
+
+func (x *FundamentalDataEvent) GetEpsEstimate() float64 {
+	if x != nil {
+		return x.EpsEstimate
+	}
+	return 0
+}
+
+func (x *FundamentalDataEvent) GetRatios() map[string]float64 {
+	if x != nil {
+		return x.Ratios
+	}
+	return nil
+}
+
+type DividendEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	Amount        float64                `protobuf:"fixed64,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DividendEvent) Reset() {
+	*x = DividendEvent{}
+	mi := &file_events_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DividendEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DividendEvent) ProtoMessage() {}
+
+func (x *DividendEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DividendEvent.ProtoReflect.Descriptor instead.
+func (*DividendEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *DividendEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+func (x *DividendEvent) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+type NewsEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	Headline      string                 `protobuf:"bytes,2,opt,name=headline,proto3" json:"headline,omitempty"`
+	Source        string                 `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"`
+	Sentiment     float64                `protobuf:"fixed64,4,opt,name=sentiment,proto3" json:"sentiment,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NewsEvent) Reset() {
+	*x = NewsEvent{}
+	mi := &file_events_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NewsEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NewsEvent) ProtoMessage() {}
+
+func (x *NewsEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NewsEvent.ProtoReflect.Descriptor instead.
+func (*NewsEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *NewsEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+func (x *NewsEvent) GetHeadline() string {
+	if x != nil {
+		return x.Headline
+	}
+	return ""
+}
+
+func (x *NewsEvent) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *NewsEvent) GetSentiment() float64 {
+	if x != nil {
+		return x.Sentiment
+	}
+	return 0
+}
+
+// CustomEvent payload is written the same way as in JSON of the event
+type CustomEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Payload       *structpb.Value        `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CustomEvent) Reset() {
+	*x = CustomEvent{}
+	mi := &file_events_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CustomEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CustomEvent) ProtoMessage() {}
+
+func (x *CustomEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CustomEvent.ProtoReflect.Descriptor instead.
+func (*CustomEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *CustomEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+func (x *CustomEvent) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CustomEvent) GetPayload() *structpb.Value {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+type SymbolAddedEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SymbolAddedEvent) Reset() {
+	*x = SymbolAddedEvent{}
+	mi := &file_events_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SymbolAddedEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SymbolAddedEvent) ProtoMessage() {}
+
+func (x *SymbolAddedEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SymbolAddedEvent.ProtoReflect.Descriptor instead.
+func (*SymbolAddedEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *SymbolAddedEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+type SymbolRemovedEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SymbolRemovedEvent) Reset() {
+	*x = SymbolRemovedEvent{}
+	mi := &file_events_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SymbolRemovedEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SymbolRemovedEvent) ProtoMessage() {}
+
+func (x *SymbolRemovedEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SymbolRemovedEvent.ProtoReflect.Descriptor instead.
+func (*SymbolRemovedEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *SymbolRemovedEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+type EndOfDataEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EndOfDataEvent) Reset() {
+	*x = EndOfDataEvent{}
+	mi := &file_events_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EndOfDataEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EndOfDataEvent) ProtoMessage() {}
+
+func (x *EndOfDataEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EndOfDataEvent.ProtoReflect.Descriptor instead.
+func (*EndOfDataEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *EndOfDataEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+// PortfolioNewPositionEvent is written without its trade
+type PortfolioNewPositionEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PortfolioNewPositionEvent) Reset() {
+	*x = PortfolioNewPositionEvent{}
+	mi := &file_events_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PortfolioNewPositionEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PortfolioNewPositionEvent) ProtoMessage() {}
+
+func (x *PortfolioNewPositionEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PortfolioNewPositionEvent.ProtoReflect.Descriptor instead.
+func (*PortfolioNewPositionEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *PortfolioNewPositionEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+type StrategyHaltedEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StrategyHaltedEvent) Reset() {
+	*x = StrategyHaltedEvent{}
+	mi := &file_events_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StrategyHaltedEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StrategyHaltedEvent) ProtoMessage() {}
+
+func (x *StrategyHaltedEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StrategyHaltedEvent.ProtoReflect.Descriptor instead.
+func (*StrategyHaltedEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *StrategyHaltedEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+func (x *StrategyHaltedEvent) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type MarginCallEvent struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent         *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	Equity            float64                `protobuf:"fixed64,2,opt,name=equity,proto3" json:"equity,omitempty"`
+	MaintenanceMargin float64                `protobuf:"fixed64,3,opt,name=maintenance_margin,json=maintenanceMargin,proto3" json:"maintenance_margin,omitempty"`
+	Liquidate         bool                   `protobuf:"varint,4,opt,name=liquidate,proto3" json:"liquidate,omitempty"`
+	Destination       string                 `protobuf:"bytes,5,opt,name=destination,proto3" json:"destination,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *MarginCallEvent) Reset() {
+	*x = MarginCallEvent{}
+	mi := &file_events_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MarginCallEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MarginCallEvent) ProtoMessage() {}
+
+func (x *MarginCallEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MarginCallEvent.ProtoReflect.Descriptor instead.
+func (*MarginCallEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *MarginCallEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+func (x *MarginCallEvent) GetEquity() float64 {
+	if x != nil {
+		return x.Equity
+	}
+	return 0
+}
+
+func (x *MarginCallEvent) GetMaintenanceMargin() float64 {
+	if x != nil {
+		return x.MaintenanceMargin
+	}
+	return 0
+}
+
+func (x *MarginCallEvent) GetLiquidate() bool {
+	if x != nil {
+		return x.Liquidate
+	}
+	return false
+}
+
+func (x *MarginCallEvent) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+type PortfolioKillSwitchEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	Equity        float64                `protobuf:"fixed64,2,opt,name=equity,proto3" json:"equity,omitempty"`
+	PeakEquity    float64                `protobuf:"fixed64,3,opt,name=peak_equity,json=peakEquity,proto3" json:"peak_equity,omitempty"`
+	Drawdown      float64                `protobuf:"fixed64,4,opt,name=drawdown,proto3" json:"drawdown,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PortfolioKillSwitchEvent) Reset() {
+	*x = PortfolioKillSwitchEvent{}
+	mi := &file_events_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PortfolioKillSwitchEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PortfolioKillSwitchEvent) ProtoMessage() {}
+
+func (x *PortfolioKillSwitchEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PortfolioKillSwitchEvent.ProtoReflect.Descriptor instead.
+func (*PortfolioKillSwitchEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *PortfolioKillSwitchEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+func (x *PortfolioKillSwitchEvent) GetEquity() float64 {
+	if x != nil {
+		return x.Equity
+	}
+	return 0
+}
+
+func (x *PortfolioKillSwitchEvent) GetPeakEquity() float64 {
+	if x != nil {
+		return x.PeakEquity
+	}
+	return 0
+}
+
+func (x *PortfolioKillSwitchEvent) GetDrawdown() float64 {
+	if x != nil {
+		return x.Drawdown
+	}
+	return 0
+}
+
+type StalePositionEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	Qty           int64                  `protobuf:"varint,2,opt,name=qty,proto3" json:"qty,omitempty"`
+	OpenTime      *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=open_time,json=openTime,proto3" json:"open_time,omitempty"`
+	Age           *durationpb.Duration   `protobuf:"bytes,4,opt,name=age,proto3" json:"age,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StalePositionEvent) Reset() {
+	*x = StalePositionEvent{}
+	mi := &file_events_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StalePositionEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StalePositionEvent) ProtoMessage() {}
+
+func (x *StalePositionEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StalePositionEvent.ProtoReflect.Descriptor instead.
+func (*StalePositionEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *StalePositionEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+func (x *StalePositionEvent) GetQty() int64 {
+	if x != nil {
+		return x.Qty
+	}
+	return 0
+}
+
+func (x *StalePositionEvent) GetOpenTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OpenTime
+	}
+	return nil
+}
+
+func (x *StalePositionEvent) GetAge() *durationpb.Duration {
+	if x != nil {
+		return x.Age
+	}
+	return nil
+}
+
+type StrategyFinishedEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StrategyFinishedEvent) Reset() {
+	*x = StrategyFinishedEvent{}
+	mi := &file_events_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StrategyFinishedEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StrategyFinishedEvent) ProtoMessage() {}
+
+func (x *StrategyFinishedEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StrategyFinishedEvent.ProtoReflect.Descriptor instead.
+func (*StrategyFinishedEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *StrategyFinishedEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+type EventDroppedEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	Event         string                 `protobuf:"bytes,2,opt,name=event,proto3" json:"event,omitempty"`
+	Strategy      string                 `protobuf:"bytes,3,opt,name=strategy,proto3" json:"strategy,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EventDroppedEvent) Reset() {
+	*x = EventDroppedEvent{}
+	mi := &file_events_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EventDroppedEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EventDroppedEvent) ProtoMessage() {}
+
+func (x *EventDroppedEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EventDroppedEvent.ProtoReflect.Descriptor instead.
+func (*EventDroppedEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *EventDroppedEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+func (x *EventDroppedEvent) GetEvent() string {
+	if x != nil {
+		return x.Event
+	}
+	return ""
+}
+
+func (x *EventDroppedEvent) GetStrategy() string {
+	if x != nil {
+		return x.Strategy
+	}
+	return ""
+}
+
+type StrategyErrorEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	Strategy      string                 `protobuf:"bytes,2,opt,name=strategy,proto3" json:"strategy,omitempty"`
+	Callback      string                 `protobuf:"bytes,3,opt,name=callback,proto3" json:"callback,omitempty"`
+	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	Stack         string                 `protobuf:"bytes,5,opt,name=stack,proto3" json:"stack,omitempty"`
+	Disabled      bool                   `protobuf:"varint,6,opt,name=disabled,proto3" json:"disabled,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StrategyErrorEvent) Reset() {
+	*x = StrategyErrorEvent{}
+	mi := &file_events_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StrategyErrorEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StrategyErrorEvent) ProtoMessage() {}
+
+func (x *StrategyErrorEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StrategyErrorEvent.ProtoReflect.Descriptor instead.
+func (*StrategyErrorEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *StrategyErrorEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+func (x *StrategyErrorEvent) GetStrategy() string {
+	if x != nil {
+		return x.Strategy
+	}
+	return ""
+}
+
+func (x *StrategyErrorEvent) GetCallback() string {
+	if x != nil {
+		return x.Callback
+	}
+	return ""
+}
+
+func (x *StrategyErrorEvent) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *StrategyErrorEvent) GetStack() string {
+	if x != nil {
+		return x.Stack
+	}
+	return ""
+}
+
+func (x *StrategyErrorEvent) GetDisabled() bool {
+	if x != nil {
+		return x.Disabled
+	}
+	return false
+}
+
+type EndOfDayEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	Date          *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=date,proto3" json:"date,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EndOfDayEvent) Reset() {
+	*x = EndOfDayEvent{}
+	mi := &file_events_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EndOfDayEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EndOfDayEvent) ProtoMessage() {}
+
+func (x *EndOfDayEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EndOfDayEvent.ProtoReflect.Descriptor instead.
+func (*EndOfDayEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *EndOfDayEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+func (x *EndOfDayEvent) GetDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Date
+	}
+	return nil
+}
+
+type RunManifestEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	Manifest      *RunManifest           `protobuf:"bytes,2,opt,name=manifest,proto3" json:"manifest,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunManifestEvent) Reset() {
+	*x = RunManifestEvent{}
+	mi := &file_events_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunManifestEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunManifestEvent) ProtoMessage() {}
+
+func (x *RunManifestEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunManifestEvent.ProtoReflect.Descriptor instead.
+func (*RunManifestEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *RunManifestEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+func (x *RunManifestEvent) GetManifest() *RunManifest {
+	if x != nil {
+		return x.Manifest
+	}
+	return nil
+}
+
+type AuctionImbalanceEvent struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent       *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	IndicativePrice float64                `protobuf:"fixed64,2,opt,name=indicative_price,json=indicativePrice,proto3" json:"indicative_price,omitempty"`
+	PairedQty       int64                  `protobuf:"varint,3,opt,name=paired_qty,json=pairedQty,proto3" json:"paired_qty,omitempty"`
+	ImbalanceQty    int64                  `protobuf:"varint,4,opt,name=imbalance_qty,json=imbalanceQty,proto3" json:"imbalance_qty,omitempty"`
+	ImbalanceSide   string                 `protobuf:"bytes,5,opt,name=imbalance_side,json=imbalanceSide,proto3" json:"imbalance_side,omitempty"`
+	Final           bool                   `protobuf:"varint,6,opt,name=final,proto3" json:"final,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *AuctionImbalanceEvent) Reset() {
+	*x = AuctionImbalanceEvent{}
+	mi := &file_events_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuctionImbalanceEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuctionImbalanceEvent) ProtoMessage() {}
+
+func (x *AuctionImbalanceEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuctionImbalanceEvent.ProtoReflect.Descriptor instead.
+func (*AuctionImbalanceEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *AuctionImbalanceEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+func (x *AuctionImbalanceEvent) GetIndicativePrice() float64 {
+	if x != nil {
+		return x.IndicativePrice
+	}
+	return 0
+}
+
+func (x *AuctionImbalanceEvent) GetPairedQty() int64 {
+	if x != nil {
+		return x.PairedQty
+	}
+	return 0
+}
+
+func (x *AuctionImbalanceEvent) GetImbalanceQty() int64 {
+	if x != nil {
+		return x.ImbalanceQty
+	}
+	return 0
+}
+
+func (x *AuctionImbalanceEvent) GetImbalanceSide() string {
+	if x != nil {
+		return x.ImbalanceSide
+	}
+	return ""
+}
+
+func (x *AuctionImbalanceEvent) GetFinal() bool {
+	if x != nil {
+		return x.Final
+	}
+	return false
+}
+
+type DelistingEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseEvent     *BaseEvent             `protobuf:"bytes,1,opt,name=base_event,json=baseEvent,proto3" json:"base_event,omitempty"`
+	Price         float64                `protobuf:"fixed64,2,opt,name=price,proto3" json:"price,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DelistingEvent) Reset() {
+	*x = DelistingEvent{}
+	mi := &file_events_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DelistingEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DelistingEvent) ProtoMessage() {}
+
+func (x *DelistingEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DelistingEvent.ProtoReflect.Descriptor instead.
+func (*DelistingEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *DelistingEvent) GetBaseEvent() *BaseEvent {
+	if x != nil {
+		return x.BaseEvent
+	}
+	return nil
+}
+
+func (x *DelistingEvent) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+// Event is any engine event
+type Event struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*Event_CandleOpenEvent
+	//	*Event_CandleCloseEvent
+	//	*Event_CandlesHistoryEvent
+	//	*Event_NewTickEvent
+	//	*Event_TickHistoryEvent
+	//	*Event_NewOrderEvent
+	//	*Event_OrderConfirmationEvent
+	//	*Event_OrderFillEvent
+	//	*Event_OrderCancelEvent
+	//	*Event_OrderCancelRejectEvent
+	//	*Event_OrderCancelRequestEvent
+	//	*Event_OrderReplaceRequestEvent
+	//	*Event_OrderReplaceRejectEvent
+	//	*Event_OrderReplacedEvent
+	//	*Event_OrderRejectedEvent
+	//	*Event_StrategyRequestNotDeliveredEvent
+	//	*Event_TimerTickEvent
+	//	*Event_TimerEvent
+	//	*Event_DataGapEvent
+	//	*Event_BacktestProgressEvent
+	//	*Event_RollEvent
+	//	*Event_OptionChainEvent
+	//	*Event_FundamentalDataEvent
+	//	*Event_DividendEvent
+	//	*Event_NewsEvent
+	//	*Event_CustomEvent
+	//	*Event_SymbolAddedEvent
+	//	*Event_SymbolRemovedEvent
+	//	*Event_EndOfDataEvent
+	//	*Event_PortfolioNewPositionEvent
+	//	*Event_StrategyHaltedEvent
+	//	*Event_MarginCallEvent
+	//	*Event_PortfolioKillSwitchEvent
+	//	*Event_StalePositionEvent
+	//	*Event_StrategyFinishedEvent
+	//	*Event_EventDroppedEvent
+	//	*Event_StrategyErrorEvent
+	//	*Event_EndOfDayEvent
+	//	*Event_RunManifestEvent
+	//	*Event_AuctionImbalanceEvent
+	//	*Event_DelistingEvent
+	Event         isEvent_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_events_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *Event) GetEvent() isEvent_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *Event) GetCandleOpenEvent() *CandleOpenEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_CandleOpenEvent); ok {
+			return x.CandleOpenEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetCandleCloseEvent() *CandleCloseEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_CandleCloseEvent); ok {
+			return x.CandleCloseEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetCandlesHistoryEvent() *CandlesHistoryEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_CandlesHistoryEvent); ok {
+			return x.CandlesHistoryEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetNewTickEvent() *NewTickEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_NewTickEvent); ok {
+			return x.NewTickEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetTickHistoryEvent() *TickHistoryEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_TickHistoryEvent); ok {
+			return x.TickHistoryEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetNewOrderEvent() *NewOrderEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_NewOrderEvent); ok {
+			return x.NewOrderEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetOrderConfirmationEvent() *OrderConfirmationEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_OrderConfirmationEvent); ok {
+			return x.OrderConfirmationEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetOrderFillEvent() *OrderFillEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_OrderFillEvent); ok {
+			return x.OrderFillEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetOrderCancelEvent() *OrderCancelEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_OrderCancelEvent); ok {
+			return x.OrderCancelEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetOrderCancelRejectEvent() *OrderCancelRejectEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_OrderCancelRejectEvent); ok {
+			return x.OrderCancelRejectEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetOrderCancelRequestEvent() *OrderCancelRequestEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_OrderCancelRequestEvent); ok {
+			return x.OrderCancelRequestEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetOrderReplaceRequestEvent() *OrderReplaceRequestEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_OrderReplaceRequestEvent); ok {
+			return x.OrderReplaceRequestEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetOrderReplaceRejectEvent() *OrderReplaceRejectEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_OrderReplaceRejectEvent); ok {
+			return x.OrderReplaceRejectEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetOrderReplacedEvent() *OrderReplacedEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_OrderReplacedEvent); ok {
+			return x.OrderReplacedEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetOrderRejectedEvent() *OrderRejectedEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_OrderRejectedEvent); ok {
+			return x.OrderRejectedEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetStrategyRequestNotDeliveredEvent() *StrategyRequestNotDeliveredEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_StrategyRequestNotDeliveredEvent); ok {
+			return x.StrategyRequestNotDeliveredEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetTimerTickEvent() *TimerTickEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_TimerTickEvent); ok {
+			return x.TimerTickEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetTimerEvent() *TimerEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_TimerEvent); ok {
+			return x.TimerEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetDataGapEvent() *DataGapEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_DataGapEvent); ok {
+			return x.DataGapEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetBacktestProgressEvent() *BacktestProgressEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_BacktestProgressEvent); ok {
+			return x.BacktestProgressEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetRollEvent() *RollEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_RollEvent); ok {
+			return x.RollEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetOptionChainEvent() *OptionChainEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_OptionChainEvent); ok {
+			return x.OptionChainEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetFundamentalDataEvent() *FundamentalDataEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_FundamentalDataEvent); ok {
+			return x.FundamentalDataEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetDividendEvent() *DividendEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_DividendEvent); ok {
+			return x.DividendEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetNewsEvent() *NewsEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_NewsEvent); ok {
+			return x.NewsEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetCustomEvent() *CustomEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_CustomEvent); ok {
+			return x.CustomEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetSymbolAddedEvent() *SymbolAddedEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_SymbolAddedEvent); ok {
+			return x.SymbolAddedEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetSymbolRemovedEvent() *SymbolRemovedEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_SymbolRemovedEvent); ok {
+			return x.SymbolRemovedEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetEndOfDataEvent() *EndOfDataEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_EndOfDataEvent); ok {
+			return x.EndOfDataEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetPortfolioNewPositionEvent() *PortfolioNewPositionEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_PortfolioNewPositionEvent); ok {
+			return x.PortfolioNewPositionEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetStrategyHaltedEvent() *StrategyHaltedEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_StrategyHaltedEvent); ok {
+			return x.StrategyHaltedEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetMarginCallEvent() *MarginCallEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_MarginCallEvent); ok {
+			return x.MarginCallEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetPortfolioKillSwitchEvent() *PortfolioKillSwitchEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_PortfolioKillSwitchEvent); ok {
+			return x.PortfolioKillSwitchEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetStalePositionEvent() *StalePositionEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_StalePositionEvent); ok {
+			return x.StalePositionEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetStrategyFinishedEvent() *StrategyFinishedEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_StrategyFinishedEvent); ok {
+			return x.StrategyFinishedEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetEventDroppedEvent() *EventDroppedEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_EventDroppedEvent); ok {
+			return x.EventDroppedEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetStrategyErrorEvent() *StrategyErrorEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_StrategyErrorEvent); ok {
+			return x.StrategyErrorEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetEndOfDayEvent() *EndOfDayEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_EndOfDayEvent); ok {
+			return x.EndOfDayEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetRunManifestEvent() *RunManifestEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_RunManifestEvent); ok {
+			return x.RunManifestEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetAuctionImbalanceEvent() *AuctionImbalanceEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_AuctionImbalanceEvent); ok {
+			return x.AuctionImbalanceEvent
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetDelistingEvent() *DelistingEvent {
+	if x != nil {
+		if x, ok := x.Event.(*Event_DelistingEvent); ok {
+			return x.DelistingEvent
+		}
+	}
+	return nil
+}
+
+type isEvent_Event interface {
+	isEvent_Event()
+}
+
+type Event_CandleOpenEvent struct {
+	CandleOpenEvent *CandleOpenEvent `protobuf:"bytes,1,opt,name=candle_open_event,json=candleOpenEvent,proto3,oneof"`
+}
+
+type Event_CandleCloseEvent struct {
+	CandleCloseEvent *CandleCloseEvent `protobuf:"bytes,2,opt,name=candle_close_event,json=candleCloseEvent,proto3,oneof"`
+}
+
+type Event_CandlesHistoryEvent struct {
+	CandlesHistoryEvent *CandlesHistoryEvent `protobuf:"bytes,3,opt,name=candles_history_event,json=candlesHistoryEvent,proto3,oneof"`
+}
+
+type Event_NewTickEvent struct {
+	NewTickEvent *NewTickEvent `protobuf:"bytes,4,opt,name=new_tick_event,json=newTickEvent,proto3,oneof"`
+}
+
+type Event_TickHistoryEvent struct {
+	TickHistoryEvent *TickHistoryEvent `protobuf:"bytes,5,opt,name=tick_history_event,json=tickHistoryEvent,proto3,oneof"`
+}
+
+type Event_NewOrderEvent struct {
+	NewOrderEvent *NewOrderEvent `protobuf:"bytes,6,opt,name=new_order_event,json=newOrderEvent,proto3,oneof"`
+}
+
+type Event_OrderConfirmationEvent struct {
+	OrderConfirmationEvent *OrderConfirmationEvent `protobuf:"bytes,7,opt,name=order_confirmation_event,json=orderConfirmationEvent,proto3,oneof"`
+}
+
+type Event_OrderFillEvent struct {
+	OrderFillEvent *OrderFillEvent `protobuf:"bytes,8,opt,name=order_fill_event,json=orderFillEvent,proto3,oneof"`
+}
+
+type Event_OrderCancelEvent struct {
+	OrderCancelEvent *OrderCancelEvent `protobuf:"bytes,9,opt,name=order_cancel_event,json=orderCancelEvent,proto3,oneof"`
+}
+
+type Event_OrderCancelRejectEvent struct {
+	OrderCancelRejectEvent *OrderCancelRejectEvent `protobuf:"bytes,10,opt,name=order_cancel_reject_event,json=orderCancelRejectEvent,proto3,oneof"`
+}
+
+type Event_OrderCancelRequestEvent struct {
+	OrderCancelRequestEvent *OrderCancelRequestEvent `protobuf:"bytes,11,opt,name=order_cancel_request_event,json=orderCancelRequestEvent,proto3,oneof"`
+}
+
+type Event_OrderReplaceRequestEvent struct {
+	OrderReplaceRequestEvent *OrderReplaceRequestEvent `protobuf:"bytes,12,opt,name=order_replace_request_event,json=orderReplaceRequestEvent,proto3,oneof"`
+}
+
+type Event_OrderReplaceRejectEvent struct {
+	OrderReplaceRejectEvent *OrderReplaceRejectEvent `protobuf:"bytes,13,opt,name=order_replace_reject_event,json=orderReplaceRejectEvent,proto3,oneof"`
+}
+
+type Event_OrderReplacedEvent struct {
+	OrderReplacedEvent *OrderReplacedEvent `protobuf:"bytes,14,opt,name=order_replaced_event,json=orderReplacedEvent,proto3,oneof"`
+}
+
+type Event_OrderRejectedEvent struct {
+	OrderRejectedEvent *OrderRejectedEvent `protobuf:"bytes,15,opt,name=order_rejected_event,json=orderRejectedEvent,proto3,oneof"`
+}
+
+type Event_StrategyRequestNotDeliveredEvent struct {
+	StrategyRequestNotDeliveredEvent *StrategyRequestNotDeliveredEvent `protobuf:"bytes,16,opt,name=strategy_request_not_delivered_event,json=strategyRequestNotDeliveredEvent,proto3,oneof"`
+}
+
+type Event_TimerTickEvent struct {
+	TimerTickEvent *TimerTickEvent `protobuf:"bytes,17,opt,name=timer_tick_event,json=timerTickEvent,proto3,oneof"`
+}
+
+type Event_TimerEvent struct {
+	TimerEvent *TimerEvent `protobuf:"bytes,18,opt,name=timer_event,json=timerEvent,proto3,oneof"`
+}
+
+type Event_DataGapEvent struct {
+	DataGapEvent *DataGapEvent `protobuf:"bytes,19,opt,name=data_gap_event,json=dataGapEvent,proto3,oneof"`
+}
+
+type Event_BacktestProgressEvent struct {
+	BacktestProgressEvent *BacktestProgressEvent `protobuf:"bytes,20,opt,name=backtest_progress_event,json=backtestProgressEvent,proto3,oneof"`
+}
+
+type Event_RollEvent struct {
+	RollEvent *RollEvent `protobuf:"bytes,21,opt,name=roll_event,json=rollEvent,proto3,oneof"`
+}
+
+type Event_OptionChainEvent struct {
+	OptionChainEvent *OptionChainEvent `protobuf:"bytes,22,opt,name=option_chain_event,json=optionChainEvent,proto3,oneof"`
+}
+
+type Event_FundamentalDataEvent struct {
+	FundamentalDataEvent *FundamentalDataEvent `protobuf:"bytes,23,opt,name=fundamental_data_event,json=fundamentalDataEvent,proto3,oneof"`
+}
+
+type Event_DividendEvent struct {
+	DividendEvent *DividendEvent `protobuf:"bytes,24,opt,name=dividend_event,json=dividendEvent,proto3,oneof"`
+}
+
+type Event_NewsEvent struct {
+	NewsEvent *NewsEvent `protobuf:"bytes,25,opt,name=news_event,json=newsEvent,proto3,oneof"`
+}
+
+type Event_CustomEvent struct {
+	CustomEvent *CustomEvent `protobuf:"bytes,26,opt,name=custom_event,json=customEvent,proto3,oneof"`
+}
+
+type Event_SymbolAddedEvent struct {
+	SymbolAddedEvent *SymbolAddedEvent `protobuf:"bytes,27,opt,name=symbol_added_event,json=symbolAddedEvent,proto3,oneof"`
+}
+
+type Event_SymbolRemovedEvent struct {
+	SymbolRemovedEvent *SymbolRemovedEvent `protobuf:"bytes,28,opt,name=symbol_removed_event,json=symbolRemovedEvent,proto3,oneof"`
+}
+
+type Event_EndOfDataEvent struct {
+	EndOfDataEvent *EndOfDataEvent `protobuf:"bytes,29,opt,name=end_of_data_event,json=endOfDataEvent,proto3,oneof"`
+}
+
+type Event_PortfolioNewPositionEvent struct {
+	PortfolioNewPositionEvent *PortfolioNewPositionEvent `protobuf:"bytes,30,opt,name=portfolio_new_position_event,json=portfolioNewPositionEvent,proto3,oneof"`
+}
+
+type Event_StrategyHaltedEvent struct {
+	StrategyHaltedEvent *StrategyHaltedEvent `protobuf:"bytes,31,opt,name=strategy_halted_event,json=strategyHaltedEvent,proto3,oneof"`
+}
+
+type Event_MarginCallEvent struct {
+	MarginCallEvent *MarginCallEvent `protobuf:"bytes,32,opt,name=margin_call_event,json=marginCallEvent,proto3,oneof"`
+}
+
+type Event_PortfolioKillSwitchEvent struct {
+	PortfolioKillSwitchEvent *PortfolioKillSwitchEvent `protobuf:"bytes,33,opt,name=portfolio_kill_switch_event,json=portfolioKillSwitchEvent,proto3,oneof"`
+}
+
+type Event_StalePositionEvent struct {
+	StalePositionEvent *StalePositionEvent `protobuf:"bytes,34,opt,name=stale_position_event,json=stalePositionEvent,proto3,oneof"`
+}
+
+type Event_StrategyFinishedEvent struct {
+	StrategyFinishedEvent *StrategyFinishedEvent `protobuf:"bytes,35,opt,name=strategy_finished_event,json=strategyFinishedEvent,proto3,oneof"`
+}
+
+type Event_EventDroppedEvent struct {
+	EventDroppedEvent *EventDroppedEvent `protobuf:"bytes,36,opt,name=event_dropped_event,json=eventDroppedEvent,proto3,oneof"`
+}
+
+type Event_StrategyErrorEvent struct {
+	StrategyErrorEvent *StrategyErrorEvent `protobuf:"bytes,37,opt,name=strategy_error_event,json=strategyErrorEvent,proto3,oneof"`
+}
+
+type Event_EndOfDayEvent struct {
+	EndOfDayEvent *EndOfDayEvent `protobuf:"bytes,38,opt,name=end_of_day_event,json=endOfDayEvent,proto3,oneof"`
+}
+
+type Event_RunManifestEvent struct {
+	RunManifestEvent *RunManifestEvent `protobuf:"bytes,39,opt,name=run_manifest_event,json=runManifestEvent,proto3,oneof"`
+}
+
+type Event_AuctionImbalanceEvent struct {
+	AuctionImbalanceEvent *AuctionImbalanceEvent `protobuf:"bytes,40,opt,name=auction_imbalance_event,json=auctionImbalanceEvent,proto3,oneof"`
+}
+
+type Event_DelistingEvent struct {
+	DelistingEvent *DelistingEvent `protobuf:"bytes,41,opt,name=delisting_event,json=delistingEvent,proto3,oneof"`
+}
+
+func (*Event_CandleOpenEvent) isEvent_Event() {}
+
+func (*Event_CandleCloseEvent) isEvent_Event() {}
+
+func (*Event_CandlesHistoryEvent) isEvent_Event() {}
+
+func (*Event_NewTickEvent) isEvent_Event() {}
+
+func (*Event_TickHistoryEvent) isEvent_Event() {}
+
+func (*Event_NewOrderEvent) isEvent_Event() {}
+
+func (*Event_OrderConfirmationEvent) isEvent_Event() {}
+
+func (*Event_OrderFillEvent) isEvent_Event() {}
+
+func (*Event_OrderCancelEvent) isEvent_Event() {}
+
+func (*Event_OrderCancelRejectEvent) isEvent_Event() {}
+
+func (*Event_OrderCancelRequestEvent) isEvent_Event() {}
+
+func (*Event_OrderReplaceRequestEvent) isEvent_Event() {}
+
+func (*Event_OrderReplaceRejectEvent) isEvent_Event() {}
+
+func (*Event_OrderReplacedEvent) isEvent_Event() {}
+
+func (*Event_OrderRejectedEvent) isEvent_Event() {}
+
+func (*Event_StrategyRequestNotDeliveredEvent) isEvent_Event() {}
+
+func (*Event_TimerTickEvent) isEvent_Event() {}
+
+func (*Event_TimerEvent) isEvent_Event() {}
+
+func (*Event_DataGapEvent) isEvent_Event() {}
+
+func (*Event_BacktestProgressEvent) isEvent_Event() {}
+
+func (*Event_RollEvent) isEvent_Event() {}
+
+func (*Event_OptionChainEvent) isEvent_Event() {}
+
+func (*Event_FundamentalDataEvent) isEvent_Event() {}
+
+func (*Event_DividendEvent) isEvent_Event() {}
+
+func (*Event_NewsEvent) isEvent_Event() {}
+
+func (*Event_CustomEvent) isEvent_Event() {}
+
+func (*Event_SymbolAddedEvent) isEvent_Event() {}
+
+func (*Event_SymbolRemovedEvent) isEvent_Event() {}
+
+func (*Event_EndOfDataEvent) isEvent_Event() {}
+
+func (*Event_PortfolioNewPositionEvent) isEvent_Event() {}
+
+func (*Event_StrategyHaltedEvent) isEvent_Event() {}
+
+func (*Event_MarginCallEvent) isEvent_Event() {}
+
+func (*Event_PortfolioKillSwitchEvent) isEvent_Event() {}
+
+func (*Event_StalePositionEvent) isEvent_Event() {}
+
+func (*Event_StrategyFinishedEvent) isEvent_Event() {}
+
+func (*Event_EventDroppedEvent) isEvent_Event() {}
+
+func (*Event_StrategyErrorEvent) isEvent_Event() {}
+
+func (*Event_EndOfDayEvent) isEvent_Event() {}
+
+func (*Event_RunManifestEvent) isEvent_Event() {}
+
+func (*Event_AuctionImbalanceEvent) isEvent_Event() {}
+
+func (*Event_DelistingEvent) isEvent_Event() {}
+
+var File_events_proto protoreflect.FileDescriptor
+
+const file_events_proto_rawDesc = "" +
+	"\n" +
+	"\fevents.proto\x12\x06engine\x1a\x1egoogle/protobuf/duration.proto\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"O\n" +
+	"\tTimeOfDay\x12\x12\n" +
+	"\x04hour\x18\x01 \x01(\x03R\x04hour\x12\x16\n" +
+	"\x06minute\x18\x02 \x01(\x03R\x06minute\x12\x16\n" +
+	"\x06second\x18\x03 \x01(\x03R\x06second\"\xb6\x01\n" +
+	"\bExchange\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12;\n" +
+	"\x10market_open_time\x18\x02 \x01(\v2\x11.engine.TimeOfDayR\x0emarketOpenTime\x12=\n" +
+	"\x11market_close_time\x18\x03 \x01(\v2\x11.engine.TimeOfDayR\x0fmarketCloseTime\x12\x1a\n" +
+	"\blocation\x18\x04 \x01(\tR\blocation\"\xa2\x01\n" +
+	"\n" +
+	"OptionSpec\x122\n" +
+	"\n" +
+	"underlying\x18\x01 \x01(\v2\x12.engine.InstrumentR\n" +
+	"underlying\x12\x16\n" +
+	"\x06strike\x18\x02 \x01(\x01R\x06strike\x122\n" +
+	"\x06expiry\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x06expiry\x12\x14\n" +
+	"\x05right\x18\x04 \x01(\tR\x05right\"M\n" +
+	"\x06FXSpec\x12\x12\n" +
+	"\x04base\x18\x01 \x01(\tR\x04base\x12\x14\n" +
+	"\x05quote\x18\x02 \x01(\tR\x05quote\x12\x19\n" +
+	"\bpip_size\x18\x03 \x01(\x01R\apipSize\"\xad\x03\n" +
+	"\n" +
+	"Instrument\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\x12,\n" +
+	"\bexchange\x18\x02 \x01(\v2\x10.engine.ExchangeR\bexchange\x12\x19\n" +
+	"\bmin_tick\x18\x03 \x01(\x01R\aminTick\x12\x19\n" +
+	"\blot_size\x18\x04 \x01(\x03R\alotSize\x12$\n" +
+	"\x0edata_time_zone\x18\x05 \x01(\tR\fdataTimeZone\x12\x1e\n" +
+	"\n" +
+	"multiplier\x18\x06 \x01(\x01R\n" +
+	"multiplier\x12#\n" +
+	"\rqty_precision\x18\a \x01(\x03R\fqtyPrecision\x12%\n" +
+	"\x0einitial_margin\x18\b \x01(\x01R\rinitialMargin\x12-\n" +
+	"\x12maintenance_margin\x18\t \x01(\x01R\x11maintenanceMargin\x12\x16\n" +
+	"\x06sector\x18\n" +
+	" \x01(\tR\x06sector\x12*\n" +
+	"\x06option\x18\v \x01(\v2\x12.engine.OptionSpecR\x06option\x12\x1e\n" +
+	"\x02fx\x18\f \x01(\v2\x0e.engine.FXSpecR\x02fx\"\xa0\x01\n" +
+	"\tBaseEvent\x12.\n" +
+	"\x04time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12*\n" +
+	"\x06ticker\x18\x02 \x01(\v2\x12.engine.InstrumentR\x06ticker\x12\x10\n" +
+	"\x03seq\x18\x03 \x01(\x04R\x03seq\x12%\n" +
+	"\x0ecorrelation_id\x18\x04 \x01(\tR\rcorrelationId\"\x8c\x02\n" +
+	"\x10MarketDataCandle\x126\n" +
+	"\bdatetime\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\bdatetime\x12\x16\n" +
+	"\x06symbol\x18\x02 \x01(\tR\x06symbol\x12\x12\n" +
+	"\x04open\x18\x03 \x01(\x01R\x04open\x12\x12\n" +
+	"\x04high\x18\x04 \x01(\x01R\x04high\x12\x10\n" +
+	"\x03low\x18\x05 \x01(\x01R\x03low\x12\x14\n" +
+	"\x05close\x18\x06 \x01(\x01R\x05close\x12\x1b\n" +
+	"\tadj_close\x18\a \x01(\x01R\badjClose\x12\x16\n" +
+	"\x06volume\x18\b \x01(\x03R\x06volume\x12#\n" +
+	"\ropen_interest\x18\t \x01(\x03R\fopenInterest\"f\n" +
+	"\x06Candle\x120\n" +
+	"\x06candle\x18\x01 \x01(\v2\x18.engine.MarketDataCandleR\x06candle\x12*\n" +
+	"\x06ticker\x18\x02 \x01(\v2\x12.engine.InstrumentR\x06ticker\"\x94\x04\n" +
+	"\x0eMarketDataTick\x126\n" +
+	"\bdatetime\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\bdatetime\x12\x16\n" +
+	"\x06symbol\x18\x02 \x01(\tR\x06symbol\x12\x1d\n" +
+	"\n" +
+	"last_price\x18\x03 \x01(\x01R\tlastPrice\x12\x1b\n" +
+	"\tlast_size\x18\x04 \x01(\x03R\blastSize\x12\x1b\n" +
+	"\tlast_exch\x18\x05 \x01(\tR\blastExch\x12\x1b\n" +
+	"\tbid_price\x18\x06 \x01(\x01R\bbidPrice\x12\x19\n" +
+	"\bbid_size\x18\a \x01(\x03R\abidSize\x12\x19\n" +
+	"\bbid_exch\x18\b \x01(\tR\abidExch\x12\x1b\n" +
+	"\task_price\x18\t \x01(\x01R\baskPrice\x12\x19\n" +
+	"\bask_size\x18\n" +
+	" \x01(\x03R\aaskSize\x12\x19\n" +
+	"\bask_exch\x18\v \x01(\tR\aaskExch\x12\x1d\n" +
+	"\n" +
+	"cond_quote\x18\f \x01(\tR\tcondQuote\x12\x14\n" +
+	"\x05cond1\x18\r \x01(\tR\x05cond1\x12\x14\n" +
+	"\x05cond2\x18\x0e \x01(\tR\x05cond2\x12\x14\n" +
+	"\x05cond3\x18\x0f \x01(\tR\x05cond3\x12\x14\n" +
+	"\x05cond4\x18\x10 \x01(\tR\x05cond4\x12\x1d\n" +
+	"\n" +
+	"is_opening\x18\x11 \x01(\bR\tisOpening\x12\x1d\n" +
+	"\n" +
+	"is_closing\x18\x12 \x01(\bR\tisClosing\"^\n" +
+	"\x04Tick\x12*\n" +
+	"\x04tick\x18\x01 \x01(\v2\x16.engine.MarketDataTickR\x04tick\x12*\n" +
+	"\x06ticker\x18\x02 \x01(\v2\x12.engine.InstrumentR\x06ticker\"\xbf\x03\n" +
+	"\x05Order\x12\x12\n" +
+	"\x04side\x18\x01 \x01(\tR\x04side\x12\x10\n" +
+	"\x03qty\x18\x02 \x01(\x03R\x03qty\x12\x19\n" +
+	"\bexec_qty\x18\x03 \x01(\x03R\aexecQty\x12*\n" +
+	"\x06ticker\x18\x04 \x01(\v2\x12.engine.InstrumentR\x06ticker\x12\x14\n" +
+	"\x05state\x18\x05 \x01(\tR\x05state\x12\x14\n" +
+	"\x05price\x18\x06 \x01(\x01R\x05price\x12\x1d\n" +
+	"\n" +
+	"exec_price\x18\a \x01(\x01R\texecPrice\x12\x12\n" +
+	"\x04type\x18\b \x01(\tR\x04type\x12\x10\n" +
+	"\x03tif\x18\t \x01(\tR\x03tif\x12 \n" +
+	"\vdestination\x18\n" +
+	" \x01(\tR\vdestination\x12\x0e\n" +
+	"\x02id\x18\v \x01(\tR\x02id\x12\x14\n" +
+	"\x05mark1\x18\f \x01(\tR\x05mark1\x12\x14\n" +
+	"\x05mark2\x18\r \x01(\tR\x05mark2\x12.\n" +
+	"\x04time\x18\x0e \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12#\n" +
+	"\rposition_side\x18\x0f \x01(\tR\fpositionSide\x12%\n" +
+	"\x0ecorrelation_id\x18\x10 \x01(\tR\rcorrelationId\"\x85\x02\n" +
+	"\vOptionQuote\x12*\n" +
+	"\x06option\x18\x01 \x01(\v2\x12.engine.InstrumentR\x06option\x12\x10\n" +
+	"\x03bid\x18\x02 \x01(\x01R\x03bid\x12\x19\n" +
+	"\bbid_size\x18\x03 \x01(\x03R\abidSize\x12\x10\n" +
+	"\x03ask\x18\x04 \x01(\x01R\x03ask\x12\x19\n" +
+	"\bask_size\x18\x05 \x01(\x03R\aaskSize\x12\x12\n" +
+	"\x04last\x18\x06 \x01(\x01R\x04last\x12\x16\n" +
+	"\x06volume\x18\a \x01(\x03R\x06volume\x12#\n" +
+	"\ropen_interest\x18\b \x01(\x03R\fopenInterest\x12\x1f\n" +
+	"\vimplied_vol\x18\t \x01(\x01R\n" +
+	"impliedVol\"x\n" +
+	"\bFillFees\x12\x1e\n" +
+	"\n" +
+	"commission\x18\x01 \x01(\x01R\n" +
+	"commission\x12!\n" +
+	"\fexchange_fee\x18\x02 \x01(\x01R\vexchangeFee\x12\x17\n" +
+	"\asec_fee\x18\x03 \x01(\x01R\x06secFee\x12\x10\n" +
+	"\x03taf\x18\x04 \x01(\x01R\x03taf\"\xc5\x02\n" +
+	"\vRunManifest\x12%\n" +
+	"\x0eengine_version\x18\x01 \x01(\tR\rengineVersion\x12\x1d\n" +
+	"\n" +
+	"go_version\x18\x02 \x01(\tR\tgoVersion\x12\x1f\n" +
+	"\vconfig_hash\x18\x03 \x01(\tR\n" +
+	"configHash\x12)\n" +
+	"\x10data_fingerprint\x18\x04 \x01(\tR\x0fdataFingerprint\x124\n" +
+	"\x05seeds\x18\x05 \x03(\v2\x1e.engine.RunManifest.SeedsEntryR\x05seeds\x124\n" +
+	"\acreated\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\acreated\x1a8\n" +
+	"\n" +
+	"SeedsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"\xb5\x01\n" +
+	"\x0fCandleOpenEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\x12;\n" +
+	"\vcandle_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"candleTime\x12\x14\n" +
+	"\x05price\x18\x03 \x01(\x01R\x05price\x12\x1d\n" +
+	"\n" +
+	"time_frame\x18\x04 \x01(\tR\ttimeFrame\"\x8b\x01\n" +
+	"\x10CandleCloseEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\x12&\n" +
+	"\x06candle\x18\x02 \x01(\v2\x0e.engine.CandleR\x06candle\x12\x1d\n" +
+	"\n" +
+	"time_frame\x18\x03 \x01(\tR\ttimeFrame\"q\n" +
+	"\x13CandlesHistoryEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\x12(\n" +
+	"\acandles\x18\x02 \x03(\v2\x0e.engine.CandleR\acandles\"b\n" +
+	"\fNewTickEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\x12 \n" +
+	"\x04tick\x18\x02 \x01(\v2\f.engine.TickR\x04tick\"h\n" +
+	"\x10TickHistoryEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\x12\"\n" +
+	"\x05ticks\x18\x02 \x03(\v2\f.engine.TickR\x05ticks\"s\n" +
+	"\rNewOrderEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\x120\n" +
+	"\flinked_order\x18\x02 \x01(\v2\r.engine.OrderR\vlinkedOrder\"a\n" +
+	"\x16OrderConfirmationEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\x12\x15\n" +
+	"\x06ord_id\x18\x02 \x01(\tR\x05ordId\"\xe3\x01\n" +
+	"\x0eOrderFillEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\x12\x15\n" +
+	"\x06ord_id\x18\x02 \x01(\tR\x05ordId\x12\x14\n" +
+	"\x05price\x18\x03 \x01(\x01R\x05price\x12\x10\n" +
+	"\x03qty\x18\x04 \x01(\x03R\x03qty\x12\x1e\n" +
+	"\n" +
+	"commission\x18\x05 \x01(\x01R\n" +
+	"commission\x12\x1a\n" +
+	"\bslippage\x18\x06 \x01(\x01R\bslippage\x12$\n" +
+	"\x04fees\x18\a \x01(\v2\x10.engine.FillFeesR\x04fees\"[\n" +
+	"\x10OrderCancelEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\x12\x15\n" +
+	"\x06ord_id\x18\x02 \x01(\tR\x05ordId\"y\n" +
+	"\x16OrderCancelRejectEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\x12\x15\n" +
+	"\x06ord_id\x18\x02 \x01(\tR\x05ordId\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"b\n" +
+	"\x17OrderCancelRequestEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\x12\x15\n" +
+	"\x06ord_id\x18\x02 \x01(\tR\x05ordId\"\x99\x01\n" +
+	"\x18OrderReplaceRequestEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\x12\x15\n" +
+	"\x06ord_id\x18\x02 \x01(\tR\x05ordId\x12\x1b\n" +
+	"\tnew_price\x18\x03 \x01(\x01R\bnewPrice\x12\x17\n" +
+	"\anew_qty\x18\x04 \x01(\x03R\x06newQty\"z\n" +
+	"\x17OrderReplaceRejectEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\x12\x15\n" +
+	"\x06ord_id\x18\x02 \x01(\tR\x05ordId\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"\x93\x01\n" +
+	"\x12OrderReplacedEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\x12\x15\n" +
+	"\x06ord_id\x18\x02 \x01(\tR\x05ordId\x12\x1b\n" +
+	"\tnew_price\x18\x03 \x01(\x01R\bnewPrice\x12\x17\n" +
+	"\anew_qty\x18\x04 \x01(\x03R\x06newQty\"u\n" +
+	"\x12OrderRejectedEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\x12\x15\n" +
+	"\x06ord_id\x18\x02 \x01(\tR\x05ordId\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"\x95\x01\n" +
+	" StrategyRequestNotDeliveredEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\x12'\n" +
+	"\arequest\x18\x02 \x01(\v2\r.engine.EventR\arequest\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"B\n" +
+	"\x0eTimerTickEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\"R\n" +
+	"\n" +
+	"TimerEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"\x9c\x01\n" +
+	"\fDataGapEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\x12.\n" +
+	"\x04from\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\"\xf2\x01\n" +
+	"\x15BacktestProgressEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\x12\x18\n" +
+	"\apercent\x18\x02 \x01(\x01R\apercent\x12)\n" +
+	"\x10events_processed\x18\x03 \x01(\x03R\x0feventsProcessed\x125\n" +
+	"\bsim_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\asimTime\x12+\n" +
+	"\x03eta\x18\x05 \x01(\v2\x19.google.protobuf.DurationR\x03eta\"\xe4\x01\n" +
+	"\tRollEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\x12&\n" +
+	"\x04from\x18\x02 \x01(\v2\x12.engine.InstrumentR\x04from\x12\"\n" +
+	"\x02to\x18\x03 \x01(\v2\x12.engine.InstrumentR\x02to\x12\x1d\n" +
+	"\n" +
+	"from_price\x18\x04 \x01(\x01R\tfromPrice\x12\x19\n" +
+	"\bto_price\x18\x05 \x01(\x01R\atoPrice\x12\x1f\n" +
+	"\vback_adjust\x18\x06 \x01(\tR\n" +
+	"backAdjust\"q\n" +
+	"\x10OptionChainEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\x12+\n" +
+	"\x06quotes\x18\x02 \x03(\v2\x13.engine.OptionQuoteR\x06quotes\"\xbb\x02\n" +
+	"\x14FundamentalDataEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\x12?\n" +
+	"\rearnings_date\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\fearningsDate\x12\x10\n" +
+	"\x03eps\x18\x03 \x01(\x01R\x03eps\x12!\n" +
+	"\feps_estimate\x18\x04 \x01(\x01R\vepsEstimate\x12@\n" +
+	"\x06ratios\x18\x05 \x03(\v2(.engine.FundamentalDataEvent.RatiosEntryR\x06ratios\x1a9\n" +
+	"\vRatiosEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\"Y\n" +
+	"\rDividendEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\x01R\x06amount\"\x8f\x01\n" +
+	"\tNewsEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\x12\x1a\n" +
+	"\bheadline\x18\x02 \x01(\tR\bheadline\x12\x16\n" +
+	"\x06source\x18\x03 \x01(\tR\x06source\x12\x1c\n" +
+	"\tsentiment\x18\x04 \x01(\x01R\tsentiment\"\x85\x01\n" +
+	"\vCustomEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x120\n" +
+	"\apayload\x18\x03 \x01(\v2\x16.google.protobuf.ValueR\apayload\"D\n" +
+	"\x10SymbolAddedEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\"F\n" +
+	"\x12SymbolRemovedEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\"B\n" +
+	"\x0eEndOfDataEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\"M\n" +
+	"\x19PortfolioNewPositionEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\"_\n" +
+	"\x13StrategyHaltedEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"\xca\x01\n" +
+	"\x0fMarginCallEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\x12\x16\n" +
+	"\x06equity\x18\x02 \x01(\x01R\x06equity\x12-\n" +
+	"\x12maintenance_margin\x18\x03 \x01(\x01R\x11maintenanceMargin\x12\x1c\n" +
+	"\tliquidate\x18\x04 \x01(\bR\tliquidate\x12 \n" +
+	"\vdestination\x18\x05 \x01(\tR\vdestination\"\xa1\x01\n" +
+	"\x18PortfolioKillSwitchEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\x12\x16\n" +
+	"\x06equity\x18\x02 \x01(\x01R\x06equity\x12\x1f\n" +
+	"\vpeak_equity\x18\x03 \x01(\x01R\n" +
+	"peakEquity\x12\x1a\n" +
+	"\bdrawdown\x18\x04 \x01(\x01R\bdrawdown\"\xbe\x01\n" +
+	"\x12StalePositionEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\x12\x10\n" +
+	"\x03qty\x18\x02 \x01(\x03R\x03qty\x127\n" +
+	"\topen_time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\bopenTime\x12+\n" +
+	"\x03age\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\x03age\"I\n" +
+	"\x15StrategyFinishedEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\"w\n" +
+	"\x11EventDroppedEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\x12\x14\n" +
+	"\x05event\x18\x02 \x01(\tR\x05event\x12\x1a\n" +
+	"\bstrategy\x18\x03 \x01(\tR\bstrategy\"\xc6\x01\n" +
+	"\x12StrategyErrorEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\x12\x1a\n" +
+	"\bstrategy\x18\x02 \x01(\tR\bstrategy\x12\x1a\n" +
+	"\bcallback\x18\x03 \x01(\tR\bcallback\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\x12\x14\n" +
+	"\x05stack\x18\x05 \x01(\tR\x05stack\x12\x1a\n" +
+	"\bdisabled\x18\x06 \x01(\bR\bdisabled\"q\n" +
+	"\rEndOfDayEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\x12.\n" +
+	"\x04date\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04date\"u\n" +
+	"\x10RunManifestEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\x12/\n" +
+	"\bmanifest\x18\x02 \x01(\v2\x13.engine.RunManifestR\bmanifest\"\xf5\x01\n" +
+	"\x15AuctionImbalanceEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\x12)\n" +
+	"\x10indicative_price\x18\x02 \x01(\x01R\x0findicativePrice\x12\x1d\n" +
+	"\n" +
+	"paired_qty\x18\x03 \x01(\x03R\tpairedQty\x12#\n" +
+	"\rimbalance_qty\x18\x04 \x01(\x03R\fimbalanceQty\x12%\n" +
+	"\x0eimbalance_side\x18\x05 \x01(\tR\rimbalanceSide\x12\x14\n" +
+	"\x05final\x18\x06 \x01(\bR\x05final\"X\n" +
+	"\x0eDelistingEvent\x120\n" +
+	"\n" +
+	"base_event\x18\x01 \x01(\v2\x11.engine.BaseEventR\tbaseEvent\x12\x14\n" +
+	"\x05price\x18\x02 \x01(\x01R\x05price\"\x87\x19\n" +
+	"\x05Event\x12E\n" +
+	"\x11candle_open_event\x18\x01 \x01(\v2\x17.engine.CandleOpenEventH\x00R\x0fcandleOpenEvent\x12H\n" +
+	"\x12candle_close_event\x18\x02 \x01(\v2\x18.engine.CandleCloseEventH\x00R\x10candleCloseEvent\x12Q\n" +
+	"\x15candles_history_event\x18\x03 \x01(\v2\x1b.engine.CandlesHistoryEventH\x00R\x13candlesHistoryEvent\x12<\n" +
+	"\x0enew_tick_event\x18\x04 \x01(\v2\x14.engine.NewTickEventH\x00R\fnewTickEvent\x12H\n" +
+	"\x12tick_history_event\x18\x05 \x01(\v2\x18.engine.TickHistoryEventH\x00R\x10tickHistoryEvent\x12?\n" +
+	"\x0fnew_order_event\x18\x06 \x01(\v2\x15.engine.NewOrderEventH\x00R\rnewOrderEvent\x12Z\n" +
+	"\x18order_confirmation_event\x18\a \x01(\v2\x1e.engine.OrderConfirmationEventH\x00R\x16orderConfirmationEvent\x12B\n" +
+	"\x10order_fill_event\x18\b \x01(\v2\x16.engine.OrderFillEventH\x00R\x0eorderFillEvent\x12H\n" +
+	"\x12order_cancel_event\x18\t \x01(\v2\x18.engine.OrderCancelEventH\x00R\x10orderCancelEvent\x12[\n" +
+	"\x19order_cancel_reject_event\x18\n" +
+	" \x01(\v2\x1e.engine.OrderCancelRejectEventH\x00R\x16orderCancelRejectEvent\x12^\n" +
+	"\x1aorder_cancel_request_event\x18\v \x01(\v2\x1f.engine.OrderCancelRequestEventH\x00R\x17orderCancelRequestEvent\x12a\n" +
+	"\x1border_replace_request_event\x18\f \x01(\v2 .engine.OrderReplaceRequestEventH\x00R\x18orderReplaceRequestEvent\x12^\n" +
+	"\x1aorder_replace_reject_event\x18\r \x01(\v2\x1f.engine.OrderReplaceRejectEventH\x00R\x17orderReplaceRejectEvent\x12N\n" +
+	"\x14order_replaced_event\x18\x0e \x01(\v2\x1a.engine.OrderReplacedEventH\x00R\x12orderReplacedEvent\x12N\n" +
+	"\x14order_rejected_event\x18\x0f \x01(\v2\x1a.engine.OrderRejectedEventH\x00R\x12orderRejectedEvent\x12z\n" +
+	"$strategy_request_not_delivered_event\x18\x10 \x01(\v2(.engine.StrategyRequestNotDeliveredEventH\x00R strategyRequestNotDeliveredEvent\x12B\n" +
+	"\x10timer_tick_event\x18\x11 \x01(\v2\x16.engine.TimerTickEventH\x00R\x0etimerTickEvent\x125\n" +
+	"\vtimer_event\x18\x12 \x01(\v2\x12.engine.TimerEventH\x00R\n" +
+	"timerEvent\x12<\n" +
+	"\x0edata_gap_event\x18\x13 \x01(\v2\x14.engine.DataGapEventH\x00R\fdataGapEvent\x12W\n" +
+	"\x17backtest_progress_event\x18\x14 \x01(\v2\x1d.engine.BacktestProgressEventH\x00R\x15backtestProgressEvent\x122\n" +
+	"\n" +
+	"roll_event\x18\x15 \x01(\v2\x11.engine.RollEventH\x00R\trollEvent\x12H\n" +
+	"\x12option_chain_event\x18\x16 \x01(\v2\x18.engine.OptionChainEventH\x00R\x10optionChainEvent\x12T\n" +
+	"\x16fundamental_data_event\x18\x17 \x01(\v2\x1c.engine.FundamentalDataEventH\x00R\x14fundamentalDataEvent\x12>\n" +
+	"\x0edividend_event\x18\x18 \x01(\v2\x15.engine.DividendEventH\x00R\rdividendEvent\x122\n" +
+	"\n" +
+	"news_event\x18\x19 \x01(\v2\x11.engine.NewsEventH\x00R\tnewsEvent\x128\n" +
+	"\fcustom_event\x18\x1a \x01(\v2\x13.engine.CustomEventH\x00R\vcustomEvent\x12H\n" +
+	"\x12symbol_added_event\x18\x1b \x01(\v2\x18.engine.SymbolAddedEventH\x00R\x10symbolAddedEvent\x12N\n" +
+	"\x14symbol_removed_event\x18\x1c \x01(\v2\x1a.engine.SymbolRemovedEventH\x00R\x12symbolRemovedEvent\x12C\n" +
+	"\x11end_of_data_event\x18\x1d \x01(\v2\x16.engine.EndOfDataEventH\x00R\x0eendOfDataEvent\x12d\n" +
+	"\x1cportfolio_new_position_event\x18\x1e \x01(\v2!.engine.PortfolioNewPositionEventH\x00R\x19portfolioNewPositionEvent\x12Q\n" +
+	"\x15strategy_halted_event\x18\x1f \x01(\v2\x1b.engine.StrategyHaltedEventH\x00R\x13strategyHaltedEvent\x12E\n" +
+	"\x11margin_call_event\x18  \x01(\v2\x17.engine.MarginCallEventH\x00R\x0fmarginCallEvent\x12a\n" +
+	"\x1bportfolio_kill_switch_event\x18! \x01(\v2 .engine.PortfolioKillSwitchEventH\x00R\x18portfolioKillSwitchEvent\x12N\n" +
+	"\x14stale_position_event\x18\" \x01(\v2\x1a.engine.StalePositionEventH\x00R\x12stalePositionEvent\x12W\n" +
+	"\x17strategy_finished_event\x18# \x01(\v2\x1d.engine.StrategyFinishedEventH\x00R\x15strategyFinishedEvent\x12K\n" +
+	"\x13event_dropped_event\x18$ \x01(\v2\x19.engine.EventDroppedEventH\x00R\x11eventDroppedEvent\x12N\n" +
+	"\x14strategy_error_event\x18% \x01(\v2\x1a.engine.StrategyErrorEventH\x00R\x12strategyErrorEvent\x12@\n" +
+	"\x10end_of_day_event\x18& \x01(\v2\x15.engine.EndOfDayEventH\x00R\rendOfDayEvent\x12H\n" +
+	"\x12run_manifest_event\x18' \x01(\v2\x18.engine.RunManifestEventH\x00R\x10runManifestEvent\x12W\n" +
+	"\x17auction_imbalance_event\x18( \x01(\v2\x1d.engine.AuctionImbalanceEventH\x00R\x15auctionImbalanceEvent\x12A\n" +
+	"\x0fdelisting_event\x18) \x01(\v2\x16.engine.DelistingEventH\x00R\x0edelistingEventB\a\n" +
+	"\x05eventB\x15Z\x13alex/engine/eventpbb\x06proto3"
+
+var (
+	file_events_proto_rawDescOnce sync.Once
+	file_events_proto_rawDescData []byte
+)
+
+func file_events_proto_rawDescGZIP() []byte {
+	file_events_proto_rawDescOnce.Do(func() {
+		file_events_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_events_proto_rawDesc), len(file_events_proto_rawDesc)))
+	})
+	return file_events_proto_rawDescData
+}
+
+var file_events_proto_msgTypes = make([]protoimpl.MessageInfo, 58)
+var file_events_proto_goTypes = []any{
+	(*TimeOfDay)(nil),                        // 0: engine.TimeOfDay
+	(*Exchange)(nil),                         // 1: engine.Exchange
+	(*OptionSpec)(nil),                       // 2: engine.OptionSpec
+	(*FXSpec)(nil),                           // 3: engine.FXSpec
+	(*Instrument)(nil),                       // 4: engine.Instrument
+	(*BaseEvent)(nil),                        // 5: engine.BaseEvent
+	(*MarketDataCandle)(nil),                 // 6: engine.MarketDataCandle
+	(*Candle)(nil),                           // 7: engine.Candle
+	(*MarketDataTick)(nil),                   // 8: engine.MarketDataTick
+	(*Tick)(nil),                             // 9: engine.Tick
+	(*Order)(nil),                            // 10: engine.Order
+	(*OptionQuote)(nil),                      // 11: engine.OptionQuote
+	(*FillFees)(nil),                         // 12: engine.FillFees
+	(*RunManifest)(nil),                      // 13: engine.RunManifest
+	(*CandleOpenEvent)(nil),                  // 14: engine.CandleOpenEvent
+	(*CandleCloseEvent)(nil),                 // 15: engine.CandleCloseEvent
+	(*CandlesHistoryEvent)(nil),              // 16: engine.CandlesHistoryEvent
+	(*NewTickEvent)(nil),                     // 17: engine.NewTickEvent
+	(*TickHistoryEvent)(nil),                 // 18: engine.TickHistoryEvent
+	(*NewOrderEvent)(nil),                    // 19: engine.NewOrderEvent
+	(*OrderConfirmationEvent)(nil),           // 20: engine.OrderConfirmationEvent
+	(*OrderFillEvent)(nil),                   // 21: engine.OrderFillEvent
+	(*OrderCancelEvent)(nil),                 // 22: engine.OrderCancelEvent
+	(*OrderCancelRejectEvent)(nil),           // 23: engine.OrderCancelRejectEvent
+	(*OrderCancelRequestEvent)(nil),          // 24: engine.OrderCancelRequestEvent
+	(*OrderReplaceRequestEvent)(nil),         // 25: engine.OrderReplaceRequestEvent
+	(*OrderReplaceRejectEvent)(nil),          // 26: engine.OrderReplaceRejectEvent
+	(*OrderReplacedEvent)(nil),               // 27: engine.OrderReplacedEvent
+	(*OrderRejectedEvent)(nil),               // 28: engine.OrderRejectedEvent
+	(*StrategyRequestNotDeliveredEvent)(nil), // 29: engine.StrategyRequestNotDeliveredEvent
+	(*TimerTickEvent)(nil),                   // 30: engine.TimerTickEvent
+	(*TimerEvent)(nil),                       // 31: engine.TimerEvent
+	(*DataGapEvent)(nil),                     // 32: engine.DataGapEvent
+	(*BacktestProgressEvent)(nil),            // 33: engine.BacktestProgressEvent
+	(*RollEvent)(nil),                        // 34: engine.RollEvent
+	(*OptionChainEvent)(nil),                 // 35: engine.OptionChainEvent
+	(*FundamentalDataEvent)(nil),             // 36: engine.FundamentalDataEvent
+	(*DividendEvent)(nil),                    // 37: engine.DividendEvent
+	(*NewsEvent)(nil),                        // 38: engine.NewsEvent
+	(*CustomEvent)(nil),                      // 39: engine.CustomEvent
+	(*SymbolAddedEvent)(nil),                 // 40: engine.SymbolAddedEvent
+	(*SymbolRemovedEvent)(nil),               // 41: engine.SymbolRemovedEvent
+	(*EndOfDataEvent)(nil),                   // 42: engine.EndOfDataEvent
+	(*PortfolioNewPositionEvent)(nil),        // 43: engine.PortfolioNewPositionEvent
+	(*StrategyHaltedEvent)(nil),              // 44: engine.StrategyHaltedEvent
+	(*MarginCallEvent)(nil),                  // 45: engine.MarginCallEvent
+	(*PortfolioKillSwitchEvent)(nil),         // 46: engine.PortfolioKillSwitchEvent
+	(*StalePositionEvent)(nil),               // 47: engine.StalePositionEvent
+	(*StrategyFinishedEvent)(nil),            // 48: engine.StrategyFinishedEvent
+	(*EventDroppedEvent)(nil),                // 49: engine.EventDroppedEvent
+	(*StrategyErrorEvent)(nil),               // 50: engine.StrategyErrorEvent
+	(*EndOfDayEvent)(nil),                    // 51: engine.EndOfDayEvent
+	(*RunManifestEvent)(nil),                 // 52: engine.RunManifestEvent
+	(*AuctionImbalanceEvent)(nil),            // 53: engine.AuctionImbalanceEvent
+	(*DelistingEvent)(nil),                   // 54: engine.DelistingEvent
+	(*Event)(nil),                            // 55: engine.Event
+	nil,                                      // 56: engine.RunManifest.SeedsEntry
+	nil,                                      // 57: engine.FundamentalDataEvent.RatiosEntry
+	(*timestamppb.Timestamp)(nil),            // 58: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),              // 59: google.protobuf.Duration
+	(*structpb.Value)(nil),                   // 60: google.protobuf.Value
+}
+var file_events_proto_depIdxs = []int32{
+	0,   // 0: engine.Exchange.market_open_time:type_name -> engine.TimeOfDay
+	0,   // 1: engine.Exchange.market_close_time:type_name -> engine.TimeOfDay
+	4,   // 2: engine.OptionSpec.underlying:type_name -> engine.Instrument
+	58,  // 3: engine.OptionSpec.expiry:type_name -> google.protobuf.Timestamp
+	1,   // 4: engine.Instrument.exchange:type_name -> engine.Exchange
+	2,   // 5: engine.Instrument.option:type_name -> engine.OptionSpec
+	3,   // 6: engine.Instrument.fx:type_name -> engine.FXSpec
+	58,  // 7: engine.BaseEvent.time:type_name -> google.protobuf.Timestamp
+	4,   // 8: engine.BaseEvent.ticker:type_name -> engine.Instrument
+	58,  // 9: engine.MarketDataCandle.datetime:type_name -> google.protobuf.Timestamp
+	6,   // 10: engine.Candle.candle:type_name -> engine.MarketDataCandle
+	4,   // 11: engine.Candle.ticker:type_name -> engine.Instrument
+	58,  // 12: engine.MarketDataTick.datetime:type_name -> google.protobuf.Timestamp
+	8,   // 13: engine.Tick.tick:type_name -> engine.MarketDataTick
+	4,   // 14: engine.Tick.ticker:type_name -> engine.Instrument
+	4,   // 15: engine.Order.ticker:type_name -> engine.Instrument
+	58,  // 16: engine.Order.time:type_name -> google.protobuf.Timestamp
+	4,   // 17: engine.OptionQuote.option:type_name -> engine.Instrument
+	56,  // 18: engine.RunManifest.seeds:type_name -> engine.RunManifest.SeedsEntry
+	58,  // 19: engine.RunManifest.created:type_name -> google.protobuf.Timestamp
+	5,   // 20: engine.CandleOpenEvent.base_event:type_name -> engine.BaseEvent
+	58,  // 21: engine.CandleOpenEvent.candle_time:type_name -> google.protobuf.Timestamp
+	5,   // 22: engine.CandleCloseEvent.base_event:type_name -> engine.BaseEvent
+	7,   // 23: engine.CandleCloseEvent.candle:type_name -> engine.Candle
+	5,   // 24: engine.CandlesHistoryEvent.base_event:type_name -> engine.BaseEvent
+	7,   // 25: engine.CandlesHistoryEvent.candles:type_name -> engine.Candle
+	5,   // 26: engine.NewTickEvent.base_event:type_name -> engine.BaseEvent
+	9,   // 27: engine.NewTickEvent.tick:type_name -> engine.Tick
+	5,   // 28: engine.TickHistoryEvent.base_event:type_name -> engine.BaseEvent
+	9,   // 29: engine.TickHistoryEvent.ticks:type_name -> engine.Tick
+	5,   // 30: engine.NewOrderEvent.base_event:type_name -> engine.BaseEvent
+	10,  // 31: engine.NewOrderEvent.linked_order:type_name -> engine.Order
+	5,   // 32: engine.OrderConfirmationEvent.base_event:type_name -> engine.BaseEvent
+	5,   // 33: engine.OrderFillEvent.base_event:type_name -> engine.BaseEvent
+	12,  // 34: engine.OrderFillEvent.fees:type_name -> engine.FillFees
+	5,   // 35: engine.OrderCancelEvent.base_event:type_name -> engine.BaseEvent
+	5,   // 36: engine.OrderCancelRejectEvent.base_event:type_name -> engine.BaseEvent
+	5,   // 37: engine.OrderCancelRequestEvent.base_event:type_name -> engine.BaseEvent
+	5,   // 38: engine.OrderReplaceRequestEvent.base_event:type_name -> engine.BaseEvent
+	5,   // 39: engine.OrderReplaceRejectEvent.base_event:type_name -> engine.BaseEvent
+	5,   // 40: engine.OrderReplacedEvent.base_event:type_name -> engine.BaseEvent
+	5,   // 41: engine.OrderRejectedEvent.base_event:type_name -> engine.BaseEvent
+	5,   // 42: engine.StrategyRequestNotDeliveredEvent.base_event:type_name -> engine.BaseEvent
+	55,  // 43: engine.StrategyRequestNotDeliveredEvent.request:type_name -> engine.Event
+	5,   // 44: engine.TimerTickEvent.base_event:type_name -> engine.BaseEvent
+	5,   // 45: engine.TimerEvent.base_event:type_name -> engine.BaseEvent
+	5,   // 46: engine.DataGapEvent.base_event:type_name -> engine.BaseEvent
+	58,  // 47: engine.DataGapEvent.from:type_name -> google.protobuf.Timestamp
+	58,  // 48: engine.DataGapEvent.to:type_name -> google.protobuf.Timestamp
+	5,   // 49: engine.BacktestProgressEvent.base_event:type_name -> engine.BaseEvent
+	58,  // 50: engine.BacktestProgressEvent.sim_time:type_name -> google.protobuf.Timestamp
+	59,  // 51: engine.BacktestProgressEvent.eta:type_name -> google.protobuf.Duration
+	5,   // 52: engine.RollEvent.base_event:type_name -> engine.BaseEvent
+	4,   // 53: engine.RollEvent.from:type_name -> engine.Instrument
+	4,   // 54: engine.RollEvent.to:type_name -> engine.Instrument
+	5,   // 55: engine.OptionChainEvent.base_event:type_name -> engine.BaseEvent
+	11,  // 56: engine.OptionChainEvent.quotes:type_name -> engine.OptionQuote
+	5,   // 57: engine.FundamentalDataEvent.base_event:type_name -> engine.BaseEvent
+	58,  // 58: engine.FundamentalDataEvent.earnings_date:type_name -> google.protobuf.Timestamp
+	57,  // 59: engine.FundamentalDataEvent.ratios:type_name -> engine.FundamentalDataEvent.RatiosEntry
+	5,   // 60: engine.DividendEvent.base_event:type_name -> engine.BaseEvent
+	5,   // 61: engine.NewsEvent.base_event:type_name -> engine.BaseEvent
+	5,   // 62: engine.CustomEvent.base_event:type_name -> engine.BaseEvent
+	60,  // 63: engine.CustomEvent.payload:type_name -> google.protobuf.Value
+	5,   // 64: engine.SymbolAddedEvent.base_event:type_name -> engine.BaseEvent
+	5,   // 65: engine.SymbolRemovedEvent.base_event:type_name -> engine.BaseEvent
+	5,   // 66: engine.EndOfDataEvent.base_event:type_name -> engine.BaseEvent
+	5,   // 67: engine.PortfolioNewPositionEvent.base_event:type_name -> engine.BaseEvent
+	5,   // 68: engine.StrategyHaltedEvent.base_event:type_name -> engine.BaseEvent
+	5,   // 69: engine.MarginCallEvent.base_event:type_name -> engine.BaseEvent
+	5,   // 70: engine.PortfolioKillSwitchEvent.base_event:type_name -> engine.BaseEvent
+	5,   // 71: engine.StalePositionEvent.base_event:type_name -> engine.BaseEvent
+	58,  // 72: engine.StalePositionEvent.open_time:type_name -> google.protobuf.Timestamp
+	59,  // 73: engine.StalePositionEvent.age:type_name -> google.protobuf.Duration
+	5,   // 74: engine.StrategyFinishedEvent.base_event:type_name -> engine.BaseEvent
+	5,   // 75: engine.EventDroppedEvent.base_event:type_name -> engine.BaseEvent
+	5,   // 76: engine.StrategyErrorEvent.base_event:type_name -> engine.BaseEvent
+	5,   // 77: engine.EndOfDayEvent.base_event:type_name -> engine.BaseEvent
+	58,  // 78: engine.EndOfDayEvent.date:type_name -> google.protobuf.Timestamp
+	5,   // 79: engine.RunManifestEvent.base_event:type_name -> engine.BaseEvent
+	13,  // 80: engine.RunManifestEvent.manifest:type_name -> engine.RunManifest
+	5,   // 81: engine.AuctionImbalanceEvent.base_event:type_name -> engine.BaseEvent
+	5,   // 82: engine.DelistingEvent.base_event:type_name -> engine.BaseEvent
+	14,  // 83: engine.Event.candle_open_event:type_name -> engine.CandleOpenEvent
+	15,  // 84: engine.Event.candle_close_event:type_name -> engine.CandleCloseEvent
+	16,  // 85: engine.Event.candles_history_event:type_name -> engine.CandlesHistoryEvent
+	17,  // 86: engine.Event.new_tick_event:type_name -> engine.NewTickEvent
+	18,  // 87: engine.Event.tick_history_event:type_name -> engine.TickHistoryEvent
+	19,  // 88: engine.Event.new_order_event:type_name -> engine.NewOrderEvent
+	20,  // 89: engine.Event.order_confirmation_event:type_name -> engine.OrderConfirmationEvent
+	21,  // 90: engine.Event.order_fill_event:type_name -> engine.OrderFillEvent
+	22,  // 91: engine.Event.order_cancel_event:type_name -> engine.OrderCancelEvent
+	23,  // 92: engine.Event.order_cancel_reject_event:type_name -> engine.OrderCancelRejectEvent
+	24,  // 93: engine.Event.order_cancel_request_event:type_name -> engine.OrderCancelRequestEvent
+	25,  // 94: engine.Event.order_replace_request_event:type_name -> engine.OrderReplaceRequestEvent
+	26,  // 95: engine.Event.order_replace_reject_event:type_name -> engine.OrderReplaceRejectEvent
+	27,  // 96: engine.Event.order_replaced_event:type_name -> engine.OrderReplacedEvent
+	28,  // 97: engine.Event.order_rejected_event:type_name -> engine.OrderRejectedEvent
+	29,  // 98: engine.Event.strategy_request_not_delivered_event:type_name -> engine.StrategyRequestNotDeliveredEvent
+	30,  // 99: engine.Event.timer_tick_event:type_name -> engine.TimerTickEvent
+	31,  // 100: engine.Event.timer_event:type_name -> engine.TimerEvent
+	32,  // 101: engine.Event.data_gap_event:type_name -> engine.DataGapEvent
+	33,  // 102: engine.Event.backtest_progress_event:type_name -> engine.BacktestProgressEvent
+	34,  // 103: engine.Event.roll_event:type_name -> engine.RollEvent
+	35,  // 104: engine.Event.option_chain_event:type_name -> engine.OptionChainEvent
+	36,  // 105: engine.Event.fundamental_data_event:type_name -> engine.FundamentalDataEvent
+	37,  // 106: engine.Event.dividend_event:type_name -> engine.DividendEvent
+	38,  // 107: engine.Event.news_event:type_name -> engine.NewsEvent
+	39,  // 108: engine.Event.custom_event:type_name -> engine.CustomEvent
+	40,  // 109: engine.Event.symbol_added_event:type_name -> engine.SymbolAddedEvent
+	41,  // 110: engine.Event.symbol_removed_event:type_name -> engine.SymbolRemovedEvent
+	42,  // 111: engine.Event.end_of_data_event:type_name -> engine.EndOfDataEvent
+	43,  // 112: engine.Event.portfolio_new_position_event:type_name -> engine.PortfolioNewPositionEvent
+	44,  // 113: engine.Event.strategy_halted_event:type_name -> engine.StrategyHaltedEvent
+	45,  // 114: engine.Event.margin_call_event:type_name -> engine.MarginCallEvent
+	46,  // 115: engine.Event.portfolio_kill_switch_event:type_name -> engine.PortfolioKillSwitchEvent
+	47,  // 116: engine.Event.stale_position_event:type_name -> engine.StalePositionEvent
+	48,  // 117: engine.Event.strategy_finished_event:type_name -> engine.StrategyFinishedEvent
+	49,  // 118: engine.Event.event_dropped_event:type_name -> engine.EventDroppedEvent
+	50,  // 119: engine.Event.strategy_error_event:type_name -> engine.StrategyErrorEvent
+	51,  // 120: engine.Event.end_of_day_event:type_name -> engine.EndOfDayEvent
+	52,  // 121: engine.Event.run_manifest_event:type_name -> engine.RunManifestEvent
+	53,  // 122: engine.Event.auction_imbalance_event:type_name -> engine.AuctionImbalanceEvent
+	54,  // 123: engine.Event.delisting_event:type_name -> engine.DelistingEvent
+	124, // [124:124] is the sub-list for method output_type
+	124, // [124:124] is the sub-list for method input_type
+	124, // [124:124] is the sub-list for extension type_name
+	124, // [124:124] is the sub-list for extension extendee
+	0,   // [0:124] is the sub-list for field type_name
+}
+
+func init() { file_events_proto_init() }
+func file_events_proto_init() {
+	if File_events_proto != nil {
+		return
+	}
+	file_events_proto_msgTypes[55].OneofWrappers = []any{
+		(*Event_CandleOpenEvent)(nil),
+		(*Event_CandleCloseEvent)(nil),
+		(*Event_CandlesHistoryEvent)(nil),
+		(*Event_NewTickEvent)(nil),
+		(*Event_TickHistoryEvent)(nil),
+		(*Event_NewOrderEvent)(nil),
+		(*Event_OrderConfirmationEvent)(nil),
+		(*Event_OrderFillEvent)(nil),
+		(*Event_OrderCancelEvent)(nil),
+		(*Event_OrderCancelRejectEvent)(nil),
+		(*Event_OrderCancelRequestEvent)(nil),
+		(*Event_OrderReplaceRequestEvent)(nil),
+		(*Event_OrderReplaceRejectEvent)(nil),
+		(*Event_OrderReplacedEvent)(nil),
+		(*Event_OrderRejectedEvent)(nil),
+		(*Event_StrategyRequestNotDeliveredEvent)(nil),
+		(*Event_TimerTickEvent)(nil),
+		(*Event_TimerEvent)(nil),
+		(*Event_DataGapEvent)(nil),
+		(*Event_BacktestProgressEvent)(nil),
+		(*Event_RollEvent)(nil),
+		(*Event_OptionChainEvent)(nil),
+		(*Event_FundamentalDataEvent)(nil),
+		(*Event_DividendEvent)(nil),
+		(*Event_NewsEvent)(nil),
+		(*Event_CustomEvent)(nil),
+		(*Event_SymbolAddedEvent)(nil),
+		(*Event_SymbolRemovedEvent)(nil),
+		(*Event_EndOfDataEvent)(nil),
+		(*Event_PortfolioNewPositionEvent)(nil),
+		(*Event_StrategyHaltedEvent)(nil),
+		(*Event_MarginCallEvent)(nil),
+		(*Event_PortfolioKillSwitchEvent)(nil),
+		(*Event_StalePositionEvent)(nil),
+		(*Event_StrategyFinishedEvent)(nil),
+		(*Event_EventDroppedEvent)(nil),
+		(*Event_StrategyErrorEvent)(nil),
+		(*Event_EndOfDayEvent)(nil),
+		(*Event_RunManifestEvent)(nil),
+		(*Event_AuctionImbalanceEvent)(nil),
+		(*Event_DelistingEvent)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_events_proto_rawDesc), len(file_events_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   58,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_events_proto_goTypes,
+		DependencyIndexes: file_events_proto_depIdxs,
+		MessageInfos:      file_events_proto_msgTypes,
+	}.Build()
+	File_events_proto = out.File
+	file_events_proto_goTypes = nil
+	file_events_proto_depIdxs = nil
+}
@@ -3,6 +3,7 @@ package engine
 import (
 	"fmt"
 	"strconv"
+	"sync/atomic"
 	"time"
 )
 
@@ -16,12 +17,24 @@ type event interface {
 type BaseEvent struct {
 	Time   time.Time
 	Ticker *Instrument
+	//Seq is number of the event in order of generation. It orders events of the same time.
+	Seq uint64
+	//CorrelationID is ID of strategy decision the event belongs to: orders, requests, broker events and
+	//portfolio updates caused by one decision have the same ID
+	CorrelationID string
 }
 
 func (c *BaseEvent) getSymbol() string {
+	if c.Ticker == nil {
+		return ""
+	}
 	return c.Ticker.Symbol
 }
 
+func (c *BaseEvent) base() *BaseEvent {
+	return c
+}
+
 func (c *BaseEvent) getTime() time.Time {
 	return c.Time
 }
@@ -35,10 +48,35 @@ func (c *BaseEvent) String() string {
 }
 
 func be(datetime time.Time, symbol *Instrument) BaseEvent {
-	b := BaseEvent{Time: datetime, Ticker: symbol}
+	b := BaseEvent{Time: datetime, Ticker: symbol, Seq: nextEventSeq()}
 	return b
 }
 
+//eventSeq is the last sequence number given to event
+var eventSeq uint64
+
+//nextEventSeq returns sequence number for new event. Numbers grow monotonically across all goroutines.
+func nextEventSeq() uint64 {
+	return atomic.AddUint64(&eventSeq, 1)
+}
+
+//eventSeqOf returns sequence number of the event or zero if it has no BaseEvent
+func eventSeqOf(e event) uint64 {
+	if b, ok := e.(interface{ base() *BaseEvent }); ok {
+		return b.base().Seq
+	}
+	return 0
+}
+
+//eventBefore orders events by time and by sequence number when time is the same
+func eventBefore(a event, b event) bool {
+	ta, tb := a.getTime(), b.getTime()
+	if !ta.Equal(tb) {
+		return ta.Before(tb)
+	}
+	return eventSeqOf(a) < eventSeqOf(b)
+}
+
 type CandleOpenEvent struct {
 	BaseEvent
 	CandleTime time.Time
@@ -64,16 +102,20 @@ func (e *CandleCloseEvent) setEventTimeFromCandle() {
 	if e.Candle == nil {
 		panic("Can't get Event time from Nil candle")
 	}
+	candleTime := e.Candle.Datetime
+	if e.Candle.Ticker != nil {
+		candleTime = e.Candle.Ticker.Exchange.LocalTime(candleTime)
+	}
 	switch e.TimeFrame {
 	case "D":
-		closeTime := e.Candle.Datetime
+		closeTime := candleTime
 		closeTime = time.Date(closeTime.Year(), closeTime.Month(), closeTime.Day(), 23, 59, 59, 0,
-			e.Candle.Datetime.Location())
+			candleTime.Location())
 		e.BaseEvent.Time = closeTime
 	case "W":
-		closeTime := e.Candle.Datetime.AddDate(0, 0, 7)
+		closeTime := candleTime.AddDate(0, 0, 7)
 		closeTime = time.Date(closeTime.Year(), closeTime.Month(), closeTime.Day(), 23, 59, 59, 0,
-			e.Candle.Datetime.Location())
+			candleTime.Location())
 		e.BaseEvent.Time = closeTime
 	default:
 		minutes, err := strconv.ParseInt(e.TimeFrame, 10, 8)
@@ -163,6 +205,11 @@ type OrderFillEvent struct {
 	OrdId string
 	Price float64
 	Qty   int64
+	//Commission and Slippage are costs of the execution in quote currency. Price already includes slippage.
+	Commission float64
+	Slippage   float64
+	//Fees are components of Commission
+	Fees FillFees
 }
 
 func (c *OrderFillEvent) getName() string {
@@ -214,10 +261,13 @@ func (c *OrderCancelRequestEvent) String() string {
 	return fmt.Sprintf("%v **%v** OrderID: %v", c.getStringTime(), c.getName(), c.OrdId)
 }
 
+//OrderReplaceRequestEvent changes price or qty of the order. NewQty is zero if qty is not changed. NewPrice
+//is zero if only qty is changed.
 type OrderReplaceRequestEvent struct {
 	BaseEvent
 	OrdId    string
 	NewPrice float64
+	NewQty   int64
 }
 
 func (c *OrderReplaceRequestEvent) getName() string {
@@ -225,7 +275,8 @@ func (c *OrderReplaceRequestEvent) getName() string {
 }
 
 func (c *OrderReplaceRequestEvent) String() string {
-	return fmt.Sprintf("%v **%v** OrderId:%v New Price: %v", c.getStringTime(), c.getName(), c.OrdId, c.NewPrice)
+	return fmt.Sprintf("%v **%v** OrderId:%v New Price: %v New Qty: %v", c.getStringTime(), c.getName(), c.OrdId,
+		c.NewPrice, c.NewQty)
 }
 
 type OrderReplaceRejectEvent struct {
@@ -242,10 +293,12 @@ func (c *OrderReplaceRejectEvent) String() string {
 	return fmt.Sprintf("%v **%v** OrderId: %v Reason: %v", c.getStringTime(), c.getName(), c.OrdId, c.Reason)
 }
 
+//OrderReplacedEvent confirms replacement. NewQty is zero if qty was not changed.
 type OrderReplacedEvent struct {
 	BaseEvent
 	OrdId    string
 	NewPrice float64
+	NewQty   int64
 }
 
 func (c *OrderReplacedEvent) getName() string {
@@ -253,7 +306,8 @@ func (c *OrderReplacedEvent) getName() string {
 }
 
 func (c *OrderReplacedEvent) String() string {
-	return fmt.Sprintf("%v **%v** OrderId: %v New Price: %v", c.getStringTime(), c.getName(), c.OrdId, c.NewPrice)
+	return fmt.Sprintf("%v **%v** OrderId: %v New Price: %v New Qty: %v", c.getStringTime(), c.getName(), c.OrdId,
+		c.NewPrice, c.NewQty)
 }
 
 type OrderRejectedEvent struct {
@@ -273,14 +327,15 @@ func (c *OrderRejectedEvent) String() string {
 type StrategyRequestNotDeliveredEvent struct {
 	BaseEvent
 	Request event
+	Reason  string
 }
 
 func (c *StrategyRequestNotDeliveredEvent) getName() string {
-	return fmt.Sprintf("StrategyRequestNotDeliveredEvent: Reason: %+v", c.Request)
+	return "StrategyRequestNotDeliveredEvent"
 }
 
 func (c *StrategyRequestNotDeliveredEvent) String() string {
-	return fmt.Sprintf("%v **%v** Request: %+v", c.getStringTime(), c.getName(), c.Request)
+	return fmt.Sprintf("%v **%v** Request: %+v Reason: %v", c.getStringTime(), c.getName(), c.Request, c.Reason)
 }
 
 type TimerTickEvent struct {
@@ -291,6 +346,258 @@ func (c *TimerTickEvent) getName() string {
 	return "TimerTickEvent"
 }
 
+//TimerEvent is produced by engine when strategy timer fires
+type TimerEvent struct {
+	BaseEvent
+	Name string
+}
+
+func (c *TimerEvent) getName() string {
+	return "TimerEvent"
+}
+
+func (c *TimerEvent) String() string {
+	return fmt.Sprintf("%v **%v** %v Timer: %v", c.getStringTime(), c.getName(), c.getSymbol(), c.Name)
+}
+
+//DataGapEvent is produced by market data when time between two consecutive ticks or candles of the symbol
+//is larger than configured threshold
+type DataGapEvent struct {
+	BaseEvent
+	From time.Time
+	To   time.Time
+}
+
+func (c *DataGapEvent) getName() string {
+	return "DataGapEvent"
+}
+
+func (c *DataGapEvent) String() string {
+	return fmt.Sprintf("%v **%v** From: %v To: %v", c.getStringTime(), c.getName(), c.From, c.To)
+}
+
+func (c *DataGapEvent) Duration() time.Duration {
+	return c.To.Sub(c.From)
+}
+
+//BacktestProgressEvent is produced by BTM periodically during the run. Percent is part of the date range
+//which is already replayed. ETA is estimated wall clock time till the end of the run.
+type BacktestProgressEvent struct {
+	BaseEvent
+	Percent         float64
+	EventsProcessed int64
+	SimTime         time.Time
+	ETA             time.Duration
+}
+
+func (c *BacktestProgressEvent) getName() string {
+	return "BacktestProgressEvent"
+}
+
+func (c *BacktestProgressEvent) String() string {
+	return fmt.Sprintf("%v **%v** %.2f%% Events: %v ETA: %v", c.getStringTime(), c.getName(), c.Percent,
+		c.EventsProcessed, c.ETA)
+}
+
+//RollEvent is produced by market data when front contract of the futures chain changes. Event symbol is
+//chain root. FromPrice and ToPrice are last prices of the old and new front contracts.
+type RollEvent struct {
+	BaseEvent
+	From       *Instrument
+	To         *Instrument
+	FromPrice  float64
+	ToPrice    float64
+	BackAdjust BackAdjustMode
+}
+
+func (c *RollEvent) getName() string {
+	return "RollEvent"
+}
+
+func (c *RollEvent) String() string {
+	return fmt.Sprintf("%v **%v** %v: %v (%v) -> %v (%v)", c.getStringTime(), c.getName(), c.getSymbol(),
+		c.From.Symbol, c.FromPrice, c.To.Symbol, c.ToPrice)
+}
+
+//Difference returns price gap between new and old front contracts
+func (c *RollEvent) Difference() float64 {
+	if c.FromPrice <= 0 || c.ToPrice <= 0 {
+		return 0
+	}
+	return c.ToPrice - c.FromPrice
+}
+
+//adjustPrice moves price of the old contract to the level of the new one according to back adjustment mode
+func (c *RollEvent) adjustPrice(price float64) float64 {
+	if price == 0 || c.FromPrice <= 0 || c.ToPrice <= 0 {
+		return price
+	}
+	switch c.BackAdjust {
+	case BackAdjustDifference:
+		return price + c.Difference()
+	case BackAdjustRatio:
+		return price * c.ToPrice / c.FromPrice
+	default:
+		return price
+	}
+}
+
+//OptionChainEvent is produced by market data for option chain snapshot of the underlying symbol
+type OptionChainEvent struct {
+	BaseEvent
+	Quotes []*OptionQuote
+}
+
+func (c *OptionChainEvent) getName() string {
+	return "OptionChainEvent"
+}
+
+func (c *OptionChainEvent) String() string {
+	return fmt.Sprintf("%v **%v** %v Options: %v", c.getStringTime(), c.getName(), c.getSymbol(), len(c.Quotes))
+}
+
+//FundamentalDataEvent is produced by market data when new fundamental data of the symbol becomes known
+type FundamentalDataEvent struct {
+	BaseEvent
+	EarningsDate time.Time
+	EPS          float64
+	EPSEstimate  float64
+	Ratios       map[string]float64
+}
+
+func (c *FundamentalDataEvent) getName() string {
+	return "FundamentalDataEvent"
+}
+
+func (c *FundamentalDataEvent) String() string {
+	return fmt.Sprintf("%v **%v** %v EPS: %v Estimate: %v Earnings: %v", c.getStringTime(), c.getName(),
+		c.getSymbol(), c.EPS, c.EPSEstimate, c.EarningsDate.Format("2006-01-02"))
+}
+
+//DividendEvent is produced by market data on ex-dividend date of the symbol. Amount is paid for one unit of qty.
+type DividendEvent struct {
+	BaseEvent
+	Amount float64
+}
+
+func (c *DividendEvent) getName() string {
+	return "DividendEvent"
+}
+
+func (c *DividendEvent) String() string {
+	return fmt.Sprintf("%v **%v** %v Amount: %v", c.getStringTime(), c.getName(), c.getSymbol(), c.Amount)
+}
+
+//AuctionImbalanceEvent is produced by market data before the open with indicative price and imbalance of the
+//opening auction. Final event has price and qty of the auction print.
+type AuctionImbalanceEvent struct {
+	BaseEvent
+	IndicativePrice float64
+	PairedQty       int64
+	ImbalanceQty    int64
+	ImbalanceSide   OrderSide
+	Final           bool
+}
+
+func (c *AuctionImbalanceEvent) getName() string {
+	return "AuctionImbalanceEvent"
+}
+
+func (c *AuctionImbalanceEvent) String() string {
+	return fmt.Sprintf("%v **%v** %v Price: %v Paired: %v Imbalance: %v %v Final: %v", c.getStringTime(),
+		c.getName(), c.getSymbol(), c.IndicativePrice, c.PairedQty, c.ImbalanceQty, c.ImbalanceSide, c.Final)
+}
+
+//DelistingEvent is produced by market data on delisting date of the symbol. Positions are closed at Price.
+type DelistingEvent struct {
+	BaseEvent
+	Price float64
+}
+
+func (c *DelistingEvent) getName() string {
+	return "DelistingEvent"
+}
+
+func (c *DelistingEvent) String() string {
+	return fmt.Sprintf("%v **%v** %v Price: %v", c.getStringTime(), c.getName(), c.getSymbol(), c.Price)
+}
+
+//NewsEvent is produced by market data for news about the symbol
+type NewsEvent struct {
+	BaseEvent
+	Headline  string
+	Source    string
+	Sentiment float64
+}
+
+func (c *NewsEvent) getName() string {
+	return "NewsEvent"
+}
+
+func (c *NewsEvent) String() string {
+	return fmt.Sprintf("%v **%v** %v Sentiment: %v %v", c.getStringTime(), c.getName(), c.getSymbol(),
+		c.Sentiment, c.Headline)
+}
+
+//CustomEvent is user defined event published by strategy or external component. Event without symbol is
+//delivered to all strategies.
+type CustomEvent struct {
+	BaseEvent
+	Name    string
+	Payload interface{}
+}
+
+func (c *CustomEvent) getName() string {
+	return "CustomEvent"
+}
+
+func (c *CustomEvent) String() string {
+	return fmt.Sprintf("%v **%v** %v Name: %v Payload: %+v", c.getStringTime(), c.getName(), c.getSymbol(),
+		c.Name, c.Payload)
+}
+
+//SymbolAddedEvent is produced by market data when symbol enters the universe
+type SymbolAddedEvent struct {
+	BaseEvent
+}
+
+func (c *SymbolAddedEvent) getName() string {
+	return "SymbolAddedEvent"
+}
+
+func (c *SymbolAddedEvent) String() string {
+	return fmt.Sprintf("%v **%v** Symbol: %v", c.getStringTime(), c.getName(), c.getSymbol())
+}
+
+//SymbolRemovedEvent is produced by market data when symbol leaves the universe
+type SymbolRemovedEvent struct {
+	BaseEvent
+}
+
+func (c *SymbolRemovedEvent) getName() string {
+	return "SymbolRemovedEvent"
+}
+
+func (c *SymbolRemovedEvent) String() string {
+	return fmt.Sprintf("%v **%v** Symbol: %v", c.getStringTime(), c.getName(), c.getSymbol())
+}
+
+//EndOfDayEvent is produced at session close of the symbol exchange. Date is the closed trading day in
+//exchange time zone.
+type EndOfDayEvent struct {
+	BaseEvent
+	Date time.Time
+}
+
+func (c *EndOfDayEvent) getName() string {
+	return "EndOfDayEvent"
+}
+
+func (c *EndOfDayEvent) String() string {
+	return fmt.Sprintf("%v **%v** %v Date: %v", c.getStringTime(), c.getName(), c.getSymbol(),
+		c.Date.Format(calendarDateLayout))
+}
+
 type EndOfDataEvent struct {
 	BaseEvent
 }
@@ -317,6 +624,110 @@ func (c *PortfolioNewPositionEvent) String() string {
 	return fmt.Sprintf("%v **%v** Trade: %+v", c.getStringTime(), c.getName(), c.trade.Id)
 }
 
+//StrategyHaltedEvent is produced by strategy when it's halted by risk limits
+type StrategyHaltedEvent struct {
+	BaseEvent
+	Reason string
+}
+
+func (c *StrategyHaltedEvent) getName() string {
+	return "StrategyHaltedEvent"
+}
+
+func (c *StrategyHaltedEvent) String() string {
+	return fmt.Sprintf("%v **%v** %v Reason: %v", c.getStringTime(), c.getName(), c.getSymbol(), c.Reason)
+}
+
+//StrategyErrorEvent is produced by strategy when callback of user strategy panics. Stack is stack trace of
+//the panic. Disabled is true if user strategy isn't called anymore.
+type StrategyErrorEvent struct {
+	BaseEvent
+	Strategy string
+	Callback string
+	Error    string
+	Stack    string
+	Disabled bool
+}
+
+func (c *StrategyErrorEvent) getName() string {
+	return "StrategyErrorEvent"
+}
+
+func (c *StrategyErrorEvent) String() string {
+	return fmt.Sprintf("%v **%v** %v %v %v panicked: %v Disabled: %v\n%v", c.getStringTime(), c.getName(),
+		c.getSymbol(), c.Strategy, c.Callback, c.Error, c.Disabled, c.Stack)
+}
+
+//EventDroppedEvent is produced by strategy queue when market data is dropped by backpressure policy. Event is
+//name of dropped event, it's empty if spilled event couldn't be read back.
+type EventDroppedEvent struct {
+	BaseEvent
+	Event    string
+	Strategy string
+}
+
+func (c *EventDroppedEvent) getName() string {
+	return "EventDroppedEvent"
+}
+
+func (c *EventDroppedEvent) String() string {
+	return fmt.Sprintf("%v **%v** %v %v Dropped: %v", c.getStringTime(), c.getName(), c.getSymbol(), c.Strategy,
+		c.Event)
+}
+
+//MarginCallEvent is produced when equity of the account falls below maintenance margin of all positions
+type MarginCallEvent struct {
+	BaseEvent
+	Equity            float64
+	MaintenanceMargin float64
+	Liquidate         bool
+	Destination       string
+}
+
+func (c *MarginCallEvent) getName() string {
+	return "MarginCallEvent"
+}
+
+func (c *MarginCallEvent) String() string {
+	return fmt.Sprintf("%v **%v** %v Equity: %v, Maintenance margin: %v, Liquidate: %v", c.getStringTime(),
+		c.getName(), c.getSymbol(), c.Equity, c.MaintenanceMargin, c.Liquidate)
+}
+
+//PortfolioKillSwitchEvent is produced when drawdown of the account equity from its peak exceeds max drawdown.
+//Strategies can't open new positions until kill switch is reset.
+type PortfolioKillSwitchEvent struct {
+	BaseEvent
+	Equity     float64
+	PeakEquity float64
+	Drawdown   float64
+}
+
+func (c *PortfolioKillSwitchEvent) getName() string {
+	return "PortfolioKillSwitchEvent"
+}
+
+func (c *PortfolioKillSwitchEvent) String() string {
+	return fmt.Sprintf("%v **%v** %v Equity: %v, Peak equity: %v, Drawdown: %v", c.getStringTime(), c.getName(),
+		c.getSymbol(), c.Equity, c.PeakEquity, c.Drawdown)
+}
+
+//StalePositionEvent is produced when position of the strategy is open longer than max holding period
+type StalePositionEvent struct {
+	BaseEvent
+	Qty      int64
+	OpenTime time.Time
+	Age      time.Duration
+}
+
+func (c *StalePositionEvent) getName() string {
+	return "StalePositionEvent"
+}
+
+func (c *StalePositionEvent) String() string {
+	return fmt.Sprintf("%v **%v** %v Qty: %v, Open time: %v, Age: %v", c.getStringTime(), c.getName(),
+		c.getSymbol(), c.Qty, c.OpenTime, c.Age)
+}
+
 type StrategyFinishedEvent struct {
 	BaseEvent
 	strategy string
@@ -0,0 +1,266 @@
+package engine
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+//ISignalGenerator is signal layer of the strategy. It returns target position when it wants to change it.
+//Second value is false if there is no new signal.
+type ISignalGenerator interface {
+	OnTick(b *BasicStrategy, tick *Tick) (int64, bool)
+	OnCandleClose(b *BasicStrategy, candle *Candle) (int64, bool)
+}
+
+//IExecutionAlgo is execution layer of the strategy. It gets target position from signal layer and manages
+//orders to reach it. OnUpdate is called after every tick and candle, so algo can check timeouts and schedules.
+type IExecutionAlgo interface {
+	OnSignal(b *BasicStrategy, target int64)
+	OnUpdate(b *BasicStrategy)
+}
+
+//SignalStrategy is user strategy which pairs signal generator with execution algo
+type SignalStrategy struct {
+	Signals   ISignalGenerator
+	Execution IExecutionAlgo
+	target    int64
+	hasTarget bool
+}
+
+func NewSignalStrategy(signals ISignalGenerator, execution IExecutionAlgo) *SignalStrategy {
+	return &SignalStrategy{Signals: signals, Execution: execution}
+}
+
+func (s *SignalStrategy) OnTick(b *BasicStrategy, tick *Tick) {
+	target, ok := s.Signals.OnTick(b, tick)
+	s.onSignal(b, target, ok)
+}
+
+func (s *SignalStrategy) OnCandleClose(b *BasicStrategy, candle *Candle) {
+	target, ok := s.Signals.OnCandleClose(b, candle)
+	s.onSignal(b, target, ok)
+}
+
+func (s *SignalStrategy) OnCandleOpen(b *BasicStrategy, price float64) {
+	s.Execution.OnUpdate(b)
+}
+
+//Target returns last target position from signal layer
+func (s *SignalStrategy) Target() int64 {
+	return s.target
+}
+
+func (s *SignalStrategy) onSignal(b *BasicStrategy, target int64, ok bool) {
+	if ok && (!s.hasTarget || target != s.target) {
+		s.target = target
+		s.hasTarget = true
+		s.Execution.OnSignal(b, target)
+	}
+	s.Execution.OnUpdate(b)
+}
+
+//lastPrice returns price of the last trade or close of the last candle if strategy has no ticks
+func (b *BasicStrategy) lastPrice() float64 {
+	var tick *Tick
+	for i := len(b.Ticks) - 1; i >= 0; i-- {
+		if b.Ticks[i].HasTrade() {
+			tick = b.Ticks[i]
+			break
+		}
+	}
+	var candle *Candle
+	if len(b.Candles) > 0 {
+		candle = b.Candles[len(b.Candles)-1]
+	}
+	switch {
+	case tick != nil && (candle == nil || !tick.Datetime.Before(candle.Datetime)):
+		return tick.LastPrice
+	case candle != nil:
+		return candle.Close
+	}
+	return math.NaN()
+}
+
+//MarketExecution reaches target position with market orders as soon as signal comes
+type MarketExecution struct {
+	Destination string
+}
+
+func NewMarketExecution(destination string) *MarketExecution {
+	return &MarketExecution{Destination: destination}
+}
+
+func (e *MarketExecution) OnSignal(b *BasicStrategy, target int64) {
+	if err := b.SetTargetPosition(target, e.Destination); err != nil {
+		b.newError(err)
+	}
+}
+
+func (e *MarketExecution) OnUpdate(b *BasicStrategy) {}
+
+//slicedExecution keeps one working order of execution algo
+type slicedExecution struct {
+	target    int64
+	hasTarget bool
+	orderID   string
+	sentAt    time.Time
+}
+
+//activeOrder returns status of the working order. Nil is returned if there is no working order.
+func (s *slicedExecution) activeOrder(b *BasicStrategy) *OrderStatusInfo {
+	if s.orderID == "" {
+		return nil
+	}
+	info, err := b.OrderStatus(s.orderID)
+	if err != nil || info.State == FilledOrder || info.State == CanceledOrder || info.State == RejectedOrder {
+		s.orderID = ""
+		return nil
+	}
+	return info
+}
+
+func (s *slicedExecution) cancel(b *BasicStrategy, info *OrderStatusInfo) {
+	if info.State == NewOrder || info.PendingCancel {
+		return
+	}
+	if err := b.CancelOrder(info.Id); err != nil {
+		b.newError(err)
+	}
+}
+
+//delta returns side and qty left to reach target
+func (s *slicedExecution) delta(b *BasicStrategy) (OrderSide, int64) {
+	d := s.target - b.Position()
+	if d < 0 {
+		return OrderSell, -d
+	}
+	return OrderBuy, d
+}
+
+//LimitExecution sends limit order at last price improved by Offset. If order isn't filled during Timeout
+//it's canceled and the rest is sent as market order.
+type LimitExecution struct {
+	Destination string
+	Offset      float64
+	Timeout     time.Duration
+	slicedExecution
+	expired bool
+}
+
+func NewLimitExecution(destination string, offset float64, timeout time.Duration) *LimitExecution {
+	return &LimitExecution{Destination: destination, Offset: offset, Timeout: timeout}
+}
+
+func (e *LimitExecution) OnSignal(b *BasicStrategy, target int64) {
+	e.target = target
+	e.hasTarget = true
+	e.expired = false
+	if info := e.activeOrder(b); info != nil {
+		e.cancel(b, info)
+	}
+}
+
+func (e *LimitExecution) OnUpdate(b *BasicStrategy) {
+	if !e.hasTarget {
+		return
+	}
+	if info := e.activeOrder(b); info != nil {
+		if !e.expired && b.mostRecentTime.Sub(e.sentAt) >= e.Timeout {
+			e.expired = true
+		}
+		side, _ := e.delta(b)
+		if e.expired || info.Side != side {
+			e.cancel(b, info)
+		}
+		return
+	}
+
+	side, qty := e.delta(b)
+	if qty == 0 {
+		return
+	}
+	var id string
+	var err error
+	if e.expired {
+		id, err = b.NewMarketOrder(side, qty, DayTIF, e.Destination)
+	} else {
+		price := b.lastPrice()
+		if math.IsNaN(price) {
+			b.newError(errors.New("Limit execution: no price to send order. "))
+			return
+		}
+		if side == OrderBuy {
+			price -= e.Offset
+		} else {
+			price += e.Offset
+		}
+		id, err = b.NewLimitOrder(b.roundToTick(price), side, qty, DayTIF, e.Destination)
+	}
+	if err != nil {
+		b.newError(err)
+		return
+	}
+	e.orderID = id
+	e.sentAt = b.mostRecentTime
+}
+
+//TWAPExecution splits the way to target position into equal market orders sent evenly during Duration
+type TWAPExecution struct {
+	Destination string
+	Duration    time.Duration
+	Slices      int
+	slicedExecution
+	start      time.Time
+	slicesSent int
+}
+
+func NewTWAPExecution(destination string, duration time.Duration, slices int) *TWAPExecution {
+	if slices < 1 {
+		slices = 1
+	}
+	return &TWAPExecution{Destination: destination, Duration: duration, Slices: slices}
+}
+
+func (e *TWAPExecution) OnSignal(b *BasicStrategy, target int64) {
+	e.target = target
+	e.hasTarget = true
+	e.start = b.mostRecentTime
+	e.slicesSent = 0
+}
+
+func (e *TWAPExecution) OnUpdate(b *BasicStrategy) {
+	if !e.hasTarget || e.slicesSent >= e.Slices || e.activeOrder(b) != nil {
+		return
+	}
+	//Slice i is due at start + i * Duration / Slices
+	due := e.start.Add(time.Duration(int64(e.Duration) * int64(e.slicesSent) / int64(e.Slices)))
+	if b.mostRecentTime.Before(due) {
+		return
+	}
+	side, qty := e.delta(b)
+	left := int64(e.Slices - e.slicesSent)
+	e.slicesSent++
+	if qty == 0 {
+		return
+	}
+	sliceQty := qty / left
+	if qty%left != 0 {
+		sliceQty++
+	}
+	id, err := b.NewMarketOrder(side, sliceQty, DayTIF, e.Destination)
+	if err != nil {
+		b.newError(err)
+		return
+	}
+	e.orderID = id
+	e.sentAt = b.mostRecentTime
+}
+
+//roundToTick rounds price to min tick of the instrument
+func (b *BasicStrategy) roundToTick(price float64) float64 {
+	if b.symbol.MinTick <= 0 {
+		return price
+	}
+	return math.Round(price/b.symbol.MinTick) * b.symbol.MinTick
+}
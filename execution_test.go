@@ -0,0 +1,134 @@
+package engine
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type testSignalGenerator struct {
+	targets []int64
+}
+
+func (g *testSignalGenerator) OnTick(b *BasicStrategy, tick *Tick) (int64, bool) {
+	return 0, false
+}
+
+func (g *testSignalGenerator) OnCandleClose(b *BasicStrategy, candle *Candle) (int64, bool) {
+	if len(g.targets) == 0 {
+		return 0, false
+	}
+	t := g.targets[0]
+	g.targets = g.targets[1:]
+	return t, true
+}
+
+func newTestExecutionStrategy() *BasicStrategy {
+	st := newTestBasicStrategy()
+	st.ch.events = make(chan event, 10)
+	go func() {
+		for range st.ch.errors {
+		}
+	}()
+	st.mostRecentTime = time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	return st
+}
+
+func nextTestOrder(t *testing.T, st *BasicStrategy) *Order {
+	assert.Len(t, st.ch.events, 1)
+	return (<-st.ch.events).(*NewOrderEvent).LinkedOrder
+}
+
+func TestSignalStrategy_MarketExecution(t *testing.T) {
+	st := newTestExecutionStrategy()
+	s := NewSignalStrategy(&testSignalGenerator{targets: []int64{100, 100, -100}}, NewMarketExecution("Sim"))
+	candle := newTestMinuteCandle(st.mostRecentTime, 50, 50, 50, 50)
+
+	s.OnCandleClose(st, candle)
+	ord := nextTestOrder(t, st)
+	assert.Equal(t, MarketOrder, ord.Type)
+	assert.Equal(t, int64(100), ord.Qty)
+	st.proxyEvent(&OrderConfirmationEvent{BaseEvent: be(st.mostRecentTime, st.symbol), OrdId: ord.Id})
+	st.proxyEvent(&OrderFillEvent{BaseEvent: be(st.mostRecentTime, st.symbol), OrdId: ord.Id, Price: 50, Qty: 100})
+
+	//Same target is not a new signal
+	s.OnCandleClose(st, candle)
+	assert.Len(t, st.ch.events, 0)
+
+	s.OnCandleClose(st, candle)
+	ord = nextTestOrder(t, st)
+	assert.Equal(t, OrderSell, ord.Side)
+	assert.Equal(t, int64(200), ord.Qty)
+	assert.Equal(t, int64(-100), s.Target())
+}
+
+func TestLimitExecution(t *testing.T) {
+	st := newTestExecutionStrategy()
+	tm := st.mostRecentTime
+	st.putNewCandle(newTestMinuteCandle(tm, 50, 50, 50, 50))
+	e := NewLimitExecution("Sim", 0.1, 5*time.Minute)
+
+	e.OnSignal(st, 100)
+	e.OnUpdate(st)
+	ord := nextTestOrder(t, st)
+	assert.Equal(t, LimitOrder, ord.Type)
+	assert.Equal(t, OrderBuy, ord.Side)
+	assert.InDelta(t, 49.9, ord.Price, 0.0000001)
+	st.proxyEvent(&OrderConfirmationEvent{BaseEvent: be(tm, st.symbol), OrdId: ord.Id})
+	st.proxyEvent(&OrderFillEvent{BaseEvent: be(tm, st.symbol), OrdId: ord.Id, Price: 49.9, Qty: 40})
+
+	st.mostRecentTime = tm.Add(4 * time.Minute)
+	e.OnUpdate(st)
+	assert.Len(t, st.ch.events, 0)
+
+	//Timeout: order is canceled and the rest is sent as market order
+	st.mostRecentTime = tm.Add(5 * time.Minute)
+	e.OnUpdate(st)
+	assert.Len(t, st.ch.events, 1)
+	assert.Equal(t, ord.Id, (<-st.ch.events).(*OrderCancelRequestEvent).OrdId)
+	e.OnUpdate(st)
+	assert.Len(t, st.ch.events, 0)
+	st.proxyEvent(&OrderCancelEvent{BaseEvent: be(st.mostRecentTime, st.symbol), OrdId: ord.Id})
+	e.OnUpdate(st)
+	ord = nextTestOrder(t, st)
+	assert.Equal(t, MarketOrder, ord.Type)
+	assert.Equal(t, int64(60), ord.Qty)
+}
+
+func TestTWAPExecution(t *testing.T) {
+	st := newTestExecutionStrategy()
+	tm := st.mostRecentTime
+	e := NewTWAPExecution("Sim", 3*time.Minute, 3)
+	fill := func(ord *Order) {
+		st.proxyEvent(&OrderConfirmationEvent{BaseEvent: be(st.mostRecentTime, st.symbol), OrdId: ord.Id})
+		st.proxyEvent(&OrderFillEvent{BaseEvent: be(st.mostRecentTime, st.symbol), OrdId: ord.Id, Price: 50,
+			Qty: ord.Qty})
+	}
+
+	e.OnSignal(st, 250)
+	e.OnUpdate(st)
+	ord := nextTestOrder(t, st)
+	assert.Equal(t, int64(84), ord.Qty)
+	fill(ord)
+
+	st.mostRecentTime = tm.Add(30 * time.Second)
+	e.OnUpdate(st)
+	assert.Len(t, st.ch.events, 0)
+
+	st.mostRecentTime = tm.Add(time.Minute)
+	e.OnUpdate(st)
+	ord = nextTestOrder(t, st)
+	assert.Equal(t, int64(83), ord.Qty)
+	fill(ord)
+
+	st.mostRecentTime = tm.Add(2 * time.Minute)
+	e.OnUpdate(st)
+	ord = nextTestOrder(t, st)
+	assert.Equal(t, int64(83), ord.Qty)
+	fill(ord)
+	assert.Equal(t, int64(250), st.Position())
+
+	st.mostRecentTime = tm.Add(10 * time.Minute)
+	e.OnUpdate(st)
+	assert.Len(t, st.ch.events, 0)
+}
@@ -0,0 +1,177 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+//exitOrder is stop loss or take profit of the position. Level is set either by price or by percent from
+//position open price.
+type exitOrder struct {
+	orderType   OrderType
+	price       float64
+	percent     float64
+	destination string
+	id          string
+}
+
+func (e *exitOrder) isActive() bool {
+	return e.price > 0 || e.percent > 0
+}
+
+//targetPrice returns exit price for the trade rounded to min tick of the instrument
+func (e *exitOrder) targetPrice(t *Trade) float64 {
+	price := e.price
+	if price <= 0 {
+		move := t.OpenPrice * e.percent / 100
+		isLong := t.Type == LongTrade
+		isStop := e.orderType == StopOrder
+		if isLong == isStop {
+			move = -move
+		}
+		price = t.OpenPrice + move
+	}
+	if t.Ticker.MinTick > 0 {
+		price = math.Round(price/t.Ticker.MinTick) * t.Ticker.MinTick
+	}
+	return price
+}
+
+func (b *BasicStrategy) setExitOrder(e **exitOrder, orderType OrderType, price float64, percent float64, destination string) error {
+	if price < 0 || percent < 0 || math.IsNaN(price) || math.IsNaN(percent) {
+		return errors.New("Exit order level can't be negative or NaN. ")
+	}
+	if destination == "" {
+		return errors.New("Exit order destination is empty. ")
+	}
+	if *e == nil {
+		*e = &exitOrder{orderType: orderType}
+	}
+	(*e).price = price
+	(*e).percent = percent
+	(*e).destination = destination
+	b.syncExitOrder(*e)
+	return nil
+}
+
+//SetStopLoss sets stop loss price of current and next positions. Stop order for the whole position is
+//maintained automatically: it's resized when position changes and canceled when position is flat.
+//Zero price removes stop loss.
+func (b *BasicStrategy) SetStopLoss(price float64, destination string) error {
+	return b.setExitOrder(&b.stopLoss, StopOrder, price, 0, destination)
+}
+
+//SetStopLossPercent sets stop loss at given percent from position open price
+func (b *BasicStrategy) SetStopLossPercent(percent float64, destination string) error {
+	return b.setExitOrder(&b.stopLoss, StopOrder, 0, percent, destination)
+}
+
+//SetTakeProfit sets take profit price of current and next positions. Limit order for the whole position is
+//maintained the same way as stop loss order. Zero price removes take profit. Stop loss and take profit
+//orders are not linked, so if both are executed at the same time position is reversed.
+func (b *BasicStrategy) SetTakeProfit(price float64, destination string) error {
+	return b.setExitOrder(&b.takeProfit, LimitOrder, price, 0, destination)
+}
+
+//SetTakeProfitPercent sets take profit at given percent from position open price
+func (b *BasicStrategy) SetTakeProfitPercent(percent float64, destination string) error {
+	return b.setExitOrder(&b.takeProfit, LimitOrder, 0, percent, destination)
+}
+
+func (b *BasicStrategy) syncExitOrders() {
+	if b.stopLoss != nil {
+		b.syncExitOrder(b.stopLoss)
+	}
+	if b.takeProfit != nil {
+		b.syncExitOrder(b.takeProfit)
+	}
+}
+
+//syncExitOrder makes exit order match current position. Order with wrong side or qty is canceled and new one
+//is sent after cancel is confirmed. Order with wrong price is replaced. Nothing is done while order or its
+//cancel or replace request waits for broker answer.
+func (b *BasicStrategy) syncExitOrder(e *exitOrder) {
+	var ord *Order
+	if r, ok := b.orders[e.id]; ok && e.id != "" {
+		ord = r.order
+	}
+	if ord != nil && ord.State == RejectedOrder {
+		//Don't send the same order again and again
+		e.price, e.percent = 0, 0
+		b.newError(fmt.Errorf("Exit order %v was rejected. Exit level is removed. ", ord.Id))
+	}
+	if ord != nil && (ord.State == FilledOrder || ord.State == CanceledOrder || ord.State == RejectedOrder) {
+		ord = nil
+	}
+	if ord == nil {
+		e.id = ""
+	} else {
+		_, cancelPending := b.waitingConfirmation["$CAN$"+ord.Id]
+		_, replacePending := b.waitingConfirmation["$REP$"+ord.Id]
+		if ord.State == NewOrder || cancelPending || replacePending {
+			return
+		}
+	}
+
+	pos := b.Position()
+	if !e.isActive() || pos == 0 {
+		if ord != nil {
+			b.cancelExitOrder(ord)
+		}
+		return
+	}
+
+	side := OrderSell
+	qty := pos
+	if pos < 0 {
+		side = OrderBuy
+		qty = -pos
+	}
+	price := e.targetPrice(b.currentTrade)
+
+	if ord != nil {
+		if ord.Side == side && ord.Qty-ord.ExecQty == qty {
+			if ord.Price != price {
+				if err := b.ReplaceOrder(ord.Id, price); err != nil {
+					b.newError(err)
+				}
+			}
+			return
+		}
+		b.cancelExitOrder(ord)
+		return
+	}
+
+	order := Order{
+		Side:        side,
+		Qty:         qty,
+		Ticker:      b.symbol,
+		Price:       price,
+		State:       NewOrder,
+		Type:        e.orderType,
+		Tif:         GTCTIF,
+		Destination: e.destination,
+		Time:        b.now().Add(20 * time.Microsecond),
+		Id:          fmt.Sprintf("%v_%v_%v", price, e.orderType, rand.Float64()),
+		//Exit order belongs to the decision which opened the trade
+		CorrelationID: b.currentTrade.CorrelationID,
+	}
+	if err := b.newOrder(&order); err != nil {
+		b.newError(err)
+		return
+	}
+	e.id = order.Id
+}
+
+func (b *BasicStrategy) isExitOrder(ordID string) bool {
+	return (b.stopLoss != nil && b.stopLoss.id == ordID) || (b.takeProfit != nil && b.takeProfit.id == ordID)
+}
+
+func (b *BasicStrategy) cancelExitOrder(ord *Order) {
+	if err := b.CancelOrder(ord.Id); err != nil {
+		b.newError(err)
+	}
+}
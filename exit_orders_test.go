@@ -0,0 +1,89 @@
+package engine
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestBasicStrategy_SetStopLossPercent(t *testing.T) {
+	st := newTestBasicStrategy()
+	go func() {
+		for range st.ch.events {
+		}
+	}()
+	go func() {
+		for range st.ch.errors {
+		}
+	}()
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	st.mostRecentTime = tm
+
+	assert.Nil(t, st.SetStopLossPercent(2, "Sim"))
+	assert.Equal(t, "", st.stopLoss.id)
+
+	id, err := st.NewLimitOrder(50, OrderBuy, 200, GTCTIF, "Sim")
+	assert.Nil(t, err)
+	st.proxyEvent(&OrderConfirmationEvent{BaseEvent: be(tm, st.symbol), OrdId: id})
+	st.proxyEvent(&OrderFillEvent{BaseEvent: be(tm, st.symbol), OrdId: id, Price: 50, Qty: 100})
+
+	stopID := st.stopLoss.id
+	assert.NotEqual(t, "", stopID)
+	stop := st.currentTrade.NewOrders[stopID]
+	assert.Equal(t, StopOrder, stop.Type)
+	assert.Equal(t, OrderSell, stop.Side)
+	assert.Equal(t, int64(100), stop.Qty)
+	assert.InDelta(t, 49.0, stop.Price, 0.0000001)
+
+	//Position grows after partial fill, so stop order is canceled and sent again for the whole position
+	st.proxyEvent(&OrderConfirmationEvent{BaseEvent: be(tm, st.symbol), OrdId: stopID})
+	st.proxyEvent(&OrderFillEvent{BaseEvent: be(tm, st.symbol), OrdId: id, Price: 50, Qty: 100})
+	info, _ := st.OrderStatus(stopID)
+	assert.True(t, info.PendingCancel)
+
+	st.proxyEvent(&OrderCancelEvent{BaseEvent: be(tm, st.symbol), OrdId: stopID})
+	assert.NotEqual(t, stopID, st.stopLoss.id)
+	stopID = st.stopLoss.id
+	stop = st.currentTrade.NewOrders[stopID]
+	assert.Equal(t, int64(200), stop.Qty)
+
+	//Stop is executed and position is flat, next position gets new stop
+	st.proxyEvent(&OrderConfirmationEvent{BaseEvent: be(tm, st.symbol), OrdId: stopID})
+	st.proxyEvent(&OrderFillEvent{BaseEvent: be(tm, st.symbol), OrdId: stopID, Price: 49, Qty: 200})
+	assert.Equal(t, int64(0), st.Position())
+	assert.Equal(t, "", st.stopLoss.id)
+	st.shutDown()
+}
+
+func TestBasicStrategy_SetTakeProfit(t *testing.T) {
+	st := newTestBasicStrategy()
+	go func() {
+		for range st.ch.events {
+		}
+	}()
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	st.mostRecentTime = tm
+
+	assert.NotNil(t, st.SetTakeProfit(52, ""))
+
+	id, err := st.NewLimitOrder(50, OrderSell, 100, GTCTIF, "Sim")
+	assert.Nil(t, err)
+	st.proxyEvent(&OrderConfirmationEvent{BaseEvent: be(tm, st.symbol), OrdId: id})
+	st.proxyEvent(&OrderFillEvent{BaseEvent: be(tm, st.symbol), OrdId: id, Price: 50, Qty: 100})
+
+	assert.Nil(t, st.SetTakeProfitPercent(4, "Sim"))
+	tp := st.currentTrade.NewOrders[st.takeProfit.id]
+	assert.Equal(t, LimitOrder, tp.Type)
+	assert.Equal(t, OrderBuy, tp.Side)
+	assert.InDelta(t, 48.0, tp.Price, 0.0000001)
+
+	//Position is closed by other order, so take profit is canceled
+	st.proxyEvent(&OrderConfirmationEvent{BaseEvent: be(tm, st.symbol), OrdId: tp.Id})
+	closeID, err := st.NewMarketOrder(OrderBuy, 100, GTCTIF, "Sim")
+	assert.Nil(t, err)
+	st.proxyEvent(&OrderConfirmationEvent{BaseEvent: be(tm, st.symbol), OrdId: closeID})
+	st.proxyEvent(&OrderFillEvent{BaseEvent: be(tm, st.symbol), OrdId: closeID, Price: 49, Qty: 100})
+	info, _ := st.OrderStatus(tp.Id)
+	assert.True(t, info.PendingCancel)
+	st.shutDown()
+}
@@ -0,0 +1,99 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+)
+
+//ExposureLimits is order risk check which keeps positions of all strategies within exposure limits. Exposure is
+//market value of positions in quote currency. Limits are checked on projected exposure after the order is
+//filled, only orders which increase exposure beyond the limit are vetoed. Zero limit is not checked.
+type ExposureLimits struct {
+	//MaxSymbol is max absolute market value of position of any symbol
+	MaxSymbol float64
+	//SymbolLimits overrides MaxSymbol for the symbols
+	SymbolLimits map[string]float64
+	//SectorLimits is max gross exposure of positions by Instrument.Sector
+	SectorLimits map[string]float64
+	MaxGross     float64
+	MaxNet       float64
+}
+
+//exposure is gross and net market value of positions
+type exposure struct {
+	gross float64
+	net   float64
+}
+
+func (e *exposure) add(value float64) {
+	e.gross += math.Abs(value)
+	e.net += value
+}
+
+func (l *ExposureLimits) symbolLimit(symbol string) float64 {
+	if v, ok := l.SymbolLimits[symbol]; ok {
+		return v
+	}
+	return l.MaxSymbol
+}
+
+func (l *ExposureLimits) CheckOrder(o *Order, lastPrice float64, p IPortfolio) error {
+	price := orderPrice(o, lastPrice)
+	if math.IsNaN(price) {
+		return nil
+	}
+	inst := o.Ticker
+	symbol := inst.Symbol
+
+	var before, after, sectorBefore, sectorAfter exposure
+	var qty int64
+	var value float64
+	for s, pos := range p.Positions() {
+		if s == symbol {
+			qty = pos.Qty
+			value = pos.MarketValue
+			continue
+		}
+		before.add(pos.MarketValue)
+		after.add(pos.MarketValue)
+		if inst.Sector != "" && pos.Sector == inst.Sector {
+			sectorBefore.add(pos.MarketValue)
+			sectorAfter.add(pos.MarketValue)
+		}
+	}
+
+	projected := qty + o.Qty
+	if o.Side == OrderSell {
+		projected = qty - o.Qty
+	}
+	projectedValue := price * inst.QtyFloat(projected) * inst.multiplier()
+	before.add(value)
+	after.add(projectedValue)
+	if inst.Sector != "" {
+		sectorBefore.add(value)
+		sectorAfter.add(projectedValue)
+	}
+
+	if err := checkExposureLimit("Symbol "+symbol, math.Abs(value), math.Abs(projectedValue),
+		l.symbolLimit(symbol)); err != nil {
+		return err
+	}
+	if inst.Sector != "" {
+		if err := checkExposureLimit("Sector "+inst.Sector, sectorBefore.gross, sectorAfter.gross,
+			l.SectorLimits[inst.Sector]); err != nil {
+			return err
+		}
+	}
+	if err := checkExposureLimit("Gross", before.gross, after.gross, l.MaxGross); err != nil {
+		return err
+	}
+	return checkExposureLimit("Net", math.Abs(before.net), math.Abs(after.net), l.MaxNet)
+}
+
+//checkExposureLimit returns error if exposure grows beyond the limit
+func checkExposureLimit(name string, before float64, after float64, limit float64) error {
+	if limit <= 0 || after <= limit || after <= before {
+		return nil
+	}
+	return fmt.Errorf("%v exposure %v would exceed limit %v. ", name, after, limit)
+}
@@ -0,0 +1,44 @@
+package engine
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestExposureLimits_CheckOrder(t *testing.T) {
+	p := newPortfolio()
+	p.positions["Test"] = PositionInfo{Symbol: "Test", Sector: "Tech", Qty: 100, MarketValue: 5000}
+	p.positions["Other"] = PositionInfo{Symbol: "Other", Sector: "Tech", Qty: -100, MarketValue: -3000}
+	p.positions["Bank"] = PositionInfo{Symbol: "Bank", Sector: "Finance", Qty: 100, MarketValue: 4000}
+
+	newOrder := func(side OrderSide, qty int64) *Order {
+		ord := newTestRiskOrder(side, qty, 50)
+		ord.Ticker.Sector = "Tech"
+		return ord
+	}
+
+	l := ExposureLimits{MaxSymbol: 6000, SymbolLimits: map[string]float64{"Test": 8000}}
+	assert.Nil(t, l.CheckOrder(newOrder(OrderBuy, 60), 50, p))
+	assert.NotNil(t, l.CheckOrder(newOrder(OrderBuy, 70), 50, p))
+	l.SymbolLimits = nil
+	assert.NotNil(t, l.CheckOrder(newOrder(OrderBuy, 30), 50, p))
+	//Position above the limit can be reduced
+	l.MaxSymbol = 1000
+	assert.Nil(t, l.CheckOrder(newOrder(OrderSell, 50), 50, p))
+
+	l = ExposureLimits{SectorLimits: map[string]float64{"Tech": 9000, "Finance": 1000}}
+	assert.Nil(t, l.CheckOrder(newOrder(OrderBuy, 20), 50, p))
+	assert.NotNil(t, l.CheckOrder(newOrder(OrderBuy, 30), 50, p))
+	assert.NotNil(t, l.CheckOrder(newOrder(OrderSell, 330), 50, p))
+
+	l = ExposureLimits{MaxGross: 13000}
+	assert.Nil(t, l.CheckOrder(newOrder(OrderBuy, 20), 50, p))
+	assert.NotNil(t, l.CheckOrder(newOrder(OrderBuy, 30), 50, p))
+
+	//Net exposure is 6000
+	l = ExposureLimits{MaxNet: 6500}
+	assert.Nil(t, l.CheckOrder(newOrder(OrderBuy, 10), 50, p))
+	assert.NotNil(t, l.CheckOrder(newOrder(OrderBuy, 20), 50, p))
+	assert.Nil(t, l.CheckOrder(newOrder(OrderSell, 250), 50, p))
+	assert.NotNil(t, l.CheckOrder(newOrder(OrderSell, 360), 50, p))
+}
@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"encoding/csv"
+	"io"
+	"sync"
+	"time"
+)
+
+//FillReportRow is fill of the run with its fee components and running totals of fees of all fills up to and
+//including it. Qty is in units of the instrument, e.g. 0.05 BTC. Fees are in quote currency of the symbol, so
+//totals of symbols with different currencies are just sums.
+type FillReportRow struct {
+	Time     time.Time
+	Symbol   string
+	OrderID  string
+	Venue    string
+	Side     OrderSide
+	Qty      float64
+	Price    float64
+	Fees     FillFees
+	Slippage float64
+	Running  FillFees
+}
+
+//FillReport collects every fill of the run from event bus
+type FillReport struct {
+	mut     sync.Mutex
+	orders  map[string]*Order
+	rows    []FillReportRow
+	running FillFees
+}
+
+//RecordFills returns report which collects fills of the engine. It should be called before run.
+func (c *Engine) RecordFills() *FillReport {
+	r := &FillReport{orders: make(map[string]*Order)}
+	c.EventBus().Subscribe(EventFilter{Types: []string{"NewOrderEvent", "OrderFillEvent"}}, r.onEvent)
+	return r
+}
+
+func (r *FillReport) onEvent(e Event) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	switch e := e.(type) {
+	case *NewOrderEvent:
+		if e.LinkedOrder != nil {
+			r.orders[e.LinkedOrder.Id] = e.LinkedOrder
+		}
+	case *OrderFillEvent:
+		row := FillReportRow{Time: e.getTime(), Symbol: e.getSymbol(), OrderID: e.OrdId,
+			Qty: e.Ticker.QtyFloat(e.Qty), Price: e.Price, Fees: e.Fees, Slippage: e.Slippage}
+		if o, ok := r.orders[e.OrdId]; ok {
+			row.Venue = o.Destination
+			row.Side = o.Side
+		}
+		//Fills of brokers without fee breakdown have the whole commission as broker commission
+		if row.Fees == (FillFees{}) {
+			row.Fees.Commission = e.Commission
+		}
+		r.running = r.running.add(row.Fees)
+		row.Running = r.running
+		r.rows = append(r.rows, row)
+	}
+}
+
+//Rows returns fills collected so far in order of their events
+func (r *FillReport) Rows() []FillReportRow {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	return append([]FillReportRow{}, r.rows...)
+}
+
+//Totals returns fees of all fills collected so far
+func (r *FillReport) Totals() FillFees {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	return r.running
+}
+
+//WriteFillsCSV writes fills report as CSV with header
+func WriteFillsCSV(w io.Writer, rows []FillReportRow) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"time", "symbol", "order_id", "venue", "side", "qty", "price", "commission", "exchange_fee",
+		"sec_fee", "taf", "total_fees", "slippage", "cum_commission", "cum_exchange_fee", "cum_sec_fee", "cum_taf",
+		"cum_total_fees"})
+	for _, r := range rows {
+		cw.Write([]string{r.Time.Format(time.RFC3339Nano), r.Symbol, r.OrderID, r.Venue, string(r.Side),
+			formatFloat(r.Qty), formatFloat(r.Price), formatFloat(r.Fees.Commission),
+			formatFloat(r.Fees.ExchangeFee), formatFloat(r.Fees.SECFee), formatFloat(r.Fees.TAF),
+			formatFloat(r.Fees.Total()), formatFloat(r.Slippage), formatFloat(r.Running.Commission),
+			formatFloat(r.Running.ExchangeFee), formatFloat(r.Running.SECFee), formatFloat(r.Running.TAF),
+			formatFloat(r.Running.Total())})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,91 @@
+package engine
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFillReport_onEvent(t *testing.T) {
+	inst := newTestInstrument()
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	r := &FillReport{orders: make(map[string]*Order)}
+	r.onEvent(&NewOrderEvent{BaseEvent: be(tm, inst),
+		LinkedOrder: &Order{Id: "1", Side: OrderSell, Destination: "Sim", Ticker: inst}})
+	r.onEvent(&OrderFillEvent{BaseEvent: be(tm, inst), OrdId: "1", Qty: 10, Price: 5, Commission: 1.5,
+		Fees: FillFees{Commission: 1, SECFee: 0.5}})
+	//Fill without breakdown is broker commission
+	r.onEvent(&OrderFillEvent{BaseEvent: be(tm, inst), OrdId: "2", Qty: 5, Price: 6, Commission: 2})
+
+	rows := r.Rows()
+	if assert.Len(t, rows, 2) {
+		assert.Equal(t, "Sim", rows[0].Venue)
+		assert.Equal(t, OrderSell, rows[0].Side)
+		assert.Equal(t, FillFees{Commission: 1, SECFee: 0.5}, rows[0].Running)
+		assert.Equal(t, FillFees{Commission: 2}, rows[1].Fees)
+		assert.Equal(t, FillFees{Commission: 3, SECFee: 0.5}, rows[1].Running)
+	}
+	assert.Equal(t, 3.5, r.Totals().Total())
+
+	//Qty of fractional instrument is in its units
+	btc := &Instrument{Symbol: "BTCUSD", MinTick: 0.01, QtyPrecision: 8}
+	r.onEvent(&OrderFillEvent{BaseEvent: be(tm, btc), OrdId: "3", Qty: 5000000, Price: 40000, Commission: 1})
+	rows = r.Rows()
+	if assert.Len(t, rows, 3) {
+		assert.Equal(t, 0.05, rows[2].Qty)
+		assert.Equal(t, 10.0, rows[0].Qty)
+	}
+	var buf bytes.Buffer
+	assert.Nil(t, WriteFillsCSV(&buf, rows[2:]))
+	assert.Contains(t, buf.String(), ",BTCUSD,3,,,0.05,40000,")
+}
+
+func TestEngine_RecordFills(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fill_report")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	broker := &SimBroker{}
+	assert.Nil(t, broker.SetCostModel(CostModel{PerUnit: 0.01, Minimum: 0.5, ExchangeFee: 0.003,
+		SECFeeRate: 0.0000278, TAFPerUnit: 0.000166, TAFMax: 8.3}))
+	e, err := New(WithMarketData(newTestBTMforSyntheticTicks(dir, 1)), WithBroker(broker),
+		WithStrategy(&Instrument{Symbol: "Sym1"}, &manifestTestStrategy{}, 1))
+	assert.Nil(t, err)
+	report := e.RecordFills()
+	fills := recordFills(e)
+	e.SetDeterministic(true)
+	e.Run()
+	assert.Nil(t, e.Status().Err)
+
+	//Every fill is reported, running totals are sums of fees
+	rows := report.Rows()
+	assert.Len(t, rows, len(fills.result()))
+	assert.True(t, len(rows) > 5)
+	total := FillFees{}
+	sells := 0
+	for _, r := range rows {
+		total = total.add(r.Fees)
+		assert.InDelta(t, total.Total(), r.Running.Total(), 0.0000001)
+		assert.Equal(t, "Sim", r.Venue)
+		assert.True(t, r.Fees.Commission >= 0.5)
+		if r.Side == OrderSell {
+			sells++
+			assert.True(t, r.Fees.SECFee > 0)
+		} else {
+			assert.Equal(t, 0.0, r.Fees.SECFee+r.Fees.TAF)
+		}
+	}
+	assert.True(t, sells > 0)
+	assert.InDelta(t, total.Total(), report.Totals().Total(), 0.0000001)
+
+	var buf bytes.Buffer
+	assert.Nil(t, WriteFillsCSV(&buf, rows))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, len(rows)+1)
+	assert.True(t, strings.HasPrefix(lines[0], "time,symbol,order_id,venue,side,qty,price,commission,exchange_fee"))
+	assert.Contains(t, lines[1], ",Sym1,")
+}
@@ -0,0 +1,120 @@
+package engine
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+//FinancingRate is annual overnight financing rate of positions quoted in the currency, e.g. 0.06 for 6%. Long
+//rate is margin interest charged on borrowed part of long position, short rate is credit paid on proceeds of
+//short sale.
+type FinancingRate struct {
+	Long  float64
+	Short float64
+}
+
+//SetFinancingRates sets financing rates by quote currency of instruments. Instruments without FX spec are
+//quoted in account currency, they use rate of account currency or rate of empty currency if it isn't set.
+//Financing is accrued at session close for every night since the last one. Long position is leveraged if its
+//instrument has initial margin, interest is charged on its market value not covered by margin. Short
+//position is credited on its whole market value.
+func (c *Engine) SetFinancingRates(rates map[string]FinancingRate) error {
+	copied := make(map[string]FinancingRate, len(rates))
+	for currency, r := range rates {
+		if r.Long < 0 || r.Short < 0 || math.IsNaN(r.Long) || math.IsNaN(r.Short) {
+			return errors.New("Financing rates can't be negative or NaN. ")
+		}
+		copied[currency] = r
+	}
+	c.portfolio.mut.Lock()
+	c.portfolio.financing = copied
+	c.portfolio.mut.Unlock()
+	return nil
+}
+
+//financingRate returns financing rate of the instrument. False is returned if there is no rate for its currency.
+func (p *portfolioHandler) financingRate(inst *Instrument) (FinancingRate, bool) {
+	currency := ""
+	if inst.FX != nil {
+		currency = inst.FX.Quote
+	} else {
+		p.fx.mut.RLock()
+		currency = p.fx.account
+		p.fx.mut.RUnlock()
+	}
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	r, ok := p.financing[currency]
+	if !ok && inst.FX == nil {
+		r, ok = p.financing[""]
+	}
+	return r, ok
+}
+
+//accrueFinancing charges or credits open trades for every night passed since the last financing. It's called at
+//session close, so trades closed within the session aren't financed.
+func (b *BasicStrategy) accrueFinancing(e *EndOfDayEvent) {
+	if b.portfolio == nil {
+		return
+	}
+	rate, ok := b.portfolio.financingRate(b.symbol)
+	if !ok {
+		return
+	}
+	day := time.Date(e.Date.Year(), e.Date.Month(), e.Date.Day(), 0, 0, 0, 0, time.UTC)
+	charged := false
+	for _, t := range b.trades() {
+		if !t.IsOpen() {
+			continue
+		}
+		nights := 1.0
+		if !t.financingDay.IsZero() {
+			nights = math.Round(day.Sub(t.financingDay).Hours() / 24)
+		}
+		if nights <= 0 {
+			continue
+		}
+		t.financingDay = day
+		value := math.Abs(t.toAccount(t.MarketValue))
+		cost := 0.0
+		switch t.Type {
+		case LongTrade:
+			if b.symbol.InitialMargin > 0 && b.symbol.InitialMargin < 1 {
+				cost = value * (1 - b.symbol.InitialMargin) * rate.Long / 365 * nights
+			}
+		case ShortTrade:
+			cost = -value * rate.Short / 365 * nights
+		}
+		if cost == 0 {
+			continue
+		}
+		t.FinancingCost += cost
+		t.ClosedPnL -= cost
+		b.financingPending += cost
+		charged = true
+	}
+	if charged {
+		b.publishPosition()
+	}
+}
+
+//takeFinancingCost returns financing cost which should be charged from portfolio cash
+func (b *BasicStrategy) takeFinancingCost() float64 {
+	cost := b.financingPending
+	b.financingPending = 0
+	return cost
+}
+
+//FinancingCost returns net overnight financing of all trades of the strategy in account currency. It's negative
+//if credit of short positions was larger than interest of long ones.
+func (b *BasicStrategy) FinancingCost() float64 {
+	cost := 0.0
+	for _, t := range b.closedTrades {
+		cost += t.FinancingCost
+	}
+	for _, t := range b.trades() {
+		cost += t.FinancingCost
+	}
+	return cost
+}
@@ -0,0 +1,56 @@
+package engine
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBasicStrategy_accrueFinancing(t *testing.T) {
+	st := newTestMarginStrategy(10000)
+	c := &Engine{portfolio: st.portfolio}
+	day := st.mostRecentTime.Truncate(24 * time.Hour)
+	endOfDay := func(d time.Time) {
+		st.notify(&EndOfDayEvent{BaseEvent: be(d.Add(16*time.Hour), st.symbol), Date: d})
+		st.shutDown()
+	}
+
+	assert.NotNil(t, c.SetFinancingRates(map[string]FinancingRate{"": {Long: math.NaN()}}))
+	assert.Nil(t, c.SetFinancingRates(map[string]FinancingRate{"EUR": {Long: 0.073, Short: 0.0365}}))
+	fillTestOrder(st, OrderBuy, 200, 50)
+	assert.InDelta(t, 0.0, st.Cash(), 0.0000001)
+
+	//Rate of other currency isn't used
+	endOfDay(day)
+	assert.Equal(t, 0.0, st.FinancingCost())
+
+	//Interest is charged on the half of long position value which isn't covered by margin
+	assert.Nil(t, c.SetFinancingRates(map[string]FinancingRate{"": {Long: 0.073, Short: 0.0365}}))
+	endOfDay(day.AddDate(0, 0, 1))
+	assert.InDelta(t, 1.0, st.FinancingCost(), 0.0000001)
+	assert.InDelta(t, -1.0, st.currentTrade.ClosedPnL, 0.0000001)
+	assert.InDelta(t, -1.0, st.Cash(), 0.0000001)
+	endOfDay(day.AddDate(0, 0, 1))
+	assert.InDelta(t, 1.0, st.FinancingCost(), 0.0000001)
+
+	//Every night since the last session close is charged
+	endOfDay(day.AddDate(0, 0, 4))
+	assert.InDelta(t, 4.0, st.FinancingCost(), 0.0000001)
+	assert.InDelta(t, -4.0, st.Cash(), 0.0000001)
+
+	//Short position is credited on its whole value
+	fillTestOrder(st, OrderSell, 400, 50)
+	endOfDay(day.AddDate(0, 0, 5))
+	assert.InDelta(t, 3.0, st.FinancingCost(), 0.0000001)
+	assert.InDelta(t, -1.0, st.currentTrade.FinancingCost, 0.0000001)
+	assert.InDelta(t, 1.0, st.currentTrade.ClosedPnL, 0.0000001)
+	assert.InDelta(t, 19997.0, st.Cash(), 0.0000001)
+
+	//Long position without margin isn't leveraged
+	fillTestOrder(st, OrderBuy, 400, 50)
+	st.symbol.InitialMargin = 0
+	endOfDay(day.AddDate(0, 0, 6))
+	assert.InDelta(t, 3.0, st.FinancingCost(), 0.0000001)
+}
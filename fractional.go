@@ -0,0 +1,34 @@
+package engine
+
+import "math"
+
+//qtyScale returns number of qty units in one whole unit of the instrument
+func (i *Instrument) qtyScale() float64 {
+	if i == nil || i.QtyPrecision <= 0 {
+		return 1
+	}
+	return math.Pow10(i.QtyPrecision)
+}
+
+//Qty converts fractional qty, e.g. 0.05 BTC, to integer qty of orders and trades
+func (i *Instrument) Qty(qty float64) int64 {
+	return int64(math.Round(qty * i.qtyScale()))
+}
+
+//QtyFloat converts integer qty of orders and trades to fractional qty
+func (i *Instrument) QtyFloat(qty int64) float64 {
+	return float64(qty) / i.qtyScale()
+}
+
+//sizeToQty converts size from market data, which is in whole units, to integer qty
+func (i *Instrument) sizeToQty(size int64) int64 {
+	if i == nil || i.QtyPrecision <= 0 {
+		return size
+	}
+	return int64(float64(size) * i.qtyScale())
+}
+
+//PositionFloat returns position in fractional units of the instrument
+func (b *BasicStrategy) PositionFloat() float64 {
+	return b.symbol.QtyFloat(b.Position())
+}
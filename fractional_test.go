@@ -0,0 +1,74 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestInstrument_Qty(t *testing.T) {
+	btc := &Instrument{Symbol: "BTCUSD", MinTick: 0.01, QtyPrecision: 8}
+	assert.Equal(t, int64(5000000), btc.Qty(0.05))
+	assert.Equal(t, 0.05, btc.QtyFloat(5000000))
+	assert.Equal(t, int64(300000000), btc.sizeToQty(3))
+
+	inst := &Instrument{Symbol: "SPY"}
+	assert.Equal(t, int64(100), inst.Qty(100))
+	assert.Equal(t, int64(3), inst.sizeToQty(3))
+}
+
+func TestBasicStrategy_fractionalQty(t *testing.T) {
+	st := newTestBasicStrategy()
+	st.symbol = &Instrument{Symbol: "BTCUSD", MinTick: 0.01, QtyPrecision: 8}
+	st.currentTrade = newFlatTrade(st.symbol)
+	st.ch.events = make(chan event, 10)
+	go func() {
+		for range st.ch.errors {
+		}
+	}()
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	st.mostRecentTime = tm
+
+	for _, side := range []OrderSide{OrderBuy, OrderSell} {
+		price := 10000.0
+		if side == OrderSell {
+			price = 11000
+		}
+		id, err := st.NewMarketOrder(side, st.symbol.Qty(0.05), DayTIF, "Sim")
+		assert.Nil(t, err)
+		st.proxyEvent(&OrderConfirmationEvent{BaseEvent: be(tm, st.symbol), OrdId: id})
+		st.proxyEvent(&OrderFillEvent{BaseEvent: be(tm, st.symbol), OrdId: id, Price: price, Qty: st.symbol.Qty(0.05)})
+		if side == OrderBuy {
+			assert.Equal(t, 0.05, st.PositionFloat())
+			assert.InDelta(t, 500.0, st.currentTrade.OpenValue, 0.0000001)
+		}
+	}
+	assert.Equal(t, 0.0, st.PositionFloat())
+	assert.InDelta(t, 50.0, st.closedTrades[0].ClosedPnL, 0.0000001)
+}
+
+func TestSimulatedBroker_fractionalFill(t *testing.T) {
+	b := newTestSimBrokerWorker()
+	order := newTestOrder(math.NaN(), OrderBuy, 250000000, "btc")
+	order.Type = MarketOrder
+	order.Ticker = &Instrument{Symbol: "BTCUSD", MinTick: 0.01, QtyPrecision: 8}
+	o := &simBrokerOrder{Order: order, BrokerState: ConfirmedOrder}
+
+	tick := Tick{Tick: &marketdata.Tick{
+		Datetime:  newTestOrderTime().Add(time.Second),
+		Symbol:    "BTCUSD",
+		LastPrice: 10000,
+		LastSize:  1,
+		BidPrice:  math.NaN(),
+		AskPrice:  math.NaN(),
+	}, Ticker: order.Ticker}
+	e := b.fillOnTickMarket(o, &tick).(*OrderFillEvent)
+	assert.Equal(t, int64(250000000), e.Qty)
+
+	tick.AskPrice, tick.AskSize, tick.BidPrice, tick.BidSize = 10001, 2, 9999, 2
+	e = b.fillOnTickMarket(o, &tick).(*OrderFillEvent)
+	assert.Equal(t, int64(200000000), e.Qty)
+	assert.Equal(t, 10001.0, e.Price)
+}
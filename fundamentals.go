@@ -0,0 +1,50 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"time"
+)
+
+//FundamentalData is fundamental state of the company known at Time, e.g. after earnings report.
+//Ratios keep any other values by name, like "PE" or "DebtToEquity".
+type FundamentalData struct {
+	Time         time.Time
+	EarningsDate time.Time
+	EPS          float64
+	EPSEstimate  float64
+	Ratios       map[string]float64
+}
+
+//IFundamentalStorage can be implemented by market data storage or data provider which has fundamental
+//data. BTM produces FundamentalDataEvent for every record of the symbols it works with.
+type IFundamentalStorage interface {
+	GetFundamentals(symbol *Instrument, dRange marketdata.DateRange) ([]*FundamentalData, error)
+}
+
+//loadFundamentals returns fundamental data events of all symbols
+func (m *BTM) loadFundamentals() eventArray {
+	s, ok := m.Storage.(IFundamentalStorage)
+	if !ok {
+		return nil
+	}
+	rng := marketdata.DateRange{From: m.FromDate, To: m.ToDate.AddDate(0, 0, 1)}
+
+	var events eventArray
+	for _, inst := range m.Symbols {
+		data, err := s.GetFundamentals(inst, rng)
+		if err != nil {
+			m.newError(err)
+			continue
+		}
+		for _, d := range data {
+			events = append(events, &FundamentalDataEvent{
+				BaseEvent:    be(d.Time, inst),
+				EarningsDate: d.EarningsDate,
+				EPS:          d.EPS,
+				EPSEstimate:  d.EPSEstimate,
+				Ratios:       d.Ratios,
+			})
+		}
+	}
+	return events
+}
@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+type mockFundamentalStorage struct {
+	*SyntheticStorage
+	data map[string][]*FundamentalData
+}
+
+func (s *mockFundamentalStorage) GetFundamentals(symbol *Instrument, dRange marketdata.DateRange) ([]*FundamentalData, error) {
+	var res []*FundamentalData
+	for _, d := range s.data[symbol.Symbol] {
+		if d.Time.Before(dRange.From) || d.Time.After(dRange.To) {
+			continue
+		}
+		res = append(res, d)
+	}
+	return res, nil
+}
+
+func TestBTM_loadFundamentals(t *testing.T) {
+	from := time.Date(2018, 3, 5, 0, 0, 0, 0, time.UTC)
+	storage := mockFundamentalStorage{
+		SyntheticStorage: NewSyntheticStorage(1, from, 100, &GBMProcess{Volatility: 0.2}),
+		data: map[string][]*FundamentalData{
+			"Sym1": {
+				{Time: from.Add(30 * time.Hour), EPS: 1.2, EPSEstimate: 1.1},
+				{Time: from.AddDate(0, 1, 0), EPS: 1.3},
+			},
+			"Sym2": {
+				{Time: from.Add(10 * time.Hour), EPS: 0.5, Ratios: map[string]float64{"PE": 20}},
+			},
+		},
+	}
+	m := BTM{
+		Symbols:   []*Instrument{{Symbol: "Sym1"}, {Symbol: "Sym2"}},
+		FromDate:  from,
+		ToDate:    from.AddDate(0, 0, 5),
+		Storage:   &storage,
+		waitGroup: &sync.WaitGroup{},
+	}
+
+	events := m.loadSideEvents()
+	assert.Len(t, events, 2)
+
+	first, ok := events[0].(*FundamentalDataEvent)
+	assert.True(t, ok)
+	assert.Equal(t, "Sym2", first.getSymbol())
+	assert.Equal(t, 20.0, first.Ratios["PE"])
+
+	second, ok := events[1].(*FundamentalDataEvent)
+	assert.True(t, ok)
+	assert.Equal(t, "Sym1", second.getSymbol())
+	assert.Equal(t, 1.2, second.EPS)
+}
+
+type fundamentalTestStrategy struct {
+	DummyStrategy
+	got []*FundamentalDataEvent
+}
+
+func (s *fundamentalTestStrategy) OnFundamentalData(b *BasicStrategy, e *FundamentalDataEvent) {
+	s.got = append(s.got, e)
+}
+
+func TestBasicStrategy_onFundamentalData(t *testing.T) {
+	st := newTestBasicStrategy()
+	us := &fundamentalTestStrategy{}
+	st.userStrategy = us
+
+	assert.Nil(t, st.Fundamentals())
+
+	e := &FundamentalDataEvent{BaseEvent: be(time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC), st.symbol), EPS: 2.5}
+	st.notify(e)
+	st.shutDown()
+
+	assert.Equal(t, e, st.Fundamentals())
+	assert.Len(t, us.got, 1)
+}
@@ -0,0 +1,163 @@
+package engine
+
+import (
+	"sort"
+	"time"
+)
+
+type BackAdjustMode string
+
+const (
+	BackAdjustNone       BackAdjustMode = "BackAdjustNone"
+	BackAdjustDifference BackAdjustMode = "BackAdjustDifference"
+	BackAdjustRatio      BackAdjustMode = "BackAdjustRatio"
+)
+
+//FuturesContract is one contract of the futures chain
+type FuturesContract struct {
+	Contract   *Instrument
+	Expiration time.Time
+}
+
+//contractStats keeps last known market data of the contract used by roll rules
+type contractStats struct {
+	price        float64
+	volume       int64
+	openInterest int64
+	hasData      bool
+}
+
+//IRollRule decides when continuous series should move from front contract to the next one
+type IRollRule interface {
+	shouldRoll(t time.Time, front *FuturesContract, frontStats *contractStats, nextStats *contractStats) bool
+}
+
+//RollByDate rolls given number of days before front contract expiration
+type RollByDate struct {
+	DaysBeforeExpiration int
+}
+
+func (r *RollByDate) shouldRoll(t time.Time, front *FuturesContract, frontStats *contractStats, nextStats *contractStats) bool {
+	return !t.Before(front.Expiration.AddDate(0, 0, -r.DaysBeforeExpiration))
+}
+
+//RollByVolume rolls when volume of the next contract becomes greater than volume of the front one
+type RollByVolume struct{}
+
+func (r *RollByVolume) shouldRoll(t time.Time, front *FuturesContract, frontStats *contractStats, nextStats *contractStats) bool {
+	return nextStats.hasData && nextStats.volume > frontStats.volume
+}
+
+//RollByOpenInterest rolls when open interest of the next contract becomes greater than open interest
+//of the front one
+type RollByOpenInterest struct{}
+
+func (r *RollByOpenInterest) shouldRoll(t time.Time, front *FuturesContract, frontStats *contractStats, nextStats *contractStats) bool {
+	return nextStats.hasData && nextStats.openInterest > frontStats.openInterest
+}
+
+//FuturesChain builds continuous series of Root instrument from the contracts. Market data of the front
+//contract is passed as data of Root, data of other contracts is only used by roll rule. Strategies and
+//broker work with Root symbol and get RollEvent when front contract changes.
+type FuturesChain struct {
+	Root       *Instrument
+	Contracts  []*FuturesContract
+	RollRule   IRollRule
+	BackAdjust BackAdjustMode
+
+	front int
+	stats map[string]*contractStats
+}
+
+func NewFuturesChain(root *Instrument, rule IRollRule, adjust BackAdjustMode, contracts ...*FuturesContract) *FuturesChain {
+	sort.SliceStable(contracts, func(i, j int) bool {
+		return contracts[i].Expiration.Before(contracts[j].Expiration)
+	})
+	c := FuturesChain{
+		Root:       root,
+		Contracts:  contracts,
+		RollRule:   rule,
+		BackAdjust: adjust,
+		stats:      make(map[string]*contractStats),
+	}
+	return &c
+}
+
+//Front returns current front contract
+func (c *FuturesChain) Front() *FuturesContract {
+	return c.Contracts[c.front]
+}
+
+func (c *FuturesChain) reset() {
+	c.front = 0
+	c.stats = make(map[string]*contractStats)
+}
+
+func (c *FuturesChain) contractIndex(symbol string) int {
+	for i, fc := range c.Contracts {
+		if fc.Contract.Symbol == symbol {
+			return i
+		}
+	}
+	return -1
+}
+
+func (c *FuturesChain) contractStats(symbol string) *contractStats {
+	s, ok := c.stats[symbol]
+	if !ok {
+		s = &contractStats{}
+		c.stats[symbol] = s
+	}
+	return s
+}
+
+//onData updates stats of the contract and checks roll rule. It returns true if data belongs to front
+//contract and should be passed as data of Root. If front contract changes RollEvent is returned.
+//Zero volume or open interest means that data doesn't have it.
+func (c *FuturesChain) onData(symbol string, t time.Time, price float64, volume int64, openInterest int64) (bool, *RollEvent) {
+	idx := c.contractIndex(symbol)
+	if idx < 0 || idx < c.front {
+		return false, nil
+	}
+
+	s := c.contractStats(symbol)
+	if price > 0 {
+		s.price = price
+		s.hasData = true
+	}
+	if volume > 0 {
+		s.volume = volume
+	}
+	if openInterest > 0 {
+		s.openInterest = openInterest
+	}
+
+	if c.front+1 >= len(c.Contracts) {
+		return idx == c.front, nil
+	}
+
+	front := c.Contracts[c.front]
+	next := c.Contracts[c.front+1]
+	frontStats := c.contractStats(front.Contract.Symbol)
+	nextStats := c.contractStats(next.Contract.Symbol)
+
+	expired := !t.Before(front.Expiration)
+	if !expired && (!nextStats.hasData || !c.RollRule.shouldRoll(t, front, frontStats, nextStats)) {
+		return idx == c.front, nil
+	}
+	if !nextStats.hasData {
+		return idx == c.front, nil
+	}
+
+	roll := RollEvent{
+		BaseEvent:  be(t, c.Root),
+		From:       front.Contract,
+		To:         next.Contract,
+		FromPrice:  frontStats.price,
+		ToPrice:    nextStats.price,
+		BackAdjust: c.BackAdjust,
+	}
+	c.front++
+
+	return idx == c.front, &roll
+}
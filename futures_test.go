@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func newTestFuturesChain(rule IRollRule) *FuturesChain {
+	root := &Instrument{Symbol: "ES"}
+	return NewFuturesChain(root, rule, BackAdjustDifference,
+		&FuturesContract{Contract: &Instrument{Symbol: "ESM18"}, Expiration: time.Date(2018, 6, 15, 0, 0, 0, 0, time.UTC)},
+		&FuturesContract{Contract: &Instrument{Symbol: "ESH18"}, Expiration: time.Date(2018, 3, 16, 0, 0, 0, 0, time.UTC)},
+	)
+}
+
+func TestFuturesChain_onData(t *testing.T) {
+	t.Log("Roll by date")
+	{
+		c := newTestFuturesChain(&RollByDate{DaysBeforeExpiration: 5})
+		assert.Equal(t, "ESH18", c.Front().Contract.Symbol)
+
+		isFront, roll := c.onData("ESH18", time.Date(2018, 3, 1, 10, 0, 0, 0, time.UTC), 2700, 0, 0)
+		assert.True(t, isFront)
+		assert.Nil(t, roll)
+
+		isFront, roll = c.onData("ESM18", time.Date(2018, 3, 1, 10, 0, 0, 0, time.UTC), 2710, 0, 0)
+		assert.False(t, isFront)
+		assert.Nil(t, roll)
+
+		isFront, roll = c.onData("ESH18", time.Date(2018, 3, 12, 10, 0, 0, 0, time.UTC), 2750, 0, 0)
+		assert.False(t, isFront)
+		assert.NotNil(t, roll)
+		assert.Equal(t, "ES", roll.getSymbol())
+		assert.Equal(t, "ESH18", roll.From.Symbol)
+		assert.Equal(t, "ESM18", roll.To.Symbol)
+		assert.Equal(t, 2750.0, roll.FromPrice)
+		assert.Equal(t, 2710.0, roll.ToPrice)
+		assert.Equal(t, -40.0, roll.Difference())
+		assert.Equal(t, 2660.0, roll.adjustPrice(2700))
+
+		isFront, _ = c.onData("ESM18", time.Date(2018, 3, 12, 10, 0, 0, 0, time.UTC), 2712, 0, 0)
+		assert.True(t, isFront)
+	}
+
+	t.Log("Roll by volume")
+	{
+		c := newTestFuturesChain(&RollByVolume{})
+		tm := time.Date(2018, 3, 1, 10, 0, 0, 0, time.UTC)
+		_, roll := c.onData("ESH18", tm, 2700, 1000, 0)
+		assert.Nil(t, roll)
+		_, roll = c.onData("ESM18", tm, 2710, 500, 0)
+		assert.Nil(t, roll)
+		isFront, roll := c.onData("ESM18", tm.AddDate(0, 0, 1), 2710, 1500, 0)
+		assert.True(t, isFront)
+		assert.NotNil(t, roll)
+	}
+}
+
+func TestContinuousEvent(t *testing.T) {
+	c := newTestFuturesChain(&RollByDate{DaysBeforeExpiration: 5})
+	contract := c.Contracts[0].Contract
+	tick := &Tick{Tick: &marketdata.Tick{Symbol: "ESH18", LastPrice: 2700, LastSize: 1}, Ticker: contract}
+	e, roll := continuousEvent(c, &NewTickEvent{BaseEvent: be(time.Date(2018, 3, 1, 10, 0, 0, 0, time.UTC), contract), Tick: tick})
+	assert.Nil(t, roll)
+	assert.Equal(t, "ES", e.getSymbol())
+	assert.Equal(t, "ES", e.(*NewTickEvent).Tick.Symbol)
+	assert.Equal(t, "ESH18", tick.Symbol)
+}
+
+func TestTrade_roll(t *testing.T) {
+	trade := newFlatTrade(&Instrument{Symbol: "ES"})
+	trade.Type = LongTrade
+	trade.Qty = 2
+	trade.OpenPrice = 2700
+	trade.OpenValue = 5400
+	trade.ConfirmedOrders["1"] = &Order{Id: "1", Price: 2800}
+
+	trade.roll(&RollEvent{FromPrice: 2750, ToPrice: 2760})
+	assert.Equal(t, 100.0, trade.ClosedPnL)
+	assert.Equal(t, 2760.0, trade.OpenPrice)
+	assert.Equal(t, 5520.0, trade.OpenValue)
+	assert.Equal(t, 2810.0, trade.ConfirmedOrders["1"].Price)
+}
@@ -0,0 +1,143 @@
+package engine
+
+import (
+	"fmt"
+	"sync"
+)
+
+//FXSpec describes currency pair of FX instrument. Prices are amount of Quote currency for one unit of Base.
+type FXSpec struct {
+	Base    string
+	Quote   string
+	PipSize float64
+}
+
+//NewFXInstrument creates currency pair instrument. Symbol is base and quote currencies, e.g. EURUSD.
+//Lot size is number of base currency units in one lot.
+func NewFXInstrument(base string, quote string, pipSize float64, lotSize int64) *Instrument {
+	inst := Instrument{
+		Symbol:  base + quote,
+		MinTick: pipSize / 10,
+		LotSize: lotSize,
+		FX: &FXSpec{
+			Base:    base,
+			Quote:   quote,
+			PipSize: pipSize,
+		},
+	}
+	return &inst
+}
+
+//ToPips converts price difference to pips. Instruments without FX spec return price difference as is.
+func (i *Instrument) ToPips(priceDiff float64) float64 {
+	if i.FX == nil || i.FX.PipSize == 0 {
+		return priceDiff
+	}
+	return priceDiff / i.FX.PipSize
+}
+
+//FromPips converts pips to price difference
+func (i *Instrument) FromPips(pips float64) float64 {
+	if i.FX == nil || i.FX.PipSize == 0 {
+		return pips
+	}
+	return pips * i.FX.PipSize
+}
+
+//FXRates keeps last known exchange rates of currency pairs
+type FXRates struct {
+	rates map[string]float64
+	mut   *sync.RWMutex
+}
+
+func NewFXRates() *FXRates {
+	r := FXRates{
+		rates: make(map[string]float64),
+		mut:   &sync.RWMutex{},
+	}
+	return &r
+}
+
+//SetRate sets price of one unit of base currency in quote currency
+func (r *FXRates) SetRate(base string, quote string, rate float64) {
+	if rate <= 0 {
+		return
+	}
+	r.mut.Lock()
+	r.rates[base+"/"+quote] = rate
+	r.mut.Unlock()
+}
+
+//Rate returns amount of to currency for one unit of from currency. Inverse pair is used if direct
+//pair is unknown.
+func (r *FXRates) Rate(from string, to string) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+	r.mut.RLock()
+	defer r.mut.RUnlock()
+	if rate, ok := r.rates[from+"/"+to]; ok {
+		return rate, nil
+	}
+	if rate, ok := r.rates[to+"/"+from]; ok {
+		return 1 / rate, nil
+	}
+	return 0, fmt.Errorf("Unknown exchange rate %v/%v", from, to)
+}
+
+//fxConversion converts PnL of trades from quote currency to account currency. It's shared by portfolio
+//and all its trades.
+type fxConversion struct {
+	account string
+	rates   *FXRates
+	mut     *sync.RWMutex
+	//onError gets error of the first conversion of every currency without rate
+	onError func(err error)
+	missing map[string]struct{}
+}
+
+func newFXConversion() *fxConversion {
+	return &fxConversion{rates: NewFXRates(), mut: &sync.RWMutex{}, missing: make(map[string]struct{})}
+}
+
+func (c *fxConversion) setAccountCurrency(currency string) {
+	c.mut.Lock()
+	c.account = currency
+	c.mut.Unlock()
+}
+
+//toAccount converts amount in currency to account currency. Amount is returned as is if account currency
+//is not set or rate is unknown yet. Unknown rate is reported once for every currency.
+func (c *fxConversion) toAccount(amount float64, currency string) float64 {
+	c.mut.RLock()
+	account := c.account
+	c.mut.RUnlock()
+	if account == "" || currency == "" || currency == account {
+		return amount
+	}
+	rate, err := c.rates.Rate(currency, account)
+	if err != nil {
+		c.reportMissing(currency+"/"+account, err)
+		return amount
+	}
+	return amount * rate
+}
+
+func (c *fxConversion) reportMissing(pair string, err error) {
+	c.mut.Lock()
+	_, reported := c.missing[pair]
+	c.missing[pair] = struct{}{}
+	onError := c.onError
+	c.mut.Unlock()
+	if !reported && onError != nil {
+		onError(fmt.Errorf("%v. Amounts aren't converted to account currency until rate is known. ", err))
+	}
+}
+
+//onTick updates exchange rate by tick of FX instrument
+func (c *fxConversion) onTick(tick *Tick) {
+	if tick.Ticker == nil || tick.Ticker.FX == nil || !tick.HasTrade() {
+		return
+	}
+	c.rates.SetRate(tick.Ticker.FX.Base, tick.Ticker.FX.Quote, tick.LastPrice)
+}
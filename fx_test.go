@@ -0,0 +1,84 @@
+package engine
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestFXRates_Rate(t *testing.T) {
+	r := NewFXRates()
+	r.SetRate("EUR", "USD", 1.25)
+
+	rate, err := r.Rate("EUR", "USD")
+	assert.Nil(t, err)
+	assert.Equal(t, 1.25, rate)
+
+	rate, err = r.Rate("USD", "EUR")
+	assert.Nil(t, err)
+	assert.Equal(t, 0.8, rate)
+
+	rate, err = r.Rate("USD", "USD")
+	assert.Nil(t, err)
+	assert.Equal(t, 1.0, rate)
+
+	_, err = r.Rate("USD", "JPY")
+	assert.NotNil(t, err)
+}
+
+func TestInstrument_pips(t *testing.T) {
+	inst := NewFXInstrument("EUR", "USD", 0.0001, 100000)
+	assert.Equal(t, "EURUSD", inst.Symbol)
+	assert.InDelta(t, 25.0, inst.ToPips(1.2025-1.2000), 0.0000001)
+	assert.InDelta(t, 0.0025, inst.FromPips(25), 0.0000001)
+
+	stock := newTestInstrument()
+	assert.Equal(t, 0.5, stock.ToPips(0.5))
+}
+
+func TestTrade_executeOrderFX(t *testing.T) {
+	inst := NewFXInstrument("USD", "JPY", 0.01, 100000)
+	fx := newFXConversion()
+	fx.setAccountCurrency("USD")
+	fx.rates.SetRate("USD", "JPY", 100)
+
+	trade := newFlatTrade(inst)
+	trade.fx = fx
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+
+	buy := &Order{Id: "1", Side: OrderBuy, Qty: 200000, Price: 100, Ticker: inst, State: ConfirmedOrder, Type: LimitOrder}
+	trade.ConfirmedOrders[buy.Id] = buy
+	_, err := trade.executeOrder(buy.Id, 200000, 100, tm)
+	assert.Nil(t, err)
+
+	assert.Nil(t, trade.updatePnL(100.5, tm))
+	assert.InDelta(t, 1000.0, trade.OpenPnL, 0.0000001)
+
+	sell := &Order{Id: "2", Side: OrderSell, Qty: 300000, Price: 101, Ticker: inst, State: ConfirmedOrder, Type: LimitOrder}
+	trade.ConfirmedOrders[sell.Id] = sell
+	newTrade, err := trade.executeOrder(sell.Id, 300000, 101, tm)
+	assert.Nil(t, err)
+	assert.InDelta(t, 2000.0, trade.ClosedPnL, 0.0000001)
+
+	assert.NotNil(t, newTrade)
+	assert.Equal(t, fx, newTrade.fx)
+	assert.Nil(t, newTrade.updatePnL(100, tm))
+	assert.InDelta(t, 1000.0, newTrade.OpenPnL, 0.0000001)
+}
+
+func TestFXConversion_toAccount(t *testing.T) {
+	fx := newFXConversion()
+	var errs []error
+	fx.onError = func(err error) { errs = append(errs, err) }
+
+	assert.Equal(t, 100.0, fx.toAccount(100, "JPY"))
+	fx.setAccountCurrency("USD")
+	assert.Equal(t, 100.0, fx.toAccount(100, "JPY"))
+	assert.Equal(t, 200.0, fx.toAccount(200, "JPY"))
+	assert.Len(t, errs, 1)
+
+	fx.rates.SetRate("USD", "JPY", 100)
+	assert.Equal(t, 2.0, fx.toAccount(200, "JPY"))
+	assert.Equal(t, 10.0, fx.toAccount(10, "EUR"))
+	assert.Len(t, errs, 2)
+}
@@ -84,12 +84,31 @@ type Instrument struct {
 	Exchange Exchange
 	MinTick  float64
 	LotSize  int64
+	//DataTimeZone is zone of the timestamps in market data storage if they are stored as exchange wall clock
+	//without zone. BTM converts them to UTC. Nil means that stored timestamps are correct.
+	DataTimeZone *time.Location
+	//Multiplier is contract multiplier used in PnL calculation. Zero means 1.
+	Multiplier float64
+	//QtyPrecision is number of decimal places of qty. Qty of orders, trades and lot size are integer numbers
+	//of 10^-QtyPrecision units, e.g. with precision 8 qty 5000000 is 0.05 BTC. Zero means whole units.
+	QtyPrecision int
+	//InitialMargin and MaintenanceMargin are margin requirements as fractions of position market value,
+	//e.g. 0.5 and 0.25 for stocks with Reg T margin. Zero means instrument doesn't need margin.
+	InitialMargin     float64
+	MaintenanceMargin float64
+	//Sector is classification of the instrument used by sector exposure limits
+	Sector string
+	Option *OptionSpec
+	FX     *FXSpec
 }
 
 type Exchange struct {
 	Name            string
 	MarketOpenTime  TimeOfDay
 	MarketCloseTime TimeOfDay
+	Calendar        *TradingCalendar
+	//Location is exchange time zone. Market open and close times are in this zone.
+	Location *time.Location
 }
 
 type Tick struct {
@@ -131,7 +150,8 @@ func (c *Candle) isValid() bool {
 
 func (c *Candle) isOpening() bool {
 	e := c.Ticker.Exchange
-	if c.Datetime.Hour() == e.MarketOpenTime.Hour && c.Datetime.Minute() == e.MarketOpenTime.Minute {
+	dt := e.LocalTime(c.Datetime)
+	if dt.Hour() == e.MarketOpenTime.Hour && dt.Minute() == e.MarketOpenTime.Minute {
 		return true
 	}
 	return false
@@ -145,9 +165,9 @@ func (c *Candle) isClosingForTimeFrame(tf string) bool {
 	if err != nil {
 		panic("Unknown timeframe. ")
 	}
-	e := c.Ticker.Exchange
-	closeTime := c.Datetime.Add(time.Minute * time.Duration(mins))
-	if closeTime.Hour() == e.MarketCloseTime.Hour && closeTime.Minute() == e.MarketCloseTime.Minute {
+	sessionClose := c.Ticker.Exchange.CloseTimeOf(c.Datetime)
+	closeTime := c.Ticker.Exchange.LocalTime(c.Datetime.Add(time.Minute * time.Duration(mins)))
+	if closeTime.Hour() == sessionClose.Hour && closeTime.Minute() == sessionClose.Minute {
 		return true
 	}
 	return false
@@ -170,6 +190,11 @@ type Order struct {
 	Mark1       string
 	Mark2       string
 	Time        time.Time
+	//PositionSide is sub-position of the order in hedging mode, e.g. sell order with LongTrade side reduces
+	//long sub-position. Empty side means order opens or adds to sub-position of the order side.
+	PositionSide TradeType
+	//CorrelationID is ID of strategy decision which created the order. Broker events of the order have it.
+	CorrelationID string
 }
 
 //isValid returns if order has right prices (NaN for market orders and specified for Limit and Stop)
@@ -293,6 +318,15 @@ func (o *Order) replace(newPrice float64) error {
 	return nil
 }
 
+//replaceQty changes order qty. New qty can't be less than executed qty.
+func (o *Order) replaceQty(newQty int64) error {
+	if newQty <= o.ExecQty {
+		return fmt.Errorf("Can't replace order qty. New qty %v should be larger than executed qty %v. ", newQty, o.ExecQty)
+	}
+	o.Qty = newQty
+	return nil
+}
+
 func (o *Order) IsPriced() bool {
 	if o.Type == LimitOnOpen || o.Type == LimitOrder || o.Type == LimitOnClose || o.Type == StopOrder {
 		return true
@@ -344,6 +378,39 @@ type Trade struct {
 	ClosedPnL       float64
 	OpenPnL         float64
 	Id              string
+	//CorrelationID is ID of strategy decision which opened the trade
+	CorrelationID string
+
+	//Direction is LongTrade or ShortTrade. Unlike Type it isn't changed when trade is closed.
+	Direction TradeType
+	//ExitPrice is average price of executions which reduced the trade, ExitQty is their total qty
+	ExitPrice float64
+	ExitQty   int64
+	//Commission is total commission of the trade executions
+	Commission float64
+	//Slippage is total cost of executions at worse than market price. It's included in PnL.
+	Slippage float64
+	//BorrowCost is net borrow cost of short trade. It's included in closed PnL.
+	BorrowCost float64
+	//FinancingCost is net overnight financing of the trade, negative for credit. It's included in closed PnL.
+	FinancingCost float64
+	//Entries is number of orders which opened or added to the trade
+	Entries        int
+	LastEntryPrice float64
+	LastEntryTime  time.Time
+	//MAE and MFE are max adverse and favorable excursions: the largest open loss and open profit seen while the
+	//trade was open, positive amounts in account currency
+	MAE float64
+	MFE float64
+
+	//fx converts PnL of instruments with FX spec to account currency. Prices and values stay in quote currency.
+	fx *fxConversion
+	//borrowDay is day borrow cost was accrued for last time
+	borrowDay time.Time
+	//financingDay is session date financing was accrued for last time
+	financingDay time.Time
+	//costFills are executions of the trade kept to re-price it with other costs
+	costFills []costFill
 }
 
 func (t *Trade) hasConfirmedOrderWithId(ordID string) bool {
@@ -356,6 +423,17 @@ func (t *Trade) hasConfirmedOrderWithId(ordID string) bool {
 	return false
 }
 
+//position returns signed qty of the trade, negative for short trade
+func (t *Trade) position() int64 {
+	switch t.Type {
+	case LongTrade:
+		return t.Qty
+	case ShortTrade:
+		return -t.Qty
+	}
+	return 0
+}
+
 func (t *Trade) IsOpen() bool {
 	if t.Type == LongTrade || t.Type == ShortTrade {
 		if t.Qty == 0 {
@@ -467,6 +545,14 @@ func (t *Trade) replaceOrder(id string, newPrice float64) error {
 	return nil
 }
 
+func (t *Trade) replaceOrderQty(id string, newQty int64) error {
+	order, ok := t.ConfirmedOrders[id]
+	if !ok {
+		return errors.New("Can't replace order qty. Not found in confirmed orders")
+	}
+	return order.replaceQty(newQty)
+}
+
 //executeOrder by given id and qty. If order qty was large than current position open qty then position will get state
 //ClosedTrade and pointer to new opened position will be returned. All position values will be updated
 func (t *Trade) executeOrder(id string, qty int64, execPrice float64, datetime time.Time) (*Trade, error) {
@@ -478,7 +564,7 @@ func (t *Trade) executeOrder(id string, qty int64, execPrice float64, datetime t
 	}
 
 	if math.IsNaN(execPrice) || execPrice == 0 {
-		panic("Panic: tried to execute order with zero or NaN execution price")
+		return nil, fmt.Errorf("Can't execute order %v. Execution price is zero or NaN. ", id)
 	}
 
 	qtyLeft := order.Qty - order.ExecQty
@@ -513,6 +599,7 @@ func (t *Trade) executeOrder(id string, qty int64, execPrice float64, datetime t
 	case FlatTrade:
 		t.Qty = qty
 		t.Id = order.Id
+		t.CorrelationID = order.CorrelationID
 		t.FirstPrice = execPrice
 		if order.Side == OrderBuy {
 			t.Type = LongTrade
@@ -522,35 +609,40 @@ func (t *Trade) executeOrder(id string, qty int64, execPrice float64, datetime t
 			}
 			t.Type = ShortTrade
 		}
+		t.Direction = t.Type
 		t.OpenPrice = execPrice
-		t.OpenValue = execPrice * float64(t.Qty)
+		t.OpenValue = execPrice * t.units(t.Qty)
 		t.MarketValue = t.OpenValue
 		t.OpenTime = datetime
+		t.addEntry(order, qty, execPrice, datetime)
 		return nil, nil
 	case ShortTrade:
 		if order.Side == OrderSell {
 			//Add to open short
 			t.Qty += qty
-			t.OpenValue += float64(qty) * execPrice
-			t.OpenPrice = t.OpenValue / float64(t.Qty)
-			t.MarketValue = float64(t.Qty) * execPrice
-			t.OpenPnL = -(t.MarketValue - t.OpenValue)
+			t.addEntry(order, qty, execPrice, datetime)
+			t.OpenValue += t.units(qty) * execPrice
+			t.OpenPrice = t.OpenValue / t.units(t.Qty)
+			t.MarketValue = t.units(t.Qty) * execPrice
+			t.OpenPnL = t.toAccount(-(t.MarketValue - t.OpenValue))
 			return nil, nil
 		} else {
 			//Cover open short position
 			if qty < t.Qty {
 				//Partial cover
 				t.Qty -= qty
-				t.ClosedPnL += -(execPrice - t.OpenPrice) * float64(qty)
-				t.OpenValue = t.OpenPrice * float64(t.Qty)
-				t.MarketValue = float64(t.Qty) * execPrice
-				t.OpenPnL = -(t.MarketValue - t.OpenValue)
+				t.addExit(qty, execPrice)
+				t.ClosedPnL += t.toAccount(-(execPrice - t.OpenPrice) * t.units(qty))
+				t.OpenValue = t.OpenPrice * t.units(t.Qty)
+				t.MarketValue = t.units(t.Qty) * execPrice
+				t.OpenPnL = t.toAccount(-(t.MarketValue - t.OpenValue))
 				return nil, nil
 			} else {
 				if qty == t.Qty {
 					//Complete cover and return new FLAT position
 					t.Qty -= qty
-					t.ClosedPnL += -(execPrice - t.OpenPrice) * float64(qty)
+					t.addExit(qty, execPrice)
+					t.ClosedPnL += t.toAccount(-(execPrice - t.OpenPrice) * t.units(qty))
 					t.OpenValue = 0
 					t.MarketValue = 0
 					t.OpenPnL = 0
@@ -558,6 +650,7 @@ func (t *Trade) executeOrder(id string, qty int64, execPrice float64, datetime t
 					t.CloseTime = datetime
 
 					newTrade := newFlatTrade(t.Ticker)
+					newTrade.fx = t.fx
 					newTrade.NewOrders = t.NewOrders
 					newTrade.ConfirmedOrders = t.ConfirmedOrders
 
@@ -569,7 +662,8 @@ func (t *Trade) executeOrder(id string, qty int64, execPrice float64, datetime t
 				} else {
 					//Complete cover and open new LONG position
 					newQty := qty - t.Qty
-					t.ClosedPnL += -(execPrice - t.OpenPrice) * float64(t.Qty)
+					t.addExit(t.Qty, execPrice)
+					t.ClosedPnL += t.toAccount(-(execPrice - t.OpenPrice) * t.units(t.Qty))
 					t.Qty = 0
 					t.OpenValue = 0
 					t.MarketValue = 0
@@ -577,10 +671,12 @@ func (t *Trade) executeOrder(id string, qty int64, execPrice float64, datetime t
 					t.Type = ClosedTrade
 					t.CloseTime = datetime
 
-					newTrade := Trade{Ticker: t.Ticker, Qty: newQty, Id: order.Id, OpenTime: datetime, Type: LongTrade}
+					newTrade := Trade{Ticker: t.Ticker, Qty: newQty, Id: order.Id, OpenTime: datetime, Type: LongTrade, Direction: LongTrade, fx: t.fx,
+						CorrelationID: order.CorrelationID}
 					newTrade.OpenPrice = execPrice
-					newTrade.OpenValue = newTrade.OpenPrice * float64(newTrade.Qty)
+					newTrade.OpenValue = newTrade.OpenPrice * newTrade.units(newTrade.Qty)
 					newTrade.MarketValue = newTrade.OpenValue
+					newTrade.addEntry(order, qty, execPrice, datetime)
 
 					newTrade.NewOrders = t.NewOrders
 					newTrade.ConfirmedOrders = t.ConfirmedOrders
@@ -598,25 +694,28 @@ func (t *Trade) executeOrder(id string, qty int64, execPrice float64, datetime t
 		if order.Side == OrderBuy {
 			//Add to open LONG
 			t.Qty += qty
-			t.OpenValue += float64(qty) * execPrice
-			t.OpenPrice = t.OpenValue / float64(t.Qty)
-			t.MarketValue = float64(t.Qty) * execPrice
-			t.OpenPnL = t.MarketValue - t.OpenValue
+			t.addEntry(order, qty, execPrice, datetime)
+			t.OpenValue += t.units(qty) * execPrice
+			t.OpenPrice = t.OpenValue / t.units(t.Qty)
+			t.MarketValue = t.units(t.Qty) * execPrice
+			t.OpenPnL = t.toAccount(t.MarketValue - t.OpenValue)
 			return nil, nil
 		} else {
 			if qty < t.Qty {
 				//Partial cover LONG
 				t.Qty -= qty
-				t.ClosedPnL += (execPrice - t.OpenPrice) * float64(qty)
-				t.OpenValue = t.OpenPrice * float64(t.Qty)
-				t.MarketValue = float64(t.Qty) * execPrice
-				t.OpenPnL = t.MarketValue - t.OpenValue
+				t.addExit(qty, execPrice)
+				t.ClosedPnL += t.toAccount((execPrice - t.OpenPrice) * t.units(qty))
+				t.OpenValue = t.OpenPrice * t.units(t.Qty)
+				t.MarketValue = t.units(t.Qty) * execPrice
+				t.OpenPnL = t.toAccount(t.MarketValue - t.OpenValue)
 				return nil, nil
 			} else {
 				if qty == t.Qty {
 					//Complete cover LONG and return new FLAT position
 					t.Qty -= qty
-					t.ClosedPnL += (execPrice - t.OpenPrice) * float64(qty)
+					t.addExit(qty, execPrice)
+					t.ClosedPnL += t.toAccount((execPrice - t.OpenPrice) * t.units(qty))
 					t.OpenValue = 0
 					t.MarketValue = 0
 					t.OpenPnL = 0
@@ -624,6 +723,7 @@ func (t *Trade) executeOrder(id string, qty int64, execPrice float64, datetime t
 					t.CloseTime = datetime
 
 					newTrade := newFlatTrade(t.Ticker)
+					newTrade.fx = t.fx
 					newTrade.NewOrders = t.NewOrders
 					newTrade.ConfirmedOrders = t.ConfirmedOrders
 
@@ -635,7 +735,8 @@ func (t *Trade) executeOrder(id string, qty int64, execPrice float64, datetime t
 				} else {
 					//Complete cover LONG and open new SHORT position
 					newQty := qty - t.Qty
-					t.ClosedPnL += (execPrice - t.OpenPrice) * float64(t.Qty)
+					t.addExit(t.Qty, execPrice)
+					t.ClosedPnL += t.toAccount((execPrice - t.OpenPrice) * t.units(t.Qty))
 					t.Qty = 0
 					t.OpenValue = 0
 					t.MarketValue = 0
@@ -643,10 +744,12 @@ func (t *Trade) executeOrder(id string, qty int64, execPrice float64, datetime t
 					t.Type = ClosedTrade
 					t.CloseTime = datetime
 
-					newTrade := Trade{Ticker: t.Ticker, Qty: newQty, Id: order.Id, OpenTime: datetime, Type: ShortTrade}
+					newTrade := Trade{Ticker: t.Ticker, Qty: newQty, Id: order.Id, OpenTime: datetime, Type: ShortTrade, Direction: ShortTrade, fx: t.fx,
+						CorrelationID: order.CorrelationID}
 					newTrade.OpenPrice = execPrice
-					newTrade.OpenValue = newTrade.OpenPrice * float64(newTrade.Qty)
+					newTrade.OpenValue = newTrade.OpenPrice * newTrade.units(newTrade.Qty)
 					newTrade.MarketValue = newTrade.OpenValue
+					newTrade.addEntry(order, qty, execPrice, datetime)
 					newTrade.OpenPnL = 0
 
 					newTrade.NewOrders = t.NewOrders
@@ -666,6 +769,21 @@ func (t *Trade) executeOrder(id string, qty int64, execPrice float64, datetime t
 	return nil, nil
 }
 
+//addEntry counts execution which opens or adds to the trade. Partial fills of the same order are one entry.
+func (t *Trade) addEntry(order *Order, qty int64, execPrice float64, datetime time.Time) {
+	if order.ExecQty == qty {
+		t.Entries++
+	}
+	t.LastEntryPrice = execPrice
+	t.LastEntryTime = datetime
+}
+
+//addExit updates average exit price by execution which reduces the trade
+func (t *Trade) addExit(qty int64, execPrice float64) {
+	t.ExitPrice = (t.ExitPrice*float64(t.ExitQty) + execPrice*float64(qty)) / float64(t.ExitQty+qty)
+	t.ExitQty += qty
+}
+
 //rejectOrder by given ID with given reject reason. Find order in NewOrdes map, change status and move it
 //to RejectedOrders map
 func (t *Trade) rejectOrder(id string, reason string) error {
@@ -717,17 +835,60 @@ func (t *Trade) updateAllOrdersIDMap() {
 
 }
 
+//units returns qty in whole units multiplied by contract multiplier of the instrument
+func (t *Trade) units(qty int64) float64 {
+	return t.Ticker.QtyFloat(qty) * t.Ticker.multiplier()
+}
+
+//toAccount converts amount in quote currency of FX instrument to account currency
+func (t *Trade) toAccount(amount float64) float64 {
+	if t.fx == nil || t.Ticker == nil || t.Ticker.FX == nil {
+		return amount
+	}
+	return t.fx.toAccount(amount, t.Ticker.FX.Quote)
+}
+
+//roll moves open position to the new front contract of futures chain. Position is closed at old contract
+//price and opened at new contract price. Prices of not executed orders are moved by contracts price difference.
+func (t *Trade) roll(e *RollEvent) {
+	diff := e.Difference()
+	if diff != 0 {
+		for _, orders := range []map[string]*Order{t.NewOrders, t.ConfirmedOrders} {
+			for _, o := range orders {
+				if o.Price != 0 {
+					o.Price += diff
+				}
+			}
+		}
+	}
+
+	if !t.IsOpen() || e.FromPrice <= 0 || e.ToPrice <= 0 {
+		return
+	}
+	if t.Type == LongTrade {
+		t.ClosedPnL += t.toAccount((e.FromPrice - t.OpenPrice) * t.units(t.Qty))
+	} else {
+		t.ClosedPnL += t.toAccount(-(e.FromPrice - t.OpenPrice) * t.units(t.Qty))
+	}
+	t.OpenPrice = e.ToPrice
+	t.OpenValue = e.ToPrice * t.units(t.Qty)
+	t.MarketValue = t.OpenValue
+	t.OpenPnL = 0
+}
+
 //updatePnL updates open pnl and positions market value for Long and Short positions
 func (t *Trade) updatePnL(marketPrice float64, lastTime time.Time) error {
-	t.MarketValue = marketPrice * float64(t.Qty)
+	t.MarketValue = marketPrice * t.units(t.Qty)
 	if t.Type == LongTrade {
-		t.OpenPnL = t.MarketValue - t.OpenValue
+		t.OpenPnL = t.toAccount(t.MarketValue - t.OpenValue)
 	} else {
 		if t.Type != ShortTrade {
 			return errors.New("Can't update pnl for not open position")
 		}
-		t.OpenPnL = -(t.MarketValue - t.OpenValue)
+		t.OpenPnL = t.toAccount(-(t.MarketValue - t.OpenValue))
 	}
+	t.MAE = math.Max(t.MAE, -t.OpenPnL)
+	t.MFE = math.Max(t.MFE, t.OpenPnL)
 
 	t.Returns = append(t.Returns, &TradeReturn{t.OpenPnL, t.ClosedPnL, lastTime})
 	return nil
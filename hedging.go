@@ -0,0 +1,266 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+type PositionMode int
+
+const (
+	//NettingMode keeps one position per symbol. Opposite orders reduce or reverse it.
+	NettingMode PositionMode = iota
+	//HedgingMode keeps long and short sub-positions of the symbol as separate trades. Orders open or add to
+	//sub-position of their side unless Order.PositionSide points to opposite sub-position.
+	HedgingMode
+)
+
+//SetPositionMode switches netting and hedging modes. Mode can be changed only while strategy is flat and has
+//no orders. Target position, exit orders and trailing stop work with net position, so they can't be used in
+//hedging mode.
+func (b *BasicStrategy) SetPositionMode(mode PositionMode) error {
+	if mode != NettingMode && mode != HedgingMode {
+		return fmt.Errorf("Unknown position mode %v. ", mode)
+	}
+	if b.currentTrade == nil {
+		b.currentTrade = newFlatTrade(b.symbol)
+		if b.portfolio != nil {
+			b.currentTrade.fx = b.portfolio.fx
+		}
+	}
+	for _, t := range b.trades() {
+		if t.Type != FlatTrade || len(t.NewOrders) > 0 || len(t.ConfirmedOrders) > 0 {
+			return errors.New("Position mode can be changed only when strategy is flat and has no orders. ")
+		}
+	}
+	if mode == HedgingMode && (b.target != nil || b.trailing != nil || (b.stopLoss != nil && b.stopLoss.isActive()) ||
+		(b.takeProfit != nil && b.takeProfit.isActive())) {
+		return errors.New("Target position, exit orders and trailing stop can't be used in hedging mode. ")
+	}
+
+	if mode == NettingMode {
+		b.shortTrade = nil
+		return nil
+	}
+	if b.shortTrade == nil {
+		b.shortTrade = newFlatTrade(b.symbol)
+		b.shortTrade.fx = b.currentTrade.fx
+	}
+	return nil
+}
+
+func (b *BasicStrategy) PositionMode() PositionMode {
+	if b.shortTrade != nil {
+		return HedgingMode
+	}
+	return NettingMode
+}
+
+//LongPosition returns qty of long sub-position in hedging mode or long position in netting mode
+func (b *BasicStrategy) LongPosition() int64 {
+	if pos := b.currentTrade.position(); pos > 0 {
+		return pos
+	}
+	return 0
+}
+
+//ShortPosition returns qty of short sub-position in hedging mode or short position in netting mode. Qty is
+//positive.
+func (b *BasicStrategy) ShortPosition() int64 {
+	t := b.currentTrade
+	if b.shortTrade != nil {
+		t = b.shortTrade
+	}
+	if pos := t.position(); pos < 0 {
+		return -pos
+	}
+	return 0
+}
+
+//CloseSubPosition sends order which closes whole long or short sub-position in hedging mode. Order type
+//should be MarketOrder or LimitOrder. Empty id is returned if sub-position is flat.
+func (b *BasicStrategy) CloseSubPosition(positionSide TradeType, orderType OrderType, price float64, destination string) (string, error) {
+	if b.shortTrade == nil {
+		return "", errors.New("Sub-positions exist only in hedging mode. ")
+	}
+	if orderType != MarketOrder && orderType != LimitOrder {
+		return "", fmt.Errorf("Can't close sub-position with %v order. ", orderType)
+	}
+	side, qty := OrderSell, b.LongPosition()
+	switch positionSide {
+	case LongTrade:
+	case ShortTrade:
+		side, qty = OrderBuy, b.ShortPosition()
+	default:
+		return "", fmt.Errorf("Unknown position side %v. ", positionSide)
+	}
+	qty -= b.workingReduceQty(b.tradeOfSide(positionSide), side)
+	if qty <= 0 {
+		return "", nil
+	}
+
+	order := Order{
+		Side:         side,
+		Qty:          qty,
+		Ticker:       b.symbol,
+		Price:        price,
+		State:        NewOrder,
+		Type:         orderType,
+		Tif:          DayTIF,
+		Destination:  destination,
+		Time:         b.now().Add(20 * time.Microsecond),
+		PositionSide: positionSide,
+	}
+	if orderType == MarketOrder {
+		order.Price = math.NaN()
+	}
+	if err := b.newOrder(&order); err != nil {
+		return "", err
+	}
+	return order.Id, nil
+}
+
+func (b *BasicStrategy) closeSubPositions(orderType OrderType, price float64, destination string) (string, error) {
+	lastID := ""
+	for _, side := range []TradeType{LongTrade, ShortTrade} {
+		id, err := b.CloseSubPosition(side, orderType, price, destination)
+		if err != nil {
+			return lastID, err
+		}
+		if id != "" {
+			lastID = id
+		}
+	}
+	return lastID, nil
+}
+
+//trades returns current trades of the strategy. Hedging mode has long and short trades.
+func (b *BasicStrategy) trades() []*Trade {
+	if b.currentTrade == nil {
+		return nil
+	}
+	if b.shortTrade == nil {
+		return []*Trade{b.currentTrade}
+	}
+	return []*Trade{b.currentTrade, b.shortTrade}
+}
+
+//positionSideOf returns sub-position of the order in hedging mode
+func positionSideOf(order *Order) TradeType {
+	if order.PositionSide != "" {
+		return order.PositionSide
+	}
+	if order.Side == OrderSell {
+		return ShortTrade
+	}
+	return LongTrade
+}
+
+func (b *BasicStrategy) tradeOfSide(positionSide TradeType) *Trade {
+	if positionSide == ShortTrade && b.shortTrade != nil {
+		return b.shortTrade
+	}
+	return b.currentTrade
+}
+
+//tradeOfOrder returns current trade which has not executed order with given id
+func (b *BasicStrategy) tradeOfOrder(ordID string) *Trade {
+	if b.shortTrade != nil {
+		if _, ok := b.shortTrade.NewOrders[ordID]; ok {
+			return b.shortTrade
+		}
+		if _, ok := b.shortTrade.ConfirmedOrders[ordID]; ok {
+			return b.shortTrade
+		}
+	}
+	return b.currentTrade
+}
+
+//replaceTrade puts new trade instead of closed one
+func (b *BasicStrategy) replaceTrade(closed *Trade, t *Trade) {
+	if closed == b.shortTrade {
+		b.shortTrade = t
+		return
+	}
+	b.currentTrade = t
+}
+
+//workingReduceQty returns not executed qty of orders of the trade with given side. Orders which wait for cancel
+//confirmation are not counted.
+func (b *BasicStrategy) workingReduceQty(t *Trade, side OrderSide) int64 {
+	var qty int64
+	for _, orders := range []map[string]*Order{t.NewOrders, t.ConfirmedOrders} {
+		for _, o := range orders {
+			if _, ok := b.waitingConfirmation["$CAN$"+o.Id]; ok {
+				continue
+			}
+			if o.Side == side {
+				qty += o.Qty - o.ExecQty
+			}
+		}
+	}
+	return qty
+}
+
+//tradeForNewOrder returns trade the order belongs to. In hedging mode orders which reduce sub-position can't be
+//larger than sub-position minus other reducing orders, so sub-position is never reversed.
+func (b *BasicStrategy) tradeForNewOrder(order *Order) (*Trade, error) {
+	if b.shortTrade == nil {
+		return b.currentTrade, nil
+	}
+	positionSide := positionSideOf(order)
+	t := b.tradeOfSide(positionSide)
+	reduces := (positionSide == LongTrade) == (order.Side == OrderSell)
+	if !reduces {
+		return t, nil
+	}
+	qty := t.position()
+	if qty < 0 {
+		qty = -qty
+	}
+	if available := qty - b.workingReduceQty(t, order.Side); order.Qty > available {
+		return nil, fmt.Errorf("Order qty %v is larger than %v available to close in %v sub-position. ", order.Qty,
+			available, positionSide)
+	}
+	return t, nil
+}
+
+//updateOpenPnL updates PnL of open trades by market price. False is returned if strategy has no open trades.
+func (b *BasicStrategy) updateOpenPnL(price float64, tm time.Time) bool {
+	open := false
+	for _, t := range b.trades() {
+		if !t.IsOpen() {
+			continue
+		}
+		open = true
+		if err := t.updatePnL(price, tm); err != nil {
+			b.newError(err)
+		}
+		b.accrueBorrow(t, tm)
+	}
+	return open
+}
+
+//positionInfo returns snapshot of the position for portfolio. Sub-positions of hedging mode are added up,
+//except margin which is required for both of them.
+func (b *BasicStrategy) positionInfo() PositionInfo {
+	info := newPositionInfo(b.currentTrade, b.mostRecentTime)
+	info.Strategy = b.name
+	if b.shortTrade == nil {
+		return info
+	}
+	short := newPositionInfo(b.shortTrade, b.mostRecentTime)
+	if info.Qty == 0 {
+		info.OpenPrice = short.OpenPrice
+		info.OpenTime = short.OpenTime
+	}
+	info.Qty += short.Qty
+	info.MarketValue += short.MarketValue
+	info.OpenPnL += short.OpenPnL
+	info.ClosedPnL += short.ClosedPnL
+	info.InitialMargin += short.InitialMargin
+	info.MaintenanceMargin += short.MaintenanceMargin
+	return info
+}
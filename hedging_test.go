@@ -0,0 +1,49 @@
+package engine
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBasicStrategy_HedgingMode(t *testing.T) {
+	st := newTestMarginStrategy(100000)
+	st.symbol.InitialMargin, st.symbol.MaintenanceMargin = 0, 0
+	assert.Equal(t, NettingMode, st.PositionMode())
+	assert.Nil(t, st.SetPositionMode(HedgingMode))
+	assert.Equal(t, HedgingMode, st.PositionMode())
+
+	fillTestOrder(st, OrderBuy, 100, 50)
+	fillTestOrder(st, OrderSell, 60, 52)
+	assert.Equal(t, int64(100), st.LongPosition())
+	assert.Equal(t, int64(60), st.ShortPosition())
+	assert.Equal(t, int64(40), st.Position())
+	assert.Equal(t, LongTrade, st.currentTrade.Type)
+	assert.Equal(t, ShortTrade, st.shortTrade.Type)
+	assert.NotNil(t, st.SetPositionMode(NettingMode))
+
+	pos, _ := st.Portfolio().Position("Test")
+	assert.Equal(t, int64(40), pos.Qty)
+	assert.InDelta(t, 100*50-60*52.0, pos.MarketValue, 0.0000001)
+
+	//Sub-position can't be reversed
+	_, err := st.CloseSubPosition(LongTrade, LimitOrder, 60, "Sim")
+	assert.Nil(t, err)
+	order := Order{Side: OrderSell, Qty: 10, Ticker: st.symbol, Price: 61, State: NewOrder, Type: LimitOrder,
+		Tif: GTCTIF, Destination: "Sim", Time: st.mostRecentTime, PositionSide: LongTrade}
+	assert.NotNil(t, st.newOrder(&order))
+
+	id, err := st.CloseSubPosition(ShortTrade, MarketOrder, math.NaN(), "Sim")
+	assert.Nil(t, err)
+	st.proxyEvent(&OrderConfirmationEvent{BaseEvent: be(st.mostRecentTime, st.symbol), OrdId: id})
+	st.proxyEvent(&OrderFillEvent{BaseEvent: be(st.mostRecentTime.Add(time.Minute), st.symbol), OrdId: id,
+		Price: 51, Qty: 60})
+	assert.Equal(t, int64(0), st.ShortPosition())
+	assert.Equal(t, int64(100), st.LongPosition())
+	assert.Len(t, st.closedTrades, 1)
+	assert.InDelta(t, 60.0, st.closedTrades[0].ClosedPnL, 0.0000001)
+	assert.Equal(t, FlatTrade, st.shortTrade.Type)
+	assert.Len(t, st.OpenOrders(), 0)
+	assert.Len(t, st.currentTrade.NewOrders, 1)
+}
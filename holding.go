@@ -0,0 +1,181 @@
+package engine
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+var defaultHoldingBuckets = []time.Duration{time.Hour, 24 * time.Hour, 7 * 24 * time.Hour, 30 * 24 * time.Hour}
+
+//IStalePositionUserStrategy can be implemented by user strategy to be notified when its position is open
+//longer than max holding period
+type IStalePositionUserStrategy interface {
+	OnStalePosition(b *BasicStrategy, e *StalePositionEvent)
+}
+
+//HoldingStats is aging summary of positions. Ages of open positions are measured at time of the last market
+//data. Distribution has number of closed trades by holding time: i-th bucket has trades held less than i-th
+//bound and the last bucket has trades held longer than all bounds.
+type HoldingStats struct {
+	OpenPositions  int
+	AvgPositionAge time.Duration
+	MaxPositionAge time.Duration
+	StalePositions []string
+	ClosedTrades   int
+	AvgHoldingTime time.Duration
+	Bounds         []time.Duration
+	Distribution   []int
+}
+
+//holdingState keeps settings of position aging and open time of positions stale alert was sent for
+type holdingState struct {
+	now     time.Time
+	maxAge  time.Duration
+	bounds  []time.Duration
+	alerted map[string]time.Time
+}
+
+func (h *holdingState) isStale(info PositionInfo) bool {
+	return h.maxAge > 0 && info.Qty != 0 && h.now.Sub(info.OpenTime) > h.maxAge
+}
+
+//SetMaxHoldingPeriod sets holding period after which position is stale. Strategy gets StalePositionEvent
+//once for every stale position. Zero disables alerts.
+func (c *Engine) SetMaxHoldingPeriod(period time.Duration) error {
+	if period < 0 {
+		return errors.New("Max holding period can't be negative. ")
+	}
+	p := c.portfolio
+	p.mut.Lock()
+	p.holding.maxAge = period
+	p.mut.Unlock()
+	return nil
+}
+
+//SetHoldingBuckets sets bounds of holding time distribution of closed trades. Default bounds are hour, day,
+//week and 30 days.
+func (c *Engine) SetHoldingBuckets(bounds []time.Duration) error {
+	for i, b := range bounds {
+		if b <= 0 || (i > 0 && b <= bounds[i-1]) {
+			return errors.New("Holding bounds should be positive and ascending. ")
+		}
+	}
+	p := c.portfolio
+	p.mut.Lock()
+	p.holding.bounds = append([]time.Duration{}, bounds...)
+	p.mut.Unlock()
+	return nil
+}
+
+//HoldingStats returns ages of open positions and holding time of closed trades of all strategies
+func (p *portfolioHandler) HoldingStats() HoldingStats {
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	return p.holdingStats()
+}
+
+//holdingStats calculates holding stats. Caller should hold the lock.
+func (p *portfolioHandler) holdingStats() HoldingStats {
+	h := &p.holding
+	s := HoldingStats{Bounds: h.bounds}
+	if s.Bounds == nil {
+		s.Bounds = defaultHoldingBuckets
+	}
+	s.Bounds = append([]time.Duration{}, s.Bounds...)
+	s.Distribution = make([]int, len(s.Bounds)+1)
+
+	var total time.Duration
+	for _, info := range p.positions {
+		if info.Qty == 0 || info.OpenTime.IsZero() {
+			continue
+		}
+		age := h.now.Sub(info.OpenTime)
+		if age < 0 {
+			age = 0
+		}
+		s.OpenPositions++
+		total += age
+		if age > s.MaxPositionAge {
+			s.MaxPositionAge = age
+		}
+		if h.isStale(info) {
+			s.StalePositions = append(s.StalePositions, info.Symbol)
+		}
+	}
+	if s.OpenPositions > 0 {
+		s.AvgPositionAge = total / time.Duration(s.OpenPositions)
+	}
+	sort.Strings(s.StalePositions)
+
+	total = 0
+	for _, t := range p.trades {
+		if t.Type != ClosedTrade {
+			continue
+		}
+		held := t.CloseTime.Sub(t.OpenTime)
+		s.ClosedTrades++
+		total += held
+		s.Distribution[sort.Search(len(s.Bounds), func(i int) bool { return held < s.Bounds[i] })]++
+	}
+	if s.ClosedTrades > 0 {
+		s.AvgHoldingTime = total / time.Duration(s.ClosedTrades)
+	}
+	return s
+}
+
+//checkHolding updates time of position ages and returns positions which became stale
+func (p *portfolioHandler) checkHolding(t time.Time) []PositionInfo {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	h := &p.holding
+	h.now = t
+	if h.maxAge == 0 {
+		return nil
+	}
+	if h.alerted == nil {
+		h.alerted = make(map[string]time.Time)
+	}
+	var stale []PositionInfo
+	for s, info := range p.positions {
+		if !h.isStale(info) || h.alerted[s].Equal(info.OpenTime) {
+			continue
+		}
+		h.alerted[s] = info.OpenTime
+		stale = append(stale, info)
+	}
+	sort.Slice(stale, func(i, j int) bool {
+		return stale[i].Symbol < stale[j].Symbol
+	})
+	return stale
+}
+
+//checkStalePositions sends StalePositionEvent to strategies which positions became stale
+func (c *Engine) checkStalePositions(t time.Time) {
+	for _, info := range c.portfolio.checkHolding(t) {
+		st, ok := c.findStrategy(info.Symbol)
+		if !ok {
+			continue
+		}
+		e := &StalePositionEvent{
+			BaseEvent: be(t, st.getInstrument()),
+			Qty:       info.Qty,
+			OpenTime:  info.OpenTime,
+			Age:       t.Sub(info.OpenTime),
+		}
+		c.logMessage(e.String())
+		st.notify(e)
+	}
+}
+
+func (b *BasicStrategy) onStalePositionHandler(e *StalePositionEvent) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	if e.getTime().After(b.mostRecentTime) {
+		b.mostRecentTime = e.getTime()
+	}
+	if us, ok := b.userStrategy.(IStalePositionUserStrategy); ok {
+		us.OnStalePosition(b, e)
+	}
+}
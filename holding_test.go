@@ -0,0 +1,84 @@
+package engine
+
+import (
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type holdingTestStrategy struct {
+	DummyStrategy
+	stale []*StalePositionEvent
+}
+
+func (s *holdingTestStrategy) OnStalePosition(b *BasicStrategy, e *StalePositionEvent) {
+	s.stale = append(s.stale, e)
+}
+
+func TestPortfolio_HoldingStats(t *testing.T) {
+	p := newPortfolio()
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	for _, held := range []time.Duration{30 * time.Minute, 2 * time.Hour, 240 * time.Hour, 960 * time.Hour} {
+		p.onNewTrade(&Trade{Type: ClosedTrade, OpenTime: tm, CloseTime: tm.Add(held)})
+	}
+	p.onNewTrade(&Trade{Type: LongTrade, OpenTime: tm})
+	p.updatePosition(PositionInfo{Symbol: "A", Qty: 10, OpenTime: tm})
+	p.updatePosition(PositionInfo{Symbol: "B", Qty: -5, OpenTime: tm.Add(2 * time.Hour)})
+	p.updatePosition(PositionInfo{Symbol: "C"})
+	assert.Nil(t, p.checkHolding(tm.Add(4*time.Hour)))
+
+	s := p.HoldingStats()
+	assert.Equal(t, 2, s.OpenPositions)
+	assert.Equal(t, 3*time.Hour, s.AvgPositionAge)
+	assert.Equal(t, 4*time.Hour, s.MaxPositionAge)
+	assert.Empty(t, s.StalePositions)
+	assert.Equal(t, 4, s.ClosedTrades)
+	assert.Equal(t, time.Duration(300.625*float64(time.Hour)), s.AvgHoldingTime)
+	assert.Equal(t, []int{1, 1, 0, 1, 1}, s.Distribution)
+
+	c := Engine{portfolio: p}
+	assert.NotNil(t, c.SetHoldingBuckets([]time.Duration{2 * time.Hour, time.Hour}))
+	assert.Nil(t, c.SetHoldingBuckets([]time.Duration{time.Hour}))
+	assert.Equal(t, []int{1, 3}, p.HoldingStats().Distribution)
+}
+
+func TestEngine_checkStalePositions(t *testing.T) {
+	st := newTestMarginStrategy(10000)
+	st.symbol.InitialMargin, st.symbol.MaintenanceMargin = 0, 0
+	us := &holdingTestStrategy{}
+	st.userStrategy = us
+	tm := st.mostRecentTime
+	fillTestOrder(st, OrderBuy, 100, 50)
+
+	c := Engine{
+		strategiesMap: map[string]ICoreStrategy{st.symbol.Symbol: st},
+		portfolio:     st.portfolio,
+		mut:           &sync.Mutex{},
+		waitG:         &sync.WaitGroup{},
+	}
+	c.log.SetOutput(ioutil.Discard)
+	assert.NotNil(t, c.SetMaxHoldingPeriod(-time.Hour))
+	assert.Nil(t, c.SetMaxHoldingPeriod(48*time.Hour))
+
+	c.checkStalePositions(tm.Add(24 * time.Hour))
+	st.shutDown()
+	assert.Empty(t, us.stale)
+
+	c.checkStalePositions(tm.Add(49 * time.Hour))
+	st.shutDown()
+	assert.Len(t, us.stale, 1)
+	assert.Equal(t, int64(100), us.stale[0].Qty)
+	assert.Equal(t, 49*time.Hour, us.stale[0].Age)
+
+	//Alert is sent once for the position
+	c.checkStalePositions(tm.Add(50 * time.Hour))
+	st.shutDown()
+	assert.Len(t, us.stale, 1)
+	s := c.portfolio.HoldingStats()
+	assert.Equal(t, 1, s.OpenPositions)
+	assert.Equal(t, 50*time.Hour, s.AvgPositionAge)
+	assert.Equal(t, []string{st.symbol.Symbol}, s.StalePositions)
+}
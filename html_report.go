@@ -0,0 +1,248 @@
+package engine
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	htmlChartWidth  = 900
+	htmlChartHeight = 240
+)
+
+var htmlReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"pct": formatPercent,
+	"num": func(v float64) string { return formatNumber(v, "%.2f") },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Backtest report</title>
+<style>
+body { font-family: sans-serif; margin: 24px; color: #222; }
+table { border-collapse: collapse; margin-bottom: 24px; }
+td, th { border: 1px solid #ccc; padding: 4px 8px; text-align: right; }
+th { background: #f0f0f0; }
+svg { border: 1px solid #ccc; margin-bottom: 24px; }
+</style>
+</head>
+<body>
+<h1>Backtest report</h1>
+<p>{{.Performance.Start.Format "2006-01-02 15:04"}} - {{.Performance.End.Format "2006-01-02 15:04"}}</p>
+
+<h2>Performance</h2>
+<table>
+<tr><th>Initial equity</th><td>{{num .Performance.InitialEquity}}</td></tr>
+<tr><th>Final equity</th><td>{{num .Performance.FinalEquity}}</td></tr>
+<tr><th>Total return</th><td>{{pct .Performance.TotalReturn}}</td></tr>
+<tr><th>CAGR</th><td>{{pct .Performance.CAGR}}</td></tr>
+<tr><th>Sharpe</th><td>{{num .Performance.Sharpe}}</td></tr>
+<tr><th>Sortino</th><td>{{num .Performance.Sortino}}</td></tr>
+<tr><th>Max drawdown</th><td>{{pct .Performance.MaxDrawdown}}</td></tr>
+<tr><th>Exposure</th><td>{{pct .Performance.Exposure}}</td></tr>
+</table>
+
+{{with .Benchmark}}<h2>Benchmark {{.Symbol}}</h2>
+<table>
+<tr><th>Benchmark return</th><td>{{pct .BenchmarkReturn}}</td></tr>
+<tr><th>Alpha</th><td>{{pct .Alpha}}</td></tr>
+<tr><th>Beta</th><td>{{num .Beta}}</td></tr>
+<tr><th>Information ratio</th><td>{{num .InformationRatio}}</td></tr>
+<tr><th>Relative drawdown</th><td>{{pct .RelativeDrawdown}}</td></tr>
+</table>
+{{end}}
+<h2>Equity</h2>
+<svg width="{{.Width}}" height="{{.Height}}"><polyline fill="none" stroke="#1f77b4" points="{{.Equity}}"/></svg>
+
+<h2>Drawdown</h2>
+<svg width="{{.Width}}" height="{{.Height}}"><polyline fill="none" stroke="#d62728" points="{{.Drawdown}}"/></svg>
+
+<h2>Monthly returns</h2>
+<table>
+<tr><th>Year</th>{{range .MonthNames}}<th>{{.}}</th>{{end}}<th>Year</th></tr>
+{{range .Years}}<tr><th>{{.Year}}</th>{{range .Months}}<td>{{.}}</td>{{end}}<td>{{pct .Total}}</td></tr>
+{{end}}</table>
+
+<h2>Trades</h2>
+<table>
+<tr><th>Trades</th><td>{{.Stats.Trades}}</td></tr>
+<tr><th>Winners</th><td>{{.Stats.Winners}}</td></tr>
+<tr><th>Losers</th><td>{{.Stats.Losers}}</td></tr>
+<tr><th>Win rate</th><td>{{pct .Performance.WinRate}}</td></tr>
+<tr><th>Profit factor</th><td>{{num .Performance.ProfitFactor}}</td></tr>
+<tr><th>Gross profit</th><td>{{num .Stats.GrossProfit}}</td></tr>
+<tr><th>Gross loss</th><td>{{num .Stats.GrossLoss}}</td></tr>
+<tr><th>Average win</th><td>{{num .Stats.AvgWin}}</td></tr>
+<tr><th>Average loss</th><td>{{num .Stats.AvgLoss}}</td></tr>
+<tr><th>Expectancy</th><td>{{num .Stats.Expectancy}}</td></tr>
+<tr><th>Average duration</th><td>{{.Stats.AvgDuration}}</td></tr>
+</table>
+
+<h2>Run</h2>
+<table>
+{{with .Performance.Manifest}}<tr><th>Engine version</th><td>{{.EngineVersion}}</td></tr>
+<tr><th>Go version</th><td>{{.GoVersion}}</td></tr>
+<tr><th>Config hash</th><td>{{.ConfigHash}}</td></tr>
+<tr><th>Data fingerprint</th><td>{{.DataFingerprint}}</td></tr>
+{{range $name, $seed := .Seeds}}<tr><th>Seed {{$name}}</th><td>{{$seed}}</td></tr>
+{{end}}{{end}}</table>
+</body>
+</html>
+`))
+
+//htmlReportData is everything HTML report shows. Charts are points of SVG polylines.
+type htmlReportData struct {
+	Performance PerformanceReport
+	Stats       TradeStats
+	Width       int
+	Height      int
+	Equity      string
+	Drawdown    string
+	MonthNames  []string
+	Years       []htmlReportYear
+	//Benchmark is shown if benchmark is set and its data can be loaded from storage of market data
+	Benchmark *BenchmarkReport
+}
+
+//htmlReportYear is row of monthly returns table. Months without data are empty.
+type htmlReportYear struct {
+	Year   int
+	Months [12]string
+	Total  float64
+}
+
+//SetHTMLReport makes engine save HTML report to path after EndOfDataEvent. Empty path disables it.
+func (c *Engine) SetHTMLReport(path string) {
+	c.performance.mut.Lock()
+	c.performance.htmlPath = path
+	c.performance.mut.Unlock()
+}
+
+//saveHTMLReport saves HTML report if it's enabled
+func (c *Engine) saveHTMLReport() {
+	c.performance.mut.Lock()
+	path := c.performance.htmlPath
+	c.performance.mut.Unlock()
+	if path == "" {
+		return
+	}
+	if err := writeFile(path, c.WriteHTMLReport); err != nil {
+		c.logError(err)
+	}
+}
+
+//WriteHTMLReport writes static HTML page with performance, benchmark statistics, equity and drawdown charts,
+//monthly returns, trade statistics and manifest of the run. Performance of the run is used if the run is
+//finished, otherwise it's calculated for now.
+func (c *Engine) WriteHTMLReport(w io.Writer) error {
+	trades := c.ClosedTrades()
+	perf, ok := c.PerformanceReport()
+	if !ok {
+		perf = c.portfolio.performance(c.now(), trades)
+		perf.Manifest, _ = c.Manifest()
+	}
+	curve := c.portfolio.EquityCurve()
+	equities := make([]float64, len(curve))
+	for i, pt := range curve {
+		equities[i] = pt.Equity
+	}
+
+	data := htmlReportData{
+		Performance: perf,
+		Stats:       calcTradeStats(trades),
+		Width:       htmlChartWidth,
+		Height:      htmlChartHeight,
+		Equity:      svgPoints(equities, htmlChartWidth, htmlChartHeight),
+		Drawdown:    svgPoints(drawdownSeries(equities), htmlChartWidth, htmlChartHeight),
+		Years:       monthlyReturnsTable(c.portfolio.DailyReturns()),
+	}
+	if b, err := c.BenchmarkReport(nil); err == nil {
+		data.Benchmark = &b
+	}
+	for m := time.January; m <= time.December; m++ {
+		data.MonthNames = append(data.MonthNames, m.String()[:3])
+	}
+	return htmlReportTemplate.Execute(w, data)
+}
+
+//drawdownSeries returns negative drawdown from running peak for every equity
+func drawdownSeries(equities []float64) []float64 {
+	dd := make([]float64, len(equities))
+	peak := math.Inf(-1)
+	for i, eq := range equities {
+		peak = math.Max(peak, eq)
+		if peak > 0 {
+			dd[i] = eq/peak - 1
+		}
+	}
+	return dd
+}
+
+//monthlyReturnsTable compounds daily returns by month and year
+func monthlyReturnsTable(days []DailyReturn) []htmlReportYear {
+	var years []htmlReportYear
+	var month [12]float64
+	var seen [12]bool
+	flush := func() {
+		if n := len(years); n > 0 {
+			for i := range month {
+				if seen[i] {
+					years[n-1].Months[i] = formatPercent(month[i])
+				}
+			}
+		}
+	}
+	for _, d := range days {
+		if n := len(years); n == 0 || years[n-1].Year != d.Date.Year() {
+			flush()
+			years = append(years, htmlReportYear{Year: d.Date.Year()})
+			month, seen = [12]float64{}, [12]bool{}
+		}
+		y := &years[len(years)-1]
+		m := d.Date.Month() - 1
+		if !seen[m] {
+			month[m], seen[m] = 0, true
+		}
+		month[m] = (1+month[m])*(1+d.Return) - 1
+		y.Total = (1+y.Total)*(1+d.Return) - 1
+	}
+	flush()
+	return years
+}
+
+//svgPoints scales values to chart size and returns points attribute of polyline
+func svgPoints(values []float64, width, height int) string {
+	if len(values) < 2 {
+		return ""
+	}
+	min, max := values[0], values[0]
+	for _, v := range values {
+		min, max = math.Min(min, v), math.Max(max, v)
+	}
+	points := make([]string, len(values))
+	for i, v := range values {
+		y := float64(height) / 2
+		if max > min {
+			y = float64(height) * (max - v) / (max - min)
+		}
+		x := float64(width) * float64(i) / float64(len(values)-1)
+		points[i] = fmt.Sprintf("%.1f,%.1f", x, y)
+	}
+	return strings.Join(points, " ")
+}
+
+func formatPercent(v float64) string {
+	return formatNumber(v*100, "%.2f%%")
+}
+
+//formatNumber formats value with format or returns dash for NaN and infinity
+func formatNumber(v float64, format string) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return "-"
+	}
+	return fmt.Sprintf(format, v)
+}
@@ -0,0 +1,67 @@
+package engine
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMonthlyReturnsTable(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	years := monthlyReturnsTable([]DailyReturn{
+		{Date: day(2017, time.December, 28), Return: 0.1},
+		{Date: day(2018, time.January, 2), Return: 0.1},
+		{Date: day(2018, time.January, 3), Return: -0.1},
+		{Date: day(2018, time.March, 1), Return: 0.02},
+	})
+	if assert.Len(t, years, 2) {
+		assert.Equal(t, 2017, years[0].Year)
+		assert.Equal(t, "10.00%", years[0].Months[11])
+		assert.Equal(t, "", years[0].Months[0])
+		assert.Equal(t, "-1.00%", years[1].Months[0])
+		assert.Equal(t, "", years[1].Months[1])
+		assert.Equal(t, "2.00%", years[1].Months[2])
+		assert.InDelta(t, 0.99*1.02-1, years[1].Total, 0.0000001)
+	}
+}
+
+func TestDrawdownSeries(t *testing.T) {
+	dd := drawdownSeries([]float64{100, 110, 99, 121})
+	assert.InDeltaSlice(t, []float64{0, 0, -0.1, 0}, dd, 0.0000001)
+	assert.Equal(t, "0.0,20.0 50.0,0.0 100.0,10.0", svgPoints([]float64{1, 3, 2}, 100, 20))
+	assert.Equal(t, "", svgPoints([]float64{1}, 100, 20))
+	assert.Equal(t, "-", formatPercent(math.NaN()))
+}
+
+func TestEngine_WriteHTMLReport(t *testing.T) {
+	c := newTestEquityEngine()
+	day := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	c.portfolio.recordEquity(day)
+	c.portfolio.cash = 11000
+	c.portfolio.recordEquity(day.AddDate(0, 0, 1))
+
+	var buf bytes.Buffer
+	assert.Nil(t, c.WriteHTMLReport(&buf))
+	html := buf.String()
+	assert.Contains(t, html, "<polyline")
+	assert.Contains(t, html, "<th>2018</th>")
+	assert.Contains(t, html, "<td>11000.00</td>")
+	assert.Contains(t, html, "<th>Config hash</th>")
+
+	dir, err := ioutil.TempDir("", "report")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "report.html")
+	c.SetHTMLReport(path)
+	c.buildPerformanceReport(day.AddDate(0, 0, 1))
+	c.saveHTMLReport()
+	data, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "Monthly returns")
+}
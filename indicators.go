@@ -0,0 +1,352 @@
+package engine
+
+import (
+	"math"
+	"time"
+)
+
+//IIndicator is technical indicator updated incrementally by closed candles of base timeframe.
+//Value is NaN until indicator has enough data.
+type IIndicator interface {
+	Update(c *Candle)
+	Value() float64
+	Ready() bool
+}
+
+//ITickIndicator can be implemented by indicator which is updated by ticks too
+type ITickIndicator interface {
+	UpdateTick(t *Tick)
+}
+
+//rollingWindow keeps last n values with their sum and sum of squares
+type rollingWindow struct {
+	values []float64
+	pos    int
+	full   bool
+	sum    float64
+	sumSq  float64
+}
+
+func newRollingWindow(n int) *rollingWindow {
+	if n < 1 {
+		n = 1
+	}
+	return &rollingWindow{values: make([]float64, n)}
+}
+
+func (w *rollingWindow) add(v float64) {
+	old := w.values[w.pos]
+	if w.full {
+		w.sum -= old
+		w.sumSq -= old * old
+	}
+	w.values[w.pos] = v
+	w.sum += v
+	w.sumSq += v * v
+	w.pos++
+	if w.pos == len(w.values) {
+		w.pos = 0
+		w.full = true
+	}
+}
+
+func (w *rollingWindow) mean() float64 {
+	return w.sum / float64(len(w.values))
+}
+
+func (w *rollingWindow) stdDev() float64 {
+	m := w.mean()
+	v := w.sumSq/float64(len(w.values)) - m*m
+	if v < 0 {
+		return 0
+	}
+	return math.Sqrt(v)
+}
+
+//SMA is simple moving average of close prices
+type SMA struct {
+	window *rollingWindow
+}
+
+func NewSMA(period int) *SMA {
+	return &SMA{window: newRollingWindow(period)}
+}
+
+func (i *SMA) Update(c *Candle) {
+	i.window.add(c.Close)
+}
+
+func (i *SMA) Ready() bool {
+	return i.window.full
+}
+
+func (i *SMA) Value() float64 {
+	if !i.Ready() {
+		return math.NaN()
+	}
+	return i.window.mean()
+}
+
+//EMA is exponential moving average of close prices. First value is SMA of the first Period closes.
+type EMA struct {
+	Period int
+	alpha  float64
+	value  float64
+	n      int
+	sum    float64
+}
+
+func NewEMA(period int) *EMA {
+	if period < 1 {
+		period = 1
+	}
+	return &EMA{Period: period, alpha: 2 / float64(period+1)}
+}
+
+func (i *EMA) Update(c *Candle) {
+	i.add(c.Close)
+}
+
+func (i *EMA) add(v float64) {
+	if i.n < i.Period {
+		i.n++
+		i.sum += v
+		i.value = i.sum / float64(i.n)
+		return
+	}
+	i.value += i.alpha * (v - i.value)
+}
+
+func (i *EMA) Ready() bool {
+	return i.n >= i.Period
+}
+
+func (i *EMA) Value() float64 {
+	if !i.Ready() {
+		return math.NaN()
+	}
+	return i.value
+}
+
+//wilderAverage is running average with Wilder smoothing. First value is simple average of Period values.
+type wilderAverage struct {
+	period int
+	n      int
+	value  float64
+}
+
+func (a *wilderAverage) add(v float64) {
+	if a.n < a.period {
+		a.n++
+		a.value += (v - a.value) / float64(a.n)
+		return
+	}
+	a.value = (a.value*float64(a.period-1) + v) / float64(a.period)
+}
+
+func (a *wilderAverage) ready() bool {
+	return a.n >= a.period
+}
+
+//RSI is relative strength index of close prices with Wilder smoothing
+type RSI struct {
+	gain      wilderAverage
+	loss      wilderAverage
+	prevClose float64
+	hasPrev   bool
+}
+
+func NewRSI(period int) *RSI {
+	if period < 1 {
+		period = 1
+	}
+	return &RSI{gain: wilderAverage{period: period}, loss: wilderAverage{period: period}}
+}
+
+func (i *RSI) Update(c *Candle) {
+	if !i.hasPrev {
+		i.prevClose = c.Close
+		i.hasPrev = true
+		return
+	}
+	change := c.Close - i.prevClose
+	i.prevClose = c.Close
+	i.gain.add(math.Max(change, 0))
+	i.loss.add(math.Max(-change, 0))
+}
+
+func (i *RSI) Ready() bool {
+	return i.gain.ready()
+}
+
+func (i *RSI) Value() float64 {
+	if !i.Ready() {
+		return math.NaN()
+	}
+	if i.loss.value == 0 {
+		if i.gain.value == 0 {
+			return 50
+		}
+		return 100
+	}
+	return 100 - 100/(1+i.gain.value/i.loss.value)
+}
+
+//ATR is average true range with Wilder smoothing
+type ATR struct {
+	tr        wilderAverage
+	prevClose float64
+	hasPrev   bool
+}
+
+func NewATR(period int) *ATR {
+	if period < 1 {
+		period = 1
+	}
+	return &ATR{tr: wilderAverage{period: period}}
+}
+
+func (i *ATR) Update(c *Candle) {
+	tr := c.High - c.Low
+	if i.hasPrev {
+		tr = math.Max(tr, math.Max(math.Abs(c.High-i.prevClose), math.Abs(c.Low-i.prevClose)))
+	}
+	i.prevClose = c.Close
+	i.hasPrev = true
+	i.tr.add(tr)
+}
+
+func (i *ATR) Ready() bool {
+	return i.tr.ready()
+}
+
+func (i *ATR) Value() float64 {
+	if !i.Ready() {
+		return math.NaN()
+	}
+	return i.tr.value
+}
+
+//Bollinger is Bollinger bands of close prices. Value is middle band.
+type Bollinger struct {
+	K      float64
+	window *rollingWindow
+}
+
+func NewBollinger(period int, k float64) *Bollinger {
+	return &Bollinger{K: k, window: newRollingWindow(period)}
+}
+
+func (i *Bollinger) Update(c *Candle) {
+	i.window.add(c.Close)
+}
+
+func (i *Bollinger) Ready() bool {
+	return i.window.full
+}
+
+func (i *Bollinger) Value() float64 {
+	if !i.Ready() {
+		return math.NaN()
+	}
+	return i.window.mean()
+}
+
+func (i *Bollinger) Upper() float64 {
+	return i.Value() + i.K*i.window.stdDev()
+}
+
+func (i *Bollinger) Lower() float64 {
+	return i.Value() - i.K*i.window.stdDev()
+}
+
+//MACD is difference of fast and slow EMA of close prices. Value is MACD line, Signal is EMA of MACD line.
+type MACD struct {
+	fast   *EMA
+	slow   *EMA
+	signal *EMA
+}
+
+func NewMACD(fast int, slow int, signal int) *MACD {
+	return &MACD{fast: NewEMA(fast), slow: NewEMA(slow), signal: NewEMA(signal)}
+}
+
+func (i *MACD) Update(c *Candle) {
+	i.fast.add(c.Close)
+	i.slow.add(c.Close)
+	if i.fast.Ready() && i.slow.Ready() {
+		i.signal.add(i.fast.value - i.slow.value)
+	}
+}
+
+func (i *MACD) Ready() bool {
+	return i.signal.Ready()
+}
+
+func (i *MACD) Value() float64 {
+	if !i.fast.Ready() || !i.slow.Ready() {
+		return math.NaN()
+	}
+	return i.fast.value - i.slow.value
+}
+
+func (i *MACD) Signal() float64 {
+	return i.signal.Value()
+}
+
+func (i *MACD) Histogram() float64 {
+	return i.Value() - i.Signal()
+}
+
+//VWAP is volume weighted average price of the current day. It's updated by trades of ticks if strategy
+//gets ticks and by typical price of candles otherwise.
+type VWAP struct {
+	day       time.Time
+	value     float64
+	volume    float64
+	fromTicks bool
+}
+
+func NewVWAP() *VWAP {
+	return &VWAP{}
+}
+
+func (i *VWAP) add(t time.Time, price float64, volume float64) {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	if !day.Equal(i.day) {
+		i.day = day
+		i.value = 0
+		i.volume = 0
+	}
+	if volume <= 0 {
+		return
+	}
+	i.value += price * volume
+	i.volume += volume
+}
+
+func (i *VWAP) Update(c *Candle) {
+	if i.fromTicks {
+		return
+	}
+	i.add(c.Datetime, (c.High+c.Low+c.Close)/3, float64(c.Volume))
+}
+
+func (i *VWAP) UpdateTick(t *Tick) {
+	if !t.HasTrade() {
+		return
+	}
+	i.fromTicks = true
+	i.add(t.Datetime, t.LastPrice, float64(t.LastSize))
+}
+
+func (i *VWAP) Ready() bool {
+	return i.volume > 0
+}
+
+func (i *VWAP) Value() float64 {
+	if !i.Ready() {
+		return math.NaN()
+	}
+	return i.value / i.volume
+}
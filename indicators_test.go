@@ -0,0 +1,100 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"time"
+)
+
+func newTestIndicatorCandle(high, low, close float64) *Candle {
+	return newTestMinuteCandle(time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC), close, high, low, close)
+}
+
+func updateWithCloses(ind IIndicator, closes ...float64) {
+	for _, c := range closes {
+		ind.Update(newTestIndicatorCandle(c, c, c))
+	}
+}
+
+func TestSMA(t *testing.T) {
+	sma := NewSMA(3)
+	updateWithCloses(sma, 1, 2)
+	assert.False(t, sma.Ready())
+	assert.True(t, math.IsNaN(sma.Value()))
+	updateWithCloses(sma, 3, 4, 5)
+	assert.True(t, sma.Ready())
+	assert.InDelta(t, 4.0, sma.Value(), 0.0000001)
+}
+
+func TestEMA(t *testing.T) {
+	ema := NewEMA(3)
+	updateWithCloses(ema, 1, 2, 3)
+	assert.InDelta(t, 2.0, ema.Value(), 0.0000001)
+	updateWithCloses(ema, 4, 5)
+	assert.InDelta(t, 4.0, ema.Value(), 0.0000001)
+}
+
+func TestRSI(t *testing.T) {
+	rsi := NewRSI(2)
+	updateWithCloses(rsi, 1, 2, 3)
+	assert.Equal(t, 100.0, rsi.Value())
+	updateWithCloses(rsi, 2)
+	assert.InDelta(t, 50.0, rsi.Value(), 0.0000001)
+}
+
+func TestATR(t *testing.T) {
+	atr := NewATR(2)
+	atr.Update(newTestIndicatorCandle(10, 8, 9))
+	atr.Update(newTestIndicatorCandle(11, 9, 10))
+	assert.InDelta(t, 2.0, atr.Value(), 0.0000001)
+	atr.Update(newTestIndicatorCandle(12, 9, 11))
+	assert.InDelta(t, 2.5, atr.Value(), 0.0000001)
+}
+
+func TestBollinger(t *testing.T) {
+	bb := NewBollinger(3, 2)
+	updateWithCloses(bb, 5, 1, 2, 3)
+	assert.InDelta(t, 2.0, bb.Value(), 0.0000001)
+	assert.InDelta(t, 2+2*math.Sqrt(2.0/3), bb.Upper(), 0.0000001)
+	assert.InDelta(t, 2-2*math.Sqrt(2.0/3), bb.Lower(), 0.0000001)
+}
+
+func TestMACD(t *testing.T) {
+	macd := NewMACD(2, 3, 2)
+	updateWithCloses(macd, 1, 2, 3)
+	assert.False(t, macd.Ready())
+	assert.InDelta(t, 0.5, macd.Value(), 0.0000001)
+	updateWithCloses(macd, 4)
+	assert.True(t, macd.Ready())
+	assert.InDelta(t, 0.5, macd.Value(), 0.0000001)
+	assert.InDelta(t, 0.5, macd.Signal(), 0.0000001)
+	assert.InDelta(t, 0.0, macd.Histogram(), 0.0000001)
+}
+
+func TestVWAP(t *testing.T) {
+	vwap := NewVWAP()
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	vwap.UpdateTick(&Tick{Tick: &marketdata.Tick{Datetime: tm, LastPrice: 10, LastSize: 100}})
+	vwap.UpdateTick(&Tick{Tick: &marketdata.Tick{Datetime: tm.Add(time.Minute), LastPrice: 12, LastSize: 300}})
+	vwap.Update(newTestIndicatorCandle(20, 20, 20))
+	assert.InDelta(t, 11.5, vwap.Value(), 0.0000001)
+
+	vwap.UpdateTick(&Tick{Tick: &marketdata.Tick{Datetime: tm.AddDate(0, 0, 1), LastPrice: 13, LastSize: 100}})
+	assert.InDelta(t, 13.0, vwap.Value(), 0.0000001)
+}
+
+func TestBasicStrategy_AddIndicator(t *testing.T) {
+	st := newTestBasicStrategy()
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	st.notify(newTestCandleCloseEvent(10, 10, 10, 10, tm, "1"))
+	st.shutDown()
+
+	st.AddIndicator("sma", NewSMA(2))
+	st.notify(newTestCandleCloseEvent(12, 12, 12, 12, tm.Add(time.Minute), "1"))
+	st.shutDown()
+
+	assert.InDelta(t, 11.0, st.Indicator("sma").Value(), 0.0000001)
+	assert.Nil(t, st.Indicator("unknown"))
+}
@@ -0,0 +1,219 @@
+package engine
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+//IEventJournal records events of the run in order they pass the engine
+type IEventJournal interface {
+	Append(e Event) error
+	Flush() error
+}
+
+//JournalRecord is event of the journal with its number in the stream
+type JournalRecord struct {
+	Seq   int64
+	Event EventJSON
+}
+
+//FileEventJournal appends events to file as JSON lines. Records are buffered until Flush or Close.
+type FileEventJournal struct {
+	mut sync.Mutex
+	f   *os.File
+	w   *bufio.Writer
+	seq int64
+}
+
+//NewFileEventJournal opens journal file for appending. Numbers of records continue from zero, so journal
+//file of every run should be new.
+func NewFileEventJournal(path string) (*FileEventJournal, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &FileEventJournal{f: f, w: bufio.NewWriter(f)}, nil
+}
+
+func (j *FileEventJournal) Append(e Event) error {
+	j.mut.Lock()
+	defer j.mut.Unlock()
+	j.seq++
+	data, err := json.Marshal(JournalRecord{Seq: j.seq, Event: EventJSON{Event: e}})
+	if err != nil {
+		return err
+	}
+	if _, err := j.w.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (j *FileEventJournal) Flush() error {
+	j.mut.Lock()
+	defer j.mut.Unlock()
+	return j.w.Flush()
+}
+
+func (j *FileEventJournal) Close() error {
+	if err := j.Flush(); err != nil {
+		j.f.Close()
+		return err
+	}
+	return j.f.Close()
+}
+
+//ReadEventJournal returns events of journal file in recorded order
+func ReadEventJournal(path string) ([]Event, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var events []Event
+	d := json.NewDecoder(bufio.NewReader(f))
+	for {
+		var r JournalRecord
+		err := d.Decode(&r)
+		if err == io.EOF {
+			return events, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, r.Event.Event)
+	}
+}
+
+//SetEventJournal makes engine record all events published to the event bus. Journal is flushed at shutdown.
+func (c *Engine) SetEventJournal(j IEventJournal) {
+	c.journal = j
+	c.EventBus().Subscribe(EventFilter{}, func(e Event) {
+		if err := j.Append(e); err != nil {
+			c.logError(err)
+		}
+	})
+}
+
+func (c *Engine) flushJournal() {
+	if c.journal == nil {
+		return
+	}
+	if err := c.journal.Flush(); err != nil {
+		c.logError(err)
+	}
+}
+
+//JournalReplayer is market data which feeds market data events of the journal to the engine, so strategies
+//get exactly the same data as in the recorded run, while broker and strategies process it again. Orders and
+//broker events of the journal are skipped, they are produced by the replay itself.
+type JournalReplayer struct {
+	events    []Event
+	symbols   map[string]*Instrument
+	errChan   chan error
+	mdChan    chan event
+	waitGroup *sync.WaitGroup
+	ctx       context.Context
+}
+
+func NewJournalReplayer(events []Event) *JournalReplayer {
+	return &JournalReplayer{events: events, waitGroup: &sync.WaitGroup{}}
+}
+
+func (r *JournalReplayer) Init(errChan chan error, mdChan chan event) {
+	if errChan == nil {
+		panic("Error chan is nil")
+	}
+	if mdChan == nil {
+		panic("Event chan is nil")
+	}
+	r.errChan = errChan
+	r.mdChan = mdChan
+}
+
+//SetSymbols sets instruments of the engine. Events of the journal get them instead of restored instruments.
+func (r *JournalReplayer) SetSymbols(symbols []*Instrument) {
+	r.symbols = make(map[string]*Instrument, len(symbols))
+	for _, s := range symbols {
+		r.symbols[s.Symbol] = s
+	}
+}
+
+//SetContext makes replayer stop sending events when context is done
+func (r *JournalReplayer) SetContext(ctx context.Context) {
+	r.ctx = ctx
+}
+
+func (r *JournalReplayer) Connect() {}
+
+//RequestHistoricalData does nothing. History events are replayed if they are in the journal.
+func (r *JournalReplayer) RequestHistoricalData(duration time.Duration) {}
+
+//Run sends market data events of the journal. Run always ends with EndOfDataEvent.
+func (r *JournalReplayer) Run() {
+	r.waitGroup.Add(1)
+	go func() {
+		defer r.waitGroup.Done()
+		for _, e := range r.events {
+			if !isMarketDataEvent(e) {
+				continue
+			}
+			r.useEngineInstruments(e)
+			if !sendEvent(r.ctx, r.mdChan, e) {
+				return
+			}
+			if _, ok := e.(*EndOfDataEvent); ok {
+				return
+			}
+		}
+		sendEvent(r.ctx, r.mdChan, &EndOfDataEvent{BaseEvent: be(time.Now(), &Instrument{})})
+	}()
+}
+
+func (r *JournalReplayer) ShutDown() {
+	waitContext(r.ctx, r.waitGroup)
+}
+
+//useEngineInstruments replaces restored instruments of the event with engine ones, which strategies and
+//broker compare by pointer
+func (r *JournalReplayer) useEngineInstruments(e Event) {
+	replaceInstruments(e, func(i *Instrument) *Instrument {
+		if i == nil {
+			return nil
+		}
+		if s, ok := r.symbols[i.Symbol]; ok {
+			return s
+		}
+		return i
+	})
+}
+
+//replaceInstruments replaces instruments of the event and of its ticks and candles
+func replaceInstruments(e Event, inst func(i *Instrument) *Instrument) {
+	if b, ok := e.(interface{ base() *BaseEvent }); ok {
+		b.base().Ticker = inst(b.base().Ticker)
+	}
+	switch i := e.(type) {
+	case *NewTickEvent:
+		if i.Tick != nil {
+			i.Tick.Ticker = inst(i.Tick.Ticker)
+		}
+	case *TickHistoryEvent:
+		for _, t := range i.Ticks {
+			t.Ticker = inst(t.Ticker)
+		}
+	case *CandleCloseEvent:
+		if i.Candle != nil {
+			i.Candle.Ticker = inst(i.Candle.Ticker)
+		}
+	case *CandlesHistoryEvent:
+		for _, c := range i.Candles {
+			c.Ticker = inst(c.Ticker)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"io/ioutil"
+	"os"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileEventJournal(t *testing.T) {
+	folder, err := ioutil.TempDir("", "journal")
+	assert.Nil(t, err)
+	defer os.RemoveAll(folder)
+	file := path.Join(folder, "run.jsonl")
+
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	inst := &Instrument{Symbol: "SPY"}
+	j, err := NewFileEventJournal(file)
+	assert.Nil(t, err)
+	assert.Nil(t, j.Append(&OrderConfirmationEvent{BaseEvent: be(tm, inst), OrdId: "1"}))
+	assert.Nil(t, j.Append(&OrderFillEvent{BaseEvent: be(tm, inst), OrdId: "1", Price: 10, Qty: 100}))
+	assert.Nil(t, j.Close())
+
+	events, err := ReadEventJournal(file)
+	assert.Nil(t, err)
+	assert.Len(t, events, 2)
+	assert.Equal(t, "1", events[0].(*OrderConfirmationEvent).OrdId)
+	assert.Equal(t, int64(100), events[1].(*OrderFillEvent).Qty)
+	assert.Equal(t, "SPY", events[1].getSymbol())
+}
+
+func TestEngine_SetEventJournal(t *testing.T) {
+	folder, err := ioutil.TempDir("", "journal")
+	assert.Nil(t, err)
+	defer os.RemoveAll(folder)
+	file := path.Join(folder, "run.jsonl")
+	j, err := NewFileEventJournal(file)
+	assert.Nil(t, err)
+	defer j.Close()
+
+	c := Engine{mut: &sync.Mutex{}, waitG: &sync.WaitGroup{}}
+	c.log.SetOutput(ioutil.Discard)
+	c.SetEventJournal(j)
+	inst := newTestInstrument()
+	c.proxyEvent(&StrategyHaltedEvent{BaseEvent: be(time.Now(), inst), Reason: "Test"})
+	//Market data passed back by simulated broker is already recorded by market data loop
+	c.proxyEvent(&NewTickEvent{BaseEvent: be(time.Now(), inst), Tick: &Tick{Tick: &marketdata.Tick{}}})
+	c.flushJournal()
+
+	events, err := ReadEventJournal(file)
+	assert.Nil(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, "Test", events[0].(*StrategyHaltedEvent).Reason)
+}
+
+func TestJournalReplayer(t *testing.T) {
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	restored := &Instrument{Symbol: "SPY"}
+	candle := &Candle{Candle: &marketdata.Candle{Datetime: tm, Symbol: "SPY", Close: 10}, Ticker: restored}
+	events := []Event{
+		&NewTickEvent{BaseEvent: be(tm, restored), Tick: &Tick{Tick: &marketdata.Tick{Datetime: tm}, Ticker: restored}},
+		&OrderFillEvent{BaseEvent: be(tm, restored), OrdId: "1", Price: 10, Qty: 100},
+		&CandleCloseEvent{BaseEvent: be(tm, restored), Candle: candle, TimeFrame: "1"},
+	}
+
+	inst := &Instrument{Symbol: "SPY"}
+	mdChan := make(chan event)
+	r := NewJournalReplayer(events)
+	r.Init(make(chan error), mdChan)
+	r.SetSymbols([]*Instrument{inst})
+	r.Run()
+
+	tick := (<-mdChan).(*NewTickEvent)
+	assert.True(t, tick.Ticker == inst)
+	assert.True(t, tick.Tick.Ticker == inst)
+	//Fills are produced by broker of the replay
+	cc := (<-mdChan).(*CandleCloseEvent)
+	assert.True(t, cc.Candle.Ticker == inst)
+	_, ok := (<-mdChan).(*EndOfDataEvent)
+	assert.True(t, ok)
+	r.ShutDown()
+}
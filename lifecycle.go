@@ -0,0 +1,191 @@
+package engine
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+//EngineState is stage of engine lifecycle
+type EngineState string
+
+const (
+	//StateCreated engine isn't connected to broker and market data yet
+	StateCreated EngineState = "Created"
+	//StateInitialized broker and market data are initialized, engine is ready to run
+	StateInitialized EngineState = "Initialized"
+	//StateWarmingUp strategies get warm up history before the first market data
+	StateWarmingUp EngineState = "WarmingUp"
+	//StateRunning market data is passed to strategies
+	StateRunning EngineState = "Running"
+	//StateStopping run is stopped or finished, engine waits for strategies, broker and market data
+	StateStopping EngineState = "Stopping"
+	//StateStopped run is over. Engine can be run again.
+	StateStopped EngineState = "Stopped"
+	//StateFailed engine can't run, e.g. market data isn't connected. Err of EngineStatus is the reason.
+	StateFailed EngineState = "Failed"
+)
+
+//engineTransitions are allowed changes of engine state. Any state except Stopped can change to Failed.
+var engineTransitions = map[EngineState][]EngineState{
+	StateCreated:     {StateInitialized},
+	StateInitialized: {StateWarmingUp, StateRunning},
+	StateWarmingUp:   {StateRunning, StateStopping},
+	StateRunning:     {StateStopping},
+	StateStopping:    {StateStopped},
+	StateStopped:     {StateWarmingUp, StateRunning},
+}
+
+//ErrInvalidTransition is returned when engine can't change state, e.g. it's run second time while running
+type ErrInvalidTransition struct {
+	From EngineState
+	To   EngineState
+}
+
+func (e *ErrInvalidTransition) Error() string {
+	return fmt.Sprintf("Engine: ErrInvalidTransition (%v -> %v). ", e.From, e.To)
+}
+
+func (e *ErrInvalidTransition) Code() ErrCode {
+	return ErrCodeInvalidTransition
+}
+
+func (e *ErrInvalidTransition) Is(target error) bool {
+	return isCode(e.Code(), target)
+}
+
+//EngineStatus is current state of engine and time when engine got it
+type EngineStatus struct {
+	State EngineState
+	Since time.Time
+	Err   error
+}
+
+//IConnectionStatus can be implemented by broker and market data which know if connection succeeded. Engine
+//doesn't run and fails if they are not connected after Connect.
+type IConnectionStatus interface {
+	IsConnected() bool
+}
+
+//lifecycle is state of the engine. Engine which isn't created by NewEngine has empty state and its
+//transitions are not checked.
+type lifecycle struct {
+	mut    sync.Mutex
+	status EngineStatus
+}
+
+func (l *lifecycle) get() EngineStatus {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+	return l.status
+}
+
+//transition changes state if it's allowed
+func (l *lifecycle) transition(to EngineState, err error) error {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+	from := l.status.State
+	if from != "" && !canTransit(from, to) {
+		return &ErrInvalidTransition{From: from, To: to}
+	}
+	l.status = EngineStatus{State: to, Since: time.Now(), Err: err}
+	return nil
+}
+
+func canTransit(from, to EngineState) bool {
+	if to == StateFailed {
+		return from != StateStopped
+	}
+	for _, s := range engineTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+//State returns current state of the engine
+func (c *Engine) State() EngineState {
+	return c.lifecycle.get().State
+}
+
+//Status returns current state of the engine with time of the last transition and error of failed engine
+func (c *Engine) Status() EngineStatus {
+	return c.lifecycle.get()
+}
+
+//startRun moves engine to WarmingUp or Running state
+func (c *Engine) startRun() error {
+	to := StateRunning
+	if c.warmUp {
+		to = StateWarmingUp
+	}
+	return c.lifecycle.transition(to, nil)
+}
+
+//warmUpDone moves engine from WarmingUp to Running when the first market data after warm up history comes
+func (c *Engine) warmUpDone() {
+	if c.State() == StateWarmingUp {
+		c.lifecycle.transition(StateRunning, nil)
+	}
+}
+
+//stopping moves running engine to Stopping state
+func (c *Engine) stopping() {
+	switch c.State() {
+	case StateRunning, StateWarmingUp:
+		c.lifecycle.transition(StateStopping, nil)
+	}
+}
+
+//fail moves engine to Failed state and logs the reason
+func (c *Engine) fail(err error) {
+	c.lifecycle.transition(StateFailed, err)
+	c.logError(err)
+}
+
+//checkConnected returns error if broker or market data know that they are not connected
+func (c *Engine) checkConnected() error {
+	if s, ok := c.md.(IConnectionStatus); ok && !s.IsConnected() {
+		return &EngineError{Kind: ErrCodeNotConnected, Message: "Market data is not connected. "}
+	}
+	if s, ok := c.broker.(IConnectionStatus); ok && !s.IsConnected() {
+		return &EngineError{Kind: ErrCodeNotConnected, Message: "Broker is not connected. "}
+	}
+	return nil
+}
+
+//universeMarketData is implemented by market data which produces SymbolAddedEvent for universe symbols
+type universeMarketData interface {
+	getUniverse() *Universe
+}
+
+//checkUniverse returns error if universe has symbols without strategy and there is no strategy factory to
+//create them when symbols are added
+func (c *Engine) checkUniverse() error {
+	m, ok := c.md.(universeMarketData)
+	if !ok || m.getUniverse() == nil {
+		return nil
+	}
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	if c.strategyFactory != nil {
+		return nil
+	}
+	for _, inst := range m.getUniverse().Instruments() {
+		if _, ok := c.strategiesMap[inst.Symbol]; !ok {
+			return &EngineError{Kind: ErrCodeNoStrategyFactory,
+				Message: "Universe symbol " + inst.Symbol + " has no strategy and strategy factory is not set. "}
+		}
+	}
+	return nil
+}
+
+//canNotifyBroker returns false if broker isn't initialized yet or run is over
+func (c *Engine) canNotifyBroker() bool {
+	switch c.State() {
+	case StateCreated, StateStopped, StateFailed:
+		return false
+	}
+	return true
+}
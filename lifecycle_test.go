@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//disconnectedTestBTM is market data which failed to connect
+type disconnectedTestBTM struct {
+	BTM
+}
+
+func (m *disconnectedTestBTM) IsConnected() bool {
+	return false
+}
+
+func TestLifecycle_transition(t *testing.T) {
+	l := lifecycle{}
+	for _, s := range []EngineState{StateCreated, StateInitialized, StateWarmingUp, StateRunning, StateStopping,
+		StateStopped, StateRunning} {
+		assert.Nil(t, l.transition(s, nil))
+	}
+	assert.Equal(t, StateRunning, l.get().State)
+
+	err := l.transition(StateInitialized, nil)
+	assert.Equal(t, &ErrInvalidTransition{From: StateRunning, To: StateInitialized}, err)
+	assert.NotNil(t, l.transition(StateRunning, nil))
+	assert.Equal(t, StateRunning, l.get().State)
+
+	assert.Nil(t, l.transition(StateFailed, os.ErrClosed))
+	assert.Equal(t, os.ErrClosed, l.get().Err)
+	assert.NotNil(t, l.transition(StateRunning, nil))
+
+	stopped := lifecycle{status: EngineStatus{State: StateStopped}}
+	assert.NotNil(t, stopped.transition(StateFailed, nil))
+}
+
+func TestEngine_Lifecycle(t *testing.T) {
+	if _, err := os.Stat("log.txt"); os.IsNotExist(err) {
+		defer os.Remove("log.txt")
+	}
+	st := NewBasicStrategy(newTestInstrument(), 1, &DummyStrategy{})
+	c := NewEngine(map[string]ICoreStrategy{"Test": st}, newTestSimBroker(), &disconnectedTestBTM{},
+		BacktestMode, false)
+	assert.Equal(t, StateInitialized, c.State())
+	assert.True(t, c.canNotifyBroker())
+
+	c.Stop()
+	assert.Equal(t, StateInitialized, c.State())
+
+	c.Run()
+	status := c.Status()
+	assert.Equal(t, StateFailed, status.State)
+	assert.EqualError(t, status.Err, "Market data is not connected. ")
+	assert.False(t, status.Since.IsZero())
+	assert.False(t, c.canNotifyBroker())
+
+	//Failed engine doesn't run again
+	c.Run()
+	assert.Equal(t, StateFailed, c.State())
+}
+
+func TestEngine_StopRunning(t *testing.T) {
+	c := Engine{mut: &sync.Mutex{}, warmUp: true}
+	assert.Nil(t, c.startRun())
+	assert.Equal(t, StateWarmingUp, c.State())
+	c.warmUpDone()
+	assert.Equal(t, StateRunning, c.State())
+	assert.NotNil(t, c.startRun())
+
+	c.Stop()
+	assert.Equal(t, StateStopping, c.State())
+	assert.True(t, c.canNotifyBroker())
+}
+
+func TestEngine_deliverRequestAfterRun(t *testing.T) {
+	broker := &failingDeliveryBroker{}
+	c, st := newTestDeadLetterEngine(broker)
+	c.lifecycle.status.State = StateStopped
+
+	id, err := st.NewLimitOrder(10, OrderBuy, 100, GTCTIF, "Sim")
+	assert.Nil(t, err)
+	c.deliverRequest(<-st.ch.events)
+	st.handlersWaitGroup.Wait()
+
+	assert.Equal(t, 0, broker.attempts)
+	letters := c.DeadLetters()
+	if assert.Len(t, letters, 1) {
+		assert.Equal(t, id, letters[0].OrdId)
+		assert.EqualError(t, letters[0].Err, "Engine is Stopped. ")
+	}
+	assert.Equal(t, int32(0), st.waitingN)
+}
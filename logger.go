@@ -0,0 +1,235 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type LogLevel int
+
+const (
+	DebugLevel LogLevel = iota
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+)
+
+func (l LogLevel) String() string {
+	switch l {
+	case DebugLevel:
+		return "DEBUG"
+	case InfoLevel:
+		return "INFO"
+	case WarnLevel:
+		return "WARN"
+	case ErrorLevel:
+		return "ERROR"
+	}
+	return fmt.Sprintf("LEVEL%d", int(l))
+}
+
+//LogFields are structured values of the log record, e.g. symbol or order id
+type LogFields map[string]interface{}
+
+//ILogger is structured logger. Implementations should be safe for concurrent use.
+type ILogger interface {
+	Log(level LogLevel, message string, fields LogFields)
+}
+
+//WithFields returns logger which adds fields to every record. Fields of the record override context fields
+//with the same name.
+func WithFields(l ILogger, fields LogFields) ILogger {
+	return &contextLogger{logger: l, fields: fields}
+}
+
+type contextLogger struct {
+	logger ILogger
+	fields LogFields
+}
+
+func (l *contextLogger) Log(level LogLevel, message string, fields LogFields) {
+	merged := make(LogFields, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	l.logger.Log(level, message, merged)
+}
+
+//TextLogger writes records as lines of text: time, level, message and sorted key=value fields
+type TextLogger struct {
+	w        io.Writer
+	minLevel LogLevel
+	mut      *sync.Mutex
+}
+
+func NewTextLogger(w io.Writer, minLevel LogLevel) *TextLogger {
+	return &TextLogger{w: w, minLevel: minLevel, mut: &sync.Mutex{}}
+}
+
+func (l *TextLogger) Log(level LogLevel, message string, fields LogFields) {
+	if level < l.minLevel {
+		return
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString(time.Now().Format("2006-01-02 15:04:05.000"))
+	sb.WriteString(" ")
+	sb.WriteString(level.String())
+	sb.WriteString(" ")
+	sb.WriteString(message)
+	for _, k := range keys {
+		fmt.Fprintf(&sb, " %v=%v", k, formatLogValue(fields[k]))
+	}
+	sb.WriteString("\n")
+
+	l.mut.Lock()
+	io.WriteString(l.w, sb.String())
+	l.mut.Unlock()
+}
+
+//JSONLogger writes every record as JSON object on separate line
+type JSONLogger struct {
+	w        io.Writer
+	minLevel LogLevel
+	mut      *sync.Mutex
+}
+
+func NewJSONLogger(w io.Writer, minLevel LogLevel) *JSONLogger {
+	return &JSONLogger{w: w, minLevel: minLevel, mut: &sync.Mutex{}}
+}
+
+func (l *JSONLogger) Log(level LogLevel, message string, fields LogFields) {
+	if level < l.minLevel {
+		return
+	}
+	record := make(map[string]interface{}, len(fields)+3)
+	for k, v := range fields {
+		if t, ok := v.(time.Time); ok {
+			v = t.Format(time.RFC3339Nano)
+		}
+		record[k] = v
+	}
+	record["ts"] = time.Now().Format(time.RFC3339Nano)
+	record["level"] = level.String()
+	record["msg"] = message
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		data, _ = json.Marshal(map[string]string{"level": ErrorLevel.String(), "msg": err.Error()})
+	}
+	l.mut.Lock()
+	l.w.Write(append(data, '\n'))
+	l.mut.Unlock()
+}
+
+func formatLogValue(v interface{}) string {
+	switch i := v.(type) {
+	case time.Time:
+		return i.Format("2006-01-02 15:04:05.000")
+	case string:
+		if strings.ContainsAny(i, " \t\"=") {
+			return fmt.Sprintf("%q", i)
+		}
+		return i
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+//ILoggerAware can be implemented by broker or market data to write records to the logger of the engine
+//instead of stdout
+type ILoggerAware interface {
+	SetLogger(l ILogger)
+}
+
+//LoggerFunc is function used as logger, e.g. to write records to a logger without adapter:
+//
+//	engine.LoggerFunc(func(level engine.LogLevel, msg string, fields engine.LogFields) {
+//		zl.WithLevel(zerolog.Level(level)).Fields(map[string]interface{}(fields)).Msg(msg)
+//	})
+type LoggerFunc func(level LogLevel, message string, fields LogFields)
+
+func (f LoggerFunc) Log(level LogLevel, message string, fields LogFields) {
+	f(level, message, fields)
+}
+
+//errorFields returns structured fields of engine error: its code and symbol, order and event time if error
+//has them
+func errorFields(err error) LogFields {
+	fields := LogFields{"code": string(ErrorCode(err))}
+	var anomaly *ErrDataAnomaly
+	if errors.As(err, &anomaly) {
+		fields["symbol"] = anomaly.Symbol
+		fields["event"] = anomaly.Event
+		fields["time"] = anomaly.Time
+	}
+	var ordID string
+	var invalidOrder *ErrInvalidOrder
+	var notFound *ErrOrderNotFoundInOrdersMap
+	var wrongID *ErrOrderIdIncorrect
+	var wrongState *ErrUnexpectedOrderState
+	var wrongType *ErrUnexpectedOrderType
+	switch {
+	case errors.As(err, &invalidOrder):
+		ordID = invalidOrder.OrdId
+	case errors.As(err, &notFound):
+		ordID = notFound.OrdId
+	case errors.As(err, &wrongID):
+		ordID = wrongID.OrdId
+	case errors.As(err, &wrongState):
+		ordID = wrongState.OrdId
+	case errors.As(err, &wrongType):
+		ordID = wrongType.OrdId
+	}
+	if ordID != "" {
+		fields["orderID"] = ordID
+	}
+	return fields
+}
+
+//eventLogFields returns structured fields of the event: symbol, event name, event time and order ID of
+//broker events and strategy requests, correlation ID of the decision if the event has it
+func eventLogFields(e event) LogFields {
+	fields := LogFields{"symbol": e.getSymbol(), "event": eventTypeName(e), "time": e.getTime()}
+	if ordID := eventOrderID(e); ordID != "" {
+		fields["orderID"] = ordID
+	}
+	if id := correlationIDOf(e); id != "" {
+		fields["correlationID"] = id
+	}
+	return fields
+}
+
+//eventOrderID returns ID of the order which request or broker event belongs to
+func eventOrderID(e event) string {
+	switch i := e.(type) {
+	case *OrderConfirmationEvent:
+		return i.OrdId
+	case *OrderFillEvent:
+		return i.OrdId
+	case *OrderCancelEvent:
+		return i.OrdId
+	case *OrderCancelRejectEvent:
+		return i.OrdId
+	case *OrderReplacedEvent:
+		return i.OrdId
+	case *OrderReplaceRejectEvent:
+		return i.OrdId
+	case *OrderRejectedEvent:
+		return i.OrdId
+	}
+	return requestOrderID(e)
+}
@@ -0,0 +1,75 @@
+package engine
+
+import (
+	"sort"
+)
+
+//IKeyValueLogger is logger with alternating keys and values, e.g. *slog.Logger of the standard library
+type IKeyValueLogger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+//ISugaredLogger is logger with alternating keys and values in methods with w suffix, e.g. *zap.SugaredLogger
+type ISugaredLogger interface {
+	Debugw(msg string, keysAndValues ...interface{})
+	Infow(msg string, keysAndValues ...interface{})
+	Warnw(msg string, keysAndValues ...interface{})
+	Errorw(msg string, keysAndValues ...interface{})
+}
+
+//NewSlogAdapter writes records of the engine to slog or other key-value logger:
+//
+//	e.SetLogger(engine.NewSlogAdapter(slog.Default()))
+func NewSlogAdapter(l IKeyValueLogger) ILogger {
+	return LoggerFunc(func(level LogLevel, message string, fields LogFields) {
+		args := keysAndValues(fields)
+		switch {
+		case level <= DebugLevel:
+			l.Debug(message, args...)
+		case level == InfoLevel:
+			l.Info(message, args...)
+		case level == WarnLevel:
+			l.Warn(message, args...)
+		default:
+			l.Error(message, args...)
+		}
+	})
+}
+
+//NewZapAdapter writes records of the engine to zap sugared logger:
+//
+//	e.SetLogger(engine.NewZapAdapter(zapLogger.Sugar()))
+//
+//Zerolog has no common interface, LoggerFunc can be used with it.
+func NewZapAdapter(l ISugaredLogger) ILogger {
+	return LoggerFunc(func(level LogLevel, message string, fields LogFields) {
+		args := keysAndValues(fields)
+		switch {
+		case level <= DebugLevel:
+			l.Debugw(message, args...)
+		case level == InfoLevel:
+			l.Infow(message, args...)
+		case level == WarnLevel:
+			l.Warnw(message, args...)
+		default:
+			l.Errorw(message, args...)
+		}
+	})
+}
+
+//keysAndValues returns fields as alternating keys and values sorted by key
+func keysAndValues(fields LogFields) []interface{} {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	args := make([]interface{}, 0, 2*len(keys))
+	for _, k := range keys {
+		args = append(args, k, fields[k])
+	}
+	return args
+}
@@ -0,0 +1,48 @@
+package engine
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//keyValueTestLogger has methods of slog and zap sugared loggers
+type keyValueTestLogger struct {
+	records []string
+}
+
+func (l *keyValueTestLogger) add(level string, msg string, args []interface{}) {
+	l.records = append(l.records, fmt.Sprint(level, " ", msg, " ", args))
+}
+
+func (l *keyValueTestLogger) Debug(msg string, args ...interface{}) { l.add("debug", msg, args) }
+func (l *keyValueTestLogger) Info(msg string, args ...interface{})  { l.add("info", msg, args) }
+func (l *keyValueTestLogger) Warn(msg string, args ...interface{})  { l.add("warn", msg, args) }
+func (l *keyValueTestLogger) Error(msg string, args ...interface{}) { l.add("error", msg, args) }
+
+func (l *keyValueTestLogger) Debugw(msg string, args ...interface{}) { l.add("debugw", msg, args) }
+func (l *keyValueTestLogger) Infow(msg string, args ...interface{})  { l.add("infow", msg, args) }
+func (l *keyValueTestLogger) Warnw(msg string, args ...interface{})  { l.add("warnw", msg, args) }
+func (l *keyValueTestLogger) Errorw(msg string, args ...interface{}) { l.add("errorw", msg, args) }
+
+func logTestRecords(l ILogger) {
+	l.Log(DebugLevel, "Tick", nil)
+	l.Log(InfoLevel, "Fill", LogFields{"symbol": "SPY", "orderID": "SPY|1"})
+	l.Log(WarnLevel, "Gap", nil)
+	l.Log(ErrorLevel, "Reject", nil)
+}
+
+func TestNewSlogAdapter(t *testing.T) {
+	kv := &keyValueTestLogger{}
+	logTestRecords(NewSlogAdapter(kv))
+	assert.Equal(t, []string{"debug Tick []", "info Fill [orderID SPY|1 symbol SPY]", "warn Gap []",
+		"error Reject []"}, kv.records)
+}
+
+func TestNewZapAdapter(t *testing.T) {
+	kv := &keyValueTestLogger{}
+	logTestRecords(NewZapAdapter(kv))
+	assert.Equal(t, []string{"debugw Tick []", "infow Fill [orderID SPY|1 symbol SPY]", "warnw Gap []",
+		"errorw Reject []"}, kv.records)
+}
@@ -0,0 +1,123 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTextLogger_Log(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := NewTextLogger(buf, InfoLevel)
+	l.Log(DebugLevel, "skipped", nil)
+	l.Log(WarnLevel, "Order rejected", LogFields{"symbol": "SPY", "reason": "no money",
+		"time": time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 1)
+	assert.True(t, strings.HasSuffix(lines[0],
+		` WARN Order rejected reason="no money" symbol=SPY time=2018-03-05 10:00:00.000`), lines[0])
+}
+
+func TestJSONLogger_Log(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := WithFields(NewJSONLogger(buf, DebugLevel), LogFields{"symbol": "SPY", "strategy": "ma"})
+	l.Log(InfoLevel, "Fill", LogFields{"symbol": "QQQ", "qty": 100})
+
+	var record map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, "Fill", record["msg"])
+	assert.Equal(t, "QQQ", record["symbol"])
+	assert.Equal(t, "ma", record["strategy"])
+	assert.Equal(t, 100.0, record["qty"])
+}
+
+func TestBasicStrategy_Log(t *testing.T) {
+	st := newTestBasicStrategy()
+	buf := &bytes.Buffer{}
+	st.Log(InfoLevel, "No logger", nil)
+	st.setLogger(NewJSONLogger(buf, DebugLevel))
+	st.mostRecentTime = time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	st.sendEventForLogging(&NewsEvent{BaseEvent: be(st.mostRecentTime, st.symbol), Headline: "Test"})
+
+	var record map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "Test", record["symbol"])
+	assert.Equal(t, "NewsEvent", record["event"])
+	assert.Equal(t, "2018-03-05T10:00:00Z", record["time"])
+}
+
+//memoryTestLogger keeps records in memory
+type memoryTestLogger struct {
+	mut     sync.Mutex
+	records []string
+	fields  []LogFields
+}
+
+func (l *memoryTestLogger) Log(level LogLevel, message string, fields LogFields) {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+	l.records = append(l.records, level.String()+" "+message)
+	l.fields = append(l.fields, fields)
+}
+
+func TestErrorFields(t *testing.T) {
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	anomaly := newErrDataAnomaly(newTestCandleCloseEvent(10, 20, 9, 10, tm, "1"), "Out of order", "BTM")
+	fields := errorFields(fmt.Errorf("Skipped: %w", anomaly))
+	assert.Equal(t, LogFields{"code": "marketdata.anomaly", "symbol": "Test", "event": anomaly.Event,
+		"time": anomaly.Time}, fields)
+
+	fields = errorFields(&ErrOrderNotFoundInConfirmedMap{ErrOrderNotFoundInOrdersMap{OrdId: "Test|1"}})
+	assert.Equal(t, LogFields{"code": "order.not_found", "orderID": "Test|1"}, fields)
+	assert.Equal(t, LogFields{"code": "unknown"}, errorFields(errors.New("Connection lost")))
+}
+
+func TestEventLogFields(t *testing.T) {
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	fields := eventLogFields(&OrderFillEvent{BaseEvent: be(tm, newTestInstrument()), OrdId: "Test|1"})
+	assert.Equal(t, LogFields{"symbol": "Test", "event": "OrderFillEvent", "time": tm, "orderID": "Test|1"}, fields)
+
+	fields = eventLogFields(&OrderCancelRequestEvent{BaseEvent: be(tm, newTestInstrument()), OrdId: "Test|2"})
+	assert.Equal(t, "Test|2", fields["orderID"])
+	_, ok := eventLogFields(newTestCandleCloseEvent(10, 20, 9, 10, tm, "1"))["orderID"]
+	assert.False(t, ok)
+}
+
+func TestEngine_SetLogger(t *testing.T) {
+	st := newTestBasicStrategy()
+	broker := &SimBroker{}
+	md := &BTM{}
+	c := Engine{strategiesMap: map[string]ICoreStrategy{st.symbol.Symbol: st}, broker: broker, md: md,
+		mut: &sync.Mutex{}, waitG: &sync.WaitGroup{}}
+	l := &memoryTestLogger{}
+	c.SetLogger(l)
+
+	c.logMessage("Engine Run")
+	c.logError(&ErrInvalidOrder{OrdId: "Test|1", Message: "No price", Caller: "Test"})
+	broker.Connect()
+	md.Connect()
+	st.Log(WarnLevel, "Strategy message", nil)
+
+	assert.Equal(t, []string{"INFO Engine Run", "ERROR Test: ErrInvalidOrder (id:Test|1). No price",
+		"INFO SimBroker connected", "INFO Backtest market data connected. ", "WARN Strategy message"}, l.records)
+	assert.Equal(t, LogFields{"code": "order.invalid", "orderID": "Test|1"}, l.fields[1])
+	assert.Equal(t, "broker", l.fields[2]["component"])
+	assert.Equal(t, "marketdata", l.fields[3]["component"])
+	assert.Equal(t, "strategy", l.fields[4]["component"])
+	assert.Equal(t, "Test", l.fields[4]["symbol"])
+
+	//Strategy logger isn't replaced
+	strategyLogger := &memoryTestLogger{}
+	c.SetStrategyLogger(strategyLogger)
+	c.SetLogger(l)
+	st.Log(InfoLevel, "Strategy message", nil)
+	assert.Len(t, strategyLogger.records, 1)
+}
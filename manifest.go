@@ -0,0 +1,308 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+//EngineVersion is written to manifests of runs. It should be changed when the same run can give other results.
+const EngineVersion = "0.9.0"
+
+//RunManifest identifies everything results of the run depend on: version of the engine, hash of engine
+//configuration, fingerprint of market data and seeds of random generators. Runs with equal manifests should
+//have equal fills. DataFingerprint is empty if market data can't be fingerprinted.
+type RunManifest struct {
+	EngineVersion   string
+	GoVersion       string
+	ConfigHash      string
+	DataFingerprint string
+	Seeds           map[string]int64
+	Created         time.Time
+}
+
+//RunManifestEvent is published at start of the run, so journal of the run begins with its manifest
+type RunManifestEvent struct {
+	BaseEvent
+	Manifest RunManifest
+}
+
+func (c *RunManifestEvent) getName() string {
+	return "RunManifestEvent"
+}
+
+func (c *RunManifestEvent) String() string {
+	return fmt.Sprintf("%v **%v** Version: %v Config: %v Data: %v", c.getStringTime(), c.getName(),
+		c.Manifest.EngineVersion, c.Manifest.ConfigHash, c.Manifest.DataFingerprint)
+}
+
+//manifestState keeps seeds set by user and manifest of the started run
+type manifestState struct {
+	mut      sync.Mutex
+	seeds    map[string]int64
+	manifest *RunManifest
+}
+
+//SetSeed records seed of random generator used by strategies or other user code, so it's part of the manifest.
+//Seed of synthetic market data is recorded automatically.
+func (c *Engine) SetSeed(name string, seed int64) {
+	c.manifest.mut.Lock()
+	defer c.manifest.mut.Unlock()
+	if c.manifest.seeds == nil {
+		c.manifest.seeds = make(map[string]int64)
+	}
+	c.manifest.seeds[name] = seed
+}
+
+//Manifest returns manifest of the run. Before the run it's built from current configuration.
+func (c *Engine) Manifest() (RunManifest, error) {
+	c.manifest.mut.Lock()
+	m := c.manifest.manifest
+	c.manifest.mut.Unlock()
+	if m != nil {
+		return *m, nil
+	}
+	return c.buildManifest()
+}
+
+//startManifest builds manifest at start of the run and publishes it
+func (c *Engine) startManifest() {
+	m, err := c.buildManifest()
+	if err != nil {
+		c.logError(err)
+	}
+	c.manifest.mut.Lock()
+	c.manifest.manifest = &m
+	c.manifest.mut.Unlock()
+	c.bus.Publish(&RunManifestEvent{BaseEvent: BaseEvent{Time: m.Created}, Manifest: m})
+}
+
+func (c *Engine) buildManifest() (RunManifest, error) {
+	m := RunManifest{
+		EngineVersion: EngineVersion,
+		GoVersion:     runtime.Version(),
+		Seeds:         make(map[string]int64),
+		Created:       time.Now(),
+	}
+	c.manifest.mut.Lock()
+	for k, v := range c.manifest.seeds {
+		m.Seeds[k] = v
+	}
+	c.manifest.mut.Unlock()
+	if btm, ok := c.md.(*BTM); ok {
+		if s, ok := btm.Storage.(*SyntheticStorage); ok {
+			m.Seeds["SyntheticStorage"] = s.Seed
+		}
+	}
+
+	var err error
+	if m.ConfigHash, err = c.configHash(); err != nil {
+		return m, err
+	}
+	m.DataFingerprint, err = c.dataFingerprint()
+	return m, err
+}
+
+//configHash returns hash of configuration of the engine
+func (c *Engine) configHash() (string, error) {
+	config, err := json.Marshal(c.runConfig())
+	if err != nil {
+		return "", err
+	}
+	return hashBytes(config), nil
+}
+
+//runConfig is configuration of the engine which changes results of the run
+type runConfig struct {
+	Mode          EngineMode
+	RunMode       RunMode
+	Deterministic bool
+	Broker        string
+	MarketData    string
+	Capital       float64
+	Currency      string
+	Strategies    []strategyConfig
+	Data          *dataConfig
+}
+
+type strategyConfig struct {
+	Symbol  string
+	Name    string
+	Type    string
+	Periods int
+	Params  map[string]float64
+}
+
+type dataConfig struct {
+	Symbols   []string
+	From      time.Time
+	To        time.Time
+	Mode      MarketDataMode
+	TimeFrame string
+	Auction   *OpeningAuction
+	Delisted  map[string]Delisting
+}
+
+func (c *Engine) runConfig() runConfig {
+	c.mut.Lock()
+	cfg := runConfig{
+		Mode:          c.engineMode,
+		RunMode:       c.runMode,
+		Deterministic: c.deterministic,
+		Broker:        fmt.Sprintf("%T", c.broker),
+		MarketData:    fmt.Sprintf("%T", c.md),
+	}
+	for s, st := range c.strategiesMap {
+		switch st := st.(type) {
+		case *BasicStrategy:
+			cfg.Strategies = append(cfg.Strategies, newStrategyConfig(s, "", st))
+		case *StrategyGroup:
+			for _, name := range st.names {
+				cfg.Strategies = append(cfg.Strategies, newStrategyConfig(s, name, st.members[name]))
+			}
+		default:
+			cfg.Strategies = append(cfg.Strategies, strategyConfig{Symbol: s, Type: fmt.Sprintf("%T", st)})
+		}
+	}
+	c.mut.Unlock()
+	sort.Slice(cfg.Strategies, func(i, j int) bool {
+		a, b := cfg.Strategies[i], cfg.Strategies[j]
+		if a.Symbol != b.Symbol {
+			return a.Symbol < b.Symbol
+		}
+		return a.Name < b.Name
+	})
+
+	c.portfolio.mut.RLock()
+	cfg.Capital = c.portfolio.capital
+	c.portfolio.mut.RUnlock()
+	c.portfolio.fx.mut.RLock()
+	cfg.Currency = c.portfolio.fx.account
+	c.portfolio.fx.mut.RUnlock()
+
+	if m, ok := c.md.(*BTM); ok {
+		d := dataConfig{From: m.FromDate, To: m.ToDate, Mode: m.mode, Auction: m.auction,
+			Delisted: m.delistings}
+		for _, s := range m.Symbols {
+			d.Symbols = append(d.Symbols, s.Symbol)
+		}
+		sort.Strings(d.Symbols)
+		if m.mode == MarketDataModeCandles {
+			d.TimeFrame = m.candlesTimeFrame
+		}
+		cfg.Data = &d
+	}
+	return cfg
+}
+
+func newStrategyConfig(symbol string, name string, b *BasicStrategy) strategyConfig {
+	s := strategyConfig{Symbol: symbol, Name: name, Type: fmt.Sprintf("%T", b.userStrategy), Periods: b.nPeriods,
+		Params: b.paramValues}
+	if b.params != nil {
+		s.Params = b.params.Values()
+	}
+	return s
+}
+
+//dataFingerprint returns fingerprint of storage of BTM data or hash of prepaired file if storage doesn't have
+//fingerprints
+func (c *Engine) dataFingerprint() (string, error) {
+	m, ok := c.md.(*BTM)
+	if !ok || len(m.Symbols) == 0 {
+		return "", nil
+	}
+	if m.Storage != nil {
+		f, err := m.sourceFingerprint(m.FromDate, m.ToDate)
+		if err != nil || f != "" {
+			return f, err
+		}
+	}
+	f, err := os.Open(m.getPrepairedFilePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	defer f.Close()
+	h := fnv.New64a()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return strconv.FormatUint(h.Sum64(), 16), nil
+}
+
+func hashBytes(data []byte) string {
+	h := fnv.New64a()
+	h.Write(data)
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+//RunFill is fill of the run compared by verification. Order ids are random, so they aren't compared.
+type RunFill struct {
+	Time   time.Time
+	Symbol string
+	Side   OrderSide
+	Qty    int64
+	Price  float64
+}
+
+//VerifyRun builds and runs engine twice in deterministic mode and checks that both runs have the same manifest
+//and the same fills in the same order. Build should create new engine with new market data and broker every
+//time. It returns manifest and fills of the first run.
+func VerifyRun(build func() (*Engine, error)) (RunManifest, []RunFill, error) {
+	r, err := checkRuns(build, 2, false)
+	return r.manifest, r.fills, err
+}
+
+func equalSeeds(a map[string]int64, b map[string]int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if s, ok := b[k]; !ok || s != v {
+			return false
+		}
+	}
+	return true
+}
+
+//fillRecorder collects fills of the run from event bus with sides of their orders
+type fillRecorder struct {
+	mut   sync.Mutex
+	sides map[string]OrderSide
+	fills []RunFill
+}
+
+func recordFills(e *Engine) *fillRecorder {
+	r := &fillRecorder{sides: make(map[string]OrderSide)}
+	e.EventBus().Subscribe(EventFilter{Types: []string{"NewOrderEvent", "OrderFillEvent"}}, r.onEvent)
+	return r
+}
+
+func (r *fillRecorder) onEvent(e Event) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	switch e := e.(type) {
+	case *NewOrderEvent:
+		if e.LinkedOrder != nil {
+			r.sides[e.LinkedOrder.Id] = e.LinkedOrder.Side
+		}
+	case *OrderFillEvent:
+		r.fills = append(r.fills, RunFill{Time: e.getTime(), Symbol: e.getSymbol(), Side: r.sides[e.OrdId],
+			Qty: e.Qty, Price: e.Price})
+	}
+}
+
+func (r *fillRecorder) result() []RunFill {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	return append([]RunFill{}, r.fills...)
+}
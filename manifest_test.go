@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//manifestTestStrategy buys and sells one share in turn every 20 ticks
+type manifestTestStrategy struct {
+	DummyStrategy
+	ticks int
+}
+
+func (s *manifestTestStrategy) OnTick(b *BasicStrategy, tick *Tick) {
+	s.ticks++
+	if s.ticks%20 != 0 {
+		return
+	}
+	side := OrderBuy
+	if s.ticks%40 == 0 {
+		side = OrderSell
+	}
+	if _, err := b.NewMarketOrder(side, 1, DayTIF, "Sim"); err != nil {
+		panic(err)
+	}
+}
+
+func TestEngine_Manifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "manifest")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	start := time.Date(2018, 3, 5, 0, 0, 0, 0, time.UTC)
+	md := &BTM{Symbols: []*Instrument{newTestInstrument()}, Folder: dir, mode: MarketDataModeTicks,
+		Storage: NewSyntheticStorage(42, start, 100, &GBMProcess{Volatility: 0.2}), waitGroup: &sync.WaitGroup{}}
+	st := newTestBasicStrategy()
+	c := Engine{strategiesMap: map[string]ICoreStrategy{st.symbol.Symbol: st}, portfolio: newPortfolio(),
+		md: md, broker: &SimBroker{}, mut: &sync.Mutex{}}
+	c.SetSeed("strategy", 5)
+
+	m, err := c.Manifest()
+	assert.Nil(t, err)
+	assert.Equal(t, EngineVersion, m.EngineVersion)
+	assert.Equal(t, map[string]int64{"strategy": 5, "SyntheticStorage": 42}, m.Seeds)
+	assert.NotEmpty(t, m.ConfigHash)
+	assert.Empty(t, m.DataFingerprint)
+
+	same, err := c.Manifest()
+	assert.Nil(t, err)
+	assert.Equal(t, m.ConfigHash, same.ConfigHash)
+	c.portfolio.setCapital(5000)
+	changed, err := c.Manifest()
+	assert.Nil(t, err)
+	assert.NotEqual(t, m.ConfigHash, changed.ConfigHash)
+
+	assert.Nil(t, ioutil.WriteFile(md.getPrepairedFilePath(), []byte("data"), 0644))
+	m, err = c.Manifest()
+	assert.Nil(t, err)
+	assert.NotEmpty(t, m.DataFingerprint)
+
+	data, err := json.Marshal(EventJSON{Event: &RunManifestEvent{BaseEvent: BaseEvent{Time: m.Created},
+		Manifest: m}})
+	assert.Nil(t, err)
+	var restored EventJSON
+	assert.Nil(t, json.Unmarshal(data, &restored))
+	if e, ok := restored.Event.(*RunManifestEvent); assert.True(t, ok) {
+		assert.Equal(t, m.ConfigHash, e.Manifest.ConfigHash)
+		assert.Equal(t, m.Seeds, e.Manifest.Seeds)
+	}
+}
+
+func TestVerifyRun(t *testing.T) {
+	data := newTestBTMforTicks()
+	build := func(capital float64) func() (*Engine, error) {
+		return func() (*Engine, error) {
+			return New(WithMarketData(data.clone()), WithBroker(&SimBroker{}),
+				WithStrategy(&Instrument{Symbol: "Sym1"}, &manifestTestStrategy{}, 1),
+				WithPortfolio(PortfolioConfig{InitialCapital: capital}))
+		}
+	}
+
+	m, fills, err := VerifyRun(build(10000))
+	assert.Nil(t, err)
+	assert.NotEmpty(t, m.ConfigHash)
+	if assert.NotEmpty(t, fills) {
+		assert.Equal(t, "Sym1", fills[0].Symbol)
+		assert.Equal(t, OrderBuy, fills[0].Side)
+	}
+
+	runs := 0
+	_, _, err = VerifyRun(func() (*Engine, error) {
+		runs++
+		return build(float64(runs) * 10000)()
+	})
+	assert.NotNil(t, err)
+}
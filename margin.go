@@ -0,0 +1,159 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+//MarginPolicy tells engine what to do on margin call. If Liquidate is true every strategy closes its position
+//with market order sent to Destination after user strategy callback.
+type MarginPolicy struct {
+	Liquidate   bool
+	Destination string
+}
+
+//IMarginCallUserStrategy can be implemented by user strategy to be notified about margin call of the account
+type IMarginCallUserStrategy interface {
+	OnMarginCall(b *BasicStrategy, e *MarginCallEvent)
+}
+
+//marginState keeps margin policy of the portfolio and whether account is in margin call now
+type marginState struct {
+	policy MarginPolicy
+	called bool
+}
+
+//positionMargin returns initial and maintenance margin of the position in quote currency. Margins are
+//fractions of absolute market value of the position.
+func positionMargin(inst *Instrument, marketValue float64) (float64, float64) {
+	v := math.Abs(marketValue)
+	return v * inst.InitialMargin, v * inst.MaintenanceMargin
+}
+
+//InitialMargin is sum of initial margin requirements of all positions in account currency
+func (p *portfolioHandler) InitialMargin() float64 {
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	m := 0.0
+	for _, v := range p.positions {
+		m += p.fx.toAccount(v.InitialMargin, v.Currency)
+	}
+	return m
+}
+
+//MaintenanceMargin is sum of maintenance margin requirements of all positions in account currency
+func (p *portfolioHandler) MaintenanceMargin() float64 {
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	m := 0.0
+	for _, v := range p.positions {
+		m += p.fx.toAccount(v.MaintenanceMargin, v.Currency)
+	}
+	return m
+}
+
+//Leverage is gross exposure divided by equity
+func (p *portfolioHandler) Leverage() float64 {
+	equity := p.Equity()
+	if equity <= 0 {
+		return math.Inf(1)
+	}
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	exp := 0.0
+	for _, v := range p.positions {
+		exp += math.Abs(p.fx.toAccount(v.MarketValue, v.Currency))
+	}
+	return exp / equity
+}
+
+func (p *portfolioHandler) setMarginPolicy(policy MarginPolicy) {
+	p.mut.Lock()
+	p.margin.policy = policy
+	p.mut.Unlock()
+}
+
+//checkMarginCall returns MarginCallEvent when equity falls below maintenance margin. Event is produced once,
+//next one is possible after equity is back above maintenance margin.
+func (p *portfolioHandler) checkMarginCall(t time.Time, inst *Instrument) *MarginCallEvent {
+	equity := p.Equity()
+	maintenance := p.MaintenanceMargin()
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	if maintenance == 0 || equity >= maintenance {
+		p.margin.called = false
+		return nil
+	}
+	if p.margin.called {
+		return nil
+	}
+	p.margin.called = true
+	return &MarginCallEvent{
+		BaseEvent:         be(t, inst),
+		Equity:            equity,
+		MaintenanceMargin: maintenance,
+		Liquidate:         p.margin.policy.Liquidate,
+		Destination:       p.margin.policy.Destination,
+	}
+}
+
+//checkMargin notifies engine about margin call of the account
+func (b *BasicStrategy) checkMargin() {
+	if b.portfolio == nil {
+		return
+	}
+	if e := b.portfolio.checkMarginCall(b.mostRecentTime, b.symbol); e != nil {
+		b.newSignal(e)
+	}
+}
+
+//checkOrderMargin returns error if initial margin of the account with projected position is larger than equity.
+//Orders which reduce position are always allowed.
+func (b *BasicStrategy) checkOrderMargin(order *Order, projected int64, reducing bool) error {
+	if b.portfolio == nil || b.symbol.InitialMargin <= 0 || reducing {
+		return nil
+	}
+	price := order.Price
+	if order.Type == MarketOrder || order.Type == MarketOnOpen || order.Type == MarketOnClose {
+		price = b.lastPrice()
+	}
+	if math.IsNaN(price) || price <= 0 {
+		return nil
+	}
+	required := b.portfolio.InitialMargin()
+	if pos, ok := b.portfolio.Position(b.symbol.Symbol); ok {
+		required -= b.portfolio.fx.toAccount(pos.InitialMargin, pos.Currency)
+	}
+	value := b.unitValue(price) * float64(abs64(projected))
+	initial, _ := positionMargin(b.symbol, value)
+	required += initial
+	if equity := b.portfolio.Equity(); required > equity {
+		return fmt.Errorf("Initial margin %v is larger than equity %v. ", required, equity)
+	}
+	return nil
+}
+
+func (b *BasicStrategy) onMarginCallHandler(e *MarginCallEvent) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	if e.getTime().After(b.mostRecentTime) {
+		b.mostRecentTime = e.getTime()
+	}
+	if us, ok := b.userStrategy.(IMarginCallUserStrategy); ok {
+		us.OnMarginCall(b, e)
+	}
+	if !e.Liquidate {
+		return
+	}
+	b.target = nil
+	if _, err := b.ClosePosition(MarketOrder, math.NaN(), e.Destination); err != nil {
+		b.newError(err)
+	}
+}
+
+//SetMarginPolicy sets what engine does on margin call
+func (c *Engine) SetMarginPolicy(policy MarginPolicy) {
+	c.portfolio.setMarginPolicy(policy)
+}
@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+)
+
+type marginTestStrategy struct {
+	DummyStrategy
+	calls []*MarginCallEvent
+}
+
+func (s *marginTestStrategy) OnMarginCall(b *BasicStrategy, e *MarginCallEvent) {
+	s.calls = append(s.calls, e)
+}
+
+func newTestMarginStrategy(capital float64) *BasicStrategy {
+	st := newTestRiskStrategy()
+	st.userStrategy = &marginTestStrategy{}
+	st.symbol.LotSize = 1
+	st.symbol.InitialMargin = 0.5
+	st.symbol.MaintenanceMargin = 0.25
+	p := newPortfolio()
+	p.setCapital(capital)
+	st.setPortfolio(p)
+	st.mostRecentTime = time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	return st
+}
+
+func TestBasicStrategy_checkOrderMargin(t *testing.T) {
+	st := newTestMarginStrategy(10000)
+	_, err := st.NewLimitOrder(50, OrderBuy, 500, GTCTIF, "Sim")
+	assert.NotNil(t, err)
+	_, err = st.NewLimitOrder(50, OrderBuy, 400, GTCTIF, "Sim")
+	assert.Nil(t, err)
+
+	fillTestOrder(st, OrderSell, 200, 50)
+	assert.InDelta(t, 5000.0, st.Portfolio().InitialMargin(), 0.0000001)
+	assert.InDelta(t, 2500.0, st.Portfolio().MaintenanceMargin(), 0.0000001)
+	assert.InDelta(t, 1.0, st.Portfolio().Leverage(), 0.0000001)
+
+	//Orders which reduce position don't need margin
+	_, err = st.NewLimitOrder(50, OrderSell, 600, GTCTIF, "Sim")
+	assert.NotNil(t, err)
+	_, err = st.NewLimitOrder(50, OrderBuy, 200, GTCTIF, "Sim")
+	assert.Nil(t, err)
+}
+
+func TestBasicStrategy_MarginCall(t *testing.T) {
+	st := newTestMarginStrategy(10000)
+	us := st.userStrategy.(*marginTestStrategy)
+	fillTestOrder(st, OrderBuy, 400, 50)
+	assert.InDelta(t, 2.0, st.Portfolio().Leverage(), 0.0000001)
+	for len(st.ch.events) > 0 {
+		<-st.ch.events
+	}
+
+	st.notify(newTestCandleCloseEvent(36, 36, 36, 36, st.mostRecentTime.Add(time.Minute), "1"))
+	st.shutDown()
+	assert.Len(t, st.ch.events, 0)
+
+	st.notify(newTestCandleCloseEvent(33, 33, 33, 33, st.mostRecentTime.Add(2*time.Minute), "1"))
+	st.shutDown()
+	st.notify(newTestCandleCloseEvent(32, 32, 32, 32, st.mostRecentTime.Add(3*time.Minute), "1"))
+	st.shutDown()
+	//Margin call is produced once
+	assert.Len(t, st.ch.events, 1)
+	e := (<-st.ch.events).(*MarginCallEvent)
+	assert.InDelta(t, 3200.0, e.Equity, 0.0000001)
+	assert.InDelta(t, 3300.0, e.MaintenanceMargin, 0.0000001)
+	assert.False(t, e.Liquidate)
+
+	st.portfolio.setMarginPolicy(MarginPolicy{Liquidate: true, Destination: "Sim"})
+	c := Engine{strategiesMap: map[string]ICoreStrategy{"Test": st}, mut: &sync.Mutex{}, waitG: &sync.WaitGroup{}}
+	c.log.SetOutput(ioutil.Discard)
+	c.proxyEvent(e)
+	c.firePortfolioEvents()
+	st.shutDown()
+	assert.Len(t, us.calls, 1)
+	assert.Len(t, c.portfolioEvents, 0)
+
+	//Liquidation is done only if policy was set before margin call
+	assert.Len(t, st.ch.events, 0)
+	e.Liquidate, e.Destination = true, "Sim"
+	st.proxyEvent(e)
+	st.shutDown()
+	assert.Len(t, us.calls, 2)
+	ord := (<-st.ch.events).(*NewOrderEvent)
+	assert.Equal(t, MarketOrder, ord.LinkedOrder.Type)
+	assert.Equal(t, OrderSell, ord.LinkedOrder.Side)
+	assert.Equal(t, int64(400), ord.LinkedOrder.Qty)
+}
@@ -3,9 +3,12 @@ package engine
 import (
 	"alex/marketdata"
 	"bufio"
+	"compress/gzip"
+	"context"
 	"fmt"
 	"github.com/pkg/errors"
 	"hash/fnv"
+	"io"
 	"os"
 	"path"
 	"sort"
@@ -39,7 +42,15 @@ type BTM struct {
 	FromDate         time.Time
 	ToDate           time.Time
 	UsePrepairedData bool
+	//CompressPrepairedData makes BTM write prepaired data as gzip file and read it back transparently
+	CompressPrepairedData bool
+	//PrepareWorkers is number of goroutines loading ticks during prepare. Zero means number of CPUs.
+	//Storage should be safe for concurrent use if it is not 1.
+	PrepareWorkers   int
 	candlesTimeFrame string
+	//Segments are named parts of the period, e.g. in-sample and out-of-sample ones. Engine reports and
+	//optimizer results have metrics of every segment.
+	Segments []DateSegment
 
 	errChan          chan error
 	mdChan           chan event
@@ -47,18 +58,166 @@ type BTM struct {
 	histDataTimeBack time.Duration
 	waitGroup        *sync.WaitGroup
 	mode             MarketDataMode
+	gapDetector      dataGapDetector
+	universe         *Universe
+	universeChanges  eventArray
+	futuresChains    map[string]*FuturesChain
+	warmUpPeriods    map[string]int
+	sideEvents       eventArray
+	replay           *replayThrottle
+	progress         progressReporter
+	ctx              context.Context
+	logger           ILogger
+	auction          *OpeningAuction
+	delistings       map[string]Delisting
+	delistedFrom     map[string]time.Time
+	streaming        *StreamingConfig
+	spill            *sideEventsSpill
+	//skip is number of events which are not sent, because run is resumed from checkpoint made after them
+	skip int64
+}
+
+//dataGapDetector keeps time of last seen data for every symbol and checks if new data comes after the gap
+//larger than threshold. Zero threshold disables detection.
+type dataGapDetector struct {
+	threshold time.Duration
+	lastSeen  map[string]time.Time
+}
+
+//check returns DataGapEvent if time between last seen data of the symbol and given time is larger than
+//threshold. For exchanges with trading calendar only session time is counted, so nights, weekends and
+//holidays are not gaps. Otherwise it returns nil
+func (d *dataGapDetector) check(ticker *Instrument, t time.Time) *DataGapEvent {
+	if d.threshold <= 0 || ticker == nil {
+		return nil
+	}
+	last, ok := d.lastSeen[ticker.Symbol]
+	if !ok {
+		return nil
+	}
+	gap := t.Sub(last)
+	if ticker.Exchange.Calendar != nil {
+		gap = ticker.Exchange.sessionDuration(last, t)
+	}
+	if gap <= d.threshold {
+		return nil
+	}
+	return &DataGapEvent{
+		BaseEvent: be(t, ticker),
+		From:      last,
+		To:        t,
+	}
+}
+
+func (d *dataGapDetector) seen(ticker *Instrument, t time.Time) {
+	if ticker == nil {
+		return
+	}
+	if d.lastSeen == nil {
+		d.lastSeen = make(map[string]time.Time)
+	}
+	if last, ok := d.lastSeen[ticker.Symbol]; ok && last.After(t) {
+		return
+	}
+	d.lastSeen[ticker.Symbol] = t
+}
+
+//SetDataGapThreshold sets max allowed time between two consecutive ticks or candles of the symbol.
+//Larger gaps produce DataGapEvent before the first data after the gap. Zero duration disables detection.
+func (m *BTM) SetDataGapThreshold(d time.Duration) {
+	m.gapDetector.threshold = d
+}
+
+//checkDataGap emits DataGapEvent if data for the symbol comes after the gap and marks time as seen
+func (m *BTM) checkDataGap(ticker *Instrument, dataTime time.Time, seenTime time.Time) {
+	if gap := m.gapDetector.check(ticker, dataTime); gap != nil {
+		m.newEvent(gap)
+	}
+	m.gapDetector.seen(ticker, seenTime)
 }
 
 func (m *BTM) ShutDown() {
-	m.waitGroup.Wait()
+	waitContext(m.ctx, m.waitGroup)
+}
+
+//SetContext makes BTM stop producing events when context is done
+func (m *BTM) SetContext(ctx context.Context) {
+	m.ctx = ctx
+}
+
+func (m *BTM) isCanceled() bool {
+	return m.ctx != nil && m.ctx.Err() != nil
+}
+
+//send passes event to engine unless run was canceled or event is skipped
+func (m *BTM) send(e event) {
+	if m.skip > 0 {
+		m.skip--
+		return
+	}
+	sendEvent(m.ctx, m.mdChan, e)
 }
 
 func (m *BTM) SetSymbols(symbols []*Instrument) {
 	m.Symbols = symbols
+	if m.universe != nil {
+		m.addUniverseSymbols()
+	}
+	m.addFuturesSymbols()
+}
+
+//SetUniverse makes BTM load data for all symbols which were ever in universe and pass through only data
+//of the symbols which are universe members at the moment. SymbolAddedEvent and SymbolRemovedEvent are
+//produced when universe changes. Data of symbols set by Symbols is skipped too while they aren't universe
+//members, so such symbols should be added to universe. Engine doesn't run universe with symbols without
+//strategy unless strategy factory is set.
+func (m *BTM) SetUniverse(u *Universe) {
+	m.universe = u
+	m.addUniverseSymbols()
+}
+
+func (m *BTM) getUniverse() *Universe {
+	return m.universe
+}
+
+func (m *BTM) addUniverseSymbols() {
+	m.addSymbols(m.universe.Instruments())
+}
+
+//addSymbols appends instruments which are not in Symbols yet
+func (m *BTM) addSymbols(instruments []*Instrument) {
+	listed := make(map[string]struct{})
+	for _, s := range m.Symbols {
+		listed[s.Symbol] = struct{}{}
+	}
+	for _, inst := range instruments {
+		if _, ok := listed[inst.Symbol]; ok {
+			continue
+		}
+		listed[inst.Symbol] = struct{}{}
+		m.Symbols = append(m.Symbols, inst)
+	}
+}
+
+//emitUniverseChanges sends all universe changes which happened not after given time
+func (m *BTM) emitUniverseChanges(t time.Time) {
+	for len(m.universeChanges) > 0 && !m.universeChanges[0].getTime().After(t) {
+		m.send(m.universeChanges[0])
+		m.universeChanges = m.universeChanges[1:]
+	}
 }
 
 func (m *BTM) Connect() {
-	fmt.Println("Backtest market data connected. ")
+	if m.logger == nil {
+		fmt.Println("Backtest market data connected. ")
+		return
+	}
+	m.logger.Log(InfoLevel, "Backtest market data connected. ", nil)
+}
+
+//SetLogger sets logger of market data messages and errors. Without logger messages are printed to stdout.
+func (m *BTM) SetLogger(l ILogger) {
+	m.logger = l
 }
 
 func (m *BTM) Init(errChan chan error, mdChan chan event) {
@@ -106,7 +265,11 @@ func (m *BTM) getFilename() (string, error) {
 	if err != nil {
 		panic(err)
 	}
-	return strconv.FormatUint(uint64(h.Sum32()), 10) + ".prep", nil
+	filename := strconv.FormatUint(uint64(h.Sum32()), 10) + ".prep"
+	if m.CompressPrepairedData {
+		filename += ".gz"
+	}
+	return filename, nil
 }
 
 func (m *BTM) getPrepairedFilePath() string {
@@ -127,10 +290,12 @@ func (m *BTM) prepare() {
 
 	if m.mode == MarketDataModeTicksQuotes || m.mode == MarketDataModeTicks || m.mode == MarketDataModeQuotes {
 		m.prepareTicks()
+		m.savePrepairedMeta()
 		return
 	}
 	if m.mode == MarketDataModeCandles {
 		m.prepareCandles()
+		m.savePrepairedMeta()
 		return
 	}
 
@@ -143,15 +308,15 @@ func (m *BTM) prepareCandles() {
 		From: m.FromDate,
 		To:   m.ToDate,
 	}
+	if m.streaming != nil {
+		if m.prepareCandlesStreaming(rng) == 0 {
+			panic("No candles were loaded")
+		}
+		return
+	}
 	var totalcandles marketdata.CandleArray
 	for _, s := range m.Symbols {
-		sc, err := m.Storage.GetStoredCandles(s.Symbol, m.candlesTimeFrame, rng)
-		if err != nil {
-			m.newError(err)
-		}
-		if sc != nil {
-			totalcandles = append(totalcandles, sc...)
-		}
+		totalcandles = append(totalcandles, m.symbolCandles(s, rng)...)
 	}
 
 	if len(totalcandles) == 0 {
@@ -163,15 +328,23 @@ func (m *BTM) prepareCandles() {
 
 }
 
-func (m *BTM) prepareTicks() {
-	d := m.FromDate
-	for {
-		m.loadDateTicks(d)
-		d = d.AddDate(0, 0, 1)
-		if d.After(m.ToDate) {
-			break
-		}
+//symbolCandles loads normalized candles of the symbol. Futures roots have no candles of their own.
+func (m *BTM) symbolCandles(s *Instrument, rng marketdata.DateRange) marketdata.CandleArray {
+	if m.isFuturesRoot(s.Symbol) {
+		return nil
+	}
+	sc, err := m.Storage.GetStoredCandles(s.Symbol, m.candlesTimeFrame, rng)
+	if err != nil {
+		m.newError(err)
 	}
+	if sc != nil {
+		s.normalizeCandles(sc)
+	}
+	return sc
+}
+
+func (m *BTM) prepareTicks() {
+	m.prepareTicksParallel()
 }
 
 func (m *BTM) clearPrepairedData() error {
@@ -180,12 +353,32 @@ func (m *BTM) clearPrepairedData() error {
 	}
 
 	err := os.Remove(m.getPrepairedFilePath())
-	return err
+	if err != nil {
+		return err
+	}
+	err = os.Remove(m.getPrepairedFilePath() + prepairedMetaExt)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
 }
 
 func (m *BTM) loadDateTicks(date time.Time) {
+	m.writeDateTicks(m.dateTicks(date))
+}
+
+//dateTicks loads time sorted ticks of all symbols for the date
+func (m *BTM) dateTicks(date time.Time) marketdata.TickArray {
+	return m.symbolsTicks(date, m.Symbols)
+}
+
+//symbolsTicks loads time sorted ticks of the symbols for the date
+func (m *BTM) symbolsTicks(date time.Time, symbols []*Instrument) marketdata.TickArray {
 	totalTicks := marketdata.TickArray{}
-	for _, symbol := range m.Symbols {
+	for _, symbol := range symbols {
+		if m.isFuturesRoot(symbol.Symbol) || !symbol.Exchange.IsTradingDay(date) {
+			continue
+		}
 		rng := marketdata.DateRange{
 			From: time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC),
 			To:   time.Date(date.Year(), date.Month(), date.Day(), 23, 59, 59, 59, time.UTC),
@@ -204,11 +397,12 @@ func (m *BTM) loadDateTicks(date time.Time) {
 			continue
 		}
 
+		symbol.normalizeTicks(symbolTicks)
 		totalTicks = append(totalTicks, symbolTicks...)
 
 	}
 	totalTicks.Sort()
-	m.writeDateTicks(totalTicks)
+	return totalTicks
 }
 
 func (m *BTM) writeDateTicks(ticks marketdata.TickArray) {
@@ -216,7 +410,7 @@ func (m *BTM) writeDateTicks(ticks marketdata.TickArray) {
 		return
 	}
 
-	f, err := os.OpenFile(m.getPrepairedFilePath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	f, err := m.openPrepairedDataForAppend()
 
 	if err != nil {
 		panic(err)
@@ -229,14 +423,7 @@ func (m *BTM) writeDateTicks(ticks marketdata.TickArray) {
 		}
 	}()
 
-	for _, t := range ticks {
-		if !t.HasTrade() {
-			continue
-		}
-		if _, err := f.Write([]byte(t.String() + "\n")); err != nil {
-			m.newError(err)
-		}
-	}
+	m.writeTicks(f, ticks)
 }
 
 func (m *BTM) writeCandles(candles marketdata.CandleArray) {
@@ -244,7 +431,7 @@ func (m *BTM) writeCandles(candles marketdata.CandleArray) {
 		return
 	}
 
-	f, err := os.OpenFile(m.getPrepairedFilePath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	f, err := m.openPrepairedDataForAppend()
 
 	if err != nil {
 		panic(err)
@@ -265,10 +452,76 @@ func (m *BTM) writeCandles(candles marketdata.CandleArray) {
 
 }
 
+//prepairedDataWriter closes both compression writer and underlying file
+type prepairedDataWriter struct {
+	io.Writer
+	closers []io.Closer
+}
+
+func (w *prepairedDataWriter) Close() error {
+	var firstErr error
+	for _, c := range w.closers {
+		if err := c.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+//openPrepairedDataForAppend opens prepaired data file for writing in append mode. Every opened compressed
+//writer adds new gzip member to the file, gzip reader reads them as a single stream.
+func (m *BTM) openPrepairedDataForAppend() (io.WriteCloser, error) {
+	f, err := os.OpenFile(m.getPrepairedFilePath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if !m.CompressPrepairedData {
+		return f, nil
+	}
+	gz := gzip.NewWriter(f)
+	return &prepairedDataWriter{Writer: gz, closers: []io.Closer{gz, f}}, nil
+}
+
+//prepairedDataReader closes both decompression reader and underlying file
+type prepairedDataReader struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (r *prepairedDataReader) Close() error {
+	var firstErr error
+	for _, c := range r.closers {
+		if err := c.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+//openPrepairedData opens prepaired data file for reading. Compressed files are decompressed on the fly
+func (m *BTM) openPrepairedData() (io.ReadCloser, error) {
+	f, err := os.Open(m.getPrepairedFilePath())
+	if err != nil {
+		return nil, err
+	}
+	if !m.CompressPrepairedData {
+		return f, nil
+	}
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &prepairedDataReader{Reader: gz, closers: []io.Closer{gz, f}}, nil
+}
+
 func (m *BTM) newError(err error) {
+	if m.logger != nil {
+		m.logger.Log(ErrorLevel, err.Error(), errorFields(err))
+	}
 	m.waitGroup.Add(1)
 	go func() {
-		m.errChan <- err
+		sendError(m.ctx, m.errChan, err)
 		m.waitGroup.Done()
 	}()
 
@@ -278,7 +531,89 @@ func (m *BTM) newEvent(e event) {
 	if m.mdChan == nil {
 		panic("BTM event chan is nil")
 	}
-	m.mdChan <- e
+	if _, ok := e.(*EndOfDataEvent); ok {
+		m.emitSideEvents(m.ToDate.AddDate(0, 0, 1))
+		if m.progress.interval > 0 {
+			m.send(m.progress.progress(m.ToDate.AddDate(0, 0, 1)))
+		}
+		m.send(e)
+		return
+	}
+	if chain, ok := m.futuresChains[e.getSymbol()]; ok {
+		var roll *RollEvent
+		e, roll = continuousEvent(chain, e)
+		if roll != nil {
+			m.send(roll)
+		}
+		if e == nil {
+			return
+		}
+	}
+	if m.universe != nil {
+		m.emitUniverseChanges(e.getTime())
+		if !m.universe.IsMember(e.getSymbol(), e.getTime()) {
+			return
+		}
+	}
+	if m.isDelisted(e) {
+		return
+	}
+	if m.replay != nil {
+		m.replay.wait(e.getTime())
+	}
+	m.emitSideEvents(e.getTime())
+	m.send(e)
+	if p := m.progress.count(e.getTime()); p != nil {
+		m.send(p)
+	}
+}
+
+//loadSideEvents loads time sorted events of storages which have data other than ticks and candles. In
+//streaming mode events of every storage are spilled to disk as soon as they are loaded.
+func (m *BTM) loadSideEvents() eventArray {
+	loaders := []func() eventArray{m.loadOptionChains, m.loadFundamentals, m.loadNews, m.loadDividends,
+		m.loadAuctions, m.loadDelistings}
+	var side eventArray
+	for _, load := range loaders {
+		events := load()
+		if m.spill != nil && len(events) > 0 {
+			events.sort()
+			err := m.spill.add(m.tempFile(".side"), events)
+			if err == nil {
+				continue
+			}
+			m.newError(err)
+		}
+		side = append(side, events...)
+	}
+	side.sort()
+	return side
+}
+
+//emitSideEvents sends all side events which happened not after given time. Spilled events are merged with
+//events kept in memory.
+func (m *BTM) emitSideEvents(t time.Time) {
+	for {
+		var next event
+		if len(m.sideEvents) > 0 {
+			next = m.sideEvents[0]
+		}
+		spilled := m.spill.peek()
+		if spilled != nil && (next == nil || eventBefore(spilled, next)) {
+			next = spilled
+		}
+		if next == nil || next.getTime().After(t) {
+			return
+		}
+		if next == spilled {
+			if err := m.spill.pop(); err != nil {
+				m.newError(err)
+			}
+		} else {
+			m.sideEvents = m.sideEvents[1:]
+		}
+		m.send(next)
+	}
 }
 
 func (m *BTM) prepairedDataExists() bool {
@@ -296,23 +631,29 @@ func (m *BTM) prepairedDataExists() bool {
 }
 
 func (m *BTM) Run() {
-	if !m.prepairedDataExists() {
-		m.prepare()
+	if !m.prepairedDataIsActual() {
+		m.prepareIncrementally()
+	}
+	m.gapDetector.lastSeen = nil
+	m.resetFuturesChains()
+	m.spill.close()
+	m.spill = nil
+	if m.streaming != nil {
+		m.spill = &sideEventsSpill{symbols: m.getTickersMap()}
+	}
+	m.sideEvents = m.loadSideEvents()
+	m.progress.start(m.FromDate, m.ToDate.AddDate(0, 0, 1))
+	if m.replay != nil {
+		m.replay.reset()
+	}
+	if m.universe != nil {
+		m.universeChanges = m.universe.changes()
 	}
 	if m.mode == MarketDataModeQuotes || m.mode == MarketDataModeTicks || m.mode == MarketDataModeTicksQuotes {
 		if m.histDataTimeBack > time.Second {
-			m.waitGroup.Add(1)
-			go func() {
-				m.genTickEventsWithHistory()
-				m.waitGroup.Done()
-			}()
-
+			m.produce(m.genTickEventsWithHistory)
 		} else {
-			m.waitGroup.Add(1)
-			go func() {
-				m.genTickEvents()
-				m.waitGroup.Done()
-			}()
+			m.produce(m.genTickEvents)
 		}
 
 		return
@@ -320,18 +661,9 @@ func (m *BTM) Run() {
 
 	if m.mode == MarketDataModeCandles {
 		if m.histDataTimeBack > time.Minute {
-			m.waitGroup.Add(1)
-			go func() {
-				m.genCandlesEventsWithHistory()
-				m.waitGroup.Done()
-			}()
-
+			m.produce(m.genCandlesEventsWithHistory)
 		} else {
-			m.waitGroup.Add(1)
-			go func() {
-				m.genCandlesEvents()
-				m.waitGroup.Done()
-			}()
+			m.produce(m.genCandlesEvents)
 		}
 
 		return
@@ -341,6 +673,19 @@ func (m *BTM) Run() {
 
 }
 
+//produce sends warm up and events of generator from goroutine of the run. Spilled side events are removed when
+//goroutine returns, also when run is canceled in the middle of data.
+func (m *BTM) produce(gen func()) {
+	spill := m.spill
+	m.waitGroup.Add(1)
+	go func() {
+		defer m.waitGroup.Done()
+		defer spill.close()
+		m.emitWarmUp()
+		gen()
+	}()
+}
+
 func (m *BTM) getTickersMap() map[string]*Instrument {
 	tickersMap := make(map[string]*Instrument)
 
@@ -356,7 +701,7 @@ func (m *BTM) genTickEvents() {
 		panic("Can't genereate tick events. Prepaired data is not exists. ")
 	}
 
-	file, err := os.Open(m.getPrepairedFilePath())
+	file, err := m.openPrepairedData()
 	if err != nil {
 		panic(err)
 	}
@@ -370,7 +715,7 @@ func (m *BTM) genTickEvents() {
 	scanner := bufio.NewScanner(file)
 	tickersMap := m.getTickersMap()
 
-	for scanner.Scan() {
+	for !m.isCanceled() && scanner.Scan() {
 		tickRaw, err := m.parseLineToTick(scanner.Text())
 		if err != nil {
 			panic(err)
@@ -384,9 +729,10 @@ func (m *BTM) genTickEvents() {
 
 		e := NewTickEvent{
 			Tick:      &tick,
-			BaseEvent: BaseEvent{Time: tick.Datetime, Ticker: ticker},
+			BaseEvent: be(tick.Datetime, ticker),
 		}
 
+		m.checkDataGap(ticker, tick.Datetime, tick.Datetime)
 		m.newEvent(&e)
 
 	}
@@ -399,7 +745,7 @@ func (m *BTM) genTickEventsWithHistory() {
 		panic("Can't genereate tick events. Prepaired data is not exists. ")
 	}
 
-	file, err := os.Open(m.getPrepairedFilePath())
+	file, err := m.openPrepairedData()
 	if err != nil {
 		panic(err)
 	}
@@ -416,7 +762,7 @@ func (m *BTM) genTickEventsWithHistory() {
 	scanner := bufio.NewScanner(file)
 	tickersMap := m.getTickersMap()
 
-	for scanner.Scan() {
+	for !m.isCanceled() && scanner.Scan() {
 		tickRaw, err := m.parseLineToTick(scanner.Text())
 		if err != nil {
 			panic(err)
@@ -431,8 +777,9 @@ func (m *BTM) genTickEventsWithHistory() {
 		if _, ok := historyLoaded[tick.Symbol]; ok {
 			e := NewTickEvent{
 				Tick:      &tick,
-				BaseEvent: BaseEvent{Time: tick.Datetime, Ticker: ticker},
+				BaseEvent: be(tick.Datetime, ticker),
 			}
+			m.checkDataGap(ticker, tick.Datetime, tick.Datetime)
 			m.newEvent(&e)
 			continue
 		}
@@ -452,12 +799,14 @@ func (m *BTM) genTickEventsWithHistory() {
 					Ticks:     historyMap[tick.Symbol],
 				}
 				m.newEvent(&historyEvent)
+				m.gapDetector.seen(ticker, arr[len(arr)-1].Datetime)
 
 				//First put out new tick event
 				e := NewTickEvent{
 					Tick:      &tick,
-					BaseEvent: BaseEvent{Time: tick.Datetime, Ticker: ticker},
+					BaseEvent: be(tick.Datetime, ticker),
 				}
+				m.checkDataGap(ticker, tick.Datetime, tick.Datetime)
 				m.newEvent(&e)
 
 			}
@@ -476,7 +825,7 @@ func (m *BTM) genCandlesEvents() {
 		panic("Can't genereate tick events. Prepaired data is not exists. ")
 	}
 
-	file, err := os.Open(m.getPrepairedFilePath())
+	file, err := m.openPrepairedData()
 	if err != nil {
 		panic(err)
 	}
@@ -491,7 +840,7 @@ func (m *BTM) genCandlesEvents() {
 	var candleCloses []*CandleCloseEvent
 	tickersMap := m.getTickersMap()
 
-	for scanner.Scan() {
+	for !m.isCanceled() && scanner.Scan() {
 		cRaw, err := m.parseLineToCandle(scanner.Text())
 		if err != nil {
 			panic(err)
@@ -513,6 +862,7 @@ func (m *BTM) genCandlesEvents() {
 			candleCloses = []*CandleCloseEvent{}
 		}
 
+		m.checkDataGap(ticker, cRaw.Datetime, cRaw.Datetime)
 		m.newEvent(&e)
 
 		c := Candle{
@@ -526,6 +876,7 @@ func (m *BTM) genCandlesEvents() {
 			TimeFrame: m.candlesTimeFrame,
 		}
 		ce.setEventTimeFromCandle()
+		m.gapDetector.seen(ticker, ce.getTime())
 		candleCloses = append(candleCloses, &ce)
 
 	}
@@ -544,7 +895,7 @@ func (m *BTM) genCandlesEventsWithHistory() {
 		panic("Can't genereate tick events. Prepaired data is not exists. ")
 	}
 
-	file, err := os.Open(m.getPrepairedFilePath())
+	file, err := m.openPrepairedData()
 	if err != nil {
 		panic(err)
 	}
@@ -563,7 +914,7 @@ func (m *BTM) genCandlesEventsWithHistory() {
 	var candleCloses []*CandleCloseEvent
 	tickersMap := m.getTickersMap()
 
-	for scanner.Scan() {
+	for !m.isCanceled() && scanner.Scan() {
 		cRaw, err := m.parseLineToCandle(scanner.Text())
 		if err != nil {
 			panic(err)
@@ -592,6 +943,7 @@ func (m *BTM) genCandlesEventsWithHistory() {
 				candleCloses = []*CandleCloseEvent{}
 			}
 
+			m.checkDataGap(ticker, cRaw.Datetime, cRaw.Datetime)
 			m.newEvent(&e)
 
 			ce := CandleCloseEvent{
@@ -600,6 +952,7 @@ func (m *BTM) genCandlesEventsWithHistory() {
 				TimeFrame: m.candlesTimeFrame,
 			}
 			ce.setEventTimeFromCandle()
+			m.gapDetector.seen(ticker, ce.getTime())
 			candleCloses = append(candleCloses, &ce)
 			continue
 		}
@@ -618,6 +971,9 @@ func (m *BTM) genCandlesEventsWithHistory() {
 					Candles:   historyMap[cRaw.Symbol],
 				}
 				m.newEvent(&historyEvent)
+				lastHist := CandleCloseEvent{Candle: arr[len(arr)-1], TimeFrame: m.candlesTimeFrame}
+				lastHist.setEventTimeFromCandle()
+				m.gapDetector.seen(ticker, lastHist.getTime())
 
 				//First put out new tick event
 				e := CandleOpenEvent{
@@ -634,6 +990,7 @@ func (m *BTM) genCandlesEventsWithHistory() {
 					candleCloses = []*CandleCloseEvent{}
 				}
 
+				m.checkDataGap(ticker, cRaw.Datetime, cRaw.Datetime)
 				m.newEvent(&e)
 
 				ce := CandleCloseEvent{
@@ -642,6 +999,7 @@ func (m *BTM) genCandlesEventsWithHistory() {
 					TimeFrame: m.candlesTimeFrame,
 				}
 				ce.setEventTimeFromCandle()
+				m.gapDetector.seen(ticker, ce.getTime())
 				candleCloses = append(candleCloses, &ce)
 
 			}
@@ -701,7 +1059,7 @@ func (m *BTM) parseLineToTick(l string) (*marketdata.Tick, error) {
 	if err != nil {
 		return nil, err
 	}
-	tm := time.Unix(i, 0)
+	tm := time.Unix(i, 0).UTC()
 
 	tick := marketdata.Tick{
 		Datetime:  tm,
@@ -735,7 +1093,7 @@ func (m *BTM) parseLineToCandle(l string) (*marketdata.Candle, error) {
 	if err != nil {
 		return nil, err
 	}
-	tm := time.Unix(i, 0)
+	tm := time.Unix(i, 0).UTC()
 
 	symbol := ls[1]
 
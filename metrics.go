@@ -0,0 +1,303 @@
+package engine
+
+import (
+	"expvar"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+//IMetrics receives runtime metrics of the engine. Engine reports:
+//
+//	events.<EventType>  count of market data, strategy and broker events processed by engine
+//	fills               count of fills
+//	orders.outstanding  number of orders sent to broker and not filled, canceled or rejected yet
+//	chan.events         depth of events channel of strategies and broker
+//	chan.portfolio      depth of portfolio updates channel
+//	queue.<Symbol>      depth of strategy queue, queue.<Symbol>.<Name> for strategies of groups
+//	handler.<Callback>  latency of callbacks of user strategies: OnTick, OnCandleOpen, OnCandleClose,
+//	                    OnTimeFrameCandleClose
+//
+//Channel and queue depths are sampled when market data moves time forward. Methods are called from
+//different goroutines.
+type IMetrics interface {
+	Count(name string, delta int64)
+	Gauge(name string, value float64)
+	Timing(name string, d time.Duration)
+}
+
+//ExpvarMetrics publishes metrics as expvar map, so they are served by /debug/vars of http server.
+//Timings are published as <name>.count and <name>.ns with total duration in nanoseconds.
+type ExpvarMetrics struct {
+	vars *expvar.Map
+	mut  sync.Mutex
+}
+
+//expvarMut makes check and publishing of expvar map one step
+var expvarMut sync.Mutex
+
+//NewExpvarMetrics publishes map with given name. Map which is already published with the name is reused,
+//so several engines of one process can share it. Error is returned if the name is used by other variable.
+func NewExpvarMetrics(name string) (*ExpvarMetrics, error) {
+	expvarMut.Lock()
+	defer expvarMut.Unlock()
+	switch v := expvar.Get(name).(type) {
+	case nil:
+		return &ExpvarMetrics{vars: expvar.NewMap(name)}, nil
+	case *expvar.Map:
+		return &ExpvarMetrics{vars: v}, nil
+	}
+	return nil, fmt.Errorf("Expvar %v is already published and it isn't a map. ", name)
+}
+
+func (m *ExpvarMetrics) Count(name string, delta int64) {
+	m.vars.Add(name, delta)
+}
+
+func (m *ExpvarMetrics) Gauge(name string, value float64) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	if f, ok := m.vars.Get(name).(*expvar.Float); ok {
+		f.Set(value)
+		return
+	}
+	f := new(expvar.Float)
+	f.Set(value)
+	m.vars.Set(name, f)
+}
+
+func (m *ExpvarMetrics) Timing(name string, d time.Duration) {
+	m.vars.Add(name+".count", 1)
+	m.vars.Add(name+".ns", int64(d))
+}
+
+//Vars returns published map
+func (m *ExpvarMetrics) Vars() *expvar.Map {
+	return m.vars
+}
+
+//TimingStats is summary of measured durations
+type TimingStats struct {
+	Count int64
+	Total time.Duration
+	Max   time.Duration
+}
+
+//Mean returns average duration
+func (s TimingStats) Mean() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Count)
+}
+
+//MetricsSnapshot is state of metrics at some moment
+type MetricsSnapshot struct {
+	Counters map[string]int64
+	Gauges   map[string]float64
+	Timings  map[string]TimingStats
+}
+
+//Names returns sorted names of all metrics of the snapshot
+func (s MetricsSnapshot) Names() []string {
+	var names []string
+	for n := range s.Counters {
+		names = append(names, n)
+	}
+	for n := range s.Gauges {
+		names = append(names, n)
+	}
+	for n := range s.Timings {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//MemoryMetrics keeps metrics in memory, e.g. to print profile of backtest after the run
+type MemoryMetrics struct {
+	mut      sync.Mutex
+	counters map[string]int64
+	gauges   map[string]float64
+	timings  map[string]TimingStats
+}
+
+func NewMemoryMetrics() *MemoryMetrics {
+	return &MemoryMetrics{
+		counters: make(map[string]int64),
+		gauges:   make(map[string]float64),
+		timings:  make(map[string]TimingStats),
+	}
+}
+
+func (m *MemoryMetrics) Count(name string, delta int64) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	m.counters[name] += delta
+}
+
+func (m *MemoryMetrics) Gauge(name string, value float64) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	m.gauges[name] = value
+}
+
+func (m *MemoryMetrics) Timing(name string, d time.Duration) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	s := m.timings[name]
+	s.Count++
+	s.Total += d
+	if d > s.Max {
+		s.Max = d
+	}
+	m.timings[name] = s
+}
+
+//Snapshot returns copy of current metrics
+func (m *MemoryMetrics) Snapshot() MetricsSnapshot {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	s := MetricsSnapshot{
+		Counters: make(map[string]int64, len(m.counters)),
+		Gauges:   make(map[string]float64, len(m.gauges)),
+		Timings:  make(map[string]TimingStats, len(m.timings)),
+	}
+	for k, v := range m.counters {
+		s.Counters[k] = v
+	}
+	for k, v := range m.gauges {
+		s.Gauges[k] = v
+	}
+	for k, v := range m.timings {
+		s.Timings[k] = v
+	}
+	return s
+}
+
+//metricsState is metrics receiver of the engine and outstanding orders by ID
+type metricsState struct {
+	mut    sync.Mutex
+	m      IMetrics
+	orders map[string]*outstandingOrder
+}
+
+type outstandingOrder struct {
+	qty     int64
+	execQty int64
+}
+
+//metricsStrategy is implemented by strategies which measure their handlers
+type metricsStrategy interface {
+	setMetrics(m IMetrics)
+}
+
+//SetMetrics sets receiver of runtime metrics of engine and strategies. Nil turns metrics off.
+func (c *Engine) SetMetrics(m IMetrics) {
+	c.metrics.mut.Lock()
+	c.metrics.m = m
+	c.metrics.orders = make(map[string]*outstandingOrder)
+	c.metrics.mut.Unlock()
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	for _, st := range c.strategiesMap {
+		if ms, ok := st.(metricsStrategy); ok {
+			ms.setMetrics(m)
+		}
+	}
+}
+
+func (c *Engine) getMetrics() IMetrics {
+	c.metrics.mut.Lock()
+	defer c.metrics.mut.Unlock()
+	return c.metrics.m
+}
+
+//countEvent counts event processed by engine and updates outstanding orders
+func (c *Engine) countEvent(e event) {
+	m := c.getMetrics()
+	if m == nil {
+		return
+	}
+	m.Count("events."+eventTypeName(e), 1)
+	if _, ok := e.(*OrderFillEvent); ok {
+		m.Count("fills", 1)
+	}
+	c.trackOrder(e)
+}
+
+//trackOrder updates orders sent to broker by their events. Replaced quantity is total quantity of the order.
+func (c *Engine) trackOrder(e event) {
+	c.metrics.mut.Lock()
+	if c.metrics.m == nil {
+		c.metrics.mut.Unlock()
+		return
+	}
+	orders := c.metrics.orders
+	switch i := e.(type) {
+	case *NewOrderEvent:
+		if i.LinkedOrder != nil {
+			orders[i.LinkedOrder.Id] = &outstandingOrder{qty: i.LinkedOrder.Qty}
+		}
+	case *OrderReplacedEvent:
+		if o, ok := orders[i.OrdId]; ok && i.NewQty > 0 {
+			o.qty = i.NewQty
+		}
+	case *OrderFillEvent:
+		if o, ok := orders[i.OrdId]; ok {
+			o.execQty += i.Qty
+			if o.execQty >= o.qty {
+				delete(orders, i.OrdId)
+			}
+		}
+	case *OrderCancelEvent:
+		delete(orders, i.OrdId)
+	case *OrderRejectedEvent:
+		delete(orders, i.OrdId)
+	case *StrategyRequestNotDeliveredEvent:
+		if _, ok := i.Request.(*NewOrderEvent); ok {
+			delete(orders, requestOrderID(i.Request))
+		}
+	default:
+		c.metrics.mut.Unlock()
+		return
+	}
+	n, m := len(orders), c.metrics.m
+	c.metrics.mut.Unlock()
+	m.Gauge("orders.outstanding", float64(n))
+}
+
+//sampleDepths reports depths of engine channels and strategy queues
+func (c *Engine) sampleDepths() {
+	m := c.getMetrics()
+	if m == nil {
+		return
+	}
+	m.Gauge("chan.events", float64(len(c.events)))
+	m.Gauge("chan.portfolio", float64(len(c.portfolioChan)))
+	for _, s := range c.QueueStats() {
+		name := []string{"queue", s.Symbol}
+		if s.Strategy != "" {
+			name = append(name, s.Strategy)
+		}
+		m.Gauge(strings.Join(name, "."), float64(s.Depth))
+	}
+}
+
+func (b *BasicStrategy) setMetrics(m IMetrics) {
+	b.metrics = m
+}
+
+//observeHandler reports latency of user strategy callback started at start
+func (b *BasicStrategy) observeHandler(callback string, start time.Time) {
+	if b.metrics != nil {
+		b.metrics.Timing("handler."+callback, time.Since(start))
+	}
+}
+
+func (g *StrategyGroup) setMetrics(m IMetrics) {
+	g.forEach(func(st *BasicStrategy) { st.setMetrics(m) })
+}
@@ -0,0 +1,143 @@
+package engine
+
+import (
+	"expvar"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryMetrics(t *testing.T) {
+	m := NewMemoryMetrics()
+	m.Count("fills", 1)
+	m.Count("fills", 2)
+	m.Gauge("chan.events", 5)
+	m.Gauge("chan.events", 3)
+	m.Timing("handler.OnTick", time.Millisecond)
+	m.Timing("handler.OnTick", 3*time.Millisecond)
+
+	s := m.Snapshot()
+	assert.Equal(t, int64(3), s.Counters["fills"])
+	assert.Equal(t, float64(3), s.Gauges["chan.events"])
+	assert.Equal(t, TimingStats{Count: 2, Total: 4 * time.Millisecond, Max: 3 * time.Millisecond},
+		s.Timings["handler.OnTick"])
+	assert.Equal(t, 2*time.Millisecond, s.Timings["handler.OnTick"].Mean())
+	assert.Equal(t, []string{"chan.events", "fills", "handler.OnTick"}, s.Names())
+
+	//Snapshot is a copy
+	m.Count("fills", 1)
+	assert.Equal(t, int64(3), s.Counters["fills"])
+}
+
+func TestExpvarMetrics(t *testing.T) {
+	m, err := NewExpvarMetrics("engine_metrics_test")
+	assert.Nil(t, err)
+	m.Count("fills", 2)
+	m.Gauge("orders.outstanding", 4)
+	m.Gauge("orders.outstanding", 1)
+	m.Timing("handler.OnTick", time.Millisecond)
+
+	assert.Equal(t, "2", m.Vars().Get("fills").String())
+	assert.Equal(t, "1", m.Vars().Get("orders.outstanding").String())
+	assert.Equal(t, "1", m.Vars().Get("handler.OnTick.count").String())
+	assert.Equal(t, "1000000", m.Vars().Get("handler.OnTick.ns").String())
+
+	//Published map is shared
+	m2, err := NewExpvarMetrics("engine_metrics_test")
+	assert.Nil(t, err)
+	m2.Count("fills", 1)
+	assert.Equal(t, "3", expvar.Get("engine_metrics_test").(*expvar.Map).Get("fills").String())
+
+	t.Log("Name of other variable can't be used")
+	{
+		_, err := NewExpvarMetrics("memstats")
+		assert.NotNil(t, err)
+	}
+
+	t.Log("Concurrent callers with new name share one map")
+	{
+		wg := &sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				m, err := NewExpvarMetrics("engine_metrics_test_concurrent")
+				assert.Nil(t, err)
+				m.Count("fills", 1)
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, "10", expvar.Get("engine_metrics_test_concurrent").(*expvar.Map).Get("fills").String())
+	}
+}
+
+func TestEngine_countEvent(t *testing.T) {
+	st := newTestBasicStrategy()
+	c := Engine{strategiesMap: map[string]ICoreStrategy{st.symbol.Symbol: st}, mut: &sync.Mutex{}}
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	order := func(id string) *NewOrderEvent {
+		return &NewOrderEvent{BaseEvent: be(tm, st.symbol), LinkedOrder: &Order{Id: id, Qty: 100}}
+	}
+
+	//Nothing is counted without metrics
+	c.countEvent(order("1"))
+	m := NewMemoryMetrics()
+	c.SetMetrics(m)
+	assert.Equal(t, m, st.metrics)
+
+	c.countEvent(order("1"))
+	c.countEvent(order("2"))
+	c.countEvent(order("3"))
+	c.countEvent(order("4"))
+	assert.Equal(t, float64(4), m.Snapshot().Gauges["orders.outstanding"])
+
+	c.countEvent(&OrderFillEvent{BaseEvent: be(tm, st.symbol), OrdId: "1", Qty: 40})
+	assert.Equal(t, float64(4), m.Snapshot().Gauges["orders.outstanding"])
+	c.countEvent(&OrderReplacedEvent{BaseEvent: be(tm, st.symbol), OrdId: "1", NewQty: 50})
+	c.countEvent(&OrderFillEvent{BaseEvent: be(tm, st.symbol), OrdId: "1", Qty: 10})
+	c.countEvent(&OrderCancelEvent{BaseEvent: be(tm, st.symbol), OrdId: "2"})
+	c.countEvent(&OrderRejectedEvent{BaseEvent: be(tm, st.symbol), OrdId: "3"})
+	c.trackOrder(&StrategyRequestNotDeliveredEvent{BaseEvent: be(tm, st.symbol), Request: order("4")})
+	c.countEvent(newTestCandleCloseEvent(10, 20, 9, 10, tm, "1"))
+
+	s := m.Snapshot()
+	assert.Equal(t, float64(0), s.Gauges["orders.outstanding"])
+	assert.Equal(t, int64(2), s.Counters["fills"])
+	assert.Equal(t, int64(4), s.Counters["events.NewOrderEvent"])
+	assert.Equal(t, int64(2), s.Counters["events.OrderFillEvent"])
+	assert.Equal(t, int64(1), s.Counters["events.CandleCloseEvent"])
+}
+
+func TestEngine_sampleDepths(t *testing.T) {
+	st := newTestBasicStrategy()
+	st.setBackpressure(&BackpressureConfig{Capacity: 10, Policy: BackpressureDrop})
+	c := Engine{strategiesMap: map[string]ICoreStrategy{st.symbol.Symbol: st}, mut: &sync.Mutex{},
+		events: make(chan event, 10), portfolioChan: make(chan *PortfolioNewPositionEvent, 10)}
+	m := NewMemoryMetrics()
+	c.SetMetrics(m)
+	c.events <- newTestCandleCloseEvent(10, 20, 9, 10, time.Now(), "1")
+	c.sampleDepths()
+
+	s := m.Snapshot()
+	assert.Equal(t, float64(1), s.Gauges["chan.events"])
+	assert.Equal(t, float64(0), s.Gauges["chan.portfolio"])
+	_, ok := s.Gauges["queue.Test"]
+	assert.True(t, ok)
+}
+
+func TestBasicStrategy_observeHandler(t *testing.T) {
+	st := newTestBasicStrategy()
+	us := &synchronousTestStrategy{}
+	st.userStrategy = us
+	st.nPeriods = 1
+	st.setSynchronous(true)
+	m := NewMemoryMetrics()
+	st.setMetrics(m)
+
+	st.notify(newTestCandleCloseEvent(10, 20, 9, 10, time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC), "1"))
+	timing := m.Snapshot().Timings["handler.OnCandleClose"]
+	assert.Equal(t, int64(1), timing.Count)
+	assert.True(t, timing.Total >= 5*time.Millisecond)
+}
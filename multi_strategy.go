@@ -0,0 +1,138 @@
+package engine
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+//IMultiSymbolUserStrategy is user strategy which trades basket of symbols. It gets data of all symbols
+//of MultiSymbolStrategy, b is strategy of the symbol the data belongs to.
+type IMultiSymbolUserStrategy interface {
+	OnTick(m *MultiSymbolStrategy, b *BasicStrategy, tick *Tick)
+	OnCandleClose(m *MultiSymbolStrategy, b *BasicStrategy, candle *Candle)
+	OnCandleOpen(m *MultiSymbolStrategy, b *BasicStrategy, price float64)
+}
+
+//MultiSymbolStrategy runs one user strategy on several symbols, e.g. for pairs trading. Every symbol has
+//its own BasicStrategy which keeps its data buffers and trades, but all of them share one lock, so user
+//strategy callbacks never run concurrently and can read data and put orders of any symbol.
+type MultiSymbolStrategy struct {
+	userStrategy IMultiSymbolUserStrategy
+	legs         map[string]*BasicStrategy
+	symbols      []string
+	rebalancer   *Rebalancer
+}
+
+func NewMultiSymbolStrategy(symbols []*Instrument, nPeriods int, userStrategy IMultiSymbolUserStrategy) *MultiSymbolStrategy {
+	m := MultiSymbolStrategy{
+		userStrategy: userStrategy,
+		legs:         make(map[string]*BasicStrategy),
+	}
+	mut := &sync.Mutex{}
+	for _, inst := range symbols {
+		if _, ok := m.legs[inst.Symbol]; ok {
+			continue
+		}
+		leg := NewBasicStrategy(inst, nPeriods, &multiSymbolLeg{m: &m})
+		leg.mut = mut
+		m.legs[inst.Symbol] = leg
+		m.symbols = append(m.symbols, inst.Symbol)
+	}
+	return &m
+}
+
+//Strategies returns strategies of all symbols which should be passed to the engine
+func (m *MultiSymbolStrategy) Strategies() map[string]ICoreStrategy {
+	sp := make(map[string]ICoreStrategy)
+	for s, leg := range m.legs {
+		sp[s] = leg
+	}
+	return sp
+}
+
+func (m *MultiSymbolStrategy) Symbols() []string {
+	return m.symbols
+}
+
+//Strategy returns strategy of the symbol or nil if symbol is not in the basket
+func (m *MultiSymbolStrategy) Strategy(symbol string) *BasicStrategy {
+	return m.legs[symbol]
+}
+
+func (m *MultiSymbolStrategy) leg(symbol string) (*BasicStrategy, error) {
+	leg, ok := m.legs[symbol]
+	if !ok {
+		return nil, fmt.Errorf("Symbol %v is not in multi symbol strategy. ", symbol)
+	}
+	return leg, nil
+}
+
+func (m *MultiSymbolStrategy) Position(symbol string) int64 {
+	leg, ok := m.legs[symbol]
+	if !ok {
+		return 0
+	}
+	return leg.Position()
+}
+
+func (m *MultiSymbolStrategy) NewLimitOrder(symbol string, price float64, side OrderSide, qty int64, tif OrderTIF, destination string) (string, error) {
+	leg, err := m.leg(symbol)
+	if err != nil {
+		return "", err
+	}
+	return leg.NewLimitOrder(price, side, qty, tif, destination)
+}
+
+func (m *MultiSymbolStrategy) NewMarketOrder(symbol string, side OrderSide, qty int64, tif OrderTIF, destination string) (string, error) {
+	leg, err := m.leg(symbol)
+	if err != nil {
+		return "", err
+	}
+	return leg.NewMarketOrder(side, qty, tif, destination)
+}
+
+func (m *MultiSymbolStrategy) CancelOrder(symbol string, ordID string) error {
+	leg, err := m.leg(symbol)
+	if err != nil {
+		return err
+	}
+	return leg.CancelOrder(ordID)
+}
+
+//GetTotalPnL returns sum of PnL of all symbols of the basket
+func (m *MultiSymbolStrategy) GetTotalPnL() float64 {
+	pnl := 0.0
+	for _, leg := range m.legs {
+		for _, t := range leg.closedTrades {
+			pnl += t.ClosedPnL
+		}
+		if leg.currentTrade != nil && leg.currentTrade.Type != FlatTrade {
+			pnl += leg.currentTrade.ClosedPnL + leg.currentTrade.OpenPnL
+		}
+	}
+	return pnl
+}
+
+//multiSymbolLeg passes callbacks of symbol strategy to multi symbol user strategy
+type multiSymbolLeg struct {
+	m *MultiSymbolStrategy
+}
+
+func (l *multiSymbolLeg) OnTick(b *BasicStrategy, tick *Tick) {
+	l.m.userStrategy.OnTick(l.m, b, tick)
+}
+
+func (l *multiSymbolLeg) OnCandleClose(b *BasicStrategy, candle *Candle) {
+	l.m.userStrategy.OnCandleClose(l.m, b, candle)
+}
+
+func (l *multiSymbolLeg) OnCandleOpen(b *BasicStrategy, price float64) {
+	l.m.userStrategy.OnCandleOpen(l.m, b, price)
+}
+
+func (l *multiSymbolLeg) OnTimer(b *BasicStrategy, name string, t time.Time) {
+	if name == rebalanceTimerName {
+		l.m.onRebalanceTimer(b)
+	}
+}
@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type pairTestStrategy struct {
+	closes    map[string]int
+	otherSeen int
+}
+
+func (s *pairTestStrategy) OnTick(m *MultiSymbolStrategy, b *BasicStrategy, tick *Tick) {}
+
+func (s *pairTestStrategy) OnCandleOpen(m *MultiSymbolStrategy, b *BasicStrategy, price float64) {}
+
+func (s *pairTestStrategy) OnCandleClose(m *MultiSymbolStrategy, b *BasicStrategy, candle *Candle) {
+	s.closes[b.symbol.Symbol]++
+	if b.symbol.Symbol != "B" {
+		return
+	}
+	other := m.Strategy("A")
+	s.otherSeen = len(other.Candles)
+	if _, err := m.NewLimitOrder("A", 10, OrderBuy, 100, GTCTIF, "Sim"); err != nil {
+		panic(err)
+	}
+}
+
+func TestMultiSymbolStrategy(t *testing.T) {
+	symA := newTestInstrument()
+	symA.Symbol = "A"
+	symB := newTestInstrument()
+	symB.Symbol = "B"
+
+	us := &pairTestStrategy{closes: make(map[string]int)}
+	m := NewMultiSymbolStrategy([]*Instrument{symA, symB, symA}, 1, us)
+	assert.Equal(t, []string{"A", "B"}, m.Symbols())
+	assert.Len(t, m.Strategies(), 2)
+	assert.Equal(t, m.Strategy("A").mut, m.Strategy("B").mut)
+
+	events := make(chan event)
+	go func() {
+		for range events {
+		}
+	}()
+	cc := CoreStrategyChannels{errors: make(chan error, 10), events: events,
+		portfolio: make(chan *PortfolioNewPositionEvent, 10)}
+	for _, st := range m.Strategies() {
+		st.init(cc)
+	}
+	assert.Equal(t, m.Strategy("A").mut, m.Strategy("B").mut)
+
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	for _, inst := range []*Instrument{symA, symB} {
+		e := newTestCandleCloseEvent(10, 11, 9, 10.5, tm, "1")
+		e.Candle.Ticker = inst
+		e.Ticker = inst
+		m.Strategy(inst.Symbol).notify(e)
+		m.Strategy(inst.Symbol).shutDown()
+	}
+
+	assert.Equal(t, 1, us.closes["A"])
+	assert.Equal(t, 1, us.closes["B"])
+	assert.Equal(t, 1, us.otherSeen)
+	assert.Len(t, m.Strategy("A").currentTrade.NewOrders, 1)
+
+	_, err := m.NewMarketOrder("C", OrderBuy, 100, DayTIF, "Sim")
+	assert.NotNil(t, err)
+}
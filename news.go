@@ -0,0 +1,47 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"time"
+)
+
+//NewsItem is news about the symbol. Sentiment is score of the news from -1 (negative) to 1 (positive).
+type NewsItem struct {
+	Time      time.Time
+	Headline  string
+	Source    string
+	Sentiment float64
+}
+
+//INewsStorage can be implemented by market data storage which has news. BTM merges news of the symbols
+//it works with into market data stream as NewsEvent.
+type INewsStorage interface {
+	GetNews(symbol *Instrument, dRange marketdata.DateRange) ([]*NewsItem, error)
+}
+
+//loadNews returns news events of all symbols
+func (m *BTM) loadNews() eventArray {
+	s, ok := m.Storage.(INewsStorage)
+	if !ok {
+		return nil
+	}
+	rng := marketdata.DateRange{From: m.FromDate, To: m.ToDate.AddDate(0, 0, 1)}
+
+	var events eventArray
+	for _, inst := range m.Symbols {
+		news, err := s.GetNews(inst, rng)
+		if err != nil {
+			m.newError(err)
+			continue
+		}
+		for _, n := range news {
+			events = append(events, &NewsEvent{
+				BaseEvent: be(n.Time, inst),
+				Headline:  n.Headline,
+				Source:    n.Source,
+				Sentiment: n.Sentiment,
+			})
+		}
+	}
+	return events
+}
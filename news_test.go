@@ -0,0 +1,73 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+type mockNewsStorage struct {
+	*SyntheticStorage
+	news []*NewsItem
+}
+
+func (s *mockNewsStorage) GetNews(symbol *Instrument, dRange marketdata.DateRange) ([]*NewsItem, error) {
+	if symbol.Symbol != "Sym1" {
+		return nil, nil
+	}
+	return s.news, nil
+}
+
+func TestBTM_newsMergedToStream(t *testing.T) {
+	from := time.Date(2018, 3, 5, 0, 0, 0, 0, time.UTC)
+	storage := mockNewsStorage{
+		SyntheticStorage: NewSyntheticStorage(1, from, 100, &GBMProcess{Volatility: 0.2}),
+		news: []*NewsItem{
+			{Time: from.Add(10 * time.Hour), Headline: "Second", Sentiment: -0.5},
+			{Time: from.Add(9 * time.Hour), Headline: "First", Sentiment: 0.7},
+		},
+	}
+	mdChan := make(chan event, 10)
+	m := BTM{
+		Symbols:   []*Instrument{{Symbol: "Sym1"}},
+		FromDate:  from,
+		ToDate:    from.AddDate(0, 0, 1),
+		Storage:   &storage,
+		waitGroup: &sync.WaitGroup{},
+		mdChan:    mdChan,
+	}
+	m.sideEvents = m.loadSideEvents()
+
+	tick := &NewTickEvent{BaseEvent: be(from.Add(9*time.Hour+30*time.Minute), m.Symbols[0])}
+	m.newEvent(tick)
+
+	first, ok := (<-mdChan).(*NewsEvent)
+	assert.True(t, ok)
+	assert.Equal(t, "First", first.Headline)
+	assert.Equal(t, 0.7, first.Sentiment)
+	assert.Equal(t, tick, <-mdChan)
+	assert.Len(t, m.sideEvents, 1)
+}
+
+type newsTestStrategy struct {
+	DummyStrategy
+	got []*NewsEvent
+}
+
+func (s *newsTestStrategy) OnNews(b *BasicStrategy, e *NewsEvent) {
+	s.got = append(s.got, e)
+}
+
+func TestBasicStrategy_onNews(t *testing.T) {
+	st := newTestBasicStrategy()
+	us := &newsTestStrategy{}
+	st.userStrategy = us
+
+	st.notify(&NewsEvent{BaseEvent: be(time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC), st.symbol), Headline: "News"})
+	st.shutDown()
+
+	assert.Len(t, us.got, 1)
+	assert.Equal(t, "News", us.got[0].Headline)
+}
@@ -0,0 +1,248 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+//OptimizerRun returns options of one backtest run for parameter values, e.g. strategies with parameters set
+//and portfolio. Market data and broker are set by optimizer, so every run gets its own.
+type OptimizerRun func(params map[string]float64) ([]Option, error)
+
+//OptimizerResult is outcome of the run with parameter values. Err is set if run failed, metrics are empty then.
+//Segments are reports of segments of the data. InSampleOnly is set if parameters are validated by in-sample
+//segments only, see scoreSegments.
+type OptimizerResult struct {
+	Params       map[string]float64
+	Performance  PerformanceReport
+	Stats        TradeStats
+	Segments     []SegmentReport
+	Score        float64
+	InSampleOnly bool
+	Err          error
+}
+
+//Optimizer runs independent backtests for points of parameter space in pool of workers and ranks them by
+//score. Data is prepared once and every run reads prepaired file with its own copy of the BTM. Points can
+//come from grid, random sampling or any other search.
+type Optimizer struct {
+	data    *BTM
+	run     OptimizerRun
+	workers int
+	score   func(r *OptimizerResult) float64
+	broker  func() IBroker
+	//runOne runs backtest of one point. It's replaced in tests.
+	runOne func(ctx context.Context, params map[string]float64) OptimizerResult
+}
+
+//NewOptimizer creates optimizer of runs over market data of the BTM. By default it has worker for every CPU,
+//simulated broker and ranks runs by Sharpe ratio.
+func NewOptimizer(data *BTM, run OptimizerRun) *Optimizer {
+	o := &Optimizer{
+		data:    data,
+		run:     run,
+		workers: runtime.NumCPU(),
+		score:   func(r *OptimizerResult) float64 { return r.Performance.Sharpe },
+		broker:  func() IBroker { return &SimBroker{} },
+	}
+	o.runOne = o.backtest
+	return o
+}
+
+//SetWorkers sets number of backtests run at the same time
+func (o *Optimizer) SetWorkers(workers int) error {
+	if workers < 1 {
+		return errors.New("Optimizer needs at least one worker. ")
+	}
+	o.workers = workers
+	return nil
+}
+
+//SetScore sets function runs are ranked by, larger is better. NaN scores are ranked last.
+func (o *Optimizer) SetScore(score func(r *OptimizerResult) float64) {
+	o.score = score
+}
+
+//SetBroker sets factory of broker of every run, e.g. simulated broker with custom costs
+func (o *Optimizer) SetBroker(broker func() IBroker) {
+	o.broker = broker
+}
+
+//RunGrid runs backtests for all combinations of parameter values
+func (o *Optimizer) RunGrid(ctx context.Context, params *Params) ([]OptimizerResult, error) {
+	return o.RunPoints(ctx, params.Grid())
+}
+
+//RunRandom runs backtests for n random points of parameter grid. Same seed gives same points.
+func (o *Optimizer) RunRandom(ctx context.Context, params *Params, n int, seed int64) ([]OptimizerResult, error) {
+	return o.RunPoints(ctx, RandomPoints(params, n, seed))
+}
+
+//RunPoints prepares data and runs backtests for every point. Results are sorted by score, failed runs are the
+//last. Runs which were not started before context is done are not returned. If data has segments, runs are
+//scored by in-sample ones.
+func (o *Optimizer) RunPoints(ctx context.Context, points []map[string]float64) ([]OptimizerResult, error) {
+	if len(o.data.futuresChains) > 0 {
+		return nil, errors.New("Optimizer doesn't support futures chains, they keep state of the run. ")
+	}
+	if err := o.data.checkSegments(); err != nil {
+		return nil, err
+	}
+	if err := o.prepare(); err != nil {
+		return nil, err
+	}
+
+	tasks := make(chan map[string]float64)
+	var mut sync.Mutex
+	var results []OptimizerResult
+	wg := &sync.WaitGroup{}
+	for i := 0; i < o.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range tasks {
+				r := o.runOne(ctx, p)
+				r.Params = p
+				if r.Err == nil {
+					r.Score = o.score(&r)
+					scoreSegments(&r, o.score)
+				}
+				mut.Lock()
+				results = append(results, r)
+				mut.Unlock()
+			}
+		}()
+	}
+Loop:
+	for _, p := range points {
+		select {
+		case tasks <- p:
+		case <-doneChan(ctx):
+			break Loop
+		}
+	}
+	close(tasks)
+	wg.Wait()
+
+	rankResults(results)
+	return results, nil
+}
+
+//prepare makes prepaired file of the data if it's missing or outdated
+func (o *Optimizer) prepare() (err error) {
+	m := o.data.clone()
+	ctx, cancel := context.WithCancel(context.Background())
+	errChan := make(chan error, 1)
+	m.Init(errChan, make(chan event))
+	m.SetContext(ctx)
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("Can't prepare optimizer data: %v", r)
+		}
+		cancel()
+		m.waitGroup.Wait()
+		if err == nil {
+			select {
+			case err = <-errChan:
+			default:
+			}
+		}
+	}()
+	if !m.prepairedDataIsActual() {
+		m.prepareIncrementally()
+	}
+	return nil
+}
+
+//backtest runs engine with options of the point, copy of data and new broker
+func (o *Optimizer) backtest(ctx context.Context, params map[string]float64) OptimizerResult {
+	opts, err := o.run(params)
+	if err != nil {
+		return OptimizerResult{Err: err}
+	}
+	opts = append(opts, WithMarketData(o.data.clone()), WithBroker(o.broker()))
+	e, err := New(opts...)
+	if err != nil {
+		return OptimizerResult{Err: err}
+	}
+	e.RunContext(ctx)
+	perf, ok := e.PerformanceReport()
+	if !ok {
+		err := errors.New("Run ended before end of data. ")
+		if status := e.Status(); status.Err != nil {
+			err = status.Err
+		}
+		return OptimizerResult{Err: err}
+	}
+	segments, err := e.SegmentReports()
+	if err != nil {
+		return OptimizerResult{Err: err}
+	}
+	return OptimizerResult{Performance: perf, Stats: calcTradeStats(e.ClosedTrades()), Segments: segments}
+}
+
+//rankResults sorts results by score. NaN scores follow numbers and failed runs are the last.
+func rankResults(results []OptimizerResult) {
+	rank := func(r *OptimizerResult) int {
+		switch {
+		case r.Err != nil:
+			return 2
+		case math.IsNaN(r.Score):
+			return 1
+		}
+		return 0
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		ri, rj := rank(&results[i]), rank(&results[j])
+		if ri != rj || ri != 0 {
+			return ri < rj
+		}
+		return results[i].Score > results[j].Score
+	})
+}
+
+//RandomPoints returns n points of parameter grid chosen at random. Parameters without step keep default value.
+func RandomPoints(params *Params, n int, seed int64) []map[string]float64 {
+	rnd := rand.New(rand.NewSource(seed))
+	points := make([]map[string]float64, n)
+	for i := range points {
+		points[i] = make(map[string]float64, len(params.Specs()))
+		for _, s := range params.Specs() {
+			values := s.values()
+			points[i][s.Name] = values[rnd.Intn(len(values))]
+		}
+	}
+	return points
+}
+
+//clone returns BTM with the same data settings and its own run state, so several copies can run at once
+func (m *BTM) clone() *BTM {
+	c := &BTM{
+		Symbols:               append([]*Instrument{}, m.Symbols...),
+		Folder:                m.Folder,
+		FromDate:              m.FromDate,
+		ToDate:                m.ToDate,
+		UsePrepairedData:      m.UsePrepairedData,
+		CompressPrepairedData: m.CompressPrepairedData,
+		PrepareWorkers:        m.PrepareWorkers,
+		Segments:              m.Segments,
+		candlesTimeFrame:      m.candlesTimeFrame,
+		Storage:               m.Storage,
+		waitGroup:             &sync.WaitGroup{},
+		mode:                  m.mode,
+		universe:              m.universe,
+		logger:                m.logger,
+		auction:               m.auction,
+		delistings:            m.delistings,
+		streaming:             m.streaming,
+	}
+	c.gapDetector.threshold = m.gapDetector.threshold
+	c.progress.interval = m.progress.interval
+	return c
+}
@@ -0,0 +1,145 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"math"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//newTestOptimizerData returns BTM with empty prepaired file in temporary folder
+func newTestOptimizerData(t *testing.T) *BTM {
+	dir, err := ioutil.TempDir("", "optimizer")
+	assert.Nil(t, err)
+	m := &BTM{Symbols: []*Instrument{newTestInstrument()}, Folder: dir, mode: MarketDataModeTicks,
+		waitGroup: &sync.WaitGroup{}}
+	assert.Nil(t, ioutil.WriteFile(m.getPrepairedFilePath(), nil, 0644))
+	return m
+}
+
+func TestOptimizer_RunGrid(t *testing.T) {
+	data := newTestOptimizerData(t)
+	defer os.RemoveAll(data.Folder)
+	params := NewParams()
+	params.DeclareInt("fast", 5, 1, 3, 1)
+	params.DeclareFloat("stop", 0.01, 0.01, 0.02, 0.01)
+
+	o := NewOptimizer(data, nil)
+	assert.NotNil(t, o.SetWorkers(0))
+	assert.Nil(t, o.SetWorkers(2))
+	var mut sync.Mutex
+	running, maxRunning := 0, 0
+	o.runOne = func(ctx context.Context, p map[string]float64) OptimizerResult {
+		mut.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mut.Unlock()
+		defer func() {
+			mut.Lock()
+			running--
+			mut.Unlock()
+		}()
+		switch {
+		case p["fast"] == 3 && p["stop"] == 0.02:
+			return OptimizerResult{Err: errors.New("failed")}
+		case p["fast"] == 1:
+			return OptimizerResult{Performance: PerformanceReport{Sharpe: math.NaN()}}
+		}
+		return OptimizerResult{Performance: PerformanceReport{Sharpe: p["fast"] + p["stop"]}}
+	}
+
+	results, err := o.RunGrid(context.Background(), params)
+	assert.Nil(t, err)
+	assert.True(t, maxRunning <= 2)
+	if assert.Len(t, results, 6) {
+		assert.Equal(t, map[string]float64{"fast": 3, "stop": 0.01}, results[0].Params)
+		assert.InDelta(t, 3.01, results[0].Score, 0.0000001)
+		assert.InDelta(t, 2.02, results[1].Score, 0.0000001)
+		assert.InDelta(t, 2.01, results[2].Score, 0.0000001)
+		assert.True(t, math.IsNaN(results[3].Score))
+		assert.True(t, math.IsNaN(results[4].Score))
+		assert.NotNil(t, results[5].Err)
+	}
+
+	o.SetScore(func(r *OptimizerResult) float64 { return -r.Performance.Sharpe })
+	results, err = o.RunPoints(context.Background(), []map[string]float64{{"fast": 2}, {"fast": 3}})
+	assert.Nil(t, err)
+	assert.Equal(t, 2.0, results[0].Params["fast"])
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err = o.RunGrid(ctx, params)
+	assert.Nil(t, err)
+	assert.True(t, len(results) < 6)
+}
+
+func TestOptimizer_prepareError(t *testing.T) {
+	o := NewOptimizer(&BTM{waitGroup: &sync.WaitGroup{}}, nil)
+	_, err := o.RunPoints(context.Background(), []map[string]float64{{}})
+	assert.NotNil(t, err)
+
+	data := newTestOptimizerData(t)
+	defer os.RemoveAll(data.Folder)
+	data.AddFuturesChain(&FuturesChain{Root: &Instrument{Symbol: "ES"},
+		Contracts: []*FuturesContract{{Contract: &Instrument{Symbol: "ESH8"}}}})
+	_, err = NewOptimizer(data, nil).RunPoints(context.Background(), nil)
+	assert.NotNil(t, err)
+}
+
+func TestRandomPoints(t *testing.T) {
+	params := NewParams()
+	params.DeclareInt("fast", 5, 1, 10, 1)
+	params.DeclareFloat("stop", 0.01, 0, 1, 0)
+
+	points := RandomPoints(params, 20, 1)
+	assert.Len(t, points, 20)
+	assert.Equal(t, points, RandomPoints(params, 20, 1))
+	for _, p := range points {
+		assert.True(t, p["fast"] >= 1 && p["fast"] <= 10 && p["fast"] == math.Trunc(p["fast"]))
+		assert.Equal(t, 0.01, p["stop"])
+	}
+}
+
+func TestBTM_clone(t *testing.T) {
+	m := newTestBTMforTicks()
+	m.SetDataGapThreshold(5)
+	c := m.clone()
+	assert.Equal(t, m.getPrepairedFilePath(), c.getPrepairedFilePath())
+	assert.Equal(t, m.gapDetector.threshold, c.gapDetector.threshold)
+	assert.False(t, m.waitGroup == c.waitGroup)
+	assert.Nil(t, c.mdChan)
+	c.Symbols[0] = nil
+	assert.NotNil(t, m.Symbols[0])
+}
+
+func TestOptimizer_backtest(t *testing.T) {
+	data := newTestBTMforTicks()
+	assert.Nil(t, data.SplitAt(time.Date(2018, 3, 7, 0, 0, 0, 0, time.UTC)))
+	o := NewOptimizer(data, func(p map[string]float64) ([]Option, error) {
+		var opts []Option
+		for _, s := range data.Symbols {
+			opts = append(opts, WithStrategy(&Instrument{Symbol: s.Symbol}, &DummyStrategy{}, int(p["periods"])))
+		}
+		return opts, nil
+	})
+	assert.Nil(t, o.SetWorkers(2))
+	results, err := o.RunPoints(context.Background(), []map[string]float64{{"periods": 1}, {"periods": 0}})
+	assert.Nil(t, err)
+	if assert.Len(t, results, 2) {
+		assert.Nil(t, results[0].Err)
+		assert.False(t, results[0].Performance.End.IsZero())
+		if assert.Len(t, results[0].Segments, 2) {
+			assert.Equal(t, OutOfSampleSegment, results[0].Segments[1].Segment.Name)
+		}
+		//Strategy without periods is rejected by engine builder
+		assert.NotNil(t, results[1].Err)
+	}
+}
@@ -0,0 +1,126 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"fmt"
+	"math"
+	"time"
+)
+
+type OptionRight string
+
+const (
+	OptionCall OptionRight = "C"
+	OptionPut  OptionRight = "P"
+)
+
+//OptionSpec describes option contract of the Instrument
+type OptionSpec struct {
+	Underlying *Instrument
+	Strike     float64
+	Expiry     time.Time
+	Right      OptionRight
+}
+
+//NewOptionInstrument creates option on the underlying. Symbol is built in OCC format: root, expiry date,
+//right and strike multiplied by 1000.
+func NewOptionInstrument(underlying *Instrument, strike float64, expiry time.Time, right OptionRight, multiplier float64) *Instrument {
+	symbol := fmt.Sprintf("%v %v%v%08d", underlying.Symbol, expiry.Format("060102"), right,
+		int64(math.Round(strike*1000)))
+	inst := Instrument{
+		Symbol:     symbol,
+		Exchange:   underlying.Exchange,
+		MinTick:    0.01,
+		LotSize:    1,
+		Multiplier: multiplier,
+		Option: &OptionSpec{
+			Underlying: underlying,
+			Strike:     strike,
+			Expiry:     expiry,
+			Right:      right,
+		},
+	}
+	return &inst
+}
+
+func (i *Instrument) IsOption() bool {
+	return i.Option != nil
+}
+
+//multiplier returns contract multiplier. Instruments without multiplier have it equal to 1.
+func (i *Instrument) multiplier() float64 {
+	if i == nil || i.Multiplier == 0 {
+		return 1
+	}
+	return i.Multiplier
+}
+
+//OptionQuote is market data of one option in the chain snapshot
+type OptionQuote struct {
+	Option       *Instrument
+	Bid          float64
+	BidSize      int64
+	Ask          float64
+	AskSize      int64
+	Last         float64
+	Volume       int64
+	OpenInterest int64
+	ImpliedVol   float64
+}
+
+//tick converts quote to tick. Mid price is used as last price if option wasn't traded.
+func (q *OptionQuote) tick(t time.Time) *Tick {
+	last := q.Last
+	if last == 0 && q.Bid > 0 && q.Ask > 0 {
+		last = (q.Bid + q.Ask) / 2
+	}
+	raw := marketdata.Tick{
+		Datetime:  t,
+		Symbol:    q.Option.Symbol,
+		LastPrice: last,
+		LastSize:  q.Volume,
+		BidPrice:  q.Bid,
+		BidSize:   q.BidSize,
+		AskPrice:  q.Ask,
+		AskSize:   q.AskSize,
+	}
+	return &Tick{Tick: &raw, Ticker: q.Option}
+}
+
+//OptionChainSnapshot is state of all options of the underlying at given time
+type OptionChainSnapshot struct {
+	Time   time.Time
+	Quotes []*OptionQuote
+}
+
+//IOptionChainStorage can be implemented by market data storage which has option chains. BTM produces
+//OptionChainEvent for every snapshot of the symbols it works with.
+type IOptionChainStorage interface {
+	GetOptionChains(underlying *Instrument, dRange marketdata.DateRange) ([]*OptionChainSnapshot, error)
+}
+
+//loadOptionChains returns time sorted option chain events of all symbols
+func (m *BTM) loadOptionChains() eventArray {
+	s, ok := m.Storage.(IOptionChainStorage)
+	if !ok {
+		return nil
+	}
+	rng := marketdata.DateRange{From: m.FromDate, To: m.ToDate.AddDate(0, 0, 1)}
+
+	var chains eventArray
+	for _, inst := range m.Symbols {
+		snapshots, err := s.GetOptionChains(inst, rng)
+		if err != nil {
+			m.newError(err)
+			continue
+		}
+		for _, snap := range snapshots {
+			chains = append(chains, &OptionChainEvent{
+				BaseEvent: be(snap.Time, inst),
+				Quotes:    snap.Quotes,
+			})
+		}
+	}
+	chains.sort()
+	return chains
+}
@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestNewOptionInstrument(t *testing.T) {
+	underlying := newTestInstrument()
+	opt := NewOptionInstrument(underlying, 105.5, time.Date(2018, 3, 16, 0, 0, 0, 0, time.UTC), OptionCall, 100)
+	assert.Equal(t, "Test 180316C00105500", opt.Symbol)
+	assert.True(t, opt.IsOption())
+	assert.False(t, underlying.IsOption())
+	assert.Equal(t, 100.0, opt.multiplier())
+	assert.Equal(t, 1.0, underlying.multiplier())
+
+	q := OptionQuote{Option: opt, Bid: 1.1, Ask: 1.3}
+	tick := q.tick(time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC))
+	assert.InDelta(t, 1.2, tick.LastPrice, 0.000001)
+	assert.Equal(t, opt.Symbol, tick.Symbol)
+}
+
+func TestTrade_executeOrderWithMultiplier(t *testing.T) {
+	opt := NewOptionInstrument(newTestInstrument(), 100, time.Date(2018, 3, 16, 0, 0, 0, 0, time.UTC), OptionPut, 100)
+	trade := newFlatTrade(opt)
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+
+	buy := &Order{Id: "1", Side: OrderBuy, Qty: 2, Price: 1.5, Ticker: opt, State: ConfirmedOrder, Type: LimitOrder}
+	trade.ConfirmedOrders[buy.Id] = buy
+	_, err := trade.executeOrder(buy.Id, 2, 1.5, tm)
+	assert.Nil(t, err)
+	assert.Equal(t, 300.0, trade.OpenValue)
+	assert.Equal(t, 1.5, trade.OpenPrice)
+
+	assert.Nil(t, trade.updatePnL(2, tm))
+	assert.Equal(t, 100.0, trade.OpenPnL)
+
+	sell := &Order{Id: "2", Side: OrderSell, Qty: 2, Price: 2.5, Ticker: opt, State: ConfirmedOrder, Type: LimitOrder}
+	trade.ConfirmedOrders[sell.Id] = sell
+	_, err = trade.executeOrder(sell.Id, 2, 2.5, tm)
+	assert.Nil(t, err)
+	assert.Equal(t, 200.0, trade.ClosedPnL)
+}
+
+func TestSimBroker_onOptionChain(t *testing.T) {
+	b := newTestSimBroker()
+	go func() {
+		for range b.events {
+		}
+	}()
+	opt := NewOptionInstrument(newTestInstrument(), 100, time.Date(2018, 3, 16, 0, 0, 0, 0, time.UTC), OptionCall, 100)
+	b.Notify(&OptionChainEvent{
+		BaseEvent: be(time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC), newTestInstrument()),
+		Quotes:    []*OptionQuote{{Option: opt, Bid: 1, Ask: 1.2, Last: 1.1, Volume: 10}},
+	})
+
+	_, ok := b.workers[opt.Symbol]
+	assert.True(t, ok)
+}
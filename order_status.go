@@ -0,0 +1,80 @@
+package engine
+
+import "time"
+
+//OrderStatusInfo is state of the order as strategy knows it from broker events
+type OrderStatusInfo struct {
+	Id           string
+	State        OrderState
+	Side         OrderSide
+	Type         OrderType
+	Tif          OrderTIF
+	Price        float64
+	Qty          int64
+	ExecQty      int64
+	LeavesQty    int64
+	AvgExecPrice float64
+	Fills        int
+	RejectReason string
+	//PendingCancel and PendingReplace are true while cancel or replace request waits for broker answer
+	PendingCancel  bool
+	PendingReplace bool
+	CreatedTime    time.Time
+	UpdatedTime    time.Time
+}
+
+//orderRecord keeps order of the strategy with the details which are not stored in the order itself
+type orderRecord struct {
+	order        *Order
+	fills        int
+	rejectReason string
+	created      time.Time
+	updated      time.Time
+}
+
+func (b *BasicStrategy) trackOrder(o *Order) {
+	if b.orders == nil {
+		b.orders = make(map[string]*orderRecord)
+	}
+	b.orders[o.Id] = &orderRecord{order: o, created: o.Time, updated: o.Time}
+}
+
+//orderUpdated updates time of the last change of tracked order and returns its record
+func (b *BasicStrategy) orderUpdated(id string, t time.Time) *orderRecord {
+	r, ok := b.orders[id]
+	if !ok {
+		return nil
+	}
+	if t.After(r.updated) {
+		r.updated = t
+	}
+	return r
+}
+
+func (r *orderRecord) info(b *BasicStrategy) *OrderStatusInfo {
+	o := r.order
+	_, pendingCancel := b.waitingConfirmation["$CAN$"+o.Id]
+	_, pendingReplace := b.waitingConfirmation["$REP$"+o.Id]
+	leaves := o.Qty - o.ExecQty
+	if o.State == CanceledOrder || o.State == RejectedOrder {
+		leaves = 0
+	}
+	return &OrderStatusInfo{
+		Id:             o.Id,
+		State:          o.State,
+		Side:           o.Side,
+		Type:           o.Type,
+		Tif:            o.Tif,
+		Price:          o.Price,
+		Qty:            o.Qty,
+		ExecQty:        o.ExecQty,
+		LeavesQty:      leaves,
+		AvgExecPrice:   o.ExecPrice,
+		Fills:          r.fills,
+		RejectReason:   r.rejectReason,
+		PendingCancel:  pendingCancel,
+		PendingReplace: pendingReplace,
+		CreatedTime:    r.created,
+		UpdatedTime:    r.updated,
+	}
+}
@@ -0,0 +1,58 @@
+package engine
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestBasicStrategy_OrderStatus(t *testing.T) {
+	st := newTestBasicStrategy()
+	go func() {
+		for range st.ch.events {
+		}
+	}()
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	st.mostRecentTime = tm
+
+	_, err := st.OrderStatus("unknown")
+	assert.NotNil(t, err)
+
+	id, err := st.NewLimitOrder(10, OrderBuy, 300, GTCTIF, "Sim")
+	assert.Nil(t, err)
+
+	info, err := st.OrderStatus(id)
+	assert.Nil(t, err)
+	assert.Equal(t, NewOrder, info.State)
+	assert.Equal(t, int64(300), info.LeavesQty)
+
+	st.proxyEvent(&OrderConfirmationEvent{BaseEvent: be(tm.Add(time.Second), st.symbol), OrdId: id})
+	st.proxyEvent(&OrderFillEvent{BaseEvent: be(tm.Add(2*time.Second), st.symbol), OrdId: id, Price: 10, Qty: 100})
+	st.proxyEvent(&OrderFillEvent{BaseEvent: be(tm.Add(3*time.Second), st.symbol), OrdId: id, Price: 9.9, Qty: 100})
+
+	info, err = st.OrderStatus(id)
+	assert.Nil(t, err)
+	assert.Equal(t, PartialFilledOrder, info.State)
+	assert.Equal(t, int64(200), info.ExecQty)
+	assert.Equal(t, int64(100), info.LeavesQty)
+	assert.Equal(t, 2, info.Fills)
+	assert.InDelta(t, 9.95, info.AvgExecPrice, 0.000001)
+	assert.Equal(t, tm.Add(3*time.Second), info.UpdatedTime)
+
+	assert.Nil(t, st.CancelOrder(id))
+	info, _ = st.OrderStatus(id)
+	assert.True(t, info.PendingCancel)
+
+	st.proxyEvent(&OrderCancelEvent{BaseEvent: be(tm.Add(4*time.Second), st.symbol), OrdId: id})
+	info, _ = st.OrderStatus(id)
+	assert.Equal(t, CanceledOrder, info.State)
+	assert.False(t, info.PendingCancel)
+	assert.Equal(t, int64(0), info.LeavesQty)
+
+	rejID, err := st.NewLimitOrder(9, OrderBuy, 100, GTCTIF, "Sim")
+	assert.Nil(t, err)
+	st.proxyEvent(&OrderRejectedEvent{BaseEvent: be(tm.Add(5*time.Second), st.symbol), OrdId: rejID, Reason: "No money"})
+	info, _ = st.OrderStatus(rejID)
+	assert.Equal(t, RejectedOrder, info.State)
+	assert.Equal(t, "No money", info.RejectReason)
+}
@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+//panicState is what strategy does when callback of user strategy panics
+type panicState struct {
+	disableOnPanic bool
+	disabled       bool
+}
+
+//panicStrategy is implemented by strategies which recover panics of user strategy
+type panicStrategy interface {
+	setDisableOnPanic(on bool)
+}
+
+//SetDisableOnPanic sets if strategy is disabled after panic of its user strategy. Panics of OnTick,
+//OnCandleOpen and OnCandleClose are always recovered and reported with StrategyErrorEvent and error, so one bad
+//callback doesn't kill the process. If strategy is disabled its user strategy isn't called anymore and working
+//orders are canceled. Other strategies keep running. In strict mode panics are not recovered.
+func (c *Engine) SetDisableOnPanic(on bool) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.disableOnPanic = on
+	for _, st := range c.strategiesMap {
+		if ps, ok := st.(panicStrategy); ok {
+			ps.setDisableOnPanic(on)
+		}
+	}
+}
+
+func (b *BasicStrategy) setDisableOnPanic(on bool) {
+	b.panics.disableOnPanic = on
+}
+
+//IsDisabled returns true if strategy was disabled after panic of user strategy
+func (b *BasicStrategy) IsDisabled() bool {
+	return b.panics.disabled
+}
+
+//callUserStrategy calls callback of user strategy unless strategy is disabled. Panic is recovered and
+//reported, latency of callback is measured.
+func (b *BasicStrategy) callUserStrategy(callback string, f func()) {
+	if b.panics.disabled {
+		return
+	}
+	start := time.Now()
+	defer b.startDecision()()
+	defer func() {
+		if r := recover(); r != nil {
+			if b.strict {
+				panic(r)
+			}
+			b.onUserStrategyPanic(callback, r, string(debug.Stack()))
+		}
+	}()
+	f()
+	b.observeHandler(callback, start)
+}
+
+//onUserStrategyPanic notifies engine about panic and disables strategy if it's required
+func (b *BasicStrategy) onUserStrategyPanic(callback string, r interface{}, stack string) {
+	err := &EngineError{
+		Kind:    ErrCodeStrategyPanic,
+		Message: fmt.Sprintf("%v of %v %v panicked: %v", callback, b.symbol.Symbol, b.name, r),
+		Caller:  "BasicStrategy",
+	}
+	b.newError(err)
+	disable := b.panics.disableOnPanic
+	if disable {
+		b.panics.disabled = true
+		b.target = nil
+		if err := b.CancelAllOrders(); err != nil {
+			b.newError(err)
+		}
+	}
+	b.newSignal(&StrategyErrorEvent{
+		BaseEvent: be(b.mostRecentTime, b.symbol),
+		Strategy:  b.name,
+		Callback:  callback,
+		Error:     fmt.Sprint(r),
+		Stack:     stack,
+		Disabled:  disable,
+	})
+}
+
+func (g *StrategyGroup) setDisableOnPanic(on bool) {
+	g.forEach(func(st *BasicStrategy) { st.setDisableOnPanic(on) })
+}
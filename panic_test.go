@@ -0,0 +1,80 @@
+package engine
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//panicTestStrategy panics on every closed candle
+type panicTestStrategy struct {
+	DummyStrategy
+	calls int
+}
+
+func (s *panicTestStrategy) OnCandleClose(b *BasicStrategy, candle *Candle) {
+	s.calls++
+	panic("index out of range")
+}
+
+func newTestPanicStrategy(disable bool) (*BasicStrategy, *panicTestStrategy) {
+	st := newTestBasicStrategy()
+	us := &panicTestStrategy{}
+	st.userStrategy = us
+	st.nPeriods = 1
+	st.ch.events = make(chan event, 10)
+	st.ch.errors = make(chan error, 10)
+	st.setSynchronous(true)
+	st.setDisableOnPanic(disable)
+	return st, us
+}
+
+func TestBasicStrategy_UserStrategyPanic(t *testing.T) {
+	st, us := newTestPanicStrategy(false)
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	st.notify(newTestCandleCloseEvent(10, 20, 9, 10, tm, "1"))
+	st.notify(newTestCandleCloseEvent(10, 20, 9, 10, tm.Add(time.Minute), "1"))
+	st.shutDown()
+
+	assert.Equal(t, 2, us.calls)
+	assert.False(t, st.IsDisabled())
+	assert.Len(t, st.ch.errors, 2)
+	if assert.Len(t, st.ch.events, 2) {
+		e := (<-st.ch.events).(*StrategyErrorEvent)
+		assert.Equal(t, "OnCandleClose", e.Callback)
+		assert.Equal(t, "index out of range", e.Error)
+		assert.Contains(t, e.Stack, "panicTestStrategy")
+		assert.False(t, e.Disabled)
+	}
+}
+
+func TestBasicStrategy_DisableOnPanic(t *testing.T) {
+	st, us := newTestPanicStrategy(true)
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	st.notify(newTestCandleCloseEvent(10, 20, 9, 10, tm, "1"))
+	st.notify(newTestCandleCloseEvent(10, 20, 9, 10, tm.Add(time.Minute), "1"))
+	st.shutDown()
+
+	assert.Equal(t, 1, us.calls)
+	assert.True(t, st.IsDisabled())
+	if assert.Len(t, st.ch.events, 1) {
+		assert.True(t, (<-st.ch.events).(*StrategyErrorEvent).Disabled)
+	}
+}
+
+func TestBasicStrategy_PanicStrictMode(t *testing.T) {
+	st, _ := newTestPanicStrategy(false)
+	st.setStrictMode(true)
+	assert.Panics(t, func() {
+		st.callUserStrategy("OnCandleClose", func() { panic("failed") })
+	})
+}
+
+func TestEngine_SetDisableOnPanic(t *testing.T) {
+	st := newTestBasicStrategy()
+	c := Engine{strategiesMap: map[string]ICoreStrategy{st.symbol.Symbol: st}, mut: &sync.Mutex{}}
+	c.SetDisableOnPanic(true)
+	assert.True(t, st.panics.disableOnPanic)
+}
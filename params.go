@@ -0,0 +1,196 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+type ParamType string
+
+const (
+	ParamInt   ParamType = "ParamInt"
+	ParamFloat ParamType = "ParamFloat"
+)
+
+//ParamSpec describes strategy parameter. Values from Min to Max with Step are used for parameter grids.
+//Zero Step means that only Default value is used.
+type ParamSpec struct {
+	Name    string
+	Type    ParamType
+	Default float64
+	Min     float64
+	Max     float64
+	Step    float64
+}
+
+func (s *ParamSpec) validate(v float64) error {
+	if math.IsNaN(v) {
+		return fmt.Errorf("Parameter %v is NaN. ", s.Name)
+	}
+	if v < s.Min || v > s.Max {
+		return fmt.Errorf("Parameter %v value %v is out of range [%v, %v]. ", s.Name, v, s.Min, s.Max)
+	}
+	if s.Type == ParamInt && v != math.Trunc(v) {
+		return fmt.Errorf("Parameter %v should be integer, got %v. ", s.Name, v)
+	}
+	return nil
+}
+
+//values returns all values of the parameter grid
+func (s *ParamSpec) values() []float64 {
+	if s.Step <= 0 {
+		return []float64{s.Default}
+	}
+	var vals []float64
+	for i := 0; ; i++ {
+		v := s.Min + float64(i)*s.Step
+		//Avoid float steps accumulation error at the end of range
+		v = math.Round(v*1e9) / 1e9
+		if v > s.Max {
+			break
+		}
+		vals = append(vals, v)
+	}
+	return vals
+}
+
+//Params keeps declared parameters of the strategy and their values
+type Params struct {
+	specs  []*ParamSpec
+	values map[string]float64
+}
+
+func NewParams() *Params {
+	return &Params{values: make(map[string]float64)}
+}
+
+func (p *Params) declare(s *ParamSpec) {
+	for i, old := range p.specs {
+		if old.Name == s.Name {
+			p.specs[i] = s
+			return
+		}
+	}
+	p.specs = append(p.specs, s)
+}
+
+func (p *Params) DeclareInt(name string, def int, min int, max int, step int) {
+	p.declare(&ParamSpec{Name: name, Type: ParamInt, Default: float64(def), Min: float64(min),
+		Max: float64(max), Step: float64(step)})
+}
+
+func (p *Params) DeclareFloat(name string, def float64, min float64, max float64, step float64) {
+	p.declare(&ParamSpec{Name: name, Type: ParamFloat, Default: def, Min: min, Max: max, Step: step})
+}
+
+func (p *Params) spec(name string) *ParamSpec {
+	for _, s := range p.specs {
+		if s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+//Specs returns declared parameters in order of declaration
+func (p *Params) Specs() []*ParamSpec {
+	return p.specs
+}
+
+//Set sets value of declared parameter. Value is checked against parameter type and range.
+func (p *Params) Set(name string, v float64) error {
+	s := p.spec(name)
+	if s == nil {
+		return fmt.Errorf("Parameter %v is not declared. ", name)
+	}
+	if err := s.validate(v); err != nil {
+		return err
+	}
+	p.values[name] = v
+	return nil
+}
+
+//SetValues sets several values, e.g. one point of parameter grid
+func (p *Params) SetValues(values map[string]float64) error {
+	names := make([]string, 0, len(values))
+	for n := range values {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		if err := p.Set(n, values[n]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Validate checks that defaults and set values of all parameters are valid
+func (p *Params) Validate() error {
+	for _, s := range p.specs {
+		if s.Min > s.Max {
+			return fmt.Errorf("Parameter %v has min greater than max. ", s.Name)
+		}
+		if err := s.validate(p.Float(s.Name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Float returns value of the parameter. Default is returned if value wasn't set. Undeclared parameters are NaN.
+func (p *Params) Float(name string) float64 {
+	if v, ok := p.values[name]; ok {
+		return v
+	}
+	if s := p.spec(name); s != nil {
+		return s.Default
+	}
+	return math.NaN()
+}
+
+func (p *Params) Int(name string) int {
+	return int(math.Round(p.Float(name)))
+}
+
+//Values returns values of all declared parameters
+func (p *Params) Values() map[string]float64 {
+	vals := make(map[string]float64)
+	for _, s := range p.specs {
+		vals[s.Name] = p.Float(s.Name)
+	}
+	return vals
+}
+
+//Grid returns all combinations of parameter values. Parameters without step keep their default value.
+func (p *Params) Grid() []map[string]float64 {
+	grid := []map[string]float64{{}}
+	for _, s := range p.specs {
+		var next []map[string]float64
+		for _, point := range grid {
+			for _, v := range s.values() {
+				np := make(map[string]float64, len(point)+1)
+				for k, pv := range point {
+					np[k] = pv
+				}
+				np[s.Name] = v
+				next = append(next, np)
+			}
+		}
+		grid = next
+	}
+	return grid
+}
+
+//IParamsUserStrategy can be implemented by user strategy to declare its parameters. Parameters are declared
+//and validated when strategy is initialized. New returns error of invalid values, strategy initialized
+//without it sends the error to error channel and has no parameters.
+type IParamsUserStrategy interface {
+	DeclareParams(p *Params)
+}
+
+//paramsStrategy is implemented by strategies which can check parameter values before engine is created
+type paramsStrategy interface {
+	validateParams() error
+}
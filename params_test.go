@@ -0,0 +1,75 @@
+package engine
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParams(t *testing.T) {
+	p := NewParams()
+	p.DeclareInt("period", 20, 10, 30, 10)
+	p.DeclareFloat("k", 2, 1.5, 2.5, 0.5)
+	p.DeclareFloat("fixed", 0.1, 0, 1, 0)
+
+	assert.Nil(t, p.Validate())
+	assert.Equal(t, 20, p.Int("period"))
+	assert.NotNil(t, p.Set("period", 15.5))
+	assert.NotNil(t, p.Set("period", 40))
+	assert.NotNil(t, p.Set("unknown", 1))
+	assert.Nil(t, p.Set("k", 1.5))
+	assert.Equal(t, 1.5, p.Float("k"))
+
+	grid := p.Grid()
+	assert.Len(t, grid, 9)
+	assert.Equal(t, map[string]float64{"period": 10, "k": 1.5, "fixed": 0.1}, grid[0])
+	assert.Equal(t, map[string]float64{"period": 30, "k": 2.5, "fixed": 0.1}, grid[8])
+
+	p.DeclareInt("bad", 5, 10, 20, 1)
+	assert.NotNil(t, p.Validate())
+}
+
+type paramsTestStrategy struct {
+	DummyStrategy
+}
+
+func (s *paramsTestStrategy) DeclareParams(p *Params) {
+	p.DeclareInt("period", 20, 5, 50, 5)
+}
+
+func TestBasicStrategy_Params(t *testing.T) {
+	cc := CoreStrategyChannels{errors: make(chan error), events: make(chan event),
+		portfolio: make(chan *PortfolioNewPositionEvent, 1)}
+
+	st := NewBasicStrategy(newTestInstrument(), 1, &paramsTestStrategy{})
+	st.SetParams(map[string]float64{"period": 10})
+	st.init(cc)
+	assert.Equal(t, 10, st.Params().Int("period"))
+
+	st = NewBasicStrategy(newTestInstrument(), 1, &paramsTestStrategy{})
+	st.SetParams(map[string]float64{"period": 100})
+	assert.NotPanics(t, func() { st.init(cc) })
+	assert.NotNil(t, <-cc.errors)
+	assert.Nil(t, st.Params())
+
+	st = NewBasicStrategy(newTestInstrument(), 1, &DummyStrategy{})
+	st.init(cc)
+	assert.Nil(t, st.Params())
+}
+
+func TestNew_InvalidParams(t *testing.T) {
+	inst := newTestInstrument()
+	st := NewBasicStrategy(inst, 1, &paramsTestStrategy{})
+	st.SetParams(map[string]float64{"period": 100})
+	_, err := New(WithBroker(newTestSimBroker()), WithMarketData(&BTM{}), WithCoreStrategy(st))
+	assert.NotNil(t, err)
+
+	st = NewBasicStrategy(inst, 1, &DummyStrategy{})
+	st.SetParams(map[string]float64{"period": 10})
+	_, err = New(WithBroker(newTestSimBroker()), WithMarketData(&BTM{}), WithCoreStrategy(st))
+	assert.NotNil(t, err)
+
+	st = NewBasicStrategy(inst, 1, &paramsTestStrategy{})
+	st.SetParams(map[string]float64{"period": 10})
+	_, err = New(WithBroker(newTestSimBroker()), WithMarketData(&BTM{}), WithCoreStrategy(st))
+	assert.Nil(t, err)
+}
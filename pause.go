@@ -0,0 +1,111 @@
+package engine
+
+import "sync"
+
+//pauseGate holds market data loop while engine is paused. Steps are events which can pass the paused gate.
+//Changed is closed when state changes, idle waiters are closed when loop stops at the gate.
+type pauseGate struct {
+	mut         sync.Mutex
+	paused      bool
+	steps       int
+	changed     chan struct{}
+	idleWaiters []chan struct{}
+}
+
+func (g *pauseGate) update(paused bool) {
+	g.mut.Lock()
+	defer g.mut.Unlock()
+	g.paused = paused
+	g.steps = 0
+	if !paused {
+		//Loop won't stop at the gate
+		for _, w := range g.idleWaiters {
+			close(w)
+		}
+		g.idleWaiters = nil
+	}
+	g.notify()
+}
+
+//notify wakes up loop waiting at the gate. Caller should hold the lock.
+func (g *pauseGate) notify() {
+	if g.changed != nil {
+		close(g.changed)
+		g.changed = nil
+	}
+}
+
+//acquire blocks while engine is paused and has no steps. It returns false if cancel or stop channel is closed
+//first.
+func (g *pauseGate) acquire(cancel <-chan struct{}, stop <-chan struct{}) bool {
+	for {
+		g.mut.Lock()
+		if !g.paused {
+			g.mut.Unlock()
+			return true
+		}
+		if g.steps > 0 {
+			g.steps--
+			g.mut.Unlock()
+			return true
+		}
+		for _, w := range g.idleWaiters {
+			close(w)
+		}
+		g.idleWaiters = nil
+		if g.changed == nil {
+			g.changed = make(chan struct{})
+		}
+		changed := g.changed
+		g.mut.Unlock()
+
+		select {
+		case <-changed:
+		case <-cancel:
+			return false
+		case <-stop:
+			return false
+		}
+	}
+}
+
+//Pause stops delivery of market data before the next event. Events already delivered are still handled.
+func (c *Engine) Pause() {
+	c.pause.update(true)
+}
+
+//Resume continues delivery of market data after Pause or Step
+func (c *Engine) Resume() {
+	c.pause.update(false)
+}
+
+//IsPaused returns true if market data delivery is paused
+func (c *Engine) IsPaused() bool {
+	c.pause.mut.Lock()
+	defer c.pause.mut.Unlock()
+	return c.pause.paused
+}
+
+//Step delivers one market data event to paused engine and returns when market data loop is paused again or run
+//is finished. Running engine is paused after the next event. Strategy handlers may still run when Step returns
+//unless engine is in deterministic mode.
+func (c *Engine) Step() {
+	c.mut.Lock()
+	c.shutdown.init()
+	done := c.shutdown.done
+	c.mut.Unlock()
+
+	g := &c.pause
+	idle := make(chan struct{})
+	g.mut.Lock()
+	g.paused = true
+	g.steps++
+	g.idleWaiters = append(g.idleWaiters, idle)
+	g.notify()
+	g.mut.Unlock()
+
+	select {
+	case <-idle:
+	case <-done:
+	}
+}
@@ -0,0 +1,65 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngine_PauseStep(t *testing.T) {
+	var calls []string
+	c, _ := newTestShutdownEngine(&calls)
+	c.marketDataChan = make(chan event, 5)
+	mut := &sync.Mutex{}
+	processed := 0
+	c.progressHandler = func(e *BacktestProgressEvent) {
+		mut.Lock()
+		processed++
+		mut.Unlock()
+	}
+	count := func() int {
+		mut.Lock()
+		defer mut.Unlock()
+		return processed
+	}
+
+	c.Pause()
+	assert.True(t, c.IsPaused())
+	for i := 0; i < 4; i++ {
+		c.marketDataChan <- &BacktestProgressEvent{BaseEvent: be(time.Now(), &Instrument{})}
+	}
+	go func() {
+		wg := &sync.WaitGroup{}
+		wg.Add(1)
+		go func() {
+			c.listenEvents()
+			wg.Done()
+		}()
+		c.listendMD()
+		wg.Wait()
+		c.endRun()
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, 0, count())
+	c.Step()
+	assert.Equal(t, 1, count())
+	c.Step()
+	assert.Equal(t, 2, count())
+	assert.True(t, c.IsPaused())
+
+	c.Resume()
+	assert.False(t, c.IsPaused())
+	for i := 0; i < 100 && count() < 4; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, 4, count())
+
+	//Paused engine still shuts down
+	c.Pause()
+	assert.Nil(t, c.Shutdown(context.Background()))
+	assert.Len(t, calls, 1)
+}
@@ -0,0 +1,184 @@
+package engine
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const yearDuration = 365 * 24 * time.Hour
+
+//PerformanceReport is summary of the run built from portfolio snapshots and closed trades of all strategies.
+//Returns and drawdown are fractions of equity. Sharpe and Sortino are annualized from returns of snapshot
+//periods with zero risk free rate. Exposure is fraction of periods which ended with open positions.
+//ProfitFactor is gross profit divided by gross loss of closed trades. Ratios which can't be calculated are NaN.
+//Manifest identifies configuration and data of the run.
+type PerformanceReport struct {
+	Start         time.Time
+	End           time.Time
+	InitialEquity float64
+	FinalEquity   float64
+	TotalReturn   float64
+	CAGR          float64
+	Sharpe        float64
+	Sortino       float64
+	MaxDrawdown   float64
+	Exposure      float64
+	Trades        int
+	WinRate       float64
+	ProfitFactor  float64
+	Manifest      RunManifest
+}
+
+//performanceState keeps report of the finished run and path HTML report is saved to
+type performanceState struct {
+	mut      sync.Mutex
+	report   *PerformanceReport
+	htmlPath string
+}
+
+//closedTradesStrategy is implemented by strategies which keep completed trades
+type closedTradesStrategy interface {
+	ClosedTrades() []ClosedTradeInfo
+}
+
+//ClosedTrades returns completed trades of all strategies of the group
+func (g *StrategyGroup) ClosedTrades() []ClosedTradeInfo {
+	var trades []ClosedTradeInfo
+	g.forEach(func(st *BasicStrategy) { trades = append(trades, st.ClosedTrades()...) })
+	return trades
+}
+
+//PerformanceReport returns report of the run. It's ready after EndOfDataEvent is handled, false is returned
+//before that.
+func (c *Engine) PerformanceReport() (PerformanceReport, bool) {
+	c.performance.mut.Lock()
+	defer c.performance.mut.Unlock()
+	if c.performance.report == nil {
+		return PerformanceReport{}, false
+	}
+	return *c.performance.report, true
+}
+
+//buildPerformanceReport calculates report of the run ended at end. Strategies should be shut down.
+func (c *Engine) buildPerformanceReport(end time.Time) {
+	r := c.portfolio.performance(end, c.ClosedTrades())
+	m, err := c.Manifest()
+	if err != nil {
+		c.logError(err)
+	}
+	r.Manifest = m
+	c.performance.mut.Lock()
+	c.performance.report = &r
+	c.performance.mut.Unlock()
+}
+
+//performance calculates report from equity at start of sampling, snapshots and current equity at end
+func (p *portfolioHandler) performance(end time.Time, trades []ClosedTradeInfo) PerformanceReport {
+	equity := p.Equity()
+	p.mut.RLock()
+	ret := &p.returns
+	r := PerformanceReport{
+		Start:         ret.start,
+		End:           end,
+		InitialEquity: ret.startEquity,
+		FinalEquity:   equity,
+	}
+	snapshots := ret.snapshots
+	period := ret.period
+	if ret.start.IsZero() {
+		r.Start = end
+		r.InitialEquity = p.capital
+	}
+	p.mut.RUnlock()
+	calcPerformance(&r, snapshots, period, trades)
+	return r
+}
+
+//calcPerformance fills metrics of the report with start, end and equities set from snapshots of the period
+//and closed trades
+func calcPerformance(r *PerformanceReport, snapshots []PortfolioSnapshot, period time.Duration,
+	trades []ClosedTradeInfo) {
+	r.TotalReturn, r.CAGR, r.MaxDrawdown = math.NaN(), math.NaN(), math.NaN()
+	if r.InitialEquity > 0 {
+		r.TotalReturn = r.FinalEquity/r.InitialEquity - 1
+		if years := float64(r.End.Sub(r.Start)) / float64(yearDuration); years > 0 && r.FinalEquity >= 0 {
+			r.CAGR = math.Pow(r.FinalEquity/r.InitialEquity, 1/years) - 1
+		}
+		peak := r.InitialEquity
+		r.MaxDrawdown = 0
+		for _, eq := range append(snapshotEquities(snapshots), r.FinalEquity) {
+			if eq > peak {
+				peak = eq
+			}
+			if dd := (peak - eq) / peak; dd > r.MaxDrawdown {
+				r.MaxDrawdown = dd
+			}
+		}
+	}
+
+	r.Sharpe, r.Sortino = annualizedRatios(snapshots, period)
+	r.Exposure = math.NaN()
+	if len(snapshots) > 0 {
+		exposed := 0
+		for _, s := range snapshots {
+			if s.GrossExposure != 0 {
+				exposed++
+			}
+		}
+		r.Exposure = float64(exposed) / float64(len(snapshots))
+	}
+
+	stats := calcTradeStats(trades)
+	r.Trades = stats.Trades
+	r.WinRate, r.ProfitFactor = math.NaN(), math.NaN()
+	if stats.Trades > 0 {
+		r.WinRate = stats.WinRate
+		switch {
+		case stats.GrossLoss < 0:
+			r.ProfitFactor = stats.GrossProfit / -stats.GrossLoss
+		case stats.GrossProfit > 0:
+			r.ProfitFactor = math.Inf(1)
+		}
+	}
+}
+
+func snapshotEquities(snapshots []PortfolioSnapshot) []float64 {
+	equities := make([]float64, 0, len(snapshots))
+	for _, s := range snapshots {
+		equities = append(equities, s.Equity)
+	}
+	return equities
+}
+
+//annualizedRatios returns Sharpe and Sortino ratios of returns of snapshots. Sortino uses downside deviation
+//below zero return. Periods without return are skipped.
+func annualizedRatios(snapshots []PortfolioSnapshot, period time.Duration) (float64, float64) {
+	var returns []float64
+	for _, s := range snapshots {
+		if !math.IsNaN(s.Return) {
+			returns = append(returns, s.Return)
+		}
+	}
+	t := returnsTracker{returns: returns}
+	mean, std := t.meanStdDev()
+	if math.IsNaN(mean) || period <= 0 {
+		return math.NaN(), math.NaN()
+	}
+	scale := math.Sqrt(float64(yearDuration) / float64(period))
+
+	sharpe, sortino := math.NaN(), math.NaN()
+	if std > 0 {
+		sharpe = mean / std * scale
+	}
+	sq := 0.0
+	for _, v := range returns {
+		if v < 0 {
+			sq += v * v
+		}
+	}
+	if downside := math.Sqrt(sq / float64(len(returns))); downside > 0 {
+		sortino = mean / downside * scale
+	}
+	return sharpe, sortino
+}
@@ -0,0 +1,86 @@
+package engine
+
+import (
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPortfolio_performance(t *testing.T) {
+	p := newPortfolio()
+	p.setCapital(10000)
+	day := time.Date(2018, 3, 5, 0, 0, 0, 0, time.UTC)
+	p.sampleReturns(day.Add(10 * time.Hour))
+
+	//Equity goes +10% flat, -10% with position and +10% with position
+	p.cash = 11000
+	p.sampleReturns(day.AddDate(0, 0, 1).Add(10 * time.Hour))
+	p.cash = 4900
+	p.positions["Test"] = PositionInfo{Symbol: "Test", Qty: 100, MarketValue: 5000}
+	p.sampleReturns(day.AddDate(0, 0, 2).Add(10 * time.Hour))
+	p.positions["Test"] = PositionInfo{Symbol: "Test", Qty: 100, MarketValue: 5990}
+	p.sampleReturns(day.AddDate(0, 0, 3).Add(10 * time.Hour))
+
+	trades := []ClosedTradeInfo{{NetPnL: 300}, {NetPnL: -100}, {NetPnL: 0}}
+	r := p.performance(day.AddDate(1, 0, 0), trades)
+	assert.Equal(t, day, r.Start)
+	assert.Equal(t, 10000.0, r.InitialEquity)
+	assert.InDelta(t, 10890.0, r.FinalEquity, 0.0000001)
+	assert.InDelta(t, 0.089, r.TotalReturn, 0.0000001)
+	assert.InDelta(t, 0.089, r.CAGR, 0.0000001)
+	assert.InDelta(t, 0.1, r.MaxDrawdown, 0.0000001)
+	assert.InDelta(t, 2.0/3, r.Exposure, 0.0000001)
+
+	mean := 0.1 / 3
+	std := math.Sqrt((2*(0.1-mean)*(0.1-mean) + (0.1+mean)*(0.1+mean)) / 2)
+	assert.InDelta(t, mean/std*math.Sqrt(365), r.Sharpe, 0.0000001)
+	assert.InDelta(t, mean/math.Sqrt(0.01/3)*math.Sqrt(365), r.Sortino, 0.0000001)
+
+	assert.Equal(t, 3, r.Trades)
+	assert.InDelta(t, 1.0/3, r.WinRate, 0.0000001)
+	assert.InDelta(t, 3.0, r.ProfitFactor, 0.0000001)
+}
+
+func TestPortfolio_performanceWithoutData(t *testing.T) {
+	p := newPortfolio()
+	p.setCapital(10000)
+	end := time.Date(2018, 3, 5, 0, 0, 0, 0, time.UTC)
+	r := p.performance(end, nil)
+	assert.Equal(t, end, r.Start)
+	assert.Equal(t, 0.0, r.TotalReturn)
+	assert.Equal(t, 0.0, r.MaxDrawdown)
+	assert.True(t, math.IsNaN(r.CAGR))
+	assert.True(t, math.IsNaN(r.Sharpe))
+	assert.True(t, math.IsNaN(r.Exposure))
+	assert.True(t, math.IsNaN(r.WinRate))
+	assert.True(t, math.IsNaN(r.ProfitFactor))
+
+	r = p.performance(end, []ClosedTradeInfo{{NetPnL: 10}})
+	assert.True(t, math.IsInf(r.ProfitFactor, 1))
+}
+
+func TestEngine_PerformanceReport(t *testing.T) {
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	st := newTestBasicStrategy()
+	st.closedTrades = append(st.closedTrades, &Trade{Ticker: st.symbol, ClosedPnL: 100, CloseTime: tm})
+	other := NewBasicStrategy(newTestPoolInstrument("Other"), 1, &DummyStrategy{})
+	other.closedTrades = append(other.closedTrades, &Trade{Ticker: other.symbol, ClosedPnL: -50,
+		CloseTime: tm.Add(-time.Hour)})
+	g := NewStrategyGroup(other.symbol)
+	assert.Nil(t, g.Add("a", other))
+
+	c := Engine{strategiesMap: map[string]ICoreStrategy{"Test": st, "Other": g}, portfolio: newPortfolio(),
+		mut: &sync.Mutex{}}
+	_, ok := c.PerformanceReport()
+	assert.False(t, ok)
+
+	c.buildPerformanceReport(tm)
+	r, ok := c.PerformanceReport()
+	assert.True(t, ok)
+	assert.Equal(t, 2, r.Trades)
+	assert.Equal(t, 0.5, r.WinRate)
+	assert.Equal(t, 2.0, r.ProfitFactor)
+}
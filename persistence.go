@@ -0,0 +1,405 @@
+package engine
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+//PortfolioState is saved state of the portfolio used to resume live trading after restart
+type PortfolioState struct {
+	Time       time.Time
+	Capital    float64
+	Cash       float64
+	Strategies map[string]*StrategyState
+}
+
+//StrategyState is saved state of the strategy. ShortTrade is saved only in hedging mode.
+type StrategyState struct {
+	Symbol         string
+	Trade          *TradeState
+	ShortTrade     *TradeState
+	Lots           []TaxLot
+	DividendIncome float64
+	//Members are states of strategies of StrategyGroup by name
+	Members map[string]*StrategyState
+}
+
+//TradeState is saved trade with its working orders
+type TradeState struct {
+	Id             string
+	Type           TradeType
+	Direction      TradeType
+	Qty            int64
+	FirstPrice     float64
+	OpenPrice      float64
+	OpenValue      float64
+	MarketValue    float64
+	OpenTime       time.Time
+	ClosedPnL      float64
+	OpenPnL        float64
+	ExitPrice      float64
+	ExitQty        int64
+	Commission     float64
+	Slippage       float64
+	BorrowCost     float64
+	FinancingCost  float64
+	FinancingDay   time.Time
+	Entries        int
+	LastEntryPrice float64
+	LastEntryTime  time.Time
+	Orders         []WorkingOrder
+}
+
+//WorkingOrder is saved order without instrument. Order gets instrument of the strategy when restored.
+type WorkingOrder struct {
+	Id           string
+	Side         OrderSide
+	Qty          int64
+	ExecQty      int64
+	State        OrderState
+	Price        float64
+	ExecPrice    float64
+	Type         OrderType
+	Tif          OrderTIF
+	Destination  string
+	Mark1        string
+	Mark2        string
+	Time         time.Time
+	PositionSide TradeType
+}
+
+func newWorkingOrder(o *Order) WorkingOrder {
+	return WorkingOrder{
+		Id:           o.Id,
+		Side:         o.Side,
+		Qty:          o.Qty,
+		ExecQty:      o.ExecQty,
+		State:        o.State,
+		Price:        o.Price,
+		ExecPrice:    o.ExecPrice,
+		Type:         o.Type,
+		Tif:          o.Tif,
+		Destination:  o.Destination,
+		Mark1:        o.Mark1,
+		Mark2:        o.Mark2,
+		Time:         o.Time,
+		PositionSide: o.PositionSide,
+	}
+}
+
+func (w *WorkingOrder) order(inst *Instrument) *Order {
+	return &Order{
+		Id:           w.Id,
+		Side:         w.Side,
+		Qty:          w.Qty,
+		ExecQty:      w.ExecQty,
+		Ticker:       inst,
+		State:        w.State,
+		Price:        w.Price,
+		ExecPrice:    w.ExecPrice,
+		Type:         w.Type,
+		Tif:          w.Tif,
+		Destination:  w.Destination,
+		Mark1:        w.Mark1,
+		Mark2:        w.Mark2,
+		Time:         w.Time,
+		PositionSide: w.PositionSide,
+	}
+}
+
+//IPortfolioStorage saves and loads portfolio state. LoadPortfolio returns nil state if nothing was saved yet.
+type IPortfolioStorage interface {
+	SavePortfolio(state *PortfolioState) error
+	LoadPortfolio() (*PortfolioState, error)
+}
+
+//FilePortfolioStorage keeps portfolio state in gob file. Gob is used because prices of market orders are NaN.
+type FilePortfolioStorage struct {
+	Path string
+}
+
+func NewFilePortfolioStorage(path string) *FilePortfolioStorage {
+	return &FilePortfolioStorage{Path: path}
+}
+
+//SavePortfolio writes state to temporary file and renames it, so file is never left half written
+func (s *FilePortfolioStorage) SavePortfolio(state *PortfolioState) error {
+	tmp := s.Path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := gob.NewEncoder(f).Encode(state); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.Path)
+}
+
+func (s *FilePortfolioStorage) LoadPortfolio() (*PortfolioState, error) {
+	f, err := os.Open(s.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var state PortfolioState
+	if err := gob.NewDecoder(f).Decode(&state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+//persistState keeps portfolio storage of the engine and time of the last save
+type persistState struct {
+	storage  IPortfolioStorage
+	interval time.Duration
+	lastSave time.Time
+	lastTime time.Time
+}
+
+//SetPortfolioStorage sets storage of portfolio state. Positions, lots, cash and working orders are restored
+//at start of the run and saved at shutdown and every interval of market time. Zero interval means that state
+//is saved only at shutdown. Restored orders are expected to be known by broker with the same ids.
+func (c *Engine) SetPortfolioStorage(s IPortfolioStorage, interval time.Duration) error {
+	if interval < 0 {
+		return errors.New("Portfolio save interval can't be negative. ")
+	}
+	c.mut.Lock()
+	c.persist = persistState{storage: s, interval: interval}
+	c.mut.Unlock()
+	return nil
+}
+
+//restorePortfolio loads saved state and restores cash and positions of strategies
+func (c *Engine) restorePortfolio() error {
+	c.mut.Lock()
+	storage := c.persist.storage
+	c.mut.Unlock()
+	if storage == nil {
+		return nil
+	}
+	state, err := storage.LoadPortfolio()
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		return nil
+	}
+	c.restorePortfolioState(state)
+	c.logMessage(fmt.Sprintf("Portfolio is restored from state of %v", state.Time))
+	return nil
+}
+
+//restorePortfolioState restores cash, capital and state of strategies
+func (c *Engine) restorePortfolioState(state *PortfolioState) {
+	symbols := make([]string, 0, len(state.Strategies))
+	for s := range state.Strategies {
+		symbols = append(symbols, s)
+	}
+	sort.Strings(symbols)
+	for _, s := range symbols {
+		st, ok := c.findStrategy(s)
+		if !ok {
+			c.logError(fmt.Errorf("Can't restore position of %v. Strategy is not found. ", s))
+			continue
+		}
+		if err := st.restoreState(state.Strategies[s]); err != nil {
+			c.logError(err)
+		}
+	}
+
+	p := c.portfolio
+	p.mut.Lock()
+	p.capital = state.Capital
+	p.cash = state.Cash
+	p.mut.Unlock()
+}
+
+//savePortfolioPeriodically saves state if save interval of market time is passed since the last save
+func (c *Engine) savePortfolioPeriodically(t time.Time) {
+	c.mut.Lock()
+	c.persist.lastTime = t
+	due := c.persist.storage != nil && c.persist.interval > 0 && !t.Before(c.persist.lastSave.Add(c.persist.interval))
+	c.mut.Unlock()
+	if due {
+		c.savePortfolio()
+	}
+}
+
+//savePortfolio saves cash and state of all strategies at time of the last market data
+func (c *Engine) savePortfolio() {
+	c.mut.Lock()
+	storage := c.persist.storage
+	t := c.persist.lastTime
+	c.persist.lastSave = t
+	c.mut.Unlock()
+	if storage == nil {
+		return
+	}
+	state := c.portfolioState(t)
+	if err := storage.SavePortfolio(&state); err != nil {
+		c.logError(err)
+	}
+}
+
+//portfolioState returns cash, capital and state of all strategies
+func (c *Engine) portfolioState(t time.Time) PortfolioState {
+	c.mut.Lock()
+	strategies := make([]ICoreStrategy, 0, len(c.strategiesMap))
+	for _, st := range c.strategiesMap {
+		strategies = append(strategies, st)
+	}
+	c.mut.Unlock()
+
+	state := PortfolioState{
+		Time:       t,
+		Strategies: make(map[string]*StrategyState),
+	}
+	for _, st := range strategies {
+		s := st.saveState()
+		state.Strategies[s.Symbol] = s
+	}
+	p := c.portfolio
+	p.mut.RLock()
+	state.Capital = p.capital
+	state.Cash = p.cash
+	p.mut.RUnlock()
+	return state
+}
+
+func newTradeState(t *Trade) *TradeState {
+	s := TradeState{
+		Id:             t.Id,
+		Type:           t.Type,
+		Direction:      t.Direction,
+		Qty:            t.Qty,
+		FirstPrice:     t.FirstPrice,
+		OpenPrice:      t.OpenPrice,
+		OpenValue:      t.OpenValue,
+		MarketValue:    t.MarketValue,
+		OpenTime:       t.OpenTime,
+		ClosedPnL:      t.ClosedPnL,
+		OpenPnL:        t.OpenPnL,
+		ExitPrice:      t.ExitPrice,
+		ExitQty:        t.ExitQty,
+		Commission:     t.Commission,
+		Slippage:       t.Slippage,
+		BorrowCost:     t.BorrowCost,
+		FinancingCost:  t.FinancingCost,
+		FinancingDay:   t.financingDay,
+		Entries:        t.Entries,
+		LastEntryPrice: t.LastEntryPrice,
+		LastEntryTime:  t.LastEntryTime,
+	}
+	for _, o := range t.ConfirmedOrders {
+		s.Orders = append(s.Orders, newWorkingOrder(o))
+	}
+	sort.Slice(s.Orders, func(i, j int) bool {
+		return s.Orders[i].Id < s.Orders[j].Id
+	})
+	return &s
+}
+
+//trade creates trade of the instrument from saved state
+func (s *TradeState) trade(inst *Instrument) *Trade {
+	t := newFlatTrade(inst)
+	t.Id = s.Id
+	t.Type = s.Type
+	t.Direction = s.Direction
+	t.Qty = s.Qty
+	t.FirstPrice = s.FirstPrice
+	t.OpenPrice = s.OpenPrice
+	t.OpenValue = s.OpenValue
+	t.MarketValue = s.MarketValue
+	t.OpenTime = s.OpenTime
+	t.ClosedPnL = s.ClosedPnL
+	t.OpenPnL = s.OpenPnL
+	t.ExitPrice = s.ExitPrice
+	t.ExitQty = s.ExitQty
+	t.Commission = s.Commission
+	t.Slippage = s.Slippage
+	t.BorrowCost = s.BorrowCost
+	t.FinancingCost = s.FinancingCost
+	t.financingDay = s.FinancingDay
+	t.Entries = s.Entries
+	t.LastEntryPrice = s.LastEntryPrice
+	t.LastEntryTime = s.LastEntryTime
+	for i := range s.Orders {
+		o := s.Orders[i].order(inst)
+		t.ConfirmedOrders[o.Id] = o
+		t.AllOrdersIDMap[o.Id] = struct{}{}
+	}
+	return t
+}
+
+//saveState returns state of open trades, lots and working orders of the strategy
+func (b *BasicStrategy) saveState() *StrategyState {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	s := StrategyState{
+		Symbol:         b.symbol.Symbol,
+		DividendIncome: b.dividendIncome,
+	}
+	if b.currentTrade != nil {
+		s.Trade = newTradeState(b.currentTrade)
+	}
+	if b.shortTrade != nil {
+		s.ShortTrade = newTradeState(b.shortTrade)
+	}
+	for _, l := range b.lots.open {
+		s.Lots = append(s.Lots, *l)
+	}
+	return &s
+}
+
+//restoreState replaces trades of the strategy with saved ones. Strategy should be in the same position mode
+//as it was when state was saved.
+func (b *BasicStrategy) restoreState(s *StrategyState) error {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	if s.Symbol != b.symbol.Symbol {
+		return fmt.Errorf("Can't restore state of %v to strategy of %v. ", s.Symbol, b.symbol.Symbol)
+	}
+	if (s.ShortTrade == nil) != (b.shortTrade == nil) {
+		return fmt.Errorf("Can't restore state of %v. Position mode is different. ", s.Symbol)
+	}
+
+	if s.Trade != nil {
+		b.currentTrade = s.Trade.trade(b.symbol)
+	}
+	if s.ShortTrade != nil {
+		b.shortTrade = s.ShortTrade.trade(b.symbol)
+	}
+	b.lots.open = nil
+	for i := range s.Lots {
+		l := s.Lots[i]
+		b.lots.open = append(b.lots.open, &l)
+	}
+	b.lots.n = len(b.lots.open)
+	b.dividendIncome = s.DividendIncome
+
+	for _, t := range b.trades() {
+		for _, o := range t.ConfirmedOrders {
+			b.trackOrder(o)
+		}
+		if b.portfolio == nil {
+			continue
+		}
+		t.fx = b.portfolio.fx
+		if t.Type != FlatTrade {
+			b.portfolio.onNewTrade(t)
+		}
+	}
+	b.publishPosition()
+	return nil
+}
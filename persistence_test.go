@@ -0,0 +1,82 @@
+package engine
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestPersistEngine(st *BasicStrategy, storage IPortfolioStorage) *Engine {
+	c := Engine{
+		strategiesMap: map[string]ICoreStrategy{st.symbol.Symbol: st},
+		portfolio:     st.portfolio,
+		mut:           &sync.Mutex{},
+		waitG:         &sync.WaitGroup{},
+	}
+	c.log.SetOutput(ioutil.Discard)
+	if err := c.SetPortfolioStorage(storage, 0); err != nil {
+		panic(err)
+	}
+	return &c
+}
+
+func TestFilePortfolioStorage(t *testing.T) {
+	folder, err := ioutil.TempDir("", "portfolio_state")
+	assert.Nil(t, err)
+	defer os.RemoveAll(folder)
+	s := NewFilePortfolioStorage(path.Join(folder, "state.gob"))
+
+	state, err := s.LoadPortfolio()
+	assert.Nil(t, err)
+	assert.Nil(t, state)
+
+	saved := PortfolioState{
+		Cash: 100,
+		Strategies: map[string]*StrategyState{
+			"Sym1": {Symbol: "Sym1", Trade: &TradeState{Type: FlatTrade, OpenPrice: math.NaN(),
+				Orders: []WorkingOrder{{Id: "1", Price: math.NaN(), Type: MarketOrder}}}},
+		},
+	}
+	assert.Nil(t, s.SavePortfolio(&saved))
+	state, err = s.LoadPortfolio()
+	assert.Nil(t, err)
+	assert.Equal(t, 100.0, state.Cash)
+	assert.True(t, math.IsNaN(state.Strategies["Sym1"].Trade.Orders[0].Price))
+}
+
+func TestEngine_restorePortfolio(t *testing.T) {
+	folder, err := ioutil.TempDir("", "portfolio_state")
+	assert.Nil(t, err)
+	defer os.RemoveAll(folder)
+	storage := NewFilePortfolioStorage(path.Join(folder, "state.gob"))
+
+	st := newTestMarginStrategy(10000)
+	st.symbol.InitialMargin, st.symbol.MaintenanceMargin = 0, 0
+	fillTestOrder(st, OrderBuy, 100, 50)
+	id, err := st.NewLimitOrder(45, OrderBuy, 10, GTCTIF, "Sim")
+	assert.Nil(t, err)
+	st.proxyEvent(&OrderConfirmationEvent{BaseEvent: be(st.mostRecentTime, st.symbol), OrdId: id})
+	c := newTestPersistEngine(st, storage)
+	c.savePortfolio()
+
+	//Restart with new strategy and empty portfolio
+	restored := newTestMarginStrategy(0)
+	c = newTestPersistEngine(restored, storage)
+	assert.Nil(t, c.restorePortfolio())
+	assert.Equal(t, int64(100), restored.Position())
+	assert.Equal(t, 50.0, restored.currentTrade.OpenPrice)
+	assert.InDelta(t, 5000.0, restored.Cash(), 0.0000001)
+	assert.InDelta(t, 10000.0, restored.Portfolio().Equity(), 0.0000001)
+	assert.Len(t, restored.OpenLots(), 1)
+
+	info, err := restored.OrderStatus(id)
+	assert.Nil(t, err)
+	assert.Equal(t, ConfirmedOrder, info.State)
+	assert.Equal(t, int64(10), info.LeavesQty)
+	assert.Nil(t, restored.CancelOrder(id))
+}
@@ -0,0 +1,71 @@
+package engine
+
+import (
+	"fmt"
+	"plugin"
+)
+
+//StrategyPluginSymbol is name of function which Go plugin exports to create user strategy:
+//
+//	func NewStrategy() engine.IUserStrategy
+//
+//Plugin should be built with -buildmode=plugin by the same Go version and with the same version of the engine
+//as the binary which loads it. Plugins are supported by Go only on Linux, FreeBSD and macOS.
+const StrategyPluginSymbol = "NewStrategy"
+
+//UserStrategyConstructor creates new user strategy. Every strategy of the engine needs its own one.
+type UserStrategyConstructor func() IUserStrategy
+
+//LoadStrategyPlugin opens Go plugin with user strategy and returns its constructor
+func LoadStrategyPlugin(path string) (UserStrategyConstructor, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("Can't open strategy plugin %v: %v", path, err)
+	}
+	sym, err := p.Lookup(StrategyPluginSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("Strategy plugin %v doesn't export %v: %v", path, StrategyPluginSymbol, err)
+	}
+	return pluginConstructor(path, sym)
+}
+
+//pluginConstructor checks type of exported symbol. Plugin can export function or variable with function.
+func pluginConstructor(path string, sym plugin.Symbol) (UserStrategyConstructor, error) {
+	switch f := sym.(type) {
+	case func() IUserStrategy:
+		return f, nil
+	case *func() IUserStrategy:
+		if f != nil && *f != nil {
+			return *f, nil
+		}
+	case *UserStrategyConstructor:
+		if f != nil && *f != nil {
+			return *f, nil
+		}
+	}
+	return nil, fmt.Errorf("%v of strategy plugin %v has type %T instead of func() IUserStrategy. ",
+		StrategyPluginSymbol, path, sym)
+}
+
+//PluginStrategyFactory returns factory which creates basic strategies with user strategy of the plugin, e.g.
+//for symbols added to universe during the run
+func PluginStrategyFactory(path string, nPeriods int) (StrategyFactory, error) {
+	newStrategy, err := LoadStrategyPlugin(path)
+	if err != nil {
+		return nil, err
+	}
+	return func(symbol *Instrument) ICoreStrategy {
+		return NewBasicStrategy(symbol, nPeriods, newStrategy())
+	}, nil
+}
+
+//WithStrategyPlugin adds basic strategy of the symbol with user strategy loaded from Go plugin
+func WithStrategyPlugin(symbol *Instrument, path string, nPeriods int) Option {
+	return func(c *engineConfig) error {
+		newStrategy, err := LoadStrategyPlugin(path)
+		if err != nil {
+			return err
+		}
+		return WithStrategy(symbol, newStrategy(), nPeriods)(c)
+	}
+}
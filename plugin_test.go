@@ -0,0 +1,39 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPluginConstructor(t *testing.T) {
+	newStrategy := func() IUserStrategy { return &DummyStrategy{} }
+
+	f, err := pluginConstructor("test.so", newStrategy)
+	assert.Nil(t, err)
+	assert.IsType(t, &DummyStrategy{}, f())
+
+	f, err = pluginConstructor("test.so", &newStrategy)
+	assert.Nil(t, err)
+	assert.IsType(t, &DummyStrategy{}, f())
+
+	var constructor UserStrategyConstructor = newStrategy
+	f, err = pluginConstructor("test.so", &constructor)
+	assert.Nil(t, err)
+	assert.NotNil(t, f)
+
+	var empty func() IUserStrategy
+	_, err = pluginConstructor("test.so", &empty)
+	assert.NotNil(t, err)
+	_, err = pluginConstructor("test.so", &DummyStrategy{})
+	assert.NotNil(t, err)
+}
+
+func TestLoadStrategyPlugin(t *testing.T) {
+	_, err := LoadStrategyPlugin("./test_data/no_such_plugin.so")
+	assert.NotNil(t, err)
+	_, err = PluginStrategyFactory("./test_data/no_such_plugin.so", 10)
+	assert.NotNil(t, err)
+	_, err = New(WithStrategyPlugin(newTestInstrument(), "./test_data/no_such_plugin.so", 10))
+	assert.NotNil(t, err)
+}
@@ -1,15 +1,41 @@
 package engine
 
-import "sync"
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
 
 type portfolioHandler struct {
 	trades []*Trade
+	fx     *fxConversion
 	mut    *sync.RWMutex
+
+	capital   float64
+	cash      float64
+	margin    marginState
+	dd        drawdownState
+	interest  interestState
+	financing map[string]FinancingRate
+	alloc     allocationState
+	holding   holdingState
+	returns   returnsTracker
+	curve     equityCurve
+	prices    map[string]*symbolReturns
+	benchmark string
+	positions map[string]PositionInfo
+	//groupPositions are positions of strategies of groups by symbol and name
+	groupPositions map[string]map[string]PositionInfo
 }
 
 func newPortfolio() *portfolioHandler {
 	p := portfolioHandler{}
+	p.fx = newFXConversion()
 	p.mut = &sync.RWMutex{}
+	p.positions = make(map[string]PositionInfo)
+	p.returns = newReturnsTracker()
+	p.prices = make(map[string]*symbolReturns)
 	return &p
 }
 
@@ -59,6 +85,225 @@ func (p *portfolioHandler) ClosedPnL() float64 {
 
 }
 
+//setCapital sets starting capital. Cash is changed by the difference, so fills made before are kept.
+func (p *portfolioHandler) setCapital(capital float64) {
+	p.mut.Lock()
+	p.cash += capital - p.capital
+	p.capital = capital
+	p.mut.Unlock()
+}
+
+//Cash is starting capital plus proceeds of sells minus cost of buys in account currency
+func (p *portfolioHandler) Cash() float64 {
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	return p.cash
+}
+
+//Equity is cash plus market value of open positions in account currency
+func (p *portfolioHandler) Equity() float64 {
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	equity := p.cash
+	for _, v := range p.positions {
+		equity += p.fx.toAccount(v.MarketValue, v.Currency)
+	}
+	return equity
+}
+
+//fillCashFlow returns change of cash by execution in account currency. Buy debits and sell credits cash.
+func (p *portfolioHandler) fillCashFlow(inst *Instrument, side OrderSide, qty int64, price float64) float64 {
+	amount := price * inst.QtyFloat(qty) * inst.multiplier()
+	if inst.FX != nil {
+		amount = p.fx.toAccount(amount, inst.FX.Quote)
+	}
+	if side == OrderBuy {
+		return -amount
+	}
+	return amount
+}
+
 func (p *portfolioHandler) genResults(){
 
 }
+
+//PositionInfo is snapshot of the strategy position. Qty is negative for short position. Values are in
+//quote currency of the instrument, PnL is in account currency. Currency is empty if instrument is quoted in
+//account currency.
+type PositionInfo struct {
+	Symbol      string
+	Currency    string
+	Sector      string
+	Qty         int64
+	OpenPrice   float64
+	MarketValue float64
+	OpenPnL     float64
+	ClosedPnL   float64
+	OpenTime    time.Time
+	Time        time.Time
+	//InitialMargin and MaintenanceMargin are margin requirements of the position in quote currency
+	InitialMargin     float64
+	MaintenanceMargin float64
+	//Strategy is name of the strategy in StrategyGroup. Positions of the portfolio are summed by symbol, so it's
+	//empty there.
+	Strategy string
+}
+
+//IPortfolio is read only view of positions of all strategies. Positions are snapshots published by strategies
+//after every change, so they can be read from any strategy without locking other strategies.
+type IPortfolio interface {
+	Positions() map[string]PositionInfo
+	Position(symbol string) (PositionInfo, bool)
+	GrossExposure() float64
+	NetExposure() float64
+	Cash() float64
+	Equity() float64
+	InitialMargin() float64
+	MaintenanceMargin() float64
+	Leverage() float64
+	PeakEquity() float64
+	Drawdown() float64
+	KillSwitchActive() bool
+	Volatility() float64
+	ParametricVaR(confidence float64) float64
+	HistoricalVaR(confidence float64) float64
+	Snapshots() []PortfolioSnapshot
+	EquityCurve() []EquityPoint
+	DailyReturns() []DailyReturn
+	Correlation(a string, b string) float64
+	Beta(symbol string) float64
+	PortfolioBeta() float64
+	Interest() float64
+	Allocation(symbol string) float64
+	HoldingStats() HoldingStats
+}
+
+func newPositionInfo(t *Trade, tm time.Time) PositionInfo {
+	info := PositionInfo{
+		Symbol:    t.Ticker.Symbol,
+		Sector:    t.Ticker.Sector,
+		ClosedPnL: t.ClosedPnL,
+		Time:      tm,
+	}
+	if t.Ticker.FX != nil {
+		info.Currency = t.Ticker.FX.Quote
+	}
+	if t.Type != LongTrade && t.Type != ShortTrade {
+		return info
+	}
+	info.Qty = t.Qty
+	info.MarketValue = t.MarketValue
+	if t.Type == ShortTrade {
+		info.Qty = -t.Qty
+		info.MarketValue = -t.MarketValue
+	}
+	info.OpenPrice = t.OpenPrice
+	info.OpenPnL = t.OpenPnL
+	info.OpenTime = t.OpenTime
+	info.InitialMargin, info.MaintenanceMargin = positionMargin(t.Ticker, info.MarketValue)
+	return info
+}
+
+func (p *portfolioHandler) updatePosition(info PositionInfo) {
+	p.updatePositionCash(info, 0)
+}
+
+//updatePositionCash updates position snapshot and cash together, so equity is never seen half updated
+func (p *portfolioHandler) updatePositionCash(info PositionInfo, cashFlow float64) {
+	p.mut.Lock()
+	if info.Strategy != "" {
+		info = p.updateGroupPosition(info)
+	}
+	p.positions[info.Symbol] = info
+	p.cash += cashFlow
+	p.mut.Unlock()
+}
+
+//Positions returns copy of last known positions by symbol. Flat positions are included while strategy has
+//closed PnL.
+func (p *portfolioHandler) Positions() map[string]PositionInfo {
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	positions := make(map[string]PositionInfo, len(p.positions))
+	for k, v := range p.positions {
+		positions[k] = v
+	}
+	return positions
+}
+
+func (p *portfolioHandler) Position(symbol string) (PositionInfo, bool) {
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	info, ok := p.positions[symbol]
+	return info, ok
+}
+
+//GrossExposure is sum of absolute market values of all positions
+func (p *portfolioHandler) GrossExposure() float64 {
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	exp := 0.0
+	for _, v := range p.positions {
+		exp += math.Abs(v.MarketValue)
+	}
+	return exp
+}
+
+//NetExposure is sum of market values of long positions minus short positions
+func (p *portfolioHandler) NetExposure() float64 {
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	exp := 0.0
+	for _, v := range p.positions {
+		exp += v.MarketValue
+	}
+	return exp
+}
+
+//onPortfolioEvent queues account event, like margin call, for delivery to all strategies with the next market data
+func (c *Engine) onPortfolioEvent(e event) {
+	c.logMessage(e.String())
+	c.mut.Lock()
+	c.portfolioEvents = append(c.portfolioEvents, e)
+	c.mut.Unlock()
+}
+
+//firePortfolioEvents delivers queued account events to all strategies in symbol order
+func (c *Engine) firePortfolioEvents() {
+	c.mut.Lock()
+	queued := c.portfolioEvents
+	c.portfolioEvents = nil
+	symbols := make([]string, 0, len(c.strategiesMap))
+	for s := range c.strategiesMap {
+		symbols = append(symbols, s)
+	}
+	c.mut.Unlock()
+	if len(queued) == 0 {
+		return
+	}
+	sort.Strings(symbols)
+	for _, e := range queued {
+		for _, s := range symbols {
+			st, ok := c.findStrategy(s)
+			if !ok {
+				continue
+			}
+			st.notify(copyPortfolioEvent(e, st.getInstrument()))
+		}
+	}
+}
+
+//copyPortfolioEvent returns copy of account event for the strategy of the instrument
+func copyPortfolioEvent(e event, inst *Instrument) event {
+	switch i := e.(type) {
+	case *MarginCallEvent:
+		copied := *i
+		copied.Ticker = inst
+		return &copied
+	case *PortfolioKillSwitchEvent:
+		copied := *i
+		copied.Ticker = inst
+		return &copied
+	}
+	return e
+}
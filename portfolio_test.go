@@ -0,0 +1,94 @@
+package engine
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestBasicStrategy_Portfolio(t *testing.T) {
+	p := newPortfolio()
+	st := newTestBasicStrategy()
+	st.setPortfolio(p)
+	go func() {
+		for range st.ch.events {
+		}
+	}()
+	go func() {
+		for range st.ch.errors {
+		}
+	}()
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	st.mostRecentTime = tm
+
+	other := newFlatTrade(&Instrument{Symbol: "Other"})
+	other.Type = LongTrade
+	other.Qty = 10
+	other.OpenPrice = 20
+	other.MarketValue = 210
+	other.OpenPnL = 10
+	p.updatePosition(newPositionInfo(other, tm))
+
+	id, err := st.NewLimitOrder(50, OrderSell, 100, GTCTIF, "Sim")
+	assert.Nil(t, err)
+	st.proxyEvent(&OrderConfirmationEvent{BaseEvent: be(tm, st.symbol), OrdId: id})
+	st.proxyEvent(&OrderFillEvent{BaseEvent: be(tm, st.symbol), OrdId: id, Price: 50, Qty: 100})
+
+	positions := st.Portfolio().Positions()
+	assert.Len(t, positions, 2)
+	pos := positions["Test"]
+	assert.Equal(t, int64(-100), pos.Qty)
+	assert.Equal(t, 50.0, pos.OpenPrice)
+	assert.Equal(t, -5000.0, pos.MarketValue)
+	assert.Equal(t, tm, pos.Time)
+
+	assert.InDelta(t, 5210.0, st.Portfolio().GrossExposure(), 0.0000001)
+	assert.InDelta(t, -4790.0, st.Portfolio().NetExposure(), 0.0000001)
+
+	//Snapshot is a copy, so changes of the map don't affect portfolio
+	positions["Other"] = PositionInfo{}
+	info, ok := st.Portfolio().Position("Other")
+	assert.True(t, ok)
+	assert.Equal(t, int64(10), info.Qty)
+
+	_, ok = st.Portfolio().Position("Unknown")
+	assert.False(t, ok)
+	st.shutDown()
+}
+
+func TestPortfolio_Cash(t *testing.T) {
+	p := newPortfolio()
+	st := newTestRiskStrategy()
+	st.setPortfolio(p)
+	p.setCapital(100000)
+	st.mostRecentTime = time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, 100000.0, st.Cash())
+	assert.Equal(t, 100000.0, st.Equity())
+
+	fillTestOrder(st, OrderBuy, 100, 50)
+	assert.InDelta(t, 95000.0, st.Cash(), 0.0000001)
+	assert.InDelta(t, 100000.0, st.Equity(), 0.0000001)
+
+	//Marks change equity, but not cash
+	st.notify(newTestCandleCloseEvent(52, 52, 52, 52, st.mostRecentTime.Add(time.Minute), "1"))
+	st.shutDown()
+	assert.InDelta(t, 95000.0, st.Cash(), 0.0000001)
+	assert.InDelta(t, 100200.0, st.Equity(), 0.0000001)
+
+	//Reversal credits proceeds of the whole sell
+	fillTestOrder(st, OrderSell, 300, 53)
+	assert.InDelta(t, 110900.0, st.Cash(), 0.0000001)
+	assert.InDelta(t, 100300.0, st.Equity(), 0.0000001)
+	assert.InDelta(t, 100000+st.strategyPnL(), st.Equity(), 0.0000001)
+
+	//Roll closes short at old contract price and opens at new one
+	st.proxyEvent(&RollEvent{BaseEvent: be(st.mostRecentTime, st.symbol), FromPrice: 53, ToPrice: 55})
+	st.shutDown()
+	assert.InDelta(t, 111300.0, st.Cash(), 0.0000001)
+	assert.InDelta(t, 100300.0, st.Equity(), 0.0000001)
+
+	//Capital set later keeps cash flows
+	p.setCapital(50000)
+	assert.InDelta(t, 61300.0, st.Cash(), 0.0000001)
+	assert.InDelta(t, 61300.0, st.Portfolio().Cash(), 0.0000001)
+}
@@ -0,0 +1,75 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+//PyramidingRules control adding to open position. Zero value of the rule disables it. Rules are checked
+//for orders which are sent while position is open and have the same side as position.
+type PyramidingRules struct {
+	//MaxEntries is max number of entries of the trade including the first one, so 1 forbids adding to position.
+	//Working orders which add to position are counted as entries.
+	MaxEntries int
+	//MinSpacing is min price distance between the last entry of the trade and the new order. Last price is
+	//used for market orders.
+	MinSpacing float64
+	//MinInterval is min time between the last entry of the trade and the new order
+	MinInterval time.Duration
+}
+
+//SetPyramiding sets rules of adding to open position for the current and next trades
+func (b *BasicStrategy) SetPyramiding(rules PyramidingRules) error {
+	if rules.MaxEntries < 0 || rules.MinSpacing < 0 || rules.MinInterval < 0 || math.IsNaN(rules.MinSpacing) {
+		return errors.New("Pyramiding rules can't be negative or NaN. ")
+	}
+	b.pyramiding = rules
+	return nil
+}
+
+//isAddOn returns true if order adds to open position
+func (b *BasicStrategy) isAddOn(order *Order) bool {
+	t := b.tradeOfSide(positionSideOf(order))
+	return (t.Type == LongTrade && order.Side == OrderBuy) || (t.Type == ShortTrade && order.Side == OrderSell)
+}
+
+func (b *BasicStrategy) checkPyramiding(order *Order) error {
+	if !b.isAddOn(order) {
+		return nil
+	}
+	t := b.tradeOfSide(positionSideOf(order))
+	r := b.pyramiding
+
+	if r.MaxEntries > 0 {
+		entries := t.Entries + 1
+		for _, orders := range []map[string]*Order{t.NewOrders, t.ConfirmedOrders} {
+			for _, o := range orders {
+				//Partially filled orders are already counted by trade
+				if o.Side == order.Side && o.ExecQty == 0 && !b.isExitOrder(o.Id) {
+					entries++
+				}
+			}
+		}
+		if entries > r.MaxEntries {
+			return fmt.Errorf("Order can make %v entries of the trade. Max is %v. ", entries, r.MaxEntries)
+		}
+	}
+
+	if r.MinSpacing > 0 {
+		price := order.Price
+		if order.Type == MarketOrder || order.Type == MarketOnOpen || order.Type == MarketOnClose {
+			price = b.lastPrice()
+		}
+		if !math.IsNaN(price) && price > 0 && math.Abs(price-t.LastEntryPrice) < r.MinSpacing {
+			return fmt.Errorf("Order price %v is closer than %v to the last entry %v. ", price, r.MinSpacing,
+				t.LastEntryPrice)
+		}
+	}
+
+	if r.MinInterval > 0 && b.mostRecentTime.Sub(t.LastEntryTime) < r.MinInterval {
+		return fmt.Errorf("Last entry was less than %v ago. ", r.MinInterval)
+	}
+	return nil
+}
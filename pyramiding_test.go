@@ -0,0 +1,64 @@
+package engine
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestBasicStrategy_SetPyramiding(t *testing.T) {
+	st := newTestRiskStrategy()
+	st.mostRecentTime = time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	assert.NotNil(t, st.SetPyramiding(PyramidingRules{MaxEntries: -1}))
+	assert.Nil(t, st.SetPyramiding(PyramidingRules{MaxEntries: 2, MinSpacing: 0.5, MinInterval: time.Minute}))
+
+	fillTestOrder(st, OrderBuy, 100, 50)
+	assert.Equal(t, 1, st.currentTrade.Entries)
+
+	//Too early
+	_, err := st.NewLimitOrder(49, OrderBuy, 100, GTCTIF, "Sim")
+	assert.NotNil(t, err)
+
+	st.mostRecentTime = st.mostRecentTime.Add(time.Minute)
+	//Too close to the last entry
+	_, err = st.NewLimitOrder(49.8, OrderBuy, 100, GTCTIF, "Sim")
+	assert.NotNil(t, err)
+	//Orders which reduce position are not checked
+	_, err = st.NewLimitOrder(50.1, OrderSell, 100, GTCTIF, "Sim")
+	assert.Nil(t, err)
+
+	_, err = st.NewLimitOrder(49, OrderBuy, 100, GTCTIF, "Sim")
+	assert.Nil(t, err)
+	//Working add-on order is counted as entry
+	_, err = st.NewLimitOrder(48, OrderBuy, 100, GTCTIF, "Sim")
+	assert.NotNil(t, err)
+
+	//Partial fills of the same order are one entry
+	st.SetPyramiding(PyramidingRules{MaxEntries: 3})
+	id, err := st.NewMarketOrder(OrderBuy, 200, DayTIF, "Sim")
+	assert.Nil(t, err)
+	st.proxyEvent(&OrderConfirmationEvent{BaseEvent: be(st.mostRecentTime, st.symbol), OrdId: id})
+	st.proxyEvent(&OrderFillEvent{BaseEvent: be(st.mostRecentTime, st.symbol), OrdId: id, Price: 51, Qty: 100})
+	st.proxyEvent(&OrderFillEvent{BaseEvent: be(st.mostRecentTime, st.symbol), OrdId: id, Price: 52, Qty: 100})
+	assert.Equal(t, 2, st.currentTrade.Entries)
+	assert.Equal(t, 52.0, st.currentTrade.LastEntryPrice)
+	assert.Equal(t, st.mostRecentTime, st.currentTrade.LastEntryTime)
+}
+
+func TestBasicStrategy_pyramidingNewTrade(t *testing.T) {
+	st := newTestRiskStrategy()
+	st.mostRecentTime = time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	assert.Nil(t, st.SetPyramiding(PyramidingRules{MaxEntries: 1}))
+
+	fillTestOrder(st, OrderBuy, 100, 50)
+	_, err := st.NewMarketOrder(OrderBuy, 100, DayTIF, "Sim")
+	assert.NotNil(t, err)
+
+	//Reversal opens new trade with one entry
+	fillTestOrder(st, OrderSell, 300, 51)
+	assert.Equal(t, ShortTrade, st.currentTrade.Type)
+	assert.Equal(t, 1, st.currentTrade.Entries)
+	_, err = st.NewMarketOrder(OrderSell, 100, DayTIF, "Sim")
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, st.ClosedTrades()[0].Entries)
+}
@@ -0,0 +1,161 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+const rebalanceTimerName = "$Rebalance$"
+
+//Rebalancer moves positions of multi symbol strategy to target weights. Weight is fraction of portfolio equity
+//in position market value, negative weight is short position. Symbols of the basket without weight are
+//closed. Target qty is rounded down to lot size and limited by MaxPosition risk limit of the symbol strategy.
+//Orders are sent with target positions, so strategy shouldn't send other orders of the basket symbols.
+type Rebalancer struct {
+	m           *MultiSymbolStrategy
+	weights     map[string]float64
+	destination string
+	//MinTradeValue is min market value of position change. Smaller changes are skipped to save commissions.
+	MinTradeValue float64
+}
+
+//NewRebalancer creates rebalancer of the basket. Rebalancing is done on schedule set with ScheduleDaily or
+//ScheduleEvery and on every Rebalance call.
+func NewRebalancer(m *MultiSymbolStrategy, destination string) *Rebalancer {
+	r := Rebalancer{
+		m:           m,
+		weights:     make(map[string]float64),
+		destination: destination,
+	}
+	m.rebalancer = &r
+	return &r
+}
+
+//SetWeights sets target weights of the symbols. It doesn't send orders until next rebalance.
+func (r *Rebalancer) SetWeights(weights map[string]float64) error {
+	total := 0.0
+	for s, w := range weights {
+		if _, err := r.m.leg(s); err != nil {
+			return err
+		}
+		if math.IsNaN(w) || math.IsInf(w, 0) {
+			return fmt.Errorf("Weight of %v is not a number. ", s)
+		}
+		total += math.Abs(w)
+	}
+	if total == 0 && len(weights) > 0 {
+		return errors.New("All weights are zero. ")
+	}
+	r.weights = make(map[string]float64, len(weights))
+	for s, w := range weights {
+		r.weights[s] = w
+	}
+	return nil
+}
+
+//Weights returns copy of target weights
+func (r *Rebalancer) Weights() map[string]float64 {
+	weights := make(map[string]float64, len(r.weights))
+	for s, w := range r.weights {
+		weights[s] = w
+	}
+	return weights
+}
+
+//ScheduleDaily rebalances basket at given exchange time of every trading day of the first basket symbol
+func (r *Rebalancer) ScheduleDaily(at TimeOfDay) error {
+	leg, err := r.scheduleLeg()
+	if err != nil {
+		return err
+	}
+	leg.AddDailyTimer(rebalanceTimerName, at)
+	return nil
+}
+
+//ScheduleEvery rebalances basket with given interval
+func (r *Rebalancer) ScheduleEvery(every time.Duration) error {
+	leg, err := r.scheduleLeg()
+	if err != nil {
+		return err
+	}
+	return leg.AddTimer(rebalanceTimerName, every)
+}
+
+func (r *Rebalancer) scheduleLeg() (*BasicStrategy, error) {
+	if len(r.m.symbols) == 0 {
+		return nil, errors.New("Multi symbol strategy has no symbols. ")
+	}
+	return r.m.legs[r.m.symbols[0]], nil
+}
+
+//Targets returns target positions of all basket symbols at current equity and last prices. Symbols without
+//price are not included.
+func (r *Rebalancer) Targets() (map[string]int64, error) {
+	leg, err := r.scheduleLeg()
+	if err != nil {
+		return nil, err
+	}
+	if leg.portfolio == nil {
+		return nil, errors.New("Rebalancer needs portfolio to get equity. ")
+	}
+	equity := leg.Equity()
+
+	targets := make(map[string]int64, len(r.m.symbols))
+	for _, s := range r.m.symbols {
+		leg := r.m.legs[s]
+		price := leg.lastPrice()
+		if math.IsNaN(price) || price <= 0 {
+			continue
+		}
+		w := r.weights[s]
+		qty := leg.SizeMaxNotional(math.Abs(w)*equity, price)
+		if limit := leg.risk.limits.MaxPosition; limit > 0 && qty > limit {
+			qty = leg.roundToLot(float64(limit))
+		}
+		if w < 0 {
+			qty = -qty
+		}
+		targets[s] = qty
+	}
+	return targets, nil
+}
+
+//Rebalance sets target positions of the basket symbols. Symbols which have no price yet are skipped.
+func (r *Rebalancer) Rebalance() error {
+	targets, err := r.Targets()
+	if err != nil {
+		return err
+	}
+	for _, s := range r.m.symbols {
+		qty, ok := targets[s]
+		if !ok {
+			continue
+		}
+		leg := r.m.legs[s]
+		pos := leg.Position()
+		if leg.target != nil {
+			pos = leg.target.qty
+		}
+		if qty == pos {
+			continue
+		}
+		if r.MinTradeValue > 0 && qty != 0 && math.Abs(leg.unitValue(leg.lastPrice())*float64(qty-pos)) < r.MinTradeValue {
+			continue
+		}
+		if err := leg.SetTargetPosition(qty, r.destination); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MultiSymbolStrategy) onRebalanceTimer(b *BasicStrategy) {
+	if m.rebalancer == nil {
+		return
+	}
+	if err := m.rebalancer.Rebalance(); err != nil {
+		b.newError(err)
+	}
+}
@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type rebalanceTestStrategy struct{}
+
+func (s *rebalanceTestStrategy) OnTick(m *MultiSymbolStrategy, b *BasicStrategy, tick *Tick) {}
+
+func (s *rebalanceTestStrategy) OnCandleOpen(m *MultiSymbolStrategy, b *BasicStrategy, price float64) {
+}
+
+func (s *rebalanceTestStrategy) OnCandleClose(m *MultiSymbolStrategy, b *BasicStrategy, candle *Candle) {
+}
+
+func TestRebalancer(t *testing.T) {
+	symA := newTestInstrument()
+	symA.Symbol = "A"
+	symA.LotSize = 1
+	symB := newTestInstrument()
+	symB.Symbol = "B"
+	symB.LotSize = 10
+	symC := newTestInstrument()
+	symC.Symbol = "C"
+
+	m := NewMultiSymbolStrategy([]*Instrument{symA, symB, symC}, 1, &rebalanceTestStrategy{})
+	r := NewRebalancer(m, "Sim")
+	_, err := r.Targets()
+	assert.NotNil(t, err)
+
+	events := make(chan event, 20)
+	cc := CoreStrategyChannels{errors: make(chan error, 10), events: events,
+		portfolio: make(chan *PortfolioNewPositionEvent, 10)}
+	p := newPortfolio()
+	p.setCapital(10000)
+	for _, st := range m.Strategies() {
+		st.init(cc)
+		st.setPortfolio(p)
+	}
+
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	for inst, price := range map[*Instrument]float64{symA: 10, symB: 20} {
+		e := newTestCandleCloseEvent(price, price, price, price, tm, "1")
+		e.Candle.Ticker = inst
+		e.Ticker = inst
+		m.Strategy(inst.Symbol).notify(e)
+		m.Strategy(inst.Symbol).shutDown()
+	}
+
+	assert.NotNil(t, r.SetWeights(map[string]float64{"D": 0.5}))
+	assert.Nil(t, r.SetWeights(map[string]float64{"A": 0.5, "B": -0.33}))
+	targets, err := r.Targets()
+	assert.Nil(t, err)
+	//C has no price yet
+	assert.Equal(t, map[string]int64{"A": 500, "B": -160}, targets)
+
+	m.Strategy("A").SetRiskLimits(RiskLimits{MaxPosition: 300})
+	assert.Nil(t, r.ScheduleEvery(time.Hour))
+	m.Strategy("A").notify(&TimerEvent{BaseEvent: be(tm.Add(time.Hour), symA), Name: rebalanceTimerName})
+	m.Strategy("A").shutDown()
+
+	orders := make(map[string]*Order)
+	for len(events) > 0 {
+		if e, ok := (<-events).(*NewOrderEvent); ok {
+			orders[e.LinkedOrder.Ticker.Symbol] = e.LinkedOrder
+		}
+	}
+	assert.Len(t, orders, 2)
+	assert.Equal(t, OrderBuy, orders["A"].Side)
+	assert.Equal(t, int64(300), orders["A"].Qty)
+	assert.Equal(t, OrderSell, orders["B"].Side)
+	assert.Equal(t, int64(160), orders["B"].Qty)
+
+	//Small changes are skipped
+	r.MinTradeValue = 500
+	assert.Nil(t, r.SetWeights(map[string]float64{"A": 0.5, "B": -0.35}))
+	assert.Nil(t, r.Rebalance())
+	assert.Equal(t, int64(-160), m.Strategy("B").target.qty)
+	assert.Nil(t, r.SetWeights(map[string]float64{"A": 0.5, "B": -0.4}))
+	assert.Nil(t, r.Rebalance())
+	assert.Equal(t, int64(-200), m.Strategy("B").target.qty)
+}
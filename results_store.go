@@ -0,0 +1,253 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const runResultExt = ".run.json"
+
+//RunResult is stored outcome of the named run
+type RunResult struct {
+	Name        string
+	Saved       time.Time
+	Performance PerformanceReport
+	Stats       TradeStats
+	Equity      []EquityPoint
+}
+
+//ResultsStore keeps results of named runs, so runs of strategy versions can be compared. Results are kept in
+//memory and, if store has folder, in JSON files of the folder, one per run.
+type ResultsStore struct {
+	mut    sync.RWMutex
+	folder string
+	runs   map[string]*RunResult
+}
+
+//NewResultsStore creates store of results in folder and loads runs saved there before. Empty folder makes
+//store which keeps results in memory only.
+func NewResultsStore(folder string) (*ResultsStore, error) {
+	s := &ResultsStore{folder: folder, runs: make(map[string]*RunResult)}
+	if folder == "" {
+		return s, nil
+	}
+	if err := createDirIfNotExists(folder); err != nil {
+		return nil, err
+	}
+	files, err := ioutil.ReadDir(folder)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), runResultExt) {
+			continue
+		}
+		r, err := readRunResult(path.Join(folder, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		s.runs[r.Name] = r
+	}
+	return s, nil
+}
+
+//Add stores result of the finished run of the engine under the name. Result with the same name is replaced.
+func (s *ResultsStore) Add(name string, e *Engine) (RunResult, error) {
+	perf, ok := e.PerformanceReport()
+	if !ok {
+		return RunResult{}, errors.New("Run isn't finished. ")
+	}
+	r := RunResult{
+		Name:        name,
+		Saved:       time.Now(),
+		Performance: perf,
+		Stats:       calcTradeStats(e.ClosedTrades()),
+		Equity:      e.portfolio.EquityCurve(),
+	}
+	return r, s.Put(r)
+}
+
+//Put stores result under its name. Name can't contain path separators.
+func (s *ResultsStore) Put(r RunResult) error {
+	if r.Name == "" || strings.ContainsAny(r.Name, `/\`) {
+		return fmt.Errorf("Invalid run name %q. ", r.Name)
+	}
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if s.folder != "" {
+		if err := writeRunResult(path.Join(s.folder, r.Name+runResultExt), &r); err != nil {
+			return err
+		}
+	}
+	s.runs[r.Name] = &r
+	return nil
+}
+
+//Get returns result of the run
+func (s *ResultsStore) Get(name string) (RunResult, bool) {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+	r, ok := s.runs[name]
+	if !ok {
+		return RunResult{}, false
+	}
+	return *r, true
+}
+
+//Names returns names of stored runs in alphabetical order
+func (s *ResultsStore) Names() []string {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+	names := make([]string, 0, len(s.runs))
+	for n := range s.runs {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//Remove deletes result of the run from the store and its folder
+func (s *ResultsStore) Remove(name string) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if _, ok := s.runs[name]; !ok {
+		return fmt.Errorf("Run %v is not found. ", name)
+	}
+	if s.folder != "" {
+		if err := os.Remove(path.Join(s.folder, name+runResultExt)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	delete(s.runs, name)
+	return nil
+}
+
+//MetricDelta is difference of the metric of two runs, Other minus Base
+type MetricDelta struct {
+	Metric string
+	Base   float64
+	Other  float64
+	Delta  float64
+}
+
+//EquityOverlay is equity of both runs at the time. Equity of the run without point at the time is its last
+//equity before it, NaN before the first point.
+type EquityOverlay struct {
+	Time  time.Time
+	Base  float64
+	Other float64
+}
+
+//RunComparison is difference of the other run from the base one
+type RunComparison struct {
+	Base        string
+	Other       string
+	Metrics     []MetricDelta
+	TradesDelta int
+	Equity      []EquityOverlay
+}
+
+//Compare returns metric deltas, change of number of trades and equity curves of two runs on common time line
+func (s *ResultsStore) Compare(base string, other string) (RunComparison, error) {
+	b, ok := s.Get(base)
+	if !ok {
+		return RunComparison{}, fmt.Errorf("Run %v is not found. ", base)
+	}
+	o, ok := s.Get(other)
+	if !ok {
+		return RunComparison{}, fmt.Errorf("Run %v is not found. ", other)
+	}
+	c := RunComparison{
+		Base:        base,
+		Other:       other,
+		TradesDelta: o.Stats.Trades - b.Stats.Trades,
+		Equity:      overlayEquity(b.Equity, o.Equity),
+	}
+	for _, m := range []struct {
+		name        string
+		base, other float64
+	}{
+		{"FinalEquity", b.Performance.FinalEquity, o.Performance.FinalEquity},
+		{"TotalReturn", b.Performance.TotalReturn, o.Performance.TotalReturn},
+		{"CAGR", b.Performance.CAGR, o.Performance.CAGR},
+		{"Sharpe", b.Performance.Sharpe, o.Performance.Sharpe},
+		{"Sortino", b.Performance.Sortino, o.Performance.Sortino},
+		{"MaxDrawdown", b.Performance.MaxDrawdown, o.Performance.MaxDrawdown},
+		{"Exposure", b.Performance.Exposure, o.Performance.Exposure},
+		{"WinRate", b.Performance.WinRate, o.Performance.WinRate},
+		{"ProfitFactor", b.Performance.ProfitFactor, o.Performance.ProfitFactor},
+		{"Expectancy", b.Stats.Expectancy, o.Stats.Expectancy},
+		{"NetPnL", b.Stats.GrossProfit + b.Stats.GrossLoss, o.Stats.GrossProfit + o.Stats.GrossLoss},
+	} {
+		c.Metrics = append(c.Metrics, MetricDelta{Metric: m.name, Base: m.base, Other: m.other,
+			Delta: m.other - m.base})
+	}
+	return c, nil
+}
+
+//overlayEquity merges equity curves by time
+func overlayEquity(base []EquityPoint, other []EquityPoint) []EquityOverlay {
+	var points []EquityOverlay
+	lastBase, lastOther := math.NaN(), math.NaN()
+	i, j := 0, 0
+	for i < len(base) || j < len(other) {
+		var t time.Time
+		switch {
+		case j == len(other) || i < len(base) && base[i].Time.Before(other[j].Time):
+			t = base[i].Time
+		default:
+			t = other[j].Time
+		}
+		if i < len(base) && base[i].Time.Equal(t) {
+			lastBase = base[i].Equity
+			i++
+		}
+		if j < len(other) && other[j].Time.Equal(t) {
+			lastOther = other[j].Equity
+			j++
+		}
+		points = append(points, EquityOverlay{Time: t, Base: lastBase, Other: lastOther})
+	}
+	return points
+}
+
+func writeRunResult(pth string, r *RunResult) error {
+	v, err := encodeValue(reflect.ValueOf(r))
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(pth, data, 0644)
+}
+
+func readRunResult(pth string) (*RunResult, error) {
+	data, err := ioutil.ReadFile(pth)
+	if err != nil {
+		return nil, err
+	}
+	var raw interface{}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	if err := d.Decode(&raw); err != nil {
+		return nil, err
+	}
+	var r RunResult
+	if err := decodeValue(raw, reflect.ValueOf(&r).Elem()); err != nil {
+		return nil, fmt.Errorf("Can't read run result %v: %v", pth, err)
+	}
+	return &r, nil
+}
@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOverlayEquity(t *testing.T) {
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	base := []EquityPoint{{Time: tm, Equity: 100}, {Time: tm.Add(2 * time.Hour), Equity: 110}}
+	other := []EquityPoint{{Time: tm.Add(time.Hour), Equity: 200}, {Time: tm.Add(2 * time.Hour), Equity: 190},
+		{Time: tm.Add(3 * time.Hour), Equity: 210}}
+
+	points := overlayEquity(base, other)
+	if assert.Len(t, points, 4) {
+		assert.Equal(t, tm, points[0].Time)
+		assert.Equal(t, 100.0, points[0].Base)
+		assert.True(t, math.IsNaN(points[0].Other))
+		assert.Equal(t, EquityOverlay{Time: tm.Add(time.Hour), Base: 100, Other: 200}, points[1])
+		assert.Equal(t, EquityOverlay{Time: tm.Add(2 * time.Hour), Base: 110, Other: 190}, points[2])
+		assert.Equal(t, EquityOverlay{Time: tm.Add(3 * time.Hour), Base: 110, Other: 210}, points[3])
+	}
+	assert.Nil(t, overlayEquity(nil, nil))
+}
+
+func TestResultsStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "results")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	s, err := NewResultsStore(dir)
+	assert.Nil(t, err)
+
+	c := newTestEquityEngine()
+	_, err = s.Add("base", c)
+	assert.NotNil(t, err)
+
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	c.portfolio.recordEquity(tm)
+	c.buildPerformanceReport(tm)
+	base, err := s.Add("base", c)
+	assert.Nil(t, err)
+	assert.True(t, math.IsNaN(base.Performance.Sharpe))
+
+	other := RunResult{Name: "other", Performance: PerformanceReport{FinalEquity: 10500, Sharpe: 1.5},
+		Stats:  TradeStats{Trades: 3, GrossProfit: 700, GrossLoss: -200},
+		Equity: []EquityPoint{{Time: tm, Equity: 10000}, {Time: tm.Add(time.Hour), Equity: 10500}}}
+	assert.Nil(t, s.Put(other))
+	assert.NotNil(t, s.Put(RunResult{Name: "../other"}))
+	assert.NotNil(t, s.Put(RunResult{}))
+
+	//Results are loaded from the folder
+	s, err = NewResultsStore(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"base", "other"}, s.Names())
+	loaded, ok := s.Get("base")
+	assert.True(t, ok)
+	assert.True(t, math.IsNaN(loaded.Performance.ProfitFactor))
+	assert.True(t, loaded.Performance.End.Equal(tm))
+
+	cmp, err := s.Compare("base", "other")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, cmp.TradesDelta)
+	metrics := make(map[string]MetricDelta)
+	for _, m := range cmp.Metrics {
+		metrics[m.Metric] = m
+	}
+	assert.Equal(t, MetricDelta{Metric: "FinalEquity", Base: 10000, Other: 10500, Delta: 500},
+		metrics["FinalEquity"])
+	assert.Equal(t, 500.0, metrics["NetPnL"].Delta)
+	assert.True(t, math.IsNaN(metrics["Sharpe"].Delta))
+	if assert.Len(t, cmp.Equity, 2) {
+		assert.Equal(t, 10000.0, cmp.Equity[1].Base)
+		assert.Equal(t, 10500.0, cmp.Equity[1].Other)
+	}
+
+	assert.Nil(t, s.Remove("other"))
+	assert.NotNil(t, s.Remove("other"))
+	_, err = s.Compare("base", "other")
+	assert.NotNil(t, err)
+	s, err = NewResultsStore(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"base"}, s.Names())
+}
@@ -0,0 +1,145 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+//RiskLimits are limits of the strategy. Zero value of the limit disables it. When limit is breached strategy
+//is halted: new orders which don't reduce position are rejected and working orders are canceled.
+type RiskLimits struct {
+	//MaxDailyLoss is max loss of the strategy since start of the exchange day, including open PnL
+	MaxDailyLoss float64
+	//MaxConsecutiveLosers is max number of losing closed trades in a row
+	MaxConsecutiveLosers int
+	//MaxPosition is max absolute position. Orders which can make position larger are rejected too.
+	MaxPosition int64
+}
+
+//riskState keeps data of daily loss limit and halt state
+type riskState struct {
+	limits      RiskLimits
+	day         time.Time
+	dayStartPnL float64
+	halted      bool
+	reason      string
+}
+
+//SetRiskLimits sets limits of the strategy. Limits are checked every time position or its PnL changes.
+func (b *BasicStrategy) SetRiskLimits(limits RiskLimits) error {
+	if limits.MaxDailyLoss < 0 || limits.MaxConsecutiveLosers < 0 || limits.MaxPosition < 0 {
+		return errors.New("Risk limits can't be negative. ")
+	}
+	b.risk.limits = limits
+	b.checkRiskLimits()
+	return nil
+}
+
+//IsHalted returns true if strategy was halted by risk limits
+func (b *BasicStrategy) IsHalted() bool {
+	return b.risk.halted
+}
+
+//strategyPnL returns closed and open PnL of all strategy trades
+func (b *BasicStrategy) strategyPnL() float64 {
+	pnl := 0.0
+	for _, t := range b.closedTrades {
+		pnl += t.ClosedPnL
+	}
+	for _, t := range b.trades() {
+		if t.Type != FlatTrade {
+			pnl += t.ClosedPnL + t.OpenPnL
+		}
+	}
+	return pnl
+}
+
+func (b *BasicStrategy) consecutiveLosers() int {
+	n := 0
+	for i := len(b.closedTrades) - 1; i >= 0; i-- {
+		if b.closedTrades[i].ClosedPnL >= 0 {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+func (b *BasicStrategy) checkRiskLimits() {
+	b.checkMargin()
+	b.checkDrawdown()
+	if b.risk.halted {
+		return
+	}
+	limits := b.risk.limits
+	pnl := b.strategyPnL()
+
+	local := b.symbol.Exchange.LocalTime(b.mostRecentTime)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+	if !day.Equal(b.risk.day) {
+		b.risk.day = day
+		b.risk.dayStartPnL = pnl
+	}
+
+	switch {
+	case limits.MaxDailyLoss > 0 && b.risk.dayStartPnL-pnl >= limits.MaxDailyLoss:
+		b.halt(fmt.Sprintf("Daily loss %v reached limit %v", b.risk.dayStartPnL-pnl, limits.MaxDailyLoss))
+	case limits.MaxConsecutiveLosers > 0 && b.consecutiveLosers() >= limits.MaxConsecutiveLosers:
+		b.halt(fmt.Sprintf("%v consecutive losing trades", b.consecutiveLosers()))
+	case limits.MaxPosition > 0 && abs64(b.Position()) > limits.MaxPosition:
+		b.halt(fmt.Sprintf("Position %v is larger than limit %v", b.Position(), limits.MaxPosition))
+	}
+}
+
+//halt stops trading of the strategy and notifies engine with StrategyHaltedEvent
+func (b *BasicStrategy) halt(reason string) {
+	b.risk.halted = true
+	b.risk.reason = reason
+	b.target = nil
+	if err := b.CancelAllOrders(); err != nil {
+		b.newError(err)
+	}
+	b.newSignal(&StrategyHaltedEvent{BaseEvent: be(b.mostRecentTime, b.symbol), Reason: reason})
+}
+
+//checkOrderRisk returns error if order is not allowed by risk limits. Order reduces position if position
+//doesn't change its sign when order and all working orders of the same side are executed.
+func (b *BasicStrategy) checkOrderRisk(order *Order) error {
+	sign := int64(1)
+	if order.Side == OrderSell {
+		sign = -1
+	}
+	//Worst case position if all working orders of the same side are executed. Stop loss and take profit are
+	//alternatives, so they are not counted.
+	pos := b.Position()
+	projected := pos + sign*order.Qty
+	for _, t := range b.trades() {
+		for _, orders := range []map[string]*Order{t.NewOrders, t.ConfirmedOrders} {
+			for _, o := range orders {
+				if o.Side == order.Side && !b.isExitOrder(o.Id) {
+					projected += sign * (o.Qty - o.ExecQty)
+				}
+			}
+		}
+	}
+	reducing := (pos > 0 && projected >= 0 && projected < pos) || (pos < 0 && projected <= 0 && projected > pos)
+
+	if b.risk.halted && !reducing {
+		return fmt.Errorf("Strategy is halted: %v. Only orders which reduce position are allowed. ", b.risk.reason)
+	}
+	if b.portfolio != nil && b.portfolio.KillSwitchActive() && !reducing {
+		return errors.New("Portfolio kill switch is active. Only orders which reduce position are allowed. ")
+	}
+	if b.risk.limits.MaxPosition > 0 && !reducing && abs64(projected) > b.risk.limits.MaxPosition {
+		return fmt.Errorf("Order can make position %v larger than limit %v. ", projected, b.risk.limits.MaxPosition)
+	}
+	return b.checkOrderMargin(order, projected, reducing)
+}
+
+func abs64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,121 @@
+package engine
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func newTestRiskStrategy() *BasicStrategy {
+	st := newTestBasicStrategy()
+	st.ch.events = make(chan event, 20)
+	go func() {
+		for range st.ch.errors {
+		}
+	}()
+	return st
+}
+
+func fillTestOrder(st *BasicStrategy, side OrderSide, qty int64, price float64) {
+	tm := st.mostRecentTime
+	id, err := st.NewMarketOrder(side, qty, DayTIF, "Sim")
+	if err != nil {
+		panic(err)
+	}
+	st.proxyEvent(&OrderConfirmationEvent{BaseEvent: be(tm, st.symbol), OrdId: id})
+	st.proxyEvent(&OrderFillEvent{BaseEvent: be(tm, st.symbol), OrdId: id, Price: price, Qty: qty})
+}
+
+func haltedEvents(st *BasicStrategy) []*StrategyHaltedEvent {
+	var halted []*StrategyHaltedEvent
+	for len(st.ch.events) > 0 {
+		if e, ok := (<-st.ch.events).(*StrategyHaltedEvent); ok {
+			halted = append(halted, e)
+		}
+	}
+	return halted
+}
+
+func TestBasicStrategy_MaxDailyLoss(t *testing.T) {
+	st := newTestRiskStrategy()
+	st.mostRecentTime = time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	assert.NotNil(t, st.SetRiskLimits(RiskLimits{MaxDailyLoss: -1}))
+	assert.Nil(t, st.SetRiskLimits(RiskLimits{MaxDailyLoss: 150}))
+
+	fillTestOrder(st, OrderBuy, 100, 50)
+	fillTestOrder(st, OrderSell, 100, 49)
+	assert.False(t, st.IsHalted())
+
+	//Loss of the previous day doesn't count
+	st.mostRecentTime = time.Date(2018, 3, 6, 10, 0, 0, 0, time.UTC)
+	fillTestOrder(st, OrderBuy, 100, 50)
+	id, _ := st.NewLimitOrder(45, OrderBuy, 100, GTCTIF, "Sim")
+	st.proxyEvent(&OrderConfirmationEvent{BaseEvent: be(st.mostRecentTime, st.symbol), OrdId: id})
+	assert.False(t, st.IsHalted())
+	haltedEvents(st)
+
+	candle := newTestCandleCloseEvent(48.5, 48.5, 48.5, 48.5, st.mostRecentTime.Add(time.Minute), "1")
+	st.proxyEvent(candle)
+	st.shutDown()
+	assert.True(t, st.IsHalted())
+
+	var cancels int
+	var halted []*StrategyHaltedEvent
+	for len(st.ch.events) > 0 {
+		switch e := (<-st.ch.events).(type) {
+		case *OrderCancelRequestEvent:
+			assert.Equal(t, id, e.OrdId)
+			cancels++
+		case *StrategyHaltedEvent:
+			halted = append(halted, e)
+		}
+	}
+	assert.Equal(t, 1, cancels)
+	assert.Len(t, halted, 1)
+
+	//Only orders which reduce position are allowed
+	_, err := st.NewMarketOrder(OrderBuy, 100, DayTIF, "Sim")
+	assert.NotNil(t, err)
+	_, err = st.NewMarketOrder(OrderSell, 200, DayTIF, "Sim")
+	assert.NotNil(t, err)
+	_, err = st.NewMarketOrder(OrderSell, 100, DayTIF, "Sim")
+	assert.Nil(t, err)
+}
+
+func TestBasicStrategy_MaxConsecutiveLosers(t *testing.T) {
+	st := newTestRiskStrategy()
+	st.mostRecentTime = time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	assert.Nil(t, st.SetRiskLimits(RiskLimits{MaxConsecutiveLosers: 2}))
+
+	fillTestOrder(st, OrderBuy, 100, 50)
+	fillTestOrder(st, OrderSell, 100, 49)
+	fillTestOrder(st, OrderBuy, 100, 50)
+	fillTestOrder(st, OrderSell, 100, 51)
+	fillTestOrder(st, OrderBuy, 100, 50)
+	fillTestOrder(st, OrderSell, 100, 49)
+	assert.False(t, st.IsHalted())
+
+	fillTestOrder(st, OrderSell, 100, 50)
+	fillTestOrder(st, OrderBuy, 100, 51)
+	assert.True(t, st.IsHalted())
+	assert.Len(t, haltedEvents(st), 1)
+	st.shutDown()
+}
+
+func TestBasicStrategy_MaxPosition(t *testing.T) {
+	st := newTestRiskStrategy()
+	st.mostRecentTime = time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	assert.Nil(t, st.SetRiskLimits(RiskLimits{MaxPosition: 300}))
+
+	fillTestOrder(st, OrderBuy, 100, 50)
+	_, err := st.NewLimitOrder(49, OrderBuy, 200, GTCTIF, "Sim")
+	assert.Nil(t, err)
+	_, err = st.NewLimitOrder(48, OrderBuy, 100, GTCTIF, "Sim")
+	assert.NotNil(t, err)
+	_, err = st.NewLimitOrder(51, OrderSell, 400, GTCTIF, "Sim")
+	assert.Nil(t, err)
+	_, err = st.NewLimitOrder(51, OrderSell, 100, GTCTIF, "Sim")
+	assert.NotNil(t, err)
+	assert.False(t, st.IsHalted())
+	st.shutDown()
+}
@@ -0,0 +1,175 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+//IOrderRiskCheck checks new order before it's sent to broker. Error vetoes the order. Last price is NaN if
+//there was no market data of the symbol yet.
+type IOrderRiskCheck interface {
+	CheckOrder(o *Order, lastPrice float64, p IPortfolio) error
+}
+
+//RiskManager inspects every new order of all strategies before it reaches the broker. Vetoed order is
+//rejected with OrderRejectedEvent which reason starts with "Risk: ".
+type RiskManager struct {
+	checks     []IOrderRiskCheck
+	lastPrices map[string]float64
+	mut        *sync.RWMutex
+}
+
+func NewRiskManager(checks ...IOrderRiskCheck) *RiskManager {
+	m := RiskManager{
+		checks:     checks,
+		lastPrices: make(map[string]float64),
+		mut:        &sync.RWMutex{},
+	}
+	return &m
+}
+
+//AddCheck adds check which is done after all checks added before
+func (m *RiskManager) AddCheck(c IOrderRiskCheck) {
+	m.mut.Lock()
+	m.checks = append(m.checks, c)
+	m.mut.Unlock()
+}
+
+func (m *RiskManager) setLastPrice(symbol string, price float64) {
+	if math.IsNaN(price) || price <= 0 {
+		return
+	}
+	m.mut.Lock()
+	m.lastPrices[symbol] = price
+	m.mut.Unlock()
+}
+
+func (m *RiskManager) lastPrice(symbol string) float64 {
+	m.mut.RLock()
+	defer m.mut.RUnlock()
+	if p, ok := m.lastPrices[symbol]; ok {
+		return p
+	}
+	return math.NaN()
+}
+
+//check returns error of the first check which vetoes the order
+func (m *RiskManager) check(o *Order, p IPortfolio) error {
+	m.mut.RLock()
+	checks := m.checks
+	m.mut.RUnlock()
+	last := m.lastPrice(o.Ticker.Symbol)
+	for _, c := range checks {
+		if err := c.CheckOrder(o, last, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//orderPrice returns price of limit and stop orders and last price for market orders
+func orderPrice(o *Order, lastPrice float64) float64 {
+	if o.Type == MarketOrder || o.Type == MarketOnOpen || o.Type == MarketOnClose || o.Price <= 0 {
+		return lastPrice
+	}
+	return o.Price
+}
+
+//RestrictedSymbolsCheck vetoes orders for symbols from the list
+type RestrictedSymbolsCheck struct {
+	symbols map[string]struct{}
+}
+
+func NewRestrictedSymbolsCheck(symbols ...string) *RestrictedSymbolsCheck {
+	c := RestrictedSymbolsCheck{symbols: make(map[string]struct{})}
+	for _, s := range symbols {
+		c.symbols[s] = struct{}{}
+	}
+	return &c
+}
+
+func (c *RestrictedSymbolsCheck) CheckOrder(o *Order, lastPrice float64, p IPortfolio) error {
+	if _, ok := c.symbols[o.Ticker.Symbol]; ok {
+		return fmt.Errorf("Symbol %v is restricted. ", o.Ticker.Symbol)
+	}
+	return nil
+}
+
+//FatFingerCheck vetoes orders with unusual qty, notional or price. MaxPriceDeviation is max distance of order
+//price from last price as fraction of last price. Zero value of the limit disables it.
+type FatFingerCheck struct {
+	MaxQty            int64
+	MaxNotional       float64
+	MaxPriceDeviation float64
+}
+
+func (c *FatFingerCheck) CheckOrder(o *Order, lastPrice float64, p IPortfolio) error {
+	if c.MaxQty > 0 && o.Qty > c.MaxQty {
+		return fmt.Errorf("Order qty %v is larger than %v. ", o.Qty, c.MaxQty)
+	}
+	price := orderPrice(o, lastPrice)
+	if c.MaxNotional > 0 && !math.IsNaN(price) {
+		notional := price * o.Ticker.QtyFloat(o.Qty) * o.Ticker.multiplier()
+		if notional > c.MaxNotional {
+			return fmt.Errorf("Order notional %v is larger than %v. ", notional, c.MaxNotional)
+		}
+	}
+	if c.MaxPriceDeviation > 0 && o.Price > 0 && !math.IsNaN(lastPrice) &&
+		math.Abs(o.Price-lastPrice) > lastPrice*c.MaxPriceDeviation {
+		return fmt.Errorf("Order price %v is too far from last price %v. ", o.Price, lastPrice)
+	}
+	return nil
+}
+
+//MaxExposureCheck vetoes orders which can make gross exposure of the portfolio larger than MaxGross.
+//Exposure is sum of absolute market values of positions.
+type MaxExposureCheck struct {
+	MaxGross float64
+}
+
+func (c *MaxExposureCheck) CheckOrder(o *Order, lastPrice float64, p IPortfolio) error {
+	price := orderPrice(o, lastPrice)
+	if c.MaxGross <= 0 || math.IsNaN(price) {
+		return nil
+	}
+	var qty int64
+	var value float64
+	if pos, ok := p.Position(o.Ticker.Symbol); ok {
+		qty = pos.Qty
+		value = math.Abs(pos.MarketValue)
+	}
+	projected := qty + o.Qty
+	if o.Side == OrderSell {
+		projected = qty - o.Qty
+	}
+	if abs64(projected) <= abs64(qty) {
+		return nil
+	}
+	gross := p.GrossExposure() - value + price*o.Ticker.QtyFloat(abs64(projected))*o.Ticker.multiplier()
+	if gross > c.MaxGross {
+		return fmt.Errorf("Order can make gross exposure %v larger than %v. ", gross, c.MaxGross)
+	}
+	return nil
+}
+
+//SetRiskManager sets risk manager which checks orders of all strategies before broker
+func (c *Engine) SetRiskManager(m *RiskManager) {
+	c.riskManager = m
+}
+
+//checkNewOrder returns reject event if risk manager vetoes the order
+func (c *Engine) checkNewOrder(e *NewOrderEvent) *OrderRejectedEvent {
+	if c.riskManager == nil {
+		return nil
+	}
+	err := c.riskManager.check(e.LinkedOrder, c.portfolio)
+	if err == nil {
+		return nil
+	}
+	return &OrderRejectedEvent{
+		BaseEvent: be(e.getTime(), e.LinkedOrder.Ticker),
+		OrdId:     e.LinkedOrder.Id,
+		Reason:    "Risk: " + err.Error(),
+	}
+}
@@ -0,0 +1,80 @@
+package engine
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"math"
+	"sync"
+	"testing"
+)
+
+func newTestRiskOrder(side OrderSide, qty int64, price float64) *Order {
+	inst := newTestInstrument()
+	inst.LotSize = 1
+	return &Order{Side: side, Qty: qty, Price: price, Type: LimitOrder, Ticker: inst, Id: "1"}
+}
+
+func TestRiskManager_check(t *testing.T) {
+	p := newPortfolio()
+	m := NewRiskManager(NewRestrictedSymbolsCheck("Bad"), &FatFingerCheck{MaxQty: 1000, MaxNotional: 20000,
+		MaxPriceDeviation: 0.1})
+
+	ord := newTestRiskOrder(OrderBuy, 100, 50)
+	assert.Nil(t, m.check(ord, p))
+	ord.Qty = 1001
+	assert.NotNil(t, m.check(ord, p))
+	ord.Qty = 500
+	assert.NotNil(t, m.check(ord, p))
+
+	//Price deviation is checked only when last price is known
+	ord.Qty = 100
+	ord.Price = 70
+	assert.Nil(t, m.check(ord, p))
+	m.setLastPrice("Test", 60)
+	assert.NotNil(t, m.check(ord, p))
+	ord.Price = 65
+	assert.Nil(t, m.check(ord, p))
+	assert.True(t, math.IsNaN(m.lastPrice("Other")))
+
+	ord.Ticker.Symbol = "Bad"
+	assert.NotNil(t, m.check(ord, p))
+}
+
+func TestMaxExposureCheck_CheckOrder(t *testing.T) {
+	p := newPortfolio()
+	p.positions["Test"] = PositionInfo{Qty: 100, MarketValue: 5000}
+	p.positions["Other"] = PositionInfo{Qty: -100, MarketValue: -3000}
+	c := MaxExposureCheck{MaxGross: 10000}
+
+	assert.Nil(t, c.CheckOrder(newTestRiskOrder(OrderBuy, 40, 50), 50, p))
+	assert.NotNil(t, c.CheckOrder(newTestRiskOrder(OrderBuy, 50, 50), 50, p))
+	//Orders which reduce position are allowed
+	assert.Nil(t, c.CheckOrder(newTestRiskOrder(OrderSell, 200, 50), 50, p))
+	assert.NotNil(t, c.CheckOrder(newTestRiskOrder(OrderSell, 250, 50), 50, p))
+
+	//Market order uses last price
+	ord := newTestRiskOrder(OrderBuy, 50, 0)
+	ord.Type = MarketOrder
+	assert.Nil(t, c.CheckOrder(ord, math.NaN(), p))
+	assert.NotNil(t, c.CheckOrder(ord, 50, p))
+}
+
+func TestEngine_checkNewOrder(t *testing.T) {
+	st := newTestRiskStrategy()
+	st.setPortfolio(newPortfolio())
+	c := Engine{strategiesMap: map[string]ICoreStrategy{"Test": st}, portfolio: st.portfolio, mut: &sync.Mutex{},
+		waitG: &sync.WaitGroup{}}
+	c.log.SetOutput(ioutil.Discard)
+	c.SetRiskManager(NewRiskManager(&FatFingerCheck{MaxQty: 500}))
+
+	ordID, err := st.NewLimitOrder(50, OrderBuy, 1000, GTCTIF, "Sim")
+	assert.Nil(t, err)
+	e := (<-st.ch.events).(*NewOrderEvent)
+	c.proxyEvent(e)
+	st.shutDown()
+
+	r, ok := st.orders[ordID]
+	assert.True(t, ok)
+	assert.Equal(t, RejectedOrder, r.order.State)
+	assert.Contains(t, r.rejectReason, "Risk: ")
+}
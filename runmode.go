@@ -0,0 +1,112 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+)
+
+//RunMode selects market data and broker engine runs with. Strategies, orders and events are the same in all
+//modes, so the strategy tested in backtest runs unchanged on paper and live account.
+type RunMode string
+
+const (
+	//RunModeBacktest runs historical market data against simulated broker
+	RunModeBacktest RunMode = "Backtest"
+	//RunModePaper runs live market data against simulated broker
+	RunModePaper RunMode = "Paper"
+	//RunModeLive runs live market data against real broker. It requires ConfirmLive.
+	RunModeLive RunMode = "Live"
+)
+
+//LiveConfirmation is the phrase ConfirmLive expects. It makes switch to real money explicit in the code.
+const LiveConfirmation = "I understand that orders are sent to real broker"
+
+//WithRunMode sets run mode of the engine. Default mode is RunModeBacktest. Market data and broker set by
+//WithMarketData and WithBroker are used in backtest, live ones are used in paper and live modes, so all of them
+//can be configured at once and only the mode is switched.
+func WithRunMode(mode RunMode) Option {
+	return func(c *engineConfig) error {
+		if mode != RunModeBacktest && mode != RunModePaper && mode != RunModeLive {
+			return fmt.Errorf("Unknown run mode %v. ", mode)
+		}
+		c.runMode = mode
+		return nil
+	}
+}
+
+//WithLiveMarketData sets market data feed of paper and live modes
+func WithLiveMarketData(md IMarketData) Option {
+	return func(c *engineConfig) error {
+		if md == nil {
+			return errors.New("Live market data is nil. ")
+		}
+		c.liveMD = md
+		return nil
+	}
+}
+
+//WithLiveBroker sets real broker of live mode
+func WithLiveBroker(b IBroker) Option {
+	return func(c *engineConfig) error {
+		if b == nil {
+			return errors.New("Live broker is nil. ")
+		}
+		if b.IsSimulated() {
+			return errors.New("Live broker can't be simulated. ")
+		}
+		c.liveBroker = b
+		return nil
+	}
+}
+
+//ConfirmLive allows live mode. Confirmation should be equal to LiveConfirmation.
+func ConfirmLive(confirmation string) Option {
+	return func(c *engineConfig) error {
+		if confirmation != LiveConfirmation {
+			return errors.New("Live mode confirmation is wrong. ")
+		}
+		c.liveConfirmed = true
+		return nil
+	}
+}
+
+//endpoints returns market data and broker of the run mode
+func (c *engineConfig) endpoints() (IMarketData, IBroker, error) {
+	switch c.runMode {
+	case RunModePaper:
+		if c.liveMD == nil {
+			return nil, nil, errors.New("Paper mode needs live market data. ")
+		}
+		if c.broker == nil || !c.broker.IsSimulated() {
+			return nil, nil, errors.New("Paper mode needs simulated broker. ")
+		}
+		return c.liveMD, c.broker, nil
+	case RunModeLive:
+		if !c.liveConfirmed {
+			return nil, nil, errors.New("Live mode is not confirmed. ")
+		}
+		if c.liveMD == nil {
+			return nil, nil, errors.New("Live mode needs live market data. ")
+		}
+		if c.liveBroker == nil {
+			return nil, nil, errors.New("Live mode needs live broker. ")
+		}
+		return c.liveMD, c.liveBroker, nil
+	default:
+		if c.broker == nil {
+			return nil, nil, errors.New("Engine needs broker. ")
+		}
+		if c.md == nil {
+			return nil, nil, errors.New("Engine needs market data. ")
+		}
+		return c.md, c.broker, nil
+	}
+}
+
+//RunMode returns run mode of the engine
+func (c *Engine) RunMode() RunMode {
+	if c.runMode == "" {
+		return RunModeBacktest
+	}
+	return c.runMode
+}
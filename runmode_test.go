@@ -0,0 +1,98 @@
+package engine
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//liveTestBroker pretends to be real broker
+type liveTestBroker struct {
+	SimBroker
+}
+
+func (b *liveTestBroker) IsSimulated() bool {
+	return false
+}
+
+func TestNew_RunModeValidation(t *testing.T) {
+	inst := newTestInstrument()
+	st := WithStrategy(inst, &DummyStrategy{}, 1)
+	base := []Option{st, WithBroker(newTestSimBroker()), WithMarketData(&BTM{})}
+
+	cases := []struct {
+		name string
+		opts []Option
+	}{
+		{"unknown mode", []Option{WithRunMode(RunMode("Demo"))}},
+		{"paper without live md", append(base, WithRunMode(RunModePaper))},
+		{"paper with live broker", []Option{st, WithBroker(&liveTestBroker{}), WithLiveMarketData(&BTM{}),
+			WithRunMode(RunModePaper)}},
+		{"live not confirmed", append(base, WithLiveMarketData(&BTM{}), WithLiveBroker(&liveTestBroker{}),
+			WithRunMode(RunModeLive))},
+		{"live wrong confirmation", []Option{ConfirmLive("yes")}},
+		{"live without broker", append(base, WithLiveMarketData(&BTM{}), ConfirmLive(LiveConfirmation),
+			WithRunMode(RunModeLive))},
+		{"simulated live broker", []Option{WithLiveBroker(newTestSimBroker())}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			e, err := New(tc.opts...)
+			assert.Error(t, err)
+			assert.Nil(t, e)
+		})
+	}
+}
+
+func TestNew_RunMode(t *testing.T) {
+	if _, err := os.Stat("log.txt"); os.IsNotExist(err) {
+		defer os.Remove("log.txt")
+	}
+
+	for _, mode := range []RunMode{RunModeBacktest, RunModePaper, RunModeLive} {
+		t.Run(string(mode), func(t *testing.T) {
+			inst := newTestInstrument()
+			simBroker := newTestSimBroker()
+			liveBroker := &liveTestBroker{}
+			btm := &BTM{}
+			feed := &BTM{}
+
+			e, err := New(
+				WithStrategy(inst, &DummyStrategy{}, 1),
+				WithBroker(simBroker),
+				WithMarketData(btm),
+				WithLiveBroker(liveBroker),
+				WithLiveMarketData(feed),
+				ConfirmLive(LiveConfirmation),
+				WithRunMode(mode),
+			)
+			assert.Nil(t, err)
+			if !assert.NotNil(t, e) {
+				return
+			}
+			assert.Equal(t, mode, e.RunMode())
+
+			switch mode {
+			case RunModeBacktest:
+				assert.True(t, e.md == btm)
+				assert.True(t, e.broker == simBroker)
+				assert.IsType(t, &SimClock{}, e.Clock())
+			case RunModePaper:
+				assert.True(t, e.md == feed)
+				assert.True(t, e.broker == simBroker)
+				assert.IsType(t, WallClock{}, e.Clock())
+			case RunModeLive:
+				assert.True(t, e.md == feed)
+				assert.True(t, e.broker == liveBroker)
+				assert.IsType(t, WallClock{}, e.Clock())
+			}
+		})
+	}
+}
+
+func TestEngine_RunModeDefault(t *testing.T) {
+	e := Engine{}
+	assert.Equal(t, RunModeBacktest, e.RunMode())
+}
@@ -0,0 +1,163 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	InSampleSegment    = "in-sample"
+	OutOfSampleSegment = "out-of-sample"
+)
+
+//DateSegment is named part of BTM period. From and To are dates, both included. In-sample segments are used to
+//choose parameters and out-of-sample segments to validate them.
+type DateSegment struct {
+	Name     string
+	From     time.Time
+	To       time.Time
+	InSample bool
+}
+
+//SegmentReport is performance of the run and statistics of trades closed during the segment. Score is set by
+//optimizer.
+type SegmentReport struct {
+	Segment     DateSegment
+	Performance PerformanceReport
+	Stats       TradeStats
+	Score       float64
+}
+
+//SplitAt divides period of the BTM into in-sample segment before the date and out-of-sample segment from the
+//date to the end
+func (m *BTM) SplitAt(date time.Time) error {
+	m.Segments = []DateSegment{
+		{Name: InSampleSegment, From: m.FromDate, To: date.AddDate(0, 0, -1), InSample: true},
+		{Name: OutOfSampleSegment, From: date, To: m.ToDate},
+	}
+	if err := m.checkSegments(); err != nil {
+		m.Segments = nil
+		return err
+	}
+	return nil
+}
+
+//checkSegments returns error if segments don't have unique names, are out of the period or overlap
+func (m *BTM) checkSegments() error {
+	names := make(map[string]bool)
+	segments := append([]DateSegment{}, m.Segments...)
+	sort.Slice(segments, func(i, j int) bool { return segments[i].From.Before(segments[j].From) })
+	for i, s := range segments {
+		switch {
+		case s.Name == "":
+			return errors.New("Segment name is empty. ")
+		case names[s.Name]:
+			return fmt.Errorf("Segment %v is declared twice. ", s.Name)
+		case s.To.Before(s.From):
+			return fmt.Errorf("Segment %v ends before it starts. ", s.Name)
+		case s.From.Before(m.FromDate) || s.To.After(m.ToDate):
+			return fmt.Errorf("Segment %v is out of BTM period. ", s.Name)
+		case i > 0 && !segments[i-1].To.Before(s.From):
+			return fmt.Errorf("Segments %v and %v overlap. ", segments[i-1].Name, s.Name)
+		}
+		names[s.Name] = true
+	}
+	return nil
+}
+
+//SegmentReports returns reports of segments of BTM market data in declared order. Reports of the finished run
+//end with the run, otherwise they are calculated for now.
+func (c *Engine) SegmentReports() ([]SegmentReport, error) {
+	m, ok := c.md.(*BTM)
+	if !ok || len(m.Segments) == 0 {
+		return nil, nil
+	}
+	if err := m.checkSegments(); err != nil {
+		return nil, err
+	}
+	end := c.now()
+	if perf, ok := c.PerformanceReport(); ok {
+		end = perf.End
+	}
+	trades := c.ClosedTrades()
+	reports := make([]SegmentReport, len(m.Segments))
+	for i, s := range m.Segments {
+		from, to := s.From, s.To.AddDate(0, 0, 1)
+		var segmentTrades []ClosedTradeInfo
+		for _, t := range trades {
+			if !t.CloseTime.Before(from) && t.CloseTime.Before(to) {
+				segmentTrades = append(segmentTrades, t)
+			}
+		}
+		reports[i] = SegmentReport{
+			Segment:     s,
+			Performance: c.portfolio.segmentPerformance(from, to, end, segmentTrades),
+			Stats:       calcTradeStats(segmentTrades),
+		}
+	}
+	return reports, nil
+}
+
+//segmentPerformance calculates report of the part of the run from snapshots taken in [from, to). Equity of the
+//last snapshot before the segment is its initial equity. Metrics are NaN if the run has no data of the segment.
+func (p *portfolioHandler) segmentPerformance(from, to, end time.Time, trades []ClosedTradeInfo) PerformanceReport {
+	equity := p.Equity()
+	p.mut.RLock()
+	ret := &p.returns
+	r := PerformanceReport{Start: from, End: to, InitialEquity: ret.startEquity}
+	if ret.start.After(from) {
+		r.Start = ret.start
+	}
+	var snapshots []PortfolioSnapshot
+	for _, s := range ret.snapshots {
+		switch {
+		case s.Time.Before(from):
+			r.InitialEquity = s.Equity
+		case s.Time.Before(to):
+			snapshots = append(snapshots, s)
+		}
+	}
+	period := ret.period
+	noData := ret.start.IsZero() || !ret.start.Before(to) || end.Before(from)
+	p.mut.RUnlock()
+
+	r.FinalEquity = r.InitialEquity
+	if n := len(snapshots); n > 0 {
+		r.FinalEquity = snapshots[n-1].Equity
+	}
+	if end.Before(to) {
+		r.End, r.FinalEquity = end, equity
+	}
+	if noData {
+		r.InitialEquity, r.FinalEquity = math.NaN(), math.NaN()
+	}
+	calcPerformance(&r, snapshots, period, trades)
+	return r
+}
+
+//scoreSegments scores segments of the result. When there are in-sample segments the run is ranked by their
+//mean score, so out-of-sample data doesn't affect the choice. Parameters are validated in the segment if its
+//score is positive. Run is flagged InSampleOnly if all in-sample segments validate it, but some out-of-sample
+//segment doesn't or there are none.
+func scoreSegments(r *OptimizerResult, score func(r *OptimizerResult) float64) {
+	var inSample []float64
+	validIn, validOut, outOfSample := true, true, 0
+	for i := range r.Segments {
+		s := &r.Segments[i]
+		s.Score = score(&OptimizerResult{Params: r.Params, Performance: s.Performance, Stats: s.Stats})
+		if s.Segment.InSample {
+			inSample = append(inSample, s.Score)
+			validIn = validIn && s.Score > 0
+		} else {
+			outOfSample++
+			validOut = validOut && s.Score > 0
+		}
+	}
+	if len(inSample) > 0 {
+		r.Score = mean(inSample)
+		r.InSampleOnly = validIn && (!validOut || outOfSample == 0)
+	}
+}
@@ -0,0 +1,123 @@
+package engine
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBTM_SplitAt(t *testing.T) {
+	day := time.Date(2018, 3, 5, 0, 0, 0, 0, time.UTC)
+	m := &BTM{FromDate: day, ToDate: day.AddDate(0, 0, 4)}
+	assert.Nil(t, m.SplitAt(day.AddDate(0, 0, 2)))
+	assert.Equal(t, []DateSegment{
+		{Name: InSampleSegment, From: day, To: day.AddDate(0, 0, 1), InSample: true},
+		{Name: OutOfSampleSegment, From: day.AddDate(0, 0, 2), To: day.AddDate(0, 0, 4)},
+	}, m.Segments)
+
+	assert.NotNil(t, m.SplitAt(day))
+	assert.Nil(t, m.Segments)
+
+	m.Segments = []DateSegment{{Name: "a", From: day, To: day.AddDate(0, 0, 2)},
+		{Name: "b", From: day.AddDate(0, 0, 2), To: day.AddDate(0, 0, 3)}}
+	assert.NotNil(t, m.checkSegments())
+	m.Segments[1].From = day.AddDate(0, 0, 3)
+	assert.Nil(t, m.checkSegments())
+	m.Segments[1].Name = "a"
+	assert.NotNil(t, m.checkSegments())
+	m.Segments[1].Name = ""
+	assert.NotNil(t, m.checkSegments())
+	m.Segments[1] = DateSegment{Name: "b", From: day.AddDate(0, 0, 3), To: day.AddDate(0, 0, 5)}
+	assert.NotNil(t, m.checkSegments())
+}
+
+func TestEngine_SegmentReports(t *testing.T) {
+	day := time.Date(2018, 3, 5, 0, 0, 0, 0, time.UTC)
+	st := newTestBasicStrategy()
+	st.closedTrades = append(st.closedTrades,
+		&Trade{Ticker: st.symbol, ClosedPnL: 100, CloseTime: day.AddDate(0, 0, 1).Add(12 * time.Hour)},
+		&Trade{Ticker: st.symbol, ClosedPnL: -50, CloseTime: day.AddDate(0, 0, 3).Add(11 * time.Hour)})
+	c := newTestEquityEngine()
+	c.strategiesMap = map[string]ICoreStrategy{st.symbol.Symbol: st}
+	reports, err := c.SegmentReports()
+	assert.Nil(t, err)
+	assert.Nil(t, reports)
+
+	m := &BTM{FromDate: day, ToDate: day.AddDate(0, 0, 4)}
+	assert.Nil(t, m.SplitAt(day.AddDate(0, 0, 2)))
+	c.md = m
+
+	//Equity goes +10% in-sample, then -10% and +10% out-of-sample
+	p := c.portfolio
+	p.sampleReturns(day.Add(10 * time.Hour))
+	p.cash = 11000
+	p.sampleReturns(day.AddDate(0, 0, 1).Add(10 * time.Hour))
+	p.cash = 9900
+	p.sampleReturns(day.AddDate(0, 0, 2).Add(10 * time.Hour))
+	p.cash = 10890
+	p.sampleReturns(day.AddDate(0, 0, 3).Add(10 * time.Hour))
+	c.buildPerformanceReport(day.AddDate(0, 0, 3).Add(12 * time.Hour))
+
+	reports, err = c.SegmentReports()
+	assert.Nil(t, err)
+	if assert.Len(t, reports, 2) {
+		in, out := reports[0].Performance, reports[1].Performance
+		assert.Equal(t, InSampleSegment, reports[0].Segment.Name)
+		assert.Equal(t, day, in.Start)
+		assert.Equal(t, day.AddDate(0, 0, 2), in.End)
+		assert.InDelta(t, 0.1, in.TotalReturn, 0.0000001)
+		assert.Equal(t, 1, reports[0].Stats.Trades)
+		assert.Equal(t, 0.0, in.MaxDrawdown)
+
+		assert.Equal(t, day.AddDate(0, 0, 2), out.Start)
+		assert.Equal(t, day.AddDate(0, 0, 3).Add(12*time.Hour), out.End)
+		assert.Equal(t, 11000.0, out.InitialEquity)
+		assert.InDelta(t, -0.01, out.TotalReturn, 0.0000001)
+		assert.InDelta(t, 0.1, out.MaxDrawdown, 0.0000001)
+		assert.Equal(t, 1, reports[1].Stats.Trades)
+		assert.Equal(t, -50.0, reports[1].Stats.GrossLoss)
+	}
+
+	//Segment after the end of the run has no data
+	m.Segments = []DateSegment{{Name: "late", From: day.AddDate(0, 0, 4), To: day.AddDate(0, 0, 4)}}
+	reports, err = c.SegmentReports()
+	assert.Nil(t, err)
+	assert.True(t, math.IsNaN(reports[0].Performance.TotalReturn))
+}
+
+func TestScoreSegments(t *testing.T) {
+	sharpe := func(r *OptimizerResult) float64 { return r.Performance.Sharpe }
+	result := func(sharpes ...float64) *OptimizerResult {
+		r := &OptimizerResult{Score: 5}
+		for i, s := range sharpes {
+			r.Segments = append(r.Segments, SegmentReport{Segment: DateSegment{InSample: i < 2},
+				Performance: PerformanceReport{Sharpe: s}})
+		}
+		return r
+	}
+
+	r := result(1, 2, -1)
+	scoreSegments(r, sharpe)
+	assert.Equal(t, 1.5, r.Score)
+	assert.Equal(t, -1.0, r.Segments[2].Score)
+	assert.True(t, r.InSampleOnly)
+
+	r = result(1, 2, 0.5)
+	scoreSegments(r, sharpe)
+	assert.False(t, r.InSampleOnly)
+
+	r = result(1, math.NaN(), -1)
+	scoreSegments(r, sharpe)
+	assert.False(t, r.InSampleOnly)
+
+	r = result(1, 2)
+	scoreSegments(r, sharpe)
+	assert.True(t, r.InSampleOnly)
+
+	r = result()
+	scoreSegments(r, sharpe)
+	assert.Equal(t, 5.0, r.Score)
+	assert.False(t, r.InSampleOnly)
+}
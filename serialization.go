@@ -0,0 +1,472 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+//eventTypes is registry of event types by name, so serialized events are restored as their concrete types
+var eventTypes = make(map[string]reflect.Type)
+
+func init() {
+	for _, e := range []event{
+		&CandleOpenEvent{}, &CandleCloseEvent{}, &CandlesHistoryEvent{}, &NewTickEvent{}, &TickHistoryEvent{},
+		&NewOrderEvent{}, &OrderConfirmationEvent{}, &OrderFillEvent{}, &OrderCancelEvent{},
+		&OrderCancelRejectEvent{}, &OrderCancelRequestEvent{}, &OrderReplaceRequestEvent{},
+		&OrderReplaceRejectEvent{}, &OrderReplacedEvent{}, &OrderRejectedEvent{},
+		&StrategyRequestNotDeliveredEvent{}, &TimerTickEvent{}, &TimerEvent{}, &DataGapEvent{},
+		&BacktestProgressEvent{}, &RollEvent{}, &OptionChainEvent{}, &FundamentalDataEvent{}, &DividendEvent{},
+		&NewsEvent{}, &CustomEvent{}, &SymbolAddedEvent{}, &SymbolRemovedEvent{}, &EndOfDataEvent{},
+		&PortfolioNewPositionEvent{}, &StrategyHaltedEvent{}, &MarginCallEvent{}, &PortfolioKillSwitchEvent{},
+		&StalePositionEvent{}, &StrategyFinishedEvent{}, &EventDroppedEvent{}, &StrategyErrorEvent{},
+		&EndOfDayEvent{}, &RunManifestEvent{}, &AuctionImbalanceEvent{},
+		&DelistingEvent{},
+	} {
+		eventTypes[eventTypeName(e)] = reflect.TypeOf(e).Elem()
+	}
+}
+
+//eventTypeName returns name of the concrete type of the event, e.g. "OrderFillEvent"
+func eventTypeName(e event) string {
+	t := reflect.TypeOf(e)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+//EventJSON wraps event to marshal it with its type name, so it can be unmarshaled to the same concrete type.
+//NaN and infinite prices are written as strings. Instruments are written without trading calendar and
+//unexported fields of events aren't written.
+type EventJSON struct {
+	Event Event
+}
+
+func (e EventJSON) MarshalJSON() ([]byte, error) {
+	if e.Event == nil {
+		return []byte("null"), nil
+	}
+	v, err := encodeValue(reflect.ValueOf(e.Event))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(map[string]interface{}{"Type": eventTypeName(e.Event), "Event": v})
+}
+
+func (e *EventJSON) UnmarshalJSON(data []byte) error {
+	var raw interface{}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	if err := d.Decode(&raw); err != nil {
+		return err
+	}
+	if raw == nil {
+		e.Event = nil
+		return nil
+	}
+	ev, err := decodeEvent(raw)
+	if err != nil {
+		return err
+	}
+	e.Event = ev
+	return nil
+}
+
+//MarshalEvent returns JSON of the event with its type name
+func MarshalEvent(e Event) ([]byte, error) {
+	return json.Marshal(EventJSON{Event: e})
+}
+
+//UnmarshalEvent restores event of registered type from JSON written by MarshalEvent
+func UnmarshalEvent(data []byte) (Event, error) {
+	var e EventJSON
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+	if e.Event == nil {
+		return nil, fmt.Errorf("JSON has no event. ")
+	}
+	return e.Event, nil
+}
+
+func decodeEvent(raw interface{}) (Event, error) {
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("Event JSON should be object, got %T. ", raw)
+	}
+	name, _ := m["Type"].(string)
+	t, ok := eventTypes[name]
+	if !ok {
+		return nil, fmt.Errorf("Unknown event type %q. ", name)
+	}
+	v := reflect.New(t)
+	if err := decodeValue(m["Event"], v.Elem()); err != nil {
+		return nil, fmt.Errorf("Can't decode %v: %v", name, err)
+	}
+	return v.Interface().(Event), nil
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	locationType = reflect.TypeOf(&time.Location{})
+)
+
+//isEventJSON returns true for generic JSON data of event wrapped by EventJSON
+func isEventJSON(raw interface{}) bool {
+	m, ok := raw.(map[string]interface{})
+	if !ok || len(m) != 2 {
+		return false
+	}
+	name, _ := m["Type"].(string)
+	_, registered := eventTypes[name]
+	_, hasEvent := m["Event"]
+	return registered && hasEvent
+}
+
+//hasExportedFields returns false for structs like trading calendar, which state can't be serialized
+func hasExportedFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).PkgPath == "" {
+			return true
+		}
+	}
+	return false
+}
+
+func encodeFloat(f float64) interface{} {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	}
+	return f
+}
+
+//encodeValue converts value to data which encoding/json can write. Fields of embedded structs are written
+//as fields of the outer struct.
+func encodeValue(v reflect.Value) (interface{}, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+	switch v.Type() {
+	case timeType:
+		return v.Interface().(time.Time).Format(time.RFC3339Nano), nil
+	case locationType:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return v.Interface().(*time.Location).String(), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return encodeValue(v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if e, ok := v.Interface().(Event); ok {
+			return EventJSON{Event: e}, nil
+		}
+		return encodeValue(v.Elem())
+	case reflect.Struct:
+		if !hasExportedFields(v.Type()) {
+			return nil, nil
+		}
+		m := make(map[string]interface{})
+		if err := encodeFields(v, m); err != nil {
+			return nil, err
+		}
+		return m, nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil, nil
+		}
+		list := make([]interface{}, v.Len())
+		for i := range list {
+			item, err := encodeValue(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			list[i] = item
+		}
+		return list, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("Map key %v isn't supported. ", v.Type().Key())
+		}
+		m := make(map[string]interface{}, v.Len())
+		for _, k := range v.MapKeys() {
+			item, err := encodeValue(v.MapIndex(k))
+			if err != nil {
+				return nil, err
+			}
+			m[k.String()] = item
+		}
+		return m, nil
+	case reflect.Float32, reflect.Float64:
+		return encodeFloat(v.Float()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint(), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("Type %v isn't supported. ", v.Type())
+}
+
+func isEmbeddedStruct(f reflect.StructField) bool {
+	t := f.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return f.Anonymous && t.Kind() == reflect.Struct
+}
+
+//encodeFields puts exported fields of struct to the map. Fields of the outer struct win over fields of
+//embedded ones with the same name.
+func encodeFields(v reflect.Value, m map[string]interface{}) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" || isEmbeddedStruct(f) {
+			continue
+		}
+		item, err := encodeValue(v.Field(i))
+		if err != nil {
+			return fmt.Errorf("%v: %v", f.Name, err)
+		}
+		m[f.Name] = item
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" || !isEmbeddedStruct(f) {
+			continue
+		}
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		}
+		inner := make(map[string]interface{})
+		if err := encodeFields(fv, inner); err != nil {
+			return err
+		}
+		for k, item := range inner {
+			if _, ok := m[k]; !ok {
+				m[k] = item
+			}
+		}
+	}
+	return nil
+}
+
+func decodeFloat(raw interface{}) (float64, error) {
+	switch r := raw.(type) {
+	case json.Number:
+		return r.Float64()
+	case string:
+		switch r {
+		case "NaN":
+			return math.NaN(), nil
+		case "+Inf":
+			return math.Inf(1), nil
+		case "-Inf":
+			return math.Inf(-1), nil
+		}
+	}
+	return 0, fmt.Errorf("Can't decode %v as number. ", raw)
+}
+
+//plainValue converts numbers of generic JSON data to float64, the same way as encoding/json does
+func plainValue(raw interface{}) interface{} {
+	switch r := raw.(type) {
+	case json.Number:
+		f, _ := decodeFloat(r)
+		return f
+	case []interface{}:
+		for i := range r {
+			r[i] = plainValue(r[i])
+		}
+	case map[string]interface{}:
+		for k := range r {
+			r[k] = plainValue(r[k])
+		}
+	}
+	return raw
+}
+
+//decodeValue sets value from generic JSON data written by encodeValue
+func decodeValue(raw interface{}, v reflect.Value) error {
+	if raw == nil {
+		return nil
+	}
+	switch v.Type() {
+	case timeType:
+		s, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("Can't decode %v as time. ", raw)
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	case locationType:
+		s, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("Can't decode %v as location. ", raw)
+		}
+		loc, err := time.LoadLocation(s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(loc))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.Type().Elem().Kind() == reflect.Struct && !hasExportedFields(v.Type().Elem()) {
+			return nil
+		}
+		p := reflect.New(v.Type().Elem())
+		if err := decodeValue(raw, p.Elem()); err != nil {
+			return err
+		}
+		v.Set(p)
+	case reflect.Interface:
+		if isEventJSON(raw) {
+			e, err := decodeEvent(raw)
+			if err != nil {
+				return err
+			}
+			if reflect.TypeOf(e).AssignableTo(v.Type()) {
+				v.Set(reflect.ValueOf(e))
+				return nil
+			}
+		}
+		v.Set(reflect.ValueOf(plainValue(raw)))
+	case reflect.Struct:
+		m, ok := raw.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("Can't decode %v as %v. ", raw, v.Type())
+		}
+		return decodeFields(m, v)
+	case reflect.Slice, reflect.Array:
+		list, ok := raw.([]interface{})
+		if !ok {
+			return fmt.Errorf("Can't decode %v as %v. ", raw, v.Type())
+		}
+		if v.Kind() == reflect.Slice {
+			v.Set(reflect.MakeSlice(v.Type(), len(list), len(list)))
+		}
+		for i := 0; i < len(list) && i < v.Len(); i++ {
+			if err := decodeValue(list[i], v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		m, ok := raw.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("Can't decode %v as %v. ", raw, v.Type())
+		}
+		v.Set(reflect.MakeMapWithSize(v.Type(), len(m)))
+		for k, r := range m {
+			item := reflect.New(v.Type().Elem()).Elem()
+			if err := decodeValue(r, item); err != nil {
+				return err
+			}
+			v.SetMapIndex(reflect.ValueOf(k).Convert(v.Type().Key()), item)
+		}
+	case reflect.Float32, reflect.Float64:
+		f, err := decodeFloat(raw)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := raw.(json.Number)
+		if !ok {
+			return fmt.Errorf("Can't decode %v as integer. ", raw)
+		}
+		i, err := n.Int64()
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := raw.(json.Number)
+		if !ok {
+			return fmt.Errorf("Can't decode %v as integer. ", raw)
+		}
+		i, err := strconv.ParseUint(n.String(), 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetUint(i)
+	case reflect.String:
+		s, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("Can't decode %v as string. ", raw)
+		}
+		v.SetString(s)
+	case reflect.Bool:
+		b, ok := raw.(bool)
+		if !ok {
+			return fmt.Errorf("Can't decode %v as bool. ", raw)
+		}
+		v.SetBool(b)
+	}
+	return nil
+}
+
+//decodeFields sets exported fields of struct from the map. Embedded structs get fields from the same map.
+func decodeFields(m map[string]interface{}, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		fv := v.Field(i)
+		if isEmbeddedStruct(f) {
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					fv.Set(reflect.New(f.Type.Elem()))
+				}
+				fv = fv.Elem()
+			}
+			if err := decodeFields(m, fv); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := decodeValue(m[f.Name], fv); err != nil {
+			return fmt.Errorf("%v: %v", f.Name, err)
+		}
+	}
+	return nil
+}
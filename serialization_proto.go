@@ -0,0 +1,477 @@
+package engine
+
+import (
+	"alex/engine/eventpb"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+//eventProtoFields are fields of eventpb.Event envelope by names of event types
+var eventProtoFields = make(map[string]protoreflect.FieldDescriptor)
+
+//protoFieldNames caches fields of protobuf messages by normalized names
+var protoFieldNames sync.Map
+
+func init() {
+	fields := (&eventpb.Event{}).ProtoReflect().Descriptor().Oneofs().ByName("event").Fields()
+	for i := 0; i < fields.Len(); i++ {
+		f := fields.Get(i)
+		eventProtoFields[string(f.Message().Name())] = f
+	}
+}
+
+//EventToProto converts event of registered type to protobuf message. Fields are written the same way as to
+//JSON: instruments without trading calendar and without unexported fields. Times are restored in UTC.
+func EventToProto(e Event) (*eventpb.Event, error) {
+	v := reflect.ValueOf(e)
+	if e == nil || (v.Kind() == reflect.Ptr && v.IsNil()) {
+		return nil, fmt.Errorf("Event is nil. ")
+	}
+	name := eventTypeName(e)
+	fd, ok := eventProtoFields[name]
+	if !ok {
+		return nil, fmt.Errorf("Event type %v has no protobuf message. ", name)
+	}
+	msg := &eventpb.Event{}
+	if err := encodeProtoFields(reflect.Indirect(v), msg.ProtoReflect().Mutable(fd).Message()); err != nil {
+		return nil, fmt.Errorf("Can't encode %v: %v", name, err)
+	}
+	return msg, nil
+}
+
+//EventFromProto restores event of registered type from protobuf message made by EventToProto
+func EventFromProto(msg *eventpb.Event) (Event, error) {
+	m := msg.ProtoReflect()
+	fd := m.WhichOneof(m.Descriptor().Oneofs().ByName("event"))
+	if fd == nil {
+		return nil, fmt.Errorf("Protobuf message has no event. ")
+	}
+	name := string(fd.Message().Name())
+	t, ok := eventTypes[name]
+	if !ok {
+		return nil, fmt.Errorf("Unknown event type %q. ", name)
+	}
+	v := reflect.New(t)
+	if err := decodeProtoFields(m.Get(fd).Message(), v.Elem()); err != nil {
+		return nil, fmt.Errorf("Can't decode %v: %v", name, err)
+	}
+	return v.Interface().(Event), nil
+}
+
+//MarshalEventProto returns protobuf encoding of the event wrapped by eventpb.Event
+func MarshalEventProto(e Event) ([]byte, error) {
+	msg, err := EventToProto(e)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(msg)
+}
+
+//UnmarshalEventProto restores event from data written by MarshalEventProto
+func UnmarshalEventProto(data []byte) (Event, error) {
+	msg := &eventpb.Event{}
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return nil, err
+	}
+	return EventFromProto(msg)
+}
+
+//normalizeFieldName makes names of the same field in Go and in protobuf equal, e.g. OrdId and ord_id
+func normalizeFieldName(name string) string {
+	return strings.ToLower(strings.Replace(name, "_", "", -1))
+}
+
+//protoField returns field of the message for Go field name or nil if message has no such field
+func protoField(md protoreflect.MessageDescriptor, name string) protoreflect.FieldDescriptor {
+	cached, ok := protoFieldNames.Load(md.FullName())
+	if !ok {
+		fields := make(map[string]protoreflect.FieldDescriptor)
+		for i := 0; i < md.Fields().Len(); i++ {
+			f := md.Fields().Get(i)
+			fields[normalizeFieldName(string(f.Name()))] = f
+		}
+		cached, _ = protoFieldNames.LoadOrStore(md.FullName(), fields)
+	}
+	return cached.(map[string]protoreflect.FieldDescriptor)[normalizeFieldName(name)]
+}
+
+//isSerializedField returns false for fields which JSON of the event doesn't have either
+func isSerializedField(f reflect.StructField) bool {
+	if f.PkgPath != "" {
+		return false
+	}
+	t := f.Type
+	if t == timeType || t == locationType {
+		return true
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		return hasExportedFields(t)
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return false
+	}
+	return true
+}
+
+//protoKindMatches returns true if value of Go type can be kept in scalar protobuf field of the kind
+func protoKindMatches(t reflect.Type, k protoreflect.Kind) bool {
+	switch k {
+	case protoreflect.DoubleKind:
+		return t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64
+	case protoreflect.Int64Kind:
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return true
+		}
+	case protoreflect.Uint64Kind:
+		switch t.Kind() {
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return true
+		}
+	case protoreflect.BoolKind:
+		return t.Kind() == reflect.Bool
+	case protoreflect.StringKind:
+		return t.Kind() == reflect.String || t == locationType
+	}
+	return false
+}
+
+//encodeProtoFields sets fields of the message from exported fields of struct. Every serialized field should
+//have protobuf field with the same name.
+func encodeProtoFields(v reflect.Value, m protoreflect.Message) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !isSerializedField(f) {
+			continue
+		}
+		fd := protoField(m.Descriptor(), f.Name)
+		if fd == nil {
+			return fmt.Errorf("%v has no field for %v. ", m.Descriptor().Name(), f.Name)
+		}
+		if err := encodeProtoField(v.Field(i), m, fd); err != nil {
+			return fmt.Errorf("%v: %v", f.Name, err)
+		}
+	}
+	return nil
+}
+
+func encodeProtoField(v reflect.Value, m protoreflect.Message, fd protoreflect.FieldDescriptor) error {
+	switch {
+	case fd.IsMap():
+		if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("Can't encode %v as map. ", v.Type())
+		}
+		if v.Len() == 0 {
+			return nil
+		}
+		mp := m.Mutable(fd).Map()
+		iter := v.MapRange()
+		for iter.Next() {
+			item, _, err := protoValue(iter.Value(), fd.MapValue(), mp.NewValue)
+			if err != nil {
+				return err
+			}
+			mp.Set(protoreflect.ValueOfString(iter.Key().String()).MapKey(), item)
+		}
+	case fd.IsList():
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return fmt.Errorf("Can't encode %v as list. ", v.Type())
+		}
+		if v.Len() == 0 {
+			return nil
+		}
+		list := m.Mutable(fd).List()
+		for i := 0; i < v.Len(); i++ {
+			item, ok, err := protoValue(v.Index(i), fd, list.NewElement)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				item = list.NewElement()
+			}
+			list.Append(item)
+		}
+	default:
+		item, ok, err := protoValue(v, fd, func() protoreflect.Value { return m.NewField(fd) })
+		if err != nil || !ok {
+			return err
+		}
+		m.Set(fd, item)
+	}
+	return nil
+}
+
+//protoValue converts value to value of protobuf field. It returns false if field should stay unset, e.g. for
+//nil pointer or zero time. NewItem returns empty message value of the field.
+func protoValue(v reflect.Value, fd protoreflect.FieldDescriptor,
+	newItem func() protoreflect.Value) (protoreflect.Value, bool, error) {
+	if fd.Kind() != protoreflect.MessageKind {
+		if !protoKindMatches(v.Type(), fd.Kind()) {
+			return protoreflect.Value{}, false, fmt.Errorf("Can't encode %v as %v. ", v.Type(), fd.Kind())
+		}
+		switch fd.Kind() {
+		case protoreflect.DoubleKind:
+			return protoreflect.ValueOfFloat64(v.Float()), true, nil
+		case protoreflect.Int64Kind:
+			return protoreflect.ValueOfInt64(v.Int()), true, nil
+		case protoreflect.Uint64Kind:
+			return protoreflect.ValueOfUint64(v.Uint()), true, nil
+		case protoreflect.BoolKind:
+			return protoreflect.ValueOfBool(v.Bool()), true, nil
+		}
+		if v.Type() == locationType {
+			if v.IsNil() {
+				return protoreflect.Value{}, false, nil
+			}
+			return protoreflect.ValueOfString(v.Interface().(*time.Location).String()), true, nil
+		}
+		return protoreflect.ValueOfString(v.String()), true, nil
+	}
+
+	switch fd.Message().FullName() {
+	case "google.protobuf.Timestamp":
+		if v.Type() != timeType {
+			return protoreflect.Value{}, false, fmt.Errorf("Can't encode %v as timestamp. ", v.Type())
+		}
+		tm := v.Interface().(time.Time)
+		if tm.IsZero() {
+			return protoreflect.Value{}, false, nil
+		}
+		return protoreflect.ValueOfMessage(timestamppb.New(tm).ProtoReflect()), true, nil
+	case "google.protobuf.Duration":
+		if v.Type() != durationType {
+			return protoreflect.Value{}, false, fmt.Errorf("Can't encode %v as duration. ", v.Type())
+		}
+		if v.Int() == 0 {
+			return protoreflect.Value{}, false, nil
+		}
+		return protoreflect.ValueOfMessage(durationpb.New(time.Duration(v.Int())).ProtoReflect()), true, nil
+	case "google.protobuf.Value":
+		return payloadProto(v)
+	case "engine.Event":
+		if v.Kind() != reflect.Interface {
+			return protoreflect.Value{}, false, fmt.Errorf("Can't encode %v as event. ", v.Type())
+		}
+		if v.IsNil() {
+			return protoreflect.Value{}, false, nil
+		}
+		e, ok := v.Interface().(Event)
+		if !ok {
+			return protoreflect.Value{}, false, fmt.Errorf("%T isn't event. ", v.Interface())
+		}
+		msg, err := EventToProto(e)
+		if err != nil {
+			return protoreflect.Value{}, false, err
+		}
+		return protoreflect.ValueOfMessage(msg.ProtoReflect()), true, nil
+	}
+
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return protoreflect.Value{}, false, nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return protoreflect.Value{}, false, fmt.Errorf("Can't encode %v as %v. ", v.Type(), fd.Message().Name())
+	}
+	item := newItem()
+	if err := encodeProtoFields(v, item.Message()); err != nil {
+		return protoreflect.Value{}, false, err
+	}
+	return item, true, nil
+}
+
+//payloadProto converts value of any type to protobuf value through its JSON, so payload is the same as in
+//JSON of the event
+func payloadProto(v reflect.Value) (protoreflect.Value, bool, error) {
+	raw, err := encodeValue(v)
+	if err != nil || raw == nil {
+		return protoreflect.Value{}, false, err
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return protoreflect.Value{}, false, err
+	}
+	payload := &structpb.Value{}
+	if err := protojson.Unmarshal(data, payload); err != nil {
+		return protoreflect.Value{}, false, err
+	}
+	return protoreflect.ValueOfMessage(payload.ProtoReflect()), true, nil
+}
+
+//decodeProtoFields sets exported fields of struct from fields of the message
+func decodeProtoFields(m protoreflect.Message, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !isSerializedField(f) {
+			continue
+		}
+		fd := protoField(m.Descriptor(), f.Name)
+		if fd == nil {
+			return fmt.Errorf("%v has no field for %v. ", m.Descriptor().Name(), f.Name)
+		}
+		if err := decodeProtoField(m, fd, v.Field(i)); err != nil {
+			return fmt.Errorf("%v: %v", f.Name, err)
+		}
+	}
+	return nil
+}
+
+func decodeProtoField(m protoreflect.Message, fd protoreflect.FieldDescriptor, v reflect.Value) error {
+	switch {
+	case fd.IsMap():
+		mp := m.Get(fd).Map()
+		if mp.Len() == 0 {
+			return nil
+		}
+		if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("Can't decode map as %v. ", v.Type())
+		}
+		out := reflect.MakeMapWithSize(v.Type(), mp.Len())
+		var err error
+		mp.Range(func(k protoreflect.MapKey, item protoreflect.Value) bool {
+			value := reflect.New(v.Type().Elem()).Elem()
+			if err = goValue(item, fd.MapValue(), value); err != nil {
+				return false
+			}
+			out.SetMapIndex(reflect.ValueOf(k.String()).Convert(v.Type().Key()), value)
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		v.Set(out)
+	case fd.IsList():
+		list := m.Get(fd).List()
+		if list.Len() == 0 {
+			return nil
+		}
+		switch v.Kind() {
+		case reflect.Slice:
+			v.Set(reflect.MakeSlice(v.Type(), list.Len(), list.Len()))
+		case reflect.Array:
+		default:
+			return fmt.Errorf("Can't decode list as %v. ", v.Type())
+		}
+		for i := 0; i < list.Len() && i < v.Len(); i++ {
+			if err := goValue(list.Get(i), fd, v.Index(i)); err != nil {
+				return err
+			}
+		}
+	default:
+		if fd.Kind() == protoreflect.MessageKind && !m.Has(fd) {
+			return nil
+		}
+		return goValue(m.Get(fd), fd, v)
+	}
+	return nil
+}
+
+//goValue sets value from value of protobuf field written by protoValue
+func goValue(item protoreflect.Value, fd protoreflect.FieldDescriptor, v reflect.Value) error {
+	if fd.Kind() != protoreflect.MessageKind {
+		if !protoKindMatches(v.Type(), fd.Kind()) {
+			return fmt.Errorf("Can't decode %v as %v. ", fd.Kind(), v.Type())
+		}
+		switch fd.Kind() {
+		case protoreflect.DoubleKind:
+			v.SetFloat(item.Float())
+		case protoreflect.Int64Kind:
+			v.SetInt(item.Int())
+		case protoreflect.Uint64Kind:
+			v.SetUint(item.Uint())
+		case protoreflect.BoolKind:
+			v.SetBool(item.Bool())
+		case protoreflect.StringKind:
+			if v.Type() != locationType {
+				v.SetString(item.String())
+				return nil
+			}
+			if item.String() == "" {
+				return nil
+			}
+			loc, err := time.LoadLocation(item.String())
+			if err != nil {
+				return err
+			}
+			v.Set(reflect.ValueOf(loc))
+		}
+		return nil
+	}
+
+	msg := item.Message().Interface()
+	switch m := msg.(type) {
+	case *timestamppb.Timestamp:
+		if v.Type() != timeType {
+			return fmt.Errorf("Can't decode timestamp as %v. ", v.Type())
+		}
+		v.Set(reflect.ValueOf(m.AsTime()))
+		return nil
+	case *durationpb.Duration:
+		if v.Type() != durationType {
+			return fmt.Errorf("Can't decode duration as %v. ", v.Type())
+		}
+		v.SetInt(int64(m.AsDuration()))
+		return nil
+	case *structpb.Value:
+		return decodePayloadProto(m, v)
+	case *eventpb.Event:
+		e, err := EventFromProto(m)
+		if err != nil {
+			return err
+		}
+		if !reflect.TypeOf(e).AssignableTo(v.Type()) {
+			return fmt.Errorf("Can't decode %T as %v. ", e, v.Type())
+		}
+		v.Set(reflect.ValueOf(e))
+		return nil
+	}
+
+	if v.Kind() == reflect.Ptr {
+		p := reflect.New(v.Type().Elem())
+		if err := decodeProtoFields(item.Message(), p.Elem()); err != nil {
+			return err
+		}
+		v.Set(p)
+		return nil
+	}
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("Can't decode %v as %v. ", fd.Message().Name(), v.Type())
+	}
+	return decodeProtoFields(item.Message(), v)
+}
+
+//decodePayloadProto sets value from protobuf value the same way as it's set from JSON of the event
+func decodePayloadProto(payload *structpb.Value, v reflect.Value) error {
+	data, err := protojson.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	var raw interface{}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	if err := d.Decode(&raw); err != nil {
+		return err
+	}
+	return decodeValue(raw, v)
+}
@@ -0,0 +1,135 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//fillTestValue sets every serialized field of the value to non zero value. Nested instruments are filled up to
+//the depth.
+func fillTestValue(v reflect.Value, depth int) {
+	switch v.Type() {
+	case timeType:
+		v.Set(reflect.ValueOf(time.Date(2018, 3, 5, 10, 0, 0, 5, time.UTC)))
+		return
+	case locationType:
+		v.Set(reflect.ValueOf(time.UTC))
+		return
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if depth == 0 || (v.Type().Elem().Kind() == reflect.Struct && !hasExportedFields(v.Type().Elem())) {
+			return
+		}
+		p := reflect.New(v.Type().Elem())
+		fillTestValue(p.Elem(), depth-1)
+		v.Set(p)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if isSerializedField(v.Type().Field(i)) {
+				fillTestValue(v.Field(i), depth)
+			}
+		}
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), 2, 2))
+		for i := 0; i < v.Len(); i++ {
+			fillTestValue(v.Index(i), depth)
+		}
+	case reflect.Map:
+		v.Set(reflect.MakeMap(v.Type()))
+		item := reflect.New(v.Type().Elem()).Elem()
+		fillTestValue(item, depth)
+		v.SetMapIndex(reflect.ValueOf("key").Convert(v.Type().Key()), item)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(1.5)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(-7)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(7)
+	case reflect.String:
+		v.SetString("value")
+	case reflect.Bool:
+		v.SetBool(true)
+	}
+}
+
+func TestMarshalEventProto_AllTypes(t *testing.T) {
+	assert.Len(t, eventProtoFields, len(eventTypes))
+	for name, typ := range eventTypes {
+		e := reflect.New(typ).Interface().(Event)
+		fillTestValue(reflect.ValueOf(e).Elem(), 3)
+		data, err := MarshalEventProto(e)
+		assert.Nil(t, err, name)
+		restored, err := UnmarshalEventProto(data)
+		assert.Nil(t, err, name)
+		assert.Equal(t, e, restored, name)
+	}
+}
+
+func TestMarshalEventProto(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	assert.Nil(t, err)
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, ny)
+	inst := &Instrument{Symbol: "SPY", MinTick: 0.01, LotSize: 100,
+		Exchange: Exchange{Name: "NYSE", MarketOpenTime: TimeOfDay{9, 30, 0}, Location: ny,
+			Calendar: &TradingCalendar{}}}
+
+	order := &Order{Side: OrderBuy, Qty: 100, Ticker: inst, Price: math.NaN(), Type: MarketOrder, Id: "1",
+		Time: tm}
+	data, err := MarshalEventProto(&NewOrderEvent{BaseEvent: be(tm, inst), LinkedOrder: order})
+	assert.Nil(t, err)
+	e, err := UnmarshalEventProto(data)
+	assert.Nil(t, err)
+	o, ok := e.(*NewOrderEvent)
+	assert.True(t, ok)
+	assert.True(t, math.IsNaN(o.LinkedOrder.Price))
+	assert.Equal(t, "1", o.LinkedOrder.Id)
+	assert.True(t, tm.Equal(o.LinkedOrder.Time))
+	assert.Equal(t, ny.String(), o.Ticker.Exchange.Location.String())
+	//Calendar has no exported state
+	assert.Nil(t, o.Ticker.Exchange.Calendar)
+
+	tick := &Tick{Tick: &marketdata.Tick{Datetime: tm, Symbol: "SPY", LastPrice: 10, BidPrice: math.Inf(-1)},
+		Ticker: inst}
+	data, err = MarshalEventProto(&NewTickEvent{BaseEvent: be(tm, inst), Tick: tick})
+	assert.Nil(t, err)
+	e, err = UnmarshalEventProto(data)
+	assert.Nil(t, err)
+	te := e.(*NewTickEvent)
+	assert.Equal(t, 10.0, te.Tick.LastPrice)
+	assert.True(t, math.IsInf(te.Tick.BidPrice, -1))
+	assert.Equal(t, "SPY", te.Tick.Ticker.Symbol)
+
+	//Request of not delivered event is restored as its own type
+	req := &OrderCancelRequestEvent{BaseEvent: be(tm, inst), OrdId: "1"}
+	data, err = MarshalEventProto(&StrategyRequestNotDeliveredEvent{BaseEvent: be(tm, inst), Request: req})
+	assert.Nil(t, err)
+	e, err = UnmarshalEventProto(data)
+	assert.Nil(t, err)
+	assert.Equal(t, "1", e.(*StrategyRequestNotDeliveredEvent).Request.(*OrderCancelRequestEvent).OrdId)
+
+	//Payload is the same as after JSON
+	custom := &CustomEvent{BaseEvent: be(tm, inst), Name: "Signal",
+		Payload: map[string]interface{}{"Score": 1.5, "Tags": []string{"a"}, "Event": req}}
+	data, err = MarshalEventProto(custom)
+	assert.Nil(t, err)
+	e, err = UnmarshalEventProto(data)
+	assert.Nil(t, err)
+	jsonData, err := MarshalEvent(custom)
+	assert.Nil(t, err)
+	fromJSON, err := UnmarshalEvent(jsonData)
+	assert.Nil(t, err)
+	assert.Equal(t, fromJSON.(*CustomEvent).Payload, e.(*CustomEvent).Payload)
+
+	_, err = MarshalEventProto(nil)
+	assert.NotNil(t, err)
+	_, err = UnmarshalEventProto([]byte{})
+	assert.NotNil(t, err)
+	_, err = UnmarshalEventProto([]byte{0xff})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,83 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalEvent_AllTypes(t *testing.T) {
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	for name, typ := range eventTypes {
+		e := reflect.New(typ).Interface().(Event)
+		reflect.ValueOf(e).Elem().FieldByName("BaseEvent").Set(reflect.ValueOf(be(tm, &Instrument{Symbol: "SPY"})))
+		data, err := MarshalEvent(e)
+		assert.Nil(t, err, name)
+		restored, err := UnmarshalEvent(data)
+		assert.Nil(t, err, name)
+		assert.Equal(t, e, restored, name)
+	}
+}
+
+func TestMarshalEvent(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	assert.Nil(t, err)
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, ny)
+	inst := &Instrument{Symbol: "SPY", MinTick: 0.01, LotSize: 100,
+		Exchange: Exchange{Name: "NYSE", MarketOpenTime: TimeOfDay{9, 30, 0}, Location: ny,
+			Calendar: &TradingCalendar{}}}
+
+	order := &Order{Side: OrderBuy, Qty: 100, Ticker: inst, Price: math.NaN(), Type: MarketOrder, Id: "1",
+		Time: tm}
+	data, err := MarshalEvent(&NewOrderEvent{BaseEvent: be(tm, inst), LinkedOrder: order})
+	assert.Nil(t, err)
+	e, err := UnmarshalEvent(data)
+	assert.Nil(t, err)
+	o, ok := e.(*NewOrderEvent)
+	assert.True(t, ok)
+	assert.True(t, math.IsNaN(o.LinkedOrder.Price))
+	assert.Equal(t, "1", o.LinkedOrder.Id)
+	assert.True(t, tm.Equal(o.LinkedOrder.Time))
+	assert.Equal(t, "SPY", o.getSymbol())
+	assert.Equal(t, ny.String(), o.Ticker.Exchange.Location.String())
+	assert.Equal(t, TimeOfDay{9, 30, 0}, o.Ticker.Exchange.MarketOpenTime)
+	//Calendar has no exported state
+	assert.Nil(t, o.Ticker.Exchange.Calendar)
+
+	tick := &Tick{Tick: &marketdata.Tick{Datetime: tm, Symbol: "SPY", LastPrice: 10, BidPrice: math.NaN()},
+		Ticker: inst}
+	data, err = MarshalEvent(&NewTickEvent{BaseEvent: be(tm, inst), Tick: tick})
+	assert.Nil(t, err)
+	e, err = UnmarshalEvent(data)
+	assert.Nil(t, err)
+	te := e.(*NewTickEvent)
+	assert.Equal(t, 10.0, te.Tick.LastPrice)
+	assert.Equal(t, "SPY", te.Tick.Symbol)
+	assert.True(t, math.IsNaN(te.Tick.BidPrice))
+	assert.Equal(t, "SPY", te.Tick.Ticker.Symbol)
+
+	//Request of not delivered event is restored as its own type
+	req := &OrderCancelRequestEvent{BaseEvent: be(tm, inst), OrdId: "1"}
+	data, err = MarshalEvent(&StrategyRequestNotDeliveredEvent{BaseEvent: be(tm, inst), Request: req})
+	assert.Nil(t, err)
+	e, err = UnmarshalEvent(data)
+	assert.Nil(t, err)
+	nd := e.(*StrategyRequestNotDeliveredEvent)
+	assert.Equal(t, "1", nd.Request.(*OrderCancelRequestEvent).OrdId)
+
+	data, err = MarshalEvent(&CustomEvent{BaseEvent: be(tm, inst), Name: "Signal",
+		Payload: map[string]interface{}{"Score": 1.5, "Tags": []string{"a"}}})
+	assert.Nil(t, err)
+	e, err = UnmarshalEvent(data)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"Score": 1.5, "Tags": []interface{}{"a"}}, e.(*CustomEvent).Payload)
+
+	_, err = UnmarshalEvent([]byte(`{"Type":"UnknownEvent","Event":{}}`))
+	assert.NotNil(t, err)
+	_, err = UnmarshalEvent([]byte(`null`))
+	assert.NotNil(t, err)
+}
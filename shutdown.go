@@ -0,0 +1,164 @@
+package engine
+
+import (
+	"context"
+	"sort"
+)
+
+//shutdownState keeps graceful shutdown request of the run. Stop is closed when shutdown is requested, done is
+//closed when run returns.
+type shutdownState struct {
+	stop      chan struct{}
+	done      chan struct{}
+	requested bool
+}
+
+//init creates channels of the next run. Caller should hold engine lock.
+func (s *shutdownState) init() {
+	if s.stop == nil {
+		s.stop = make(chan struct{})
+		s.done = make(chan struct{})
+	}
+}
+
+//Shutdown stops the run gracefully: market data loop stops, events simulated broker holds are delivered,
+//strategies get OnBacktestEnd callback and events which are already sent are handled. Shutdown returns when
+//run returned. If ctx is done first, run is canceled the same way as by Stop and ctx error is returned.
+//Shutdown requested before the run makes the run stop right after start.
+func (c *Engine) Shutdown(ctx context.Context) error {
+	c.mut.Lock()
+	c.shutdown.init()
+	if !c.shutdown.requested {
+		c.shutdown.requested = true
+		close(c.shutdown.stop)
+	}
+	done := c.shutdown.done
+	c.mut.Unlock()
+	c.stopping()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		c.Stop()
+		return ctx.Err()
+	}
+}
+
+//stopChan returns channel which is closed when graceful shutdown is requested
+func (c *Engine) stopChan() <-chan struct{} {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.shutdown.init()
+	return c.shutdown.stop
+}
+
+//endRun notifies Shutdown callers that run returned and resets shutdown request for the next run
+func (c *Engine) endRun() {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.shutdown.init()
+	close(c.shutdown.done)
+	c.shutdown = shutdownState{}
+}
+
+//eventFlusher is implemented by broker which holds generated events until market data reaches their time
+type eventFlusher interface {
+	flush() eventArray
+}
+
+//flushBroker delivers events which broker still holds, so strategies see fills of the last market data
+//before the end of the run
+func (c *Engine) flushBroker() {
+	f, ok := c.broker.(eventFlusher)
+	if !ok {
+		return
+	}
+	for _, e := range f.flush() {
+		c.proxyEvent(e)
+	}
+}
+
+//stopMD finishes market data loop the same way as end of data does. Market data is canceled, so it doesn't
+//block on sending events nobody reads anymore.
+func (c *Engine) stopMD() {
+	c.logMessage("Shutdown is requested")
+	c.mut.Lock()
+	cancelMD := c.cancelMD
+	c.mut.Unlock()
+	if cancelMD != nil {
+		cancelMD()
+	}
+	c.flushBroker()
+	c.eEndOfData(&EndOfDataEvent{BaseEvent: be(c.now(), &Instrument{})})
+}
+
+//drainEvents handles events, portfolio updates and errors while engine waits for handlers, so handlers
+//which still send them can't block shutdown. Returned func stops draining and handles what is left in the
+//channels. Nothing is drained when run is canceled, handlers observe cancellation themselves.
+func (c *Engine) drainEvents() func() {
+	if c.ctx != nil && c.ctx.Err() != nil {
+		return func() {}
+	}
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case e := <-c.events:
+				c.proxyEvent(e)
+			case e := <-c.portfolioChan:
+				c.portfolio.onNewTrade(e.trade)
+			case err := <-c.errChan:
+				c.logError(err)
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(stop)
+		<-done
+		for {
+			select {
+			case e := <-c.events:
+				c.proxyEvent(e)
+			case e := <-c.portfolioChan:
+				c.portfolio.onNewTrade(e.trade)
+			case err := <-c.errChan:
+				c.logError(err)
+			default:
+				return
+			}
+		}
+	}
+}
+
+//flush returns events generated by all workers which weren't sent yet
+func (b *SimBroker) flush() eventArray {
+	b.workersMut.RLock()
+	symbols := make([]string, 0, len(b.workers))
+	for s := range b.workers {
+		symbols = append(symbols, s)
+	}
+	sort.Strings(symbols)
+	var events eventArray
+	for _, s := range symbols {
+		events = append(events, b.workers[s].flush()...)
+	}
+	b.workersMut.RUnlock()
+	events.sort()
+	return events
+}
+
+//flush returns generated events which wait for market data of their time and forgets them
+func (b *simBrokerWorker) flush() eventArray {
+	b.mpMutext.Lock()
+	defer b.mpMutext.Unlock()
+	events := b.generatedEvents
+	b.generatedEvents = nil
+	events.sort()
+	return events
+}
@@ -0,0 +1,143 @@
+package engine
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestShutdownEngine(calls *[]string) (*Engine, *BasicStrategy) {
+	events := make(chan event, 10)
+	errChan := make(chan error)
+	portfolioChan := make(chan *PortfolioNewPositionEvent, 5)
+	st := NewBasicStrategy(newTestInstrument(), 1, &backtestEndTestStrategy{calls: calls})
+	st.init(CoreStrategyChannels{errors: errChan, events: events, portfolio: portfolioChan})
+	broker := &SimBroker{delay: 1000}
+	broker.Init(errChan, events, []*Instrument{st.symbol})
+
+	c := Engine{
+		strategiesMap:   map[string]ICoreStrategy{st.symbol.Symbol: st},
+		broker:          broker,
+		portfolio:       newPortfolio(),
+		events:          events,
+		errChan:         errChan,
+		portfolioChan:   portfolioChan,
+		marketDataChan:  make(chan event),
+		terminationChan: make(chan struct{}),
+		mut:             &sync.Mutex{},
+		waitG:           &sync.WaitGroup{},
+	}
+	c.log.SetOutput(ioutil.Discard)
+	return &c, st
+}
+
+func TestEngine_Shutdown(t *testing.T) {
+	var calls []string
+	c, st := newTestShutdownEngine(&calls)
+	tm := time.Date(2018, 3, 5, 16, 0, 0, 0, time.UTC)
+	st.mostRecentTime = tm
+	id, err := st.NewMarketOrder(OrderBuy, 100, DayTIF, "Sim")
+	assert.Nil(t, err)
+	<-c.events
+	st.proxyEvent(&OrderConfirmationEvent{BaseEvent: be(tm, st.symbol), OrdId: id})
+
+	//Fill is after the last market data, so broker still holds it
+	w := c.broker.(*SimBroker).workers[st.symbol.Symbol]
+	w.generatedEvents = eventArray{&OrderFillEvent{BaseEvent: be(tm.Add(time.Second), st.symbol), OrdId: id,
+		Price: 50, Qty: 100}}
+
+	go func() {
+		wg := &sync.WaitGroup{}
+		wg.Add(1)
+		go func() {
+			c.listenEvents()
+			wg.Done()
+		}()
+		c.listendMD()
+		wg.Wait()
+		c.drainEvents()()
+		c.endRun()
+	}()
+
+	assert.Nil(t, c.Shutdown(context.Background()))
+	assert.Equal(t, []string{st.symbol.Symbol}, calls)
+	assert.Equal(t, int64(100), st.Position())
+	assert.Empty(t, w.generatedEvents)
+	//Order sent from OnBacktestEnd reached broker
+	assert.Len(t, w.requestEvents, 1)
+	assert.Len(t, c.events, 0)
+}
+
+func TestEngine_ShutdownBTM(t *testing.T) {
+	dir, err := ioutil.TempDir("", "shutdown")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	md := newTestBTMforSyntheticTicks(dir, 1)
+	assert.Nil(t, md.SetStreaming(&StreamingConfig{}))
+	e, err := New(WithMarketData(md), WithBroker(&SimBroker{}),
+		WithStrategy(&Instrument{Symbol: "Sym1"}, &manifestTestStrategy{}, 1))
+	assert.Nil(t, err)
+
+	//Market data is blocked on sending the next event when shutdown is requested
+	e.Pause()
+	done := make(chan struct{})
+	go func() {
+		e.Run()
+		close(done)
+	}()
+	e.Step()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.Nil(t, e.Shutdown(ctx))
+	<-done
+	assert.Nil(t, e.Status().Err)
+	assertNoChunks(t, dir)
+}
+
+func TestEngine_ShutdownTimeout(t *testing.T) {
+	c := Engine{mut: &sync.Mutex{}}
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.Equal(t, context.DeadlineExceeded, c.Shutdown(ctx))
+	assert.NotNil(t, c.ctx.Err())
+	//Run started after shutdown request stops right away
+	select {
+	case <-c.stopChan():
+	default:
+		t.Error("Shutdown request is lost")
+	}
+
+	c.endRun()
+	select {
+	case <-c.stopChan():
+		t.Error("Shutdown request is kept for the next run")
+	default:
+	}
+}
+
+func TestEngine_drainEvents(t *testing.T) {
+	c := Engine{events: make(chan event, 1), errChan: make(chan error),
+		portfolioChan: make(chan *PortfolioNewPositionEvent), portfolio: newPortfolio(), mut: &sync.Mutex{},
+		waitG: &sync.WaitGroup{}}
+	c.log.SetOutput(ioutil.Discard)
+	stop := c.drainEvents()
+
+	//Unbuffered error chan doesn't block senders during shutdown
+	done := make(chan struct{})
+	go func() {
+		c.errChan <- assert.AnError
+		close(done)
+	}()
+	<-done
+	c.events <- &CustomEvent{BaseEvent: be(time.Now(), newTestInstrument())}
+	stop()
+	assert.Len(t, c.events, 0)
+	assert.Len(t, c.customEvents, 1)
+}
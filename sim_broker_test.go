@@ -52,6 +52,30 @@ func TestEventArray_Sort(t *testing.T) {
 	}
 }
 
+func TestEventArray_SortSameTime(t *testing.T) {
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	first := &OrderConfirmationEvent{BaseEvent: be(tm.Add(500*time.Millisecond), newTestInstrument()), OrdId: "1"}
+	second := &OrderFillEvent{BaseEvent: be(tm.Add(500*time.Millisecond), newTestInstrument()), OrdId: "1"}
+	earlier := &OrderConfirmationEvent{BaseEvent: be(tm.Add(100*time.Millisecond), newTestInstrument()), OrdId: "2"}
+	assert.True(t, first.Seq < second.Seq)
+
+	events := eventArray{second, first, earlier}
+	events.sort()
+	//Sub second time is respected and events of the same time keep order of generation
+	assert.Equal(t, eventArray{earlier, first, second}, events)
+}
+
+func TestEventBefore(t *testing.T) {
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	a := &TimerEvent{BaseEvent: be(tm, newTestInstrument())}
+	b := &TimerEvent{BaseEvent: be(tm, newTestInstrument())}
+	c := &TimerEvent{BaseEvent: be(tm.Add(-time.Nanosecond), newTestInstrument())}
+	assert.True(t, eventBefore(a, b))
+	assert.False(t, eventBefore(b, a))
+	assert.False(t, eventBefore(a, a))
+	assert.True(t, eventBefore(c, a))
+}
+
 func newTestSimBrokerWorker() *simBrokerWorker {
 	w := simBrokerWorker{
 		symbol:            newTestInstrument(),
@@ -424,6 +448,25 @@ func TestSimulatedBroker_OnReplaceRequest(t *testing.T) {
 	}
 }
 
+func TestSimulatedBroker_OnReplaceQtyRequest(t *testing.T) {
+	b := newTestSimBrokerWorker()
+	order := newTestOrder(15, OrderSell, 300, "qty")
+	v := putNewOrderToWorkerAndGetBrokerEvent(b, order)
+	assert.IsType(t, &OrderConfirmationEvent{}, v)
+	b.orders[order.Id].BrokerExecQty = 100
+
+	b.onReplaceRequest(&OrderReplaceRequestEvent{OrdId: order.Id, NewQty: 100})
+	assert.IsType(t, &OrderReplaceRejectEvent{}, b.generatedEvents[len(b.generatedEvents)-1])
+
+	b.onReplaceRequest(&OrderReplaceRequestEvent{OrdId: order.Id, NewQty: 200})
+	e := b.generatedEvents[len(b.generatedEvents)-1].(*OrderReplacedEvent)
+	assert.Equal(t, 15.0, e.NewPrice)
+	assert.Equal(t, int64(200), e.NewQty)
+
+	assert.Equal(t, int64(200), b.orders[order.Id].qty())
+	assert.Equal(t, int64(300), order.Qty)
+}
+
 func newTestGtcBrokerOrder(price float64, side OrderSide, qty int64, id string) *simBrokerOrder {
 	ord := newTestOrder(price, side, qty, id)
 	o := simBrokerOrder{
@@ -0,0 +1,72 @@
+package engine
+
+import "math"
+
+//Equity returns cash plus market value of open positions of all strategies
+func (b *BasicStrategy) Equity() float64 {
+	return b.portfolio.Equity()
+}
+
+//Cash returns cash balance of the account shared by all strategies
+func (b *BasicStrategy) Cash() float64 {
+	return b.portfolio.Cash()
+}
+
+//unitValue returns value of one qty unit of the instrument at price in account currency
+func (b *BasicStrategy) unitValue(price float64) float64 {
+	v := b.symbol.QtyFloat(1) * price * b.symbol.multiplier()
+	if b.symbol.FX != nil && b.portfolio != nil {
+		v = b.portfolio.fx.toAccount(v, b.symbol.FX.Quote)
+	}
+	return v
+}
+
+//roundToLot rounds qty down to whole number of lots of the instrument
+func (b *BasicStrategy) roundToLot(qty float64) int64 {
+	if math.IsNaN(qty) || math.IsInf(qty, 0) || qty <= 0 {
+		return 0
+	}
+	lot := b.symbol.LotSize
+	if lot <= 0 {
+		lot = 1
+	}
+	return int64(math.Floor(qty/float64(lot))) * lot
+}
+
+//SizeFixedFractional returns qty which market value at price is fraction of allocated equity, e.g. 0.1 for 10%
+func (b *BasicStrategy) SizeFixedFractional(fraction float64, price float64) int64 {
+	return b.SizeMaxNotional(b.AllocatedEquity()*fraction, price)
+}
+
+//SizeVolatilityTarget returns qty which loses riskFraction of allocated equity if price moves by atrMultiple of ATR.
+//ATR is usually taken from indicator added with AddIndicator.
+func (b *BasicStrategy) SizeVolatilityTarget(riskFraction float64, atr float64, atrMultiple float64) int64 {
+	risk := b.unitValue(atr * atrMultiple)
+	if risk <= 0 {
+		return 0
+	}
+	return b.roundToLot(b.AllocatedEquity() * riskFraction / risk)
+}
+
+//SizeKelly returns qty sized by Kelly criterion. WinRate is probability of winning trade, winLossRatio is
+//average win divided by average loss. Kelly fraction of allocated equity is multiplied by scale, e.g. 0.5 for
+//half Kelly. Zero is returned if Kelly fraction is negative.
+func (b *BasicStrategy) SizeKelly(winRate float64, winLossRatio float64, scale float64, price float64) int64 {
+	if winLossRatio <= 0 {
+		return 0
+	}
+	kelly := winRate - (1-winRate)/winLossRatio
+	if kelly <= 0 {
+		return 0
+	}
+	return b.SizeFixedFractional(kelly*scale, price)
+}
+
+//SizeMaxNotional returns max qty which market value at price doesn't exceed notional in account currency
+func (b *BasicStrategy) SizeMaxNotional(notional float64, price float64) int64 {
+	value := b.unitValue(price)
+	if value <= 0 {
+		return 0
+	}
+	return b.roundToLot(notional / value)
+}
@@ -0,0 +1,54 @@
+package engine
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newTestSizingStrategy(capital float64) *BasicStrategy {
+	st := newTestBasicStrategy()
+	p := newPortfolio()
+	p.setCapital(capital)
+	st.setPortfolio(p)
+	return st
+}
+
+func TestBasicStrategy_SizeFixedFractional(t *testing.T) {
+	st := newTestSizingStrategy(100000)
+	assert.Equal(t, 100000.0, st.Equity())
+	st.symbol.LotSize = 1
+	assert.Equal(t, int64(333), st.SizeFixedFractional(0.1, 30))
+
+	st.symbol.LotSize = 100
+	assert.Equal(t, int64(300), st.SizeFixedFractional(0.1, 30))
+	assert.Equal(t, int64(0), st.SizeFixedFractional(0.01, 30))
+	assert.Equal(t, int64(0), st.SizeFixedFractional(0.1, 0))
+
+	st.symbol.LotSize = 1
+	st.symbol.Multiplier = 50
+	assert.Equal(t, int64(6), st.SizeFixedFractional(0.1, 30))
+}
+
+func TestBasicStrategy_SizeVolatilityTarget(t *testing.T) {
+	st := newTestSizingStrategy(100000)
+	st.symbol.LotSize = 1
+	//1% of equity at risk with stop at 2 ATR of 0.5
+	assert.Equal(t, int64(1000), st.SizeVolatilityTarget(0.01, 0.5, 2))
+	assert.Equal(t, int64(0), st.SizeVolatilityTarget(0.01, 0, 2))
+}
+
+func TestBasicStrategy_SizeKelly(t *testing.T) {
+	st := newTestSizingStrategy(100000)
+	//Kelly fraction is 0.6 - 0.4/2 = 0.4, half Kelly is 0.2 of equity
+	assert.Equal(t, int64(400), st.SizeKelly(0.6, 2, 0.5, 50))
+	assert.Equal(t, int64(0), st.SizeKelly(0.3, 1, 0.5, 50))
+}
+
+func TestBasicStrategy_SizeMaxNotional(t *testing.T) {
+	st := newTestSizingStrategy(100000)
+	st.symbol = NewFXInstrument("EUR", "JPY", 0.01, 1000)
+	st.portfolio.fx.setAccountCurrency("USD")
+	st.portfolio.fx.rates.SetRate("USD", "JPY", 100)
+	//One EUR costs 130 JPY or 1.3 USD
+	assert.Equal(t, int64(7000), st.SizeMaxNotional(10000, 130))
+}
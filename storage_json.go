@@ -0,0 +1,143 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+)
+
+//JSONStorage keeps market data in JSON files of the folder: ticks of every day in <symbol>/<yyyy-mm-dd>.json
+//and candles in <symbol>.json. Candles of every timeframe should have their own folder. It's BTM storage
+//and storage which DataDownloader saves data to.
+type JSONStorage struct {
+	Folder string
+}
+
+func NewJSONStorage(folder string) *JSONStorage {
+	return &JSONStorage{Folder: folder}
+}
+
+//GetStoredTicks reads ticks of every day of the date range. Days without file have no ticks.
+func (s *JSONStorage) GetStoredTicks(symbol string, dRange marketdata.DateRange, quotes bool, trades bool) (marketdata.TickArray, error) {
+	var ticks marketdata.TickArray
+	from := time.Date(dRange.From.Year(), dRange.From.Month(), dRange.From.Day(), 0, 0, 0, 0, time.UTC)
+	for d := from; !d.After(dRange.To); d = d.AddDate(0, 0, 1) {
+		var dayTicks marketdata.TickArray
+		err := readJSONFile(path.Join(s.Folder, symbol, d.Format("2006-01-02")+".json"), &dayTicks)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		for _, t := range dayTicks {
+			if t.Datetime.Before(dRange.From) || t.Datetime.After(dRange.To) {
+				continue
+			}
+			t.Symbol = symbol
+			if !quotes {
+				t.BidPrice, t.BidSize, t.AskPrice, t.AskSize = 0, 0, 0, 0
+			}
+			if !trades {
+				t.LastPrice, t.LastSize = 0, 0
+			}
+			ticks = append(ticks, t)
+		}
+	}
+	return ticks, nil
+}
+
+//GetStoredCandles reads candles of the symbol. Zero date range means all stored candles.
+func (s *JSONStorage) GetStoredCandles(symbol string, tf string, dRange marketdata.DateRange) (marketdata.CandleArray, error) {
+	var stored marketdata.CandleArray
+	if err := readJSONFile(path.Join(s.Folder, symbol+".json"), &stored); err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var candles marketdata.CandleArray
+	for _, c := range stored {
+		if !dRange.From.IsZero() && c.Datetime.Before(dRange.From) {
+			continue
+		}
+		if !dRange.To.IsZero() && c.Datetime.After(dRange.To) {
+			continue
+		}
+		c.Symbol = symbol
+		candles = append(candles, c)
+	}
+	return candles, nil
+}
+
+//SaveTicks writes ticks to per day files. Ticks of one day replace previously saved file of this day.
+func (s *JSONStorage) SaveTicks(symbol string, ticks marketdata.TickArray) error {
+	days := make(map[string]marketdata.TickArray)
+	var order []string
+	for _, t := range ticks {
+		d := t.Datetime.UTC().Format("2006-01-02")
+		if _, ok := days[d]; !ok {
+			order = append(order, d)
+		}
+		days[d] = append(days[d], t)
+	}
+
+	err := createDirIfNotExists(path.Join(s.Folder, symbol))
+	if err != nil {
+		return err
+	}
+
+	for _, d := range order {
+		if err := writeJSONFile(path.Join(s.Folder, symbol, d+".json"), days[d]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//SaveCandles merges candles with already saved ones. Saved candles with the same time are replaced.
+func (s *JSONStorage) SaveCandles(symbol string, tf string, candles marketdata.CandleArray) error {
+	stored, err := s.GetStoredCandles(symbol, tf, marketdata.DateRange{})
+	if err != nil {
+		return err
+	}
+
+	byTime := make(map[int64]*marketdata.Candle)
+	for _, c := range stored {
+		byTime[c.Datetime.Unix()] = c
+	}
+	for _, c := range candles {
+		byTime[c.Datetime.Unix()] = c
+	}
+
+	var merged marketdata.CandleArray
+	for _, c := range byTime {
+		merged = append(merged, c)
+	}
+	merged.Sort()
+
+	err = createDirIfNotExists(s.Folder)
+	if err != nil {
+		return err
+	}
+	return writeJSONFile(path.Join(s.Folder, symbol+".json"), merged)
+}
+
+func readJSONFile(pth string, v interface{}) error {
+	data, err := ioutil.ReadFile(pth)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func writeJSONFile(pth string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(pth, data, 0644)
+}
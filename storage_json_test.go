@@ -0,0 +1,45 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestJSONStorage(t *testing.T) {
+	folder, err := ioutil.TempDir("", "json_storage")
+	assert.Nil(t, err)
+	defer os.RemoveAll(folder)
+
+	s := NewJSONStorage(folder)
+	day := time.Date(2018, 3, 5, 0, 0, 0, 0, time.UTC)
+	ticks := marketdata.TickArray{
+		{Datetime: day.Add(10 * time.Hour), LastPrice: 10, LastSize: 100, BidPrice: 9.9},
+		{Datetime: day.Add(34 * time.Hour), LastPrice: 11, LastSize: 200, BidPrice: 10.9},
+	}
+	assert.Nil(t, s.SaveTicks("Test", ticks))
+
+	stored, err := s.GetStoredTicks("Test", marketdata.DateRange{From: day, To: day.Add(47 * time.Hour)}, false, true)
+	assert.Nil(t, err)
+	assert.Len(t, stored, 2)
+	assert.Equal(t, "Test", stored[0].Symbol)
+	assert.Equal(t, 11.0, stored[1].LastPrice)
+	assert.Equal(t, 0.0, stored[1].BidPrice)
+
+	holidays := marketdata.DateRange{From: day.AddDate(0, 0, 5), To: day.AddDate(0, 0, 6)}
+	stored, err = s.GetStoredTicks("Test", holidays, true, true)
+	assert.Nil(t, err)
+	assert.Len(t, stored, 0)
+
+	candles := marketdata.CandleArray{{Datetime: day, Close: 10}, {Datetime: day.AddDate(0, 0, 1), Close: 11}}
+	assert.Nil(t, s.SaveCandles("Test", "D", candles))
+	assert.Nil(t, s.SaveCandles("Test", "D", marketdata.CandleArray{{Datetime: day.AddDate(0, 0, 1), Close: 12}}))
+	rng := marketdata.DateRange{From: day.AddDate(0, 0, 1), To: day.AddDate(0, 0, 2)}
+	saved, err := s.GetStoredCandles("Test", "D", rng)
+	assert.Nil(t, err)
+	assert.Len(t, saved, 1)
+	assert.Equal(t, 12.0, saved[0].Close)
+}
@@ -1,9 +1,9 @@
 package engine
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"log"
 	"math"
 	"math/rand"
 	"os"
@@ -43,9 +43,17 @@ type ICoreStrategy interface {
 	candles() CandleArray
 	setPortfolio(p *portfolioHandler)
 	enableEventLogging()
+	setLogger(l ILogger)
+	setContext(ctx context.Context)
 	notify(e event)
 	shutDown()
 	getInstrument() *Instrument
+	saveState() *StrategyState
+	restoreState(s *StrategyState) error
+	setStrictMode(strict bool)
+	setSynchronous(on bool)
+	setClock(clock Clock)
+	setBackpressure(cfg *BackpressureConfig)
 }
 
 type IUserStrategy interface {
@@ -54,11 +62,73 @@ type IUserStrategy interface {
 	OnCandleOpen(b *BasicStrategy, price float64)
 }
 
+//IDataGapUserStrategy can be implemented by user strategy to be notified when market data has a gap
+//larger than threshold (missing sessions, feed outages), e.g. to flatten position or to pause trading.
+type IDataGapUserStrategy interface {
+	OnDataGap(b *BasicStrategy, from time.Time, to time.Time)
+}
+
+//IMultiTimeFrameUserStrategy can be implemented by user strategy subscribed to several timeframes.
+//If it's implemented OnTimeFrameCandleClose is called for closed candles of every timeframe (base timeframe
+//included) instead of OnCandleClose.
+type IMultiTimeFrameUserStrategy interface {
+	OnTimeFrameCandleClose(b *BasicStrategy, candle *Candle, timeFrame string)
+}
+
+//IUniverseUserStrategy can be implemented by user strategy to be notified when its symbol enters or
+//leaves universe. When symbol is removed its open orders are canceled and new orders are not accepted.
+type IUniverseUserStrategy interface {
+	OnSymbolAdded(b *BasicStrategy)
+	OnSymbolRemoved(b *BasicStrategy)
+}
+
+//IBacktestEndUserStrategy can be implemented by user strategy to be called when market data has no more events,
+//e.g. to flush custom stats or write artifacts. Orders sent here are executed only if broker can do it without
+//new market data.
+type IBacktestEndUserStrategy interface {
+	OnBacktestEnd(b *BasicStrategy)
+}
+
+//IRollUserStrategy can be implemented by user strategy of futures chain root to be notified when front
+//contract changes. Position and orders are already moved to the new contract when OnRoll is called.
+type IRollUserStrategy interface {
+	OnRoll(b *BasicStrategy, from *Instrument, to *Instrument)
+}
+
+//IOptionChainUserStrategy can be implemented by user strategy to get option chain snapshots of its symbol
+type IOptionChainUserStrategy interface {
+	OnOptionChain(b *BasicStrategy, quotes []*OptionQuote)
+}
+
+//IFundamentalUserStrategy can be implemented by user strategy to get fundamental data of its symbol
+type IFundamentalUserStrategy interface {
+	OnFundamentalData(b *BasicStrategy, e *FundamentalDataEvent)
+}
+
+//INewsUserStrategy can be implemented by user strategy to get news of its symbol
+type INewsUserStrategy interface {
+	OnNews(b *BasicStrategy, e *NewsEvent)
+}
+
+//ICustomEventUserStrategy can be implemented by user strategy to get custom events
+type ICustomEventUserStrategy interface {
+	OnCustomEvent(b *BasicStrategy, e *CustomEvent)
+}
+
+//ISessionUserStrategy can be implemented by user strategy to be called at session open and close of its
+//exchange. Session times come from exchange calendar, so holidays are skipped and early closes are used.
+type ISessionUserStrategy interface {
+	OnSessionOpen(b *BasicStrategy, t time.Time)
+	OnSessionClose(b *BasicStrategy, t time.Time)
+}
+
 type BasicStrategy struct {
 	portfolio *portfolioHandler
 	isReady   bool
 	symbol    *Instrument
 	nPeriods  int
+	//name is set for strategy in StrategyGroup, it's part of its order IDs
+	name string
 
 	ch                         CoreStrategyChannels
 	terminationChan            chan struct{}
@@ -66,26 +136,73 @@ type BasicStrategy struct {
 	waitingN                   int32
 	closedTrades               []*Trade
 	currentTrade               *Trade
+	shortTrade                 *Trade
 	Ticks                      TickArray
 	Candles                    CandleArray
 	lastCandleOpen             float64
+	baseTimeFrame              string
+	dataGaps                   int
+	removedFromUniverse        bool
+	delisted                   bool
+	orders                     map[string]*orderRecord
+	indicators                 map[string]IIndicator
+	stopLoss                   *exitOrder
+	timers                     *strategyTimers
+	params                     *Params
+	paramValues                map[string]float64
+	takeProfit                 *exitOrder
+	target                     *targetPosition
+	risk                       riskState
+	pyramiding                 PyramidingRules
+	trailing                   *trailingStop
+	lots                       lotBook
+	dividendIncome             float64
+	borrow                     borrowState
+	financingPending           float64
+	strict                     bool
+	synchronous                bool
+	queue                      *strategyQueue
+	loop                       *strategyQueue
+	metrics                    IMetrics
+	panics                     panicState
+	decision                   decisionState
+	clock                      Clock
+	indicatorsOrder            []string
+	optionChain                *OptionChainEvent
+	fundamentals               *FundamentalDataEvent
+	timeFrames                 map[string]*timeFrameCandles
+	timeFramesOrder            []string
 	lastCandleOpenTime         time.Time
 	userStrategy               IUserStrategy
 	mostRecentTime             time.Time
 	mut                        *sync.Mutex
-	isEventLoggingEnabled      bool
 	isEventSliceStorageEnabled bool
 
-	log                log.Logger
+	logger             ILogger
+	ctx                context.Context
 	eventsLoggingSlice eventsSliceStorage
-	mdChan             chan event
 	handlersWaitGroup  *sync.WaitGroup
 }
 
+//NewBasicStrategy creates strategy for the symbol. It can be used in StrategyFactory of the engine.
+func NewBasicStrategy(symbol *Instrument, nPeriods int, userStrategy IUserStrategy) *BasicStrategy {
+	b := BasicStrategy{
+		symbol:       symbol,
+		nPeriods:     nPeriods,
+		userStrategy: userStrategy,
+	}
+	return &b
+}
+
 //******* Connection methods ***********************
 
 func (b *BasicStrategy) shutDown() {
-	b.handlersWaitGroup.Wait()
+	waitContext(b.ctx, b.handlersWaitGroup)
+	b.closeQueue()
+}
+
+func (b *BasicStrategy) setContext(ctx context.Context) {
+	b.ctx = ctx
 }
 
 func (b *BasicStrategy) getInstrument() *Instrument{
@@ -99,7 +216,28 @@ func (b *BasicStrategy) init(ch CoreStrategyChannels) {
 	b.ch = ch
 	b.terminationChan = make(chan struct{})
 	b.waitingConfirmation = make(map[string]struct{})
-	b.mut = &sync.Mutex{}
+	b.orders = make(map[string]*orderRecord)
+	if b.timers == nil {
+		b.timers = &strategyTimers{}
+	}
+	if _, ok := b.userStrategy.(ISessionUserStrategy); ok {
+		b.addTimer(&strategyTimer{name: sessionOpenTimerName, kind: sessionOpenTimer})
+		b.addTimer(&strategyTimer{name: sessionCloseTimerName, kind: sessionCloseTimer})
+	}
+	if b.mut == nil {
+		b.mut = &sync.Mutex{}
+	}
+
+	if b.handlersWaitGroup == nil {
+		b.handlersWaitGroup = &sync.WaitGroup{}
+	}
+	if b.loop == nil {
+		b.loop = b.newQueue(eventLoopConfig)
+	}
+
+	if err := b.initParams(); err != nil {
+		b.newError(err)
+	}
 
 	if b.currentTrade == nil {
 		b.currentTrade = newFlatTrade(b.symbol)
@@ -114,6 +252,11 @@ func (b *BasicStrategy) init(ch CoreStrategyChannels) {
 
 func (b *BasicStrategy) setPortfolio(p *portfolioHandler) {
 	b.portfolio = p
+	for _, t := range b.trades() {
+		if t.fx == nil {
+			t.fx = p.fx
+		}
+	}
 }
 
 //*******API CALLS************************************************
@@ -121,68 +264,134 @@ func (b *BasicStrategy) GetTotalPnL() float64 {
 	return b.portfolio.totalPnL()
 }
 
-func (b *BasicStrategy) OpenOrders() map[string]*Order {
-	return b.currentTrade.ConfirmedOrders
+//Context returns context of the engine run. It's done when run is stopped, so long running user callbacks
+//can check it and return early.
+func (b *BasicStrategy) Context() context.Context {
+	if b.ctx == nil {
+		return context.Background()
+	}
+	return b.ctx
 }
 
-func (b *BasicStrategy) Position() int64 {
-	if b.currentTrade.Type == FlatTrade || b.currentTrade.Type == ClosedTrade {
-		return 0
+//Portfolio returns read only view of positions of all strategies of the engine
+func (b *BasicStrategy) Portfolio() IPortfolio {
+	return b.portfolio
+}
+
+//PublishCustomEvent sends custom event to strategy of the symbol or to all strategies if symbol is nil. Event
+//has time of the last strategy event and is delivered before market data of later time.
+func (b *BasicStrategy) PublishCustomEvent(name string, payload interface{}, symbol *Instrument) {
+	e := CustomEvent{
+		BaseEvent: be(b.now(), symbol),
+		Name:      name,
+		Payload:   payload,
 	}
-	pos := b.currentTrade.Qty
-	if b.currentTrade.Type == ShortTrade {
-		pos = -pos
+	b.newSignal(&e)
+}
+
+func (b *BasicStrategy) OpenOrders() map[string]*Order {
+	if b.shortTrade == nil {
+		return b.currentTrade.ConfirmedOrders
+	}
+	orders := make(map[string]*Order)
+	for _, t := range b.trades() {
+		for id, o := range t.ConfirmedOrders {
+			orders[id] = o
+		}
 	}
+	return orders
+}
 
+//Position returns net position of the strategy. In hedging mode it's long sub-position minus short one.
+func (b *BasicStrategy) Position() int64 {
+	var pos int64
+	for _, t := range b.trades() {
+		pos += t.position()
+	}
 	return pos
-
 }
 
 func (b *BasicStrategy) IsOrderConfirmed(ordId string) bool {
-	return b.currentTrade.hasConfirmedOrderWithId(ordId)
+	return b.tradeOfOrder(ordId).hasConfirmedOrderWithId(ordId)
 }
 
-func (b *BasicStrategy) OrderStatus(ordId string) OrderState {
-	//Todo
-	return ""
+//OrderStatus returns current state of the order with given id. Orders of closed trades are found too.
+func (b *BasicStrategy) OrderStatus(ordId string) (*OrderStatusInfo, error) {
+	r, ok := b.orders[ordId]
+	if !ok {
+		err := ErrOrderNotFoundInOrdersMap{
+			OrdId:   ordId,
+			Message: "Order is unknown. ",
+			Caller:  "OrderStatus func",
+		}
+		return nil, &err
+	}
+	return r.info(b), nil
 }
 
 func (b *BasicStrategy) NewLimitOrder(price float64, side OrderSide, qty int64, tif OrderTIF, destination string) (string, error) {
-	order := Order{
-		Side:        side,
-		Qty:         qty,
-		Ticker:      b.symbol,
-		Price:       price,
-		State:       NewOrder,
-		Type:        LimitOrder,
-		Tif:         tif,
-		Destination: destination,
-		Time:        b.mostRecentTime.Add(20 * time.Microsecond),
-		Id:          fmt.Sprintf("%v_%v_%v", price, LimitOrder, rand.Float64()),
-	}
+	return b.newTypedOrder(LimitOrder, price, side, qty, tif, destination)
+}
 
-	err := b.newOrder(&order)
-	return order.Id, err
+func (b *BasicStrategy) NewMarketOrder(side OrderSide, qty int64, tif OrderTIF, destination string) (string, error) {
+	return b.newTypedOrder(MarketOrder, math.NaN(), side, qty, tif, destination)
+}
 
+//NewStopOrder sends stop order which becomes market order when price reaches stop price
+func (b *BasicStrategy) NewStopOrder(price float64, side OrderSide, qty int64, tif OrderTIF, destination string) (string, error) {
+	return b.newTypedOrder(StopOrder, price, side, qty, tif, destination)
 }
 
-func (b *BasicStrategy) NewMarketOrder(side OrderSide, qty int64, tif OrderTIF, destination string) (string, error) {
+//NewMarketOnOpenOrder sends order executed at open price of the next session
+func (b *BasicStrategy) NewMarketOnOpenOrder(side OrderSide, qty int64, destination string) (string, error) {
+	return b.newTypedOrder(MarketOnOpen, math.NaN(), side, qty, DayTIF, destination)
+}
+
+//NewMarketOnCloseOrder sends order executed at close price of the session
+func (b *BasicStrategy) NewMarketOnCloseOrder(side OrderSide, qty int64, destination string) (string, error) {
+	return b.newTypedOrder(MarketOnClose, math.NaN(), side, qty, DayTIF, destination)
+}
+
+//NewLimitOnOpenOrder sends order executed at open price of the next session if open price is not worse than limit
+func (b *BasicStrategy) NewLimitOnOpenOrder(price float64, side OrderSide, qty int64, destination string) (string, error) {
+	return b.newTypedOrder(LimitOnOpen, price, side, qty, DayTIF, destination)
+}
+
+//NewLimitOnCloseOrder sends order executed at close price of the session if close price is not worse than limit
+func (b *BasicStrategy) NewLimitOnCloseOrder(price float64, side OrderSide, qty int64, destination string) (string, error) {
+	return b.newTypedOrder(LimitOnClose, price, side, qty, DayTIF, destination)
+}
+
+func (b *BasicStrategy) newTypedOrder(orderType OrderType, price float64, side OrderSide, qty int64, tif OrderTIF, destination string) (string, error) {
+	if side != OrderBuy && side != OrderSell {
+		return "", fmt.Errorf("Unknown order side: %v. ", side)
+	}
+	if qty <= 0 {
+		return "", fmt.Errorf("Order qty should be positive: %v. ", qty)
+	}
 	order := Order{
 		Side:        side,
 		Qty:         qty,
 		Ticker:      b.symbol,
-		Price:       math.NaN(),
+		Price:       price,
 		State:       NewOrder,
-		Type:        MarketOrder,
+		Type:        orderType,
 		Tif:         tif,
 		Destination: destination,
-		Time:        b.mostRecentTime,
-		Id:          fmt.Sprintf("%v_%v", MarketOrder, rand.Float64()),
+		Time:        b.now(),
+	}
+	if order.IsPriced() {
+		if math.IsNaN(price) || math.IsInf(price, 0) || price <= 0 {
+			return "", fmt.Errorf("%v order price should be positive: %v. ", orderType, price)
+		}
+		order.Time = b.now().Add(20 * time.Microsecond)
+		order.Id = fmt.Sprintf("%v_%v_%v", price, orderType, rand.Float64())
+	} else {
+		order.Id = fmt.Sprintf("%v_%v", orderType, rand.Float64())
 	}
 
 	err := b.newOrder(&order)
 	return order.Id, err
-
 }
 
 func (b *BasicStrategy) CancelOrder(ordID string) error {
@@ -196,7 +405,8 @@ func (b *BasicStrategy) CancelOrder(ordID string) error {
 		return &err
 	}
 
-	if !b.currentTrade.hasConfirmedOrderWithId(ordID) {
+	trade := b.tradeOfOrder(ordID)
+	if !trade.hasConfirmedOrderWithId(ordID) {
 		err := ErrOrderNotFoundInConfirmedMap{
 			ErrOrderNotFoundInOrdersMap{
 				OrdId:   ordID,
@@ -209,8 +419,9 @@ func (b *BasicStrategy) CancelOrder(ordID string) error {
 
 	cancelReq := OrderCancelRequestEvent{
 		OrdId:     ordID,
-		BaseEvent: be(b.mostRecentTime.Add(20*time.Microsecond), b.currentTrade.ConfirmedOrders[ordID].Ticker),
+		BaseEvent: be(b.now().Add(20*time.Microsecond), trade.ConfirmedOrders[ordID].Ticker),
 	}
+	cancelReq.CorrelationID = trade.ConfirmedOrders[ordID].CorrelationID
 
 	reqID := "$CAN$" + ordID
 	if _, ok := b.waitingConfirmation[reqID]; ok {
@@ -235,7 +446,7 @@ func (b *BasicStrategy) ReplaceOrder(ordID string, newPrice float64) error {
 		return &err
 	}
 
-	if !b.currentTrade.hasConfirmedOrderWithId(ordID) {
+	if !b.tradeOfOrder(ordID).hasConfirmedOrderWithId(ordID) {
 		err := ErrOrderNotFoundInConfirmedMap{
 			ErrOrderNotFoundInOrdersMap{
 				OrdId:   ordID,
@@ -249,8 +460,9 @@ func (b *BasicStrategy) ReplaceOrder(ordID string, newPrice float64) error {
 	replaceReq := OrderReplaceRequestEvent{
 		OrdId:     ordID,
 		NewPrice:  newPrice,
-		BaseEvent: be(b.mostRecentTime.Add(20*time.Microsecond), b.symbol),
+		BaseEvent: be(b.now().Add(20*time.Microsecond), b.symbol),
 	}
+	replaceReq.CorrelationID = b.tradeOfOrder(ordID).ConfirmedOrders[ordID].CorrelationID
 
 	reqID := "$REP$" + ordID
 	if _, ok := b.waitingConfirmation[reqID]; ok {
@@ -265,13 +477,280 @@ func (b *BasicStrategy) ReplaceOrder(ordID string, newPrice float64) error {
 	return nil
 }
 
+//ReplaceOrderQty changes qty of the confirmed order. New qty should be larger than executed qty of the order.
+//Price of the order is not changed.
+func (b *BasicStrategy) ReplaceOrderQty(ordID string, newQty int64) error {
+	order, ok := b.tradeOfOrder(ordID).ConfirmedOrders[ordID]
+	if !ok {
+		err := ErrOrderNotFoundInConfirmedMap{
+			ErrOrderNotFoundInOrdersMap{
+				OrdId:   ordID,
+				Message: "Order is not confirmed. ",
+				Caller:  "ReplaceOrderQty func",
+			},
+		}
+		return &err
+	}
+	if newQty <= order.ExecQty {
+		return fmt.Errorf("New qty %v should be larger than executed qty %v. ", newQty, order.ExecQty)
+	}
+	if newQty > order.Qty {
+		if err := b.checkOrderRisk(&Order{Side: order.Side, Qty: newQty - order.Qty}); err != nil {
+			return err
+		}
+	}
+
+	replaceReq := OrderReplaceRequestEvent{
+		OrdId:     ordID,
+		NewQty:    newQty,
+		BaseEvent: be(b.now().Add(20*time.Microsecond), b.symbol),
+	}
+	replaceReq.CorrelationID = order.CorrelationID
+
+	reqID := "$REP$" + ordID
+	if _, ok := b.waitingConfirmation[reqID]; ok {
+		return errors.New("Request is already waiting for conf. ")
+	}
+	b.waitingConfirmation[reqID] = struct{}{}
+	atomic.AddInt32(&b.waitingN, 1)
+
+	b.newSignal(&replaceReq)
+	return nil
+}
+
 func (b *BasicStrategy) LastCandleOpen() float64 {
 	return b.lastCandleOpen
 }
 
+//OptionChain returns quotes of the last option chain snapshot of strategy symbol or nil if there was no snapshot yet
+func (b *BasicStrategy) OptionChain() []*OptionQuote {
+	if b.optionChain == nil {
+		return nil
+	}
+	return b.optionChain.Quotes
+}
+
+//Fundamentals returns last fundamental data of the symbol or nil if there was no data yet
+func (b *BasicStrategy) Fundamentals() *FundamentalDataEvent {
+	return b.fundamentals
+}
+
+//AddIndicator adds indicator with given name. Indicator is updated with candles and ticks which are already
+//in strategy buffers and then with every new closed candle of base timeframe (and tick for ITickIndicator)
+//before user strategy callbacks.
+func (b *BasicStrategy) AddIndicator(name string, ind IIndicator) {
+	if b.indicators == nil {
+		b.indicators = make(map[string]IIndicator)
+	}
+	if _, ok := b.indicators[name]; !ok {
+		b.indicatorsOrder = append(b.indicatorsOrder, name)
+	}
+	b.indicators[name] = ind
+
+	for _, c := range b.Candles {
+		ind.Update(c)
+	}
+	if ti, ok := ind.(ITickIndicator); ok {
+		for _, t := range b.Ticks {
+			ti.UpdateTick(t)
+		}
+	}
+}
+
+//Indicator returns indicator added with given name or nil
+func (b *BasicStrategy) Indicator(name string) IIndicator {
+	return b.indicators[name]
+}
+
+func (b *BasicStrategy) updateIndicators(c *Candle) {
+	for _, name := range b.indicatorsOrder {
+		b.indicators[name].Update(c)
+	}
+}
+
+func (b *BasicStrategy) updateTickIndicators(t *Tick) {
+	for _, name := range b.indicatorsOrder {
+		if ti, ok := b.indicators[name].(ITickIndicator); ok {
+			ti.UpdateTick(t)
+		}
+	}
+}
+
+//AddTimer adds timer which calls OnTimer of user strategy every interval. Fire times are aligned to interval,
+//e.g. every 5 minutes timer fires at 10:00, 10:05 and so on. Timer with the same name is replaced.
+func (b *BasicStrategy) AddTimer(name string, every time.Duration) error {
+	if every <= 0 {
+		return errors.New("Timer interval should be positive. ")
+	}
+	b.addTimer(&strategyTimer{name: name, kind: intervalTimer, every: every})
+	return nil
+}
+
+//AddDailyTimer adds timer which calls OnTimer of user strategy at given exchange time of every trading day
+func (b *BasicStrategy) AddDailyTimer(name string, at TimeOfDay) {
+	b.addTimer(&strategyTimer{name: name, kind: dailyTimer, at: at})
+}
+
+func (b *BasicStrategy) addTimer(t *strategyTimer) {
+	if b.timers == nil {
+		b.timers = &strategyTimers{}
+	}
+	b.timers.add(t)
+}
+
+//warmUpPeriods returns number of candles or ticks strategy needs before the first event
+func (b *BasicStrategy) warmUpPeriods() int {
+	return b.nPeriods
+}
+
+//dueTimers returns events of timers which should fire not later than now
+func (b *BasicStrategy) dueTimers(now time.Time) []event {
+	if b.timers == nil {
+		return nil
+	}
+	return b.timers.due(now, b.symbol)
+}
+
+//SetParams sets values of parameters declared by user strategy. It should be called before strategy is
+//passed to engine, values are validated on init.
+func (b *BasicStrategy) SetParams(values map[string]float64) {
+	b.paramValues = values
+}
+
+//Params returns parameters of user strategy. It's nil if user strategy doesn't declare parameters.
+func (b *BasicStrategy) Params() *Params {
+	return b.params
+}
+
+func (b *BasicStrategy) initParams() error {
+	p, err := b.declaredParams()
+	if err != nil {
+		return err
+	}
+	b.params = p
+	return nil
+}
+
+//validateParams checks values set by SetParams against parameters declared by user strategy
+func (b *BasicStrategy) validateParams() error {
+	_, err := b.declaredParams()
+	return err
+}
+
+func (b *BasicStrategy) declaredParams() (*Params, error) {
+	us, ok := b.userStrategy.(IParamsUserStrategy)
+	if !ok {
+		if len(b.paramValues) > 0 {
+			return nil, errors.New("Parameters are set, but user strategy doesn't declare them. ")
+		}
+		return nil, nil
+	}
+	p := NewParams()
+	us.DeclareParams(p)
+	if err := p.SetValues(b.paramValues); err != nil {
+		return nil, err
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+//DataGaps returns number of market data gaps strategy has been notified about
+func (b *BasicStrategy) DataGaps() int {
+	return b.dataGaps
+}
+
+//SetTimeFrames subscribes strategy to additional candle timeframes. Candles of additional timeframes are
+//built from candles of the base timeframe delivered by market data, so they should be higher than base one.
+func (b *BasicStrategy) SetTimeFrames(timeFrames ...string) error {
+	b.timeFrames = make(map[string]*timeFrameCandles)
+	b.timeFramesOrder = nil
+	for _, tf := range timeFrames {
+		if _, err := timeFrameDuration(tf); err != nil {
+			return err
+		}
+		if _, ok := b.timeFrames[tf]; ok {
+			continue
+		}
+		b.timeFrames[tf] = newTimeFrameCandles(tf)
+		b.timeFramesOrder = append(b.timeFramesOrder, tf)
+	}
+	sortTimeFrames(b.timeFramesOrder)
+	return nil
+}
+
+//TimeFrames returns base timeframe and all additional timeframes strategy is subscribed to
+func (b *BasicStrategy) TimeFrames() []string {
+	var tfs []string
+	if b.baseTimeFrame != "" {
+		tfs = append(tfs, b.baseTimeFrame)
+	}
+	for _, tf := range b.timeFramesOrder {
+		if tf != b.baseTimeFrame {
+			tfs = append(tfs, tf)
+		}
+	}
+	return tfs
+}
+
+//CandlesOf returns candles buffer for given timeframe. For base timeframe it's the same as Candles
+func (b *BasicStrategy) CandlesOf(timeFrame string) CandleArray {
+	if timeFrame == b.baseTimeFrame {
+		return b.Candles
+	}
+	if tfc, ok := b.timeFrames[timeFrame]; ok {
+		return tfc.candles
+	}
+	return nil
+}
+
+//AddTimeFrameIndicator adds indicator with given name to the timeframe. Indicator is updated with candles
+//already in timeframe buffer and then once with every closed candle of the timeframe, before user strategy
+//callbacks. Indicators of base timeframe are the same as added with AddIndicator.
+func (b *BasicStrategy) AddTimeFrameIndicator(name string, timeFrame string, ind IIndicator) error {
+	if tfc, ok := b.timeFrames[timeFrame]; ok {
+		tfc.addIndicator(name, ind)
+		return nil
+	}
+	if timeFrame == b.baseTimeFrame && timeFrame != "" {
+		b.AddIndicator(name, ind)
+		return nil
+	}
+	return fmt.Errorf("Strategy is not subscribed to %v timeframe. ", timeFrame)
+}
+
+//IndicatorOf returns indicator added to the timeframe with given name or nil
+func (b *BasicStrategy) IndicatorOf(name string, timeFrame string) IIndicator {
+	if tfc, ok := b.timeFrames[timeFrame]; ok {
+		if ind, ok := tfc.indicators[name]; ok {
+			return ind
+		}
+	}
+	if timeFrame == b.baseTimeFrame {
+		return b.indicators[name]
+	}
+	return nil
+}
+
 //****** MARKET DATA AND EVENT PROCESSORS ******************************************
 
+//notify passes event to the strategy queue if it has one, otherwise to the event loop. In deterministic mode
+//and before init event is handled before notify returns.
 func (b *BasicStrategy) notify(e event) {
+	switch {
+	case b.synchronous:
+		b.deliver(e)
+	case b.queue != nil:
+		b.queue.push(e)
+	case b.loop != nil:
+		b.loop.push(e)
+	default:
+		b.deliver(e)
+	}
+}
+
+func (b *BasicStrategy) deliver(e event) {
 	switch e.(type) {
 	case *NewTickEvent:
 		b.proxyEvent(e)
@@ -279,7 +758,6 @@ func (b *BasicStrategy) notify(e event) {
 		b.sendEventForLogging(e)
 		b.proxyEvent(e)
 	}
-
 }
 
 func (b *BasicStrategy) proxyEvent(e event) {
@@ -304,99 +782,298 @@ func (b *BasicStrategy) proxyEvent(e event) {
 		b.onTickHandler(i)
 	case *TickHistoryEvent:
 		b.onTickHistoryHandler(i)
+	case *CandlesHistoryEvent:
+		b.onCandleHistoryHandler(i)
 	case *CandleCloseEvent:
 		b.onCandleCloseHandler(i)
 	case *CandleOpenEvent:
 		b.onCandleOpenHandler(i)
+	case *DataGapEvent:
+		b.onDataGapHandler(i)
+	case *RollEvent:
+		b.onRollHandler(i)
+	case *OptionChainEvent:
+		b.onOptionChainHandler(i)
+	case *FundamentalDataEvent:
+		b.onFundamentalDataHandler(i)
+	case *NewsEvent:
+		b.onNewsHandler(i)
+	case *DividendEvent:
+		b.onDividendHandler(i)
+	case *AuctionImbalanceEvent:
+		b.onAuctionHandler(i)
+	case *TimerEvent:
+		b.onTimerHandler(i)
+	case *CustomEvent:
+		b.onCustomEventHandler(i)
+	case *SymbolAddedEvent:
+		b.onSymbolAddedHandler(i)
+	case *SymbolRemovedEvent:
+		b.onSymbolRemovedHandler(i)
+	case *DelistingEvent:
+		b.onDelistingHandler(i)
 	case *EndOfDataEvent:
 		b.onEndOfDataHandler(i)
+	case *EndOfDayEvent:
+		b.onEndOfDayHandler(i)
+	case *MarginCallEvent:
+		b.onMarginCallHandler(i)
+	case *PortfolioKillSwitchEvent:
+		b.onKillSwitchHandler(i)
+	case *StalePositionEvent:
+		b.onStalePositionHandler(i)
 
 	default:
-		panic("Unexpected event type in BasicStrategy: " + e.getName())
+		b.anomaly(e, "Unexpected event type in BasicStrategy. ")
 	}
 }
 
 //****** EVENT HANDLERS *******************************************************
 
 func (b *BasicStrategy) onCandleCloseHandler(e *CandleCloseEvent) {
-	<-b.mdChan
-	b.handlersWaitGroup.Add(1)
-	go func() {
-		defer func() {
-			b.handlersWaitGroup.Done()
-			b.mdChan <- e
+	if e == nil {
+		return
+	}
 
-		}()
+	b.mut.Lock()
+	defer b.mut.Unlock()
 
-		if e == nil {
-			return
-		}
+	if e.getTime().After(b.mostRecentTime) {
+		b.mostRecentTime = e.getTime()
+	}
 
-		b.mut.Lock()
-		defer b.mut.Unlock()
+	if !e.Candle.isValid() {
+		return
+	}
 
-		if e.getTime().After(b.mostRecentTime) {
-			b.mostRecentTime = e.getTime()
-		}
+	b.putNewCandle(e.Candle)
+	b.updateIndicators(e.Candle)
+	b.onTrailingStopCandle(e.Candle)
 
-		if !e.Candle.isValid() {
-			return
-		}
+	if b.updateOpenPnL(e.Candle.Close, e.Candle.Datetime) {
+		b.publishPosition()
+		b.checkRiskLimits()
+	}
+	if b.baseTimeFrame == "" {
+		b.baseTimeFrame = e.TimeFrame
+	}
+	closedTimeFrames := b.aggregateTimeFrames(e.Candle, e.TimeFrame)
 
-		b.putNewCandle(e.Candle)
+	if len(b.Candles) >= b.nPeriods {
+		b.onCandleCloseUserStrategy(e.Candle, e.TimeFrame)
+	}
 
-		if b.currentTrade.IsOpen() {
-			err := b.currentTrade.updatePnL(e.Candle.Close, e.Candle.Datetime)
-			if err != nil {
-				b.newError(err)
-			}
+	for _, c := range closedTimeFrames {
+		if len(b.timeFrames[c.timeFrame].candles) < b.nPeriods {
+			continue
 		}
-		if len(b.Candles) < b.nPeriods {
+		b.onCandleCloseUserStrategy(c.candle, c.timeFrame)
+	}
+}
 
-			return
-		}
+func (b *BasicStrategy) onCandleOpenHandler(e *CandleOpenEvent) {
+	if e == nil {
+		return
+	}
 
-		b.userStrategy.OnCandleClose(b, e.Candle)
-	}()
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	if e.CandleTime.After(b.mostRecentTime) {
+		b.mostRecentTime = e.CandleTime
+	}
+
+	if !e.CandleTime.Before(b.lastCandleOpenTime) {
+		b.lastCandleOpen = e.Price
+		b.lastCandleOpenTime = e.CandleTime
+	}
+	if b.updateOpenPnL(e.Price, e.CandleTime) {
+		b.publishPosition()
+		b.checkRiskLimits()
+	}
+
+	b.callUserStrategy("OnCandleOpen", func() { b.userStrategy.OnCandleOpen(b, e.Price) })
 
 }
 
-func (b *BasicStrategy) onCandleOpenHandler(e *CandleOpenEvent) {
-	<-b.mdChan
-	b.handlersWaitGroup.Add(1)
-	go func() {
-		defer func() {
-			b.handlersWaitGroup.Done()
-			b.mdChan <- e
+func (b *BasicStrategy) onDataGapHandler(e *DataGapEvent) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
 
-		}()
+	b.dataGaps++
+	if us, ok := b.userStrategy.(IDataGapUserStrategy); ok {
+		us.OnDataGap(b, e.From, e.To)
+	}
+}
 
-		if e == nil {
-			return
-		}
+func (b *BasicStrategy) onRollHandler(e *RollEvent) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
 
-		b.mut.Lock()
-		defer b.mut.Unlock()
+	for _, t := range b.trades() {
+		t.roll(e)
+	}
+	b.publishRoll(e)
+	b.checkRiskLimits()
+	b.backAdjust(e)
+	if us, ok := b.userStrategy.(IRollUserStrategy); ok {
+		us.OnRoll(b, e.From, e.To)
+	}
+}
 
-		if e.CandleTime.After(b.mostRecentTime) {
-			b.mostRecentTime = e.CandleTime
+//backAdjust adjusts stored ticks and candles of the old contract to price level of the new one
+func (b *BasicStrategy) backAdjust(e *RollEvent) {
+	if e.BackAdjust == BackAdjustNone || e.BackAdjust == "" {
+		return
+	}
+	for _, t := range b.Ticks {
+		t.LastPrice = e.adjustPrice(t.LastPrice)
+		t.BidPrice = e.adjustPrice(t.BidPrice)
+		t.AskPrice = e.adjustPrice(t.AskPrice)
+	}
+	candles := []CandleArray{b.Candles}
+	for _, tf := range b.timeFrames {
+		candles = append(candles, tf.candles)
+		if tf.aggregator.current != nil {
+			candles = append(candles, CandleArray{tf.aggregator.current})
 		}
+	}
+	for _, arr := range candles {
+		for _, c := range arr {
+			c.Open = e.adjustPrice(c.Open)
+			c.High = e.adjustPrice(c.High)
+			c.Low = e.adjustPrice(c.Low)
+			c.Close = e.adjustPrice(c.Close)
+		}
+	}
+	if b.lastCandleOpen != 0 {
+		b.lastCandleOpen = e.adjustPrice(b.lastCandleOpen)
+	}
+}
+
+func (b *BasicStrategy) onOptionChainHandler(e *OptionChainEvent) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
 
-		if !e.CandleTime.Before(b.lastCandleOpenTime) {
-			b.lastCandleOpen = e.Price
-			b.lastCandleOpenTime = e.CandleTime
+	b.optionChain = e
+	if us, ok := b.userStrategy.(IOptionChainUserStrategy); ok {
+		us.OnOptionChain(b, e.Quotes)
+	}
+}
+
+func (b *BasicStrategy) onFundamentalDataHandler(e *FundamentalDataEvent) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	b.fundamentals = e
+	if us, ok := b.userStrategy.(IFundamentalUserStrategy); ok {
+		us.OnFundamentalData(b, e)
+	}
+}
+
+func (b *BasicStrategy) onNewsHandler(e *NewsEvent) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	if us, ok := b.userStrategy.(INewsUserStrategy); ok {
+		us.OnNews(b, e)
+	}
+}
+
+func (b *BasicStrategy) onCustomEventHandler(e *CustomEvent) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	if e.getTime().After(b.mostRecentTime) {
+		b.mostRecentTime = e.getTime()
+	}
+	if us, ok := b.userStrategy.(ICustomEventUserStrategy); ok {
+		us.OnCustomEvent(b, e)
+	}
+}
+
+func (b *BasicStrategy) onTimerHandler(e *TimerEvent) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	if e.getTime().After(b.mostRecentTime) {
+		b.mostRecentTime = e.getTime()
+	}
+	switch e.Name {
+	case sessionOpenTimerName:
+		if us, ok := b.userStrategy.(ISessionUserStrategy); ok {
+			us.OnSessionOpen(b, e.getTime())
 		}
-		if b.currentTrade.IsOpen() {
-			err := b.currentTrade.updatePnL(e.Price, e.CandleTime)
-			if err != nil {
-				b.newError(err)
-			}
+	case sessionCloseTimerName:
+		if us, ok := b.userStrategy.(ISessionUserStrategy); ok {
+			us.OnSessionClose(b, e.getTime())
 		}
+	default:
+		if us, ok := b.userStrategy.(ITimerUserStrategy); ok {
+			us.OnTimer(b, e.Name, e.getTime())
+		}
+	}
+}
 
-		b.userStrategy.OnCandleOpen(b, e.Price)
+func (b *BasicStrategy) onSymbolAddedHandler(e *SymbolAddedEvent) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
 
-	}()
+	b.removedFromUniverse = false
+	if us, ok := b.userStrategy.(IUniverseUserStrategy); ok {
+		us.OnSymbolAdded(b)
+	}
+}
 
+func (b *BasicStrategy) onSymbolRemovedHandler(e *SymbolRemovedEvent) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	b.removedFromUniverse = true
+	if us, ok := b.userStrategy.(IUniverseUserStrategy); ok {
+		us.OnSymbolRemoved(b)
+	}
+}
+
+//onCandleCloseUserStrategy passes closed candle to user strategy. Strategies working with several timeframes
+//get timeframe of candle as well
+func (b *BasicStrategy) onCandleCloseUserStrategy(candle *Candle, timeFrame string) {
+	if mtf, ok := b.userStrategy.(IMultiTimeFrameUserStrategy); ok {
+		b.callUserStrategy("OnTimeFrameCandleClose", func() { mtf.OnTimeFrameCandleClose(b, candle, timeFrame) })
+		return
+	}
+	if timeFrame == b.baseTimeFrame {
+		b.callUserStrategy("OnCandleClose", func() { b.userStrategy.OnCandleClose(b, candle) })
+	}
+}
+
+type timeFrameCandle struct {
+	timeFrame string
+	candle    *Candle
+}
+
+//aggregateTimeFrames puts base timeframe candle to buffers of all additional timeframes and returns
+//candles which were closed by it. Closed candles are sorted from lower to higher timeframe
+func (b *BasicStrategy) aggregateTimeFrames(candle *Candle, baseTimeFrame string) []timeFrameCandle {
+	var closed []timeFrameCandle
+	for _, tf := range b.timeFramesOrder {
+		tfc := b.timeFrames[tf]
+		if tf == baseTimeFrame {
+			tfc.updateIndicators(candle)
+			continue
+		}
+		completed, err := tfc.aggregator.add(candle, baseTimeFrame)
+		if err != nil {
+			b.newError(err)
+			continue
+		}
+		for _, c := range completed {
+			tfc.put(c, b.nPeriods)
+			tfc.updateIndicators(c)
+			closed = append(closed, timeFrameCandle{timeFrame: tf, candle: c})
+		}
+	}
+	return closed
 }
 
 //onCandleHistoryHandler puts historical candles in current array of candles.
@@ -411,6 +1088,10 @@ func (b *BasicStrategy) onCandleHistoryHandler(e *CandlesHistoryEvent) {
 		return
 	}
 
+	var lastCandleTime time.Time
+	if len(b.Candles) > 0 {
+		lastCandleTime = b.Candles[len(b.Candles)-1].Datetime
+	}
 	allCandles := append(b.Candles, e.Candles...)
 	listedCandleTimes := make(map[time.Time]struct{})
 	var checkedCandles CandleArray
@@ -434,6 +1115,12 @@ func (b *BasicStrategy) onCandleHistoryHandler(e *CandlesHistoryEvent) {
 		return checkedCandles[i].Datetime.Unix() < checkedCandles[j].Datetime.Unix()
 	})
 
+	for _, c := range checkedCandles {
+		if c.Datetime.After(lastCandleTime) {
+			b.updateIndicators(c)
+		}
+	}
+
 	if len(checkedCandles) > b.nPeriods {
 		b.Candles = checkedCandles[len(checkedCandles)-b.nPeriods:]
 	} else {
@@ -446,52 +1133,42 @@ func (b *BasicStrategy) onCandleHistoryHandler(e *CandlesHistoryEvent) {
 }
 
 func (b *BasicStrategy) onTickHandler(e *NewTickEvent) {
-	<-b.mdChan
-	b.handlersWaitGroup.Add(1)
-	go func() {
-
-		defer func() {
-			b.handlersWaitGroup.Done()
-			b.mdChan <- e
-
-		}()
-
-		if e == nil {
-			return
-		}
-		if !e.Tick.IsValid() {
-			return
-		}
+	if e == nil {
+		return
+	}
+	if !e.Tick.IsValid() {
+		return
+	}
 
-		/*if e.Tick.Datetime.Sub(b.prevTick.Datetime) > time.Duration(1*time.Second) {
-				for atomic.LoadInt32(&b.waitingN) > 0 {
-					//runtime.Gosched()
+	/*if e.Tick.Datetime.Sub(b.prevTick.Datetime) > time.Duration(1*time.Second) {
+			for atomic.LoadInt32(&b.waitingN) > 0 {
+				//runtime.Gosched()
 
-				}
-			} todo*/
+			}
+		} todo*/
 
-		b.mut.Lock()
-		defer b.mut.Unlock()
+	b.mut.Lock()
+	defer b.mut.Unlock()
 
-		if e.Tick.Datetime.After(b.mostRecentTime) {
-			b.mostRecentTime = e.Tick.Datetime
-		}
+	if e.Tick.Datetime.After(b.mostRecentTime) {
+		b.mostRecentTime = e.Tick.Datetime
+	}
 
-		b.putNewTick(e.Tick)
-		if b.currentTrade.IsOpen() {
-			err := b.currentTrade.updatePnL(e.Tick.LastPrice, e.Tick.Datetime)
-			if err != nil {
-				b.newError(err)
-			}
+	b.putNewTick(e.Tick)
+	b.updateTickIndicators(e.Tick)
+	if b.updateOpenPnL(e.Tick.LastPrice, e.Tick.Datetime) {
+		b.publishPosition()
+		b.checkRiskLimits()
+		if e.Tick.HasTrade() {
+			b.updateTrailingStop(e.Tick.LastPrice, e.Tick.LastPrice)
 		}
-		if len(b.Ticks) < b.nPeriods {
-			return
-		}
-
-		b.userStrategy.OnTick(b, e.Tick)
-		b.sendEventForLogging(e)
-	}()
+	}
+	if len(b.Ticks) < b.nPeriods {
+		return
+	}
 
+	b.callUserStrategy("OnTick", func() { b.userStrategy.OnTick(b, e.Tick) })
+	b.sendEventForLogging(e)
 }
 
 //onTickHistoryHandler puts history ticks in current array of ticks. It doesn't produce any events.
@@ -507,6 +1184,10 @@ func (b *BasicStrategy) onTickHistoryHandler(e *TickHistoryEvent) {
 		return
 	}
 
+	var lastTickTime time.Time
+	if len(b.Ticks) > 0 {
+		lastTickTime = b.Ticks[len(b.Ticks)-1].Datetime
+	}
 	allTicks := append(b.Ticks, e.Ticks...)
 
 	var checkedTicks TickArray
@@ -527,6 +1208,12 @@ func (b *BasicStrategy) onTickHistoryHandler(e *TickHistoryEvent) {
 		return checkedTicks[i].Datetime.Unix() < checkedTicks[j].Datetime.Unix()
 	})
 
+	for _, t := range checkedTicks {
+		if t.Datetime.After(lastTickTime) {
+			b.updateTickIndicators(t)
+		}
+	}
+
 	if len(checkedTicks) > b.nPeriods {
 		b.Ticks = checkedTicks[len(checkedTicks)-b.nPeriods:]
 	} else {
@@ -539,7 +1226,11 @@ func (b *BasicStrategy) onTickHistoryHandler(e *TickHistoryEvent) {
 func (b *BasicStrategy) onOrderFillHandler(e *OrderFillEvent) {
 	b.mut.Lock()
 	defer b.mut.Unlock()
+	b.applyFill(e)
+}
 
+//applyFill updates trade, lots and portfolio by execution of the order
+func (b *BasicStrategy) applyFill(e *OrderFillEvent) {
 	if e.getTime().After(b.mostRecentTime) {
 		b.mostRecentTime = e.getTime()
 	}
@@ -556,29 +1247,49 @@ func (b *BasicStrategy) onOrderFillHandler(e *OrderFillEvent) {
 		b.newError(errors.New("Price is NaN or less or equal to zero. "))
 	}
 
-	prevState := b.currentTrade.Type
-	newPos, err := b.currentTrade.executeOrder(e.OrdId, e.Qty, e.Price, e.Time)
+	trade := b.tradeOfOrder(e.OrdId)
+	prevState := trade.Type
+	prevPos := trade.position()
+	var side OrderSide
+	order, ok := trade.ConfirmedOrders[e.OrdId]
+	if ok {
+		side = order.Side
+	}
+	newPos, err := trade.executeOrder(e.OrdId, e.Qty, e.Price, e.Time)
 
 	if err != nil {
 		b.newError(err)
 		return
 	}
+	if r := b.orderUpdated(e.OrdId, e.Time); r != nil {
+		r.fills++
+	}
+	b.updateLots(e.OrdId, side, prevPos, e.Qty, e.Price, e.Time)
+	b.attributeCosts(trade, newPos, prevPos, e)
+	b.recordCostFill(trade, newPos, prevPos, order, e)
 	if newPos != nil {
-		if b.currentTrade.Type != ClosedTrade {
+		if trade.Type != ClosedTrade {
 			b.newError(errors.New("New position opened, but previous is not closed. "))
 			return
 		}
-		b.closedTrades = append(b.closedTrades, b.currentTrade)
-		b.currentTrade = newPos
+		b.closedTrades = append(b.closedTrades, trade)
+		b.replaceTrade(trade, newPos)
 		//fmt.Println("New trade to portf event")
-		b.notifyPortfolioAboutPosition(&PortfolioNewPositionEvent{be(e.getTime(), b.symbol), b.currentTrade})
+		pe := &PortfolioNewPositionEvent{be(e.getTime(), b.symbol), newPos}
+		pe.CorrelationID = e.CorrelationID
+		b.notifyPortfolioAboutPosition(pe)
 
 	} else {
 		if prevState == FlatTrade {
 			//fmt.Println("New trade to portf event")
-			b.notifyPortfolioAboutPosition(&PortfolioNewPositionEvent{be(e.getTime(), b.symbol), b.currentTrade})
+			pe := &PortfolioNewPositionEvent{be(e.getTime(), b.symbol), trade}
+			pe.CorrelationID = e.CorrelationID
+			b.notifyPortfolioAboutPosition(pe)
 		}
 	}
+	b.publishFill(side, e.Qty, e.Price, trade.toAccount(e.Commission))
+	b.checkRiskLimits()
+	b.syncManagedOrders()
 
 }
 
@@ -591,26 +1302,19 @@ func (b *BasicStrategy) onOrderCancelHandler(e *OrderCancelEvent) {
 	}
 
 	atomic.AddInt32(&b.waitingN, -1)
-	delete(b.waitingConfirmation, "&CAN&"+e.OrdId)
+	delete(b.waitingConfirmation, "$CAN$"+e.OrdId)
 
-	err := b.currentTrade.cancelOrder(e.OrdId)
+	err := b.tradeOfOrder(e.OrdId).cancelOrder(e.OrdId)
 
 	if err != nil {
 		b.newError(err)
 		return
 	}
+	b.orderUpdated(e.OrdId, e.Time)
+	b.syncManagedOrders()
 
 }
 
-func (b *BasicStrategy) onStrategyRequestNotDeliveredEventHandler(e *StrategyRequestNotDeliveredEvent) {
-	if e.Request == nil {
-		b.newError(errors.New("onStrategyRequestNotDeliveredEventHandler got event with nil Request field"))
-		return
-	}
-
-	panic("Not implemented") // Todo
-}
-
 func (b *BasicStrategy) onOrderCancelRejectHandler(e *OrderCancelRejectEvent) {
 	b.mut.Lock()
 	defer b.mut.Unlock()
@@ -620,8 +1324,8 @@ func (b *BasicStrategy) onOrderCancelRejectHandler(e *OrderCancelRejectEvent) {
 	}
 
 	atomic.AddInt32(&b.waitingN, -1)
-	delete(b.waitingConfirmation, "&CAN&"+e.OrdId)
-
+	delete(b.waitingConfirmation, "$CAN$"+e.OrdId)
+	b.syncManagedOrders()
 }
 
 func (b *BasicStrategy) onOrderReplaceRejectHandler(e *OrderReplaceRejectEvent) {
@@ -633,8 +1337,8 @@ func (b *BasicStrategy) onOrderReplaceRejectHandler(e *OrderReplaceRejectEvent)
 	}
 
 	atomic.AddInt32(&b.waitingN, -1)
-	delete(b.waitingConfirmation, "&REP&"+e.OrdId)
-
+	delete(b.waitingConfirmation, "$REP$"+e.OrdId)
+	b.syncManagedOrders()
 }
 
 func (b *BasicStrategy) onOrderConfirmHandler(e *OrderConfirmationEvent) {
@@ -646,14 +1350,16 @@ func (b *BasicStrategy) onOrderConfirmHandler(e *OrderConfirmationEvent) {
 	}
 
 	atomic.AddInt32(&b.waitingN, -1)
-	delete(b.waitingConfirmation, "&NO&"+e.OrdId)
+	delete(b.waitingConfirmation, "$NO$"+e.OrdId)
 
-	err := b.currentTrade.confirmOrder(e.OrdId)
+	err := b.tradeOfOrder(e.OrdId).confirmOrder(e.OrdId)
 
 	if err != nil {
 		b.newError(err)
 		return
 	}
+	b.orderUpdated(e.OrdId, e.Time)
+	b.syncManagedOrders()
 }
 
 func (b *BasicStrategy) onOrderReplacedHandler(e *OrderReplacedEvent) {
@@ -664,13 +1370,23 @@ func (b *BasicStrategy) onOrderReplacedHandler(e *OrderReplacedEvent) {
 	}
 
 	atomic.AddInt32(&b.waitingN, -1)
-	delete(b.waitingConfirmation, "&REP&"+e.OrdId)
+	delete(b.waitingConfirmation, "$REP$"+e.OrdId)
 
-	err := b.currentTrade.replaceOrder(e.OrdId, e.NewPrice)
+	var err error
+	if e.NewQty > 0 {
+		err = b.tradeOfOrder(e.OrdId).replaceOrderQty(e.OrdId, e.NewQty)
+	}
+	hasPrice := e.NewPrice != 0 && !math.IsNaN(e.NewPrice)
+	if err == nil && (e.NewQty == 0 || hasPrice) {
+		err = b.tradeOfOrder(e.OrdId).replaceOrder(e.OrdId, e.NewPrice)
+	}
 
 	if err != nil {
 		b.newError(err)
+		return
 	}
+	b.orderUpdated(e.OrdId, e.Time)
+	b.syncManagedOrders()
 
 }
 
@@ -682,25 +1398,37 @@ func (b *BasicStrategy) onOrderRejectedHandler(e *OrderRejectedEvent) {
 	}
 
 	atomic.AddInt32(&b.waitingN, -1)
-	delete(b.waitingConfirmation, "&NO&"+e.OrdId)
+	delete(b.waitingConfirmation, "$NO$"+e.OrdId)
 
-	err := b.currentTrade.rejectOrder(e.OrdId, e.Reason)
+	err := b.tradeOfOrder(e.OrdId).rejectOrder(e.OrdId, e.Reason)
 
 	if err != nil {
 		b.newError(err)
 		return
 	}
+	if r := b.orderUpdated(e.OrdId, e.Time); r != nil {
+		r.rejectReason = e.Reason
+	}
+	b.syncManagedOrders()
 }
 
 func (b *BasicStrategy) onEndOfDataHandler(e *EndOfDataEvent) {
-	b.terminationChan <- struct{}{}
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	//Event has wall clock time, so strategy time is not changed
+	if us, ok := b.userStrategy.(IBacktestEndUserStrategy); ok {
+		us.OnBacktestEnd(b)
+	}
 }
 
 //Private funcs to work with data
 func (b *BasicStrategy) newError(err error) {
+	b.Log(ErrorLevel, err.Error(), nil)
+	ctx := b.ctx
 	b.handlersWaitGroup.Add(1)
 	go func() {
-		b.ch.errors <- err
+		sendError(ctx, b.ch.errors, err)
 		b.handlersWaitGroup.Done()
 	}()
 
@@ -708,13 +1436,19 @@ func (b *BasicStrategy) newError(err error) {
 
 func (b *BasicStrategy) newSignal(e event) {
 	b.sendEventForLogging(e)
-	b.ch.events <- e
+	sendEvent(b.ctx, b.ch.events, e)
 }
 
 func (b *BasicStrategy) newOrder(order *Order) error {
 	if order.Ticker != b.symbol {
 		return errors.New("Can't put new order. Strategy symbol and order symbol are different. ")
 	}
+	if b.removedFromUniverse {
+		return errors.New("Can't put new order. Symbol was removed from universe. ")
+	}
+	if b.delisted {
+		return errors.New("Can't put new order. Symbol is delisted. ")
+	}
 	if order.Id == "" {
 		order.Id = order.Time.Format(orderidLayout)
 	}
@@ -722,18 +1456,31 @@ func (b *BasicStrategy) newOrder(order *Order) error {
 	if !order.isValid() {
 		return errors.New("Order is not valid. ")
 	}
-	order.Id = b.symbol.Symbol + "|" + string(order.Side) + "|" + order.Id
+	if err := b.checkOrderRisk(order); err != nil {
+		return err
+	}
+	if err := b.checkPyramiding(order); err != nil {
+		return err
+	}
+	trade, err := b.tradeForNewOrder(order)
+	if err != nil {
+		return err
+	}
+	order.Id = b.orderIDPrefix() + string(order.Side) + "|" + order.Id
+	order.CorrelationID = b.orderCorrelationID(order)
 
-	err := b.currentTrade.putNewOrder(order)
+	err = trade.putNewOrder(order)
 
 	if err != nil {
 		b.newError(err)
 		return err
 	}
+	b.trackOrder(order)
 	ordEvent := NewOrderEvent{
 		LinkedOrder: order,
-		BaseEvent:   be(b.mostRecentTime, order.Ticker),
+		BaseEvent:   be(b.now(), order.Ticker),
 	}
+	ordEvent.CorrelationID = order.CorrelationID
 
 	reqID := "$NO$" + order.Id
 	if _, ok := b.waitingConfirmation[reqID]; ok {
@@ -747,21 +1494,97 @@ func (b *BasicStrategy) newOrder(order *Order) error {
 }
 
 func (b *BasicStrategy) notifyPortfolioAboutPosition(e *PortfolioNewPositionEvent) {
+	if b.synchronous && b.portfolio != nil {
+		b.portfolio.onNewTrade(e.trade)
+		return
+	}
 	b.handlersWaitGroup.Add(1)
 	go func() {
-		b.ch.portfolio <- e
+		select {
+		case b.ch.portfolio <- e:
+		case <-doneChan(b.ctx):
+		}
 		b.handlersWaitGroup.Done()
 	}()
 }
 
+//publishPosition updates snapshot of the position in portfolio
+func (b *BasicStrategy) publishPosition() {
+	if b.portfolio == nil {
+		return
+	}
+	b.portfolio.updatePositionCash(b.positionInfo(), -b.takeBorrowCost()-b.takeFinancingCost())
+}
+
+//publishFill updates position snapshot and cash of the portfolio by execution. Commission is in account currency.
+func (b *BasicStrategy) publishFill(side OrderSide, qty int64, price float64, commission float64) {
+	if b.portfolio == nil {
+		return
+	}
+	flow := b.portfolio.fillCashFlow(b.symbol, side, qty, price) - commission
+	b.portfolio.updatePositionCash(b.positionInfo(), flow)
+}
+
+//publishRoll updates position snapshot and cash after roll, which is sale of the old contract and purchase
+//of the new one for long position
+func (b *BasicStrategy) publishRoll(e *RollEvent) {
+	if b.portfolio == nil {
+		return
+	}
+	var flow float64
+	for _, t := range b.trades() {
+		if !t.IsOpen() || e.FromPrice <= 0 || e.ToPrice <= 0 {
+			continue
+		}
+		closeSide, openSide := OrderSell, OrderBuy
+		if t.Type == ShortTrade {
+			closeSide, openSide = OrderBuy, OrderSell
+		}
+		flow += b.portfolio.fillCashFlow(b.symbol, closeSide, t.Qty, e.FromPrice) +
+			b.portfolio.fillCashFlow(b.symbol, openSide, t.Qty, e.ToPrice)
+	}
+	b.portfolio.updatePositionCash(b.positionInfo(), flow)
+}
+
 func (b *BasicStrategy) enableEventLogging() {
-	pth := path.Join("./StrategyLogs", b.symbol.Symbol+".txt")
+	file := b.symbol.Symbol
+	if b.name != "" {
+		file += "_" + b.name
+	}
+	pth := path.Join("./StrategyLogs", file+".txt")
 	f, err := os.OpenFile(pth, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		panic(err)
 	}
-	b.log.SetOutput(f)
-	b.isEventLoggingEnabled = true
+	b.setLogger(NewTextLogger(f, DebugLevel))
+}
+
+//setLogger sets logger of strategy events and errors. Symbol and name of the strategy in group are added to
+//every record.
+func (b *BasicStrategy) setLogger(l ILogger) {
+	if l == nil {
+		b.logger = nil
+		return
+	}
+	fields := LogFields{"symbol": b.symbol.Symbol}
+	if b.name != "" {
+		fields["strategy"] = b.name
+	}
+	b.logger = WithFields(l, fields)
+}
+
+//Log writes record to the strategy logger if it's set
+func (b *BasicStrategy) Log(level LogLevel, message string, fields LogFields) {
+	if b.logger == nil {
+		return
+	}
+	if fields == nil {
+		fields = LogFields{}
+	}
+	if _, ok := fields["time"]; !ok {
+		fields["time"] = b.mostRecentTime
+	}
+	b.logger.Log(level, message, fields)
 }
 
 func (b *BasicStrategy) enableEventSliceStorage() {
@@ -770,9 +1593,12 @@ func (b *BasicStrategy) enableEventSliceStorage() {
 }
 
 func (b *BasicStrategy) sendEventForLogging(e event) {
-	if b.isEventLoggingEnabled {
-		message := fmt.Sprintf("[SE:%v]  %+v", b.symbol, e.String())
-		b.log.Print(message)
+	if b.logger != nil {
+		fields := LogFields{"event": e.getName(), "time": e.getTime()}
+		if id := correlationIDOf(e); id != "" {
+			fields["correlationID"] = id
+		}
+		b.logger.Log(InfoLevel, e.String(), fields)
 	}
 	if b.isEventSliceStorageEnabled {
 		b.eventsLoggingSlice.add(e)
@@ -797,6 +1623,8 @@ func (b *BasicStrategy) putNewCandle(candle *Candle) {
 	b.Candles = append(b.Candles[1:], candle)
 
 	if sortIt {
+		//Buffer is sorted as a copy, so windows created before keep their order
+		b.Candles = append(CandleArray(nil), b.Candles...)
 		sort.SliceStable(b.Candles, func(i, j int) bool {
 			return b.Candles[i].Datetime.Unix() < b.Candles[j].Datetime.Unix()
 		})
@@ -822,6 +1650,8 @@ func (b *BasicStrategy) putNewTick(tick *Tick) {
 	b.Ticks = append(b.Ticks[1:], tick)
 
 	if sortIt {
+		//Buffer is sorted as a copy, so windows created before keep their order
+		b.Ticks = append(TickArray(nil), b.Ticks...)
 		sort.SliceStable(b.Ticks, func(i, j int) bool {
 			return b.Ticks[i].Datetime.Unix() < b.Ticks[j].Datetime.Unix()
 		})
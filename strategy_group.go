@@ -0,0 +1,316 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+//StrategyGroup runs several strategies on one symbol. Engine sees it as strategy of the symbol: market data,
+//custom and account events are passed to every strategy of the group, broker events are passed to the
+//strategy which sent the order. Every strategy has its own trades, orders and handoff of events, its orders are
+//marked with its name. Portfolio sums positions of strategies by symbol, allocation of the symbol is shared.
+type StrategyGroup struct {
+	symbol  *Instrument
+	members map[string]*BasicStrategy
+	names   []string
+
+	portfolio *portfolioHandler
+	//timers are due timer events by strategy they belong to
+	timers    map[event]*BasicStrategy
+	timersMut sync.Mutex
+}
+
+func NewStrategyGroup(symbol *Instrument) *StrategyGroup {
+	return &StrategyGroup{
+		symbol:  symbol,
+		members: make(map[string]*BasicStrategy),
+		timers:  make(map[event]*BasicStrategy),
+	}
+}
+
+//Add adds strategy of the group symbol with unique name. It should be called before group is passed to engine.
+func (g *StrategyGroup) Add(name string, st *BasicStrategy) error {
+	if name == "" || strings.Contains(name, "|") {
+		return fmt.Errorf("Wrong strategy name %q. It should be non empty and without '|'. ", name)
+	}
+	if st == nil || st.symbol == nil || st.symbol.Symbol != g.symbol.Symbol {
+		return fmt.Errorf("Strategy %v should trade %v. ", name, g.symbol.Symbol)
+	}
+	if _, ok := g.members[name]; ok {
+		return fmt.Errorf("Strategy %v is already in group of %v. ", name, g.symbol.Symbol)
+	}
+	st.name = name
+	g.members[name] = st
+	g.names = append(g.names, name)
+	return nil
+}
+
+//Strategy returns strategy of the group by name or nil if there is no such strategy
+func (g *StrategyGroup) Strategy(name string) *BasicStrategy {
+	return g.members[name]
+}
+
+//Names returns names of strategies in order they were added
+func (g *StrategyGroup) Names() []string {
+	return append([]string{}, g.names...)
+}
+
+func (g *StrategyGroup) forEach(f func(st *BasicStrategy)) {
+	for _, name := range g.names {
+		f(g.members[name])
+	}
+}
+
+func (g *StrategyGroup) init(ch CoreStrategyChannels) {
+	g.forEach(func(st *BasicStrategy) { st.init(ch) })
+}
+
+func (g *StrategyGroup) ticks() TickArray {
+	if len(g.names) == 0 {
+		return nil
+	}
+	return g.members[g.names[0]].ticks()
+}
+
+func (g *StrategyGroup) candles() CandleArray {
+	if len(g.names) == 0 {
+		return nil
+	}
+	return g.members[g.names[0]].candles()
+}
+
+func (g *StrategyGroup) setPortfolio(p *portfolioHandler) {
+	g.portfolio = p
+	g.forEach(func(st *BasicStrategy) { st.setPortfolio(p) })
+}
+
+func (g *StrategyGroup) enableEventLogging() {
+	g.forEach(func(st *BasicStrategy) { st.enableEventLogging() })
+}
+
+func (g *StrategyGroup) setLogger(l ILogger) {
+	g.forEach(func(st *BasicStrategy) { st.setLogger(l) })
+}
+
+func (g *StrategyGroup) setContext(ctx context.Context) {
+	g.forEach(func(st *BasicStrategy) { st.setContext(ctx) })
+}
+
+func (g *StrategyGroup) shutDown() {
+	g.forEach(func(st *BasicStrategy) { st.shutDown() })
+}
+
+func (g *StrategyGroup) getInstrument() *Instrument {
+	return g.symbol
+}
+
+func (g *StrategyGroup) validateParams() error {
+	for _, name := range g.names {
+		if err := g.members[name].validateParams(); err != nil {
+			return fmt.Errorf("%v: %v", name, err)
+		}
+	}
+	return nil
+}
+
+func (g *StrategyGroup) setStrictMode(strict bool) {
+	g.forEach(func(st *BasicStrategy) { st.setStrictMode(strict) })
+}
+
+func (g *StrategyGroup) setSynchronous(on bool) {
+	g.forEach(func(st *BasicStrategy) { st.setSynchronous(on) })
+}
+
+func (g *StrategyGroup) setClock(clock Clock) {
+	g.forEach(func(st *BasicStrategy) { st.setClock(clock) })
+}
+
+func (g *StrategyGroup) setBackpressure(cfg *BackpressureConfig) {
+	g.forEach(func(st *BasicStrategy) { st.setBackpressure(cfg) })
+}
+
+func (g *StrategyGroup) queueStats() []QueueStats {
+	var stats []QueueStats
+	g.forEach(func(st *BasicStrategy) { stats = append(stats, st.queueStats()...) })
+	return stats
+}
+
+//warmUpPeriods returns the largest number of periods strategies of the group need
+func (g *StrategyGroup) warmUpPeriods() int {
+	n := 0
+	g.forEach(func(st *BasicStrategy) {
+		if st.warmUpPeriods() > n {
+			n = st.warmUpPeriods()
+		}
+	})
+	return n
+}
+
+//dueTimers returns due timers of all strategies and remembers which strategy each of them belongs to
+func (g *StrategyGroup) dueTimers(now time.Time) []event {
+	var due []event
+	g.timersMut.Lock()
+	defer g.timersMut.Unlock()
+	g.forEach(func(st *BasicStrategy) {
+		for _, e := range st.dueTimers(now) {
+			g.timers[e] = st
+			due = append(due, e)
+		}
+	})
+	return due
+}
+
+func (g *StrategyGroup) notify(e event) {
+	switch i := e.(type) {
+	case *OrderConfirmationEvent:
+		g.notifyOrderOwner(i.OrdId, e)
+	case *OrderFillEvent:
+		g.notifyOrderOwner(i.OrdId, e)
+	case *OrderCancelEvent:
+		g.notifyOrderOwner(i.OrdId, e)
+	case *OrderCancelRejectEvent:
+		g.notifyOrderOwner(i.OrdId, e)
+	case *OrderReplacedEvent:
+		g.notifyOrderOwner(i.OrdId, e)
+	case *OrderReplaceRejectEvent:
+		g.notifyOrderOwner(i.OrdId, e)
+	case *OrderRejectedEvent:
+		g.notifyOrderOwner(i.OrdId, e)
+	case *StrategyRequestNotDeliveredEvent:
+		g.notifyOrderOwner(requestOrderID(i.Request), e)
+	case *StalePositionEvent:
+		//Only strategies which hold position get it
+		g.forEach(func(st *BasicStrategy) {
+			if info, ok := g.portfolio.groupPosition(g.symbol.Symbol, st.name); ok && info.Qty != 0 {
+				st.notify(e)
+			}
+		})
+	default:
+		g.timersMut.Lock()
+		st, isTimer := g.timers[e]
+		delete(g.timers, e)
+		g.timersMut.Unlock()
+		if isTimer {
+			st.notify(e)
+			return
+		}
+		g.forEach(func(st *BasicStrategy) { st.notify(e) })
+	}
+}
+
+//notifyOrderOwner passes broker event to the strategy which order ID has its prefix
+func (g *StrategyGroup) notifyOrderOwner(ordID string, e event) {
+	for _, name := range g.names {
+		st := g.members[name]
+		if strings.HasPrefix(ordID, st.orderIDPrefix()) {
+			st.notify(e)
+			return
+		}
+	}
+}
+
+//saveState returns state of the group symbol with states of its strategies
+func (g *StrategyGroup) saveState() *StrategyState {
+	s := StrategyState{Symbol: g.symbol.Symbol, Members: make(map[string]*StrategyState)}
+	g.forEach(func(st *BasicStrategy) { s.Members[st.name] = st.saveState() })
+	return &s
+}
+
+func (g *StrategyGroup) restoreState(s *StrategyState) error {
+	if s.Symbol != g.symbol.Symbol {
+		return fmt.Errorf("Can't restore state of %v to strategy group of %v. ", s.Symbol, g.symbol.Symbol)
+	}
+	if s.Members == nil {
+		return fmt.Errorf("Can't restore state of %v. It's not state of strategy group. ", s.Symbol)
+	}
+	var errs []string
+	for _, name := range g.names {
+		ms, ok := s.Members[name]
+		if !ok {
+			continue
+		}
+		if err := g.members[name].restoreState(ms); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, ""))
+	}
+	return nil
+}
+
+//orderIDPrefix returns beginning of IDs of orders of the strategy
+func (b *BasicStrategy) orderIDPrefix() string {
+	if b.name == "" {
+		return b.symbol.Symbol + "|"
+	}
+	return b.symbol.Symbol + "|" + b.name + "|"
+}
+
+//Name returns name of the strategy in StrategyGroup. It's empty for strategy which is alone on its symbol.
+func (b *BasicStrategy) Name() string {
+	return b.name
+}
+
+//updateGroupPosition saves position of the strategy of group and returns position of the symbol summed over
+//strategies of the group. Caller should hold the lock.
+func (p *portfolioHandler) updateGroupPosition(info PositionInfo) PositionInfo {
+	if p.groupPositions == nil {
+		p.groupPositions = make(map[string]map[string]PositionInfo)
+	}
+	group, ok := p.groupPositions[info.Symbol]
+	if !ok {
+		group = make(map[string]PositionInfo)
+		p.groupPositions[info.Symbol] = group
+	}
+	group[info.Strategy] = info
+
+	names := make([]string, 0, len(group))
+	for name := range group {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	total := PositionInfo{Symbol: info.Symbol, Currency: info.Currency, Sector: info.Sector}
+	openValue := 0.0
+	var openQty int64
+	for _, name := range names {
+		v := group[name]
+		total.Qty += v.Qty
+		total.MarketValue += v.MarketValue
+		total.OpenPnL += v.OpenPnL
+		total.ClosedPnL += v.ClosedPnL
+		total.InitialMargin += v.InitialMargin
+		total.MaintenanceMargin += v.MaintenanceMargin
+		if v.Time.After(total.Time) {
+			total.Time = v.Time
+		}
+		if v.Qty == 0 {
+			continue
+		}
+		openValue += v.OpenPrice * float64(abs64(v.Qty))
+		openQty += abs64(v.Qty)
+		if total.OpenTime.IsZero() || v.OpenTime.Before(total.OpenTime) {
+			total.OpenTime = v.OpenTime
+		}
+	}
+	if openQty > 0 {
+		total.OpenPrice = openValue / float64(openQty)
+	}
+	return total
+}
+
+//groupPosition returns position of the strategy of group
+func (p *portfolioHandler) groupPosition(symbol string, name string) (PositionInfo, bool) {
+	if p == nil {
+		return PositionInfo{}, false
+	}
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	info, ok := p.groupPositions[symbol][name]
+	return info, ok
+}
@@ -0,0 +1,100 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestStrategyGroup() *StrategyGroup {
+	inst := newTestInstrument()
+	g := NewStrategyGroup(inst)
+	g.Add("A", NewBasicStrategy(inst, 1, &DummyStrategy{}))
+	g.Add("B", NewBasicStrategy(inst, 1, &DummyStrategy{}))
+	g.init(CoreStrategyChannels{
+		errors:    make(chan error, 10),
+		events:    make(chan event, 10),
+		portfolio: make(chan *PortfolioNewPositionEvent, 10),
+	})
+	g.setSynchronous(true)
+	return g
+}
+
+func TestStrategyGroup_Add(t *testing.T) {
+	inst := newTestInstrument()
+	g := NewStrategyGroup(inst)
+	assert.Nil(t, g.Add("A", NewBasicStrategy(inst, 1, &DummyStrategy{})))
+	assert.NotNil(t, g.Add("A", NewBasicStrategy(inst, 1, &DummyStrategy{})))
+	assert.NotNil(t, g.Add("", NewBasicStrategy(inst, 1, &DummyStrategy{})))
+	assert.NotNil(t, g.Add("A|B", NewBasicStrategy(inst, 1, &DummyStrategy{})))
+	assert.NotNil(t, g.Add("C", NewBasicStrategy(&Instrument{Symbol: "Other"}, 1, &DummyStrategy{})))
+	assert.Equal(t, []string{"A"}, g.Names())
+	assert.Equal(t, "A", g.Strategy("A").Name())
+	assert.Nil(t, g.Strategy("C"))
+}
+
+func TestStrategyGroup_notify(t *testing.T) {
+	g := newTestStrategyGroup()
+	a, b := g.Strategy("A"), g.Strategy("B")
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+
+	//Market data is passed to all strategies
+	g.notify(newTestCandleCloseEvent(10, 11, 9, 10, tm, "1"))
+	assert.Len(t, a.Candles, 1)
+	assert.Len(t, b.Candles, 1)
+
+	id, err := a.NewLimitOrder(10, OrderBuy, 100, GTCTIF, "Sim")
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(id, "Test|A|"))
+	<-a.ch.events
+
+	//Broker events are passed to the owner of the order
+	g.notify(&OrderConfirmationEvent{BaseEvent: be(tm, a.symbol), OrdId: id})
+	assert.True(t, a.IsOrderConfirmed(id))
+	assert.False(t, b.IsOrderConfirmed(id))
+	assert.Len(t, a.ch.errors, 0)
+}
+
+func TestStrategyGroup_saveState(t *testing.T) {
+	g := newTestStrategyGroup()
+	s := g.saveState()
+	assert.Equal(t, "Test", s.Symbol)
+	assert.Len(t, s.Members, 2)
+	assert.Equal(t, "Test", s.Members["B"].Symbol)
+
+	assert.Nil(t, g.restoreState(s))
+	assert.NotNil(t, g.restoreState(&StrategyState{Symbol: "Test"}))
+	assert.NotNil(t, g.restoreState(&StrategyState{Symbol: "Other", Members: s.Members}))
+}
+
+func TestPortfolioHandler_updateGroupPosition(t *testing.T) {
+	p := newPortfolio()
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	p.updatePosition(PositionInfo{Symbol: "Test", Strategy: "A", Qty: 100, OpenPrice: 10, MarketValue: 1100,
+		OpenPnL: 100, OpenTime: tm, Time: tm})
+	p.updatePosition(PositionInfo{Symbol: "Test", Strategy: "B", Qty: 300, OpenPrice: 11, MarketValue: 3300,
+		OpenPnL: 0, ClosedPnL: 50, OpenTime: tm.Add(time.Hour), Time: tm.Add(time.Hour)})
+
+	info, ok := p.Position("Test")
+	assert.True(t, ok)
+	assert.Equal(t, int64(400), info.Qty)
+	assert.Equal(t, 4400.0, info.MarketValue)
+	assert.Equal(t, 100.0, info.OpenPnL)
+	assert.Equal(t, 50.0, info.ClosedPnL)
+	assert.InDelta(t, 10.75, info.OpenPrice, 1e-9)
+	assert.Equal(t, tm, info.OpenTime)
+	assert.Equal(t, "", info.Strategy)
+	assert.Len(t, p.Positions(), 1)
+
+	p.updatePosition(PositionInfo{Symbol: "Test", Strategy: "A", ClosedPnL: 100, Time: tm.Add(2 * time.Hour)})
+	info, _ = p.Position("Test")
+	assert.Equal(t, int64(300), info.Qty)
+	assert.Equal(t, 11.0, info.OpenPrice)
+	assert.Equal(t, 150.0, info.ClosedPnL)
+
+	a, ok := p.groupPosition("Test", "A")
+	assert.True(t, ok)
+	assert.Equal(t, int64(0), a.Qty)
+}
@@ -0,0 +1,87 @@
+package engine
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBasicStrategy_orderConstructors(t *testing.T) {
+	st := newTestBasicStrategy()
+	st.ch.events = make(chan event, 10)
+	st.mostRecentTime = time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+
+	send := func(id string, err error) *Order {
+		assert.Nil(t, err)
+		assert.Len(t, st.ch.events, 1)
+		ord := (<-st.ch.events).(*NewOrderEvent).LinkedOrder
+		assert.Equal(t, id, ord.Id)
+		return ord
+	}
+
+	ord := send(st.NewStopOrder(49, OrderSell, 100, GTCTIF, "Sim"))
+	assert.Equal(t, StopOrder, ord.Type)
+	assert.Equal(t, 49.0, ord.Price)
+	assert.Equal(t, GTCTIF, ord.Tif)
+
+	ord = send(st.NewMarketOnOpenOrder(OrderBuy, 100, "Sim"))
+	assert.Equal(t, MarketOnOpen, ord.Type)
+	assert.True(t, math.IsNaN(ord.Price))
+
+	ord = send(st.NewMarketOnCloseOrder(OrderSell, 100, "Sim"))
+	assert.Equal(t, MarketOnClose, ord.Type)
+
+	ord = send(st.NewLimitOnOpenOrder(50, OrderBuy, 100, "Sim"))
+	assert.Equal(t, LimitOnOpen, ord.Type)
+	assert.Equal(t, 50.0, ord.Price)
+
+	ord = send(st.NewLimitOnCloseOrder(51, OrderSell, 100, "Sim"))
+	assert.Equal(t, LimitOnClose, ord.Type)
+	assert.Equal(t, DayTIF, ord.Tif)
+
+	_, err := st.NewStopOrder(0, OrderSell, 100, GTCTIF, "Sim")
+	assert.NotNil(t, err)
+	_, err = st.NewLimitOnCloseOrder(math.NaN(), OrderSell, 100, "Sim")
+	assert.NotNil(t, err)
+	_, err = st.NewMarketOnOpenOrder(OrderBuy, 0, "Sim")
+	assert.NotNil(t, err)
+	_, err = st.NewStopOrder(49, OrderSide("X"), 100, GTCTIF, "Sim")
+	assert.NotNil(t, err)
+	assert.Len(t, st.ch.events, 0)
+}
+
+func TestBasicStrategy_ReplaceOrderQty(t *testing.T) {
+	st := newTestBasicStrategy()
+	st.ch.events = make(chan event, 10)
+	go func() {
+		for range st.ch.errors {
+		}
+	}()
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	st.mostRecentTime = tm
+
+	id, _ := st.NewLimitOrder(50, OrderBuy, 300, GTCTIF, "Sim")
+	<-st.ch.events
+	assert.NotNil(t, st.ReplaceOrderQty(id, 200))
+	st.proxyEvent(&OrderConfirmationEvent{BaseEvent: be(tm, st.symbol), OrdId: id})
+	st.proxyEvent(&OrderFillEvent{BaseEvent: be(tm, st.symbol), OrdId: id, Price: 50, Qty: 100})
+
+	assert.NotNil(t, st.ReplaceOrderQty(id, 100))
+	assert.Nil(t, st.ReplaceOrderQty(id, 200))
+	assert.NotNil(t, st.ReplaceOrderQty(id, 150))
+	req := (<-st.ch.events).(*OrderReplaceRequestEvent)
+	assert.Equal(t, int64(200), req.NewQty)
+	assert.Equal(t, 0.0, req.NewPrice)
+
+	st.proxyEvent(&OrderReplacedEvent{BaseEvent: be(tm, st.symbol), OrdId: id, NewPrice: 50, NewQty: 200})
+	info, _ := st.OrderStatus(id)
+	assert.Equal(t, int64(200), info.Qty)
+	assert.Equal(t, int64(100), info.LeavesQty)
+	assert.Equal(t, 50.0, info.Price)
+	assert.False(t, info.PendingReplace)
+
+	st.proxyEvent(&OrderFillEvent{BaseEvent: be(tm, st.symbol), OrdId: id, Price: 50, Qty: 100})
+	assert.Equal(t, int64(200), st.Position())
+	assert.Equal(t, FilledOrder, st.currentTrade.FilledOrders[id].State)
+}
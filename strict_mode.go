@@ -0,0 +1,73 @@
+package engine
+
+//IStrictModeAware can be implemented by broker or market data which can abort run on data anomalies
+type IStrictModeAware interface {
+	SetStrictMode(strict bool)
+}
+
+//SetStrictMode makes engine, simulated broker and strategies panic on data anomalies, like out of order market
+//data or events of unknown orders. By default such events are skipped and ErrDataAnomaly is sent to error
+//channel, so one bad record doesn't kill the whole backtest.
+func (c *Engine) SetStrictMode(strict bool) {
+	c.mut.Lock()
+	c.strict = strict
+	for _, st := range c.strategiesMap {
+		st.setStrictMode(strict)
+	}
+	c.mut.Unlock()
+	if b, ok := c.broker.(IStrictModeAware); ok {
+		b.SetStrictMode(strict)
+	}
+	if md, ok := c.md.(IStrictModeAware); ok {
+		md.SetStrictMode(strict)
+	}
+}
+
+//anomaly logs data anomaly of the event or panics in strict mode
+func (c *Engine) anomaly(e event, message string) {
+	err := newErrDataAnomaly(e, message, "Engine")
+	c.mut.Lock()
+	strict := c.strict
+	c.mut.Unlock()
+	if strict {
+		panic(err)
+	}
+	c.logError(err)
+}
+
+//SetStrictMode sets if workers panic on data anomalies
+func (b *SimBroker) SetStrictMode(strict bool) {
+	b.strict = strict
+	if b.workersMut == nil {
+		return
+	}
+	b.workersMut.RLock()
+	defer b.workersMut.RUnlock()
+	for _, w := range b.workers {
+		w.mpMutext.Lock()
+		w.strict = strict
+		w.mpMutext.Unlock()
+	}
+}
+
+//anomaly sends data anomaly of the event to error channel or panics in strict mode
+func (b *simBrokerWorker) anomaly(e event, message string) {
+	err := newErrDataAnomaly(e, message, "Sim Broker")
+	if b.strict {
+		panic(err)
+	}
+	b.newError(err)
+}
+
+func (b *BasicStrategy) setStrictMode(strict bool) {
+	b.strict = strict
+}
+
+//anomaly sends data anomaly of the event to error channel or panics in strict mode
+func (b *BasicStrategy) anomaly(e event, message string) {
+	err := newErrDataAnomaly(e, message, "BasicStrategy")
+	if b.strict {
+		panic(err)
+	}
+	b.newError(err)
+}
@@ -0,0 +1,77 @@
+package engine
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func receiveAnomaly(t *testing.T, errChan chan error) *ErrDataAnomaly {
+	select {
+	case err := <-errChan:
+		anomaly, ok := err.(*ErrDataAnomaly)
+		assert.True(t, ok)
+		return anomaly
+	case <-time.After(time.Second):
+		t.Fatal("Data anomaly wasn't reported")
+	}
+	return nil
+}
+
+func TestSimBrokerWorker_anomaly(t *testing.T) {
+	w := newTestSimBrokerWorker()
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	w.lastCandleTime = tm
+
+	w.onCandleClose(newTestCandleCloseEvent(10, 10, 10, 10, tm.Add(-time.Hour), "1"))
+	anomaly := receiveAnomaly(t, w.errChan)
+	assert.Equal(t, "Test", anomaly.Symbol)
+	assert.Equal(t, "CandleCloseEvent", anomaly.Event)
+	assert.True(t, anomaly.Time.Before(tm))
+	assert.Equal(t, tm, w.lastCandleTime)
+
+	w.addBrokerEvent(&OrderFillEvent{BaseEvent: be(tm, w.symbol), OrdId: "Unknown", Price: 10, Qty: 100})
+	anomaly = receiveAnomaly(t, w.errChan)
+	assert.Equal(t, "OrderFillEvent", anomaly.Event)
+	assert.Empty(t, w.generatedEvents)
+
+	w.strict = true
+	assert.Panics(t, func() {
+		w.onCandleClose(newTestCandleCloseEvent(10, 10, 10, 10, tm.Add(-time.Hour), "1"))
+	})
+}
+
+func TestBasicStrategy_anomaly(t *testing.T) {
+	st := newTestBasicStrategy()
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	st.proxyEvent(&PortfolioNewPositionEvent{BaseEvent: be(tm, st.symbol)})
+	anomaly := receiveAnomaly(t, st.ch.errors)
+	assert.Equal(t, "PortfolioNewPositionEvent", anomaly.Event)
+	assert.Equal(t, "BasicStrategy", anomaly.Caller)
+
+	st.setStrictMode(true)
+	assert.Panics(t, func() {
+		st.proxyEvent(&PortfolioNewPositionEvent{BaseEvent: be(tm, st.symbol)})
+	})
+}
+
+func TestEngine_SetStrictMode(t *testing.T) {
+	st := newTestBasicStrategy()
+	broker := newTestSimBroker()
+	c := Engine{
+		broker:        broker,
+		strategiesMap: map[string]ICoreStrategy{st.symbol.Symbol: st},
+		mut:           &sync.Mutex{},
+	}
+	c.SetStrictMode(true)
+	assert.True(t, st.strict)
+	assert.True(t, broker.strict)
+	for _, w := range broker.workers {
+		assert.True(t, w.strict)
+	}
+	assert.Panics(t, func() {
+		c.anomaly(&NewTickEvent{BaseEvent: be(time.Now(), st.symbol)}, "Test")
+	})
+}
@@ -0,0 +1,294 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"errors"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+)
+
+//IPriceProcess moves price one step forward. dt is step length in years of trading time.
+type IPriceProcess interface {
+	Next(rnd *rand.Rand, price float64, dt float64) float64
+}
+
+//GBMProcess is geometric brownian motion with annual drift and volatility
+type GBMProcess struct {
+	Drift      float64
+	Volatility float64
+}
+
+func (p *GBMProcess) Next(rnd *rand.Rand, price float64, dt float64) float64 {
+	return price * math.Exp((p.Drift-p.Volatility*p.Volatility/2)*dt+p.Volatility*math.Sqrt(dt)*rnd.NormFloat64())
+}
+
+//OUProcess is Ornstein-Uhlenbeck mean reverting process. Speed is annual speed of reversion to Mean.
+type OUProcess struct {
+	Mean       float64
+	Speed      float64
+	Volatility float64
+}
+
+func (p *OUProcess) Next(rnd *rand.Rand, price float64, dt float64) float64 {
+	next := price + p.Speed*(p.Mean-price)*dt + p.Volatility*math.Sqrt(dt)*rnd.NormFloat64()
+	if next <= 0 {
+		return price
+	}
+	return next
+}
+
+//RegimeSwitchingProcess uses one of the regimes and switches to random other regime with SwitchProb
+//probability on every step. Synthetic storage starts every day of every symbol in random regime drawn from
+//generator of the day, so the regime isn't shared between symbols and days. Next called without storage has no
+//state, so regime of every step is random.
+type RegimeSwitchingProcess struct {
+	Regimes    []IPriceProcess
+	SwitchProb float64
+}
+
+func (p *RegimeSwitchingProcess) Next(rnd *rand.Rand, price float64, dt float64) float64 {
+	return p.Regimes[rnd.Intn(len(p.Regimes))].Next(rnd, price, dt)
+}
+
+func (p *RegimeSwitchingProcess) newPath(rnd *rand.Rand) IPriceProcess {
+	return &regimePath{process: p, current: rnd.Intn(len(p.Regimes))}
+}
+
+//pathPriceProcess is implemented by price processes with state. Storage gets new path for every generated day.
+type pathPriceProcess interface {
+	newPath(rnd *rand.Rand) IPriceProcess
+}
+
+//regimePath keeps current regime of one generated day
+type regimePath struct {
+	process *RegimeSwitchingProcess
+	current int
+}
+
+func (p *regimePath) Next(rnd *rand.Rand, price float64, dt float64) float64 {
+	regimes := p.process.Regimes
+	if len(regimes) > 1 && rnd.Float64() < p.process.SwitchProb {
+		p.current = (p.current + 1 + rnd.Intn(len(regimes)-1)) % len(regimes)
+	}
+	return regimes[p.current].Next(rnd, price, dt)
+}
+
+//SyntheticStorage generates market data instead of loading it, so it can be used as BTM storage to run
+//strategies on generated prices. Data is generated day by day from Start with Seed, so the same settings
+//always produce the same data. Weekends are skipped.
+type SyntheticStorage struct {
+	Seed         int64
+	Start        time.Time
+	StartPrice   float64
+	Process      IPriceProcess
+	Spread       float64
+	MinTick      float64
+	MinVolume    int64
+	MaxVolume    int64
+	TickInterval time.Duration
+	SessionOpen  TimeOfDay
+	SessionClose TimeOfDay
+
+	mut  sync.Mutex
+	days map[string][]marketdata.TickArray
+}
+
+func NewSyntheticStorage(seed int64, start time.Time, startPrice float64, process IPriceProcess) *SyntheticStorage {
+	s := SyntheticStorage{
+		Seed:         seed,
+		Start:        time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC),
+		StartPrice:   startPrice,
+		Process:      process,
+		Spread:       0.02,
+		MinTick:      0.01,
+		MinVolume:    100,
+		MaxVolume:    1000,
+		TickInterval: time.Minute,
+		SessionOpen:  TimeOfDay{9, 30, 0},
+		SessionClose: TimeOfDay{16, 0, 0},
+		days:         make(map[string][]marketdata.TickArray),
+	}
+	return &s
+}
+
+func (s *SyntheticStorage) GetStoredTicks(symbol string, dRange marketdata.DateRange, quotes bool, trades bool) (marketdata.TickArray, error) {
+	var ticks marketdata.TickArray
+	for d := dRange.From; !d.After(dRange.To); d = d.AddDate(0, 0, 1) {
+		dayTicks, err := s.dayTicks(symbol, d)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range dayTicks {
+			if t.Datetime.Before(dRange.From) || t.Datetime.After(dRange.To) {
+				continue
+			}
+			tc := *t
+			if !quotes {
+				tc.BidPrice, tc.BidSize, tc.AskPrice, tc.AskSize = 0, 0, 0, 0
+			}
+			if !trades {
+				tc.LastPrice, tc.LastSize = 0, 0
+			}
+			ticks = append(ticks, &tc)
+		}
+	}
+	return ticks, nil
+}
+
+//GetStoredCandles aggregates generated ticks to candles of minute timeframes or to day candles
+func (s *SyntheticStorage) GetStoredCandles(symbol string, tf string, dRange marketdata.DateRange) (marketdata.CandleArray, error) {
+	var step time.Duration
+	if tf != "D" {
+		d, err := timeFrameDuration(tf)
+		if err != nil {
+			return nil, err
+		}
+		if tf == "W" {
+			return nil, errors.New("SyntheticStorage can't generate week candles. ")
+		}
+		step = d
+	}
+
+	var candles marketdata.CandleArray
+	for d := dRange.From; !d.After(dRange.To); d = d.AddDate(0, 0, 1) {
+		dayTicks, err := s.dayTicks(symbol, d)
+		if err != nil {
+			return nil, err
+		}
+		var current *marketdata.Candle
+		for _, t := range dayTicks {
+			start := time.Date(t.Datetime.Year(), t.Datetime.Month(), t.Datetime.Day(), 0, 0, 0, 0, time.UTC)
+			if step > 0 {
+				start = t.Datetime.Truncate(step)
+			}
+			if current == nil || !current.Datetime.Equal(start) {
+				current = &marketdata.Candle{
+					Datetime: start,
+					Symbol:   symbol,
+					Open:     t.LastPrice,
+					High:     t.LastPrice,
+					Low:      t.LastPrice,
+				}
+				if !start.Before(dRange.From) && !start.After(dRange.To) {
+					candles = append(candles, current)
+				}
+			}
+			current.High = math.Max(current.High, t.LastPrice)
+			current.Low = math.Min(current.Low, t.LastPrice)
+			current.Close = t.LastPrice
+			current.AdjClose = t.LastPrice
+			current.Volume += t.LastSize
+		}
+	}
+	return candles, nil
+}
+
+//dayTicks returns generated ticks of the day. All days from Start are generated in order, because
+//every day starts from the close of the previous one. Generated days are cached.
+func (s *SyntheticStorage) dayTicks(symbol string, date time.Time) (marketdata.TickArray, error) {
+	if s.Process == nil {
+		return nil, errors.New("SyntheticStorage price process is not set. ")
+	}
+	if s.TickInterval <= 0 {
+		return nil, errors.New("SyntheticStorage tick interval should be positive. ")
+	}
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	if date.Before(s.Start) {
+		return nil, nil
+	}
+	idx := int(date.Sub(s.Start).Hours() / 24)
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if s.days == nil {
+		s.days = make(map[string][]marketdata.TickArray)
+	}
+	days := s.days[symbol]
+	if len(days) > idx {
+		return days[idx], nil
+	}
+
+	//Continue from the close of the last generated trading day
+	price := s.StartPrice
+	for i := len(days) - 1; i >= 0; i-- {
+		if n := len(days[i]); n > 0 {
+			price = days[i][n-1].LastPrice
+			break
+		}
+	}
+
+	for len(days) <= idx {
+		d := s.Start.AddDate(0, 0, len(days))
+		var ticks marketdata.TickArray
+		if d.Weekday() != time.Saturday && d.Weekday() != time.Sunday {
+			ticks = s.generateDay(s.dayRand(symbol, len(days)), symbol, d, price)
+			if len(ticks) > 0 {
+				price = ticks[len(ticks)-1].LastPrice
+			}
+		}
+		days = append(days, ticks)
+	}
+	s.days[symbol] = days
+	return days[idx], nil
+}
+
+//dayRand returns random generator of the day. Every day has its own seed, so generated data doesn't
+//depend on the order in which days are requested.
+func (s *SyntheticStorage) dayRand(symbol string, day int) *rand.Rand {
+	h := fnv.New64a()
+	h.Write([]byte(symbol + "|" + strconv.Itoa(day)))
+	return rand.New(rand.NewSource(s.Seed ^ int64(h.Sum64())))
+}
+
+func (s *SyntheticStorage) generateDay(rnd *rand.Rand, symbol string, date time.Time, price float64) marketdata.TickArray {
+	so, sc := s.SessionOpen, s.SessionClose
+	open := time.Date(date.Year(), date.Month(), date.Day(), so.Hour, so.Minute, so.Second, 0, time.UTC)
+	end := time.Date(date.Year(), date.Month(), date.Day(), sc.Hour, sc.Minute, sc.Second, 0, time.UTC)
+	session := end.Sub(open)
+	if session <= 0 {
+		return nil
+	}
+	dt := s.TickInterval.Hours() / (session.Hours() * 252)
+	process := s.Process
+	if pp, ok := process.(pathPriceProcess); ok {
+		process = pp.newPath(rnd)
+	}
+
+	var ticks marketdata.TickArray
+	for t := open; t.Before(end); t = t.Add(s.TickInterval) {
+		price = process.Next(rnd, price, dt)
+		last := s.round(price)
+		volume := s.MinVolume
+		if s.MaxVolume > s.MinVolume {
+			volume += rnd.Int63n(s.MaxVolume - s.MinVolume + 1)
+		}
+		tick := marketdata.Tick{
+			Datetime:  t,
+			Symbol:    symbol,
+			LastPrice: last,
+			LastSize:  volume,
+			LastExch:  "SYNTH",
+			BidPrice:  s.round(last - s.Spread/2),
+			BidSize:   volume,
+			AskPrice:  s.round(last + s.Spread/2),
+			AskSize:   volume,
+			IsOpening: t.Equal(open),
+			IsClosing: !t.Add(s.TickInterval).Before(end),
+		}
+		ticks = append(ticks, &tick)
+	}
+	return ticks
+}
+
+func (s *SyntheticStorage) round(price float64) float64 {
+	if s.MinTick <= 0 {
+		return price
+	}
+	r, _ := strconv.ParseFloat(strconv.FormatFloat(math.Round(price/s.MinTick)*s.MinTick, 'f', 8, 64), 64)
+	return r
+}
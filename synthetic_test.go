@@ -0,0 +1,94 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSyntheticStorage(t *testing.T) {
+	start := time.Date(2018, 3, 5, 0, 0, 0, 0, time.UTC)
+	rng := marketdata.DateRange{
+		From: time.Date(2018, 3, 7, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2018, 3, 7, 23, 59, 59, 0, time.UTC),
+	}
+
+	t.Log("Same seed gives the same data regardless of requested days order")
+	{
+		s1 := NewSyntheticStorage(42, start, 100, &GBMProcess{Drift: 0.05, Volatility: 0.2})
+		s2 := NewSyntheticStorage(42, start, 100, &GBMProcess{Drift: 0.05, Volatility: 0.2})
+		_, err := s2.GetStoredTicks("Test", marketdata.DateRange{From: start, To: start.Add(time.Hour * 23)}, true, true)
+		assert.Nil(t, err)
+
+		t1, err := s1.GetStoredTicks("Test", rng, true, true)
+		assert.Nil(t, err)
+		t2, err := s2.GetStoredTicks("Test", rng, true, true)
+		assert.Nil(t, err)
+
+		assert.Len(t, t1, 390)
+		assert.Equal(t, t1[100].LastPrice, t2[100].LastPrice)
+		assert.True(t, t1[0].IsOpening)
+		assert.True(t, t1[389].IsClosing)
+		assert.InDelta(t, 0.02, t1[5].AskPrice-t1[5].BidPrice, 0.0000001)
+	}
+
+	t.Log("Weekends are not generated")
+	{
+		s := NewSyntheticStorage(1, start, 100, &OUProcess{Mean: 100, Speed: 5, Volatility: 10})
+		ticks, err := s.GetStoredTicks("Test", marketdata.DateRange{
+			From: time.Date(2018, 3, 10, 0, 0, 0, 0, time.UTC),
+			To:   time.Date(2018, 3, 11, 23, 59, 59, 0, time.UTC),
+		}, true, true)
+		assert.Nil(t, err)
+		assert.Len(t, ticks, 0)
+	}
+
+	t.Log("Candles are aggregated from ticks")
+	{
+		s := NewSyntheticStorage(7, start, 50, &RegimeSwitchingProcess{
+			Regimes:    []IPriceProcess{&GBMProcess{Volatility: 0.1}, &GBMProcess{Volatility: 0.6}},
+			SwitchProb: 0.01,
+		})
+		candles, err := s.GetStoredCandles("Test", "5", rng)
+		assert.Nil(t, err)
+		assert.Len(t, candles, 78)
+		ticks, _ := s.GetStoredTicks("Test", rng, true, true)
+		assert.Equal(t, ticks[0].LastPrice, candles[0].Open)
+		assert.Equal(t, ticks[4].LastPrice, candles[0].Close)
+
+		days, err := s.GetStoredCandles("Test", "D", rng)
+		assert.Nil(t, err)
+		assert.Len(t, days, 1)
+		assert.Equal(t, ticks[389].LastPrice, days[0].Close)
+	}
+}
+
+func TestSyntheticStorage_RegimeOrder(t *testing.T) {
+	start := time.Date(2018, 3, 5, 0, 0, 0, 0, time.UTC)
+	first := marketdata.DateRange{From: start, To: start.Add(time.Hour * 23)}
+	second := marketdata.DateRange{From: start.AddDate(0, 0, 1), To: start.AddDate(0, 0, 1).Add(time.Hour * 23)}
+	newStorage := func() *SyntheticStorage {
+		return NewSyntheticStorage(3, start, 100, &RegimeSwitchingProcess{
+			Regimes:    []IPriceProcess{&GBMProcess{Volatility: 0.1}, &OUProcess{Mean: 100, Speed: 5, Volatility: 20}},
+			SwitchProb: 0.05,
+		})
+	}
+	load := func(s *SyntheticStorage, symbol string, r marketdata.DateRange) marketdata.TickArray {
+		ticks, err := s.GetStoredTicks(symbol, r, true, true)
+		assert.Nil(t, err)
+		return ticks
+	}
+
+	s1 := newStorage()
+	a1, b1 := load(s1, "A", first), load(s1, "B", second)
+	s2 := newStorage()
+	b2, a2 := load(s2, "B", second), load(s2, "A", first)
+	assert.Equal(t, a1, a2)
+	assert.Equal(t, b1, b2)
+
+	s1, s2 = newStorage(), newStorage()
+	d1, d2 := load(s1, "A", first), load(s1, "A", second)
+	assert.Equal(t, d2, load(s2, "A", second))
+	assert.Equal(t, d1, load(s2, "A", first))
+}
@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+)
+
+//targetPosition is position which strategy keeps with market orders
+type targetPosition struct {
+	qty         int64
+	destination string
+	id          string
+}
+
+//SetTargetPosition sets position which strategy should have. Negative qty is short position. Difference
+//between target and current position with working orders is sent as market order. Working orders which
+//don't lead to the target are canceled first. Stop loss and take profit orders are not counted. Strategy
+//shouldn't send other orders while target position is set.
+func (b *BasicStrategy) SetTargetPosition(qty int64, destination string) error {
+	if destination == "" {
+		return errors.New("Target position destination is empty. ")
+	}
+	if b.target == nil {
+		b.target = &targetPosition{}
+	}
+	b.target.qty = qty
+	b.target.destination = destination
+	b.syncTargetPosition()
+	return nil
+}
+
+//ClearTargetPosition stops position management. Working orders are not canceled.
+func (b *BasicStrategy) ClearTargetPosition() {
+	b.target = nil
+}
+
+//workingQty returns signed not executed qty of strategy orders, except exit orders. Second value is false
+//if some order or its cancel request waits for broker answer.
+func (b *BasicStrategy) workingQty() (int64, bool) {
+	var qty int64
+	for id, o := range b.currentTrade.ConfirmedOrders {
+		if b.isExitOrder(id) {
+			continue
+		}
+		if _, ok := b.waitingConfirmation["$CAN$"+id]; ok {
+			return 0, false
+		}
+		leaves := o.Qty - o.ExecQty
+		if o.Side == OrderSell {
+			leaves = -leaves
+		}
+		qty += leaves
+	}
+	for id := range b.currentTrade.NewOrders {
+		if !b.isExitOrder(id) {
+			return 0, false
+		}
+	}
+	return qty, true
+}
+
+func (b *BasicStrategy) syncTargetPosition() {
+	if b.target == nil {
+		return
+	}
+	if r, ok := b.orders[b.target.id]; ok && r.order.State == RejectedOrder {
+		b.newError(fmt.Errorf("Target position order %v was rejected. Target position is removed. ", r.order.Id))
+		b.target = nil
+		return
+	}
+	working, ok := b.workingQty()
+	if !ok {
+		return
+	}
+	pos := b.Position()
+	if pos+working == b.target.qty {
+		return
+	}
+	if working != 0 {
+		if err := b.CancelAllOrders(); err != nil {
+			b.newError(err)
+		}
+		return
+	}
+
+	side := OrderBuy
+	delta := b.target.qty - pos
+	if delta < 0 {
+		side = OrderSell
+		delta = -delta
+	}
+	id, err := b.NewMarketOrder(side, delta, DayTIF, b.target.destination)
+	if err != nil {
+		b.newError(err)
+		return
+	}
+	b.target.id = id
+}
+
+//syncManagedOrders makes orders managed by strategy match current position
+func (b *BasicStrategy) syncManagedOrders() {
+	b.syncTargetPosition()
+	b.syncTrailingStop()
+	b.syncExitOrders()
+}
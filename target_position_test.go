@@ -0,0 +1,63 @@
+package engine
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestBasicStrategy_SetTargetPosition(t *testing.T) {
+	st := newTestBasicStrategy()
+	st.ch.events = make(chan event, 10)
+	go func() {
+		for range st.ch.errors {
+		}
+	}()
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	st.mostRecentTime = tm
+	nextOrder := func() *Order {
+		assert.Len(t, st.ch.events, 1)
+		return (<-st.ch.events).(*NewOrderEvent).LinkedOrder
+	}
+
+	assert.NotNil(t, st.SetTargetPosition(100, ""))
+	assert.Nil(t, st.SetTargetPosition(100, "Sim"))
+	ord := nextOrder()
+	assert.Equal(t, MarketOrder, ord.Type)
+	assert.Equal(t, OrderBuy, ord.Side)
+	assert.Equal(t, int64(100), ord.Qty)
+
+	//Nothing is sent while order waits for confirmation
+	assert.Nil(t, st.SetTargetPosition(100, "Sim"))
+	assert.Len(t, st.ch.events, 0)
+	st.proxyEvent(&OrderConfirmationEvent{BaseEvent: be(tm, st.symbol), OrdId: ord.Id})
+	st.proxyEvent(&OrderFillEvent{BaseEvent: be(tm, st.symbol), OrdId: ord.Id, Price: 50, Qty: 100})
+	assert.Equal(t, int64(100), st.Position())
+	assert.Len(t, st.ch.events, 0)
+
+	//Position is reversed
+	assert.Nil(t, st.SetTargetPosition(-50, "Sim"))
+	ord = nextOrder()
+	assert.Equal(t, OrderSell, ord.Side)
+	assert.Equal(t, int64(150), ord.Qty)
+	st.proxyEvent(&OrderConfirmationEvent{BaseEvent: be(tm, st.symbol), OrdId: ord.Id})
+	st.proxyEvent(&OrderFillEvent{BaseEvent: be(tm, st.symbol), OrdId: ord.Id, Price: 50, Qty: 50})
+	assert.Equal(t, int64(50), st.Position())
+	assert.Len(t, st.ch.events, 0)
+
+	//Working order doesn't lead to the new target, so it's canceled and new order is sent after cancel
+	assert.Nil(t, st.SetTargetPosition(0, "Sim"))
+	assert.Len(t, st.ch.events, 1)
+	cancel := (<-st.ch.events).(*OrderCancelRequestEvent)
+	assert.Equal(t, ord.Id, cancel.OrdId)
+	st.proxyEvent(&OrderCancelEvent{BaseEvent: be(tm, st.symbol), OrdId: ord.Id})
+	ord = nextOrder()
+	assert.Equal(t, OrderSell, ord.Side)
+	assert.Equal(t, int64(50), ord.Qty)
+
+	//Rejected order removes target
+	st.proxyEvent(&OrderRejectedEvent{BaseEvent: be(tm, st.symbol), OrdId: ord.Id, Reason: "Test"})
+	assert.Nil(t, st.target)
+	assert.Len(t, st.ch.events, 0)
+	st.shutDown()
+}
@@ -0,0 +1,192 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type LotMethod int
+
+const (
+	//FIFOLots closes the oldest lots first
+	FIFOLots LotMethod = iota
+	//LIFOLots closes the newest lots first
+	LIFOLots
+)
+
+//TaxLot is qty of the position bought or sold short by one execution. Qty is not closed part of the lot.
+type TaxLot struct {
+	Id        string
+	Symbol    string
+	Direction TradeType
+	Qty       int64
+	OpenQty   int64
+	OpenPrice float64
+	OpenTime  time.Time
+}
+
+//RealizedLot is part of the lot closed by one execution. PnL is in account currency without commissions.
+type RealizedLot struct {
+	LotId      string
+	Symbol     string
+	Direction  TradeType
+	Qty        int64
+	OpenPrice  float64
+	ClosePrice float64
+	OpenTime   time.Time
+	CloseTime  time.Time
+	PnL        float64
+	//LongTerm is true if lot was held more than one year
+	LongTerm bool
+}
+
+//lotBook keeps open lots in open time order and realized records of the strategy
+type lotBook struct {
+	method   LotMethod
+	open     []*TaxLot
+	realized []RealizedLot
+	selected map[string][]string
+	n        int
+}
+
+//SetLotMethod sets which lots are closed first when position is reduced. Lots selected for the order with
+//SelectLots are closed before others.
+func (b *BasicStrategy) SetLotMethod(method LotMethod) error {
+	if method != FIFOLots && method != LIFOLots {
+		return fmt.Errorf("Unknown lot method %v. ", method)
+	}
+	b.lots.method = method
+	return nil
+}
+
+//SelectLots sets lots which executions of the order close first, for specific lot identification
+func (b *BasicStrategy) SelectLots(ordID string, lotIDs ...string) error {
+	if _, ok := b.orders[ordID]; !ok {
+		return fmt.Errorf("Order %v is unknown. ", ordID)
+	}
+	for _, id := range lotIDs {
+		if b.lots.find(id) < 0 {
+			return fmt.Errorf("Lot %v is not open. ", id)
+		}
+	}
+	if len(lotIDs) == 0 {
+		return errors.New("No lots selected. ")
+	}
+	if b.lots.selected == nil {
+		b.lots.selected = make(map[string][]string)
+	}
+	b.lots.selected[ordID] = lotIDs
+	return nil
+}
+
+//OpenLots returns copies of open lots in open time order
+func (b *BasicStrategy) OpenLots() []TaxLot {
+	lots := make([]TaxLot, len(b.lots.open))
+	for i, l := range b.lots.open {
+		lots[i] = *l
+	}
+	return lots
+}
+
+//RealizedLots returns records of closed parts of the lots in close time order
+func (b *BasicStrategy) RealizedLots() []RealizedLot {
+	return append([]RealizedLot{}, b.lots.realized...)
+}
+
+func (l *lotBook) find(id string) int {
+	for i, lot := range l.open {
+		if lot.Id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+//closeOrder returns indexes of open lots of the direction in order they should be closed
+func (l *lotBook) closeOrder(direction TradeType, ordID string) []int {
+	var order []int
+	used := make(map[int]bool)
+	for _, id := range l.selected[ordID] {
+		if i := l.find(id); i >= 0 && l.open[i].Direction == direction {
+			order = append(order, i)
+			used[i] = true
+		}
+	}
+	for k := range l.open {
+		i := k
+		if l.method == LIFOLots {
+			i = len(l.open) - 1 - k
+		}
+		if !used[i] && l.open[i].Direction == direction {
+			order = append(order, i)
+		}
+	}
+	return order
+}
+
+//updateLots closes and opens lots by execution of the trade which position was prevPos before it
+func (b *BasicStrategy) updateLots(ordID string, side OrderSide, prevPos int64, qty int64, price float64, t time.Time) {
+	l := &b.lots
+	if (prevPos > 0 && side == OrderSell) || (prevPos < 0 && side == OrderBuy) {
+		direction := LongTrade
+		sign := 1.0
+		if prevPos < 0 {
+			direction = ShortTrade
+			sign = -1
+		}
+		toClose := qty
+		if toClose > abs64(prevPos) {
+			toClose = abs64(prevPos)
+		}
+		qty -= toClose
+		for _, i := range l.closeOrder(direction, ordID) {
+			if toClose == 0 {
+				break
+			}
+			lot := l.open[i]
+			n := lot.Qty
+			if n > toClose {
+				n = toClose
+			}
+			lot.Qty -= n
+			toClose -= n
+			l.realized = append(l.realized, RealizedLot{
+				LotId:      lot.Id,
+				Symbol:     lot.Symbol,
+				Direction:  direction,
+				Qty:        n,
+				OpenPrice:  lot.OpenPrice,
+				ClosePrice: price,
+				OpenTime:   lot.OpenTime,
+				CloseTime:  t,
+				PnL:        sign * b.unitValue(price-lot.OpenPrice) * float64(n),
+				LongTerm:   t.After(lot.OpenTime.AddDate(1, 0, 0)),
+			})
+		}
+		open := l.open[:0]
+		for _, lot := range l.open {
+			if lot.Qty > 0 {
+				open = append(open, lot)
+			}
+		}
+		l.open = open
+	}
+	if qty == 0 {
+		return
+	}
+	direction := LongTrade
+	if side == OrderSell {
+		direction = ShortTrade
+	}
+	l.n++
+	l.open = append(l.open, &TaxLot{
+		Id:        fmt.Sprintf("%v/%v", ordID, l.n),
+		Symbol:    b.symbol.Symbol,
+		Direction: direction,
+		Qty:       qty,
+		OpenQty:   qty,
+		OpenPrice: price,
+		OpenTime:  t,
+	})
+}
@@ -0,0 +1,62 @@
+package engine
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestBasicStrategy_TaxLots(t *testing.T) {
+	st := newTestRiskStrategy()
+	st.symbol.LotSize = 1
+	st.mostRecentTime = time.Date(2017, 3, 5, 10, 0, 0, 0, time.UTC)
+	assert.NotNil(t, st.SetLotMethod(LotMethod(5)))
+
+	fillTestOrder(st, OrderBuy, 100, 10)
+	st.mostRecentTime = st.mostRecentTime.AddDate(0, 6, 0)
+	fillTestOrder(st, OrderBuy, 50, 12)
+	st.mostRecentTime = st.mostRecentTime.AddDate(0, 7, 0)
+	fillTestOrder(st, OrderBuy, 50, 14)
+	lots := st.OpenLots()
+	assert.Len(t, lots, 3)
+
+	//FIFO
+	fillTestOrder(st, OrderSell, 120, 15)
+	realized := st.RealizedLots()
+	assert.Len(t, realized, 2)
+	assert.Equal(t, lots[0].Id, realized[0].LotId)
+	assert.Equal(t, int64(100), realized[0].Qty)
+	assert.InDelta(t, 500.0, realized[0].PnL, 0.0000001)
+	assert.True(t, realized[0].LongTerm)
+	assert.Equal(t, int64(20), realized[1].Qty)
+	assert.InDelta(t, 60.0, realized[1].PnL, 0.0000001)
+	assert.False(t, realized[1].LongTerm)
+	assert.Len(t, st.OpenLots(), 2)
+	assert.Equal(t, int64(30), st.OpenLots()[0].Qty)
+
+	//LIFO
+	assert.Nil(t, st.SetLotMethod(LIFOLots))
+	fillTestOrder(st, OrderSell, 10, 15)
+	assert.Equal(t, lots[2].Id, st.RealizedLots()[2].LotId)
+
+	//Specific lot
+	id, err := st.NewMarketOrder(OrderSell, 10, DayTIF, "Sim")
+	assert.Nil(t, err)
+	assert.NotNil(t, st.SelectLots(id, "unknown"))
+	assert.Nil(t, st.SelectLots(id, lots[1].Id))
+	st.proxyEvent(&OrderConfirmationEvent{BaseEvent: be(st.mostRecentTime, st.symbol), OrdId: id})
+	st.proxyEvent(&OrderFillEvent{BaseEvent: be(st.mostRecentTime, st.symbol), OrdId: id, Price: 11, Qty: 10})
+	assert.Equal(t, lots[1].Id, st.RealizedLots()[3].LotId)
+	assert.InDelta(t, -10.0, st.RealizedLots()[3].PnL, 0.0000001)
+
+	//Reversal closes all lots and opens short lot
+	fillTestOrder(st, OrderSell, 100, 16)
+	lots = st.OpenLots()
+	assert.Len(t, lots, 1)
+	assert.Equal(t, ShortTrade, lots[0].Direction)
+	assert.Equal(t, int64(40), lots[0].Qty)
+	fillTestOrder(st, OrderBuy, 40, 15)
+	realized = st.RealizedLots()
+	assert.InDelta(t, 40.0, realized[len(realized)-1].PnL, 0.0000001)
+	assert.Len(t, st.OpenLots(), 0)
+}
@@ -0,0 +1,174 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//timeFrameDuration returns duration of candle for given timeframe. Timeframe is number of minutes or
+//"D" and "W" for day and week candles
+func timeFrameDuration(tf string) (time.Duration, error) {
+	switch tf {
+	case "D":
+		return 24 * time.Hour, nil
+	case "W":
+		return 7 * 24 * time.Hour, nil
+	default:
+		minutes, err := strconv.ParseInt(tf, 10, 64)
+		if err != nil || minutes <= 0 {
+			return 0, fmt.Errorf("Unknown timeframe: %v", tf)
+		}
+		return time.Duration(minutes) * time.Minute, nil
+	}
+}
+
+//sortTimeFrames sorts timeframes from lowest to highest. Unknown timeframes go to the end
+func sortTimeFrames(tfs []string) {
+	sort.SliceStable(tfs, func(i, j int) bool {
+		di, erri := timeFrameDuration(tfs[i])
+		dj, errj := timeFrameDuration(tfs[j])
+		if erri != nil {
+			return false
+		}
+		if errj != nil {
+			return true
+		}
+		return di < dj
+	})
+}
+
+//candleAggregator builds candles of higher timeframe from the candles of base timeframe.
+type candleAggregator struct {
+	timeFrame   string
+	current     *Candle
+	bucketStart time.Time
+	bucketEnd   time.Time
+}
+
+//bucket returns start and end of higher timeframe candle which contains given time
+func (a *candleAggregator) bucket(t time.Time) (time.Time, time.Time) {
+	switch a.timeFrame {
+	case "D":
+		start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		return start, start.AddDate(0, 0, 1)
+	case "W":
+		start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		offset := (int(start.Weekday()) + 6) % 7 //Weeks start on monday
+		start = start.AddDate(0, 0, -offset)
+		return start, start.AddDate(0, 0, 7)
+	default:
+		d, _ := timeFrameDuration(a.timeFrame)
+		start := t.Truncate(d)
+		return start, start.Add(d)
+	}
+}
+
+//add puts base timeframe candle to aggregator. It returns completed candle of aggregator timeframe if
+//there is one. If new candle belongs to the next bucket, previous unfinished candle is returned as completed.
+func (a *candleAggregator) add(c *Candle, baseTimeFrame string) ([]*Candle, error) {
+	baseDur, err := timeFrameDuration(baseTimeFrame)
+	if err != nil {
+		return nil, err
+	}
+	aggDur, err := timeFrameDuration(a.timeFrame)
+	if err != nil {
+		return nil, err
+	}
+	if aggDur <= baseDur {
+		return nil, fmt.Errorf("Can't aggregate %v candles to %v timeframe. ", baseTimeFrame, a.timeFrame)
+	}
+
+	var completed []*Candle
+	candleTime := c.Datetime
+	if c.Ticker != nil {
+		candleTime = c.Ticker.Exchange.LocalTime(candleTime)
+	}
+	start, end := a.bucket(candleTime)
+
+	if a.current != nil && !start.Equal(a.bucketStart) {
+		if c.Datetime.Before(a.bucketStart) {
+			return nil, nil
+		}
+		completed = append(completed, a.current)
+		a.current = nil
+	}
+
+	if a.current == nil {
+		a.current = &Candle{
+			Candle: &marketdata.Candle{
+				Datetime: start,
+				Symbol:   c.Symbol,
+				Open:     c.Open,
+				High:     c.High,
+				Low:      c.Low,
+				Close:    c.Close,
+				AdjClose: c.AdjClose,
+			},
+			Ticker: c.Ticker,
+		}
+		a.bucketStart = start
+		a.bucketEnd = end
+	} else {
+		if c.High > a.current.High {
+			a.current.High = c.High
+		}
+		if c.Low < a.current.Low {
+			a.current.Low = c.Low
+		}
+		a.current.Close = c.Close
+		a.current.AdjClose = c.AdjClose
+	}
+	a.current.Volume += c.Volume
+	a.current.OpenInterest = c.OpenInterest
+
+	baseEnd := c.Datetime.Add(baseDur)
+	sessionEnd := c.Ticker != nil && a.timeFrame == "D" && c.isClosingForTimeFrame(baseTimeFrame)
+	if !baseEnd.Before(a.bucketEnd) || sessionEnd {
+		completed = append(completed, a.current)
+		a.current = nil
+	}
+
+	return completed, nil
+}
+
+//timeFrameCandles is candles buffer and indicators for one of the additional timeframes of the strategy
+type timeFrameCandles struct {
+	aggregator      *candleAggregator
+	candles         CandleArray
+	indicators      map[string]IIndicator
+	indicatorsOrder []string
+}
+
+func newTimeFrameCandles(tf string) *timeFrameCandles {
+	return &timeFrameCandles{aggregator: &candleAggregator{timeFrame: tf}}
+}
+
+func (t *timeFrameCandles) put(c *Candle, nPeriods int) {
+	if len(t.candles) < nPeriods {
+		t.candles = append(t.candles, c)
+		return
+	}
+	t.candles = append(t.candles[1:], c)
+}
+
+func (t *timeFrameCandles) addIndicator(name string, ind IIndicator) {
+	if t.indicators == nil {
+		t.indicators = make(map[string]IIndicator)
+	}
+	if _, ok := t.indicators[name]; !ok {
+		t.indicatorsOrder = append(t.indicatorsOrder, name)
+	}
+	t.indicators[name] = ind
+	for _, c := range t.candles {
+		ind.Update(c)
+	}
+}
+
+func (t *timeFrameCandles) updateIndicators(c *Candle) {
+	for _, name := range t.indicatorsOrder {
+		t.indicators[name].Update(c)
+	}
+}
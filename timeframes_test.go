@@ -0,0 +1,134 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func newTestMinuteCandle(datetime time.Time, open, high, low, close float64) *Candle {
+	c := Candle{
+		Candle: &marketdata.Candle{
+			Datetime: datetime,
+			Symbol:   "Test",
+			Open:     open,
+			High:     high,
+			Low:      low,
+			Close:    close,
+			Volume:   100,
+		},
+		Ticker: newTestInstrument(),
+	}
+	return &c
+}
+
+func TestTimeFrameDuration(t *testing.T) {
+	d, err := timeFrameDuration("240")
+	assert.Nil(t, err)
+	assert.Equal(t, 240*time.Minute, d)
+
+	d, err = timeFrameDuration("D")
+	assert.Nil(t, err)
+	assert.Equal(t, 24*time.Hour, d)
+
+	_, err = timeFrameDuration("X")
+	assert.NotNil(t, err)
+
+	tfs := []string{"D", "60", "5", "W", "15"}
+	sortTimeFrames(tfs)
+	assert.Equal(t, []string{"5", "15", "60", "D", "W"}, tfs)
+}
+
+func TestCandleAggregator_add(t *testing.T) {
+	t.Log("Aggregate 5 min candles to 15 min")
+	{
+		a := candleAggregator{timeFrame: "15"}
+		start := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+
+		closed, err := a.add(newTestMinuteCandle(start, 10, 11, 9.5, 10.5), "5")
+		assert.Nil(t, err)
+		assert.Len(t, closed, 0)
+
+		closed, err = a.add(newTestMinuteCandle(start.Add(5*time.Minute), 10.5, 12, 10, 11), "5")
+		assert.Nil(t, err)
+		assert.Len(t, closed, 0)
+
+		closed, err = a.add(newTestMinuteCandle(start.Add(10*time.Minute), 11, 11.5, 9, 9.2), "5")
+		assert.Nil(t, err)
+		assert.Len(t, closed, 1)
+
+		c := closed[0]
+		assert.Equal(t, start, c.Datetime)
+		assert.Equal(t, 10.0, c.Open)
+		assert.Equal(t, 12.0, c.High)
+		assert.Equal(t, 9.0, c.Low)
+		assert.Equal(t, 9.2, c.Close)
+		assert.Equal(t, int64(300), c.Volume)
+	}
+
+	t.Log("Gap in data closes unfinished candle")
+	{
+		a := candleAggregator{timeFrame: "15"}
+		start := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+
+		closed, err := a.add(newTestMinuteCandle(start, 10, 11, 9.5, 10.5), "5")
+		assert.Nil(t, err)
+		assert.Len(t, closed, 0)
+
+		closed, err = a.add(newTestMinuteCandle(start.Add(30*time.Minute), 10.5, 12, 10, 11), "5")
+		assert.Nil(t, err)
+		assert.Len(t, closed, 1)
+		assert.Equal(t, 10.5, closed[0].Close)
+	}
+
+	t.Log("Day candle is closed on session close")
+	{
+		a := candleAggregator{timeFrame: "D"}
+		closed, err := a.add(newTestMinuteCandle(time.Date(2018, 3, 5, 15, 50, 0, 0, time.UTC), 10, 11, 9.5, 10.5), "5")
+		assert.Nil(t, err)
+		assert.Len(t, closed, 0)
+
+		closed, err = a.add(newTestMinuteCandle(time.Date(2018, 3, 5, 15, 55, 0, 0, time.UTC), 10.5, 11, 10, 10.8), "5")
+		assert.Nil(t, err)
+		assert.Len(t, closed, 1)
+		assert.Equal(t, 10.8, closed[0].Close)
+	}
+
+	t.Log("Can't aggregate to lower timeframe")
+	{
+		a := candleAggregator{timeFrame: "5"}
+		_, err := a.add(newTestMinuteCandle(time.Date(2018, 3, 5, 15, 50, 0, 0, time.UTC), 10, 11, 9.5, 10.5), "15")
+		assert.NotNil(t, err)
+	}
+}
+
+func TestBasicStrategy_AddTimeFrameIndicator(t *testing.T) {
+	st := newTestBasicStrategy()
+	assert.Nil(t, st.SetTimeFrames("1", "5"))
+	assert.NotNil(t, st.AddTimeFrameIndicator("sma", "60", NewSMA(2)))
+	assert.Nil(t, st.AddTimeFrameIndicator("sma", "5", NewSMA(2)))
+	assert.Nil(t, st.AddTimeFrameIndicator("sma", "1", NewSMA(2)))
+
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	for i := 0; i < 10; i++ {
+		//Candle which closes 5 minute bucket is not seen by 5 minute indicator until it's closed
+		if i == 9 {
+			assert.InDelta(t, 7.5, st.IndicatorOf("sma", "1").Value(), 0.0000001)
+			assert.False(t, st.IndicatorOf("sma", "5").Ready())
+		}
+		c := float64(i)
+		st.notify(&CandleCloseEvent{BaseEvent: be(tm.Add(time.Duration(i)*time.Minute), st.symbol),
+			Candle: newTestMinuteCandle(tm.Add(time.Duration(i)*time.Minute), c, c, c, c), TimeFrame: "1"})
+		st.shutDown()
+	}
+
+	assert.InDelta(t, 8.5, st.IndicatorOf("sma", "1").Value(), 0.0000001)
+	assert.InDelta(t, 6.5, st.IndicatorOf("sma", "5").Value(), 0.0000001)
+	assert.Nil(t, st.IndicatorOf("sma", "D"))
+	assert.Nil(t, st.IndicatorOf("unknown", "5"))
+
+	//Indicator added later is warmed up by candles in timeframe buffer
+	assert.Nil(t, st.AddTimeFrameIndicator("last", "5", NewSMA(1)))
+	assert.InDelta(t, 9.0, st.IndicatorOf("last", "5").Value(), 0.0000001)
+}
@@ -1,6 +1,9 @@
 package engine
 
-import "time"
+import (
+	"sync"
+	"time"
+)
 
 type ITimerEventProducer interface {
 	Run()
@@ -72,3 +75,106 @@ func (t *TimerEventProducer) SetDelay(d int64) {
 func (t *TimerEventProducer) SetFraction(f int64) {
 	t.fraction = f
 }
+
+//timerStrategy is implemented by strategies which have timers
+type timerStrategy interface {
+	dueTimers(now time.Time) []event
+}
+
+//ITimerUserStrategy can be implemented by user strategy to get callbacks of timers added with AddTimer and
+//AddDailyTimer
+type ITimerUserStrategy interface {
+	OnTimer(b *BasicStrategy, name string, t time.Time)
+}
+
+type timerKind int
+
+const (
+	intervalTimer timerKind = iota
+	dailyTimer
+	sessionOpenTimer
+	sessionCloseTimer
+)
+
+const (
+	sessionOpenTimerName  = "$SessionOpen$"
+	sessionCloseTimerName = "$SessionClose$"
+)
+
+//strategyTimer fires every interval, daily at given exchange time on trading days or at session open or close
+type strategyTimer struct {
+	name  string
+	kind  timerKind
+	every time.Duration
+	at    TimeOfDay
+	next  time.Time
+}
+
+//nextAfter returns first fire time after given moment. Interval timers are aligned to interval.
+func (t *strategyTimer) nextAfter(after time.Time, e *Exchange) time.Time {
+	if t.kind == intervalTimer {
+		return after.Truncate(t.every).Add(t.every)
+	}
+	l := e.LocalTime(after)
+	d := time.Date(l.Year(), l.Month(), l.Day(), t.at.Hour, t.at.Minute, t.at.Second, 0, l.Location())
+	for i := 0; i < 366; i++ {
+		fire, ok := d, e.IsTradingDay(d)
+		switch t.kind {
+		case sessionOpenTimer:
+			fire, ok = e.SessionOpen(d)
+		case sessionCloseTimer:
+			fire, ok = e.SessionClose(d)
+		}
+		if ok && fire.After(after) {
+			return fire
+		}
+		d = d.AddDate(0, 0, 1)
+	}
+	return d
+}
+
+type strategyTimers struct {
+	mut    sync.Mutex
+	timers []*strategyTimer
+}
+
+//due returns timer events which should fire not later than now. If timer should have fired several times
+//since last check (e.g. during data gap), it fires once with the last due time.
+func (s *strategyTimers) due(now time.Time, inst *Instrument) []event {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	var events []event
+	for _, t := range s.timers {
+		if t.next.IsZero() {
+			t.next = t.nextAfter(now.Add(-time.Nanosecond), &inst.Exchange)
+		}
+		if t.next.After(now) {
+			continue
+		}
+		fired := t.next
+		if t.kind != intervalTimer {
+			for !t.next.After(now) {
+				fired = t.next
+				t.next = t.nextAfter(t.next, &inst.Exchange)
+			}
+		} else {
+			fired = now.Truncate(t.every)
+			t.next = fired.Add(t.every)
+		}
+		events = append(events, &TimerEvent{BaseEvent: be(fired, inst), Name: t.name})
+	}
+	return events
+}
+
+func (s *strategyTimers) add(t *strategyTimer) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	for i, old := range s.timers {
+		if old.name == t.name {
+			s.timers[i] = t
+			return
+		}
+	}
+	s.timers = append(s.timers, t)
+}
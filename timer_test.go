@@ -3,6 +3,7 @@ package engine
 import (
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 func TestTimerEventProducer_Run(t *testing.T) {
@@ -37,3 +38,94 @@ TIMER_LOOP:
 
 	t.Log("Timer is done")
 }
+
+func TestStrategyTimers_due(t *testing.T) {
+	inst := newTestCalendarInstrument()
+	timers := &strategyTimers{}
+	timers.add(&strategyTimer{name: "5min", kind: intervalTimer, every: 5 * time.Minute})
+	timers.add(&strategyTimer{name: "close", kind: dailyTimer, at: TimeOfDay{15, 45, 0}})
+
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	events := timers.due(tm, inst)
+	assert.Len(t, events, 1)
+	assert.Equal(t, "5min", events[0].(*TimerEvent).Name)
+	assert.Equal(t, tm, events[0].getTime())
+
+	assert.Len(t, timers.due(tm.Add(time.Minute), inst), 0)
+
+	//Missed fires are collapsed to the last one
+	events = timers.due(tm.Add(12*time.Minute), inst)
+	assert.Len(t, events, 1)
+	assert.Equal(t, tm.Add(10*time.Minute), events[0].getTime())
+
+	events = timers.due(time.Date(2018, 3, 5, 15, 46, 0, 0, time.UTC), inst)
+	assert.Len(t, events, 2)
+	assert.Equal(t, "close", events[1].(*TimerEvent).Name)
+	assert.Equal(t, time.Date(2018, 3, 5, 15, 45, 0, 0, time.UTC), events[1].getTime())
+
+	//Daily timer skips weekend
+	events = timers.due(time.Date(2018, 3, 10, 15, 46, 0, 0, time.UTC), inst)
+	assert.Len(t, events, 2)
+	assert.Equal(t, time.Date(2018, 3, 9, 15, 45, 0, 0, time.UTC), events[1].getTime())
+	assert.Equal(t, time.Date(2018, 3, 12, 15, 45, 0, 0, time.UTC), timers.timers[1].next)
+}
+
+type timerTestStrategy struct {
+	DummyStrategy
+	fired []string
+}
+
+func (s *timerTestStrategy) OnTimer(b *BasicStrategy, name string, t time.Time) {
+	s.fired = append(s.fired, name)
+}
+
+func TestBasicStrategy_onTimer(t *testing.T) {
+	st := newTestBasicStrategy()
+	us := &timerTestStrategy{}
+	st.userStrategy = us
+	assert.NotNil(t, st.AddTimer("bad", 0))
+	assert.Nil(t, st.AddTimer("min", time.Minute))
+
+	tm := time.Date(2018, 3, 5, 10, 0, 30, 0, time.UTC)
+	assert.Len(t, st.dueTimers(tm), 0)
+	for _, e := range st.dueTimers(tm.Add(time.Minute)) {
+		st.notify(e)
+	}
+	st.shutDown()
+	assert.Equal(t, []string{"min"}, us.fired)
+	assert.Equal(t, time.Date(2018, 3, 5, 10, 1, 0, 0, time.UTC), st.mostRecentTime)
+}
+
+type sessionTestStrategy struct {
+	DummyStrategy
+	events []string
+}
+
+func (s *sessionTestStrategy) OnSessionOpen(b *BasicStrategy, t time.Time) {
+	s.events = append(s.events, "open "+t.Format("2006-01-02 15:04"))
+}
+
+func (s *sessionTestStrategy) OnSessionClose(b *BasicStrategy, t time.Time) {
+	s.events = append(s.events, "close "+t.Format("2006-01-02 15:04"))
+}
+
+func TestBasicStrategy_sessionCallbacks(t *testing.T) {
+	us := &sessionTestStrategy{}
+	st := NewBasicStrategy(newTestCalendarInstrument(), 1, us)
+	st.init(CoreStrategyChannels{errors: make(chan error), events: make(chan event),
+		portfolio: make(chan *PortfolioNewPositionEvent, 1)})
+
+	assert.Len(t, st.dueTimers(time.Date(2018, 7, 3, 8, 0, 0, 0, time.UTC)), 0)
+	for _, tm := range []time.Time{
+		time.Date(2018, 7, 3, 9, 31, 0, 0, time.UTC),
+		time.Date(2018, 7, 3, 14, 0, 0, 0, time.UTC),
+		time.Date(2018, 7, 5, 9, 30, 0, 0, time.UTC),
+	} {
+		for _, e := range st.dueTimers(tm) {
+			st.notify(e)
+		}
+	}
+	st.shutDown()
+
+	assert.Equal(t, []string{"open 2018-07-03 09:30", "close 2018-07-03 13:00", "open 2018-07-05 09:30"}, us.events)
+}
@@ -0,0 +1,36 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"time"
+)
+
+//LocalTime converts t to exchange time zone. Market open and close times are compared with local time.
+//If exchange has no Location, t is returned as is.
+func (e *Exchange) LocalTime(t time.Time) time.Time {
+	if e.Location == nil {
+		return t
+	}
+	return t.In(e.Location)
+}
+
+//normalizeTime converts time stored in market data storage to UTC. If instrument has DataTimeZone, stored
+//time is considered to be wall clock of this zone regardless of its location.
+func (i *Instrument) normalizeTime(t time.Time) time.Time {
+	if i.DataTimeZone == nil {
+		return t.UTC()
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), i.DataTimeZone).UTC()
+}
+
+func (i *Instrument) normalizeTicks(ticks marketdata.TickArray) {
+	for _, t := range ticks {
+		t.Datetime = i.normalizeTime(t.Datetime)
+	}
+}
+
+func (i *Instrument) normalizeCandles(candles marketdata.CandleArray) {
+	for _, c := range candles {
+		c.Datetime = i.normalizeTime(c.Datetime)
+	}
+}
@@ -0,0 +1,56 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestInstrument_normalizeTime(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("No time zone database: ", err)
+	}
+	inst := newTestInstrument()
+
+	t.Log("Stored time without data zone is converted to UTC")
+	{
+		tm := time.Date(2018, 3, 5, 9, 30, 0, 0, ny)
+		assert.Equal(t, time.Date(2018, 3, 5, 14, 30, 0, 0, time.UTC), inst.normalizeTime(tm))
+	}
+
+	t.Log("Stored wall clock is interpreted in data zone")
+	{
+		inst.DataTimeZone = ny
+		tm := time.Date(2018, 3, 5, 9, 30, 0, 0, time.UTC)
+		assert.Equal(t, time.Date(2018, 3, 5, 14, 30, 0, 0, time.UTC), inst.normalizeTime(tm))
+
+		ticks := marketdata.TickArray{&marketdata.Tick{Datetime: tm}}
+		inst.normalizeTicks(ticks)
+		assert.Equal(t, time.Date(2018, 3, 5, 14, 30, 0, 0, time.UTC), ticks[0].Datetime)
+	}
+}
+
+func TestExchange_LocalTime(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("No time zone database: ", err)
+	}
+	inst := newTestInstrument()
+	utcOpen := time.Date(2018, 3, 5, 14, 30, 0, 0, time.UTC)
+
+	c := newTestMinuteCandle(utcOpen, 10, 11, 9.5, 10.5)
+	c.Ticker = inst
+	assert.False(t, c.isOpening())
+
+	inst.Exchange.Location = ny
+	assert.True(t, c.isOpening())
+
+	t.Log("MOC expiration is calculated in exchange zone")
+	{
+		o := simBrokerOrder{Order: &Order{Ticker: inst, Tif: AuctionTIF, Type: MarketOnClose}}
+		o.Time = utcOpen
+		assert.True(t, o.getExpirationTime().Equal(time.Date(2018, 3, 5, 21, 0, 3, 0, time.UTC)))
+	}
+}
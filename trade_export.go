@@ -0,0 +1,63 @@
+package engine
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//ClosedTrades returns completed trades of all strategies in order of closing
+func (c *Engine) ClosedTrades() []ClosedTradeInfo {
+	c.mut.Lock()
+	var trades []ClosedTradeInfo
+	for _, st := range c.strategiesMap {
+		if cs, ok := st.(closedTradesStrategy); ok {
+			trades = append(trades, cs.ClosedTrades()...)
+		}
+	}
+	c.mut.Unlock()
+	sort.SliceStable(trades, func(i, j int) bool {
+		if trades[i].CloseTime.Equal(trades[j].CloseTime) {
+			return trades[i].Id < trades[j].Id
+		}
+		return trades[i].CloseTime.Before(trades[j].CloseTime)
+	})
+	return trades
+}
+
+//WriteTradesCSV writes closed trades with header line
+func WriteTradesCSV(w io.Writer, trades []ClosedTradeInfo) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"id", "symbol", "direction", "qty", "entries", "open_time", "entry_price", "close_time",
+		"exit_price", "duration", "pnl", "commission", "slippage", "net_pnl", "mae", "mfe", "correlation_id"})
+	for _, t := range trades {
+		cw.Write([]string{t.Id, t.Symbol, string(t.Direction), strconv.FormatInt(t.Qty, 10),
+			strconv.Itoa(t.Entries), t.OpenTime.Format(time.RFC3339Nano), formatFloat(t.EntryPrice),
+			t.CloseTime.Format(time.RFC3339Nano), formatFloat(t.ExitPrice), t.Duration.String(), formatFloat(t.PnL),
+			formatFloat(t.Commission), formatFloat(t.Slippage), formatFloat(t.NetPnL), formatFloat(t.MAE),
+			formatFloat(t.MFE), t.CorrelationID})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+//WriteTradesJSON writes closed trades as JSON array
+func WriteTradesJSON(w io.Writer, trades []ClosedTradeInfo) error {
+	if trades == nil {
+		trades = []ClosedTradeInfo{}
+	}
+	return json.NewEncoder(w).Encode(trades)
+}
+
+//SaveClosedTrades writes closed trades of all strategies to savePath_trades.csv and savePath_trades.json
+func (c *Engine) SaveClosedTrades(savePath string) error {
+	trades := c.ClosedTrades()
+	err := writeFile(savePath+"_trades.csv", func(w io.Writer) error { return WriteTradesCSV(w, trades) })
+	if err != nil {
+		return err
+	}
+	return writeFile(savePath+"_trades.json", func(w io.Writer) error { return WriteTradesJSON(w, trades) })
+}
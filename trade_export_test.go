@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBasicStrategy_ClosedTradesExcursions(t *testing.T) {
+	st := newTestRiskStrategy()
+	st.mostRecentTime = time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	fillTestOrder(st, OrderBuy, 100, 50)
+	for _, price := range []float64{49, 53, 51} {
+		assert.Nil(t, st.currentTrade.updatePnL(price, st.mostRecentTime))
+	}
+	fillTestOrder(st, OrderSell, 100, 51)
+
+	trades := st.ClosedTrades()
+	if assert.Len(t, trades, 1) {
+		assert.InDelta(t, 100.0, trades[0].MAE, 0.0000001)
+		assert.InDelta(t, 300.0, trades[0].MFE, 0.0000001)
+	}
+}
+
+func TestEngine_ClosedTrades(t *testing.T) {
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	st := newTestBasicStrategy()
+	st.closedTrades = append(st.closedTrades, &Trade{Id: "2", Ticker: st.symbol, Direction: LongTrade, ExitQty: 100,
+		OpenTime: tm.Add(-time.Hour), CloseTime: tm, OpenPrice: 10, ExitPrice: 11, ClosedPnL: 100, MAE: 20, MFE: 150})
+	other := NewBasicStrategy(newTestPoolInstrument("Other"), 1, &DummyStrategy{})
+	other.closedTrades = append(other.closedTrades, &Trade{Id: "1", Ticker: other.symbol, CloseTime: tm})
+	c := Engine{strategiesMap: map[string]ICoreStrategy{"Test": st, "Other": other}, mut: &sync.Mutex{}}
+
+	trades := c.ClosedTrades()
+	if !assert.Len(t, trades, 2) {
+		return
+	}
+	assert.Equal(t, "1", trades[0].Id)
+
+	var buf bytes.Buffer
+	assert.Nil(t, WriteTradesCSV(&buf, trades[1:]))
+	assert.Equal(t, "id,symbol,direction,qty,entries,open_time,entry_price,close_time,exit_price,duration,pnl,"+
+		"commission,slippage,net_pnl,mae,mfe,correlation_id\n"+
+		"2,Test,LongTrade,100,0,2018-03-05T09:00:00Z,10,2018-03-05T10:00:00Z,11,1h0m0s,100,0,0,100,20,150,\n",
+		buf.String())
+
+	buf.Reset()
+	assert.Nil(t, WriteTradesJSON(&buf, nil))
+	assert.Equal(t, "[]\n", buf.String())
+	buf.Reset()
+	assert.Nil(t, WriteTradesJSON(&buf, trades))
+	var restored []ClosedTradeInfo
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &restored))
+	assert.Equal(t, trades, restored)
+
+	dir, err := ioutil.TempDir("", "trades")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	assert.Nil(t, c.SaveClosedTrades(filepath.Join(dir, "run")))
+	data, err := ioutil.ReadFile(filepath.Join(dir, "run_trades.json"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"MFE":150`)
+}
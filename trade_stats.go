@@ -0,0 +1,113 @@
+package engine
+
+import (
+	"time"
+)
+
+//ClosedTradeInfo is summary of completed trade. PnL is in account currency, NetPnL is PnL minus commission.
+//Slippage is already included in PnL.
+type ClosedTradeInfo struct {
+	Id         string
+	Symbol     string
+	Direction  TradeType
+	Qty        int64
+	Entries    int
+	EntryPrice float64
+	ExitPrice  float64
+	OpenTime   time.Time
+	CloseTime  time.Time
+	Duration   time.Duration
+	PnL        float64
+	Commission float64
+	Slippage   float64
+	NetPnL     float64
+	//CorrelationID is ID of strategy decision which opened the trade
+	CorrelationID string
+	//MAE and MFE are max adverse and favorable excursions of open PnL, positive amounts in account currency
+	MAE float64
+	MFE float64
+}
+
+//TradeStats is summary of closed trades. Trades with zero net PnL are counted neither as winners nor losers.
+//AvgLoss is negative. Expectancy is average net PnL of the trade.
+type TradeStats struct {
+	Trades      int
+	Winners     int
+	Losers      int
+	WinRate     float64
+	GrossProfit float64
+	GrossLoss   float64
+	AvgWin      float64
+	AvgLoss     float64
+	Expectancy  float64
+	AvgDuration time.Duration
+}
+
+func newClosedTradeInfo(t *Trade) ClosedTradeInfo {
+	info := ClosedTradeInfo{
+		Id:         t.Id,
+		Direction:  t.Direction,
+		Qty:        t.ExitQty,
+		Entries:    t.Entries,
+		EntryPrice: t.OpenPrice,
+		ExitPrice:  t.ExitPrice,
+		OpenTime:   t.OpenTime,
+		CloseTime:  t.CloseTime,
+		Duration:   t.CloseTime.Sub(t.OpenTime),
+		PnL:        t.ClosedPnL,
+		Commission: t.Commission,
+		Slippage:   t.Slippage,
+		NetPnL:     t.ClosedPnL - t.Commission,
+	}
+	info.CorrelationID = t.CorrelationID
+	info.MAE, info.MFE = t.MAE, t.MFE
+	if t.Ticker != nil {
+		info.Symbol = t.Ticker.Symbol
+	}
+	return info
+}
+
+//ClosedTrades returns completed trades of the strategy in order of closing
+func (b *BasicStrategy) ClosedTrades() []ClosedTradeInfo {
+	trades := make([]ClosedTradeInfo, 0, len(b.closedTrades))
+	for _, t := range b.closedTrades {
+		trades = append(trades, newClosedTradeInfo(t))
+	}
+	return trades
+}
+
+//TradeStats returns statistics of closed trades of the strategy
+func (b *BasicStrategy) TradeStats() TradeStats {
+	return calcTradeStats(b.ClosedTrades())
+}
+
+func calcTradeStats(trades []ClosedTradeInfo) TradeStats {
+	s := TradeStats{Trades: len(trades)}
+	if s.Trades == 0 {
+		return s
+	}
+	var total float64
+	var duration time.Duration
+	for _, t := range trades {
+		total += t.NetPnL
+		duration += t.Duration
+		switch {
+		case t.NetPnL > 0:
+			s.Winners++
+			s.GrossProfit += t.NetPnL
+		case t.NetPnL < 0:
+			s.Losers++
+			s.GrossLoss += t.NetPnL
+		}
+	}
+	s.WinRate = float64(s.Winners) / float64(s.Trades)
+	if s.Winners > 0 {
+		s.AvgWin = s.GrossProfit / float64(s.Winners)
+	}
+	if s.Losers > 0 {
+		s.AvgLoss = s.GrossLoss / float64(s.Losers)
+	}
+	s.Expectancy = total / float64(s.Trades)
+	s.AvgDuration = duration / time.Duration(s.Trades)
+	return s
+}
@@ -0,0 +1,69 @@
+package engine
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestBasicStrategy_ClosedTrades(t *testing.T) {
+	st := newTestRiskStrategy()
+	st.mostRecentTime = time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	assert.Len(t, st.ClosedTrades(), 0)
+	assert.Equal(t, TradeStats{}, st.TradeStats())
+
+	fillTestOrder(st, OrderBuy, 200, 50)
+	st.mostRecentTime = st.mostRecentTime.Add(time.Hour)
+	fillTestOrder(st, OrderSell, 100, 51)
+	st.mostRecentTime = st.mostRecentTime.Add(time.Hour)
+	//Reversal closes long and opens short
+	fillTestOrder(st, OrderSell, 300, 52)
+	st.mostRecentTime = st.mostRecentTime.Add(time.Hour)
+	fillTestOrder(st, OrderBuy, 200, 53)
+
+	trades := st.ClosedTrades()
+	assert.Len(t, trades, 2)
+
+	long := trades[0]
+	assert.Equal(t, "Test", long.Symbol)
+	assert.Equal(t, LongTrade, long.Direction)
+	assert.Equal(t, int64(200), long.Qty)
+	assert.Equal(t, 50.0, long.EntryPrice)
+	assert.InDelta(t, 51.5, long.ExitPrice, 0.0000001)
+	assert.Equal(t, 2*time.Hour, long.Duration)
+	assert.InDelta(t, 300.0, long.PnL, 0.0000001)
+	assert.InDelta(t, 300.0, long.NetPnL, 0.0000001)
+
+	short := trades[1]
+	assert.Equal(t, ShortTrade, short.Direction)
+	assert.Equal(t, int64(200), short.Qty)
+	assert.Equal(t, 52.0, short.EntryPrice)
+	assert.Equal(t, 53.0, short.ExitPrice)
+	assert.Equal(t, time.Hour, short.Duration)
+	assert.InDelta(t, -200.0, short.PnL, 0.0000001)
+
+	s := st.TradeStats()
+	assert.Equal(t, 2, s.Trades)
+	assert.Equal(t, 1, s.Winners)
+	assert.Equal(t, 1, s.Losers)
+	assert.Equal(t, 0.5, s.WinRate)
+	assert.InDelta(t, 300.0, s.AvgWin, 0.0000001)
+	assert.InDelta(t, -200.0, s.AvgLoss, 0.0000001)
+	assert.InDelta(t, 50.0, s.Expectancy, 0.0000001)
+	assert.Equal(t, 90*time.Minute, s.AvgDuration)
+}
+
+func TestCalcTradeStats_commission(t *testing.T) {
+	trades := []ClosedTradeInfo{
+		{PnL: 10, Commission: 2, NetPnL: 8},
+		{PnL: 1, Commission: 2, NetPnL: -1},
+		{PnL: 2, Commission: 2, NetPnL: 0},
+	}
+	s := calcTradeStats(trades)
+	assert.Equal(t, 1, s.Winners)
+	assert.Equal(t, 1, s.Losers)
+	assert.InDelta(t, 1.0/3, s.WinRate, 0.0000001)
+	assert.InDelta(t, 8.0, s.GrossProfit, 0.0000001)
+	assert.InDelta(t, -1.0, s.GrossLoss, 0.0000001)
+	assert.InDelta(t, 7.0/3, s.Expectancy, 0.0000001)
+}
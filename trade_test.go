@@ -855,6 +855,8 @@ func TestTrade_updatePnL(t *testing.T) {
 		assert.Equal(t, -100.0, trade.Returns[0].OpenPnL)
 		assert.Equal(t, 100.0, trade.Returns[1].OpenPnL)
 		assert.Equal(t, 0.0, trade.Returns[2].OpenPnL)
+		assert.Equal(t, 100.0, trade.MAE)
+		assert.Equal(t, 100.0, trade.MFE)
 
 	}
 
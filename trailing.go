@@ -0,0 +1,141 @@
+package engine
+
+import (
+	"errors"
+	"math"
+)
+
+//trailingStop moves stop loss of the trade behind the best price reached since entry. Distance is either
+//percent of the best price or multiple of ATR (chandelier exit). Stop level only moves in trade direction.
+type trailingStop struct {
+	percent     float64
+	atr         *ATR
+	multiple    float64
+	destination string
+
+	tradeID string
+	extreme float64
+	level   float64
+}
+
+//distance returns distance of the stop from the best price or NaN if it's not known yet
+func (s *trailingStop) distance() float64 {
+	if s.atr != nil {
+		return s.multiple * s.atr.Value()
+	}
+	return s.extreme * s.percent / 100
+}
+
+//SetTrailingStop sets stop loss which trails the best price of the trade at given percent. Trailing stop
+//uses stop loss order, so it replaces level set by SetStopLoss. Zero percent removes trailing stop.
+func (b *BasicStrategy) SetTrailingStop(percent float64, destination string) error {
+	if percent < 0 || math.IsNaN(percent) {
+		return errors.New("Trailing stop percent can't be negative or NaN. ")
+	}
+	if percent == 0 {
+		return b.clearTrailingStop(destination)
+	}
+	return b.setTrailingStop(&trailingStop{percent: percent, destination: destination})
+}
+
+//SetChandelierStop sets stop loss which trails the best price of the trade at given multiple of ATR with
+//given period. ATR is calculated by candles of base timeframe. Zero multiple removes trailing stop.
+func (b *BasicStrategy) SetChandelierStop(atrPeriod int, multiple float64, destination string) error {
+	if atrPeriod < 1 || multiple < 0 || math.IsNaN(multiple) {
+		return errors.New("Chandelier stop ATR period should be positive and multiple can't be negative. ")
+	}
+	if multiple == 0 {
+		return b.clearTrailingStop(destination)
+	}
+	atr := NewATR(atrPeriod)
+	for _, c := range b.Candles {
+		atr.Update(c)
+	}
+	return b.setTrailingStop(&trailingStop{atr: atr, multiple: multiple, destination: destination})
+}
+
+func (b *BasicStrategy) setTrailingStop(s *trailingStop) error {
+	if s.destination == "" {
+		return errors.New("Exit order destination is empty. ")
+	}
+	b.trailing = s
+	b.syncTrailingStop()
+	return nil
+}
+
+func (b *BasicStrategy) clearTrailingStop(destination string) error {
+	b.trailing = nil
+	return b.SetStopLoss(0, destination)
+}
+
+//TrailingStopLevel returns current level of trailing stop or NaN if there is no trailing stop for open trade
+func (b *BasicStrategy) TrailingStopLevel() float64 {
+	if b.trailing == nil || b.trailing.level == 0 {
+		return math.NaN()
+	}
+	return b.trailing.level
+}
+
+//updateTrailingStop moves trailing stop by the high and low prices seen after the trade was opened
+func (b *BasicStrategy) updateTrailingStop(high float64, low float64) {
+	s := b.trailing
+	if s == nil || !b.currentTrade.IsOpen() || s.tradeID != b.currentTrade.Id {
+		return
+	}
+	if b.currentTrade.Type == LongTrade {
+		s.extreme = math.Max(s.extreme, high)
+	} else {
+		s.extreme = math.Min(s.extreme, low)
+	}
+	b.syncTrailingStop()
+}
+
+func (b *BasicStrategy) onTrailingStopCandle(c *Candle) {
+	if b.trailing == nil {
+		return
+	}
+	if b.trailing.atr != nil {
+		b.trailing.atr.Update(c)
+	}
+	b.updateTrailingStop(c.High, c.Low)
+}
+
+//syncTrailingStop ratchets stop level of the current trade and passes it to stop loss order
+func (b *BasicStrategy) syncTrailingStop() {
+	s := b.trailing
+	if s == nil {
+		return
+	}
+	t := b.currentTrade
+	if !t.IsOpen() {
+		if s.tradeID != "" {
+			s.tradeID, s.level = "", 0
+			b.setExitOrder(&b.stopLoss, StopOrder, 0, 0, s.destination)
+		}
+		return
+	}
+	if s.tradeID != t.Id {
+		s.tradeID = t.Id
+		s.extreme = t.OpenPrice
+		s.level = 0
+	}
+
+	dist := s.distance()
+	if math.IsNaN(dist) {
+		return
+	}
+	level := s.extreme - dist
+	if t.Type == ShortTrade {
+		level = s.extreme + dist
+	}
+	if level <= 0 {
+		return
+	}
+	if s.level != 0 && ((t.Type == LongTrade && level <= s.level) || (t.Type == ShortTrade && level >= s.level)) {
+		return
+	}
+	s.level = level
+	if err := b.setExitOrder(&b.stopLoss, StopOrder, level, 0, s.destination); err != nil {
+		b.newError(err)
+	}
+}
@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"alex/marketdata"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"time"
+)
+
+func newTestTrailingTick(st *BasicStrategy, tm time.Time, price float64) *NewTickEvent {
+	tick := Tick{Tick: &marketdata.Tick{
+		Datetime:  tm,
+		Symbol:    st.symbol.Symbol,
+		LastPrice: price,
+		LastSize:  100,
+		BidPrice:  math.NaN(),
+		AskPrice:  math.NaN(),
+	}, Ticker: st.symbol}
+	return &NewTickEvent{BaseEvent: be(tm, st.symbol), Tick: &tick}
+}
+
+func TestBasicStrategy_SetTrailingStop(t *testing.T) {
+	st := newTestRiskStrategy()
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	st.mostRecentTime = tm
+	assert.NotNil(t, st.SetTrailingStop(-1, "Sim"))
+	assert.NotNil(t, st.SetTrailingStop(10, ""))
+	assert.Nil(t, st.SetTrailingStop(10, "Sim"))
+	assert.True(t, math.IsNaN(st.TrailingStopLevel()))
+
+	fillTestOrder(st, OrderBuy, 100, 50)
+	stopID := st.stopLoss.id
+	stop := st.currentTrade.NewOrders[stopID]
+	assert.Equal(t, StopOrder, stop.Type)
+	assert.InDelta(t, 45.0, stop.Price, 0.0000001)
+	st.proxyEvent(&OrderConfirmationEvent{BaseEvent: be(tm, st.symbol), OrdId: stopID})
+
+	//Trail moves up with price
+	st.notify(newTestTrailingTick(st, tm.Add(time.Second), 60))
+	st.shutDown()
+	assert.InDelta(t, 54.0, st.TrailingStopLevel(), 0.0000001)
+	info, _ := st.OrderStatus(stopID)
+	assert.True(t, info.PendingReplace)
+	st.proxyEvent(&OrderReplacedEvent{BaseEvent: be(tm, st.symbol), OrdId: stopID, NewPrice: 54})
+
+	//and never moves down
+	st.notify(newTestTrailingTick(st, tm.Add(2*time.Second), 55))
+	st.shutDown()
+	assert.InDelta(t, 54.0, st.TrailingStopLevel(), 0.0000001)
+	info, _ = st.OrderStatus(stopID)
+	assert.False(t, info.PendingReplace)
+
+	//Stop is executed and trail is reset for the next trade
+	st.proxyEvent(&OrderFillEvent{BaseEvent: be(tm, st.symbol), OrdId: stopID, Price: 54, Qty: 100})
+	assert.Equal(t, int64(0), st.Position())
+	assert.True(t, math.IsNaN(st.TrailingStopLevel()))
+
+	fillTestOrder(st, OrderSell, 100, 50)
+	stop = st.currentTrade.NewOrders[st.stopLoss.id]
+	assert.Equal(t, OrderBuy, stop.Side)
+	assert.InDelta(t, 55.0, stop.Price, 0.0000001)
+}
+
+func TestBasicStrategy_SetChandelierStop(t *testing.T) {
+	st := newTestRiskStrategy()
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	st.mostRecentTime = tm
+	assert.NotNil(t, st.SetChandelierStop(0, 3, "Sim"))
+	assert.Nil(t, st.SetChandelierStop(2, 3, "Sim"))
+
+	fillTestOrder(st, OrderBuy, 100, 50)
+	//ATR is not ready yet
+	assert.Nil(t, st.stopLoss)
+
+	st.notify(newTestCandleCloseEvent(50, 51, 49, 50, tm.Add(time.Minute), "1"))
+	st.shutDown()
+	st.notify(newTestCandleCloseEvent(50, 52, 50, 51, tm.Add(2*time.Minute), "1"))
+	st.shutDown()
+	//ATR is 2, highest high is 52
+	assert.InDelta(t, 46.0, st.TrailingStopLevel(), 0.0000001)
+	assert.InDelta(t, 46.0, st.currentTrade.NewOrders[st.stopLoss.id].Price, 0.0000001)
+
+	//Zero multiple removes trailing stop together with its order
+	assert.Nil(t, st.SetChandelierStop(2, 0, "Sim"))
+	assert.True(t, math.IsNaN(st.TrailingStopLevel()))
+	assert.False(t, st.stopLoss.isActive())
+}
@@ -0,0 +1,84 @@
+package engine
+
+import (
+	"sort"
+	"time"
+)
+
+type universeMember struct {
+	instrument *Instrument
+	from       time.Time
+	to         time.Time
+}
+
+func (m *universeMember) contains(t time.Time) bool {
+	if t.Before(m.from) {
+		return false
+	}
+	if !m.to.IsZero() && !t.Before(m.to) {
+		return false
+	}
+	return true
+}
+
+//Universe is the set of symbols which changes over time. E.g. constituents of the index by date.
+type Universe struct {
+	members     map[string][]*universeMember
+	instruments map[string]*Instrument
+}
+
+func NewUniverse() *Universe {
+	u := Universe{
+		members:     make(map[string][]*universeMember),
+		instruments: make(map[string]*Instrument),
+	}
+	return &u
+}
+
+//AddMember puts instrument in universe for period [from, to). Zero to means that instrument is never removed.
+//Instrument can have several membership periods.
+func (u *Universe) AddMember(inst *Instrument, from time.Time, to time.Time) {
+	u.instruments[inst.Symbol] = inst
+	u.members[inst.Symbol] = append(u.members[inst.Symbol], &universeMember{
+		instrument: inst,
+		from:       from,
+		to:         to,
+	})
+}
+
+//IsMember returns true if symbol is in universe at given time
+func (u *Universe) IsMember(symbol string, t time.Time) bool {
+	for _, m := range u.members[symbol] {
+		if m.contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+//Instruments returns all instruments which were ever members of universe sorted by symbol
+func (u *Universe) Instruments() []*Instrument {
+	var instruments []*Instrument
+	for _, inst := range u.instruments {
+		instruments = append(instruments, inst)
+	}
+	sort.Slice(instruments, func(i, j int) bool {
+		return instruments[i].Symbol < instruments[j].Symbol
+	})
+	return instruments
+}
+
+//changes returns time sorted SymbolAddedEvent and SymbolRemovedEvent for all membership periods
+func (u *Universe) changes() eventArray {
+	var changes eventArray
+	for _, inst := range u.Instruments() {
+		for _, m := range u.members[inst.Symbol] {
+			changes = append(changes, &SymbolAddedEvent{BaseEvent: be(m.from, inst)})
+			if !m.to.IsZero() {
+				changes = append(changes, &SymbolRemovedEvent{BaseEvent: be(m.to, inst)})
+			}
+		}
+	}
+	changes.sort()
+	return changes
+}
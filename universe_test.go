@@ -0,0 +1,76 @@
+package engine
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestUniverse_IsMember(t *testing.T) {
+	u := NewUniverse()
+	inst := newTestInstrument()
+	from := time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2018, 4, 1, 0, 0, 0, 0, time.UTC)
+	u.AddMember(inst, from, to)
+
+	assert.False(t, u.IsMember("Test", from.Add(-time.Second)))
+	assert.True(t, u.IsMember("Test", from))
+	assert.True(t, u.IsMember("Test", to.Add(-time.Second)))
+	assert.False(t, u.IsMember("Test", to))
+	assert.False(t, u.IsMember("Other", from))
+
+	t.Log("Open ended membership")
+	{
+		u.AddMember(inst, time.Date(2018, 5, 1, 0, 0, 0, 0, time.UTC), time.Time{})
+		assert.False(t, u.IsMember("Test", time.Date(2018, 4, 15, 0, 0, 0, 0, time.UTC)))
+		assert.True(t, u.IsMember("Test", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)))
+	}
+}
+
+func TestUniverse_changes(t *testing.T) {
+	u := NewUniverse()
+	inst := newTestInstrument()
+	other := &Instrument{Symbol: "Other"}
+
+	u.AddMember(inst, time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2018, 4, 1, 0, 0, 0, 0, time.UTC))
+	u.AddMember(other, time.Date(2018, 3, 15, 0, 0, 0, 0, time.UTC), time.Time{})
+
+	assert.Len(t, u.Instruments(), 2)
+	assert.Equal(t, "Other", u.Instruments()[0].Symbol)
+
+	changes := u.changes()
+	assert.Len(t, changes, 3)
+
+	assert.IsType(t, &SymbolAddedEvent{}, changes[0])
+	assert.Equal(t, "Test", changes[0].getSymbol())
+
+	assert.IsType(t, &SymbolAddedEvent{}, changes[1])
+	assert.Equal(t, "Other", changes[1].getSymbol())
+
+	assert.IsType(t, &SymbolRemovedEvent{}, changes[2])
+	assert.Equal(t, "Test", changes[2].getSymbol())
+}
+
+func TestEngine_checkUniverse(t *testing.T) {
+	if _, err := os.Stat("log.txt"); os.IsNotExist(err) {
+		defer os.Remove("log.txt")
+	}
+	u := NewUniverse()
+	u.AddMember(newTestInstrument(), time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC), time.Time{})
+	u.AddMember(&Instrument{Symbol: "Other"}, time.Date(2018, 3, 15, 0, 0, 0, 0, time.UTC), time.Time{})
+	md := &BTM{}
+	md.SetUniverse(u)
+
+	st := NewBasicStrategy(newTestInstrument(), 1, &DummyStrategy{})
+	c := NewEngine(map[string]ICoreStrategy{"Test": st}, newTestSimBroker(), md, BacktestMode, false)
+	c.Run()
+	assert.Equal(t, StateFailed, c.State())
+	assert.True(t, errors.Is(c.Status().Err, ErrCodeNoStrategyFactory))
+
+	c.SetStrategyFactory(func(inst *Instrument) ICoreStrategy {
+		return NewBasicStrategy(inst, 1, &DummyStrategy{})
+	})
+	assert.Nil(t, c.checkUniverse())
+}
@@ -0,0 +1,218 @@
+package engine
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	defaultReturnsPeriod = 24 * time.Hour
+	defaultReturnsWindow = 250
+	defaultVaRConfidence = 0.95
+)
+
+//PortfolioSnapshot is state of the account at the end of returns period
+type PortfolioSnapshot struct {
+	Time          time.Time
+	Cash          float64
+	Equity        float64
+	GrossExposure float64
+	NetExposure   float64
+	Drawdown      float64
+	//Return is equity return of the period, Volatility is standard deviation of returns in the window
+	Return     float64
+	Volatility float64
+	//ParametricVaR and HistoricalVaR are estimated one period losses at VaR confidence, positive amounts
+	//in account currency
+	ParametricVaR float64
+	HistoricalVaR float64
+	//AvgPositionAge is average age of open positions, StalePositions is number of positions open longer than
+	//max holding period
+	AvgPositionAge time.Duration
+	StalePositions int
+}
+
+//returnsTracker samples equity at the end of every period and keeps returns of last periods
+type returnsTracker struct {
+	period      time.Duration
+	window      int
+	confidence  float64
+	periodStart time.Time
+	lastEquity  float64
+	returns     []float64
+	snapshots   []PortfolioSnapshot
+	//start and startEquity are the first sample, where snapshots begin
+	start       time.Time
+	startEquity float64
+}
+
+func newReturnsTracker() returnsTracker {
+	return returnsTracker{
+		period:     defaultReturnsPeriod,
+		window:     defaultReturnsWindow,
+		confidence: defaultVaRConfidence,
+	}
+}
+
+func (r *returnsTracker) add(ret float64) {
+	r.returns = append(r.returns, ret)
+	if len(r.returns) > r.window {
+		r.returns = append([]float64{}, r.returns[len(r.returns)-r.window:]...)
+	}
+}
+
+//meanStdDev returns mean and sample standard deviation of returns. NaN is returned if there are less than
+//two returns.
+func (r *returnsTracker) meanStdDev() (float64, float64) {
+	n := float64(len(r.returns))
+	if n < 2 {
+		return math.NaN(), math.NaN()
+	}
+	sum := 0.0
+	for _, v := range r.returns {
+		sum += v
+	}
+	mean := sum / n
+	sq := 0.0
+	for _, v := range r.returns {
+		sq += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(sq / (n - 1))
+}
+
+//parametricVaR is loss of equity at confidence if returns are normally distributed
+func (r *returnsTracker) parametricVaR(equity float64, confidence float64) float64 {
+	mean, std := r.meanStdDev()
+	if math.IsNaN(std) {
+		return math.NaN()
+	}
+	z := math.Sqrt2 * math.Erfinv(2*confidence-1)
+	return math.Max(0, (z*std-mean)*equity)
+}
+
+//historicalVaR is loss of equity at confidence quantile of observed returns
+func (r *returnsTracker) historicalVaR(equity float64, confidence float64) float64 {
+	if len(r.returns) < 2 {
+		return math.NaN()
+	}
+	sorted := append([]float64{}, r.returns...)
+	sort.Float64s(sorted)
+	i := int(math.Floor((1 - confidence) * float64(len(sorted))))
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+	return math.Max(0, -sorted[i]*equity)
+}
+
+func validConfidence(confidence float64) bool {
+	return confidence > 0 && confidence < 1
+}
+
+//setReturnsPeriod sets period of equity returns, number of returns used by estimates and default VaR
+//confidence. Returns collected before are dropped.
+func (p *portfolioHandler) setReturnsPeriod(period time.Duration, window int, confidence float64) error {
+	if period <= 0 || window < 2 || !validConfidence(confidence) {
+		return errors.New("Returns period should be positive, window at least 2 and confidence in (0, 1). ")
+	}
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	p.returns = newReturnsTracker()
+	p.prices = make(map[string]*symbolReturns)
+	p.returns.period = period
+	p.returns.window = window
+	p.returns.confidence = confidence
+	return nil
+}
+
+//sampleReturns records return and snapshot of the account when time of market data crosses end of the period
+func (p *portfolioHandler) sampleReturns(t time.Time) {
+	equity := p.Equity()
+	gross := p.GrossExposure()
+	net := p.NetExposure()
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	r := &p.returns
+	start := t.Truncate(r.period)
+	if r.periodStart.IsZero() {
+		r.periodStart = start
+		r.lastEquity = equity
+		r.start = start
+		r.startEquity = equity
+		p.sampleSymbolReturns()
+		return
+	}
+	if !start.After(r.periodStart) {
+		return
+	}
+	p.sampleSymbolReturns()
+	ret := math.NaN()
+	if r.lastEquity > 0 {
+		ret = equity/r.lastEquity - 1
+		r.add(ret)
+	}
+	_, std := r.meanStdDev()
+	holding := p.holdingStats()
+	r.snapshots = append(r.snapshots, PortfolioSnapshot{
+		Time:           start,
+		Cash:           p.cash,
+		Equity:         equity,
+		GrossExposure:  gross,
+		NetExposure:    net,
+		Drawdown:       p.dd.drawdown,
+		Return:         ret,
+		Volatility:     std,
+		ParametricVaR:  r.parametricVaR(equity, r.confidence),
+		HistoricalVaR:  r.historicalVaR(equity, r.confidence),
+		AvgPositionAge: holding.AvgPositionAge,
+		StalePositions: len(holding.StalePositions),
+	})
+	r.periodStart = start
+	r.lastEquity = equity
+}
+
+//Volatility is standard deviation of equity returns of the last periods. It's NaN until there are two returns.
+func (p *portfolioHandler) Volatility() float64 {
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	_, std := p.returns.meanStdDev()
+	return std
+}
+
+//ParametricVaR is one period loss of current equity which is not exceeded with given confidence, e.g. 0.95,
+//if returns are normally distributed with mean and volatility of the last periods
+func (p *portfolioHandler) ParametricVaR(confidence float64) float64 {
+	if !validConfidence(confidence) {
+		return math.NaN()
+	}
+	equity := p.Equity()
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	return p.returns.parametricVaR(equity, confidence)
+}
+
+//HistoricalVaR is one period loss of current equity at given confidence quantile of returns of the last periods
+func (p *portfolioHandler) HistoricalVaR(confidence float64) float64 {
+	if !validConfidence(confidence) {
+		return math.NaN()
+	}
+	equity := p.Equity()
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	return p.returns.historicalVaR(equity, confidence)
+}
+
+//Snapshots returns copy of account snapshots taken at the end of every returns period
+func (p *portfolioHandler) Snapshots() []PortfolioSnapshot {
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	return append([]PortfolioSnapshot{}, p.returns.snapshots...)
+}
+
+//SetReturnsPeriod sets period of portfolio returns used by volatility and VaR estimates, e.g. 24 hours for
+//daily returns, number of last returns used and VaR confidence of snapshots. Default is daily returns,
+//window of 250 and 95% confidence.
+func (c *Engine) SetReturnsPeriod(period time.Duration, window int, confidence float64) error {
+	return c.portfolio.setReturnsPeriod(period, window, confidence)
+}
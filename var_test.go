@@ -0,0 +1,55 @@
+package engine
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPortfolio_VaR(t *testing.T) {
+	p := newPortfolio()
+	p.setCapital(10000)
+	assert.NotNil(t, p.setReturnsPeriod(0, 10, 0.95))
+	assert.NotNil(t, p.setReturnsPeriod(time.Hour, 10, 1))
+	assert.Nil(t, p.setReturnsPeriod(time.Hour, 4, 0.8))
+
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	p.sampleReturns(tm)
+	assert.True(t, math.IsNaN(p.Volatility()))
+	assert.True(t, math.IsNaN(p.HistoricalVaR(0.95)))
+
+	//Equity changes by +1%, -2%, +3%, -1%, +2%
+	for i, ret := range []float64{0.01, -0.02, 0.03, -0.01, 0.02} {
+		p.setCapital(p.capital * (1 + ret))
+		p.sampleReturns(tm.Add(time.Duration(i+1)*time.Hour + 10*time.Minute))
+		//Second sample of the same period is ignored
+		p.sampleReturns(tm.Add(time.Duration(i+1)*time.Hour + 20*time.Minute))
+	}
+
+	snapshots := p.Snapshots()
+	assert.Len(t, snapshots, 5)
+	assert.Equal(t, tm.Add(time.Hour), snapshots[0].Time)
+	assert.InDelta(t, 0.01, snapshots[0].Return, 0.0000001)
+	assert.True(t, math.IsNaN(snapshots[0].Volatility))
+	assert.InDelta(t, 0.02, snapshots[4].Return, 0.0000001)
+
+	//Window keeps last 4 returns
+	returns := []float64{-0.02, 0.03, -0.01, 0.02}
+	mean := 0.005
+	sq := 0.0
+	for _, r := range returns {
+		sq += (r - mean) * (r - mean)
+	}
+	std := math.Sqrt(sq / 3)
+	assert.InDelta(t, std, p.Volatility(), 0.0000001)
+	assert.InDelta(t, std, snapshots[4].Volatility, 0.0000001)
+
+	equity := p.Equity()
+	assert.InDelta(t, (1.6448536269514722*std-mean)*equity, p.ParametricVaR(0.95), 0.0001)
+	assert.InDelta(t, 0.02*equity, p.HistoricalVaR(0.95), 0.0000001)
+	assert.InDelta(t, 0.01*equity, p.HistoricalVaR(0.7), 0.0000001)
+	assert.InDelta(t, 0.0, p.HistoricalVaR(0.5), 0.0000001)
+	assert.InDelta(t, 0.02*equity, snapshots[4].HistoricalVaR, 0.0000001)
+	assert.True(t, math.IsNaN(p.ParametricVaR(0)))
+}
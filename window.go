@@ -0,0 +1,128 @@
+package engine
+
+import (
+	"sort"
+	"time"
+)
+
+//CandleWindow is read only view of candles buffer of the strategy. It shares memory with the buffer, so it's
+//cheap to create in every callback. Buffer never changes candles which are already in the view, new candles
+//are put after its end, so view is snapshot of the buffer at the time it was created. Candles themselves
+//are shared too and shouldn't be modified.
+type CandleWindow struct {
+	candles CandleArray
+}
+
+func newCandleWindow(c CandleArray) CandleWindow {
+	//Capacity is limited, so append to the slice of the view can't overwrite the buffer
+	return CandleWindow{candles: c[:len(c):len(c)]}
+}
+
+//Len returns number of candles in the window
+func (w CandleWindow) Len() int {
+	return len(w.candles)
+}
+
+//At returns candle by index. Zero index is the oldest candle, negative index counts from the newest one,
+//so At(-1) is the last candle. Nil is returned if index is out of range.
+func (w CandleWindow) At(i int) *Candle {
+	if i < 0 {
+		i += len(w.candles)
+	}
+	if i < 0 || i >= len(w.candles) {
+		return nil
+	}
+	return w.candles[i]
+}
+
+//Last returns window of the last n candles
+func (w CandleWindow) Last(n int) CandleWindow {
+	if n < 0 {
+		n = 0
+	}
+	if n > len(w.candles) {
+		n = len(w.candles)
+	}
+	return newCandleWindow(w.candles[len(w.candles)-n:])
+}
+
+//Between returns window of candles with time in [from, to) range
+func (w CandleWindow) Between(from time.Time, to time.Time) CandleWindow {
+	i := sort.Search(len(w.candles), func(i int) bool { return !w.candles[i].Datetime.Before(from) })
+	j := sort.Search(len(w.candles), func(i int) bool { return !w.candles[i].Datetime.Before(to) })
+	if j < i {
+		j = i
+	}
+	return newCandleWindow(w.candles[i:j])
+}
+
+//Closes returns close prices of the window. Unlike the window itself it's a copy.
+func (w CandleWindow) Closes() []float64 {
+	closes := make([]float64, len(w.candles))
+	for i, c := range w.candles {
+		closes[i] = c.Close
+	}
+	return closes
+}
+
+//TickWindow is read only view of ticks buffer of the strategy with the same semantics as CandleWindow
+type TickWindow struct {
+	ticks TickArray
+}
+
+func newTickWindow(t TickArray) TickWindow {
+	return TickWindow{ticks: t[:len(t):len(t)]}
+}
+
+//Len returns number of ticks in the window
+func (w TickWindow) Len() int {
+	return len(w.ticks)
+}
+
+//At returns tick by index. Zero index is the oldest tick, negative index counts from the newest one.
+//Nil is returned if index is out of range.
+func (w TickWindow) At(i int) *Tick {
+	if i < 0 {
+		i += len(w.ticks)
+	}
+	if i < 0 || i >= len(w.ticks) {
+		return nil
+	}
+	return w.ticks[i]
+}
+
+//Last returns window of the last n ticks
+func (w TickWindow) Last(n int) TickWindow {
+	if n < 0 {
+		n = 0
+	}
+	if n > len(w.ticks) {
+		n = len(w.ticks)
+	}
+	return newTickWindow(w.ticks[len(w.ticks)-n:])
+}
+
+//Between returns window of ticks with time in [from, to) range
+func (w TickWindow) Between(from time.Time, to time.Time) TickWindow {
+	i := sort.Search(len(w.ticks), func(i int) bool { return !w.ticks[i].Datetime.Before(from) })
+	j := sort.Search(len(w.ticks), func(i int) bool { return !w.ticks[i].Datetime.Before(to) })
+	if j < i {
+		j = i
+	}
+	return newTickWindow(w.ticks[i:j])
+}
+
+//CandleWindow returns view of candles buffer of base timeframe
+func (b *BasicStrategy) CandleWindow() CandleWindow {
+	return newCandleWindow(b.Candles)
+}
+
+//CandleWindowOf returns view of candles buffer of given timeframe
+func (b *BasicStrategy) CandleWindowOf(timeFrame string) CandleWindow {
+	return newCandleWindow(b.CandlesOf(timeFrame))
+}
+
+//TickWindow returns view of ticks buffer
+func (b *BasicStrategy) TickWindow() TickWindow {
+	return newTickWindow(b.Ticks)
+}
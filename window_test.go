@@ -0,0 +1,59 @@
+package engine
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestBasicStrategy_CandleWindow(t *testing.T) {
+	st := newTestBasicStrategy()
+	st.nPeriods = 5
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		st.putNewCandle(newTestMinuteCandle(tm.Add(time.Duration(i)*time.Minute), 10, 20, 5, float64(10+i)))
+	}
+
+	w := st.CandleWindow()
+	assert.Equal(t, 5, w.Len())
+	assert.Equal(t, 10.0, w.At(0).Close)
+	assert.Equal(t, 14.0, w.At(-1).Close)
+	assert.Nil(t, w.At(5))
+	assert.Nil(t, w.At(-6))
+
+	last := w.Last(2)
+	assert.Equal(t, []float64{13, 14}, last.Closes())
+	assert.Equal(t, 5, w.Last(10).Len())
+	assert.Equal(t, 0, w.Last(-1).Len())
+
+	between := w.Between(tm.Add(time.Minute), tm.Add(3*time.Minute))
+	assert.Equal(t, []float64{11, 12}, between.Closes())
+	assert.Equal(t, 0, w.Between(tm.Add(time.Hour), tm).Len())
+
+	//Window is snapshot: new and out of order candles don't change it
+	st.putNewCandle(newTestMinuteCandle(tm.Add(5*time.Minute), 10, 20, 5, 15))
+	st.putNewCandle(newTestMinuteCandle(tm.Add(90*time.Second), 10, 20, 5, 16))
+	assert.Equal(t, []float64{10, 11, 12, 13, 14}, w.Closes())
+	assert.Equal(t, []float64{16, 12, 13, 14, 15}, st.CandleWindow().Closes())
+
+	//Append to view doesn't overwrite buffer
+	_ = append(st.CandleWindow().Last(3).candles, newTestMinuteCandle(tm, 1, 1, 1, 1))
+	assert.Equal(t, 15.0, st.CandleWindow().At(-1).Close)
+
+	assert.Equal(t, 0, st.CandleWindowOf("60").Len())
+}
+
+func TestBasicStrategy_TickWindow(t *testing.T) {
+	st := newTestBasicStrategy()
+	st.nPeriods = 3
+	tm := time.Date(2018, 3, 5, 10, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		st.putNewTick(newTestTrailingTick(st, tm.Add(time.Duration(i)*time.Second), float64(10+i)).Tick)
+	}
+
+	w := st.TickWindow()
+	assert.Equal(t, 3, w.Len())
+	assert.Equal(t, 11.0, w.At(0).LastPrice)
+	assert.Equal(t, 13.0, w.Last(1).At(0).LastPrice)
+	assert.Equal(t, 12.0, w.Between(tm.Add(2*time.Second), tm.Add(3*time.Second)).At(0).LastPrice)
+}